@@ -0,0 +1,58 @@
+// Command replay re-sends traffic recorded with RECORD_FILE to a target environment, at the recorded
+// pace or faster, and reports the requests the target rejected.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/olyamironova/exchange-engine/internal/record"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func main() {
+	file := flag.String("file", "", "recording to replay")
+	httpBase := flag.String("http", "http://localhost:8080", "target HTTP base URL, empty to skip HTTP entries")
+	grpcAddr := flag.String("grpc", "", "target gRPC address, empty to skip gRPC entries")
+	key := flag.String("key", os.Getenv("REPLAY_API_KEY"), "API key to replay with")
+	speed := flag.Float64("speed", 1, "pace multiplier: 1 keeps the recorded pace, 0 sends as fast as the target answers")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("failed to open %s: %v", *file, err)
+	}
+	defer f.Close()
+
+	p := &record.Replayer{
+		HTTPBase: *httpBase,
+		APIKey:   *key,
+		Speed:    *speed,
+		Report: func(e record.Entry, err error) {
+			log.Printf("%s %s %s at %s: %v", e.Proto, e.Method, e.Path, e.At.Format("15:04:05.000"), err)
+		},
+	}
+	if *grpcAddr != "" {
+		conn, err := grpc.NewClient(*grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Fatalf("failed to dial %s: %v", *grpcAddr, err)
+		}
+		defer conn.Close()
+		p.GRPC = conn
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	stats, err := p.Replay(ctx, f)
+	log.Printf("sent %d, failed %d, skipped %d", stats.Sent, stats.Failed, stats.Skipped)
+	if err != nil {
+		log.Fatalf("replay stopped: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	apigrpc "github.com/olyamironova/exchange-engine/internal/api/grpc"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// listeners serves the HTTP API and, if grpc is not nil, the gRPC API: on grpcAddr, or on httpAddr
+// too when both are the same, telling gRPC requests apart by their content type over cleartext
+// HTTP/2. Keepalive settings of the gRPC server only apply to its own listener.
+type listeners struct {
+	http     *nethttp.Server
+	grpc     *apigrpc.Server
+	grpcAddr string
+	// shutdownTimeout bounds draining streams and finishing requests once a shutdown begins
+	shutdownTimeout time.Duration
+}
+
+func newListeners(httpAddr string, handler nethttp.Handler, grpc *apigrpc.Server, grpcAddr string, shutdownTimeout time.Duration) *listeners {
+	l := &listeners{grpc: grpc, grpcAddr: grpcAddr, shutdownTimeout: shutdownTimeout}
+	if grpc != nil && grpcAddr == httpAddr {
+		api := handler
+		handler = h2c.NewHandler(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+				grpc.ServeHTTP(w, r)
+				return
+			}
+			api.ServeHTTP(w, r)
+		}), &http2.Server{})
+		l.grpcAddr = ""
+	}
+	l.http = &nethttp.Server{Addr: httpAddr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	return l
+}
+
+// run serves until ctx is done or a listener fails, then shuts both down: open gRPC streams are
+// drained first, so their clients reconnect elsewhere without a gap, and requests in flight finish
+// within the shutdown timeout
+func (l *listeners) run(ctx context.Context) error {
+	failed := make(chan error, 2)
+	if l.grpc != nil && l.grpcAddr != "" {
+		lis, err := net.Listen("tcp", l.grpcAddr)
+		if err != nil {
+			return err
+		}
+		log.Printf("Starting gRPC server on %s...", l.grpcAddr)
+		go func() { failed <- l.grpc.Serve(lis) }()
+	}
+	if l.grpc != nil && l.grpcAddr == "" {
+		log.Printf("Starting HTTP and gRPC servers on %s...", l.http.Addr)
+	} else {
+		log.Printf("Starting HTTP server on %s...", l.http.Addr)
+	}
+	go func() { failed <- l.http.ListenAndServe() }()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Printf("Draining streams...")
+	case err = <-failed:
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+	if l.grpc != nil {
+		l.grpc.Shutdown(shutdownCtx)
+	}
+	if serr := l.http.Shutdown(shutdownCtx); serr != nil {
+		_ = l.http.Close()
+	}
+	if errors.Is(err, nethttp.ErrServerClosed) {
+		err = nil
+	}
+	return err
+}
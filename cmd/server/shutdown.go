@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/olyamironova/exchange-engine/internal/core"
+)
+
+// jobs runs the engine's background loops on a context cancelled at shutdown, so none is cut off in
+// the middle of a transaction when the process exits
+type jobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newJobs(parent context.Context) *jobs {
+	ctx, cancel := context.WithCancel(parent)
+	return &jobs{ctx: ctx, cancel: cancel}
+}
+
+func (j *jobs) run(fn func(ctx context.Context)) {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		fn(j.ctx)
+	}()
+}
+
+// stop cancels the loops and waits for them to return, at most until ctx is done
+func (j *jobs) stop(ctx context.Context) error {
+	j.cancel()
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown finishes what the listeners leave once they stopped: the background loops are stopped,
+// order operations still in flight, as from FIX sessions, commit, the book cache is rewritten from
+// the repository and stream subscribers are told the streams ended. Each step is bounded by ctx;
+// one that runs out of time is logged and the next goes ahead.
+func shutdown(ctx context.Context, engine *core.Engine, exchange core.Exchange, background *jobs) {
+	if err := background.stop(ctx); err != nil {
+		log.Printf("shutdown: background jobs still running: %v", err)
+	}
+	if err := engine.Drain(ctx); err != nil {
+		log.Printf("shutdown: order operations still running: %v", err)
+	}
+	if err := engine.FlushCache(ctx); err != nil {
+		log.Printf("shutdown: flushing the book cache: %v", err)
+	}
+	exchange.CloseStreams()
+}
@@ -0,0 +1,206 @@
+// Package breaker guards a port.Cache with a circuit breaker: once the cache keeps failing or timing
+// out, calls stop reaching it and fail at once, so the engine serves books from the repository
+// without waiting on the cache, until a probe finds it healthy again. Books whose update or
+// invalidation was lost meanwhile are invalidated before the cache serves them again.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+var _ port.Cache = (*Cache)(nil)
+
+// ErrOpen is returned by calls the open breaker kept from the cache
+var ErrOpen = errors.New("cache circuit breaker is open")
+
+type State string
+
+const (
+	Closed   State = "CLOSED"    // calls reach the cache
+	Open     State = "OPEN"      // calls fail with ErrOpen until the cooldown ends
+	HalfOpen State = "HALF_OPEN" // one probe call reaches the cache, the others fail with ErrOpen
+)
+
+// Config tunes the breaker; zero fields take the defaults noted
+type Config struct {
+	// Failures is how many consecutive failed calls open the breaker; 5 by default
+	Failures int
+	// Cooldown is how long the breaker stays open before probing the cache; 10s by default
+	Cooldown time.Duration
+	// Timeout fails a call the cache has not answered in time, so a slow cache counts as a failing
+	// one; 0 leaves calls to the caller's deadline
+	Timeout time.Duration
+	// OnStateChange, if not nil, is called on every transition, outside the breaker's lock
+	OnStateChange func(from, to State)
+}
+
+// Stats is the breaker's state and its counters since it was created
+type Stats struct {
+	State               State
+	ConsecutiveFailures int
+	Trips               int64 // times the breaker opened
+	Rejected            int64 // calls failed with ErrOpen
+	Failures            int64 // calls the cache failed
+	Since               time.Time
+}
+
+// Cache passes calls to the wrapped cache while the breaker is closed
+type Cache struct {
+	next port.Cache
+	cfg  Config
+
+	mu      sync.Mutex
+	stats   Stats
+	probing bool
+	stale   map[string]bool // tenant-scoped symbols whose cached book missed a write
+}
+
+func NewCache(next port.Cache, cfg Config) *Cache {
+	if cfg.Failures <= 0 {
+		cfg.Failures = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 10 * time.Second
+	}
+	return &Cache{next: next, cfg: cfg, stats: Stats{State: Closed, Since: time.Now()}, stale: make(map[string]bool)}
+}
+
+// Stats returns the breaker's current state and counters
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// allow reports whether a call may reach the cache, turning an open breaker whose cooldown is over
+// half open with the caller as its probe
+func (c *Cache) allow() (bool, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.stats.State {
+	case Closed:
+		return true, nil
+	case Open:
+		if time.Since(c.stats.Since) >= c.cfg.Cooldown {
+			c.probing = true
+			return true, c.setState(HalfOpen)
+		}
+	case HalfOpen:
+		if !c.probing {
+			c.probing = true
+			return true, nil
+		}
+	}
+	c.stats.Rejected++
+	return false, nil
+}
+
+// done records the outcome of a call that reached the cache
+func (c *Cache) done(err error) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probing = false
+	if errors.Is(err, context.Canceled) {
+		// a caller giving up says nothing about the cache
+		return nil
+	}
+	if err == nil {
+		c.stats.ConsecutiveFailures = 0
+		if c.stats.State != Closed {
+			return c.setState(Closed)
+		}
+		return nil
+	}
+	c.stats.Failures++
+	c.stats.ConsecutiveFailures++
+	if c.stats.State == HalfOpen || c.stats.ConsecutiveFailures >= c.cfg.Failures {
+		c.stats.Trips++
+		return c.setState(Open)
+	}
+	return nil
+}
+
+// setState moves to s under the lock and returns the notification to send once it is released
+func (c *Cache) setState(s State) func() {
+	from := c.stats.State
+	c.stats.State, c.stats.Since = s, time.Now()
+	if c.cfg.OnStateChange == nil || from == s {
+		return nil
+	}
+	return func() { c.cfg.OnStateChange(from, s) }
+}
+
+func notify(fn func()) {
+	if fn != nil {
+		fn()
+	}
+}
+
+// call runs fn against the cache if the breaker allows it, bounded by the configured timeout
+func call[T any](ctx context.Context, c *Cache, fn func(context.Context) (T, error)) (T, error) {
+	ok, changed := c.allow()
+	notify(changed)
+	if !ok {
+		var zero T
+		return zero, ErrOpen
+	}
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+	v, err := fn(ctx)
+	notify(c.done(err))
+	return v, err
+}
+
+func do(ctx context.Context, c *Cache, fn func(context.Context) error) error {
+	_, err := call(ctx, c, func(ctx context.Context) (struct{}, error) { return struct{}{}, fn(ctx) })
+	return err
+}
+
+// written records whether the symbol's cached book took its latest write
+func (c *Cache) written(ctx context.Context, symbol string, err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.stale[tenant.Scope(ctx, symbol)] = true
+	} else {
+		delete(c.stale, tenant.Scope(ctx, symbol))
+	}
+	return err
+}
+
+func (c *Cache) SetOrderbook(ctx context.Context, symbol string, ob *domain.OrderbookSnapshot) error {
+	return c.written(ctx, symbol, do(ctx, c, func(ctx context.Context) error { return c.next.SetOrderbook(ctx, symbol, ob) }))
+}
+
+// GetOrderbook reports a miss for a book that missed a write, once its cached copy is dropped
+func (c *Cache) GetOrderbook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	c.mu.Lock()
+	stale := c.stale[tenant.Scope(ctx, symbol)]
+	c.mu.Unlock()
+	if stale {
+		return nil, c.Invalidate(ctx, symbol)
+	}
+	return call(ctx, c, func(ctx context.Context) (*domain.OrderbookSnapshot, error) { return c.next.GetOrderbook(ctx, symbol) })
+}
+
+func (c *Cache) Invalidate(ctx context.Context, symbol string) error {
+	return c.written(ctx, symbol, do(ctx, c, func(ctx context.Context) error { return c.next.Invalidate(ctx, symbol) }))
+}
+
+func (c *Cache) SetSnapshot(ctx context.Context, snapshotID string, data []byte, ttl time.Duration) error {
+	return do(ctx, c, func(ctx context.Context) error { return c.next.SetSnapshot(ctx, snapshotID, data, ttl) })
+}
+
+func (c *Cache) GetSnapshot(ctx context.Context, snapshotID string) ([]byte, error) {
+	return call(ctx, c, func(ctx context.Context) ([]byte, error) { return c.next.GetSnapshot(ctx, snapshotID) })
+}
@@ -0,0 +1,183 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+)
+
+var errDown = errors.New("cache down")
+
+// flakyCache holds books in a map and fails every call while down, slowing each by delay
+type flakyCache struct {
+	port.Cache
+
+	mu    sync.Mutex
+	down  bool
+	delay time.Duration
+	calls int
+	books map[string]*domain.OrderbookSnapshot
+}
+
+func (f *flakyCache) enter(ctx context.Context) error {
+	f.mu.Lock()
+	f.calls++
+	down, delay := f.down, f.delay
+	f.mu.Unlock()
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if down {
+		return errDown
+	}
+	return nil
+}
+
+func (f *flakyCache) set(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *flakyCache) SetOrderbook(ctx context.Context, symbol string, ob *domain.OrderbookSnapshot) error {
+	if err := f.enter(ctx); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.books[symbol] = ob
+	return nil
+}
+
+func (f *flakyCache) GetOrderbook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	if err := f.enter(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.books[symbol], nil
+}
+
+func (f *flakyCache) Invalidate(ctx context.Context, symbol string) error {
+	if err := f.enter(ctx); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.books, symbol)
+	return nil
+}
+
+func newFlaky() *flakyCache {
+	return &flakyCache{books: make(map[string]*domain.OrderbookSnapshot)}
+}
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	next := newFlaky()
+	var changes []State
+	c := NewCache(next, Config{Failures: 3, Cooldown: 20 * time.Millisecond, OnStateChange: func(_, to State) {
+		changes = append(changes, to)
+	}})
+
+	next.set(true)
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetOrderbook(ctx, "BTC/USD"); !errors.Is(err, errDown) {
+			t.Fatalf("call %d: %v, want the cache's error", i, err)
+		}
+	}
+	if st := c.Stats(); st.State != Open || st.Trips != 1 {
+		t.Fatalf("after 3 failures: %+v, want open after one trip", st)
+	}
+	calls := next.calls
+	if _, err := c.GetOrderbook(ctx, "BTC/USD"); !errors.Is(err, ErrOpen) {
+		t.Fatalf("open breaker: %v, want ErrOpen", err)
+	}
+	if next.calls != calls {
+		t.Error("open breaker let a call through")
+	}
+
+	// the probe after the cooldown fails and opens the breaker again
+	time.Sleep(25 * time.Millisecond)
+	if _, err := c.GetOrderbook(ctx, "BTC/USD"); !errors.Is(err, errDown) {
+		t.Fatalf("failed probe: %v", err)
+	}
+	if st := c.Stats(); st.State != Open || st.Trips != 2 {
+		t.Fatalf("after a failed probe: %+v, want open after two trips", st)
+	}
+
+	next.set(false)
+	time.Sleep(25 * time.Millisecond)
+	if _, err := c.GetOrderbook(ctx, "BTC/USD"); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if st := c.Stats(); st.State != Closed || st.ConsecutiveFailures != 0 || st.Rejected != 1 {
+		t.Fatalf("after a good probe: %+v, want closed", st)
+	}
+	want := []State{Open, HalfOpen, Open, HalfOpen, Closed}
+	if len(changes) != len(want) {
+		t.Fatalf("transitions %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("transitions %v, want %v", changes, want)
+		}
+	}
+}
+
+func TestSlowCacheCountsAsFailing(t *testing.T) {
+	next := newFlaky()
+	next.delay = time.Second
+	c := NewCache(next, Config{Failures: 1, Timeout: 10 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := c.GetOrderbook(context.Background(), "BTC/USD"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("slow call: %v, want deadline exceeded", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("slow call was not cut short")
+	}
+	if st := c.Stats(); st.State != Open {
+		t.Fatalf("after a timeout: %+v, want open", st)
+	}
+}
+
+func TestBookMissingAWriteIsNotServed(t *testing.T) {
+	ctx := context.Background()
+	next := newFlaky()
+	c := NewCache(next, Config{Failures: 1, Cooldown: 10 * time.Millisecond})
+	old := &domain.OrderbookSnapshot{Symbol: "BTC/USD"}
+	if err := c.SetOrderbook(ctx, "BTC/USD", old); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	// the book changes while the cache is unreachable, so its invalidation is lost
+	next.set(true)
+	_ = c.Invalidate(ctx, "BTC/USD")
+	next.set(false)
+	time.Sleep(15 * time.Millisecond)
+
+	ob, err := c.GetOrderbook(ctx, "BTC/USD")
+	if err != nil || ob != nil {
+		t.Fatalf("stale book served: %v, %v, want a miss", ob, err)
+	}
+	if _, ok := next.books["BTC/USD"]; ok {
+		t.Error("stale book left in the cache")
+	}
+	fresh := &domain.OrderbookSnapshot{Symbol: "BTC/USD"}
+	if err := c.SetOrderbook(ctx, "BTC/USD", fresh); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if ob, _ := c.GetOrderbook(ctx, "BTC/USD"); ob != fresh {
+		t.Errorf("got %v, want the fresh book", ob)
+	}
+}
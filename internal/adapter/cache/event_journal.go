@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	eventJournalMaxLen = 100000
+	// eventJournalPage is how many entries EventsAfter reads per round trip while filtering by client
+	eventJournalPage = 512
+)
+
+// journalKey holds every order event of the tenant; per-client reads filter it
+func journalKey(ctx context.Context) string {
+	return "events:" + tenant.From(ctx)
+}
+
+func (c *RedisCache) AppendEvents(ctx context.Context, evs []*domain.OrderEvent) error {
+	if len(evs) == 0 {
+		return nil
+	}
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(evs))
+	for i, ev := range evs {
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		cmds[i] = pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: journalKey(ctx),
+			MaxLen: eventJournalMaxLen,
+			Approx: true,
+			Values: map[string]interface{}{"client": ev.ClientID, "data": b},
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	for i, cmd := range cmds {
+		evs[i].Seq = cmd.Val()
+	}
+	return nil
+}
+
+func (c *RedisCache) EventsAfter(ctx context.Context, clientID, afterSeq string, limit int64) ([]*domain.OrderEvent, error) {
+	var out []*domain.OrderEvent
+	for int64(len(out)) < limit {
+		start := "-"
+		if afterSeq != "" {
+			start = "(" + afterSeq
+		}
+		msgs, err := c.client.XRangeN(ctx, journalKey(ctx), start, "+", eventJournalPage).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			afterSeq = m.ID
+			if clientID != "*" && m.Values["client"] != clientID {
+				continue
+			}
+			raw, ok := m.Values["data"].(string)
+			if !ok {
+				return nil, errors.New("malformed event journal entry " + m.ID)
+			}
+			var ev domain.OrderEvent
+			if err := json.Unmarshal([]byte(raw), &ev); err != nil {
+				return nil, err
+			}
+			ev.Seq = m.ID
+			out = append(out, &ev)
+			if int64(len(out)) == limit {
+				break
+			}
+		}
+		if len(msgs) < eventJournalPage {
+			break
+		}
+	}
+	return out, nil
+}
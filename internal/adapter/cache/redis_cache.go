@@ -7,12 +7,37 @@ import (
 	"time"
 
 	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
 	"github.com/redis/go-redis/v9"
 )
 
+var (
+	_ port.Cache           = (*RedisCache)(nil)
+	_ port.TradeTape       = (*RedisCache)(nil)
+	_ port.EventJournal    = (*RedisCache)(nil)
+	_ port.SnapshotCatalog = (*RedisCache)(nil)
+	_ port.TopOfBookStore  = (*RedisCache)(nil)
+)
+
 type RedisCache struct {
-	client *redis.Client
-	ttl    time.Duration
+	client   *redis.Client
+	ttl      time.Duration
+	policies map[string]domain.CachePolicy
+}
+
+// SetSymbolPolicies makes books of the listed symbols live for their policy's TTL instead of the
+// default one; call it before the cache is used
+func (c *RedisCache) SetSymbolPolicies(policies map[string]domain.CachePolicy) {
+	c.policies = policies
+}
+
+// bookTTL is how long the symbol's book stays cached
+func (c *RedisCache) bookTTL(symbol string) time.Duration {
+	if p, ok := c.policies[symbol]; ok && p.TTL > 0 {
+		return p.TTL
+	}
+	return c.ttl
 }
 
 func NewRedisCache(addr string, password string, db int, ttl time.Duration) *RedisCache {
@@ -27,17 +52,21 @@ func NewRedisCache(addr string, password string, db int, ttl time.Duration) *Red
 	}
 }
 
-func key(symbol string) string { return "ob:" + symbol }
+func key(ctx context.Context, symbol string) string { return "ob:" + tenant.Scope(ctx, symbol) }
 func (c *RedisCache) SetOrderbook(ctx context.Context, symbol string, ob *domain.OrderbookSnapshot) error {
 	b, err := json.Marshal(ob)
 	if err != nil {
 		return err
 	}
-	return c.client.Set(ctx, key(symbol), b, c.ttl).Err()
+	return c.client.Set(ctx, key(ctx, symbol), b, c.bookTTL(symbol)).Err()
 }
 
 func (c *RedisCache) GetOrderbook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
-	b, err := c.client.Get(ctx, key(symbol)).Bytes()
+	b, err := c.client.Get(ctx, key(ctx, symbol)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// a miss is not a failure
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -49,15 +78,19 @@ func (c *RedisCache) GetOrderbook(ctx context.Context, symbol string) (*domain.O
 }
 
 func (c *RedisCache) Invalidate(ctx context.Context, symbol string) error {
-	return c.client.Del(ctx, key(symbol)).Err()
+	return c.client.Del(ctx, key(ctx, symbol)).Err()
+}
+
+func snapshotKey(ctx context.Context, snapshotID string) string {
+	return "snapshot:" + tenant.Scope(ctx, snapshotID)
 }
 
 func (r *RedisCache) SetSnapshot(ctx context.Context, snapshotID string, data []byte, ttl time.Duration) error {
-	return r.client.Set(ctx, "snapshot:"+snapshotID, data, ttl).Err()
+	return r.client.Set(ctx, snapshotKey(ctx, snapshotID), data, ttl).Err()
 }
 
 func (r *RedisCache) GetSnapshot(ctx context.Context, snapshotID string) ([]byte, error) {
-	res, err := r.client.Get(ctx, "snapshot:"+snapshotID).Bytes()
+	res, err := r.client.Get(ctx, snapshotKey(ctx, snapshotID)).Bytes()
 	if errors.Is(err, redis.Nil) {
 		return nil, nil
 	}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/redis/go-redis/v9"
+)
+
+// snapshot metadata lives next to the snapshot with the same ttl; a sorted set per symbol,
+// scored by sequence, indexes it and is pruned lazily when listing finds expired entries
+func snapshotMetaKey(ctx context.Context, snapshotID string) string {
+	return "snapshot-meta:" + tenant.Scope(ctx, snapshotID)
+}
+
+func snapshotIndexKey(ctx context.Context, symbol string) string {
+	return "snapshots:" + tenant.Scope(ctx, symbol)
+}
+
+func snapshotSeqKey(ctx context.Context, symbol string) string {
+	return "snapshot-seq:" + tenant.Scope(ctx, symbol)
+}
+
+func (c *RedisCache) AddSnapshot(ctx context.Context, meta *domain.SnapshotMeta, ttl time.Duration) error {
+	seq, err := c.client.Incr(ctx, snapshotSeqKey(ctx, meta.Symbol)).Result()
+	if err != nil {
+		return err
+	}
+	meta.Sequence = seq
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	index := snapshotIndexKey(ctx, meta.Symbol)
+	pipe := c.client.TxPipeline()
+	pipe.Set(ctx, snapshotMetaKey(ctx, meta.ID), b, ttl)
+	pipe.ZAdd(ctx, index, redis.Z{Score: float64(seq), Member: meta.ID})
+	pipe.Expire(ctx, index, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *RedisCache) ListSnapshots(ctx context.Context, symbol string, beforeSeq, limit int64) ([]domain.SnapshotMeta, error) {
+	index := snapshotIndexKey(ctx, symbol)
+	rng := &redis.ZRangeBy{Min: "-inf", Max: "+inf"}
+	if beforeSeq > 0 {
+		rng.Max = "(" + strconv.FormatInt(beforeSeq, 10)
+	}
+	if limit > 0 {
+		rng.Count = limit
+	}
+	ids, err := c.client.ZRevRangeByScore(ctx, index, rng).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = snapshotMetaKey(ctx, id)
+	}
+	vals, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.SnapshotMeta, 0, len(ids))
+	var expired []interface{}
+	for i, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+		var m domain.SnapshotMeta
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	if len(expired) > 0 {
+		_ = c.client.ZRem(ctx, index, expired...).Err()
+	}
+	return out, nil
+}
+
+func (c *RedisCache) GetSnapshotMeta(ctx context.Context, snapshotID string) (*domain.SnapshotMeta, error) {
+	b, err := c.client.Get(ctx, snapshotMetaKey(ctx, snapshotID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m domain.SnapshotMeta
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (c *RedisCache) DeleteSnapshot(ctx context.Context, snapshotID string) error {
+	meta, err := c.GetSnapshotMeta(ctx, snapshotID)
+	if err != nil {
+		return err
+	}
+	pipe := c.client.TxPipeline()
+	pipe.Del(ctx, snapshotKey(ctx, snapshotID), snapshotMetaKey(ctx, snapshotID))
+	if meta != nil {
+		pipe.ZRem(ctx, snapshotIndexKey(ctx, meta.Symbol), snapshotID)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
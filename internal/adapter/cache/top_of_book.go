@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/redis/go-redis/v9"
+)
+
+func topKey(ctx context.Context, symbol string) string {
+	return "tob:" + tenant.Scope(ctx, symbol)
+}
+
+// setTopIfNewer replaces the stored top unless it is fresher than the new one. Sequences are
+// zero-padded to the same width, so comparing them as strings orders them without losing precision
+// to Lua's numbers.
+var setTopIfNewer = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], 'seq')
+if cur and cur > ARGV[1] then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'seq', ARGV[1], 'top', ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+func (c *RedisCache) SetTopOfBook(ctx context.Context, symbol string, top *domain.OrderbookSnapshot) error {
+	b, err := json.Marshal(top)
+	if err != nil {
+		return err
+	}
+	seq := fmt.Sprintf("%020d", top.Sequence)
+	return setTopIfNewer.Run(ctx, c.client, []string{topKey(ctx, symbol)}, seq, b, c.bookTTL(symbol).Milliseconds()).Err()
+}
+
+func (c *RedisCache) GetTopOfBook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	b, err := c.client.HGet(ctx, topKey(ctx, symbol), "top").Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var top domain.OrderbookSnapshot
+	if err := json.Unmarshal(b, &top); err != nil {
+		return nil, err
+	}
+	return &top, nil
+}
+
+func (c *RedisCache) InvalidateTopOfBook(ctx context.Context, symbol string) error {
+	return c.client.Del(ctx, topKey(ctx, symbol)).Err()
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/redis/go-redis/v9"
+)
+
+const tradeTapeMaxLen = 10000
+
+func tapeKey(ctx context.Context, symbol string) string {
+	return "trades:" + tenant.Scope(ctx, symbol)
+}
+
+func (c *RedisCache) AppendTrades(ctx context.Context, symbol string, trades []*domain.Trade) ([]string, error) {
+	if len(trades) == 0 {
+		return nil, nil
+	}
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(trades))
+	for i, t := range trades {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		cmds[i] = pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: tapeKey(ctx, symbol),
+			MaxLen: tradeTapeMaxLen,
+			Approx: true,
+			Values: map[string]interface{}{"data": b},
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	seqs := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		seqs[i] = cmd.Val()
+	}
+	return seqs, nil
+}
+
+// RecentTrades returns up to limit latest trades, newest first
+func (c *RedisCache) RecentTrades(ctx context.Context, symbol string, limit int64) ([]*domain.Trade, error) {
+	msgs, err := c.client.XRevRangeN(ctx, tapeKey(ctx, symbol), "+", "-", limit).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := decodeTape(msgs)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*domain.Trade, len(entries))
+	for i, e := range entries {
+		out[i] = e.Trade
+	}
+	return out, nil
+}
+
+func (c *RedisCache) TradesBefore(ctx context.Context, symbol, beforeSeq string, limit int64) ([]domain.TapeEntry, error) {
+	end := "+"
+	if beforeSeq != "" {
+		end = "(" + beforeSeq
+	}
+	msgs, err := c.client.XRevRangeN(ctx, tapeKey(ctx, symbol), end, "-", limit).Result()
+	if err != nil {
+		return nil, err
+	}
+	return decodeTape(msgs)
+}
+
+// TradesAfter returns trades strictly after afterSeq in tape order; an empty afterSeq reads from the start
+func (c *RedisCache) TradesAfter(ctx context.Context, symbol, afterSeq string, limit int64) ([]domain.TapeEntry, error) {
+	start := "-"
+	if afterSeq != "" {
+		start = "(" + afterSeq
+	}
+	msgs, err := c.client.XRangeN(ctx, tapeKey(ctx, symbol), start, "+", limit).Result()
+	if err != nil {
+		return nil, err
+	}
+	return decodeTape(msgs)
+}
+
+// EnsureTradeGroup creates a consumer group on the symbol's tape starting from new entries
+func (c *RedisCache) EnsureTradeGroup(ctx context.Context, symbol, group string) error {
+	err := c.client.XGroupCreateMkStream(ctx, tapeKey(ctx, symbol), group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// ReadTradeGroup delivers entries not yet delivered to the group; block of zero waits forever
+func (c *RedisCache) ReadTradeGroup(ctx context.Context, symbol, group, consumer string, count int64, block time.Duration) ([]domain.TapeEntry, error) {
+	res, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{tapeKey(ctx, symbol), ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return decodeTape(res[0].Messages)
+}
+
+func (c *RedisCache) AckTrades(ctx context.Context, symbol, group string, seqs ...string) error {
+	return c.client.XAck(ctx, tapeKey(ctx, symbol), group, seqs...).Err()
+}
+
+func decodeTape(msgs []redis.XMessage) ([]domain.TapeEntry, error) {
+	out := make([]domain.TapeEntry, 0, len(msgs))
+	for _, m := range msgs {
+		raw, ok := m.Values["data"].(string)
+		if !ok {
+			return nil, errors.New("malformed trade tape entry " + m.ID)
+		}
+		var t domain.Trade
+		if err := json.Unmarshal([]byte(raw), &t); err != nil {
+			return nil, err
+		}
+		out = append(out, domain.TapeEntry{Seq: m.ID, Trade: &t})
+	}
+	return out, nil
+}
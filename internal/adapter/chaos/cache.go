@@ -0,0 +1,42 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+)
+
+var _ port.Cache = (*Cache)(nil)
+
+// Cache passes every call to the wrapped cache under the injector's faults, operations named
+// "Cache.<method>"
+type Cache struct {
+	next port.Cache
+	in   *Injector
+}
+
+func NewCache(next port.Cache, in *Injector) *Cache {
+	return &Cache{next: next, in: in}
+}
+
+func (c *Cache) SetOrderbook(ctx context.Context, symbol string, ob *domain.OrderbookSnapshot) error {
+	return c.in.do(ctx, "Cache.SetOrderbook", func() error { return c.next.SetOrderbook(ctx, symbol, ob) })
+}
+
+func (c *Cache) GetOrderbook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	return call(ctx, c.in, "Cache.GetOrderbook", func() (*domain.OrderbookSnapshot, error) { return c.next.GetOrderbook(ctx, symbol) })
+}
+
+func (c *Cache) Invalidate(ctx context.Context, symbol string) error {
+	return c.in.do(ctx, "Cache.Invalidate", func() error { return c.next.Invalidate(ctx, symbol) })
+}
+
+func (c *Cache) SetSnapshot(ctx context.Context, snapshotID string, data []byte, ttl time.Duration) error {
+	return c.in.do(ctx, "Cache.SetSnapshot", func() error { return c.next.SetSnapshot(ctx, snapshotID, data, ttl) })
+}
+
+func (c *Cache) GetSnapshot(ctx context.Context, snapshotID string) ([]byte, error) {
+	return call(ctx, c.in, "Cache.GetSnapshot", func() ([]byte, error) { return c.next.GetSnapshot(ctx, snapshotID) })
+}
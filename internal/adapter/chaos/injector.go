@@ -0,0 +1,138 @@
+// Package chaos wraps adapters in decorators that inject latency and failures on chosen calls, so the
+// engine's failure handling can be exercised deterministically: a seeded injector fails the same calls
+// on every run.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjected is returned by calls failed by a rule without an error of its own
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Any matches every operation
+const Any = "*"
+
+// Rule injects a fault into the calls of one operation, named "Repository.SaveOrder", "Tx.Commit",
+// "Cache.SetOrderbook" and so on after the wrapped method
+type Rule struct {
+	Op string
+	// Latency delays the call, or fails it with the context's error if the context ends first
+	Latency time.Duration
+	// Err fails the call; ErrInjected if nil and the rule is not latency only
+	Err error
+	// LatencyOnly delays the call without failing it
+	LatencyOnly bool
+	// AfterCall lets the wrapped call complete before failing it: the write happened but the caller
+	// is told it did not, as when a commit's acknowledgement is lost
+	AfterCall bool
+	// Skip lets that many matching calls through before the rule fires
+	Skip int
+	// Times stops the rule after firing that many times; 0 never stops it
+	Times int
+	// Probability fires the rule on that share of matching calls; 0 fires on all of them
+	Probability float64
+
+	seen, fired int
+}
+
+// Injector decides which calls fail. It is safe for concurrent use.
+type Injector struct {
+	mu    sync.Mutex
+	rnd   *rand.Rand
+	rules []*Rule
+	calls map[string]int
+}
+
+// NewInjector returns an injector applying rules in order; seed fixes the outcome of probabilistic rules
+func NewInjector(seed int64, rules ...Rule) *Injector {
+	in := &Injector{rnd: rand.New(rand.NewSource(seed)), calls: make(map[string]int)}
+	in.Set(rules...)
+	return in
+}
+
+// Set replaces the rules, e.g. to heal the adapter once a test has seen it fail
+func (in *Injector) Set(rules ...Rule) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.rules = in.rules[:0]
+	for _, r := range rules {
+		r.seen, r.fired = 0, 0
+		in.rules = append(in.rules, &r)
+	}
+}
+
+// Calls returns how many times the operation was called, failed or not
+func (in *Injector) Calls(op string) int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.calls[op]
+}
+
+// fault is what happens to one call
+type fault struct {
+	latency   time.Duration
+	err       error
+	afterCall bool
+}
+
+func (in *Injector) next(op string) fault {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.calls[op]++
+	var f fault
+	for _, r := range in.rules {
+		if r.Op != op && r.Op != Any {
+			continue
+		}
+		r.seen++
+		if r.seen <= r.Skip || (r.Times > 0 && r.fired >= r.Times) {
+			continue
+		}
+		if r.Probability > 0 && in.rnd.Float64() >= r.Probability {
+			continue
+		}
+		r.fired++
+		f.latency += r.Latency
+		if !r.LatencyOnly && f.err == nil {
+			f.err, f.afterCall = r.Err, r.AfterCall
+			if f.err == nil {
+				f.err = ErrInjected
+			}
+		}
+	}
+	return f
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// do runs call under the faults drawn for op
+func (in *Injector) do(ctx context.Context, op string, call func() error) error {
+	f := in.next(op)
+	if err := wait(ctx, f.latency); err != nil {
+		return err
+	}
+	if f.err != nil && !f.afterCall {
+		return f.err
+	}
+	if err := call(); err != nil {
+		return err
+	}
+	return f.err
+}
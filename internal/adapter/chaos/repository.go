@@ -0,0 +1,347 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	_ port.Repository = (*Repository)(nil)
+	_ port.Tx         = (*Tx)(nil)
+)
+
+// Repository passes every call to the wrapped repository under the injector's faults. Transactions
+// it begins are wrapped too, their operations named "Tx.<method>".
+type Repository struct {
+	next port.Repository
+	in   *Injector
+}
+
+func NewRepository(next port.Repository, in *Injector) *Repository {
+	return &Repository{next: next, in: in}
+}
+
+// call runs fn under the faults of op; a call failed after it ran returns the zero value
+func call[T any](ctx context.Context, in *Injector, op string, fn func() (T, error)) (T, error) {
+	var out T
+	err := in.do(ctx, op, func() error {
+		var err error
+		out, err = fn()
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return out, nil
+}
+
+func (r *Repository) BeginTx(ctx context.Context) (port.Tx, error) {
+	tx, err := call(ctx, r.in, "Repository.BeginTx", func() (port.Tx, error) { return r.next.BeginTx(ctx) })
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{next: tx, in: r.in}, nil
+}
+
+func (r *Repository) SaveOrder(ctx context.Context, o *domain.Order) error {
+	return r.in.do(ctx, "Repository.SaveOrder", func() error { return r.next.SaveOrder(ctx, o) })
+}
+
+func (r *Repository) SaveTrade(ctx context.Context, t *domain.Trade) error {
+	return r.in.do(ctx, "Repository.SaveTrade", func() error { return r.next.SaveTrade(ctx, t) })
+}
+
+func (r *Repository) LoadOpenOrders(ctx context.Context, symbol string) ([]*domain.Order, error) {
+	return call(ctx, r.in, "Repository.LoadOpenOrders", func() ([]*domain.Order, error) { return r.next.LoadOpenOrders(ctx, symbol) })
+}
+
+func (r *Repository) CancelOrder(ctx context.Context, orderID, clientID string) error {
+	return r.in.do(ctx, "Repository.CancelOrder", func() error { return r.next.CancelOrder(ctx, orderID, clientID) })
+}
+
+func (r *Repository) CancelOrders(ctx context.Context, clientID string, orderIDs []string) (map[string]string, error) {
+	return call(ctx, r.in, "Repository.CancelOrders", func() (map[string]string, error) {
+		return r.next.CancelOrders(ctx, clientID, orderIDs)
+	})
+}
+
+func (r *Repository) CancelOpenOrders(ctx context.Context, clientID, symbol string, side domain.Side) (map[string]string, error) {
+	return call(ctx, r.in, "Repository.CancelOpenOrders", func() (map[string]string, error) {
+		return r.next.CancelOpenOrders(ctx, clientID, symbol, side)
+	})
+}
+
+func (r *Repository) CancelSessionOrders(ctx context.Context, clientID, sessionID, symbol string) (map[string]string, error) {
+	return call(ctx, r.in, "Repository.CancelSessionOrders", func() (map[string]string, error) {
+		return r.next.CancelSessionOrders(ctx, clientID, sessionID, symbol)
+	})
+}
+
+func (r *Repository) CancelGroupOrders(ctx context.Context, clientID, groupID, symbol string) (map[string]string, error) {
+	return call(ctx, r.in, "Repository.CancelGroupOrders", func() (map[string]string, error) {
+		return r.next.CancelGroupOrders(ctx, clientID, groupID, symbol)
+	})
+}
+
+func (r *Repository) ModifyOrder(ctx context.Context, orderID, clientID string, price, qty decimal.Decimal) error {
+	return r.in.do(ctx, "Repository.ModifyOrder", func() error { return r.next.ModifyOrder(ctx, orderID, clientID, price, qty) })
+}
+
+func (r *Repository) LoadSnapshot(ctx context.Context, id string) (*domain.OrderbookSnapshot, error) {
+	return call(ctx, r.in, "Repository.LoadSnapshot", func() (*domain.OrderbookSnapshot, error) { return r.next.LoadSnapshot(ctx, id) })
+}
+
+func (r *Repository) LoadOrderByIDForClient(ctx context.Context, orderID, clientID string) (*domain.Order, error) {
+	return call(ctx, r.in, "Repository.LoadOrderByIDForClient", func() (*domain.Order, error) {
+		return r.next.LoadOrderByIDForClient(ctx, orderID, clientID)
+	})
+}
+
+func (r *Repository) LoadOrderByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	return call(ctx, r.in, "Repository.LoadOrderByID", func() (*domain.Order, error) { return r.next.LoadOrderByID(ctx, orderID) })
+}
+
+func (r *Repository) LoadOrderByClientOrderID(ctx context.Context, clientID, clientOrderID string) (*domain.Order, error) {
+	return call(ctx, r.in, "Repository.LoadOrderByClientOrderID", func() (*domain.Order, error) {
+		return r.next.LoadOrderByClientOrderID(ctx, clientID, clientOrderID)
+	})
+}
+
+func (r *Repository) LoadTradeByID(ctx context.Context, tradeID string) (*domain.Trade, error) {
+	return call(ctx, r.in, "Repository.LoadTradeByID", func() (*domain.Trade, error) { return r.next.LoadTradeByID(ctx, tradeID) })
+}
+
+func (r *Repository) LoadTopOfBook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	return call(ctx, r.in, "Repository.LoadTopOfBook", func() (*domain.OrderbookSnapshot, error) { return r.next.LoadTopOfBook(ctx, symbol) })
+}
+
+func (r *Repository) LoadTriggerOrders(ctx context.Context, symbol string, crossed *domain.PriceRange) ([]*domain.Order, error) {
+	return call(ctx, r.in, "Repository.LoadTriggerOrders", func() ([]*domain.Order, error) {
+		return r.next.LoadTriggerOrders(ctx, symbol, crossed)
+	})
+}
+
+func (r *Repository) LoadTradesForOrder(ctx context.Context, orderID string, after *page.Key, limit int) ([]*domain.Trade, error) {
+	return call(ctx, r.in, "Repository.LoadTradesForOrder", func() ([]*domain.Trade, error) {
+		return r.next.LoadTradesForOrder(ctx, orderID, after, limit)
+	})
+}
+
+func (r *Repository) LoadTrades(ctx context.Context, symbol string, from, to time.Time, after *page.Key, limit int) ([]*domain.Trade, error) {
+	return call(ctx, r.in, "Repository.LoadTrades", func() ([]*domain.Trade, error) {
+		return r.next.LoadTrades(ctx, symbol, from, to, after, limit)
+	})
+}
+
+func (r *Repository) LoadNotificationPreferences(ctx context.Context, clientID string) ([]domain.NotificationPreference, error) {
+	return call(ctx, r.in, "Repository.LoadNotificationPreferences", func() ([]domain.NotificationPreference, error) {
+		return r.next.LoadNotificationPreferences(ctx, clientID)
+	})
+}
+
+func (r *Repository) SaveNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	return r.in.do(ctx, "Repository.SaveNotificationPreference", func() error { return r.next.SaveNotificationPreference(ctx, p) })
+}
+
+func (r *Repository) DeleteNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	return r.in.do(ctx, "Repository.DeleteNotificationPreference", func() error { return r.next.DeleteNotificationPreference(ctx, p) })
+}
+
+func (r *Repository) ListSymbols(ctx context.Context) ([]string, error) {
+	return call(ctx, r.in, "Repository.ListSymbols", func() ([]string, error) { return r.next.ListSymbols(ctx) })
+}
+
+func (r *Repository) LoadOrderFills(ctx context.Context, symbol string) ([]domain.OrderFill, error) {
+	return call(ctx, r.in, "Repository.LoadOrderFills", func() ([]domain.OrderFill, error) { return r.next.LoadOrderFills(ctx, symbol) })
+}
+
+func (r *Repository) ListOrders(ctx context.Context, f domain.OrderFilter, after *page.Key, limit int) ([]*domain.Order, error) {
+	return call(ctx, r.in, "Repository.ListOrders", func() ([]*domain.Order, error) {
+		return r.next.ListOrders(ctx, f, after, limit)
+	})
+}
+
+func (r *Repository) ScanOrders(ctx context.Context, f domain.ExportFilter, fn func(*domain.Order) error) error {
+	return r.in.do(ctx, "Repository.ScanOrders", func() error { return r.next.ScanOrders(ctx, f, fn) })
+}
+
+func (r *Repository) ScanTrades(ctx context.Context, f domain.ExportFilter, fn func(*domain.Trade) error) error {
+	return r.in.do(ctx, "Repository.ScanTrades", func() error { return r.next.ScanTrades(ctx, f, fn) })
+}
+
+func (r *Repository) ArchiveOrders(ctx context.Context, before time.Time, limit int) (int, error) {
+	return call(ctx, r.in, "Repository.ArchiveOrders", func() (int, error) { return r.next.ArchiveOrders(ctx, before, limit) })
+}
+
+func (r *Repository) PurgeArchive(ctx context.Context, before time.Time) (int, int, error) {
+	var orders, trades int
+	err := r.in.do(ctx, "Repository.PurgeArchive", func() error {
+		var err error
+		orders, trades, err = r.next.PurgeArchive(ctx, before)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return orders, trades, nil
+}
+
+func (r *Repository) SaveDailyReport(ctx context.Context, day time.Time) error {
+	return r.in.do(ctx, "Repository.SaveDailyReport", func() error { return r.next.SaveDailyReport(ctx, day) })
+}
+
+func (r *Repository) LoadDailyReport(ctx context.Context, day time.Time) (*domain.DailyReport, error) {
+	return call(ctx, r.in, "Repository.LoadDailyReport", func() (*domain.DailyReport, error) { return r.next.LoadDailyReport(ctx, day) })
+}
+
+func (r *Repository) LoadClientFills(ctx context.Context, clientID string, from, to time.Time) ([]domain.StatementFill, error) {
+	return call(ctx, r.in, "Repository.LoadClientFills", func() ([]domain.StatementFill, error) {
+		return r.next.LoadClientFills(ctx, clientID, from, to)
+	})
+}
+
+func (r *Repository) SaveDelisting(ctx context.Context, d domain.Delisting) error {
+	return r.in.do(ctx, "Repository.SaveDelisting", func() error { return r.next.SaveDelisting(ctx, d) })
+}
+
+func (r *Repository) DeleteDelisting(ctx context.Context, symbol string) error {
+	return r.in.do(ctx, "Repository.DeleteDelisting", func() error { return r.next.DeleteDelisting(ctx, symbol) })
+}
+
+func (r *Repository) LoadDelistings(ctx context.Context) ([]domain.Delisting, error) {
+	return call(ctx, r.in, "Repository.LoadDelistings", func() ([]domain.Delisting, error) { return r.next.LoadDelistings(ctx) })
+}
+
+func (r *Repository) SaveInstrument(ctx context.Context, in domain.Instrument) error {
+	return r.in.do(ctx, "Repository.SaveInstrument", func() error { return r.next.SaveInstrument(ctx, in) })
+}
+
+func (r *Repository) LoadInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return call(ctx, r.in, "Repository.LoadInstruments", func() ([]domain.Instrument, error) { return r.next.LoadInstruments(ctx) })
+}
+
+func (r *Repository) CompleteDelisting(ctx context.Context, symbol string, at time.Time) ([]*domain.Order, error) {
+	return call(ctx, r.in, "Repository.CompleteDelisting", func() ([]*domain.Order, error) {
+		return r.next.CompleteDelisting(ctx, symbol, at)
+	})
+}
+
+func (r *Repository) SaveAlerts(ctx context.Context, alerts []domain.SurveillanceAlert) error {
+	return r.in.do(ctx, "Repository.SaveAlerts", func() error { return r.next.SaveAlerts(ctx, alerts) })
+}
+
+func (r *Repository) LoadAlerts(ctx context.Context, f domain.AlertFilter, after *page.Key, limit int) ([]domain.SurveillanceAlert, error) {
+	return call(ctx, r.in, "Repository.LoadAlerts", func() ([]domain.SurveillanceAlert, error) {
+		return r.next.LoadAlerts(ctx, f, after, limit)
+	})
+}
+
+func (r *Repository) ReviewAlert(ctx context.Context, id string, status domain.AlertStatus, reviewer, note string, at time.Time) error {
+	return r.in.do(ctx, "Repository.ReviewAlert", func() error { return r.next.ReviewAlert(ctx, id, status, reviewer, note, at) })
+}
+
+func (r *Repository) SaveTransitions(ctx context.Context, ts []domain.OrderTransition) error {
+	return r.in.do(ctx, "Repository.SaveTransitions", func() error { return r.next.SaveTransitions(ctx, ts) })
+}
+
+func (r *Repository) ScanBookChanges(ctx context.Context, f domain.ExportFilter, fn func(domain.BookChange) error) error {
+	return r.in.do(ctx, "Repository.ScanBookChanges", func() error { return r.next.ScanBookChanges(ctx, f, fn) })
+}
+
+func (r *Repository) LoadTransitions(ctx context.Context, orderID string) ([]domain.OrderTransition, error) {
+	return call(ctx, r.in, "Repository.LoadTransitions", func() ([]domain.OrderTransition, error) {
+		return r.next.LoadTransitions(ctx, orderID)
+	})
+}
+
+func (r *Repository) MergeCandles(ctx context.Context, candles []domain.Candle) error {
+	return r.in.do(ctx, "Repository.MergeCandles", func() error { return r.next.MergeCandles(ctx, candles) })
+}
+
+func (r *Repository) LoadCandles(ctx context.Context, symbol string, interval domain.CandleInterval, from, to time.Time, limit int) ([]domain.Candle, error) {
+	return call(ctx, r.in, "Repository.LoadCandles", func() ([]domain.Candle, error) {
+		return r.next.LoadCandles(ctx, symbol, interval, from, to, limit)
+	})
+}
+
+func (r *Repository) ScanLedger(ctx context.Context, symbol string, afterSeq uint64, fn func(domain.LedgerEvent) error) error {
+	return r.in.do(ctx, "Repository.ScanLedger", func() error { return r.next.ScanLedger(ctx, symbol, afterSeq, fn) })
+}
+
+func (r *Repository) LoadAccounts(ctx context.Context, clientID string) ([]domain.Account, error) {
+	return call(ctx, r.in, "Repository.LoadAccounts", func() ([]domain.Account, error) { return r.next.LoadAccounts(ctx, clientID) })
+}
+
+// Tx injects faults into a transaction. A commit failed before it ran leaves the wrapped transaction
+// open for the caller's rollback, as a failed commit would.
+type Tx struct {
+	next port.Tx
+	in   *Injector
+}
+
+func (t *Tx) SaveOrder(ctx context.Context, o *domain.Order) error {
+	return t.in.do(ctx, "Tx.SaveOrder", func() error { return t.next.SaveOrder(ctx, o) })
+}
+
+func (t *Tx) SaveTrade(ctx context.Context, tr *domain.Trade) error {
+	return t.in.do(ctx, "Tx.SaveTrade", func() error { return t.next.SaveTrade(ctx, tr) })
+}
+
+func (t *Tx) CancelOrder(ctx context.Context, orderID, clientID string) error {
+	return t.in.do(ctx, "Tx.CancelOrder", func() error { return t.next.CancelOrder(ctx, orderID, clientID) })
+}
+
+func (t *Tx) ModifyOrder(ctx context.Context, orderID, clientID string, price, qty *decimal.Decimal, at time.Time) error {
+	return t.in.do(ctx, "Tx.ModifyOrder", func() error { return t.next.ModifyOrder(ctx, orderID, clientID, price, qty, at) })
+}
+
+func (t *Tx) LoadOrderByIDForClient(ctx context.Context, orderID, clientID string) (*domain.Order, error) {
+	return call(ctx, t.in, "Tx.LoadOrderByIDForClient", func() (*domain.Order, error) {
+		return t.next.LoadOrderByIDForClient(ctx, orderID, clientID)
+	})
+}
+
+func (t *Tx) LockSymbol(ctx context.Context, symbol string) error {
+	return t.in.do(ctx, "Tx.LockSymbol", func() error { return t.next.LockSymbol(ctx, symbol) })
+}
+
+func (t *Tx) LoadCandidatesForMatch(ctx context.Context, symbol string, side domain.Side, limitPrice *decimal.Decimal, after *domain.BookKey, limit int) ([]*domain.Order, error) {
+	return call(ctx, t.in, "Tx.LoadCandidatesForMatch", func() ([]*domain.Order, error) {
+		return t.next.LoadCandidatesForMatch(ctx, symbol, side, limitPrice, after, limit)
+	})
+}
+
+func (t *Tx) AppendLedger(ctx context.Context, evs []domain.LedgerEvent) error {
+	return t.in.do(ctx, "Tx.AppendLedger", func() error { return t.next.AppendLedger(ctx, evs) })
+}
+
+func (t *Tx) AdjustAccount(ctx context.Context, clientID, asset string, available, locked decimal.Decimal) error {
+	return t.in.do(ctx, "Tx.AdjustAccount", func() error { return t.next.AdjustAccount(ctx, clientID, asset, available, locked) })
+}
+
+func (t *Tx) LoadHold(ctx context.Context, orderID string) (*domain.Hold, error) {
+	return call(ctx, t.in, "Tx.LoadHold", func() (*domain.Hold, error) { return t.next.LoadHold(ctx, orderID) })
+}
+
+func (t *Tx) SaveHold(ctx context.Context, h domain.Hold) error {
+	return t.in.do(ctx, "Tx.SaveHold", func() error { return t.next.SaveHold(ctx, h) })
+}
+
+func (t *Tx) Commit(ctx context.Context) error {
+	return t.in.do(ctx, "Tx.Commit", func() error { return t.next.Commit(ctx) })
+}
+
+// Rollback always reaches the wrapped transaction, so an injected fault never leaks its locks
+func (t *Tx) Rollback(ctx context.Context) error {
+	err := t.next.Rollback(ctx)
+	if ierr := t.in.do(ctx, "Tx.Rollback", func() error { return nil }); ierr != nil {
+		return ierr
+	}
+	return err
+}
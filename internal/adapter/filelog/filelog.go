@@ -0,0 +1,195 @@
+// Package filelog is a port.Repository for durable single-node deployments without a database: the
+// memory repository with every committed change to orders and trades appended to a log file before
+// it is applied. Opening the log replays it; compaction rewrites it as the current state.
+//
+// Only orders, trades, the ledger and account balances are logged. Notification preferences, daily
+// reports, delistings, instruments, surveillance alerts, order histories and candles live in memory
+// and start empty after a restart.
+package filelog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/port"
+)
+
+var _ port.Repository = (*Repository)(nil)
+
+// Repository serves reads and transactions from memory and makes each commit durable in the log,
+// one JSON batch per line, synced before the commit returns
+type Repository struct {
+	*memory.Repository
+	path string
+
+	mu       sync.Mutex // guards the file; taken under the memory repository's lock
+	f        *os.File
+	appended int // batches appended since the last compaction
+}
+
+// Open replays the log at path, which need not exist yet, and appends to it from then on
+func Open(path string) (*Repository, error) {
+	mem := memory.NewRepository()
+	good, err := replay(path, mem)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	// a torn last line is a write that failed before its commit returned; it is dropped
+	if err := f.Truncate(good); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(good, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	r := &Repository{Repository: mem, path: path, f: f}
+	mem.OnCommit(r.append)
+	return r, nil
+}
+
+// replay applies the batches of the log to mem and returns the length of its complete lines
+func replay(path string, mem *memory.Repository) (int64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	rd := bufio.NewReader(f)
+	var good int64
+	for n := 1; ; n++ {
+		line, err := rd.ReadBytes('\n')
+		if err == io.EOF {
+			return good, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		var b memory.Batch
+		if err := json.Unmarshal(line, &b); err != nil {
+			return 0, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		mem.Restore(b)
+		good += int64(len(line))
+	}
+}
+
+func (r *Repository) append(b memory.Batch) error {
+	line, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := r.f.Sync(); err != nil {
+		return err
+	}
+	r.appended++
+	return nil
+}
+
+// Compact replaces the log with a single batch of the current orders and trades. Commits wait
+// while it runs.
+func (r *Repository) Compact() error {
+	return r.Repository.Snapshot(func(state memory.Batch) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		line, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		tmp := r.path + ".tmp"
+		if err := writeSynced(tmp, append(line, '\n')); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, r.path); err != nil {
+			return err
+		}
+		if err := syncDir(filepath.Dir(r.path)); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		r.f.Close()
+		r.f, r.appended = f, 0
+		return nil
+	})
+}
+
+func writeSynced(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir makes a rename in dir durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// RunCompaction compacts the log each interval in which something was appended until ctx is done,
+// passing failures to report if it is not nil
+func (r *Repository) RunCompaction(ctx context.Context, interval time.Duration, report func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		r.mu.Lock()
+		idle := r.appended == 0
+		r.mu.Unlock()
+		if idle {
+			continue
+		}
+		if err := r.Compact(); err != nil && report != nil {
+			report(err)
+		}
+	}
+}
+
+// Close closes the log; the repository must not be written to afterwards
+func (r *Repository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
@@ -0,0 +1,130 @@
+package filelog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/port/porttest"
+	"github.com/shopspring/decimal"
+)
+
+func open(t *testing.T, path string) *Repository {
+	t.Helper()
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestRepositoryConformance(t *testing.T) {
+	porttest.TestRepository(t, func(t *testing.T) port.Repository {
+		return open(t, filepath.Join(t.TempDir(), "exchange.log"))
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "exchange.log")
+	r := open(t, path)
+	at := time.Now().UTC().Add(-time.Hour)
+	save := func(id string, side domain.Side, status domain.OrderStatus) *domain.Order {
+		t.Helper()
+		o := &domain.Order{ID: id, ClientID: "c", Symbol: "BTC/USD", Side: side, Type: domain.Limit, Price: decimal.NewFromInt(100),
+			Quantity: decimal.NewFromInt(2), Remaining: decimal.NewFromInt(2), Status: status, CreatedAt: at.Add(123), UpdatedAt: at.Add(456),
+			Tags: []string{"alpha"}}
+		if status == domain.Filled {
+			o.Remaining = decimal.Zero
+		}
+		if err := r.SaveOrder(ctx, o); err != nil {
+			t.Fatalf("save %s: %v", id, err)
+		}
+		return o
+	}
+	resting := save("resting", domain.Buy, domain.Open)
+	save("cancelled", domain.Buy, domain.Open)
+	filled := save("filled", domain.Sell, domain.Filled)
+	if err := r.CancelOrder(ctx, "cancelled", "c"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	tr := &domain.Trade{ID: "t1", Symbol: "BTC/USD", BuyOrder: resting.ID, SellOrder: filled.ID, Price: decimal.NewFromInt(100),
+		Quantity: decimal.NewFromInt(2), Timestamp: at.Add(789), Sequence: 7}
+	if err := r.SaveTrade(ctx, tr); err != nil {
+		t.Fatalf("save trade: %v", err)
+	}
+
+	check := func(what string, r *Repository) {
+		t.Helper()
+		got, err := r.LoadOrderByID(ctx, "resting")
+		if err != nil || got.Status != domain.Open || !got.CreatedAt.Equal(resting.CreatedAt) || !got.Price.Equal(resting.Price) ||
+			len(got.Tags) != 1 {
+			t.Errorf("%s: resting order %+v, %v", what, got, err)
+		}
+		if got, err := r.LoadOrderByID(ctx, "cancelled"); err != nil || got.Status != domain.Cancelled {
+			t.Errorf("%s: cancelled order %+v, %v", what, got, err)
+		}
+		trades, err := r.LoadTradesForOrder(ctx, resting.ID, nil, 0)
+		if err != nil || len(trades) != 1 || trades[0].Sequence != 7 || !trades[0].Timestamp.Equal(tr.Timestamp) {
+			t.Errorf("%s: trades %+v, %v", what, trades, err)
+		}
+		book, err := r.LoadOpenOrders(ctx, "BTC/USD")
+		if err != nil || len(book) != 1 {
+			t.Errorf("%s: book %+v, %v", what, book, err)
+		}
+	}
+	r.Close()
+	check("replayed", open(t, path))
+
+	compacted := open(t, path)
+	if err := compacted.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	check("compacted", compacted)
+	if err := compacted.CancelOrder(ctx, "resting", "c"); err != nil {
+		t.Fatalf("cancel after compaction: %v", err)
+	}
+	compacted.Close()
+	if got, _ := open(t, path).LoadOrderByID(ctx, "resting"); got == nil || got.Status != domain.Cancelled {
+		t.Errorf("cancel after compaction read back as %+v", got)
+	}
+}
+
+func TestTornTailIsDropped(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "exchange.log")
+	r := open(t, path)
+	o := &domain.Order{ID: "o", ClientID: "c", Symbol: "BTC/USD", Side: domain.Buy, Type: domain.Limit, Price: decimal.NewFromInt(1),
+		Quantity: decimal.NewFromInt(1), Remaining: decimal.NewFromInt(1), Status: domain.Open, CreatedAt: time.Now().UTC()}
+	if err := r.SaveOrder(ctx, o); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	r.Close()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"orders":[{"tenant":"","order":{"ID":"torn"`)
+	f.Close()
+
+	r = open(t, path)
+	if _, err := r.LoadOrderByID(ctx, "o"); err != nil {
+		t.Errorf("committed order lost: %v", err)
+	}
+	if _, err := r.LoadOrderByID(ctx, "torn"); err == nil {
+		t.Error("torn write was applied")
+	}
+	o.ID = "after"
+	if err := r.SaveOrder(ctx, o); err != nil {
+		t.Fatalf("save after torn tail: %v", err)
+	}
+	r.Close()
+	if _, err := open(t, path).LoadOrderByID(ctx, "after"); err != nil {
+		t.Errorf("order written after the torn tail lost: %v", err)
+	}
+}
@@ -0,0 +1,408 @@
+// Package kafka is a minimal Kafka producer. It finds partition leaders with Metadata requests and
+// writes uncompressed record batches with Produce requests (Kafka 0.11 and later), choosing the
+// partition of a keyed message like the Java client's default partitioner, so consumers of other
+// producers see the same placement. It speaks plaintext only, without TLS or SASL.
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/port"
+)
+
+const (
+	apiProduce  = 0
+	apiMetadata = 3
+
+	produceVersion  = 3
+	metadataVersion = 1
+)
+
+// Config addresses a Kafka cluster
+type Config struct {
+	// Brokers are the bootstrap host:port addresses; the rest of the cluster is discovered
+	Brokers []string
+	// ClientID names the producer in the brokers' logs and quotas; empty means "exchange-engine"
+	ClientID string
+	// LeaderAck acknowledges a write once the partition leader has it, instead of every in-sync replica
+	LeaderAck bool
+	// Timeout bounds each request; zero means ten seconds
+	Timeout time.Duration
+}
+
+var _ port.EventPublisher = (*Producer)(nil)
+
+// Producer publishes to Kafka topics. Requests are sent one at a time.
+type Producer struct {
+	cfg Config
+
+	mu      sync.Mutex
+	corr    int32
+	conns   map[string]*conn   // by broker address
+	brokers map[int32]string   // node ID -> address, from metadata
+	leaders map[string][]int32 // topic -> leader node ID of each partition, -1 while it has none
+	next    int                // round robin of messages without a key
+}
+
+type conn struct {
+	c net.Conn
+	r *bufio.Reader
+}
+
+func NewProducer(cfg Config) *Producer {
+	if cfg.ClientID == "" {
+		cfg.ClientID = "exchange-engine"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Producer{
+		cfg:     cfg,
+		conns:   make(map[string]*conn),
+		brokers: make(map[int32]string),
+		leaders: make(map[string][]int32),
+	}
+}
+
+// Publish writes msgs to the partitions of topic, one Produce request per partition leader. A
+// failure may leave the messages of some partitions written; the cached leaders of the topic are
+// then dropped, so a retry finds where the partitions moved.
+func (p *Producer) Publish(ctx context.Context, topic string, msgs []port.BrokerMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	leaders, err := p.partitions(ctx, topic)
+	if err != nil {
+		return err
+	}
+	byPartition := make(map[int32][]port.BrokerMessage)
+	for _, m := range msgs {
+		part := p.partition(m.Key, len(leaders))
+		byPartition[part] = append(byPartition[part], m)
+	}
+	byLeader := make(map[int32][]int32)
+	for part := range byPartition {
+		leader := leaders[part]
+		if leader < 0 {
+			delete(p.leaders, topic)
+			return fmt.Errorf("kafka: partition %s/%d has no leader", topic, part)
+		}
+		byLeader[leader] = append(byLeader[leader], part)
+	}
+	now := time.Now()
+	for leader, parts := range byLeader {
+		if err := p.produce(ctx, leader, topic, parts, byPartition, now); err != nil {
+			delete(p.leaders, topic)
+			return err
+		}
+	}
+	return nil
+}
+
+// partition places a message the way the Java client does: murmur2 of the key, round robin without one
+func (p *Producer) partition(key string, n int) int32 {
+	if key == "" {
+		p.next++
+		return int32(p.next % n)
+	}
+	return int32(int(uint32(murmur2([]byte(key))&0x7fffffff)) % n)
+}
+
+func (p *Producer) produce(ctx context.Context, leader int32, topic string, parts []int32, msgs map[int32][]port.BrokerMessage, now time.Time) error {
+	addr, ok := p.brokers[leader]
+	if !ok {
+		return fmt.Errorf("kafka: unknown broker %d", leader)
+	}
+	acks := int16(-1)
+	if p.cfg.LeaderAck {
+		acks = 1
+	}
+	var e encoder
+	e.int16(-1) // no transactional ID
+	e.int16(acks)
+	e.int32(int32(p.cfg.Timeout / time.Millisecond))
+	e.int32(1)
+	e.string(topic)
+	e.int32(int32(len(parts)))
+	for _, part := range parts {
+		e.int32(part)
+		e.bytes(recordBatch(msgs[part], now))
+	}
+	resp, err := p.request(ctx, addr, apiProduce, produceVersion, e.b)
+	if err != nil {
+		return err
+	}
+
+	d := decoder{b: resp}
+	for range d.arrayLen() {
+		name := d.string()
+		for range d.arrayLen() {
+			part, code := d.int32(), d.int16()
+			d.int64() // base offset
+			d.int64() // log append time
+			if d.err == nil && code != 0 {
+				return fmt.Errorf("kafka: produce to %s/%d: %s", name, part, errorName(code))
+			}
+		}
+	}
+	return d.err
+}
+
+// partitions returns the leader of each partition of topic, asking the cluster when they are not cached
+func (p *Producer) partitions(ctx context.Context, topic string) ([]int32, error) {
+	if leaders, ok := p.leaders[topic]; ok {
+		return leaders, nil
+	}
+	var e encoder
+	e.int32(1)
+	e.string(topic)
+	var (
+		resp []byte
+		err  error
+	)
+	for _, addr := range p.bootstrap() {
+		if resp, err = p.request(ctx, addr, apiMetadata, metadataVersion, e.b); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d := decoder{b: resp}
+	for range d.arrayLen() {
+		node, host, portNum := d.int32(), d.string(), d.int32()
+		d.nullableString() // rack
+		p.brokers[node] = net.JoinHostPort(host, strconv.Itoa(int(portNum)))
+	}
+	d.int32() // controller
+	var leaders []int32
+	for range d.arrayLen() {
+		code, name := d.int16(), d.string()
+		d.int8() // internal
+		n := d.arrayLen()
+		if d.err == nil && name == topic && code != 0 {
+			return nil, fmt.Errorf("kafka: topic %s: %s", topic, errorName(code))
+		}
+		parts := make([]int32, n)
+		for range n {
+			d.int16() // partition error, e.g. no leader: reported when the partition is written
+			part, leader := d.int32(), d.int32()
+			d.skipInt32s() // replicas
+			d.skipInt32s() // in-sync replicas
+			if part >= 0 && int(part) < n {
+				parts[part] = leader
+			}
+		}
+		if name == topic {
+			leaders = parts
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(leaders) == 0 {
+		return nil, fmt.Errorf("kafka: topic %s has no partitions", topic)
+	}
+	p.leaders[topic] = leaders
+	return leaders, nil
+}
+
+// bootstrap lists the addresses to ask for metadata: the configured brokers, then the discovered ones
+func (p *Producer) bootstrap() []string {
+	addrs := append([]string(nil), p.cfg.Brokers...)
+	for _, addr := range p.brokers {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// request sends one request to the broker at addr and returns the body of its response. A failed
+// connection is closed and dialled again by the next request.
+func (p *Producer) request(ctx context.Context, addr string, apiKey, version int16, body []byte) ([]byte, error) {
+	c, err := p.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.roundTrip(ctx, c, apiKey, version, body)
+	if err != nil {
+		c.c.Close()
+		delete(p.conns, addr)
+		return nil, fmt.Errorf("kafka: %s: %w", addr, err)
+	}
+	return resp, nil
+}
+
+func (p *Producer) dial(ctx context.Context, addr string) (*conn, error) {
+	if c, ok := p.conns[addr]; ok {
+		return c, nil
+	}
+	d := net.Dialer{Timeout: p.cfg.Timeout}
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: %w", err)
+	}
+	c := &conn{c: nc, r: bufio.NewReader(nc)}
+	p.conns[addr] = c
+	return c, nil
+}
+
+func (p *Producer) roundTrip(ctx context.Context, c *conn, apiKey, version int16, body []byte) ([]byte, error) {
+	deadline := time.Now().Add(p.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.c.SetDeadline(deadline)
+
+	p.corr++
+	var e encoder
+	e.int32(0) // size, set below
+	e.int16(apiKey)
+	e.int16(version)
+	e.int32(p.corr)
+	e.string(p.cfg.ClientID)
+	e.b = append(e.b, body...)
+	binary.BigEndian.PutUint32(e.b, uint32(len(e.b)-4))
+	if _, err := c.c.Write(e.b); err != nil {
+		return nil, err
+	}
+
+	var head [8]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return nil, err
+	}
+	size := int32(binary.BigEndian.Uint32(head[:4]))
+	if size < 4 || size > 64<<20 {
+		return nil, fmt.Errorf("bad response size %d", size)
+	}
+	if corr := int32(binary.BigEndian.Uint32(head[4:])); corr != p.corr {
+		return nil, fmt.Errorf("response %d to request %d", corr, p.corr)
+	}
+	resp := make([]byte, size-4)
+	if _, err := io.ReadFull(c.r, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for addr, c := range p.conns {
+		errs = append(errs, c.c.Close())
+		delete(p.conns, addr)
+	}
+	return errors.Join(errs...)
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// recordBatch encodes msgs as an uncompressed record batch, format version 2
+func recordBatch(msgs []port.BrokerMessage, now time.Time) []byte {
+	var records []byte
+	for i, m := range msgs {
+		r := []byte{0}                       // attributes
+		r = binary.AppendVarint(r, 0)        // timestamp delta
+		r = binary.AppendVarint(r, int64(i)) // offset delta
+		if m.Key == "" {
+			r = binary.AppendVarint(r, -1)
+		} else {
+			r = binary.AppendVarint(r, int64(len(m.Key)))
+			r = append(r, m.Key...)
+		}
+		r = binary.AppendVarint(r, int64(len(m.Value)))
+		r = append(r, m.Value...)
+		r = binary.AppendVarint(r, 0) // headers
+		records = binary.AppendVarint(records, int64(len(r)))
+		records = append(records, r...)
+	}
+
+	// the CRC covers the batch from its attributes on
+	var tail encoder
+	tail.int16(0) // attributes: no compression, create time
+	tail.int32(int32(len(msgs) - 1))
+	tail.int64(now.UnixMilli())
+	tail.int64(now.UnixMilli())
+	tail.int64(-1) // producer ID
+	tail.int16(-1) // producer epoch
+	tail.int32(-1) // base sequence
+	tail.int32(int32(len(msgs)))
+	tail.b = append(tail.b, records...)
+
+	var e encoder
+	e.int64(0) // base offset
+	e.int32(int32(4 + 1 + 4 + len(tail.b)))
+	e.int32(-1) // partition leader epoch
+	e.int8(2)   // magic
+	e.int32(int32(crc32.Checksum(tail.b, castagnoli)))
+	e.b = append(e.b, tail.b...)
+	return e.b
+}
+
+// murmur2 is the hash of the Java client's default partitioner
+func murmur2(data []byte) uint32 {
+	const (
+		seed = 0x9747b28c
+		m    = 0x5bd1e995
+		r    = 24
+	)
+	h := uint32(seed) ^ uint32(len(data))
+	n := len(data) / 4
+	for i := range n {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := data[n*4:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
+
+// errorNames are the codes a producer commonly meets
+var errorNames = map[int16]string{
+	2:  "corrupt message",
+	3:  "unknown topic or partition",
+	5:  "leader not available",
+	6:  "not leader for partition",
+	7:  "request timed out",
+	10: "message too large",
+	19: "not enough replicas",
+	20: "not enough replicas after append",
+	29: "topic authorization failed",
+}
+
+func errorName(code int16) string {
+	if name, ok := errorNames[code]; ok {
+		return name
+	}
+	return "error code " + strconv.Itoa(int(code))
+}
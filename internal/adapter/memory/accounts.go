@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+// accountKey identifies a client's account of one asset
+type accountKey struct {
+	tenant, client, asset string
+}
+
+// accountDelta is what a transaction adds to an account
+type accountDelta struct {
+	available, locked decimal.Decimal
+}
+
+// account returns the committed account, zero if it was never opened; the caller holds mu
+func (r *Repository) account(k accountKey) domain.Account {
+	if rec, ok := r.accounts[k]; ok {
+		return rec.Account
+	}
+	return domain.Account{ClientID: k.client, Asset: k.asset}
+}
+
+func (r *Repository) LoadAccounts(ctx context.Context, clientID string) ([]domain.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.Account
+	for k, rec := range r.accounts {
+		if k.tenant == tenant.From(ctx) && k.client == clientID {
+			out = append(out, rec.Account)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Asset < out[j].Asset })
+	return out, nil
+}
+
+// AdjustAccount stages the deltas. Deltas of concurrent transactions add up, so accounts are not
+// locked; Commit checks the balances again and reports a conflict if another transaction spent them.
+func (t *Tx) AdjustAccount(ctx context.Context, clientID, asset string, available, locked decimal.Decimal) error {
+	k := accountKey{tenant: tenant.From(ctx), client: clientID, asset: asset}
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	d := t.accounts[k]
+	d.available, d.locked = d.available.Add(available), d.locked.Add(locked)
+	acc := t.r.account(k)
+	if acc.Available.Add(d.available).IsNegative() || acc.Locked.Add(d.locked).IsNegative() {
+		return domain.ErrInsufficientFunds
+	}
+	t.accounts[k] = d
+	return nil
+}
+
+// LoadHold returns the hold as this transaction sees it. Holds are written only under the lock of
+// their order's row.
+func (t *Tx) LoadHold(ctx context.Context, orderID string) (*domain.Hold, error) {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	rec, ok := t.hold(orderID)
+	if !ok || rec.Tenant != tenant.From(ctx) {
+		return nil, nil
+	}
+	h := rec.Hold
+	return &h, nil
+}
+
+func (t *Tx) SaveHold(ctx context.Context, h domain.Hold) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	t.holds[h.OrderID] = HoldRecord{Tenant: tenant.From(ctx), Hold: h}
+	return nil
+}
+
+// hold returns a live hold as this transaction sees it; the caller holds mu
+func (t *Tx) hold(orderID string) (HoldRecord, bool) {
+	rec, ok := t.holds[orderID]
+	if !ok {
+		rec, ok = t.r.holds[orderID]
+	}
+	return rec, ok && rec.Hold.Amount.IsPositive()
+}
+
+// releaseHold stages the return of the order's hold to the available balance; the caller holds mu
+func (t *Tx) releaseHold(orderID string) {
+	rec, ok := t.hold(orderID)
+	if !ok {
+		return
+	}
+	k := accountKey{tenant: rec.Tenant, client: rec.Hold.ClientID, asset: rec.Hold.Asset}
+	d := t.accounts[k]
+	d.available, d.locked = d.available.Add(rec.Hold.Amount), d.locked.Sub(rec.Hold.Amount)
+	t.accounts[k] = d
+	rec.Hold.Amount = decimal.Zero
+	t.holds[orderID] = rec
+}
+
+// settleAccounts adds the staged deltas to the committed balances, reporting false if one would go
+// below zero; the caller holds mu
+func (t *Tx) settleAccounts(now time.Time) ([]AccountRecord, bool) {
+	out := make([]AccountRecord, 0, len(t.accounts))
+	for k, d := range t.accounts {
+		acc := t.r.account(k)
+		acc.Available, acc.Locked, acc.UpdatedAt = acc.Available.Add(d.available), acc.Locked.Add(d.locked), now
+		if acc.Available.IsNegative() || acc.Locked.IsNegative() {
+			return nil, false
+		}
+		out = append(out, AccountRecord{Tenant: k.tenant, Account: acc})
+	}
+	return out, true
+}
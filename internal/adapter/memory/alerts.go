@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+func (r *Repository) SaveAlerts(ctx context.Context, alerts []domain.SurveillanceAlert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range alerts {
+		key := tenant.Scope(ctx, a.ID)
+		if _, ok := r.alerts[key]; ok {
+			continue
+		}
+		a.ClientIDs, a.TradeIDs = slices.Clone(a.ClientIDs), slices.Clone(a.TradeIDs)
+		r.alerts[key] = a
+	}
+	return nil
+}
+
+func (r *Repository) LoadAlerts(ctx context.Context, f domain.AlertFilter, after *page.Key, limit int) ([]domain.SurveillanceAlert, error) {
+	r.mu.Lock()
+	var out []domain.SurveillanceAlert
+	for key, a := range r.alerts {
+		if t, _ := tenant.Split(key); t != tenant.From(ctx) || !f.Match(&a) {
+			continue
+		}
+		if after != nil && !keyAfter(a.At, a.ID, *after) {
+			continue
+		}
+		out = append(out, a)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].At.Equal(out[j].At) {
+			return out[i].At.Before(out[j].At)
+		}
+		return out[i].ID < out[j].ID
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *Repository) ReviewAlert(ctx context.Context, id string, status domain.AlertStatus, reviewer, note string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := tenant.Scope(ctx, id)
+	a, ok := r.alerts[key]
+	if !ok {
+		return errors.New("alert not found")
+	}
+	a.Status, a.ReviewedBy, a.ReviewNote, a.ReviewedAt = status, reviewer, note, &at
+	r.alerts[key] = a
+	return nil
+}
@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// bookKey names one tenant's symbol
+type bookKey struct {
+	tenant, symbol string
+}
+
+// books indexes the resting orders of every tenant's symbol by price level. Commits update it under
+// the repository's lock; book reads take only the locks of the index and of the one book.
+type books struct {
+	mu       sync.RWMutex
+	bySymbol map[bookKey]*book
+}
+
+// book is a symbol's resting orders: each side a list of price levels, best first, and each level a
+// FIFO queue of its orders, oldest first
+type book struct {
+	mu    sync.RWMutex
+	bids  bookSide
+	asks  bookSide
+	where map[string]*domain.Order // order ID -> its entry in a queue
+}
+
+type bookSide struct {
+	side   domain.Side
+	prices []decimal.Decimal // best first
+	levels map[string]*priceLevel
+}
+
+type priceLevel struct {
+	price  decimal.Decimal
+	orders []*domain.Order
+}
+
+func newBooks() *books {
+	return &books{bySymbol: make(map[bookKey]*book)}
+}
+
+// get returns the symbol's book, nil if no order ever rested on it
+func (bs *books) get(tenantID, symbol string) *book {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	return bs.bySymbol[bookKey{tenant: tenantID, symbol: symbol}]
+}
+
+// put files the committed order in its book: as the entry of a resting order, or by dropping the
+// entry of one that stopped resting
+func (bs *books) put(tenantID string, o domain.Order) {
+	b := bs.get(tenantID, o.Symbol)
+	if b == nil {
+		if !resting(o.Status) {
+			return
+		}
+		bs.mu.Lock()
+		key := bookKey{tenant: tenantID, symbol: o.Symbol}
+		if b = bs.bySymbol[key]; b == nil {
+			b = &book{
+				bids:  bookSide{side: domain.Buy, levels: make(map[string]*priceLevel)},
+				asks:  bookSide{side: domain.Sell, levels: make(map[string]*priceLevel)},
+				where: make(map[string]*domain.Order),
+			}
+			bs.bySymbol[key] = b
+		}
+		bs.mu.Unlock()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if prev, ok := b.where[o.ID]; ok {
+		b.side(prev.Side).remove(prev)
+		delete(b.where, o.ID)
+	}
+	if resting(o.Status) {
+		entry := &o
+		b.side(o.Side).add(entry)
+		b.where[o.ID] = entry
+	}
+}
+
+// remove drops the order's entry, if it has one
+func (bs *books) remove(tenantID, symbol, orderID string) {
+	b := bs.get(tenantID, symbol)
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if prev, ok := b.where[orderID]; ok {
+		b.side(prev.Side).remove(prev)
+		delete(b.where, orderID)
+	}
+}
+
+func (b *book) side(s domain.Side) *bookSide {
+	if s == domain.Buy {
+		return &b.bids
+	}
+	return &b.asks
+}
+
+// better reports whether price a comes before price b on the side
+func (s *bookSide) better(a, b decimal.Decimal) bool {
+	if s.side == domain.Buy {
+		return a.GreaterThan(b)
+	}
+	return a.LessThan(b)
+}
+
+func (s *bookSide) add(o *domain.Order) {
+	key := o.Price.String()
+	lvl, ok := s.levels[key]
+	if !ok {
+		lvl = &priceLevel{price: o.Price}
+		s.levels[key] = lvl
+		i := sort.Search(len(s.prices), func(i int) bool { return !s.better(s.prices[i], o.Price) })
+		s.prices = append(s.prices, decimal.Decimal{})
+		copy(s.prices[i+1:], s.prices[i:])
+		s.prices[i] = o.Price
+	}
+	// orders arrive in time order but for a replay, which may come in any order
+	i := len(lvl.orders)
+	for i > 0 && before(o, lvl.orders[i-1], s.side) {
+		i--
+	}
+	lvl.orders = append(lvl.orders, nil)
+	copy(lvl.orders[i+1:], lvl.orders[i:])
+	lvl.orders[i] = o
+}
+
+func (s *bookSide) remove(o *domain.Order) {
+	key := o.Price.String()
+	lvl, ok := s.levels[key]
+	if !ok {
+		return
+	}
+	for i, e := range lvl.orders {
+		if e == o {
+			lvl.orders = append(lvl.orders[:i], lvl.orders[i+1:]...)
+			break
+		}
+	}
+	if len(lvl.orders) > 0 {
+		return
+	}
+	delete(s.levels, key)
+	for i, p := range s.prices {
+		if p.Equal(lvl.price) {
+			s.prices = append(s.prices[:i], s.prices[i+1:]...)
+			break
+		}
+	}
+}
+
+// each calls fn with the side's orders in price-time priority until fn returns false; from, if set,
+// skips the levels better than it
+func (s *bookSide) each(from *decimal.Decimal, fn func(*domain.Order) bool) {
+	for _, p := range s.prices {
+		if from != nil && s.better(p, *from) {
+			continue
+		}
+		for _, o := range s.levels[p.String()].orders {
+			if !fn(o) {
+				return
+			}
+		}
+	}
+}
+
+// best returns copies of the orders at the side's best price, oldest first
+func (s *bookSide) best() []domain.Order {
+	if len(s.prices) == 0 {
+		return nil
+	}
+	lvl := s.levels[s.prices[0].String()]
+	out := make([]domain.Order, 0, len(lvl.orders))
+	for _, o := range lvl.orders {
+		out = append(out, *o)
+	}
+	return out
+}
+
+// orders returns copies of the book's resting orders
+func (b *book) orders() []*domain.Order {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make([]*domain.Order, 0, len(b.where))
+	for _, o := range b.where {
+		c := *o
+		out = append(out, &c)
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// candleSeries identifies the bars of one tenant's symbol at one interval
+type candleSeries struct {
+	tenant, symbol string
+	interval       domain.CandleInterval
+}
+
+func (r *Repository) MergeCandles(ctx context.Context, candles []domain.Candle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range candles {
+		key := candleSeries{tenant: tenant.From(ctx), symbol: c.Symbol, interval: c.Interval}
+		bars := r.candles[key]
+		i, found := slices.BinarySearchFunc(bars, c.Start, func(b domain.Candle, start time.Time) int {
+			return b.Start.Compare(start)
+		})
+		if found {
+			bars[i].Merge(c)
+			continue
+		}
+		r.candles[key] = slices.Insert(bars, i, c)
+	}
+	return nil
+}
+
+func (r *Repository) LoadCandles(ctx context.Context, symbol string, interval domain.CandleInterval, from, to time.Time, limit int) ([]domain.Candle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.Candle
+	for _, c := range r.candles[candleSeries{tenant: tenant.From(ctx), symbol: symbol, interval: interval}] {
+		if (!from.IsZero() && c.Start.Before(from)) || (!to.IsZero() && !c.Start.Before(to)) {
+			continue
+		}
+		out = append(out, c)
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return slices.Clone(out), nil
+}
@@ -0,0 +1,423 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+func within(at time.Time, f domain.ExportFilter) bool {
+	return (f.From.IsZero() || !at.Before(f.From)) && (f.To.IsZero() || at.Before(f.To))
+}
+
+func (r *Repository) ListOrders(ctx context.Context, f domain.OrderFilter, after *page.Key, limit int) ([]*domain.Order, error) {
+	r.mu.Lock()
+	var out []*domain.Order
+	for _, row := range r.orders {
+		o := row.order
+		if row.tenant != tenant.From(ctx) || !f.Match(&o) {
+			continue
+		}
+		if after != nil && !keyAfter(o.CreatedAt, o.ID, *after) {
+			continue
+		}
+		out = append(out, &o)
+	}
+	r.mu.Unlock()
+
+	byCreation(out)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// ScanOrders copies the matching orders before calling fn, so fn may use the repository
+func (r *Repository) ScanOrders(ctx context.Context, f domain.ExportFilter, fn func(*domain.Order) error) error {
+	r.mu.Lock()
+	var out []*domain.Order
+	for _, row := range r.orders {
+		o := row.order
+		if row.tenant == tenant.From(ctx) && (f.Symbol == "" || o.Symbol == f.Symbol) && within(o.CreatedAt, f) && domain.HasTag(o.Tags, f.Tag) {
+			out = append(out, &o)
+		}
+	}
+	r.mu.Unlock()
+
+	byCreation(out)
+	for _, o := range out {
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) LoadTrades(ctx context.Context, symbol string, from, to time.Time, after *page.Key, limit int) ([]*domain.Trade, error) {
+	span := domain.ExportFilter{From: from, To: to}
+	r.mu.Lock()
+	var out []*domain.Trade
+	for _, row := range r.trades {
+		t := row.trade
+		if row.tenant != tenant.From(ctx) || t.Symbol != symbol || !within(t.Timestamp, span) {
+			continue
+		}
+		if after != nil && !keyAfter(t.Timestamp, t.ID, *after) {
+			continue
+		}
+		out = append(out, &t)
+	}
+	r.mu.Unlock()
+
+	sortTrades(out)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *Repository) ScanTrades(ctx context.Context, f domain.ExportFilter, fn func(*domain.Trade) error) error {
+	r.mu.Lock()
+	var out []*domain.Trade
+	for _, row := range r.trades {
+		t := row.trade
+		if row.tenant == tenant.From(ctx) && (f.Symbol == "" || t.Symbol == f.Symbol) && within(t.Timestamp, f) && r.tradeTagged(row, f.Tag) {
+			out = append(out, &t)
+		}
+	}
+	r.mu.Unlock()
+
+	sortTrades(out)
+	for _, t := range out {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tradeTagged reports whether an order of either side of the trade carries tag; the caller holds r.mu
+func (r *Repository) tradeTagged(row tradeRow, tag string) bool {
+	if tag == "" {
+		return true
+	}
+	for _, id := range []string{row.trade.BuyOrder, row.trade.SellOrder} {
+		if o, ok := r.orders[id]; ok && o.tenant == row.tenant && domain.HasTag(o.order.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Repository) ArchiveOrders(ctx context.Context, before time.Time, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var b Batch
+	for _, row := range r.orders {
+		if len(b.Orders) >= limit {
+			break
+		}
+		if row.tenant != tenant.From(ctx) || row.archived || live(row.order.Status) || !row.order.UpdatedAt.Before(before) {
+			continue
+		}
+		b.Orders = append(b.Orders, OrderRecord{Tenant: row.tenant, Order: row.order, Archived: true})
+	}
+	if err := r.logBatch(b); err != nil {
+		return 0, err
+	}
+	r.apply(b)
+	return len(b.Orders), nil
+}
+
+func (r *Repository) PurgeArchive(ctx context.Context, before time.Time) (int, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := tenant.From(ctx)
+	var b Batch
+	purged := make(map[string]bool)
+	for id, row := range r.orders {
+		if row.tenant == t && row.archived && row.order.UpdatedAt.Before(before) {
+			b.PurgedOrders = append(b.PurgedOrders, id)
+			purged[id] = true
+		}
+	}
+	kept := func(id string) bool {
+		_, ok := r.orders[id]
+		return ok && !purged[id]
+	}
+	// a long-lived order keeps its old fills until it is archived and purged itself
+	for _, row := range r.trades {
+		if row.tenant == t && row.trade.Timestamp.Before(before) && !kept(row.trade.BuyOrder) && !kept(row.trade.SellOrder) {
+			b.PurgedTrades = append(b.PurgedTrades, row.trade.ID)
+		}
+	}
+	if err := r.logBatch(b); err != nil {
+		return 0, 0, err
+	}
+	r.apply(b)
+	// histories go with their orders, and those of orders never stored once they are as old
+	for key, ts := range r.transitions {
+		if k, id := tenant.Split(key); k == t && ts[len(ts)-1].At.Before(before) {
+			if _, ok := r.orders[id]; !ok {
+				delete(r.transitions, key)
+			}
+		}
+	}
+	return len(b.PurgedOrders), len(b.PurgedTrades), nil
+}
+
+// fill is one side of a trade as seen by the client that placed the order; the caller holds mu
+type fill struct {
+	order *domain.Order
+	trade domain.Trade
+}
+
+// fills returns both sides of the tenant's trades executed in [from, to) whose orders still exist
+func (r *Repository) fills(tenantID string, from, to time.Time) []fill {
+	var out []fill
+	for _, row := range r.trades {
+		if row.tenant != tenantID || row.trade.Timestamp.Before(from) || !row.trade.Timestamp.Before(to) {
+			continue
+		}
+		for _, id := range []string{row.trade.BuyOrder, row.trade.SellOrder} {
+			if o, ok := r.orders[id]; ok && o.tenant == tenantID {
+				order := o.order
+				out = append(out, fill{order: &order, trade: row.trade})
+			}
+		}
+	}
+	return out
+}
+
+func reportKey(tenantID string, day time.Time) string {
+	return tenant.ScopeID(tenantID, day.Format(time.DateOnly))
+}
+
+// SaveDailyReport computes the day's reports from trades and orders, archived ones included, and
+// replaces any earlier run for that day
+func (r *Repository) SaveDailyReport(ctx context.Context, day time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, from, to := tenant.From(ctx), day, day.AddDate(0, 0, 1)
+
+	symbols := make(map[string]*domain.SymbolDayReport)
+	for _, row := range r.trades {
+		tr := row.trade
+		if row.tenant != t || tr.Timestamp.Before(from) || !tr.Timestamp.Before(to) {
+			continue
+		}
+		s, ok := symbols[tr.Symbol]
+		if !ok {
+			s = &domain.SymbolDayReport{Symbol: tr.Symbol, High: tr.Price, Low: tr.Price}
+			symbols[tr.Symbol] = s
+		}
+		s.Trades++
+		s.Volume = s.Volume.Add(tr.Quantity)
+		s.Notional = s.Notional.Add(tr.Price.Mul(tr.Quantity))
+		s.High = decimal.Max(s.High, tr.Price)
+		s.Low = decimal.Min(s.Low, tr.Price)
+	}
+
+	clients := make(map[string]*domain.ClientDayReport)
+	client := func(id string) *domain.ClientDayReport {
+		c, ok := clients[id]
+		if !ok {
+			c = &domain.ClientDayReport{ClientID: id}
+			clients[id] = c
+		}
+		return c
+	}
+	for _, f := range r.fills(t, from, to) {
+		c := client(f.order.ClientID)
+		c.Fills++
+		c.Volume = c.Volume.Add(f.trade.Quantity)
+		c.Notional = c.Notional.Add(f.trade.Price.Mul(f.trade.Quantity))
+	}
+	for _, row := range r.orders {
+		o := row.order
+		if row.tenant != t {
+			continue
+		}
+		placed := !o.CreatedAt.Before(from) && o.CreatedAt.Before(to)
+		cancelled := o.Status == domain.Cancelled && !o.UpdatedAt.Before(from) && o.UpdatedAt.Before(to)
+		if placed {
+			client(o.ClientID).Orders++
+		}
+		if cancelled {
+			client(o.ClientID).Cancelled++
+		}
+	}
+
+	key := reportKey(t, day)
+	delete(r.reports, key)
+	if len(symbols) == 0 && len(clients) == 0 {
+		return nil
+	}
+	rep := &domain.DailyReport{Day: day, GeneratedAt: time.Now().UTC()}
+	for _, s := range symbols {
+		s.VWAP = s.Notional.Div(s.Volume)
+		rep.Symbols = append(rep.Symbols, *s)
+	}
+	for _, c := range clients {
+		rep.Clients = append(rep.Clients, *c)
+	}
+	sort.Slice(rep.Symbols, func(i, j int) bool { return rep.Symbols[i].Symbol < rep.Symbols[j].Symbol })
+	sort.Slice(rep.Clients, func(i, j int) bool { return rep.Clients[i].ClientID < rep.Clients[j].ClientID })
+	r.reports[key] = rep
+	return nil
+}
+
+func (r *Repository) LoadDailyReport(ctx context.Context, day time.Time) (*domain.DailyReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rep, ok := r.reports[reportKey(tenant.From(ctx), day)]
+	if !ok {
+		return nil, nil
+	}
+	out := *rep
+	out.Day = day
+	out.Symbols = append([]domain.SymbolDayReport(nil), rep.Symbols...)
+	out.Clients = append([]domain.ClientDayReport(nil), rep.Clients...)
+	return &out, nil
+}
+
+// LoadClientFills returns the client's side of every trade executed in [from, to), oldest first,
+// including trades of archived orders. A client trading with itself gets both sides.
+func (r *Repository) LoadClientFills(ctx context.Context, clientID string, from, to time.Time) ([]domain.StatementFill, error) {
+	r.mu.Lock()
+	fills := r.fills(tenant.From(ctx), from, to)
+	r.mu.Unlock()
+
+	var out []domain.StatementFill
+	for _, f := range fills {
+		if f.order.ClientID != clientID {
+			continue
+		}
+		sf := domain.StatementFill{
+			TradeID: f.trade.ID, OrderID: f.order.ID, Symbol: f.trade.Symbol, Side: f.order.Side,
+			Price: f.trade.Price, Quantity: f.trade.Quantity, Timestamp: f.trade.Timestamp, Tags: f.order.Tags,
+			UserData: f.order.UserData,
+		}
+		switch f.trade.TakerSide {
+		case "":
+		case f.order.Side:
+			sf.Liquidity, sf.Fee = domain.Taker, f.trade.TakerFee
+		default:
+			sf.Liquidity, sf.Fee = domain.Maker, f.trade.MakerFee
+		}
+		out = append(out, sf)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Timestamp.Equal(out[j].Timestamp) {
+			return out[i].Timestamp.Before(out[j].Timestamp)
+		}
+		if out[i].TradeID != out[j].TradeID {
+			return out[i].TradeID < out[j].TradeID
+		}
+		return out[i].Side < out[j].Side
+	})
+	return out, nil
+}
+
+// SaveDelisting schedules a delisting or reschedules one not yet carried out
+func (r *Repository) SaveDelisting(ctx context.Context, d domain.Delisting) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := tenant.Scope(ctx, d.Symbol)
+	if prev, ok := r.delistings[key]; ok {
+		if prev.DelistedAt != nil {
+			return errors.New("symbol is already delisted")
+		}
+		d.AnnouncedAt = prev.AnnouncedAt
+	}
+	d.DelistedAt = nil
+	r.delistings[key] = d
+	return nil
+}
+
+// DeleteDelisting withdraws a pending delisting or relists a delisted symbol
+func (r *Repository) DeleteDelisting(ctx context.Context, symbol string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := tenant.Scope(ctx, symbol)
+	if _, ok := r.delistings[key]; !ok {
+		return errors.New("symbol has no delisting")
+	}
+	delete(r.delistings, key)
+	return nil
+}
+
+func (r *Repository) LoadDelistings(ctx context.Context) ([]domain.Delisting, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.Delisting
+	for key, d := range r.delistings {
+		if t, _ := tenant.Split(key); t == tenant.From(ctx) {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DelistAt.Equal(out[j].DelistAt) {
+			return out[i].DelistAt.Before(out[j].DelistAt)
+		}
+		return out[i].Symbol < out[j].Symbol
+	})
+	return out, nil
+}
+
+func (r *Repository) SaveInstrument(ctx context.Context, in domain.Instrument) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instruments[tenant.Scope(ctx, in.Symbol)] = in
+	return nil
+}
+
+func (r *Repository) LoadInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.Instrument
+	for key, in := range r.instruments {
+		if t, _ := tenant.Split(key); t == tenant.From(ctx) {
+			out = append(out, in)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Symbol < out[j].Symbol })
+	return out, nil
+}
+
+// CompleteDelisting cancels every resting order of the symbol and marks it delisted in one
+// transaction, holding the symbol's matching lock so no match runs against the orders meanwhile
+func (r *Repository) CompleteDelisting(ctx context.Context, symbol string, at time.Time) ([]*domain.Order, error) {
+	var cancelled []*domain.Order
+	err := r.autocommit(ctx, func(tx *Tx) error {
+		if err := tx.LockSymbol(ctx, symbol); err != nil {
+			return err
+		}
+		r.mu.Lock()
+		open := r.openOrders(tenant.From(ctx), func(o *domain.Order) bool { return o.Symbol == symbol })
+		r.mu.Unlock()
+		byCreation(open)
+		for _, o := range open {
+			if c, ok := tx.cancel(ctx, o.ID, o.ClientID); ok {
+				cancelled = append(cancelled, &c)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d, ok := r.delistings[tenant.Scope(ctx, symbol)]; ok {
+		d.DelistedAt = &at
+		r.delistings[tenant.Scope(ctx, symbol)] = d
+	}
+	return cancelled, nil
+}
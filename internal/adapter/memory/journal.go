@@ -0,0 +1,161 @@
+package memory
+
+import "github.com/olyamironova/exchange-engine/internal/domain"
+
+// OrderRecord is an order as stored, with the tenant it belongs to
+type OrderRecord struct {
+	Tenant   string       `json:"tenant"`
+	Order    domain.Order `json:"order"`
+	Archived bool         `json:"archived,omitempty"`
+}
+
+// TradeRecord is a trade as stored, with the tenant it belongs to
+type TradeRecord struct {
+	Tenant string       `json:"tenant"`
+	Trade  domain.Trade `json:"trade"`
+}
+
+// LedgerRecord is a ledger event as stored, with the tenant it belongs to
+type LedgerRecord struct {
+	Tenant string             `json:"tenant"`
+	Event  domain.LedgerEvent `json:"event"`
+}
+
+// AccountRecord is an account's balance as stored, with the tenant it belongs to
+type AccountRecord struct {
+	Tenant  string         `json:"tenant"`
+	Account domain.Account `json:"account"`
+}
+
+// HoldRecord is an order's hold as stored, with the tenant it belongs to; a zero amount removes it
+type HoldRecord struct {
+	Tenant string      `json:"tenant"`
+	Hold   domain.Hold `json:"hold"`
+}
+
+// Batch is one committed write to orders and trades: the new state of the orders written, the
+// trades and ledger events added, the new balances and holds and the IDs of the orders and trades
+// purged. The full state is a single batch of every order, trade, ledger event, account and hold.
+type Batch struct {
+	Orders       []OrderRecord   `json:"orders,omitempty"`
+	Trades       []TradeRecord   `json:"trades,omitempty"`
+	Ledger       []LedgerRecord  `json:"ledger,omitempty"`
+	Accounts     []AccountRecord `json:"accounts,omitempty"`
+	Holds        []HoldRecord    `json:"holds,omitempty"`
+	PurgedOrders []string        `json:"purged_orders,omitempty"`
+	PurgedTrades []string        `json:"purged_trades,omitempty"`
+}
+
+func (b Batch) empty() bool {
+	return len(b.Orders) == 0 && len(b.Trades) == 0 && len(b.Ledger) == 0 && len(b.Accounts) == 0 && len(b.Holds) == 0 &&
+		len(b.PurgedOrders) == 0 && len(b.PurgedTrades) == 0
+}
+
+// OnCommit has fn write ahead every change to orders and trades: it is called with each batch
+// before the batch is applied, in commit order, and an error fails the write, leaving the repository
+// as it was. fn runs under the repository's lock and must not call back into it. Notification
+// preferences, reports, delistings, instruments, alerts and order histories are not passed to fn.
+func (r *Repository) OnCommit(fn func(Batch) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commitHook = fn
+}
+
+// logBatch passes a non-empty batch to the commit hook; the caller holds mu
+func (r *Repository) logBatch(b Batch) error {
+	if r.commitHook == nil || b.empty() {
+		return nil
+	}
+	return r.commitHook(b)
+}
+
+// Snapshot calls fn with the full state of orders, trades and balances, holding the repository's lock so that
+// no commit happens meanwhile; fn must not call back into the repository
+func (r *Repository) Snapshot(fn func(Batch) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var b Batch
+	for _, row := range r.orders {
+		b.Orders = append(b.Orders, OrderRecord{Tenant: row.tenant, Order: row.order, Archived: row.archived})
+	}
+	for _, row := range r.trades {
+		b.Trades = append(b.Trades, TradeRecord{Tenant: row.tenant, Trade: row.trade})
+	}
+	b.Ledger = append(b.Ledger, r.ledger...)
+	for _, rec := range r.accounts {
+		b.Accounts = append(b.Accounts, rec)
+	}
+	for _, rec := range r.holds {
+		b.Holds = append(b.Holds, rec)
+	}
+	return fn(b)
+}
+
+// Restore applies a batch read back from a journal without passing it to the commit hook
+func (r *Repository) Restore(b Batch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apply(b)
+}
+
+// Evict drops the orders of the records from memory once a durable repository keeps them: those that
+// ended, are unchanged since the record and are held by no transaction. Lookups of them miss from
+// then on, and the caller reads them from the durable repository.
+func (r *Repository) Evict(orders []OrderRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rec := range orders {
+		row, ok := r.orders[rec.Order.ID]
+		if !ok || row.tenant != rec.Tenant || live(row.order.Status) || r.rowLocks[rec.Order.ID] != nil {
+			continue
+		}
+		if row.order.Status == rec.Order.Status && row.order.UpdatedAt.Equal(rec.Order.UpdatedAt) {
+			delete(r.orders, rec.Order.ID)
+		}
+	}
+}
+
+// apply writes a batch to the repository; the caller holds mu
+func (r *Repository) apply(b Batch) {
+	for _, rec := range b.Orders {
+		r.orders[rec.Order.ID] = orderRow{tenant: rec.Tenant, order: rec.Order, archived: rec.Archived}
+		r.books.put(rec.Tenant, rec.Order)
+	}
+	for _, rec := range b.Trades {
+		r.trades = append(r.trades, tradeRow{tenant: rec.Tenant, trade: rec.Trade})
+	}
+	for _, rec := range b.Ledger {
+		r.ledger = append(r.ledger, rec)
+		r.ledgerSeq = max(r.ledgerSeq, rec.Event.Seq)
+	}
+	for _, rec := range b.Accounts {
+		r.accounts[accountKey{tenant: rec.Tenant, client: rec.Account.ClientID, asset: rec.Account.Asset}] = rec
+	}
+	for _, rec := range b.Holds {
+		if rec.Hold.Amount.IsPositive() {
+			r.holds[rec.Hold.OrderID] = rec
+		} else {
+			delete(r.holds, rec.Hold.OrderID)
+		}
+	}
+	for _, id := range b.PurgedOrders {
+		if row, ok := r.orders[id]; ok {
+			r.books.remove(row.tenant, row.order.Symbol, id)
+		}
+		delete(r.orders, id)
+	}
+	if len(b.PurgedTrades) == 0 {
+		return
+	}
+	purged := make(map[string]bool, len(b.PurgedTrades))
+	for _, id := range b.PurgedTrades {
+		purged[id] = true
+	}
+	kept := r.trades[:0]
+	for _, row := range r.trades {
+		if !purged[row.trade.ID] {
+			kept = append(kept, row)
+		}
+	}
+	r.trades = kept
+}
@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// AppendLedger stages events to be appended, and numbered, on commit
+func (t *Tx) AppendLedger(ctx context.Context, evs []domain.LedgerEvent) error {
+	for _, ev := range evs {
+		t.ledger = append(t.ledger, LedgerRecord{Tenant: tenant.From(ctx), Event: ev})
+	}
+	return nil
+}
+
+func (r *Repository) ScanLedger(ctx context.Context, symbol string, afterSeq uint64, fn func(domain.LedgerEvent) error) error {
+	r.mu.Lock()
+	var evs []domain.LedgerEvent
+	for _, rec := range r.ledger {
+		if rec.Tenant == tenant.From(ctx) && rec.Event.Seq > afterSeq && (symbol == "" || rec.Event.Symbol == symbol) {
+			evs = append(evs, rec.Event)
+		}
+	}
+	r.mu.Unlock()
+	for _, ev := range evs {
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
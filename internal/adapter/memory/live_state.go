@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+)
+
+var _ port.LiveStateLoader = (*Repository)(nil)
+
+func (r *Repository) LoadLiveState(ctx context.Context) (*port.LiveState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := newLiveState()
+	for _, row := range r.orders {
+		if live(row.order.Status) {
+			st.Orders[row.tenant] = append(st.Orders[row.tenant], row.order)
+		}
+	}
+	for _, rec := range r.accounts {
+		st.Accounts[rec.Tenant] = append(st.Accounts[rec.Tenant], rec.Account)
+	}
+	for _, rec := range r.holds {
+		st.Holds[rec.Tenant] = append(st.Holds[rec.Tenant], rec.Hold)
+	}
+	return st, nil
+}
+
+func newLiveState() *port.LiveState {
+	return &port.LiveState{
+		Orders:   make(map[string][]domain.Order),
+		Accounts: make(map[string][]domain.Account),
+		Holds:    make(map[string][]domain.Hold),
+	}
+}
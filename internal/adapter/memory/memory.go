@@ -0,0 +1,790 @@
+// Package memory is an in-process implementation of port.Repository for development and tests.
+// It keeps the pg adapter's semantics: price-time ordering, per-symbol matching locks, row locks
+// taken by transactions with skip-locked candidate reads, and writes that become visible on commit.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	_ port.Repository = (*Repository)(nil)
+	_ port.Tx         = (*Tx)(nil)
+)
+
+var errNotOpen = errors.New("order not found or not OPEN")
+
+// ErrNotFound is returned by lookups of orders that do not exist in the ctx tenant
+var ErrNotFound = errors.New("order not found")
+
+// ErrTradeNotFound is returned by lookups of trades that were not executed in the ctx tenant
+var ErrTradeNotFound = errors.New("trade not found")
+
+type orderRow struct {
+	tenant   string
+	order    domain.Order
+	archived bool
+}
+
+type tradeRow struct {
+	tenant string
+	trade  domain.Trade
+}
+
+// Repository keeps every tenant's orders, trades and bookkeeping in memory
+type Repository struct {
+	mu       sync.Mutex
+	released *sync.Cond // signalled whenever a transaction gives up its row locks
+
+	orders      map[string]orderRow
+	books       *books // resting orders by symbol and price level
+	trades      []tradeRow
+	rowLocks    map[string]*Tx
+	symbolLocks map[string]chan struct{}
+	prefs       map[string]domain.NotificationPreference
+	reports     map[string]*domain.DailyReport
+	delistings  map[string]domain.Delisting
+	instruments map[string]domain.Instrument
+	alerts      map[string]domain.SurveillanceAlert
+	transitions map[string][]domain.OrderTransition // tenant-scoped order ID -> history
+	ledger      []LedgerRecord
+	ledgerSeq   uint64
+	accounts    map[accountKey]AccountRecord
+	holds       map[string]HoldRecord            // order ID -> its hold
+	candles     map[candleSeries][]domain.Candle // bars by start
+	commitHook  func(Batch) error
+}
+
+func NewRepository() *Repository {
+	r := &Repository{
+		orders:      make(map[string]orderRow),
+		books:       newBooks(),
+		rowLocks:    make(map[string]*Tx),
+		symbolLocks: make(map[string]chan struct{}),
+		prefs:       make(map[string]domain.NotificationPreference),
+		reports:     make(map[string]*domain.DailyReport),
+		delistings:  make(map[string]domain.Delisting),
+		instruments: make(map[string]domain.Instrument),
+		alerts:      make(map[string]domain.SurveillanceAlert),
+		transitions: make(map[string][]domain.OrderTransition),
+		accounts:    make(map[accountKey]AccountRecord),
+		holds:       make(map[string]HoldRecord),
+		candles:     make(map[candleSeries][]domain.Candle),
+	}
+	r.released = sync.NewCond(&r.mu)
+	return r
+}
+
+func resting(s domain.OrderStatus) bool {
+	return s == domain.Open || s == domain.PartiallyFilled
+}
+
+// live reports whether an order of the status can still be cancelled: resting on the book or a
+// stop waiting for its trigger
+func live(s domain.OrderStatus) bool {
+	return resting(s) || s == domain.Pending
+}
+
+// before orders a side of the book in price-time priority: best price first, then oldest, then id
+func before(a, b *domain.Order, side domain.Side) bool {
+	if !a.Price.Equal(b.Price) {
+		if side == domain.Buy {
+			return a.Price.GreaterThan(b.Price)
+		}
+		return a.Price.LessThan(b.Price)
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+func sortBook(orders []*domain.Order, side domain.Side) {
+	sort.Slice(orders, func(i, j int) bool { return before(orders[i], orders[j], side) })
+}
+
+func byCreation(orders []*domain.Order) {
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+}
+
+// autocommit runs fn in its own transaction, as a single pg statement would
+func (r *Repository) autocommit(ctx context.Context, fn func(*Tx) error) error {
+	tx := r.begin()
+	defer tx.Rollback(ctx)
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *Repository) BeginTx(ctx context.Context) (port.Tx, error) {
+	return r.begin(), nil
+}
+
+func (r *Repository) begin() *Tx {
+	return &Tx{r: r, orders: make(map[string]orderRow), accounts: make(map[accountKey]accountDelta), holds: make(map[string]HoldRecord)}
+}
+
+func (r *Repository) SaveOrder(ctx context.Context, o *domain.Order) error {
+	return r.autocommit(ctx, func(tx *Tx) error { return tx.SaveOrder(ctx, o) })
+}
+
+func (r *Repository) SaveTrade(ctx context.Context, t *domain.Trade) error {
+	return r.autocommit(ctx, func(tx *Tx) error { return tx.SaveTrade(ctx, t) })
+}
+
+// openOrders returns copies of the tenant's live orders matching keep; the caller holds mu
+func (r *Repository) openOrders(tenantID string, keep func(*domain.Order) bool) []*domain.Order {
+	var out []*domain.Order
+	for _, row := range r.orders {
+		if row.tenant != tenantID || !live(row.order.Status) {
+			continue
+		}
+		o := row.order
+		if keep(&o) {
+			out = append(out, &o)
+		}
+	}
+	return out
+}
+
+func (r *Repository) LoadOpenOrders(ctx context.Context, symbol string) ([]*domain.Order, error) {
+	b := r.books.get(tenant.From(ctx), symbol)
+	if b == nil {
+		return nil, nil
+	}
+	out := b.orders()
+	byCreation(out)
+	return out, nil
+}
+
+func (r *Repository) CancelOrder(ctx context.Context, orderID, clientID string) error {
+	return r.autocommit(ctx, func(tx *Tx) error { return tx.CancelOrder(ctx, orderID, clientID) })
+}
+
+func (r *Repository) CancelOrders(ctx context.Context, clientID string, orderIDs []string) (map[string]string, error) {
+	out := make(map[string]string, len(orderIDs))
+	err := r.autocommit(ctx, func(tx *Tx) error {
+		for _, id := range orderIDs {
+			if o, ok := tx.cancel(ctx, id, clientID); ok {
+				out[id] = o.Symbol
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (r *Repository) CancelOpenOrders(ctx context.Context, clientID, symbol string, side domain.Side) (map[string]string, error) {
+	out := make(map[string]string)
+	err := r.autocommit(ctx, func(tx *Tx) error {
+		r.mu.Lock()
+		cands := r.openOrders(tenant.From(ctx), func(o *domain.Order) bool {
+			return o.ClientID == clientID && (symbol == "" || o.Symbol == symbol) && (side == "" || o.Side == side)
+		})
+		r.mu.Unlock()
+		byCreation(cands)
+		for _, c := range cands {
+			if o, ok := tx.cancel(ctx, c.ID, clientID); ok {
+				out[c.ID] = o.Symbol
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (r *Repository) CancelSessionOrders(ctx context.Context, clientID, sessionID, symbol string) (map[string]string, error) {
+	out := make(map[string]string)
+	if sessionID == "" {
+		return out, nil
+	}
+	err := r.autocommit(ctx, func(tx *Tx) error {
+		r.mu.Lock()
+		cands := r.openOrders(tenant.From(ctx), func(o *domain.Order) bool {
+			return o.ClientID == clientID && o.SessionID == sessionID && (symbol == "" || o.Symbol == symbol)
+		})
+		r.mu.Unlock()
+		for _, c := range cands {
+			if o, ok := tx.cancel(ctx, c.ID, clientID); ok {
+				out[c.ID] = o.Symbol
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (r *Repository) CancelGroupOrders(ctx context.Context, clientID, groupID, symbol string) (map[string]string, error) {
+	out := make(map[string]string)
+	if groupID == "" {
+		return out, nil
+	}
+	err := r.autocommit(ctx, func(tx *Tx) error {
+		r.mu.Lock()
+		cands := r.openOrders(tenant.From(ctx), func(o *domain.Order) bool {
+			return o.ClientID == clientID && o.GroupID == groupID && (symbol == "" || o.Symbol == symbol)
+		})
+		r.mu.Unlock()
+		for _, c := range cands {
+			if o, ok := tx.cancel(ctx, c.ID, clientID); ok {
+				out[c.ID] = o.Symbol
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (r *Repository) ModifyOrder(ctx context.Context, orderID, clientID string, price, qty decimal.Decimal) error {
+	return r.autocommit(ctx, func(tx *Tx) error { return tx.ModifyOrder(ctx, orderID, clientID, &price, &qty, time.Now().UTC()) })
+}
+
+func (r *Repository) LoadSnapshot(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	orders, err := r.LoadOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	var bids, asks []domain.Order
+	for _, o := range orders {
+		if o.Side == domain.Buy {
+			bids = append(bids, *o)
+		} else {
+			asks = append(asks, *o)
+		}
+	}
+	return &domain.OrderbookSnapshot{Symbol: symbol, Bids: bids, Asks: asks}, nil
+}
+
+// LoadOrderByIDForClient finds open, historical and archived orders alike
+func (r *Repository) LoadOrderByIDForClient(ctx context.Context, orderID, clientID string) (*domain.Order, error) {
+	o, err := r.LoadOrderByID(ctx, orderID)
+	if err != nil || o.ClientID != clientID {
+		return nil, ErrNotFound
+	}
+	return o, nil
+}
+
+func (r *Repository) LoadOrderByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.orders[orderID]
+	if !ok || row.tenant != tenant.From(ctx) {
+		return nil, ErrNotFound
+	}
+	o := row.order
+	return &o, nil
+}
+
+// preferred reports whether a client order ID names a rather than b: an open order rather than a done
+// one, then the latest
+func preferred(a, b *domain.Order) bool {
+	if live(a.Status) != live(b.Status) {
+		return live(a.Status)
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
+
+func (r *Repository) LoadOrderByClientOrderID(ctx context.Context, clientID, clientOrderID string) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *domain.Order
+	for _, row := range r.orders {
+		o := row.order
+		if clientOrderID == "" || row.tenant != tenant.From(ctx) || o.ClientID != clientID || o.ClientOrderID != clientOrderID {
+			continue
+		}
+		if found == nil || preferred(&o, found) {
+			found = &o
+		}
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+// LoadTopOfBook returns the orders at the best bid and ask prices in time priority
+func (r *Repository) LoadTopOfBook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	snap := &domain.OrderbookSnapshot{Symbol: symbol}
+	b := r.books.get(tenant.From(ctx), symbol)
+	if b == nil {
+		return snap, nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	snap.Bids = b.bids.best()
+	snap.Asks = b.asks.best()
+	return snap, nil
+}
+
+// LoadTriggerOrders returns the symbol's pending stops, those crossed only if crossed is set
+func (r *Repository) LoadTriggerOrders(ctx context.Context, symbol string, crossed *domain.PriceRange) ([]*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.openOrders(tenant.From(ctx), func(o *domain.Order) bool {
+		return o.Symbol == symbol && o.Status == domain.Pending && (crossed == nil || o.TriggeredBy(*crossed))
+	})
+	byCreation(out)
+	return out, nil
+}
+
+// LoadTradesForOrder returns the order's trades after the page position ordered by time then id
+func (r *Repository) LoadTradesForOrder(ctx context.Context, orderID string, after *page.Key, limit int) ([]*domain.Trade, error) {
+	r.mu.Lock()
+	var out []*domain.Trade
+	for _, row := range r.trades {
+		t := row.trade
+		if row.tenant != tenant.From(ctx) || (t.BuyOrder != orderID && t.SellOrder != orderID) {
+			continue
+		}
+		if after != nil && !keyAfter(t.Timestamp, t.ID, *after) {
+			continue
+		}
+		out = append(out, &t)
+	}
+	r.mu.Unlock()
+
+	sortTrades(out)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *Repository) LoadTradeByID(ctx context.Context, tradeID string) (*domain.Trade, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, row := range r.trades {
+		if row.trade.ID == tradeID && row.tenant == tenant.From(ctx) {
+			t := row.trade
+			return &t, nil
+		}
+	}
+	return nil, ErrTradeNotFound
+}
+
+func keyAfter(at time.Time, id string, k page.Key) bool {
+	return at.After(k.At) || (at.Equal(k.At) && id > k.ID)
+}
+
+func sortTrades(trades []*domain.Trade) {
+	sort.Slice(trades, func(i, j int) bool {
+		if !trades[i].Timestamp.Equal(trades[j].Timestamp) {
+			return trades[i].Timestamp.Before(trades[j].Timestamp)
+		}
+		return trades[i].ID < trades[j].ID
+	})
+}
+
+func prefKey(tenantID string, p domain.NotificationPreference) string {
+	return tenantID + "\x00" + p.ClientID + "\x00" + string(p.Channel) + "\x00" + p.Target
+}
+
+func (r *Repository) LoadNotificationPreferences(ctx context.Context, clientID string) ([]domain.NotificationPreference, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.NotificationPreference
+	for key, p := range r.prefs {
+		if p.ClientID == clientID && key == prefKey(tenant.From(ctx), p) {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Channel != out[j].Channel {
+			return out[i].Channel < out[j].Channel
+		}
+		return out[i].Target < out[j].Target
+	})
+	return out, nil
+}
+
+func (r *Repository) SaveNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefs[prefKey(tenant.From(ctx), p)] = p
+	return nil
+}
+
+func (r *Repository) DeleteNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := prefKey(tenant.From(ctx), p)
+	if _, ok := r.prefs[key]; !ok {
+		return errors.New("notification preference not found")
+	}
+	delete(r.prefs, key)
+	return nil
+}
+
+func (r *Repository) ListSymbols(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := make(map[string]bool)
+	var out []string
+	for _, row := range r.orders {
+		if row.tenant == tenant.From(ctx) && !seen[row.order.Symbol] {
+			seen[row.order.Symbol] = true
+			out = append(out, row.order.Symbol)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (r *Repository) LoadOrderFills(ctx context.Context, symbol string) ([]domain.OrderFill, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := tenant.From(ctx)
+	filled := make(map[string]decimal.Decimal)
+	for _, row := range r.trades {
+		if row.tenant == t {
+			filled[row.trade.BuyOrder] = filled[row.trade.BuyOrder].Add(row.trade.Quantity)
+			filled[row.trade.SellOrder] = filled[row.trade.SellOrder].Add(row.trade.Quantity)
+		}
+	}
+	var orders []*domain.Order
+	for _, row := range r.orders {
+		if row.tenant == t && row.order.Symbol == symbol {
+			o := row.order
+			orders = append(orders, &o)
+		}
+	}
+	byCreation(orders)
+	out := make([]domain.OrderFill, 0, len(orders))
+	for _, o := range orders {
+		out = append(out, domain.OrderFill{Order: *o, Filled: filled[o.ID]})
+	}
+	return out, nil
+}
+
+// Tx stages its writes until Commit. It holds the row locks of the orders it loaded for update and
+// the matching locks of the symbols it locked, all released on Commit or Rollback.
+type Tx struct {
+	r        *Repository
+	orders   map[string]orderRow
+	trades   []tradeRow
+	ledger   []LedgerRecord
+	accounts map[accountKey]accountDelta
+	holds    map[string]HoldRecord
+	symbols  []string
+	done     bool
+}
+
+// view returns the order as this transaction sees it; the caller holds mu
+func (t *Tx) view(id string) (orderRow, bool) {
+	if row, ok := t.orders[id]; ok {
+		return row, true
+	}
+	row, ok := t.r.orders[id]
+	return row, ok
+}
+
+// lockRow waits until no other transaction holds the order, then takes it; the caller holds mu
+func (t *Tx) lockRow(id string) {
+	for {
+		owner := t.r.rowLocks[id]
+		if owner == nil || owner == t {
+			break
+		}
+		t.r.released.Wait()
+	}
+	t.r.rowLocks[id] = t
+}
+
+// lockMatching locks the order while it matches, as an update of the rows satisfying a condition
+// would, waiting for any transaction holding it and checking again after the wait; the caller holds mu
+func (t *Tx) lockMatching(id string, match func(orderRow) bool) (orderRow, bool) {
+	for {
+		row, ok := t.view(id)
+		if !ok || !match(row) {
+			return orderRow{}, false
+		}
+		if owner := t.r.rowLocks[id]; owner == nil || owner == t {
+			t.r.rowLocks[id] = t
+			return row, true
+		}
+		t.r.released.Wait()
+	}
+}
+
+// LockSymbol takes the symbol's matching lock until the transaction ends
+func (t *Tx) LockSymbol(ctx context.Context, symbol string) error {
+	key := tenant.Scope(ctx, symbol)
+	t.r.mu.Lock()
+	lock, ok := t.r.symbolLocks[key]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		t.r.symbolLocks[key] = lock
+	}
+	held := false
+	for _, s := range t.symbols {
+		held = held || s == key
+	}
+	t.r.mu.Unlock()
+	if held {
+		return nil
+	}
+	select {
+	case lock <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	t.symbols = append(t.symbols, key)
+	return nil
+}
+
+func (t *Tx) SaveOrder(ctx context.Context, o *domain.Order) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	t.lockRow(o.ID)
+	saved := *o
+	if prev, ok := t.view(o.ID); ok {
+		saved.ClientID, saved.ClientOrderID, saved.Symbol, saved.Side, saved.Type, saved.CreatedAt =
+			prev.order.ClientID, prev.order.ClientOrderID, prev.order.Symbol, prev.order.Side, prev.order.Type, prev.order.CreatedAt
+	} else if o.ClientOrderID != "" && live(o.Status) {
+		// as pg's unique index would, the key's lock makes a concurrent insert of the same ID wait
+		// for this transaction to end before checking it
+		t.lockRow("client-order-id/" + tenant.Scope(ctx, o.ClientID+"/"+o.ClientOrderID))
+		if t.clientOrderIDTaken(tenant.From(ctx), o) {
+			return port.ErrDuplicateClientOrderID
+		}
+	}
+	if saved.UpdatedAt.IsZero() {
+		saved.UpdatedAt = time.Now().UTC()
+	}
+	t.orders[o.ID] = orderRow{tenant: tenant.From(ctx), order: saved}
+	return nil
+}
+
+// clientOrderIDTaken reports whether another open order of o's client, as this transaction sees it,
+// has o's client order ID; the caller holds mu
+func (t *Tx) clientOrderIDTaken(tenantID string, o *domain.Order) bool {
+	taken := func(row orderRow) bool {
+		return row.tenant == tenantID && row.order.ID != o.ID && row.order.ClientID == o.ClientID &&
+			row.order.ClientOrderID == o.ClientOrderID && live(row.order.Status)
+	}
+	for id := range t.orders {
+		if taken(t.orders[id]) {
+			return true
+		}
+	}
+	for id, row := range t.r.orders {
+		if _, staged := t.orders[id]; !staged && taken(row) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tx) SaveTrade(ctx context.Context, tr *domain.Trade) error {
+	t.trades = append(t.trades, tradeRow{tenant: tenant.From(ctx), trade: *tr})
+	return nil
+}
+
+// cancel stages the cancel of the client's resting order, reporting whether there was one
+func (t *Tx) cancel(ctx context.Context, orderID, clientID string) (domain.Order, bool) {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	row, ok := t.lockMatching(orderID, func(row orderRow) bool {
+		return row.tenant == tenant.From(ctx) && row.order.ClientID == clientID && live(row.order.Status)
+	})
+	if !ok {
+		return domain.Order{}, false
+	}
+	row.order.Status = domain.Cancelled
+	row.order.Remaining = decimal.Zero
+	row.order.UpdatedAt = time.Now().UTC()
+	t.orders[orderID] = row
+	t.ledger = append(t.ledger, LedgerRecord{Tenant: row.tenant, Event: domain.OrderLedgerEvent(domain.OrderCancelled, &row.order)})
+	t.releaseHold(orderID)
+	return row.order, true
+}
+
+func (t *Tx) CancelOrder(ctx context.Context, orderID, clientID string) error {
+	if _, ok := t.cancel(ctx, orderID, clientID); !ok {
+		return errNotOpen
+	}
+	return nil
+}
+
+func (t *Tx) ModifyOrder(ctx context.Context, orderID, clientID string, price, qty *decimal.Decimal, at time.Time) error {
+	if price == nil || qty == nil {
+		return errors.New("price and qty must not be nil")
+	}
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	row, ok := t.lockMatching(orderID, func(row orderRow) bool {
+		return row.tenant == tenant.From(ctx) && row.order.ClientID == clientID && resting(row.order.Status)
+	})
+	if !ok {
+		return errNotOpen
+	}
+	filled := row.order.Quantity.Sub(row.order.Remaining)
+	row.order.Price, row.order.Quantity, row.order.Remaining = *price, *qty, qty.Sub(filled)
+	row.order.CreatedAt, row.order.UpdatedAt = at, at
+	t.orders[orderID] = row
+	return nil
+}
+
+// LoadOrderByIDForClient locks the order if it is open or pending, waiting for any transaction holding it
+func (t *Tx) LoadOrderByIDForClient(ctx context.Context, orderID, clientID string) (*domain.Order, error) {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	row, ok := t.lockMatching(orderID, func(row orderRow) bool {
+		return row.tenant == tenant.From(ctx) && row.order.ClientID == clientID && live(row.order.Status)
+	})
+	if !ok {
+		if row, ok = t.view(orderID); !ok || row.tenant != tenant.From(ctx) || row.order.ClientID != clientID {
+			return nil, ErrNotFound
+		}
+	}
+	o := row.order
+	return &o, nil
+}
+
+// LoadCandidatesForMatch locks the next batch of resting orders opposite to side in price-time
+// priority, skipping orders other transactions hold. It walks the symbol's book from the level of
+// after, taking this transaction's own writes over the committed orders.
+func (t *Tx) LoadCandidatesForMatch(ctx context.Context, symbol string, side domain.Side, limitPrice *decimal.Decimal, after *domain.BookKey, limit int) ([]*domain.Order, error) {
+	opposite := domain.Sell
+	if side == domain.Sell {
+		opposite = domain.Buy
+	}
+	var last *domain.Order
+	var from *decimal.Decimal
+	if after != nil {
+		last = &domain.Order{Price: after.Price, CreatedAt: after.CreatedAt, ID: after.ID}
+		from = &after.Price
+	}
+	tenantID := tenant.From(ctx)
+	// beyond reports whether the price is worse than the limit, as is every price after it
+	beyond := func(o *domain.Order) bool {
+		return limitPrice != nil && ((side == domain.Buy && o.Price.GreaterThan(*limitPrice)) || (side == domain.Sell && o.Price.LessThan(*limitPrice)))
+	}
+	eligible := func(o *domain.Order) bool {
+		if last != nil && !before(last, o, opposite) {
+			return false
+		}
+		owner := t.r.rowLocks[o.ID]
+		return owner == nil || owner == t
+	}
+
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	var cands []*domain.Order
+	for _, row := range t.orders {
+		o := row.order
+		if row.tenant == tenantID && o.Symbol == symbol && o.Side == opposite && resting(o.Status) && !beyond(&o) && eligible(&o) {
+			cands = append(cands, &o)
+		}
+	}
+	var book []*domain.Order
+	if b := t.r.books.get(tenantID, symbol); b != nil {
+		b.mu.RLock()
+		b.side(opposite).each(from, func(o *domain.Order) bool {
+			if beyond(o) {
+				return false
+			}
+			if _, staged := t.orders[o.ID]; !staged && eligible(o) {
+				c := *o
+				book = append(book, &c)
+			}
+			return len(book) < limit
+		})
+		b.mu.RUnlock()
+	}
+	cands = append(cands, book...)
+	sortBook(cands, opposite)
+	if len(cands) > limit {
+		cands = cands[:limit]
+	}
+	for _, o := range cands {
+		t.r.rowLocks[o.ID] = t
+	}
+	return cands, nil
+}
+
+func (t *Tx) Commit(ctx context.Context) error {
+	if t.done {
+		return errors.New("transaction already closed")
+	}
+	t.r.mu.Lock()
+	accounts, ok := t.settleAccounts(time.Now().UTC())
+	if !ok {
+		// a concurrent commit spent the balance this one moved
+		t.r.mu.Unlock()
+		t.release()
+		return port.ErrTxConflict
+	}
+	b := Batch{Accounts: accounts}
+	for _, rec := range t.holds {
+		b.Holds = append(b.Holds, rec)
+	}
+	for _, row := range t.orders {
+		rec := OrderRecord{Tenant: row.tenant, Order: row.order}
+		if prev, ok := t.r.orders[row.order.ID]; ok {
+			rec.Archived = prev.archived
+		}
+		b.Orders = append(b.Orders, rec)
+	}
+	for _, row := range t.trades {
+		b.Trades = append(b.Trades, TradeRecord{Tenant: row.tenant, Trade: row.trade})
+	}
+	for i, rec := range t.ledger {
+		rec.Event.Seq = t.r.ledgerSeq + uint64(i) + 1
+		b.Ledger = append(b.Ledger, rec)
+	}
+	err := t.r.logBatch(b)
+	if err == nil {
+		t.r.apply(b)
+	}
+	t.r.mu.Unlock()
+	t.release()
+	return err
+}
+
+func (t *Tx) Rollback(ctx context.Context) error {
+	if !t.done {
+		t.release()
+	}
+	return nil
+}
+
+func (t *Tx) release() {
+	t.done = true
+	t.r.mu.Lock()
+	for id, owner := range t.r.rowLocks {
+		if owner == t {
+			delete(t.r.rowLocks, id)
+		}
+	}
+	locks := make([]chan struct{}, 0, len(t.symbols))
+	for _, key := range t.symbols {
+		locks = append(locks, t.r.symbolLocks[key])
+	}
+	t.r.released.Broadcast()
+	t.r.mu.Unlock()
+	for _, lock := range locks {
+		<-lock
+	}
+	t.symbols = nil
+}
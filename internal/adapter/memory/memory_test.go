@@ -0,0 +1,12 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/port/porttest"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	porttest.TestRepository(t, func(*testing.T) port.Repository { return NewRepository() })
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+func (r *Repository) SaveTransitions(ctx context.Context, ts []domain.OrderTransition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, tr := range ts {
+		key := tenant.Scope(ctx, tr.OrderID)
+		history := r.transitions[key]
+		i, found := slices.BinarySearchFunc(history, tr.Sequence, func(h domain.OrderTransition, seq uint64) int {
+			return cmp.Compare(h.Sequence, seq)
+		})
+		if !found {
+			r.transitions[key] = slices.Insert(history, i, tr)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) LoadTransitions(ctx context.Context, orderID string) ([]domain.OrderTransition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.transitions[tenant.Scope(ctx, orderID)]), nil
+}
+
+// ScanBookChanges copies the matching changes before calling fn, so fn may use the repository
+func (r *Repository) ScanBookChanges(ctx context.Context, f domain.ExportFilter, fn func(domain.BookChange) error) error {
+	r.mu.Lock()
+	var out []domain.BookChange
+	for key, history := range r.transitions {
+		for _, tr := range history {
+			// a stop reaches the book when it is triggered, not when it is placed
+			if tr.ExecType == domain.ExecRejected || tr.Status == domain.Pending || (f.Symbol != "" && tr.Symbol != f.Symbol) || !within(tr.At, f) {
+				continue
+			}
+			row, ok := r.orders[tr.OrderID]
+			if !ok || key != tenant.Scope(ctx, tr.OrderID) || row.tenant != tenant.From(ctx) {
+				continue
+			}
+			out = append(out, domain.BookChange{
+				OrderID: tr.OrderID, Symbol: tr.Symbol, Side: row.order.Side, Price: row.order.Price,
+				ExecType: tr.ExecType, Remaining: tr.Remaining, At: tr.At, Sequence: tr.Sequence,
+			})
+		}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Sequence != out[j].Sequence {
+			return out[i].Sequence < out[j].Sequence
+		}
+		return out[i].OrderID < out[j].OrderID
+	})
+	for _, c := range out {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// Package nats is a minimal NATS publisher speaking the client protocol over plain TCP. A publish
+// ends with a PING and waits for the server's PONG, so once it returns the server has processed the
+// messages, or answered with the error it found. Subjects carry no keys: every message of a subject
+// is delivered in publish order.
+package nats
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/port"
+)
+
+// Config addresses a NATS server
+type Config struct {
+	// URL is nats://[user:password@]host:port, or nats://token@host:port
+	URL string
+	// Name identifies the connection in the server's monitoring; empty means "exchange-engine"
+	Name string
+	// Timeout bounds dialling and each publish; zero means ten seconds
+	Timeout time.Duration
+}
+
+var _ port.EventPublisher = (*Publisher)(nil)
+
+// Publisher publishes to NATS subjects over one connection, dialled on first use and again after it fails
+type Publisher struct {
+	cfg Config
+
+	mu   sync.Mutex // one publish at a time
+	conn *conn
+}
+
+// conn is a connection with its reader, which answers the server's PINGs and reports PONGs
+type conn struct {
+	c          net.Conn
+	maxPayload int
+
+	wmu sync.Mutex
+	w   *bufio.Writer
+
+	pongs chan error    // one per PONG: nil, or the -ERR the server sent since the last one
+	done  chan struct{} // closed when the reader stops
+	err   error         // why the reader stopped
+}
+
+func NewPublisher(cfg Config) *Publisher {
+	if cfg.Name == "" {
+		cfg.Name = "exchange-engine"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Publisher{cfg: cfg}
+}
+
+// Publish sends msgs to the subject topic; their keys are ignored
+func (p *Publisher) Publish(ctx context.Context, topic string, msgs []port.BrokerMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		c, err := p.dial(ctx)
+		if err != nil {
+			return err
+		}
+		p.conn = c
+	}
+	if err := p.conn.publish(ctx, topic, msgs, p.cfg.Timeout); err != nil {
+		p.conn.c.Close()
+		p.conn = nil
+		return fmt.Errorf("nats: %w", err)
+	}
+	return nil
+}
+
+type serverInfo struct {
+	MaxPayload  int  `json:"max_payload"`
+	TLSRequired bool `json:"tls_required"`
+}
+
+type connectOptions struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Version   string `json:"version"`
+	Protocol  int    `json:"protocol"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// dial connects, reads the server's INFO and sends CONNECT
+func (p *Publisher) dial(ctx context.Context) (*conn, error) {
+	u, err := url.Parse(p.cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("nats: %w", err)
+	}
+	opts := connectOptions{Name: p.cfg.Name, Lang: "go", Version: "1.0.0", Protocol: 1}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			opts.User, opts.Pass = u.User.Username(), pass
+		} else {
+			opts.AuthToken = u.User.Username()
+		}
+	}
+	d := net.Dialer{Timeout: p.cfg.Timeout}
+	nc, err := d.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, fmt.Errorf("nats: %w", err)
+	}
+	nc.SetDeadline(time.Now().Add(p.cfg.Timeout))
+	r := bufio.NewReader(nc)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("nats: %w", err)
+	}
+	var info serverInfo
+	if op, arg, _ := strings.Cut(strings.TrimSpace(line), " "); op != "INFO" || json.Unmarshal([]byte(arg), &info) != nil {
+		nc.Close()
+		return nil, fmt.Errorf("nats: unexpected greeting %q", strings.TrimSpace(line))
+	}
+	if info.TLSRequired {
+		nc.Close()
+		return nil, errors.New("nats: the server requires TLS")
+	}
+	connect, _ := json.Marshal(opts)
+	c := &conn{
+		c: nc, maxPayload: info.MaxPayload, w: bufio.NewWriter(nc),
+		pongs: make(chan error, 1), done: make(chan struct{}),
+	}
+	fmt.Fprintf(c.w, "CONNECT %s\r\n", connect)
+	if err := c.w.Flush(); err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("nats: %w", err)
+	}
+	nc.SetDeadline(time.Time{})
+	go c.read(r)
+	return c, nil
+}
+
+// publish writes the messages and a PING and waits for the PONG
+func (c *conn) publish(ctx context.Context, subject string, msgs []port.BrokerMessage, timeout time.Duration) error {
+	c.wmu.Lock()
+	c.c.SetWriteDeadline(time.Now().Add(timeout))
+	for _, m := range msgs {
+		if c.maxPayload > 0 && len(m.Value) > c.maxPayload {
+			c.wmu.Unlock()
+			return fmt.Errorf("message of %d bytes exceeds the server's limit of %d", len(m.Value), c.maxPayload)
+		}
+		fmt.Fprintf(c.w, "PUB %s %d\r\n", subject, len(m.Value))
+		c.w.Write(m.Value)
+		c.w.WriteString("\r\n")
+	}
+	c.w.WriteString("PING\r\n")
+	err := c.w.Flush()
+	c.wmu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-c.pongs:
+		return err
+	case <-c.done:
+		return c.err
+	case <-timer.C:
+		return errors.New("no PONG from the server")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// read handles the server's messages until the connection fails
+func (c *conn) read(r *bufio.Reader) {
+	defer close(c.done)
+	var serverErr error
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			c.err = err
+			return
+		}
+		op, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch strings.ToUpper(op) {
+		case "PING":
+			c.wmu.Lock()
+			c.w.WriteString("PONG\r\n")
+			c.w.Flush()
+			c.wmu.Unlock()
+		case "PONG":
+			select {
+			case c.pongs <- serverErr:
+			default:
+			}
+			serverErr = nil
+		case "-ERR":
+			serverErr = fmt.Errorf("server error %s", arg)
+		}
+	}
+}
+
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.c.Close()
+	p.conn = nil
+	return err
+}
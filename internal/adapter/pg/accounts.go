@@ -0,0 +1,85 @@
+package pg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+func (r *Repository) LoadAccounts(ctx context.Context, clientID string) ([]domain.Account, error) {
+	rows, err := r.db.Query(ctx, `
+		select client_id, asset, available, locked, updated_at
+		from accounts where tenant = $1 and client_id = $2
+		order by asset
+	`, tenant.From(ctx), clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []domain.Account
+	for rows.Next() {
+		var a domain.Account
+		if err := rows.Scan(&a.ClientID, &a.Asset, &a.Available, &a.Locked, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// AdjustAccount updates the balance in place, and the row stays locked until the transaction ends.
+// A debit only updates a row it leaves non-negative, so an overdraft fails without aborting the
+// transaction; the table's checks are a backstop.
+func (t *Tx) AdjustAccount(ctx context.Context, clientID, asset string, available, locked decimal.Decimal) error {
+	if !available.IsNegative() && !locked.IsNegative() {
+		_, err := t.tx.Exec(ctx, `
+			insert into accounts (tenant, client_id, asset, available, locked) values ($1, $2, $3, $4, $5)
+			on conflict (tenant, client_id, asset) do update set
+				available = accounts.available + excluded.available,
+				locked = accounts.locked + excluded.locked,
+				updated_at = now()
+		`, tenant.From(ctx), clientID, asset, available, locked)
+		return conflict(err)
+	}
+	cmd, err := t.tx.Exec(ctx, `
+		update accounts set available = available + $4, locked = locked + $5, updated_at = now()
+		where tenant = $1 and client_id = $2 and asset = $3 and available + $4 >= 0 and locked + $5 >= 0
+	`, tenant.From(ctx), clientID, asset, available, locked)
+	if err != nil {
+		return conflict(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrInsufficientFunds
+	}
+	return nil
+}
+
+func (t *Tx) LoadHold(ctx context.Context, orderID string) (*domain.Hold, error) {
+	h := domain.Hold{OrderID: orderID}
+	err := t.tx.QueryRow(ctx, `
+		select client_id, asset, amount from account_holds where tenant = $1 and order_id = $2
+	`, tenant.From(ctx), orderID).Scan(&h.ClientID, &h.Asset, &h.Amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, conflict(err)
+	}
+	return &h, nil
+}
+
+func (t *Tx) SaveHold(ctx context.Context, h domain.Hold) error {
+	if !h.Amount.IsPositive() {
+		_, err := t.tx.Exec(ctx, `delete from account_holds where tenant = $1 and order_id = $2`, tenant.From(ctx), h.OrderID)
+		return conflict(err)
+	}
+	_, err := t.tx.Exec(ctx, `
+		insert into account_holds (tenant, order_id, client_id, asset, amount) values ($1, $2, $3, $4, $5)
+		on conflict (tenant, order_id) do update set client_id = excluded.client_id, asset = excluded.asset, amount = excluded.amount
+	`, tenant.From(ctx), h.OrderID, h.ClientID, h.Asset, h.Amount)
+	return conflict(err)
+}
@@ -0,0 +1,71 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// SaveAlerts inserts the alerts in one batch; a finding stored by an earlier scan keeps its review
+func (r *Repository) SaveAlerts(ctx context.Context, alerts []domain.SurveillanceAlert) error {
+	batch := &pgx.Batch{}
+	for _, a := range alerts {
+		batch.Queue(`
+			insert into surveillance_alerts (tenant, id, kind, symbol, client_ids, trade_ids, detail, at, detected_at, status)
+			values ($1, $2, $3, $4, coalesce($5::text[], '{}'), coalesce($6::text[], '{}'), $7, $8, $9, $10)
+			on conflict (tenant, id) do nothing
+		`, tenant.From(ctx), a.ID, a.Kind, a.Symbol, a.ClientIDs, a.TradeIDs, a.Detail, a.At, a.DetectedAt, a.Status)
+	}
+	return r.db.SendBatch(ctx, batch).Close()
+}
+
+func (r *Repository) LoadAlerts(ctx context.Context, f domain.AlertFilter, after *page.Key, limit int) ([]domain.SurveillanceAlert, error) {
+	args := []any{tenant.From(ctx)}
+	where := "tenant = $1"
+	for _, c := range []struct{ col, val string }{{"status", string(f.Status)}, {"kind", string(f.Kind)}, {"symbol", f.Symbol}} {
+		if c.val != "" {
+			args = append(args, c.val)
+			where += fmt.Sprintf(" and %s = $%d", c.col, len(args))
+		}
+	}
+	if f.ClientID != "" {
+		args = append(args, f.ClientID)
+		where += fmt.Sprintf(" and $%d = any(client_ids)", len(args))
+	}
+	cond, args := keysetAfter("at", "id::text", after, args)
+	lim, args := limitClause(limit, args)
+	rows, err := r.db.Query(ctx, `
+		select id, kind, symbol, client_ids, trade_ids, detail, at, detected_at, status, reviewed_by, review_note, reviewed_at
+		from surveillance_alerts
+		where `+where+cond+`
+		order by at asc, id::text asc`+lim, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.SurveillanceAlert, error) {
+		var a domain.SurveillanceAlert
+		err := row.Scan(&a.ID, &a.Kind, &a.Symbol, &a.ClientIDs, &a.TradeIDs, &a.Detail, &a.At, &a.DetectedAt,
+			&a.Status, &a.ReviewedBy, &a.ReviewNote, &a.ReviewedAt)
+		return a, err
+	})
+}
+
+func (r *Repository) ReviewAlert(ctx context.Context, id string, status domain.AlertStatus, reviewer, note string, at time.Time) error {
+	cmd, err := r.db.Exec(ctx, `
+		update surveillance_alerts set status = $3, reviewed_by = $4, review_note = $5, reviewed_at = $6
+		where tenant = $1 and id::text = $2
+	`, tenant.From(ctx), id, status, reviewer, note, at)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New("alert not found")
+	}
+	return nil
+}
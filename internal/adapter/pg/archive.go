@@ -0,0 +1,66 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// ArchiveOrders moves terminal orders in one statement, so an order is never in both tables or neither
+func (r *Repository) ArchiveOrders(ctx context.Context, before time.Time, limit int) (int, error) {
+	cmd, err := r.db.Exec(ctx, `
+		with moved as (
+			delete from order_history
+			where id in (
+				select id from order_history
+				where tenant = $1 and updated_at < $2
+				limit $3
+			)
+			returning `+orderColumns+`, tenant
+		)
+		insert into orders_archive (`+orderColumns+`, tenant)
+		select `+orderColumns+`, tenant from moved
+	`, tenant.From(ctx), before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return int(cmd.RowsAffected()), nil
+}
+
+func (r *Repository) PurgeArchive(ctx context.Context, before time.Time) (int, int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	t := tenant.From(ctx)
+	orders, err := tx.Exec(ctx, `
+		delete from orders_archive where tenant = $1 and updated_at < $2
+	`, t, before)
+	if err != nil {
+		return 0, 0, err
+	}
+	// a long-lived order keeps its old fills until it is archived and purged itself
+	trades, err := tx.Exec(ctx, `
+		delete from trades tr
+		where tr.tenant = $1 and tr.executed_at < $2
+		  and not exists (select 1 from orders o where o.id in (tr.buy_order, tr.sell_order))
+	`, t, before)
+	if err != nil {
+		return 0, 0, err
+	}
+	// histories go with their orders, and those of orders never stored once they are as old
+	if _, err := tx.Exec(ctx, `
+		delete from order_transitions ot
+		where ot.tenant = $1 and ot.at < $2
+		  and not exists (select 1 from orders o where o.tenant = ot.tenant and o.id::text = ot.order_id)
+	`, t, before); err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	return int(orders.RowsAffected()), int(trades.RowsAffected()), nil
+}
@@ -0,0 +1,66 @@
+package pg
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// MergeCandles upserts the bars in one batch; a stored bar keeps its open and takes the later close
+func (r *Repository) MergeCandles(ctx context.Context, candles []domain.Candle) error {
+	batch := &pgx.Batch{}
+	for _, c := range candles {
+		batch.Queue(`
+			insert into candles (tenant, symbol, period, start_at, open, high, low, close, volume, notional, trades)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			on conflict (tenant, symbol, period, start_at) do update set
+				high = greatest(candles.high, excluded.high),
+				low = least(candles.low, excluded.low),
+				close = excluded.close,
+				volume = candles.volume + excluded.volume,
+				notional = candles.notional + excluded.notional,
+				trades = candles.trades + excluded.trades
+		`, tenant.From(ctx), c.Symbol, c.Interval, c.Start, c.Open, c.High, c.Low, c.Close, c.Volume, c.Notional, c.Trades)
+	}
+	return r.db.SendBatch(ctx, batch).Close()
+}
+
+func (r *Repository) LoadCandles(ctx context.Context, symbol string, interval domain.CandleInterval, from, to time.Time, limit int) ([]domain.Candle, error) {
+	var fromArg, toArg *time.Time
+	if !from.IsZero() {
+		fromArg = &from
+	}
+	if !to.IsZero() {
+		toArg = &to
+	}
+	var limitArg *int
+	if limit > 0 {
+		limitArg = &limit
+	}
+	rows, err := r.db.Query(ctx, `
+		select start_at, open, high, low, close, volume, notional, trades
+		from candles
+		where tenant = $1 and symbol = $2 and period = $3
+		  and ($4::timestamptz is null or start_at >= $4) and ($5::timestamptz is null or start_at < $5)
+		order by start_at desc
+		limit $6
+	`, tenant.From(ctx), symbol, interval, fromArg, toArg, limitArg)
+	if err != nil {
+		return nil, err
+	}
+	out, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Candle, error) {
+		c := domain.Candle{Symbol: symbol, Interval: interval}
+		err := row.Scan(&c.Start, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.Notional, &c.Trades)
+		c.Start = c.Start.UTC()
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(out)
+	return out, nil
+}
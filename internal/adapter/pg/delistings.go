@@ -0,0 +1,90 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// SaveDelisting schedules a delisting or reschedules one not yet carried out
+func (r *Repository) SaveDelisting(ctx context.Context, d domain.Delisting) error {
+	cmd, err := r.db.Exec(ctx, `
+		insert into symbol_delistings (tenant, symbol, reason, announced_at, halt_at, delist_at)
+		values ($1, $2, $3, $4, $5, $6)
+		on conflict (tenant, symbol) do update set
+		  reason = excluded.reason, halt_at = excluded.halt_at, delist_at = excluded.delist_at
+		where symbol_delistings.delisted_at is null
+	`, tenant.From(ctx), d.Symbol, d.Reason, d.AnnouncedAt, d.HaltAt, d.DelistAt)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New("symbol is already delisted")
+	}
+	return nil
+}
+
+// DeleteDelisting withdraws a pending delisting or relists a delisted symbol
+func (r *Repository) DeleteDelisting(ctx context.Context, symbol string) error {
+	cmd, err := r.db.Exec(ctx, `delete from symbol_delistings where tenant = $1 and symbol = $2`, tenant.From(ctx), symbol)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New("symbol has no delisting")
+	}
+	return nil
+}
+
+func (r *Repository) LoadDelistings(ctx context.Context) ([]domain.Delisting, error) {
+	rows, err := r.db.Query(ctx, `
+		select symbol, reason, announced_at, halt_at, delist_at, delisted_at
+		from symbol_delistings
+		where tenant = $1
+		order by delist_at, symbol
+	`, tenant.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Delisting, error) {
+		var d domain.Delisting
+		err := row.Scan(&d.Symbol, &d.Reason, &d.AnnouncedAt, &d.HaltAt, &d.DelistAt, &d.DelistedAt)
+		return d, err
+	})
+}
+
+// CompleteDelisting cancels every resting order of the symbol and marks it delisted in one
+// transaction, holding the symbol's matching lock so no match runs against the orders meanwhile
+func (r *Repository) CompleteDelisting(ctx context.Context, symbol string, at time.Time) ([]*domain.Order, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	t := &Tx{tx: tx, cockroach: r.cockroach}
+	if err := t.LockSymbol(ctx, symbol); err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, cancelOpen(`tenant = $1 and symbol = $2`, orderColumns), tenant.From(ctx), symbol)
+	if err != nil {
+		return nil, err
+	}
+	cancelled, err := collectOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `
+		update symbol_delistings set delisted_at = $3 where tenant = $1 and symbol = $2
+	`, tenant.From(ctx), symbol, at); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
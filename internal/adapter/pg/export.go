@@ -0,0 +1,153 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// exportWhere builds the conditions of a bulk export over rows timestamped by timeCol; tagged is the
+// condition on the filter's tag with a %d verb for its parameter
+func exportWhere(ctx context.Context, timeCol, tagged string, f domain.ExportFilter) (string, []any) {
+	args := []any{tenant.From(ctx)}
+	where := "tenant = $1"
+	if f.Symbol != "" {
+		args = append(args, f.Symbol)
+		where += fmt.Sprintf(" and symbol = $%d", len(args))
+	}
+	if !f.From.IsZero() {
+		args = append(args, f.From)
+		where += fmt.Sprintf(" and %s >= $%d", timeCol, len(args))
+	}
+	if !f.To.IsZero() {
+		args = append(args, f.To)
+		where += fmt.Sprintf(" and %s < $%d", timeCol, len(args))
+	}
+	if f.Tag != "" {
+		args = append(args, f.Tag)
+		where += " and " + fmt.Sprintf(tagged, len(args))
+	}
+	return where, args
+}
+
+// ListOrders pages through the orders view by (created_ns, id), as created_at rounds the nanoseconds
+// of the engine's timestamps away
+func (r *Repository) ListOrders(ctx context.Context, f domain.OrderFilter, after *page.Key, limit int) ([]*domain.Order, error) {
+	args := []any{tenant.From(ctx)}
+	where := "tenant = $1"
+	for _, c := range []struct{ col, val string }{{"client_id", f.ClientID}, {"symbol", f.Symbol}, {"status", string(f.Status)}} {
+		if c.val != "" {
+			args = append(args, c.val)
+			where += fmt.Sprintf(" and %s = $%d", c.col, len(args))
+		}
+	}
+	if after != nil {
+		args = append(args, after.At.UnixNano(), after.ID)
+		where += fmt.Sprintf(" and (created_ns, id::text) > ($%d, $%d)", len(args)-1, len(args))
+	}
+	lim, args := limitClause(limit, args)
+	rows, err := r.db.Query(ctx, `
+		select `+orderColumns+`
+		from orders
+		where `+where+`
+		order by created_ns asc, id::text asc`+lim, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectOrders(rows)
+}
+
+// LoadTrades pages through the symbol's trades by (executed_ns, id), as executed_at rounds the
+// nanoseconds of the engine's timestamps away
+func (r *Repository) LoadTrades(ctx context.Context, symbol string, from, to time.Time, after *page.Key, limit int) ([]*domain.Trade, error) {
+	args := []any{tenant.From(ctx), symbol}
+	where := "tenant = $1 and symbol = $2"
+	if !from.IsZero() {
+		args = append(args, from.UnixNano())
+		where += fmt.Sprintf(" and executed_ns >= $%d", len(args))
+	}
+	if !to.IsZero() {
+		args = append(args, to.UnixNano())
+		where += fmt.Sprintf(" and executed_ns < $%d", len(args))
+	}
+	if after != nil {
+		args = append(args, after.At.UnixNano(), after.ID)
+		where += fmt.Sprintf(" and (executed_ns, id::text) > ($%d, $%d)", len(args)-1, len(args))
+	}
+	lim, args := limitClause(limit, args)
+	rows, err := r.db.Query(ctx, `
+		select `+tradeColumns+`
+		from trades
+		where `+where+`
+		order by executed_ns asc, id::text asc`+lim, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trades []*domain.Trade
+	for rows.Next() {
+		t, err := scanTrade(rows)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, t)
+	}
+	return trades, rows.Err()
+}
+
+// ScanOrders streams matching live and archived orders to fn as rows arrive, so a slow consumer
+// holds back the query instead of the result piling up in memory
+func (r *Repository) ScanOrders(ctx context.Context, f domain.ExportFilter, fn func(*domain.Order) error) error {
+	where, args := exportWhere(ctx, "created_at", "$%d = any(tags)", f)
+	rows, err := r.db.Query(ctx, `
+		select `+orderColumns+`
+		from orders
+		where `+where+`
+		order by created_at asc, id asc
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ScanTrades streams matching trades to fn as rows arrive
+func (r *Repository) ScanTrades(ctx context.Context, f domain.ExportFilter, fn func(*domain.Trade) error) error {
+	where, args := exportWhere(ctx, "executed_at", `exists (
+		select 1 from orders o where o.tenant = trades.tenant and o.id in (trades.buy_order, trades.sell_order) and $%d = any(o.tags))`, f)
+	rows, err := r.db.Query(ctx, `
+		select `+tradeColumns+`
+		from trades
+		where `+where+`
+		order by executed_at asc, id asc
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t, err := scanTrade(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,38 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+func (r *Repository) SaveInstrument(ctx context.Context, in domain.Instrument) error {
+	_, err := r.db.Exec(ctx, `
+		insert into instruments (tenant, symbol, base_asset, quote_asset, tick_size, lot_size, min_notional, status, updated_at)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		on conflict (tenant, symbol) do update set
+		  base_asset = excluded.base_asset, quote_asset = excluded.quote_asset, tick_size = excluded.tick_size,
+		  lot_size = excluded.lot_size, min_notional = excluded.min_notional, status = excluded.status,
+		  updated_at = excluded.updated_at
+	`, tenant.From(ctx), in.Symbol, in.BaseAsset, in.QuoteAsset, in.TickSize, in.LotSize, in.MinNotional, in.Status, in.UpdatedAt)
+	return err
+}
+
+func (r *Repository) LoadInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	rows, err := r.db.Query(ctx, `
+		select symbol, base_asset, quote_asset, tick_size, lot_size, min_notional, status, updated_at
+		from instruments
+		where tenant = $1
+		order by symbol
+	`, tenant.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Instrument, error) {
+		var in domain.Instrument
+		err := row.Scan(&in.Symbol, &in.BaseAsset, &in.QuoteAsset, &in.TickSize, &in.LotSize, &in.MinNotional, &in.Status, &in.UpdatedAt)
+		return in, err
+	})
+}
@@ -0,0 +1,67 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// ledgerBody is the jsonb body of a ledger row: the order's state or the trade
+type ledgerBody struct {
+	Order *domain.Order `json:"order,omitempty"`
+	Trade *domain.Trade `json:"trade,omitempty"`
+}
+
+func (t *Tx) AppendLedger(ctx context.Context, evs []domain.LedgerEvent) error {
+	for _, ev := range evs {
+		body, err := json.Marshal(ledgerBody{Order: ev.Order, Trade: ev.Trade})
+		if err != nil {
+			return err
+		}
+		if _, err := t.tx.Exec(ctx, `
+			insert into order_ledger (tenant, kind, symbol, order_id, body, at) values ($1, $2, $3, $4, $5, $6)
+		`, tenant.From(ctx), ev.Kind, ev.Symbol, ev.OrderID, body, ev.At); err != nil {
+			return conflict(err)
+		}
+	}
+	return nil
+}
+
+// ScanLedger reads the ledger in seq order; cancellations recorded by the cancel statements carry
+// only the order ID
+func (r *Repository) ScanLedger(ctx context.Context, symbol string, afterSeq uint64, fn func(domain.LedgerEvent) error) error {
+	rows, err := r.db.Query(ctx, `
+		select seq, kind, symbol, order_id, body, at
+		from order_ledger
+		where tenant = $1 and ($2 = '' or symbol = $2) and seq > $3
+		order by seq
+	`, tenant.From(ctx), symbol, int64(afterSeq))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ev   domain.LedgerEvent
+			seq  int64
+			body []byte
+		)
+		if err := rows.Scan(&seq, &ev.Kind, &ev.Symbol, &ev.OrderID, &body, &ev.At); err != nil {
+			return err
+		}
+		ev.Seq = uint64(seq)
+		if body != nil {
+			var b ledgerBody
+			if err := json.Unmarshal(body, &b); err != nil {
+				return err
+			}
+			ev.Order, ev.Trade = b.Order, b.Trade
+		}
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,86 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+)
+
+var _ port.LiveStateLoader = (*Repository)(nil)
+
+// withTenant scans a row of the scanned columns followed by the tenant
+type withTenant struct {
+	pgx.Row
+	tenant *string
+}
+
+func (r withTenant) Scan(dest ...any) error { return r.Row.Scan(append(dest, r.tenant)...) }
+
+// LoadLiveState reads every tenant's open_orders, accounts and account_holds in one snapshot
+func (r *Repository) LoadLiveState(ctx context.Context) (*port.LiveState, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	st := &port.LiveState{
+		Orders:   make(map[string][]domain.Order),
+		Accounts: make(map[string][]domain.Account),
+		Holds:    make(map[string][]domain.Hold),
+	}
+
+	rows, err := tx.Query(ctx, `select `+orderColumns+`, tenant from open_orders`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var t string
+		o, err := scanOrder(withTenant{Row: rows, tenant: &t})
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		st.Orders[t] = append(st.Orders[t], *o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, `select tenant, client_id, asset, available, locked, updated_at from accounts`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			t string
+			a domain.Account
+		)
+		if err := rows.Scan(&t, &a.ClientID, &a.Asset, &a.Available, &a.Locked, &a.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		st.Accounts[t] = append(st.Accounts[t], a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, `select tenant, order_id, client_id, asset, amount from account_holds`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			t string
+			h domain.Hold
+		)
+		if err := rows.Scan(&t, &h.OrderID, &h.ClientID, &h.Asset, &h.Amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		st.Holds[t] = append(st.Holds[t], h)
+	}
+	return st, rows.Err()
+}
@@ -0,0 +1,26 @@
+package pg
+
+import (
+	"fmt"
+
+	"github.com/olyamironova/exchange-engine/internal/page"
+)
+
+// keysetAfter narrows a query ordered by (timeCol, idCol) to the rows after a page position, appending
+// the position to args; the first page has no condition
+func keysetAfter(timeCol, idCol string, after *page.Key, args []any) (string, []any) {
+	if after == nil {
+		return "", args
+	}
+	args = append(args, after.At, after.ID)
+	return fmt.Sprintf(" AND (%s, %s) > ($%d, $%d)", timeCol, idCol, len(args)-1, len(args)), args
+}
+
+// limitClause caps the rows of a page query; limit <= 0 reads them all
+func limitClause(limit int, args []any) (string, []any) {
+	if limit <= 0 {
+		return "", args
+	}
+	args = append(args, limit)
+	return fmt.Sprintf(" LIMIT $%d", len(args)), args
+}
@@ -3,23 +3,78 @@ package pg
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
 	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
 	"github.com/shopspring/decimal"
+	"time"
 )
 
-type Repository struct{ db *pgxpool.Pool }
+var (
+	_ port.Repository = (*Repository)(nil)
+	_ port.Tx         = (*Tx)(nil)
+)
+
+type Repository struct {
+	db        *pgxpool.Pool
+	cockroach bool
+}
 
-func NewRepository(db *pgxpool.Pool) *Repository { return &Repository{db: db} }
+type Option func(*Repository)
+
+// WithCockroachDB adapts the repository to CockroachDB: transactions run serializable, the
+// cluster's default, symbols are locked through symbol_locks rows instead of advisory locks, which
+// CockroachDB does not implement, and candidates are locked without skipping, since the symbol lock
+// already keeps other matchers away. Conflicts CockroachDB resolves by aborting a transaction surface
+// as port.ErrTxConflict, for the caller to run it again.
+func WithCockroachDB() Option {
+	return func(r *Repository) { r.cockroach = true }
+}
+
+func NewRepository(db *pgxpool.Pool, opts ...Option) *Repository {
+	r := &Repository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
 
 func (r *Repository) BeginTx(ctx context.Context) (port.Tx, error) {
-	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	// read committed: matching serializes on the symbol's advisory lock (LockSymbol) and every statement
+	// after it sees the book as left by the previous holder; a serializable snapshot would be taken
+	// before the lock is granted and fail on the rows that holder changed. CockroachDB retries those
+	// failures instead, through port.ErrTxConflict.
+	iso := pgx.ReadCommitted
+	if r.cockroach {
+		iso = pgx.Serializable
+	}
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: iso})
 	if err != nil {
-		return nil, err
+		return nil, conflict(err)
+	}
+	return &Tx{tx: tx, cockroach: r.cockroach}, nil
+}
+
+// conflict marks serialization failures and deadlocks, after which the transaction is aborted but
+// may succeed when run again, as port.ErrTxConflict
+func conflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01") {
+		return fmt.Errorf("%w: %w", port.ErrTxConflict, err)
 	}
-	return &Tx{tx: tx}, nil
+	return err
+}
+
+// clientOrderIDTaken reports whether err is the violation of the uniqueness of client order IDs
+// among a client's open orders
+func clientOrderIDTaken(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "open_orders_client_order_id"
 }
 
 func (r *Repository) SaveOrder(ctx context.Context, o *domain.Order) error {
@@ -29,7 +84,7 @@ func (r *Repository) SaveOrder(ctx context.Context, o *domain.Order) error {
 	}
 	defer tx.Rollback(ctx)
 
-	t := &Tx{tx: tx}
+	t := &Tx{tx: tx, cockroach: r.cockroach}
 	if err := t.SaveOrder(ctx, o); err != nil {
 		return err
 	}
@@ -37,20 +92,45 @@ func (r *Repository) SaveOrder(ctx context.Context, o *domain.Order) error {
 }
 
 func (r *Repository) SaveTrade(ctx context.Context, t *domain.Trade) error {
-	_, err := r.db.Exec(ctx, `
-		insert into trades (id, symbol, buy_order, sell_order, price, quantity, executed_at)
-		values ($1,$2,$3,$4,$5,$6,$7)
-	`, t.ID, t.Symbol, t.BuyOrder, t.SellOrder, t.Price, t.Quantity, t.Timestamp)
+	_, err := r.db.Exec(ctx, insertTrade, tradeArgs(ctx, t)...)
 	return err
 }
 
+// insertTrade stores a trade given tradeArgs; the liquidity columns are generated from taker_side
+const insertTrade = `
+	insert into trades (id, symbol, buy_order, sell_order, price, quantity, executed_at, tenant, maker_fee, taker_fee,
+	                    taker_side, taker_type, buy_client, sell_client, maker_fee_asset, taker_fee_asset, executed_ns, seq)
+	values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,nullif($11,''),nullif($12,''),$13,$14,$15,$16,$17,nullif($18,0))
+`
+
+func tradeArgs(ctx context.Context, t *domain.Trade) []any {
+	return []any{t.ID, t.Symbol, t.BuyOrder, t.SellOrder, t.Price, t.Quantity, t.Timestamp, tenant.From(ctx), t.MakerFee, t.TakerFee,
+		string(t.TakerSide), string(t.TakerType), t.BuyClient, t.SellClient, t.MakerFeeAsset, t.TakerFeeAsset, t.Timestamp.UnixNano(), int64(t.Sequence)}
+}
+
+const tradeColumns = `id, symbol, buy_order, sell_order, price, quantity, executed_ns, coalesce(seq, 0), maker_fee, taker_fee,
+	coalesce(taker_side, ''), coalesce(taker_type, ''), buy_client, sell_client, maker_fee_asset, taker_fee_asset,
+	coalesce(buy_liquidity, ''), coalesce(sell_liquidity, '')`
+
+func scanTrade(row pgx.Row) (*domain.Trade, error) {
+	var t domain.Trade
+	var executedNs, seq int64
+	err := row.Scan(&t.ID, &t.Symbol, &t.BuyOrder, &t.SellOrder, &t.Price, &t.Quantity, &executedNs, &seq, &t.MakerFee, &t.TakerFee,
+		&t.TakerSide, &t.TakerType, &t.BuyClient, &t.SellClient, &t.MakerFeeAsset, &t.TakerFeeAsset, &t.BuyLiquidity, &t.SellLiquidity)
+	if err != nil {
+		return nil, err
+	}
+	t.Timestamp, t.Sequence = fromNanos(executedNs), uint64(seq)
+	return &t, nil
+}
+
 func (r *Repository) LoadOpenOrders(ctx context.Context, symbol string) ([]*domain.Order, error) {
 	rows, err := r.db.Query(ctx, `
-		select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at
-		from orders
-		where symbol=$1 and status='OPEN'
+		select `+orderColumns+`
+		from open_orders
+		where tenant=$2 and symbol=$1 and status <> 'PENDING'
 		order by created_at asc
-	`, symbol)
+	`, symbol, tenant.From(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -58,10 +138,7 @@ func (r *Repository) LoadOpenOrders(ctx context.Context, symbol string) ([]*doma
 }
 
 func (r *Repository) CancelOrder(ctx context.Context, orderID, clientID string) error {
-	cmd, err := r.db.Exec(ctx, `
-		update orders set status='CANCELLED', remaining=0
-		where id=$1 and client_id=$2 and status='OPEN'
-	`, orderID, clientID)
+	cmd, err := r.db.Exec(ctx, cancelOpen(`id=$1 and client_id=$2 and tenant=$3`, "id"), orderID, clientID, tenant.From(ctx))
 	if err != nil {
 		return err
 	}
@@ -71,16 +148,98 @@ func (r *Repository) CancelOrder(ctx context.Context, orderID, clientID string)
 	return nil
 }
 
+func (r *Repository) CancelOrders(ctx context.Context, clientID string, orderIDs []string) (map[string]string, error) {
+	out := make(map[string]string, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, cancelOpen(`client_id=$1 and id = any($2::uuid[]) and tenant=$3`, "id, symbol"),
+		clientID, orderIDs, tenant.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, symbol string
+		if err := rows.Scan(&id, &symbol); err != nil {
+			return nil, err
+		}
+		out[id] = symbol
+	}
+	return out, rows.Err()
+}
+
+func (r *Repository) CancelOpenOrders(ctx context.Context, clientID, symbol string, side domain.Side) (map[string]string, error) {
+	rows, err := r.db.Query(ctx, cancelOpen(`client_id=$1 and tenant=$4 and ($2 = '' or symbol=$2) and ($3 = '' or side=$3)`, "id, symbol"),
+		clientID, symbol, string(side), tenant.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]string)
+	for rows.Next() {
+		var id, symbol string
+		if err := rows.Scan(&id, &symbol); err != nil {
+			return nil, err
+		}
+		out[id] = symbol
+	}
+	return out, rows.Err()
+}
+
+// CancelSessionOrders cancels the client's resting orders placed by the session, those of symbol only if
+// it is set
+func (r *Repository) CancelSessionOrders(ctx context.Context, clientID, sessionID, symbol string) (map[string]string, error) {
+	out := make(map[string]string)
+	if sessionID == "" {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, cancelOpen(`client_id=$1 and session_id=$2 and tenant=$3 and ($4 = '' or symbol=$4)`, "id, symbol"),
+		clientID, sessionID, tenant.From(ctx), symbol)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, symbol string
+		if err := rows.Scan(&id, &symbol); err != nil {
+			return nil, err
+		}
+		out[id] = symbol
+	}
+	return out, rows.Err()
+}
+
+// CancelGroupOrders cancels the client's resting orders of the group, those of symbol only if it is set
+func (r *Repository) CancelGroupOrders(ctx context.Context, clientID, groupID, symbol string) (map[string]string, error) {
+	out := make(map[string]string)
+	if groupID == "" {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, cancelOpen(`client_id=$1 and group_id=$2 and tenant=$3 and ($4 = '' or symbol=$4)`, "id, symbol"),
+		clientID, groupID, tenant.From(ctx), symbol)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, symbol string
+		if err := rows.Scan(&id, &symbol); err != nil {
+			return nil, err
+		}
+		out[id] = symbol
+	}
+	return out, rows.Err()
+}
+
 func (r *Repository) ModifyOrder(ctx context.Context, orderID, clientID string, price, qty decimal.Decimal) error {
-	cmd, err := r.db.Exec(ctx, `
-		update orders set price=$3, quantity=$4, remaining=$4, status='OPEN'
-		where id=$1 and client_id=$2 and status='OPEN'
-	`, orderID, clientID, price, qty)
+	at := time.Now().UTC()
+	cmd, err := r.db.Exec(ctx, modifyOpen, orderID, clientID, price, qty, tenant.From(ctx), at, at.UnixNano())
 	if err != nil {
 		return err
 	}
 	if cmd.RowsAffected() == 0 {
-		return errors.New("order not found or not OPEN")
+		return errors.New("order not found or not resting")
 	}
 	return nil
 }
@@ -107,41 +266,44 @@ func (r *Repository) LoadSnapshot(ctx context.Context, symbol string) (*domain.O
 
 func (r *Repository) LoadOrderByIDForClient(ctx context.Context, orderID, clientID string) (*domain.Order, error) {
 	row := r.db.QueryRow(ctx, `
-		select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at
+		select `+orderColumns+`
 		from orders
-		where id=$1 and client_id=$2
-	`, orderID, clientID)
+		where id=$1 and client_id=$2 and tenant=$3
+	`, orderID, clientID, tenant.From(ctx))
 	return scanOrder(row)
 }
 
-// returns best bid/ask
-func (r *Repository) LoadTopOfBook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
-	rowBid := r.db.QueryRow(ctx, `
-		select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at
+// LoadOrderByID finds open, historical and archived orders alike
+func (r *Repository) LoadOrderByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	row := r.db.QueryRow(ctx, `
+		select `+orderColumns+`
 		from orders
-		where symbol=$1 and side='BUY' and status='OPEN'
-		order by price desc, created_at asc
-		limit 1
-	`, symbol)
-	rowAsk := r.db.QueryRow(ctx, `
-		select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at
+		where id=$1 and tenant=$2
+	`, orderID, tenant.From(ctx))
+	return scanOrder(row)
+}
+
+func (r *Repository) LoadOrderByClientOrderID(ctx context.Context, clientID, clientOrderID string) (*domain.Order, error) {
+	row := r.db.QueryRow(ctx, `
+		select `+orderColumns+`
 		from orders
-		where symbol=$1 and side='SELL' and status='OPEN'
-		order by price asc, created_at asc
+		where tenant=$1 and client_id=$2 and client_order_id=$3 and client_order_id <> ''
+		order by status in ('OPEN', 'PARTIALLY_FILLED', 'PENDING') desc, created_ns desc, id desc
 		limit 1
-	`, symbol)
-
-	bid, _ := scanOrder(rowBid)
-	ask, _ := scanOrder(rowAsk)
+	`, tenant.From(ctx), clientID, clientOrderID)
+	return scanOrder(row)
+}
 
-	var bids, asks []domain.Order
-	if bid != nil {
-		bids = append(bids, *bid)
+// LoadTopOfBook returns the orders at the best bid and ask prices in time priority
+func (r *Repository) LoadTopOfBook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	bids, err := r.bestLevel(ctx, symbol, domain.Buy)
+	if err != nil {
+		return nil, err
 	}
-	if ask != nil {
-		asks = append(asks, *ask)
+	asks, err := r.bestLevel(ctx, symbol, domain.Sell)
+	if err != nil {
+		return nil, err
 	}
-
 	return &domain.OrderbookSnapshot{
 		Symbol: symbol,
 		Bids:   bids,
@@ -149,139 +311,249 @@ func (r *Repository) LoadTopOfBook(ctx context.Context, symbol string) (*domain.
 	}, nil
 }
 
-type Tx struct{ tx pgx.Tx }
+// bestLevel returns the orders resting at the side's best price in time priority
+func (r *Repository) bestLevel(ctx context.Context, symbol string, side domain.Side) ([]domain.Order, error) {
+	best := "max"
+	if side == domain.Sell {
+		best = "min"
+	}
+	rows, err := r.db.Query(ctx, `
+		select `+orderColumns+`
+		from open_orders
+		where tenant=$2 and symbol=$1 and side=$3 and status <> 'PENDING'
+		  and price = (
+		    select `+best+`(price) from open_orders
+		    where tenant=$2 and symbol=$1 and side=$3 and status <> 'PENDING'
+		  )
+		order by created_at asc, id
+	`, symbol, tenant.From(ctx), string(side))
+	if err != nil {
+		return nil, err
+	}
+	orders, err := collectOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.Order, 0, len(orders))
+	for _, o := range orders {
+		out = append(out, *o)
+	}
+	return out, nil
+}
 
-func (t *Tx) Commit(ctx context.Context) error   { return t.tx.Commit(ctx) }
+type Tx struct {
+	tx        pgx.Tx
+	cockroach bool
+}
+
+func (t *Tx) Commit(ctx context.Context) error   { return conflict(t.tx.Commit(ctx)) }
 func (t *Tx) Rollback(ctx context.Context) error { return t.tx.Rollback(ctx) }
 
 func scanOrder(row pgx.Row) (*domain.Order, error) {
 	var o domain.Order
-	err := row.Scan(&o.ID, &o.ClientID, &o.Symbol, &o.Side, &o.Type, &o.Price, &o.Quantity, &o.Remaining, &o.Status, &o.CreatedAt, &o.UpdatedAt)
+	var createdNs, updatedNs int64
+	err := row.Scan(&o.ID, &o.ClientID, &o.Symbol, &o.Side, &o.Type, &o.Price, &o.Quantity, &o.Remaining, &o.Status, &o.CreatedAt, &o.UpdatedAt,
+		&createdNs, &updatedNs, &o.SessionID, &o.Tags, &o.UserData, &o.GroupID, &o.CancelGroupOnFill, &o.TriggerPrice, &o.ClientOrderID)
 	if err != nil {
 		return nil, err
 	}
+	o.CreatedAt, o.UpdatedAt = fromNanos(createdNs), fromNanos(updatedNs)
 	return &o, nil
 }
 
+// fromNanos reads a *_ns column, which keeps the nanoseconds its timestamptz twin rounds away
+func fromNanos(ns int64) time.Time {
+	return time.Unix(0, ns).UTC()
+}
+
+// LoadOrderByIDForClient locks the order if it is open; a terminal order is returned unlocked, as
+// nothing changes it any more
 func (t *Tx) LoadOrderByIDForClient(ctx context.Context, orderID, clientID string) (*domain.Order, error) {
-	row := t.tx.QueryRow(ctx, `
-    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at
-    from orders where id=$1 and client_id=$2 for update`, orderID, clientID)
-	return scanOrder(row)
+	o, err := scanOrder(t.tx.QueryRow(ctx, `
+    select `+orderColumns+`
+    from open_orders where id=$1 and client_id=$2 and tenant=$3 for update`, orderID, clientID, tenant.From(ctx)))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return o, conflict(err)
+	}
+	o, err = scanOrder(t.tx.QueryRow(ctx, `
+    select `+orderColumns+`
+    from orders where id=$1 and client_id=$2 and tenant=$3`, orderID, clientID, tenant.From(ctx)))
+	return o, conflict(err)
 }
 
-// candidates for glass-lock matching
-func (t *Tx) LoadCandidatesForMatch(ctx context.Context, symbol string, side domain.Side, limitPrice *decimal.Decimal, limit int) ([]*domain.Order, error) {
-	// buyer matches the ASK (sales) in ascending order of price
-	if side == domain.Buy {
-		if limitPrice != nil {
-			rows, err := t.tx.Query(ctx, `
-        select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at
-        from orders
-        where symbol=$1 and side='SELL' and status='OPEN' and price <= $2
-        order by price asc, created_at asc
-        for update skip locked
-        limit $3
-      `, symbol, limitPrice, limit)
-			if err != nil {
-				return nil, err
-			}
-			return collectOrders(rows)
-		}
-		rows, err := t.tx.Query(ctx, `
-      select ... from orders
-      where symbol=$1 and side='SELL' and status='OPEN'
-      order by price asc, created_at asc
-      for update skip locked
-      limit $2
-    `, symbol, limit)
-		if err != nil {
-			return nil, err
-		}
-		return collectOrders(rows)
+// orderColumns is the column list scanned by scanOrder and collectOrders
+const orderColumns = `id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, created_ns, updated_ns, session_id, tags, user_data,
+	group_id, cancel_group_on_fill, trigger_price, client_order_id`
+
+// cancelOpen builds a statement that moves the open orders matching where to order_history as
+// CANCELLED, records their cancellation in the ledger, returns their holds to the available balance
+// and returns the given columns of each
+func cancelOpen(where, returning string) string {
+	return `
+		with moved as (
+			delete from open_orders where ` + where + `
+			returning ` + orderColumns + `, tenant
+		), logged as (
+			insert into order_ledger (tenant, kind, symbol, order_id)
+			select tenant, 'ORDER_CANCELLED', symbol, id from moved
+		), freed as (
+			delete from account_holds h using moved m
+			where h.tenant = m.tenant and h.order_id = m.id::text
+			returning h.tenant, h.client_id, h.asset, h.amount
+		), released as (
+			update accounts a set available = a.available + f.amount, locked = a.locked - f.amount, updated_at = now()
+			from (select tenant, client_id, asset, sum(amount) as amount from freed group by tenant, client_id, asset) f
+			where a.tenant = f.tenant and a.client_id = f.client_id and a.asset = f.asset
+		)
+		insert into order_history (` + orderColumns + `, tenant)
+		select id, client_id, symbol, side, type, price, quantity, 0, 'CANCELLED', created_at, now(), created_ns, epoch_ns(now()), session_id, tags, user_data,
+			group_id, cancel_group_on_fill, trigger_price, client_order_id, tenant
+		from moved
+		returning ` + returning
+}
+
+// LockSymbol takes the transaction-scoped advisory lock of the tenant's symbol, waiting for the
+// transaction that holds it; the lock is released on commit or rollback. On CockroachDB the lock is
+// the symbol's symbol_locks row, held by writing it.
+func (t *Tx) LockSymbol(ctx context.Context, symbol string) error {
+	query := `select pg_advisory_xact_lock(hashtextextended($1 || '/' || $2, 0))`
+	if t.cockroach {
+		query = `insert into symbol_locks (tenant, symbol) values ($1, $2)
+			on conflict (tenant, symbol) do update set locked_at = now()`
+	}
+	_, err := t.tx.Exec(ctx, query, tenant.From(ctx), symbol)
+	return conflict(err)
+}
+
+// LoadCandidatesForMatch locks the next batch of resting orders on the opposite side in price-time
+// priority. after resumes the scan behind the last order of the previous batch instead of re-reading
+// the rows already passed, including those skipped because another transaction holds them.
+func (t *Tx) LoadCandidatesForMatch(ctx context.Context, symbol string, side domain.Side, limitPrice *decimal.Decimal, after *domain.BookKey, limit int) ([]*domain.Order, error) {
+	args := []any{tenant.From(ctx), symbol}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	// a buyer matches asks from the lowest price up, a seller bids from the highest down
+	opposite, better, order := domain.Sell, "<=", "price asc"
+	if side == domain.Sell {
+		opposite, better, order = domain.Buy, ">=", "price desc"
 	}
-	// for the seller, we select the BID in descending order of price
+	where := "tenant = $1 and symbol = $2 and status <> 'PENDING' and side = " + arg(string(opposite))
 	if limitPrice != nil {
-		rows, err := t.tx.Query(ctx, `
-      select ... from orders
-      where symbol=$1 and side='BUY' and status='OPEN' and price >= $2
-      order by price desc, created_at asc
-      for update skip locked
-      limit $3
-    `, symbol, limitPrice, limit)
-		if err != nil {
-			return nil, err
+		where += " and price " + better + " " + arg(*limitPrice)
+	}
+	if after != nil {
+		p, at, id := arg(after.Price), arg(after.CreatedAt), arg(after.ID)
+		beyond := ">"
+		if opposite == domain.Buy {
+			beyond = "<"
 		}
-		return collectOrders(rows)
+		where += fmt.Sprintf(" and (price %s %s or (price = %s and (created_at, id) > (%s, %s)))", beyond, p, p, at, id)
+	}
+	lock := "for update skip locked"
+	if t.cockroach {
+		lock = "for update"
 	}
 	rows, err := t.tx.Query(ctx, `
-    select ... from orders
-    where symbol=$1 and side='BUY' and status='OPEN'
-    order by price desc, created_at asc
-    for update skip locked
-    limit $2
-  `, symbol, limit)
+		select `+orderColumns+`
+		from open_orders
+		where `+where+`
+		order by `+order+`, created_at asc, id asc
+		limit `+arg(limit)+`
+		`+lock, args...)
 	if err != nil {
-		return nil, err
+		return nil, conflict(err)
 	}
-	return collectOrders(rows)
+	orders, err := collectOrders(rows)
+	return orders, conflict(err)
 }
 
 func collectOrders(rows pgx.Rows) ([]*domain.Order, error) {
 	defer rows.Close()
 	out := make([]*domain.Order, 0, 64)
 	for rows.Next() {
-		var o domain.Order
-		if err := rows.Scan(&o.ID, &o.ClientID, &o.Symbol, &o.Side, &o.Type, &o.Price, &o.Quantity, &o.Remaining, &o.Status, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		o, err := scanOrder(rows)
+		if err != nil {
 			return nil, err
 		}
-		out = append(out, &o)
+		out = append(out, o)
 	}
 	return out, rows.Err()
 }
 
+// SaveOrder keeps a resting or pending order in open_orders and moves a FILLED or CANCELLED one to
+// order_history
 func (t *Tx) SaveOrder(ctx context.Context, o *domain.Order) error {
+	if o.Status == domain.Open || o.Status == domain.PartiallyFilled || o.Status == domain.Pending {
+		_, err := t.tx.Exec(ctx, `
+    insert into open_orders (`+orderColumns+`, tenant)
+    values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$11)
+    on conflict (id) do update set
+      price=excluded.price, quantity=excluded.quantity, remaining=excluded.remaining, status=excluded.status,
+      updated_at=excluded.updated_at, updated_ns=excluded.updated_ns
+  `, orderArgs(ctx, o)...)
+		if clientOrderIDTaken(err) {
+			return port.ErrDuplicateClientOrderID
+		}
+		return conflict(err)
+	}
 	_, err := t.tx.Exec(ctx, `
-    insert into orders (id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at)
-    values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$10)
+    with gone as (delete from open_orders where id=$1 and tenant=$11)
+    insert into order_history (`+orderColumns+`, tenant)
+    values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$11)
     on conflict (id) do update set
-      price=excluded.price, quantity=excluded.quantity, remaining=excluded.remaining, status=excluded.status, updated_at=excluded.updated_at
-  `, o.ID, o.ClientID, o.Symbol, o.Side, o.Type, o.Price, o.Quantity, o.Remaining, o.Status, o.CreatedAt)
-	return err
+      price=excluded.price, quantity=excluded.quantity, remaining=excluded.remaining, status=excluded.status,
+      updated_at=excluded.updated_at, updated_ns=excluded.updated_ns
+  `, orderArgs(ctx, o)...)
+	return conflict(err)
+}
+
+// orderArgs are the parameters of SaveOrder; an order saved without UpdatedAt is stamped with the time
+// of the save
+func orderArgs(ctx context.Context, o *domain.Order) []any {
+	updated := o.UpdatedAt
+	if updated.IsZero() {
+		updated = time.Now().UTC()
+	}
+	tags := o.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return []any{o.ID, o.ClientID, o.Symbol, o.Side, o.Type, o.Price, o.Quantity, o.Remaining, o.Status, o.CreatedAt, tenant.From(ctx),
+		updated, o.CreatedAt.UnixNano(), updated.UnixNano(), o.SessionID, tags, o.UserData, o.GroupID, o.CancelGroupOnFill, o.TriggerPrice, o.ClientOrderID}
 }
 
 func (t *Tx) SaveTrade(ctx context.Context, tr *domain.Trade) error {
-	_, err := t.tx.Exec(ctx, `
-    insert into trades (id, symbol, buy_order, sell_order, price, quantity, executed_at)
-    values ($1,$2,$3,$4,$5,$6,$7)
-  `, tr.ID, tr.Symbol, tr.BuyOrder, tr.SellOrder, tr.Price, tr.Quantity, tr.Timestamp)
-	return err
+	_, err := t.tx.Exec(ctx, insertTrade, tradeArgs(ctx, tr)...)
+	return conflict(err)
 }
 
-func (t *Tx) ModifyOrder(ctx context.Context, orderID, clientID string, price, qty *decimal.Decimal) error {
+// modifyOpen replaces a resting order's price and total quantity, keeping what already filled, and
+// restamps it, putting it behind the orders resting at its price
+const modifyOpen = `
+    update open_orders set price=$3, quantity=$4, remaining=$4 - (quantity - remaining),
+      created_at=$6, created_ns=$7, updated_at=$6, updated_ns=$7
+    where id=$1 and client_id=$2 and tenant=$5 and status in ('OPEN', 'PARTIALLY_FILLED')`
+
+func (t *Tx) ModifyOrder(ctx context.Context, orderID, clientID string, price, qty *decimal.Decimal, at time.Time) error {
 	if price == nil || qty == nil {
 		return errors.New("price and qty must not be nil")
 	}
-	cmd, err := t.tx.Exec(ctx, `
-    update orders set price=$3, quantity=$4, remaining=$4, status='OPEN'
-    where id=$1 and client_id=$2 and status='OPEN'
-  `, orderID, clientID, price, qty)
+	cmd, err := t.tx.Exec(ctx, modifyOpen, orderID, clientID, price, qty, tenant.From(ctx), at, at.UnixNano())
 	if err != nil {
-		return err
+		return conflict(err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return errors.New("order not found or not OPEN")
+		return errors.New("order not found or not resting")
 	}
 	return nil
 }
 
 func (t *Tx) CancelOrder(ctx context.Context, orderID, clientID string) error {
-	cmd, err := t.tx.Exec(ctx, `
-    update orders set status='CANCELLED', remaining=0
-    where id=$1 and client_id=$2 and status='OPEN'
-  `, orderID, clientID)
+	cmd, err := t.tx.Exec(ctx, cancelOpen(`id=$1 and client_id=$2 and tenant=$3`, "id"), orderID, clientID, tenant.From(ctx))
 	if err != nil {
-		return err
+		return conflict(err)
 	}
 	if cmd.RowsAffected() == 0 {
 		return errors.New("order not found or not OPEN")
@@ -289,13 +561,36 @@ func (t *Tx) CancelOrder(ctx context.Context, orderID, clientID string) error {
 	return nil
 }
 
-func (r *Repository) LoadTradesForOrder(ctx context.Context, orderID string) ([]*domain.Trade, error) {
+// LoadTriggerOrders reads the trigger book; crossed narrows it to the buy stops at or below its high
+// and the sell stops at or above its low
+func (r *Repository) LoadTriggerOrders(ctx context.Context, symbol string, crossed *domain.PriceRange) ([]*domain.Order, error) {
+	where := "tenant=$1 and symbol=$2 and status='PENDING'"
+	args := []any{tenant.From(ctx), symbol}
+	if crossed != nil {
+		where += " and ((side='BUY' and trigger_price <= $3) or (side='SELL' and trigger_price >= $4))"
+		args = append(args, crossed.High, crossed.Low)
+	}
+	rows, err := r.db.Query(ctx, `
+		select `+orderColumns+`
+		from open_orders
+		where `+where+`
+		order by created_at, id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectOrders(rows)
+}
+
+func (r *Repository) LoadTradesForOrder(ctx context.Context, orderID string, after *page.Key, limit int) ([]*domain.Trade, error) {
+	args := []any{orderID, tenant.From(ctx)}
+	cond, args := keysetAfter("executed_at", "id", after, args)
+	lim, args := limitClause(limit, args)
 	rows, err := r.db.Query(ctx, `
-		SELECT id, symbol, buy_order, sell_order, price, quantity, executed_at
+		SELECT `+tradeColumns+`
 		FROM trades
-		WHERE buy_order = $1 OR sell_order = $1
-		ORDER BY executed_at ASC
-	`, orderID)
+		WHERE (buy_order = $1 OR sell_order = $1) AND tenant = $2`+cond+`
+		ORDER BY executed_at ASC, id ASC`+lim, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -303,11 +598,113 @@ func (r *Repository) LoadTradesForOrder(ctx context.Context, orderID string) ([]
 
 	var trades []*domain.Trade
 	for rows.Next() {
-		var t domain.Trade
-		if err := rows.Scan(&t.ID, &t.Symbol, &t.BuyOrder, &t.SellOrder, &t.Price, &t.Quantity, &t.Timestamp); err != nil {
+		t, err := scanTrade(rows)
+		if err != nil {
 			return nil, err
 		}
-		trades = append(trades, &t)
+		trades = append(trades, t)
 	}
 	return trades, rows.Err()
 }
+
+func (r *Repository) LoadTradeByID(ctx context.Context, tradeID string) (*domain.Trade, error) {
+	return scanTrade(r.db.QueryRow(ctx, `
+		SELECT `+tradeColumns+`
+		FROM trades
+		WHERE tenant = $1 AND id = $2`, tenant.From(ctx), tradeID))
+}
+
+func (r *Repository) LoadNotificationPreferences(ctx context.Context, clientID string) ([]domain.NotificationPreference, error) {
+	rows, err := r.db.Query(ctx, `
+		select client_id, channel, target, exec_types
+		from notification_preferences
+		where client_id = $1 and tenant = $2
+	`, clientID, tenant.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []domain.NotificationPreference
+	for rows.Next() {
+		var (
+			p     domain.NotificationPreference
+			types []string
+		)
+		if err := rows.Scan(&p.ClientID, &p.Channel, &p.Target, &types); err != nil {
+			return nil, err
+		}
+		for _, t := range types {
+			p.ExecTypes = append(p.ExecTypes, domain.ExecType(t))
+		}
+		prefs = append(prefs, p)
+	}
+	return prefs, rows.Err()
+}
+
+func (r *Repository) SaveNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	types := make([]string, len(p.ExecTypes))
+	for i, t := range p.ExecTypes {
+		types[i] = string(t)
+	}
+	_, err := r.db.Exec(ctx, `
+		insert into notification_preferences (client_id, channel, target, exec_types, tenant)
+		values ($1,$2,$3,$4,$5)
+		on conflict (tenant, client_id, channel, target) do update set exec_types=excluded.exec_types
+	`, p.ClientID, string(p.Channel), p.Target, types, tenant.From(ctx))
+	return err
+}
+
+func (r *Repository) DeleteNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	cmd, err := r.db.Exec(ctx, `
+		delete from notification_preferences
+		where client_id=$1 and channel=$2 and target=$3 and tenant=$4
+	`, p.ClientID, string(p.Channel), p.Target, tenant.From(ctx))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New("notification preference not found")
+	}
+	return nil
+}
+
+func (r *Repository) ListSymbols(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		select distinct symbol from orders where tenant=$1 order by symbol
+	`, tenant.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (r *Repository) LoadOrderFills(ctx context.Context, symbol string) ([]domain.OrderFill, error) {
+	rows, err := r.db.Query(ctx, `
+		select o.id, o.client_id, o.symbol, o.side, o.type, o.price, o.quantity, o.remaining, o.status,
+		       o.created_at, o.updated_at, o.created_ns, o.updated_ns,
+		       coalesce((select sum(t.quantity) from trades t
+		                 where t.tenant = o.tenant and (t.buy_order = o.id or t.sell_order = o.id)), 0)
+		from orders o
+		where o.tenant=$2 and o.symbol=$1
+		order by o.created_at asc
+	`, symbol, tenant.From(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.OrderFill
+	for rows.Next() {
+		var f domain.OrderFill
+		o := &f.Order
+		var createdNs, updatedNs int64
+		if err := rows.Scan(&o.ID, &o.ClientID, &o.Symbol, &o.Side, &o.Type, &o.Price, &o.Quantity, &o.Remaining, &o.Status,
+			&o.CreatedAt, &o.UpdatedAt, &createdNs, &updatedNs, &f.Filled); err != nil {
+			return nil, err
+		}
+		o.CreatedAt, o.UpdatedAt = fromNanos(createdNs), fromNanos(updatedNs)
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
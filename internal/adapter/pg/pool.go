@@ -0,0 +1,92 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolConfig tunes the connection pool; zero fields keep pgxpool's defaults or whatever the
+// connection string's pool_* parameters set
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// ConnectTimeout bounds establishing one connection
+	ConnectTimeout time.Duration
+}
+
+// NewPool connects a pool to url tuned by cfg
+func NewPool(ctx context.Context, url string, cfg PoolConfig) (*pgxpool.Pool, error) {
+	pc, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxConns > 0 {
+		pc.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		pc.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		pc.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		pc.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		pc.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	if cfg.ConnectTimeout > 0 {
+		pc.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	}
+	return pgxpool.NewWithConfig(ctx, pc)
+}
+
+// PoolStats is a point-in-time view of the pool; the counters and durations accumulate from its start
+type PoolStats struct {
+	MaxConns     int32
+	TotalConns   int32
+	InUseConns   int32
+	IdleConns    int32
+	Constructing int32
+	Acquires     int64
+	AcquireTime  time.Duration
+	WaitedFor    int64 // acquires that found no idle connection and waited
+	WaitTime     time.Duration
+	Cancelled    int64 // acquires given up before a connection was free
+	NewConns     int64
+	ExpiredConns int64 // closed for exceeding MaxConnLifetime
+	IdleClosed   int64 // closed for exceeding MaxConnIdleTime
+}
+
+// AvgWait is the mean time an acquire that had to wait spent waiting
+func (s PoolStats) AvgWait() time.Duration {
+	if s.WaitedFor == 0 {
+		return 0
+	}
+	return s.WaitTime / time.Duration(s.WaitedFor)
+}
+
+// Stats reads the pool's counters
+func Stats(pool *pgxpool.Pool) PoolStats {
+	st := pool.Stat()
+	return PoolStats{
+		MaxConns:     st.MaxConns(),
+		TotalConns:   st.TotalConns(),
+		InUseConns:   st.AcquiredConns(),
+		IdleConns:    st.IdleConns(),
+		Constructing: st.ConstructingConns(),
+		Acquires:     st.AcquireCount(),
+		AcquireTime:  st.AcquireDuration(),
+		WaitedFor:    st.EmptyAcquireCount(),
+		WaitTime:     st.EmptyAcquireWaitTime(),
+		Cancelled:    st.CanceledAcquireCount(),
+		NewConns:     st.NewConnsCount(),
+		ExpiredConns: st.MaxLifetimeDestroyCount(),
+		IdleClosed:   st.MaxIdleDestroyCount(),
+	}
+}
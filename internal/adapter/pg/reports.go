@@ -0,0 +1,126 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// SaveDailyReport computes the day's reports from trades and orders, archived ones included, and
+// replaces any earlier run for that day
+func (r *Repository) SaveDailyReport(ctx context.Context, day time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	t, from, to := tenant.From(ctx), day, day.AddDate(0, 0, 1)
+	if _, err := tx.Exec(ctx, `delete from daily_symbol_reports where tenant = $1 and day = $2::date`, t, from); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `delete from daily_client_reports where tenant = $1 and day = $2::date`, t, from); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		insert into daily_symbol_reports (tenant, day, symbol, trades, volume, notional, vwap, high, low)
+		select tenant, $2::date, symbol, count(*), sum(quantity), sum(price * quantity),
+		       sum(price * quantity) / sum(quantity), max(price), min(price)
+		from trades
+		where tenant = $1 and executed_at >= $2 and executed_at < $3
+		group by tenant, symbol
+	`, t, from, to); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		with fills as (
+			select orders.client_id, tr.price, tr.quantity
+			from trades tr join orders on orders.id = tr.buy_order and orders.tenant = tr.tenant
+			where tr.tenant = $1 and tr.executed_at >= $2 and tr.executed_at < $3
+			union all
+			select orders.client_id, tr.price, tr.quantity
+			from trades tr join orders on orders.id = tr.sell_order and orders.tenant = tr.tenant
+			where tr.tenant = $1 and tr.executed_at >= $2 and tr.executed_at < $3
+		), filled as (
+			select client_id, count(*) as fills, sum(quantity) as volume, sum(price * quantity) as notional
+			from fills
+			group by client_id
+		), placed as (
+			select client_id,
+			       count(*) filter (where created_at >= $2 and created_at < $3) as orders,
+			       count(*) filter (where status = 'CANCELLED' and updated_at >= $2 and updated_at < $3) as cancelled
+			from orders
+			where tenant = $1 and ((created_at >= $2 and created_at < $3) or (updated_at >= $2 and updated_at < $3))
+			group by client_id
+		)
+		insert into daily_client_reports (tenant, day, client_id, orders, cancelled, fills, volume, notional)
+		select $1, $2::date, client_id, coalesce(orders, 0), coalesce(cancelled, 0),
+		       coalesce(fills, 0), coalesce(volume, 0), coalesce(notional, 0)
+		from placed full join filled using (client_id)
+	`, t, from, to); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// LoadDailyReport returns the stored report of the day, symbols and clients sorted, or nil if the day
+// has not been generated
+func (r *Repository) LoadDailyReport(ctx context.Context, day time.Time) (*domain.DailyReport, error) {
+	t := tenant.From(ctx)
+	rep := &domain.DailyReport{Day: day}
+
+	var generated *time.Time
+	if err := r.db.QueryRow(ctx, `
+		select max(generated_at) from (
+			select generated_at from daily_symbol_reports where tenant = $1 and day = $2::date
+			union all
+			select generated_at from daily_client_reports where tenant = $1 and day = $2::date
+		) g
+	`, t, day).Scan(&generated); err != nil {
+		return nil, err
+	}
+	if generated == nil {
+		return nil, nil
+	}
+	rep.GeneratedAt = generated.UTC()
+
+	rows, err := r.db.Query(ctx, `
+		select symbol, trades, volume, notional, vwap, high, low
+		from daily_symbol_reports
+		where tenant = $1 and day = $2::date
+		order by symbol
+	`, t, day)
+	if err != nil {
+		return nil, err
+	}
+	rep.Symbols, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.SymbolDayReport, error) {
+		var s domain.SymbolDayReport
+		err := row.Scan(&s.Symbol, &s.Trades, &s.Volume, &s.Notional, &s.VWAP, &s.High, &s.Low)
+		return s, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(ctx, `
+		select client_id, orders, cancelled, fills, volume, notional
+		from daily_client_reports
+		where tenant = $1 and day = $2::date
+		order by client_id
+	`, t, day)
+	if err != nil {
+		return nil, err
+	}
+	rep.Clients, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ClientDayReport, error) {
+		var c domain.ClientDayReport
+		err := row.Scan(&c.ClientID, &c.Orders, &c.Cancelled, &c.Fills, &c.Volume, &c.Notional)
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
@@ -0,0 +1,38 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// LoadClientFills returns the client's side of every trade executed in [from, to), oldest first,
+// including trades of archived orders. A client trading with itself gets both sides.
+func (r *Repository) LoadClientFills(ctx context.Context, clientID string, from, to time.Time) ([]domain.StatementFill, error) {
+	rows, err := r.db.Query(ctx, `
+		select tr.id, orders.id, tr.symbol, orders.side,
+		       case when tr.taker_side is null then ''
+		            when tr.taker_side = orders.side then 'TAKER'
+		            else 'MAKER' end,
+		       tr.price, tr.quantity,
+		       case when tr.taker_side is null then 0
+		            when tr.taker_side = orders.side then tr.taker_fee
+		            else tr.maker_fee end,
+		       tr.executed_at, orders.tags, orders.user_data
+		from orders
+		join trades tr on tr.tenant = orders.tenant and orders.id in (tr.buy_order, tr.sell_order)
+		where orders.tenant = $1 and orders.client_id = $2 and tr.executed_at >= $3 and tr.executed_at < $4
+		order by tr.executed_at asc, tr.id asc, orders.side asc
+	`, tenant.From(ctx), clientID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.StatementFill, error) {
+		var f domain.StatementFill
+		err := row.Scan(&f.TradeID, &f.OrderID, &f.Symbol, &f.Side, &f.Liquidity, &f.Price, &f.Quantity, &f.Fee, &f.Timestamp, &f.Tags, &f.UserData)
+		return f, err
+	})
+}
@@ -0,0 +1,76 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// SaveTransitions inserts the history entries in one batch; an entry is identified by its order and sequence
+func (r *Repository) SaveTransitions(ctx context.Context, ts []domain.OrderTransition) error {
+	batch := &pgx.Batch{}
+	for _, tr := range ts {
+		batch.Queue(`
+			insert into order_transitions (tenant, order_id, seq, client_id, symbol, exec_type, status, cause, reason,
+				remaining, trade_id, at, at_ns)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			on conflict (tenant, order_id, seq) do nothing
+		`, tenant.From(ctx), tr.OrderID, tr.Sequence, tr.ClientID, tr.Symbol, tr.ExecType, tr.Status, tr.Cause, tr.Reason,
+			tr.Remaining, tr.TradeID, tr.At, tr.At.UnixNano())
+	}
+	return r.db.SendBatch(ctx, batch).Close()
+}
+
+func (r *Repository) LoadTransitions(ctx context.Context, orderID string) ([]domain.OrderTransition, error) {
+	rows, err := r.db.Query(ctx, `
+		select order_id, client_id, symbol, exec_type, status, cause, reason, remaining, trade_id, at_ns, seq
+		from order_transitions
+		where tenant = $1 and order_id = $2
+		order by seq
+	`, tenant.From(ctx), orderID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.OrderTransition, error) {
+		var (
+			tr domain.OrderTransition
+			at int64
+		)
+		err := row.Scan(&tr.OrderID, &tr.ClientID, &tr.Symbol, &tr.ExecType, &tr.Status, &tr.Cause, &tr.Reason,
+			&tr.Remaining, &tr.TradeID, &at, &tr.Sequence)
+		tr.At = fromNanos(at)
+		return tr, err
+	})
+}
+
+// ScanBookChanges streams the matching transitions with their orders' side and price as rows arrive
+func (r *Repository) ScanBookChanges(ctx context.Context, f domain.ExportFilter, fn func(domain.BookChange) error) error {
+	f.Tag = ""
+	where, args := exportWhere(ctx, "at", "", f)
+	rows, err := r.db.Query(ctx, `
+		select t.order_id, t.symbol, o.side, o.price, t.exec_type, t.remaining, t.at_ns, t.seq
+		from (select * from order_transitions where `+where+` and exec_type <> 'REJECTED' and status <> 'PENDING') t
+		join orders o on o.tenant = t.tenant and o.id = t.order_id
+		order by t.seq, t.order_id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			c  domain.BookChange
+			at int64
+		)
+		if err := rows.Scan(&c.OrderID, &c.Symbol, &c.Side, &c.Price, &c.ExecType, &c.Remaining, &at, &c.Sequence); err != nil {
+			return err
+		}
+		c.At = fromNanos(at)
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,230 @@
+package writebehind
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+)
+
+// LoadOrderByID reads memory, then the durable repository for orders that ended before Open
+func (r *Repository) LoadOrderByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	o, err := r.Repository.LoadOrderByID(ctx, orderID)
+	if errors.Is(err, memory.ErrNotFound) {
+		return r.durable.LoadOrderByID(ctx, orderID)
+	}
+	return o, err
+}
+
+func (r *Repository) LoadOrderByIDForClient(ctx context.Context, orderID, clientID string) (*domain.Order, error) {
+	o, err := r.Repository.LoadOrderByIDForClient(ctx, orderID, clientID)
+	if errors.Is(err, memory.ErrNotFound) {
+		return r.durable.LoadOrderByIDForClient(ctx, orderID, clientID)
+	}
+	return o, err
+}
+
+func (r *Repository) LoadOrderByClientOrderID(ctx context.Context, clientID, clientOrderID string) (*domain.Order, error) {
+	o, err := r.Repository.LoadOrderByClientOrderID(ctx, clientID, clientOrderID)
+	if errors.Is(err, memory.ErrNotFound) {
+		return r.durable.LoadOrderByClientOrderID(ctx, clientID, clientOrderID)
+	}
+	return o, err
+}
+
+func (r *Repository) LoadTradeByID(ctx context.Context, tradeID string) (*domain.Trade, error) {
+	t, err := r.Repository.LoadTradeByID(ctx, tradeID)
+	if errors.Is(err, memory.ErrTradeNotFound) {
+		return r.durable.LoadTradeByID(ctx, tradeID)
+	}
+	return t, err
+}
+
+// The reads below span history from before Open, so they flush and read the durable repository
+
+func (r *Repository) LoadTradesForOrder(ctx context.Context, orderID string, after *page.Key, limit int) ([]*domain.Trade, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.durable.LoadTradesForOrder(ctx, orderID, after, limit)
+}
+
+func (r *Repository) LoadTrades(ctx context.Context, symbol string, from, to time.Time, after *page.Key, limit int) ([]*domain.Trade, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.durable.LoadTrades(ctx, symbol, from, to, after, limit)
+}
+
+func (r *Repository) ListSymbols(ctx context.Context) ([]string, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.durable.ListSymbols(ctx)
+}
+
+func (r *Repository) LoadOrderFills(ctx context.Context, symbol string) ([]domain.OrderFill, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.durable.LoadOrderFills(ctx, symbol)
+}
+
+func (r *Repository) ListOrders(ctx context.Context, f domain.OrderFilter, after *page.Key, limit int) ([]*domain.Order, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.durable.ListOrders(ctx, f, after, limit)
+}
+
+func (r *Repository) ScanOrders(ctx context.Context, f domain.ExportFilter, fn func(*domain.Order) error) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.durable.ScanOrders(ctx, f, fn)
+}
+
+func (r *Repository) ScanTrades(ctx context.Context, f domain.ExportFilter, fn func(*domain.Trade) error) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.durable.ScanTrades(ctx, f, fn)
+}
+
+func (r *Repository) LoadClientFills(ctx context.Context, clientID string, from, to time.Time) ([]domain.StatementFill, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.durable.LoadClientFills(ctx, clientID, from, to)
+}
+
+func (r *Repository) SaveDailyReport(ctx context.Context, day time.Time) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.durable.SaveDailyReport(ctx, day)
+}
+
+func (r *Repository) ScanBookChanges(ctx context.Context, f domain.ExportFilter, fn func(domain.BookChange) error) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.durable.ScanBookChanges(ctx, f, fn)
+}
+
+func (r *Repository) ScanLedger(ctx context.Context, symbol string, afterSeq uint64, fn func(domain.LedgerEvent) error) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.durable.ScanLedger(ctx, symbol, afterSeq, fn)
+}
+
+// ArchiveOrders archives in the durable repository and sheds the same ended orders from memory
+func (r *Repository) ArchiveOrders(ctx context.Context, before time.Time, limit int) (int, error) {
+	if err := r.Flush(ctx); err != nil {
+		return 0, err
+	}
+	n, err := r.durable.ArchiveOrders(ctx, before, limit)
+	if err != nil {
+		return n, err
+	}
+	if _, err := r.Repository.ArchiveOrders(ctx, before, limit); err != nil {
+		return n, err
+	}
+	if _, _, err := r.Repository.PurgeArchive(ctx, before); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+func (r *Repository) PurgeArchive(ctx context.Context, before time.Time) (orders, trades int, err error) {
+	if err := r.Flush(ctx); err != nil {
+		return 0, 0, err
+	}
+	return r.durable.PurgeArchive(ctx, before)
+}
+
+// CompleteDelisting cancels the symbol's orders in memory, then marks it delisted in the durable
+// repository once the cancels are written
+func (r *Repository) CompleteDelisting(ctx context.Context, symbol string, at time.Time) ([]*domain.Order, error) {
+	cancelled, err := r.Repository.CompleteDelisting(ctx, symbol, at)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := r.durable.CompleteDelisting(ctx, symbol, at); err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// The bookkeeping below is not on the write path and is kept by the durable repository alone
+
+func (r *Repository) LoadNotificationPreferences(ctx context.Context, clientID string) ([]domain.NotificationPreference, error) {
+	return r.durable.LoadNotificationPreferences(ctx, clientID)
+}
+
+func (r *Repository) SaveNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	return r.durable.SaveNotificationPreference(ctx, p)
+}
+
+func (r *Repository) DeleteNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	return r.durable.DeleteNotificationPreference(ctx, p)
+}
+
+func (r *Repository) LoadDailyReport(ctx context.Context, day time.Time) (*domain.DailyReport, error) {
+	return r.durable.LoadDailyReport(ctx, day)
+}
+
+func (r *Repository) SaveDelisting(ctx context.Context, d domain.Delisting) error {
+	return r.durable.SaveDelisting(ctx, d)
+}
+
+func (r *Repository) DeleteDelisting(ctx context.Context, symbol string) error {
+	return r.durable.DeleteDelisting(ctx, symbol)
+}
+
+func (r *Repository) LoadDelistings(ctx context.Context) ([]domain.Delisting, error) {
+	return r.durable.LoadDelistings(ctx)
+}
+
+func (r *Repository) SaveInstrument(ctx context.Context, in domain.Instrument) error {
+	return r.durable.SaveInstrument(ctx, in)
+}
+
+func (r *Repository) LoadInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return r.durable.LoadInstruments(ctx)
+}
+
+func (r *Repository) SaveAlerts(ctx context.Context, alerts []domain.SurveillanceAlert) error {
+	return r.durable.SaveAlerts(ctx, alerts)
+}
+
+func (r *Repository) LoadAlerts(ctx context.Context, f domain.AlertFilter, after *page.Key, limit int) ([]domain.SurveillanceAlert, error) {
+	return r.durable.LoadAlerts(ctx, f, after, limit)
+}
+
+func (r *Repository) ReviewAlert(ctx context.Context, id string, status domain.AlertStatus, reviewer, note string, at time.Time) error {
+	return r.durable.ReviewAlert(ctx, id, status, reviewer, note, at)
+}
+
+func (r *Repository) SaveTransitions(ctx context.Context, ts []domain.OrderTransition) error {
+	return r.durable.SaveTransitions(ctx, ts)
+}
+
+func (r *Repository) MergeCandles(ctx context.Context, candles []domain.Candle) error {
+	return r.durable.MergeCandles(ctx, candles)
+}
+
+func (r *Repository) LoadCandles(ctx context.Context, symbol string, interval domain.CandleInterval, from, to time.Time, limit int) ([]domain.Candle, error) {
+	return r.durable.LoadCandles(ctx, symbol, interval, from, to, limit)
+}
+
+func (r *Repository) LoadTransitions(ctx context.Context, orderID string) ([]domain.OrderTransition, error) {
+	return r.durable.LoadTransitions(ctx, orderID)
+}
@@ -0,0 +1,333 @@
+// Package writebehind is a port.Repository that keeps the write path off the database: orders,
+// trades, the ledger and account balances live in the memory repository, which matches without a
+// round trip per batch of candidates, and every commit is written to a durable repository, normally
+// Postgres, asynchronously and in commit order.
+//
+// A commit returns once it is applied in memory and queued; the durable repository trails by the
+// queue. Reads of single orders and trades, books and balances are served from memory. Bulk reads
+// of orders and trades (exports, statements, reports, trade pages, the ledger) flush the queue and
+// read the durable repository, so they see every committed write. Notification preferences,
+// delistings, alerts, order histories and candles are not on the write path and go to the durable
+// repository directly.
+//
+// Opening loads the durable live state: pending and resting orders, balances and holds. Orders that
+// ended before then are read from the durable repository only, as are those that end later once
+// their commit is written.
+package writebehind
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+var _ port.Repository = (*Repository)(nil)
+
+// ErrClosed is returned by commits after Close
+var ErrClosed = errors.New("write-behind repository is closed")
+
+// Durable is the repository commits are written behind to
+type Durable interface {
+	port.Repository
+	port.LiveStateLoader
+}
+
+// Options tune the write-behind queue
+type Options struct {
+	// MaxPending bounds the batches committed but not yet written; commits wait while it is
+	// reached, so a slow or unavailable database slows trading down instead of growing the queue
+	// without bound. Zero means 10000.
+	MaxPending int
+	// MaxGroup bounds the batches written in one durable transaction. Zero means 256.
+	MaxGroup int
+	// RetryInterval is the wait before writing a batch again after the durable repository failed.
+	// Zero means one second.
+	RetryInterval time.Duration
+	// OnError is called with every failed write, which is retried; nil ignores them
+	OnError func(error)
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxPending <= 0 {
+		o.MaxPending = 10000
+	}
+	if o.MaxGroup <= 0 {
+		o.MaxGroup = 256
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = time.Second
+	}
+	return o
+}
+
+// accountKey identifies a client's account of one asset
+type accountKey struct {
+	tenant, client, asset string
+}
+
+// Repository serves the write path from memory and writes its commits to the durable repository
+type Repository struct {
+	*memory.Repository
+	durable Durable
+	opts    Options
+
+	mu       sync.Mutex
+	queue    []memory.Batch
+	queued   uint64        // batches committed since Open
+	written  uint64        // batches written to the durable repository
+	progress chan struct{} // closed and replaced whenever batches are written
+	wake     chan struct{}
+	closed   bool
+	lastErr  error
+	done     chan struct{}
+
+	// accounts are the balances as last written, which writes turn into deltas; only the writer uses it
+	accounts map[accountKey]domain.Account
+}
+
+// Open loads the durable repository's live state into memory and starts writing commits behind
+func Open(ctx context.Context, durable Durable, opts Options) (*Repository, error) {
+	st, err := durable.LoadLiveState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mem := memory.NewRepository()
+	r := &Repository{
+		Repository: mem,
+		durable:    durable,
+		opts:       opts.withDefaults(),
+		progress:   make(chan struct{}),
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		accounts:   make(map[accountKey]domain.Account),
+	}
+	var b memory.Batch
+	for t, orders := range st.Orders {
+		for _, o := range orders {
+			b.Orders = append(b.Orders, memory.OrderRecord{Tenant: t, Order: o})
+		}
+	}
+	for t, accounts := range st.Accounts {
+		for _, a := range accounts {
+			b.Accounts = append(b.Accounts, memory.AccountRecord{Tenant: t, Account: a})
+			r.accounts[accountKey{tenant: t, client: a.ClientID, asset: a.Asset}] = a
+		}
+	}
+	for t, holds := range st.Holds {
+		for _, h := range holds {
+			b.Holds = append(b.Holds, memory.HoldRecord{Tenant: t, Hold: h})
+		}
+	}
+	mem.Restore(b)
+	mem.OnCommit(r.enqueue)
+	go r.run()
+	return r, nil
+}
+
+// enqueue queues a committed batch, waiting while the queue is full. It runs under the memory
+// repository's lock, which the writer never takes.
+func (r *Repository) enqueue(b memory.Batch) error {
+	r.mu.Lock()
+	for len(r.queue) >= r.opts.MaxPending && !r.closed {
+		progress := r.progress
+		r.mu.Unlock()
+		<-progress
+		r.mu.Lock()
+	}
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	r.queue = append(r.queue, b)
+	r.queued++
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// run writes queued batches in commit order until Close, retrying a failed write until it succeeds
+func (r *Repository) run() {
+	defer close(r.done)
+	for {
+		r.mu.Lock()
+		n := min(len(r.queue), r.opts.MaxGroup)
+		group := r.queue[:n:n]
+		closed := r.closed
+		r.mu.Unlock()
+		if n == 0 {
+			if closed {
+				return
+			}
+			<-r.wake
+			continue
+		}
+
+		accounts, err := r.write(group)
+		if err != nil {
+			r.mu.Lock()
+			r.lastErr = err
+			r.mu.Unlock()
+			if r.opts.OnError != nil {
+				r.opts.OnError(err)
+			}
+			time.Sleep(r.opts.RetryInterval)
+			continue
+		}
+		r.accounts = accounts
+		r.mu.Lock()
+		r.queue = r.queue[n:]
+		r.written += uint64(n)
+		r.lastErr = nil
+		close(r.progress)
+		r.progress = make(chan struct{})
+		r.mu.Unlock()
+		// after the queue moved on, as a commit waiting for room holds the memory lock
+		r.evict(group)
+	}
+}
+
+// evict drops the orders that ended in a written group from memory, which reads them from the
+// durable repository from then on, so memory holds the live state and the orders still queued
+func (r *Repository) evict(group []memory.Batch) {
+	var written []memory.OrderRecord
+	for _, b := range group {
+		written = append(written, b.Orders...)
+	}
+	if len(written) > 0 {
+		r.Repository.Evict(written)
+	}
+}
+
+// write stores a group of batches in one durable transaction and returns the balances it leaves
+func (r *Repository) write(group []memory.Batch) (map[accountKey]domain.Account, error) {
+	ctx := context.Background()
+	tx, err := r.durable.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	accounts := make(map[accountKey]domain.Account, len(r.accounts))
+	for k, a := range r.accounts {
+		accounts[k] = a
+	}
+	for _, b := range group {
+		if err := writeBatch(ctx, tx, b, accounts); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// writeBatch replays one commit on the durable transaction. Archiving and purging in memory only
+// sheds what the durable repository keeps, so archived orders and purges are not written.
+func writeBatch(ctx context.Context, tx port.Tx, b memory.Batch, accounts map[accountKey]domain.Account) error {
+	for _, rec := range b.Orders {
+		if rec.Archived {
+			continue
+		}
+		if err := tx.SaveOrder(tenant.With(ctx, rec.Tenant), &rec.Order); err != nil {
+			return err
+		}
+	}
+	for _, rec := range b.Trades {
+		if err := tx.SaveTrade(tenant.With(ctx, rec.Tenant), &rec.Trade); err != nil {
+			return err
+		}
+	}
+	for _, rec := range b.Ledger {
+		if err := tx.AppendLedger(tenant.With(ctx, rec.Tenant), []domain.LedgerEvent{rec.Event}); err != nil {
+			return err
+		}
+	}
+	for _, rec := range b.Accounts {
+		k := accountKey{tenant: rec.Tenant, client: rec.Account.ClientID, asset: rec.Account.Asset}
+		prev := accounts[k]
+		available, locked := rec.Account.Available.Sub(prev.Available), rec.Account.Locked.Sub(prev.Locked)
+		if available.IsZero() && locked.IsZero() {
+			continue
+		}
+		if err := tx.AdjustAccount(tenant.With(ctx, rec.Tenant), k.client, k.asset, available, locked); err != nil {
+			return err
+		}
+		accounts[k] = rec.Account
+	}
+	for _, rec := range b.Holds {
+		if err := tx.SaveHold(tenant.With(ctx, rec.Tenant), rec.Hold); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush waits until every batch committed before the call is written to the durable repository
+func (r *Repository) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	target := r.queued
+	r.mu.Unlock()
+	for {
+		r.mu.Lock()
+		if r.written >= target {
+			r.mu.Unlock()
+			return nil
+		}
+		progress, lastErr := r.progress, r.lastErr
+		r.mu.Unlock()
+		select {
+		case <-progress:
+		case <-ctx.Done():
+			if lastErr != nil {
+				return errors.Join(ctx.Err(), lastErr)
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// Stats is the state of the write-behind queue
+type Stats struct {
+	Pending int    // batches committed but not yet written
+	Written uint64 // batches written since Open
+	// LastError is the failure of the write being retried, "" while writes succeed
+	LastError string
+}
+
+func (r *Repository) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := Stats{Pending: len(r.queue), Written: r.written}
+	if r.lastErr != nil {
+		s.LastError = r.lastErr.Error()
+	}
+	return s
+}
+
+// Close refuses further commits, writes the queue out and stops the writer. If ctx ends first the
+// batches still queued are lost to the durable repository.
+func (r *Repository) Close(ctx context.Context) error {
+	r.mu.Lock()
+	r.closed = true
+	close(r.progress)
+	r.progress = make(chan struct{})
+	r.mu.Unlock()
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
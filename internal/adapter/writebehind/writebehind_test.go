@@ -0,0 +1,128 @@
+package writebehind
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/port/porttest"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+func open(t *testing.T, durable Durable, opts Options) *Repository {
+	t.Helper()
+	r, err := Open(context.Background(), durable, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close(context.Background()) })
+	return r
+}
+
+func TestRepositoryConformance(t *testing.T) {
+	porttest.TestRepository(t, func(t *testing.T) port.Repository { return open(t, memory.NewRepository(), Options{}) })
+}
+
+// failing fails the commit of the next transaction while failNext is set
+type failing struct {
+	*memory.Repository
+	failNext atomic.Bool
+}
+
+type failingTx struct {
+	port.Tx
+	fail bool
+}
+
+func (f *failing) BeginTx(ctx context.Context) (port.Tx, error) {
+	tx, err := f.Repository.BeginTx(ctx)
+	return &failingTx{Tx: tx, fail: f.failNext.Swap(false)}, err
+}
+
+func (t *failingTx) Commit(ctx context.Context) error {
+	if t.fail {
+		return errors.New("database unavailable")
+	}
+	return t.Tx.Commit(ctx)
+}
+
+func TestWritesBehindAndRestartsFromTheDurableState(t *testing.T) {
+	ctx := tenant.With(context.Background(), "wb")
+	durable := &failing{Repository: memory.NewRepository()}
+	durable.failNext.Store(true) // the first write fails and is retried
+	var failures atomic.Int32
+	r := open(t, durable, Options{RetryInterval: time.Millisecond, OnError: func(error) { failures.Add(1) }})
+
+	o := &domain.Order{ID: uuid.NewString(), ClientID: "c", Symbol: "BTC/USD", Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(2), Remaining: decimal.NewFromInt(2),
+		Status: domain.Open, CreatedAt: time.Now().UTC()}
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SaveOrder(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.AdjustAccount(ctx, "c", "USD", decimal.NewFromInt(800), decimal.NewFromInt(200)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SaveHold(ctx, domain.Hold{OrderID: o.ID, ClientID: "c", Asset: "USD", Amount: decimal.NewFromInt(200)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.LoadOrderByID(ctx, o.ID); err != nil {
+		t.Fatalf("committed order not readable from memory: %v", err)
+	}
+
+	flushCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := r.Flush(flushCtx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if n := failures.Load(); n != 1 {
+		t.Errorf("%d failed writes, want 1", n)
+	}
+	if s := r.Stats(); s.Pending != 0 || s.Written != 1 || s.LastError != "" {
+		t.Errorf("stats after flush: %+v", s)
+	}
+	if got, err := durable.LoadOrderByID(ctx, o.ID); err != nil || !got.Remaining.Equal(o.Remaining) {
+		t.Fatalf("durable order %+v, %v", got, err)
+	}
+
+	// a cancel releases the hold behind as well
+	if err := r.CancelOrder(ctx, o.ID, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(flushCtx); err != nil {
+		t.Fatal(err)
+	}
+	accs, err := durable.LoadAccounts(ctx, "c")
+	if err != nil || len(accs) != 1 || !accs[0].Available.Equal(decimal.NewFromInt(1000)) || !accs[0].Locked.IsZero() {
+		t.Fatalf("durable balances %+v, %v", accs, err)
+	}
+	// the cancelled order left memory once written and is read from the durable repository
+	if _, err := r.Repository.LoadOrderByID(ctx, o.ID); !errors.Is(err, memory.ErrNotFound) {
+		t.Errorf("written cancelled order still in memory: %v", err)
+	}
+	if got, err := r.LoadOrderByID(ctx, o.ID); err != nil || got.Status != domain.Cancelled {
+		t.Errorf("evicted order %+v, %v", got, err)
+	}
+
+	reopened := open(t, durable, Options{})
+	if got, err := reopened.LoadOrderByID(ctx, o.ID); err != nil || got.Status != domain.Cancelled {
+		t.Errorf("order ended before the restart: %+v, %v", got, err)
+	}
+	accs, err = reopened.LoadAccounts(ctx, "c")
+	if err != nil || len(accs) != 1 || !accs[0].Available.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("balances after the restart %+v, %v", accs, err)
+	}
+}
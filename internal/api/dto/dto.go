@@ -8,8 +8,10 @@ import (
 type OrderType string
 
 const (
-	Limit  OrderType = "LIMIT"
-	Market OrderType = "MARKET"
+	Limit     OrderType = "LIMIT"
+	Market    OrderType = "MARKET"
+	Stop      OrderType = "STOP"
+	StopLimit OrderType = "STOP_LIMIT"
 )
 
 type Side string
@@ -28,44 +30,196 @@ const (
 )
 
 type SubmitOrderRequest struct {
-	OrderID  string          `json:"order_id,omitempty"` // for deduplicate
-	ClientID string          `json:"client_id" binding:"required"`
-	Symbol   string          `json:"symbol" binding:"required"`
-	Side     Side            `json:"side" binding:"required"`
-	Type     OrderType       `json:"type" binding:"required"`
-	Price    decimal.Decimal `json:"price,omitempty"` // for limited order
-	Quantity decimal.Decimal `json:"quantity" binding:"required"`
+	OrderID string `json:"order_id,omitempty"` // for deduplicate
+	// ClientID may be left out by credentials bound to a client, as on every order request
+	ClientID string `json:"client_id"`
+	// ClientOrderID is the client's own ID of the order, unique among its open orders; the order
+	// can be looked up, cancelled and modified by it
+	ClientOrderID string          `json:"client_order_id,omitempty"`
+	Symbol        string          `json:"symbol" binding:"required"`
+	Side          Side            `json:"side" binding:"required"`
+	Type          OrderType       `json:"type" binding:"required"`
+	Price         decimal.Decimal `json:"price,omitempty"` // for limited order
+	Quantity      decimal.Decimal `json:"quantity" binding:"required"`
+	// TriggerPrice activates a STOP or STOP_LIMIT order once a trade executes at or through it
+	TriggerPrice decimal.Decimal `json:"trigger_price,omitempty"`
+	// Tags label the order, e.g. with the strategy that placed it, and come back on its events
+	Tags []string `json:"tags,omitempty"`
+	// UserData is opaque to the engine: stored and echoed verbatim on the order, its events and fills
+	UserData string `json:"user_data,omitempty"`
+	// ValidateOnly runs all checks and reports the outcome without persisting or matching
+	ValidateOnly bool `json:"validate_only,omitempty"`
 }
 
 type SubmitOrderResponse struct {
-	OrderID   string          `json:"order_id"`
-	Trades    []Trade         `json:"trades"`
-	Remaining decimal.Decimal `json:"remaining"`
-	Message   string          `json:"message,omitempty"`
+	OrderID       string  `json:"order_id"`
+	ClientOrderID string  `json:"client_order_id,omitempty"`
+	Trades        []Trade `json:"trades"`
+	Remaining     string  `json:"remaining"`
+	Accepted      bool    `json:"accepted"`
+	RejectReason  string  `json:"reject_reason,omitempty"`
+	// RejectCode is the machine-readable RejectReason, one of the domain.RejectCode values
+	RejectCode string `json:"reject_code,omitempty"`
+	Message    string `json:"message,omitempty"`
+	UserData   string `json:"user_data,omitempty"`
+}
+
+// SubmitOrderGroupRequest submits the orders, of one client and possibly of different symbols, as a
+// basket: one order failing its checks rejects them all
+type SubmitOrderGroupRequest struct {
+	Orders []SubmitOrderRequest `json:"orders" binding:"required,min=1,dive"`
+	// CancelOnFill cancels the other orders once one of them fills completely
+	CancelOnFill bool `json:"cancel_on_fill,omitempty"`
+}
+
+// SubmitOrderGroupResponse has the outcome of every order of the basket in request order
+type SubmitOrderGroupResponse struct {
+	GroupID string                `json:"group_id"`
+	Legs    []SubmitOrderResponse `json:"legs"`
+	Error   string                `json:"error,omitempty"`
 }
 
+type CancelOrderGroupRequest struct {
+	ClientID string `json:"client_id"`
+	GroupID  string `json:"group_id" binding:"required"`
+}
+
+type CancelOrderGroupResponse struct {
+	CancelledOrderIDs []string `json:"cancelled_order_ids"`
+}
+
+type PreviewFill struct {
+	Price    decimal.Decimal `json:"price"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+type PreviewOrderResponse struct {
+	Symbol       string              `json:"symbol"`
+	Side         Side                `json:"side"`
+	Fills        []PreviewFill       `json:"fills"`
+	Filled       decimal.Decimal     `json:"filled"`
+	Remaining    decimal.Decimal     `json:"remaining"`
+	Notional     decimal.Decimal     `json:"notional"`
+	AvgPrice     decimal.NullDecimal `json:"avg_price"`
+	BestPrice    decimal.NullDecimal `json:"best_price"`
+	Slippage     decimal.NullDecimal `json:"slippage"`
+	EstimatedFee decimal.Decimal     `json:"estimated_fee"`
+}
+
+// ModifyOrderRequest names the order by order_id or else client_order_id
 type ModifyOrderRequest struct {
-	OrderID  string          `json:"order_id" binding:"required"`
-	ClientID string          `json:"client_id" binding:"required"`
-	NewPrice decimal.Decimal `json:"new_price,omitempty"`
-	NewQty   decimal.Decimal `json:"new_qty,omitempty"`
+	OrderID       string          `json:"order_id"`
+	ClientOrderID string          `json:"client_order_id,omitempty"`
+	ClientID      string          `json:"client_id"`
+	NewPrice      decimal.Decimal `json:"new_price,omitempty"`
+	NewQty        decimal.Decimal `json:"new_qty,omitempty"`
 }
 
 type ModifyOrderResponse struct {
 	OrderID  string `json:"order_id"`
 	Modified bool   `json:"modified"`
 	Message  string `json:"message,omitempty"`
+	// Trades are those of the modified order crossing the book
+	Trades []Trade `json:"trades,omitempty"`
+}
+
+type Amend struct {
+	OrderID  string          `json:"order_id" binding:"required"`
+	NewPrice decimal.Decimal `json:"new_price"`
+	NewQty   decimal.Decimal `json:"new_qty"`
+}
+
+type BatchModifyRequest struct {
+	ClientID string  `json:"client_id"`
+	Orders   []Amend `json:"orders" binding:"required,min=1,dive"`
+}
+
+type BatchModifyResponse struct {
+	Results []ModifyOrderResponse `json:"results"` // in request order, message holds the failure reason
 }
 
+// CancelOrderRequest names the order by order_id or else client_order_id
 type CancelOrderRequest struct {
-	OrderID  string `json:"order_id" binding:"required"`
-	ClientID string `json:"client_id" binding:"required"`
+	OrderID       string `json:"order_id"`
+	ClientOrderID string `json:"client_order_id,omitempty"`
+	ClientID      string `json:"client_id"`
+}
+
+// OrderByClientOrderIDRequest looks up the client's order by the ID the client gave it
+type OrderByClientOrderIDRequest struct {
+	ClientID string `form:"client_id"`
+}
+
+type ForceCancelRequest struct {
+	OrderID string `json:"order_id" binding:"required"`
+	Reason  string `json:"reason,omitempty"`
 }
 
 type CancelOrderResponse struct {
-	OrderID   string `json:"order_id"`
-	Cancelled bool   `json:"cancelled"`
-	Message   string `json:"message,omitempty"`
+	OrderID string `json:"order_id"`
+	// ClientOrderID is set in batch results of orders named by their client order ID
+	ClientOrderID string `json:"client_order_id,omitempty"`
+	Cancelled     bool   `json:"cancelled"`
+	Message       string `json:"message,omitempty"`
+}
+
+// BatchCancelRequest names the orders by order_ids, client_order_ids or both; results list the
+// order IDs first
+type BatchCancelRequest struct {
+	ClientID       string   `json:"client_id"`
+	OrderIDs       []string `json:"order_ids"`
+	ClientOrderIDs []string `json:"client_order_ids"`
+}
+
+type BatchCancelResponse struct {
+	Results []CancelOrderResponse `json:"results"`
+}
+
+type CancelBySideRequest struct {
+	ClientID string `json:"client_id"`
+	Symbol   string `json:"symbol" binding:"required"`
+	Side     Side   `json:"side" binding:"required"`
+}
+
+type CancelBySideResponse struct {
+	CancelledOrderIDs []string `json:"cancelled_order_ids"`
+}
+
+// CancelAllRequest cancels on every symbol without Symbol and on both sides without Side
+type CancelAllRequest struct {
+	ClientID string `json:"client_id"`
+	Symbol   string `json:"symbol,omitempty"`
+	Side     Side   `json:"side,omitempty"`
+}
+
+type CancelAllResponse struct {
+	CancelledOrderIDs []string `json:"cancelled_order_ids"`
+}
+
+// ListOrdersRequest pages through the client's orders, oldest first; Status is OPEN, PARTIALLY_FILLED,
+// PENDING, FILLED or CANCELLED
+type ListOrdersRequest struct {
+	ClientID string `form:"client_id"`
+	Symbol   string `form:"symbol"`
+	Status   string `form:"status"`
+	Limit    int    `form:"limit"`
+	Cursor   string `form:"cursor"`
+}
+
+type ListOrdersResponse struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type NotificationPreference struct {
+	ClientID  string   `json:"client_id"`
+	Channel   string   `json:"channel" binding:"required"`
+	Target    string   `json:"target,omitempty"`
+	ExecTypes []string `json:"exec_types,omitempty"`
+}
+
+type NotificationPreferencesResponse struct {
+	Preferences []NotificationPreference `json:"preferences"`
 }
 
 type GetOrderRequest struct {
@@ -76,12 +230,90 @@ type GetOrderResponse struct {
 	Order Order `json:"order"`
 }
 
+type GetQueuePositionRequest struct {
+	OrderID  string `form:"order_id" binding:"required"`
+	ClientID string `form:"client_id"`
+}
+
+type GetQueuePositionResponse struct {
+	OrderID       string          `json:"order_id"`
+	Symbol        string          `json:"symbol"`
+	Side          Side            `json:"side"`
+	Price         decimal.Decimal `json:"price"`
+	Position      int             `json:"position"`
+	OrdersAhead   int             `json:"orders_ahead"`
+	QuantityAhead decimal.Decimal `json:"quantity_ahead"`
+	LevelOrders   int             `json:"level_orders"`
+	LevelQuantity decimal.Decimal `json:"level_quantity"`
+	Remaining     decimal.Decimal `json:"remaining"`
+}
+
+// OrderHistoryRequest scopes GET /orders/:id/history to the order's owner
+type OrderHistoryRequest struct {
+	ClientID string `form:"client_id"`
+}
+
+type OrderHistoryResponse struct {
+	OrderID     string            `json:"order_id"`
+	Transitions []OrderTransition `json:"transitions"`
+}
+
+// OrderTransition is one step of an order's history; cause is PLACED, REJECTED, MATCHED, MODIFIED,
+// CANCELLED_BY_USER, ADMIN, DELISTED, EXPIRED, TRIGGERED or DISCONNECTED
+type OrderTransition struct {
+	ExecType  string          `json:"exec_type"`
+	Status    string          `json:"status"`
+	Cause     string          `json:"cause"`
+	Reason    string          `json:"reason,omitempty"`
+	Remaining decimal.Decimal `json:"remaining"`
+	TradeID   string          `json:"trade_id,omitempty"`
+	At        time.Time       `json:"at"`
+	Sequence  uint64          `json:"sequence"`
+}
+
+type LoginRequest struct {
+	ClientID string `json:"client_id"`
+	// CancelOnDisconnect cancels the session's resting orders when it logs out or misses its heartbeats
+	CancelOnDisconnect bool `json:"cancel_on_disconnect"`
+}
+
+// Session is a logged-in trading connection; the token is only returned by login
+type Session struct {
+	SessionID          string    `json:"session_id"`
+	Token              string    `json:"token,omitempty"`
+	ClientID           string    `json:"client_id"`
+	CancelOnDisconnect bool      `json:"cancel_on_disconnect"`
+	CreatedAt          time.Time `json:"created_at"`
+	LastSeen           time.Time `json:"last_seen"`
+}
+
+// SessionCancelResponse lists the orders cancelled by a logout or a session mass cancel
+type SessionCancelResponse struct {
+	Cancelled []string `json:"cancelled"`
+}
+
 type GetTradesRequest struct {
 	OrderID string `json:"order_id" binding:"required"`
 }
 
 type GetTradesResponse struct {
-	Trades []Trade `json:"trades"`
+	Trades     []Trade `json:"trades"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// SymbolTradesRequest pages through a symbol's stored trades executed in [from, to)
+type SymbolTradesRequest struct {
+	Symbol string    `form:"symbol" binding:"required"`
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit  int       `form:"limit"`
+	Cursor string    `form:"cursor"`
+}
+
+type GetRecentTradesRequest struct {
+	Symbol string `form:"symbol" binding:"required"`
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
 }
 
 type GetOrderbookRequest struct {
@@ -92,6 +324,69 @@ type GetOrderbookResponse struct {
 	Bids      []Order   `json:"bids"`
 	Asks      []Order   `json:"asks"`
 	Timestamp time.Time `json:"timestamp"`
+	// Sequence is the engine sequence number the book is fresh as of, 0 if unknown
+	Sequence uint64 `json:"sequence,omitempty"`
+}
+
+// GetDepthRequest selects GET /orderbook/depth; levels defaults to 20 per side, and step, when set,
+// merges prices into buckets of that width
+type GetDepthRequest struct {
+	Symbol string `form:"symbol" binding:"required"`
+	Levels int    `form:"levels" binding:"min=0"`
+	Step   string `form:"step"`
+}
+
+type DepthLevel struct {
+	Price    decimal.Decimal `json:"price"`
+	Quantity decimal.Decimal `json:"quantity"`
+	Orders   int             `json:"orders"`
+}
+
+type GetDepthResponse struct {
+	Symbol    string          `json:"symbol"`
+	Step      decimal.Decimal `json:"step"`
+	Bids      []DepthLevel    `json:"bids"`
+	Asks      []DepthLevel    `json:"asks"`
+	Timestamp time.Time       `json:"timestamp"`
+	Sequence  uint64          `json:"sequence,omitempty"`
+}
+
+// CandlesRequest selects GET /candles: the last limit bars of the interval (1m, 5m, 1h or 1d)
+// starting in [from, to); times are RFC 3339 and either end may be left open
+type CandlesRequest struct {
+	Symbol   string    `form:"symbol" binding:"required"`
+	Interval string    `form:"interval" binding:"required"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit    int       `form:"limit" binding:"min=0"`
+}
+
+type Candle struct {
+	Start    time.Time       `json:"start"`
+	Open     decimal.Decimal `json:"open"`
+	High     decimal.Decimal `json:"high"`
+	Low      decimal.Decimal `json:"low"`
+	Close    decimal.Decimal `json:"close"`
+	Volume   decimal.Decimal `json:"volume"`
+	Notional decimal.Decimal `json:"notional"`
+	Trades   int             `json:"trades"`
+}
+
+type CandlesResponse struct {
+	Symbol   string   `json:"symbol"`
+	Interval string   `json:"interval"`
+	Candles  []Candle `json:"candles"` // oldest first
+}
+
+type QuoteResponse struct {
+	Symbol    string              `json:"symbol"`
+	BidPrice  decimal.NullDecimal `json:"bid_price"`
+	BidQty    decimal.Decimal     `json:"bid_qty"`
+	AskPrice  decimal.NullDecimal `json:"ask_price"`
+	AskQty    decimal.Decimal     `json:"ask_qty"`
+	Spread    decimal.NullDecimal `json:"spread"`
+	Mid       decimal.NullDecimal `json:"mid"`
+	Timestamp time.Time           `json:"timestamp"`
 }
 
 type SnapshotRequest struct {
@@ -112,24 +407,515 @@ type RestoreResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
-type Order struct {
-	ID        string          `json:"id"`
+type ListSnapshotsRequest struct {
+	Symbol string `form:"symbol" binding:"required"`
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+type SnapshotMeta struct {
+	SnapshotID string    `json:"snapshot_id"`
+	Symbol     string    `json:"symbol"`
+	Sequence   int64     `json:"sequence"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Bids       int       `json:"bids"`
+	Asks       int       `json:"asks"`
+	Bytes      int       `json:"bytes"`
+}
+
+type ListSnapshotsResponse struct {
+	Snapshots  []SnapshotMeta `json:"snapshots"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type GetSnapshotResponse struct {
+	SnapshotMeta
+	Bids []Order `json:"bids"`
+	Asks []Order `json:"asks"`
+}
+
+type DeleteSnapshotRequest struct {
+	SnapshotID string `json:"snapshot_id" binding:"required"`
+}
+
+type PruneSnapshotsRequest struct {
+	Symbol    string    `json:"symbol" binding:"required"`
+	OlderThan time.Time `json:"older_than" binding:"required"`
+}
+
+type PruneSnapshotsResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+type RetentionResponse struct {
+	Archived     int `json:"archived"`
+	PurgedOrders int `json:"purged_orders"`
+	PurgedTrades int `json:"purged_trades"`
+}
+
+// DailyReportRequest selects a UTC day, formatted 2006-01-02
+type DailyReportRequest struct {
+	Day time.Time `json:"day" form:"day" binding:"required" time_format:"2006-01-02"`
+	// Format of GET downloads: json (default) or csv; csv needs Part
+	Format string `form:"format"`
+	// Part of a csv download: symbols or clients
+	Part string `form:"part"`
+}
+
+type SymbolDayReport struct {
+	Symbol   string          `json:"symbol"`
+	Trades   int             `json:"trades"`
+	Volume   decimal.Decimal `json:"volume"`
+	Notional decimal.Decimal `json:"notional"`
+	VWAP     decimal.Decimal `json:"vwap"`
+	High     decimal.Decimal `json:"high"`
+	Low      decimal.Decimal `json:"low"`
+}
+
+type ClientDayReport struct {
 	ClientID  string          `json:"client_id"`
+	Orders    int             `json:"orders"`
+	Cancelled int             `json:"cancelled"`
+	Fills     int             `json:"fills"`
+	Volume    decimal.Decimal `json:"volume"`
+	Notional  decimal.Decimal `json:"notional"`
+}
+
+type DailyReport struct {
+	Day         string            `json:"day"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Symbols     []SymbolDayReport `json:"symbols"`
+	Clients     []ClientDayReport `json:"clients"`
+}
+
+// StatementRequest selects a client's statement over [from, to); times are RFC 3339
+type StatementRequest struct {
+	ClientID string    `form:"client_id"`
+	From     time.Time `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Format is json (default) or csv; csv needs Part
+	Format string `form:"format"`
+	// Part of a csv download: fills, movements or positions
+	Part string `form:"part"`
+	// Tag keeps only the fills of orders carrying it
+	Tag string `form:"tag"`
+}
+
+type StatementFill struct {
+	TradeID   string          `json:"trade_id"`
+	OrderID   string          `json:"order_id"`
 	Symbol    string          `json:"symbol"`
 	Side      Side            `json:"side"`
-	Type      OrderType       `json:"type"`
+	Liquidity string          `json:"liquidity,omitempty"`
 	Price     decimal.Decimal `json:"price"`
 	Quantity  decimal.Decimal `json:"quantity"`
+	Fee       decimal.Decimal `json:"fee"`
+	Timestamp time.Time       `json:"timestamp"`
+	Tags      []string        `json:"tags,omitempty"`
+	UserData  string          `json:"user_data,omitempty"`
+}
+
+type LedgerMovement struct {
+	Timestamp time.Time       `json:"timestamp"`
+	TradeID   string          `json:"trade_id"`
+	Kind      string          `json:"kind"`
+	Asset     string          `json:"asset"`
+	Amount    decimal.Decimal `json:"amount"`
+}
+
+type AssetAmount struct {
+	Asset  string          `json:"asset"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type Statement struct {
+	ClientID  string           `json:"client_id"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Fills     []StatementFill  `json:"fills"`
+	Movements []LedgerMovement `json:"movements"`
+	Positions []AssetAmount    `json:"positions"`
+	Fees      []AssetAmount    `json:"fees"`
+}
+
+// DelistRequest schedules a delisting; halt_at defaults to now
+type DelistRequest struct {
+	Symbol   string    `json:"symbol" binding:"required"`
+	HaltAt   time.Time `json:"halt_at"`
+	DelistAt time.Time `json:"delist_at" binding:"required"`
+	Reason   string    `json:"reason"`
+}
+
+type WithdrawDelistingRequest struct {
+	Symbol string `json:"symbol" binding:"required"`
+}
+
+type Delisting struct {
+	Symbol      string     `json:"symbol"`
+	Phase       string     `json:"phase"`
+	Reason      string     `json:"reason,omitempty"`
+	AnnouncedAt time.Time  `json:"announced_at"`
+	HaltAt      time.Time  `json:"halt_at"`
+	DelistAt    time.Time  `json:"delist_at"`
+	DelistedAt  *time.Time `json:"delisted_at,omitempty"`
+}
+
+// SaveInstrumentRequest registers a symbol or replaces its definition; the assets default to the halves
+// of a BASE/QUOTE symbol, zero steps and minimum do not constrain, and Status is TRADING (the default)
+// or HALTED
+type SaveInstrumentRequest struct {
+	Symbol      string          `json:"symbol" binding:"required"`
+	BaseAsset   string          `json:"base_asset"`
+	QuoteAsset  string          `json:"quote_asset"`
+	TickSize    decimal.Decimal `json:"tick_size"`
+	LotSize     decimal.Decimal `json:"lot_size"`
+	MinNotional decimal.Decimal `json:"min_notional"`
+	Status      string          `json:"status"`
+}
+
+type Instrument struct {
+	Symbol      string          `json:"symbol"`
+	BaseAsset   string          `json:"base_asset"`
+	QuoteAsset  string          `json:"quote_asset"`
+	TickSize    decimal.Decimal `json:"tick_size"`
+	LotSize     decimal.Decimal `json:"lot_size"`
+	MinNotional decimal.Decimal `json:"min_notional"`
+	Status      string          `json:"status"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// CancelOnlyRequest switches cancel-only mode for a symbol, or the whole engine without one
+type CancelOnlyRequest struct {
+	Symbol  string `json:"symbol"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+type CancelOnly struct {
+	// Symbol is empty for the engine-wide mode
+	Symbol string    `json:"symbol,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+type ExportSnapshotRequest struct {
+	SnapshotID string `json:"snapshot_id" binding:"required"`
+}
+
+type ExportSnapshotResponse struct {
+	Key string `json:"key"`
+}
+
+type ImportSnapshotRequest struct {
+	Key string `json:"key" binding:"required"`
+}
+
+type StoredObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+type ListSnapshotExportsRequest struct {
+	Symbol string `form:"symbol"`
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+type ListSnapshotExportsResponse struct {
+	Exports    []StoredObject `json:"exports"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type Order struct {
+	ID            string    `json:"id"`
+	ClientID      string    `json:"client_id"`
+	ClientOrderID string    `json:"client_order_id,omitempty"`
+	Symbol        string    `json:"symbol"`
+	Side          Side      `json:"side"`
+	Type          OrderType `json:"type"`
+	Price         string    `json:"price"` // with the symbol's configured decimal places
+	Quantity      string    `json:"quantity"`
+	Remaining     string    `json:"remaining"`
+	Status        string    `json:"status"`
+	// TriggerPrice is set on stop orders, which wait off the book as PENDING until it is reached
+	TriggerPrice string    `json:"trigger_price,omitempty"`
+	SessionID    string    `json:"session_id,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+	UserData     string    `json:"user_data,omitempty"`
+	GroupID      string    `json:"group_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// BookDumpRequest selects the book of GET /admin/orderbook/dump; format is json (default) or text
+type BookDumpRequest struct {
+	Symbol string `form:"symbol" binding:"required"`
+	Format string `form:"format"`
+}
+
+// BookDump is a symbol's order-by-order book; levels are best first and orders in queue order
+type BookDump struct {
+	Symbol   string      `json:"symbol"`
+	At       time.Time   `json:"at"`
+	Bids     []DumpLevel `json:"bids"`
+	Asks     []DumpLevel `json:"asks"`
+	Triggers []DumpOrder `json:"triggers"`
+}
+
+type DumpLevel struct {
+	Price     decimal.Decimal `json:"price"`
 	Remaining decimal.Decimal `json:"remaining"`
-	Status    string          `json:"status"`
-	CreatedAt time.Time       `json:"created_at"`
+	Display   decimal.Decimal `json:"display"`
+	Hidden    decimal.Decimal `json:"hidden"`
+	Orders    []DumpOrder     `json:"orders"`
+}
+
+// DumpOrder is a resting order with its 1-based queue position and the quantity ahead of it
+type DumpOrder struct {
+	Order
+	Position int             `json:"position"`
+	Ahead    decimal.Decimal `json:"ahead"`
+	Display  decimal.Decimal `json:"display"`
+	Hidden   decimal.Decimal `json:"hidden"`
 }
 
 type Trade struct {
 	ID        string          `json:"id"`
+	Symbol    string          `json:"symbol,omitempty"`
 	BuyOrder  string          `json:"buy_order"`
 	SellOrder string          `json:"sell_order"`
-	Price     decimal.Decimal `json:"price"`
-	Quantity  decimal.Decimal `json:"quantity"`
+	Price     string          `json:"price"` // with the symbol's configured decimal places
+	Quantity  string          `json:"quantity"`
 	Timestamp time.Time       `json:"timestamp"`
+	MakerFee  decimal.Decimal `json:"maker_fee"`
+	TakerFee  decimal.Decimal `json:"taker_fee"`
+	Sequence  uint64          `json:"sequence,omitempty"`
+
+	MakerFeeAsset string `json:"maker_fee_asset,omitempty"`
+	TakerFeeAsset string `json:"taker_fee_asset,omitempty"`
+	TakerSide     string `json:"taker_side,omitempty"`
+	TakerType     string `json:"taker_type,omitempty"`
+	BuyLiquidity  string `json:"buy_liquidity,omitempty"`
+	SellLiquidity string `json:"sell_liquidity,omitempty"`
+	// BuyClient and SellClient are set on compliance exports only
+	BuyClient  string `json:"buy_client,omitempty"`
+	SellClient string `json:"sell_client,omitempty"`
+}
+
+// ExportRequest filters a bulk NDJSON export; times are RFC 3339, from inclusive and to exclusive
+type ExportRequest struct {
+	Symbol string    `form:"symbol"`
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Tag keeps orders carrying it and trades of such orders
+	Tag string `form:"tag"`
+}
+
+type SandboxResetRequest struct {
+	ClientID string `json:"client_id"`
+}
+
+type SandboxBalancesResponse struct {
+	ClientID string                     `json:"client_id"`
+	Balances map[string]decimal.Decimal `json:"balances"`
+}
+
+// Balance is a client's balance of one asset; locked is held by its open orders
+type Balance struct {
+	Asset     string          `json:"asset"`
+	Available decimal.Decimal `json:"available"`
+	Locked    decimal.Decimal `json:"locked"`
+	Total     decimal.Decimal `json:"total"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type BalancesResponse struct {
+	ClientID string    `json:"client_id"`
+	Balances []Balance `json:"balances"`
+}
+
+// AccountTransferRequest deposits to or withdraws from a client's available balance
+type AccountTransferRequest struct {
+	ClientID string          `json:"client_id" binding:"required"`
+	Asset    string          `json:"asset" binding:"required"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
+type SymbolRequest struct {
+	Symbol string `json:"symbol" binding:"required"`
+}
+
+type SymbolLoad struct {
+	Symbol string `json:"symbol"`
+	Ops    uint64 `json:"ops"`
+}
+
+type WorkerAssignment struct {
+	Worker    int          `json:"worker"`
+	Dedicated bool         `json:"dedicated"`
+	Queued    int          `json:"queued"`
+	Symbols   []SymbolLoad `json:"symbols"`
+}
+
+type WorkerAssignmentsResponse struct {
+	Workers []WorkerAssignment `json:"workers"`
+}
+
+type IsolateSymbolResponse struct {
+	Symbol string `json:"symbol"`
+	Worker int    `json:"worker"`
+}
+
+type StreamStats struct {
+	Topics       int    `json:"topics"`
+	Subscribers  int    `json:"subscribers"`
+	Queued       int    `json:"queued"`
+	Published    uint64 `json:"published"`
+	Dropped      uint64 `json:"dropped"`
+	Disconnected uint64 `json:"disconnected"`
+}
+
+// Readiness is the progress of loading the order books at boot
+type Readiness struct {
+	Ready      bool       `json:"ready"`
+	Symbols    int        `json:"symbols"`
+	Loaded     int        `json:"loaded"`
+	Orders     int        `json:"orders"`
+	Failed     []string   `json:"failed"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type StreamTopicStats struct {
+	Topic       string `json:"topic"`
+	Subscribers int    `json:"subscribers"`
+	Queued      int    `json:"queued"`
+	Published   uint64 `json:"published"`
+	Delivered   uint64 `json:"delivered"`
+	Dropped     uint64 `json:"dropped"`
+}
+
+// PoolStats is the database connection pool's state; counters and durations accumulate from startup
+type PoolStats struct {
+	MaxConns          int32  `json:"max_conns"`
+	TotalConns        int32  `json:"total_conns"`
+	InUseConns        int32  `json:"in_use_conns"`
+	IdleConns         int32  `json:"idle_conns"`
+	ConstructingConns int32  `json:"constructing_conns"`
+	Acquires          int64  `json:"acquires"`
+	AcquireTime       string `json:"acquire_time"`
+	Waits             int64  `json:"waits"`
+	WaitTime          string `json:"wait_time"`
+	AvgWait           string `json:"avg_wait"`
+	CancelledAcquires int64  `json:"cancelled_acquires"`
+	NewConns          int64  `json:"new_conns"`
+	ExpiredConns      int64  `json:"expired_conns"`
+	IdleClosedConns   int64  `json:"idle_closed_conns"`
+}
+
+// CacheBreaker is the state of the book cache's circuit breaker: CLOSED, OPEN or HALF_OPEN
+type CacheBreaker struct {
+	State               string    `json:"state"`
+	Since               time.Time `json:"since"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Failures            int64     `json:"failures"`
+	Trips               int64     `json:"trips"`
+	Rejected            int64     `json:"rejected"`
+}
+
+// ExecutionStatsRequest selects one client's or one symbol's execution statistics
+type ExecutionStatsRequest struct {
+	ClientID string `form:"client_id"`
+	Symbol   string `form:"symbol"`
+}
+
+// DurationBucket counts durations up to UpTo, a Go duration; the last bucket, without UpTo, counts the rest
+type DurationBucket struct {
+	UpTo  string `json:"up_to,omitempty"`
+	Count int64  `json:"count"`
+}
+
+type ExecutionStats struct {
+	ClientID           string           `json:"client_id,omitempty"`
+	Symbol             string           `json:"symbol,omitempty"`
+	Orders             int64            `json:"orders"`
+	Rejected           int64            `json:"rejected"`
+	Filled             int64            `json:"filled"`
+	Cancelled          int64            `json:"cancelled"`
+	Quantity           decimal.Decimal  `json:"quantity"`
+	FilledQuantity     decimal.Decimal  `json:"filled_quantity"`
+	FillRate           decimal.Decimal  `json:"fill_rate"`
+	SlippageBps        decimal.Decimal  `json:"slippage_bps"`
+	EffectiveSpreadBps decimal.Decimal  `json:"effective_spread_bps"`
+	TimeToFill         []DurationBucket `json:"time_to_fill"`
+}
+
+// ListAlertsRequest filters surveillance alerts; empty fields do not filter
+type ListAlertsRequest struct {
+	Status   string `form:"status"`
+	Kind     string `form:"kind"`
+	Symbol   string `form:"symbol"`
+	ClientID string `form:"client_id"`
+	Limit    int    `form:"limit"`
+	Cursor   string `form:"cursor"`
+}
+
+type SurveillanceAlert struct {
+	AlertID    string     `json:"alert_id"`
+	Kind       string     `json:"kind"`
+	Symbol     string     `json:"symbol"`
+	ClientIDs  []string   `json:"client_ids"`
+	TradeIDs   []string   `json:"trade_ids"`
+	Detail     string     `json:"detail"`
+	At         time.Time  `json:"at"`
+	DetectedAt time.Time  `json:"detected_at"`
+	Status     string     `json:"status"`
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewNote string     `json:"review_note,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+type ListAlertsResponse struct {
+	Alerts     []SurveillanceAlert `json:"alerts"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// ReviewAlertRequest records a compliance decision: Status is OPEN, ESCALATED or DISMISSED
+type ReviewAlertRequest struct {
+	AlertID  string `json:"alert_id" binding:"required"`
+	Status   string `json:"status" binding:"required"`
+	Reviewer string `json:"reviewer" binding:"required"`
+	Note     string `json:"note"`
+}
+
+// TickDataRequest asks for a symbol's trades, and optionally its book changes, between from,
+// inclusive, and to, exclusive
+type TickDataRequest struct {
+	Symbol      string    `json:"symbol" binding:"required"`
+	From        time.Time `json:"from" binding:"required"`
+	To          time.Time `json:"to" binding:"required"`
+	BookChanges bool      `json:"book_changes"`
+}
+
+// TickJob is a tick data job; files lists the names to download once it is DONE
+type TickJob struct {
+	ID          string     `json:"id"`
+	Symbol      string     `json:"symbol"`
+	From        time.Time  `json:"from"`
+	To          time.Time  `json:"to"`
+	BookChanges bool       `json:"book_changes"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	Files       []string   `json:"files"`
+	Trades      int        `json:"trades"`
+	Changes     int        `json:"changes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// TickDataFileRequest names the file of a tick data job to download, trades by default
+type TickDataFileRequest struct {
+	File string `form:"file"`
 }
@@ -0,0 +1,229 @@
+package fix
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	beginString = "FIX.4.4"
+	soh         = '\x01'
+	// maxBodyLength bounds the messages a counterparty may send
+	maxBodyLength = 64 << 10
+	// sendingTimeFormat is UTCTimestamp with milliseconds
+	sendingTimeFormat = "20060102-15:04:05.000"
+)
+
+// Tags the gateway reads or writes
+const (
+	tagAccount              = 1
+	tagAvgPx                = 6
+	tagBeginSeqNo           = 7
+	tagBeginString          = 8
+	tagBodyLength           = 9
+	tagCheckSum             = 10
+	tagClOrdID              = 11
+	tagCumQty               = 14
+	tagEndSeqNo             = 16
+	tagExecID               = 17
+	tagLastPx               = 31
+	tagLastQty              = 32
+	tagMsgSeqNum            = 34
+	tagMsgType              = 35
+	tagNewSeqNo             = 36
+	tagOrderID              = 37
+	tagOrderQty             = 38
+	tagOrdStatus            = 39
+	tagOrdType              = 40
+	tagOrigClOrdID          = 41
+	tagPossDupFlag          = 43
+	tagPrice                = 44
+	tagRefSeqNum            = 45
+	tagSenderCompID         = 49
+	tagSendingTime          = 52
+	tagSide                 = 54
+	tagSymbol               = 55
+	tagTargetCompID         = 56
+	tagText                 = 58
+	tagTimeInForce          = 59
+	tagTransactTime         = 60
+	tagEncryptMethod        = 98
+	tagStopPx               = 99
+	tagCxlRejReason         = 102
+	tagOrdRejReason         = 103
+	tagHeartBtInt           = 108
+	tagTestReqID            = 112
+	tagGapFillFlag          = 123
+	tagResetSeqNumFlag      = 141
+	tagExecType             = 150
+	tagLeavesQty            = 151
+	tagRefTagID             = 371
+	tagRefMsgType           = 372
+	tagSessionRejectReason  = 373
+	tagBusinessRejectReason = 380
+	tagCxlRejResponseTo     = 434
+	tagPassword             = 554
+)
+
+// Message types
+const (
+	msgHeartbeat                 = "0"
+	msgTestRequest               = "1"
+	msgResendRequest             = "2"
+	msgReject                    = "3"
+	msgSequenceReset             = "4"
+	msgLogout                    = "5"
+	msgExecutionReport           = "8"
+	msgOrderCancelReject         = "9"
+	msgLogon                     = "A"
+	msgNewOrderSingle            = "D"
+	msgOrderCancelRequest        = "F"
+	msgOrderCancelReplaceRequest = "G"
+	msgBusinessMessageReject     = "j"
+)
+
+// errGarbled is a message whose checksum or fields are wrong; FIX ignores such messages
+var errGarbled = errors.New("garbled message")
+
+type field struct {
+	tag   int
+	value string
+}
+
+// message is the body of a FIX message: its fields in order, without BeginString, BodyLength and
+// CheckSum
+type message struct {
+	fields []field
+}
+
+func newMessage(msgType string) *message {
+	return (&message{}).add(tagMsgType, msgType)
+}
+
+func (m *message) add(tag int, value string) *message {
+	m.fields = append(m.fields, field{tag: tag, value: value})
+	return m
+}
+
+// addIf adds the field when value is not empty
+func (m *message) addIf(tag int, value string) *message {
+	if value == "" {
+		return m
+	}
+	return m.add(tag, value)
+}
+
+// get returns the first value of tag, "" if the message has none
+func (m *message) get(tag int) string {
+	for _, f := range m.fields {
+		if f.tag == tag {
+			return f.value
+		}
+	}
+	return ""
+}
+
+func (m *message) msgType() string { return m.get(tagMsgType) }
+
+// seqNum is MsgSeqNum, 0 if it is missing or not a number
+func (m *message) seqNum() int {
+	n, _ := strconv.Atoi(m.get(tagMsgSeqNum))
+	return n
+}
+
+// missing returns the first of tags the message lacks, 0 if it has them all
+func (m *message) missing(tags ...int) int {
+	for _, tag := range tags {
+		if m.get(tag) == "" {
+			return tag
+		}
+	}
+	return 0
+}
+
+// readMessage reads the next message. A message with a wrong checksum or malformed fields returns
+// errGarbled and the stream stays usable; any other error means the framing is lost.
+func readMessage(r *bufio.Reader) (*message, error) {
+	begin, err := r.ReadString(soh)
+	if err != nil {
+		return nil, err
+	}
+	if begin != "8="+beginString+string(soh) {
+		return nil, fmt.Errorf("unexpected BeginString %q", strings.TrimSuffix(begin, string(soh)))
+	}
+	length, err := r.ReadString(soh)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(length, "9="), string(soh)))
+	if !strings.HasPrefix(length, "9=") || err != nil || n <= 0 || n > maxBodyLength {
+		return nil, fmt.Errorf("bad BodyLength %q", strings.TrimSuffix(length, string(soh)))
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	trailer, err := r.ReadString(soh)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(trailer, "10=") {
+		return nil, fmt.Errorf("expected CheckSum, got %q", strings.TrimSuffix(trailer, string(soh)))
+	}
+	sum, err := strconv.Atoi(strings.TrimSuffix(trailer[3:], string(soh)))
+	if err != nil || sum != checksum([]byte(begin), []byte(length), body) || body[n-1] != soh {
+		return nil, errGarbled
+	}
+
+	m := &message{}
+	for _, raw := range bytes.Split(body[:n-1], []byte{soh}) {
+		tag, value, ok := bytes.Cut(raw, []byte{'='})
+		t, err := strconv.Atoi(string(tag))
+		if !ok || err != nil || t <= 0 {
+			return nil, errGarbled
+		}
+		m.fields = append(m.fields, field{tag: t, value: string(value)})
+	}
+	return m, nil
+}
+
+// checksum is the sum of the bytes of parts modulo 256
+func checksum(parts ...[]byte) int {
+	sum := 0
+	for _, p := range parts {
+		for _, c := range p {
+			sum += int(c)
+		}
+	}
+	return sum % 256
+}
+
+// encode frames the message with its session header, BodyLength and CheckSum
+func encode(m *message, sender, target string, seq int, at time.Time) []byte {
+	var body bytes.Buffer
+	put := func(tag int, value string) {
+		body.WriteString(strconv.Itoa(tag))
+		body.WriteByte('=')
+		body.WriteString(value)
+		body.WriteByte(soh)
+	}
+	put(tagMsgType, m.msgType())
+	put(tagSenderCompID, sender)
+	put(tagTargetCompID, target)
+	put(tagMsgSeqNum, strconv.Itoa(seq))
+	put(tagSendingTime, at.UTC().Format(sendingTimeFormat))
+	for _, f := range m.fields {
+		if f.tag != tagMsgType {
+			put(f.tag, f.value)
+		}
+	}
+	head := fmt.Sprintf("8=%s%c9=%d%c", beginString, soh, body.Len(), soh)
+	out := append([]byte(head), body.Bytes()...)
+	return append(out, fmt.Sprintf("10=%03d%c", checksum(out), soh)...)
+}
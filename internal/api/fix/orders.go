@@ -0,0 +1,373 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// order is what a session keeps of an order it placed, to report it in FIX terms
+type order struct {
+	id, clOrdID string
+	// pending is the ClOrdID of a cancel or replace sent to the engine and not yet reported
+	pending string
+	symbol  string
+	side    domain.Side
+	typ     domain.OrderType
+	price   decimal.Decimal
+	qty     decimal.Decimal
+	// cumQty and notional sum the order's fills, for CumQty and AvgPx
+	cumQty   decimal.Decimal
+	notional decimal.Decimal
+	status   string // OrdStatus of the last report
+}
+
+var (
+	sides = map[string]domain.Side{"1": domain.Buy, "2": domain.Sell}
+	types = map[string]domain.OrderType{"1": domain.Market, "2": domain.Limit, "3": domain.Stop, "4": domain.StopLimit}
+
+	execTypes = map[domain.ExecType]string{
+		domain.ExecNew:         "0",
+		domain.ExecPartialFill: "F",
+		domain.ExecFill:        "F",
+		domain.ExecCanceled:    "4",
+		domain.ExecReplaced:    "5",
+		domain.ExecRejected:    "8",
+		domain.ExecExpired:     "C",
+		domain.ExecTriggered:   "L",
+	}
+
+	// ordRejReasons maps reject codes to OrdRejReason; the rest are 99, Other
+	ordRejReasons = map[domain.RejectCode]string{
+		domain.RejectUnknownSymbol:          "1",
+		domain.RejectSymbolDelisted:         "1",
+		domain.RejectSymbolHalted:           "2",
+		domain.RejectCancelOnly:             "2",
+		domain.RejectInsufficientFunds:      "3",
+		domain.RejectDuplicateClientOrderID: "6",
+		domain.RejectInvalidQuantity:        "13",
+		domain.RejectMinNotional:            "13",
+	}
+)
+
+// fixCode returns the FIX code of v in codes
+func fixCode[V comparable](codes map[string]V, v V) string {
+	for code, w := range codes {
+		if w == v {
+			return code
+		}
+	}
+	return ""
+}
+
+// orderOf reads the order a NewOrderSingle places
+func orderOf(m *message) (*domain.Order, error) {
+	o := &domain.Order{Symbol: m.get(tagSymbol)}
+	var ok bool
+	if o.Side, ok = sides[m.get(tagSide)]; !ok {
+		return nil, domain.Reject(domain.RejectInvalidOrder, "unsupported Side %q", m.get(tagSide))
+	}
+	if o.Type, ok = types[m.get(tagOrdType)]; !ok {
+		return nil, domain.Reject(domain.RejectInvalidOrder, "unsupported OrdType %q", m.get(tagOrdType))
+	}
+	switch tif := m.get(tagTimeInForce); tif {
+	case "", "0", "1":
+	default:
+		return nil, domain.Reject(domain.RejectInvalidOrder, "unsupported TimeInForce %q, orders rest until cancelled", tif)
+	}
+	var err error
+	if o.Quantity, err = decimal.NewFromString(m.get(tagOrderQty)); err != nil {
+		return nil, domain.Reject(domain.RejectInvalidQuantity, "bad OrderQty %q", m.get(tagOrderQty))
+	}
+	if o.Type.HasLimit() {
+		if o.Price, err = decimal.NewFromString(m.get(tagPrice)); err != nil {
+			return nil, domain.Reject(domain.RejectInvalidPrice, "bad or missing Price %q", m.get(tagPrice))
+		}
+	}
+	if o.Type.IsStop() {
+		if o.TriggerPrice, err = decimal.NewFromString(m.get(tagStopPx)); err != nil {
+			return nil, domain.Reject(domain.RejectInvalidPrice, "bad or missing StopPx %q", m.get(tagStopPx))
+		}
+	}
+	return o, nil
+}
+
+// newOrder places a NewOrderSingle. Its outcome is reported by the order's events; a refusal before
+// the engine accepted the order is reported here.
+func (s *session) newOrder(m *message) {
+	if tag := m.missing(tagClOrdID, tagSymbol, tagSide, tagOrderQty, tagOrdType); tag != 0 {
+		s.rejectMissing(m, tag)
+		return
+	}
+	clOrdID := m.get(tagClOrdID)
+	o, err := orderOf(m)
+	if err == nil {
+		if account := m.get(tagAccount); account != "" && account != s.clientID {
+			err = domain.Reject(domain.RejectInvalidOrder, "Account %q is not the session's client", account)
+		}
+	}
+	if err != nil {
+		s.send(rejectedOrder(m, s.clientID, err))
+		return
+	}
+	o.ID, o.ClientID, o.ClientOrderID, o.SessionID = uuid.NewString(), s.clientID, clOrdID, s.sessionID
+
+	s.mu.Lock()
+	if _, dup := s.clOrdIDs[clOrdID]; dup {
+		s.mu.Unlock()
+		s.send(rejectedOrder(m, s.clientID, domain.Reject(domain.RejectDuplicateClientOrderID, "ClOrdID %q is in use", clOrdID)))
+		return
+	}
+	s.orders[o.ID] = &order{
+		id: o.ID, clOrdID: clOrdID, symbol: o.Symbol, side: o.Side, typ: o.Type, price: o.Price, qty: o.Quantity,
+		status: "A",
+	}
+	s.clOrdIDs[clOrdID] = o.ID
+	s.mu.Unlock()
+
+	if _, err := s.srv.Eng.SubmitOrder(s.ctx, o); err != nil && s.forget(o.ID) {
+		s.send(rejectedOrder(m, s.clientID, err))
+	}
+}
+
+// forget drops an order whose events have not ended it yet and reports whether it did
+func (s *session) forget(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.orders[id]
+	if ok {
+		delete(s.orders, id)
+		delete(s.clOrdIDs, st.clOrdID)
+	}
+	return ok
+}
+
+// rejectedOrder is the ExecutionReport of a NewOrderSingle refused before it became an order
+func rejectedOrder(m *message, clientID string, err error) *message {
+	return newMessage(msgExecutionReport).
+		add(tagOrderID, "NONE").
+		add(tagClOrdID, m.get(tagClOrdID)).
+		add(tagExecID, uuid.NewString()).
+		add(tagExecType, "8").
+		add(tagOrdStatus, "8").
+		add(tagAccount, clientID).
+		add(tagSymbol, m.get(tagSymbol)).
+		add(tagSide, m.get(tagSide)).
+		addIf(tagOrdType, m.get(tagOrdType)).
+		add(tagOrderQty, m.get(tagOrderQty)).
+		add(tagLeavesQty, "0").
+		add(tagCumQty, "0").
+		add(tagAvgPx, "0").
+		add(tagOrdRejReason, ordRejReason(domain.RejectCodeOf(err))).
+		add(tagText, err.Error()).
+		add(tagTransactTime, time.Now().UTC().Format(sendingTimeFormat))
+}
+
+func ordRejReason(code domain.RejectCode) string {
+	if r, ok := ordRejReasons[code]; ok {
+		return r
+	}
+	return "99"
+}
+
+// cancelOrder sends an OrderCancelRequest to the engine; the cancellation is reported by its event
+func (s *session) cancelOrder(m *message) {
+	if tag := m.missing(tagClOrdID, tagSymbol, tagSide); tag != 0 {
+		s.rejectMissing(m, tag)
+		return
+	}
+	st, reject := s.pend(m, "1")
+	if st == nil {
+		s.send(reject)
+		return
+	}
+	ok, err := s.srv.Eng.CancelOrder(s.ctx, st.id, s.clientID)
+	if err == nil && !ok {
+		err = errors.New("order is not open")
+	}
+	if err != nil {
+		s.send(s.unpend(m, st, "1", err))
+	}
+}
+
+// replaceOrder sends an OrderCancelReplaceRequest to the engine, which changes the price and
+// quantity of an open order in place; the change is reported by its event
+func (s *session) replaceOrder(m *message) {
+	if tag := m.missing(tagClOrdID, tagSymbol, tagSide, tagOrderQty, tagOrdType); tag != 0 {
+		s.rejectMissing(m, tag)
+		return
+	}
+	qty, qerr := decimal.NewFromString(m.get(tagOrderQty))
+	price, perr := decimal.NewFromString(m.get(tagPrice))
+	st, reject := s.pend(m, "2")
+	if st == nil {
+		s.send(reject)
+		return
+	}
+	var err error
+	switch {
+	case qerr != nil:
+		err = fmt.Errorf("bad OrderQty %q", m.get(tagOrderQty))
+	case m.get(tagPrice) == "":
+		price = st.price
+	case perr != nil:
+		err = fmt.Errorf("bad Price %q", m.get(tagPrice))
+	}
+	if err == nil && types[m.get(tagOrdType)] != st.typ {
+		err = errors.New("OrdType cannot be changed")
+	}
+	if err == nil {
+		_, err = s.srv.Eng.ModifyOrder(s.ctx, st.id, s.clientID, price, qty)
+	}
+	if err != nil {
+		s.send(s.unpend(m, st, "2", err))
+	}
+}
+
+// pend finds the order a cancel or replace refers to, by OrderID or else OrigClOrdID, and marks the
+// request pending on it. Without such an order, or with another request pending, it returns the
+// OrderCancelReject to send instead.
+func (s *session) pend(m *message, responseTo string) (*order, *message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := m.get(tagOrderID)
+	if id == "" {
+		id = s.clOrdIDs[m.get(tagOrigClOrdID)]
+	}
+	st, ok := s.orders[id]
+	switch {
+	case !ok:
+		return nil, cancelReject(m, "NONE", "8", responseTo, "1", "unknown order")
+	case st.pending != "":
+		return nil, cancelReject(m, st.id, st.status, responseTo, "3", "a cancel or replace of the order is pending")
+	}
+	if _, dup := s.clOrdIDs[m.get(tagClOrdID)]; dup {
+		return nil, cancelReject(m, st.id, st.status, responseTo, "6", fmt.Sprintf("ClOrdID %q is in use", m.get(tagClOrdID)))
+	}
+	st.pending = m.get(tagClOrdID)
+	return st, nil
+}
+
+// unpend clears the request the engine refused and returns its OrderCancelReject
+func (s *session) unpend(m *message, st *order, responseTo string, err error) *message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.pending = ""
+	return cancelReject(m, st.id, st.status, responseTo, "0", err.Error())
+}
+
+func cancelReject(m *message, orderID, ordStatus, responseTo, reason, text string) *message {
+	return newMessage(msgOrderCancelReject).
+		add(tagOrderID, orderID).
+		add(tagClOrdID, m.get(tagClOrdID)).
+		add(tagOrigClOrdID, m.get(tagOrigClOrdID)).
+		add(tagOrdStatus, ordStatus).
+		add(tagCxlRejResponseTo, responseTo).
+		add(tagCxlRejReason, reason).
+		add(tagText, text)
+}
+
+// report returns the ExecutionReport of an order event, nil for orders the session did not place.
+// A cancel or replace pending on the order takes its ClOrdID over when the event reports it done.
+func (s *session) report(ev *domain.OrderEvent) *message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.orders[ev.OrderID]
+	if !ok {
+		return nil
+	}
+	var orig string
+	if st.pending != "" && (ev.ExecType == domain.ExecCanceled || ev.ExecType == domain.ExecReplaced) {
+		delete(s.clOrdIDs, st.clOrdID)
+		orig, st.clOrdID, st.pending = st.clOrdID, st.pending, ""
+		s.clOrdIDs[st.clOrdID] = st.id
+	}
+	fill := ev.LastQty.IsPositive()
+	if fill {
+		st.cumQty = st.cumQty.Add(ev.LastQty)
+		st.notional = st.notional.Add(ev.LastQty.Mul(ev.LastPrice))
+	}
+	if ev.ExecType == domain.ExecReplaced {
+		st.price, st.qty = ev.Price, ev.Quantity
+	}
+	st.status = ordStatus(ev)
+	done := st.status == "2" || st.status == "4" || st.status == "8" || st.status == "C"
+
+	leaves := ev.Remaining
+	if done {
+		leaves = decimal.Zero
+	}
+	avgPx := decimal.Zero
+	if st.cumQty.IsPositive() {
+		avgPx = st.notional.Div(st.cumQty)
+	}
+	execType, ok := execTypes[ev.ExecType]
+	if !ok {
+		execType = "I"
+	}
+	m := newMessage(msgExecutionReport).
+		add(tagOrderID, st.id).
+		add(tagClOrdID, st.clOrdID).
+		addIf(tagOrigClOrdID, orig).
+		add(tagExecID, st.id+"-"+strconv.FormatUint(ev.Sequence, 10)).
+		add(tagExecType, execType).
+		add(tagOrdStatus, st.status).
+		add(tagAccount, s.clientID).
+		add(tagSymbol, st.symbol).
+		add(tagSide, fixCode(sides, st.side)).
+		add(tagOrdType, fixCode(types, st.typ))
+	if st.typ.HasLimit() {
+		m.add(tagPrice, st.price.String())
+	}
+	m.add(tagOrderQty, st.qty.String())
+	if fill {
+		m.add(tagLastPx, ev.LastPrice.String()).add(tagLastQty, ev.LastQty.String())
+	}
+	m.add(tagLeavesQty, leaves.String()).
+		add(tagCumQty, st.cumQty.String()).
+		add(tagAvgPx, avgPx.String())
+	if ev.ExecType == domain.ExecRejected {
+		m.add(tagOrdRejReason, ordRejReason(ev.RejectCode))
+	}
+	m.addIf(tagText, ev.Reason).
+		add(tagTransactTime, ev.Timestamp.UTC().Format(sendingTimeFormat))
+
+	if done {
+		delete(s.orders, st.id)
+		delete(s.clOrdIDs, st.clOrdID)
+	}
+	return m
+}
+
+// ordStatus is the OrdStatus an event leaves its order in
+func ordStatus(ev *domain.OrderEvent) string {
+	switch {
+	case ev.ExecType == domain.ExecRejected:
+		return "8"
+	case ev.ExecType == domain.ExecExpired:
+		return "C"
+	case ev.ExecType == domain.ExecCanceled || ev.Status == domain.Cancelled:
+		return "4"
+	case ev.Status == domain.Filled:
+		return "2"
+	case ev.Status == domain.PartiallyFilled:
+		return "1"
+	}
+	return "0"
+}
+
+// rejectMissing answers a message lacking a required tag with a session-level Reject
+func (s *session) rejectMissing(m *message, tag int) {
+	s.send(newMessage(msgReject).
+		add(tagRefSeqNum, strconv.Itoa(m.seqNum())).
+		add(tagRefTagID, strconv.Itoa(tag)).
+		add(tagRefMsgType, m.msgType()).
+		add(tagSessionRejectReason, "1").
+		add(tagText, "required tag missing"))
+}
@@ -0,0 +1,98 @@
+// Package fix is a FIX 4.4 acceptor for institutional clients. A counterparty logs on with its API
+// key in Password(554) and trades for the key's client, or for its SenderCompID when the key is not
+// bound to a client: NewOrderSingle, OrderCancelRequest and OrderCancelReplaceRequest are mapped onto
+// the engine and every change of the session's orders comes back as an ExecutionReport, built from
+// the client's order events.
+//
+// Each FIX connection is an engine trading session, so its orders can be cancelled together on
+// disconnect. Sequence numbers and sent messages are not stored: every logon starts both directions
+// at 1, a ResendRequest is answered with a gap fill, and a gap in the counterparty's numbers is
+// accepted rather than recovered. Orders placed before a reconnect are reported again only on
+// their next change, without their ClOrdID.
+package fix
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/auth"
+	"github.com/olyamironova/exchange-engine/internal/core"
+)
+
+// drainReason is the text of the Logout sent to sessions on shutdown
+const drainReason = "server is shutting down, reconnect to resume"
+
+const (
+	// logonTimeout bounds the wait for a connection's Logon
+	logonTimeout = 10 * time.Second
+	// maxHeartBtInt caps the heartbeat interval a counterparty may ask for, so its engine session
+	// never times out between messages
+	maxHeartBtInt = 60 * time.Second
+)
+
+// Server accepts FIX connections; set the fields before Serve
+type Server struct {
+	Eng  core.Exchange
+	Keys *auth.KeyStore
+	// CompID is the server's SenderCompID, which counterparties send as TargetCompID
+	CompID string
+	// CancelOnDisconnect cancels a session's resting orders when it logs out or its connection drops
+	CancelOnDisconnect bool
+
+	mu        sync.Mutex
+	lis       net.Listener
+	drainOnce sync.Once
+	drain     chan struct{}
+	conns     sync.WaitGroup
+}
+
+func NewServer(eng core.Exchange, keys *auth.KeyStore, compID string) *Server {
+	return &Server{Eng: eng, Keys: keys, CompID: compID, drain: make(chan struct{})}
+}
+
+// Serve accepts connections on lis until Drain, then waits for the sessions to log out
+func (s *Server) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	s.lis = lis
+	s.mu.Unlock()
+	select {
+	case <-s.drain:
+		lis.Close()
+	default:
+	}
+	defer s.conns.Wait()
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			select {
+			case <-s.drain:
+				return nil
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		s.conns.Add(1)
+		go func() {
+			defer s.conns.Done()
+			newSession(s, conn).serve()
+		}()
+	}
+}
+
+// Drain stops accepting connections and logs every session out, so counterparties reconnect to
+// another instance
+func (s *Server) Drain() {
+	s.drainOnce.Do(func() {
+		close(s.drain)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.lis != nil {
+			s.lis.Close()
+		}
+	})
+}
@@ -0,0 +1,314 @@
+package fix
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/auth"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
+)
+
+// writeTimeout bounds one write to a counterparty that stopped reading
+const writeTimeout = 10 * time.Second
+
+// session is one logged-on FIX connection
+type session struct {
+	srv  *Server
+	conn net.Conn
+	r    *bufio.Reader
+
+	// set by the Logon
+	ctx        context.Context // carries the API key's principal and tenant
+	peer       string          // the counterparty's SenderCompID
+	clientID   string
+	token      string // of the engine trading session
+	sessionID  string
+	heartBtInt time.Duration
+
+	inSeq int // the MsgSeqNum expected next; only the reader uses it
+
+	writeMu sync.Mutex
+	outSeq  int // the MsgSeqNum of the last message sent
+	lastOut time.Time
+
+	mu        sync.Mutex
+	lastIn    time.Time
+	testReqAt time.Time         // when the unanswered TestRequest was sent, zero if there is none
+	orders    map[string]*order // orders placed through the session, by order ID, until they end
+	clOrdIDs  map[string]string // ClOrdID of each of those orders -> order ID
+}
+
+func newSession(srv *Server, conn net.Conn) *session {
+	return &session{
+		srv: srv, conn: conn, r: bufio.NewReader(conn),
+		orders: make(map[string]*order), clOrdIDs: make(map[string]string),
+	}
+}
+
+// serve runs the connection from its Logon until either side logs out or it drops, then ends the
+// engine session, cancelling its orders if the server cancels on disconnect
+func (s *session) serve() {
+	defer s.conn.Close()
+	s.conn.SetReadDeadline(time.Now().Add(logonTimeout))
+	m, err := readMessage(s.r)
+	if err != nil {
+		return
+	}
+	release, ok := s.logon(m)
+	if !ok {
+		return
+	}
+	defer release()
+	defer s.srv.Eng.Logout(s.ctx, s.token)
+	s.conn.SetReadDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	sub := s.srv.Eng.SubscribeOrderEvents(ctx, s.clientID)
+	defer sub.Close()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.pumpReports(ctx, sub)
+	}()
+	go func() {
+		defer wg.Done()
+		s.keepAlive(ctx)
+	}()
+	s.read()
+	cancel()
+	s.conn.Close()
+	wg.Wait()
+}
+
+// logon authenticates the first message of the connection and answers it. A connection that does
+// not start with a Logon is dropped without a reply.
+func (s *session) logon(m *message) (release func(), ok bool) {
+	s.peer = m.get(tagSenderCompID)
+	if m.msgType() != msgLogon || s.peer == "" || m.seqNum() == 0 {
+		return nil, false
+	}
+	s.inSeq = m.seqNum() + 1
+	refuse := func(format string, args ...any) (func(), bool) {
+		s.send(newMessage(msgLogout).add(tagText, fmt.Sprintf(format, args...)))
+		return nil, false
+	}
+	if target := m.get(tagTargetCompID); target != s.srv.CompID {
+		return refuse("unknown TargetCompID %q", target)
+	}
+	if enc := m.get(tagEncryptMethod); enc != "" && enc != "0" {
+		return refuse("EncryptMethod must be 0")
+	}
+	hb, err := strconv.Atoi(m.get(tagHeartBtInt))
+	if err != nil || hb <= 0 || time.Duration(hb)*time.Second > maxHeartBtInt {
+		return refuse("HeartBtInt must be 1 to %d seconds", int(maxHeartBtInt/time.Second))
+	}
+	p, ok := s.srv.Keys.Resolve(m.get(tagPassword))
+	if !ok {
+		return refuse("unknown API key")
+	}
+	if !p.HasAny(auth.TradeRoles...) {
+		return refuse("the API key is not allowed to trade")
+	}
+	s.ctx = auth.WithPrincipal(context.Background(), p)
+	// a key bound to a client trades for it; otherwise the SenderCompID names the client, if the
+	// key may act for any
+	claimed := ""
+	if p.ClientID == "" {
+		claimed = s.peer
+	}
+	if s.clientID, err = auth.ClientID(s.ctx, claimed); err != nil {
+		return refuse("%v", err)
+	}
+	release, err = s.srv.Keys.OpenStream(p)
+	if err != nil {
+		return refuse("%v", err)
+	}
+	sess, err := s.srv.Eng.Login(s.ctx, s.clientID, s.srv.CancelOnDisconnect)
+	if err != nil {
+		release()
+		return refuse("%v", err)
+	}
+	s.token, s.sessionID = sess.Token, sess.ID
+	s.heartBtInt = time.Duration(hb) * time.Second
+	s.lastIn = time.Now()
+
+	reply := newMessage(msgLogon).add(tagEncryptMethod, "0").add(tagHeartBtInt, strconv.Itoa(hb))
+	if m.get(tagResetSeqNumFlag) == "Y" {
+		reply.add(tagResetSeqNumFlag, "Y")
+	}
+	if err := s.send(reply); err != nil {
+		s.srv.Eng.Logout(s.ctx, s.token)
+		release()
+		return nil, false
+	}
+	return release, true
+}
+
+// read handles the counterparty's messages until it logs out or the connection fails
+func (s *session) read() {
+	for {
+		m, err := readMessage(s.r)
+		if errors.Is(err, errGarbled) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.lastIn, s.testReqAt = time.Now(), time.Time{}
+		s.mu.Unlock()
+
+		seq := m.seqNum()
+		if seq < s.inSeq {
+			if m.get(tagPossDupFlag) == "Y" {
+				continue
+			}
+			s.logout(fmt.Sprintf("MsgSeqNum too low, expecting %d but received %d", s.inSeq, seq))
+			return
+		}
+		s.inSeq = seq + 1
+		if _, err := s.srv.Eng.Heartbeat(s.ctx, s.token); err != nil {
+			s.logout("trading session expired")
+			return
+		}
+		if !s.handle(m) {
+			return
+		}
+	}
+}
+
+// handle acts on one in-sequence message and reports whether the session goes on
+func (s *session) handle(m *message) bool {
+	switch m.msgType() {
+	case msgHeartbeat, msgReject:
+	case msgTestRequest:
+		s.send(newMessage(msgHeartbeat).add(tagTestReqID, m.get(tagTestReqID)))
+	case msgResendRequest:
+		s.gapFill(m)
+	case msgSequenceReset:
+		if n, err := strconv.Atoi(m.get(tagNewSeqNo)); err == nil && n > s.inSeq {
+			s.inSeq = n
+		}
+	case msgLogout:
+		s.send(newMessage(msgLogout))
+		return false
+	case msgLogon:
+		s.send(newMessage(msgReject).add(tagRefSeqNum, strconv.Itoa(m.seqNum())).add(tagRefMsgType, msgLogon).
+			add(tagText, "already logged on"))
+	case msgNewOrderSingle:
+		s.newOrder(m)
+	case msgOrderCancelRequest:
+		s.cancelOrder(m)
+	case msgOrderCancelReplaceRequest:
+		s.replaceOrder(m)
+	default:
+		s.send(newMessage(msgBusinessMessageReject).add(tagRefSeqNum, strconv.Itoa(m.seqNum())).
+			add(tagRefMsgType, m.msgType()).add(tagBusinessRejectReason, "3").add(tagText, "unsupported message type"))
+	}
+	return true
+}
+
+// gapFill answers a ResendRequest: sent messages are not stored, so the counterparty is moved past
+// all of them
+func (s *session) gapFill(m *message) {
+	begin, err := strconv.Atoi(m.get(tagBeginSeqNo))
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	next := s.outSeq + 1
+	if err != nil || begin <= 0 || begin >= next {
+		return
+	}
+	reset := newMessage(msgSequenceReset).add(tagPossDupFlag, "Y").add(tagGapFillFlag, "Y").add(tagNewSeqNo, strconv.Itoa(next))
+	s.write(encode(reset, s.srv.CompID, s.peer, begin, time.Now()))
+}
+
+// keepAlive sends a Heartbeat when the session has been quiet for the heartbeat interval, tests a
+// silent counterparty with a TestRequest and logs it out if that goes unanswered. On shutdown it
+// logs the session out.
+func (s *session) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.srv.drain:
+			s.logout(drainReason)
+			return
+		case now = <-ticker.C:
+		}
+		s.mu.Lock()
+		lastIn, testReqAt := s.lastIn, s.testReqAt
+		if testReqAt.IsZero() && now.Sub(lastIn) > s.heartBtInt+s.heartBtInt/5 {
+			s.testReqAt = now
+		}
+		s.mu.Unlock()
+		switch {
+		case !testReqAt.IsZero() && now.Sub(testReqAt) > s.heartBtInt:
+			s.logout("heartbeat timeout")
+			return
+		case testReqAt.IsZero() && now.Sub(lastIn) > s.heartBtInt+s.heartBtInt/5:
+			s.send(newMessage(msgTestRequest).add(tagTestReqID, now.UTC().Format(sendingTimeFormat)))
+			continue
+		}
+		s.writeMu.Lock()
+		idle := now.Sub(s.lastOut) >= s.heartBtInt
+		s.writeMu.Unlock()
+		if idle {
+			s.send(newMessage(msgHeartbeat))
+		}
+	}
+}
+
+// pumpReports turns the client's order events into execution reports of the session's orders
+func (s *session) pumpReports(ctx context.Context, sub *pubsub.Subscription[*domain.OrderEvent]) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				if err := sub.Err(); err != nil {
+					s.logout(fmt.Sprintf("execution reports fell behind: %v", err))
+				}
+				return
+			}
+			if m := s.report(ev); m != nil {
+				s.send(m)
+			}
+		}
+	}
+}
+
+// logout sends a Logout and drops the connection without waiting for the counterparty's
+func (s *session) logout(text string) {
+	s.send(newMessage(msgLogout).add(tagText, text))
+	s.conn.Close()
+}
+
+// send writes m with the next MsgSeqNum; a failed write drops the connection
+func (s *session) send(m *message) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.outSeq++
+	return s.write(encode(m, s.srv.CompID, s.peer, s.outSeq, time.Now()))
+}
+
+func (s *session) write(b []byte) error {
+	s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := s.conn.Write(b); err != nil {
+		s.conn.Close()
+		return err
+	}
+	s.lastOut = time.Now()
+	return nil
+}
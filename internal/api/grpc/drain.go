@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/olyamironova/exchange-engine/proto"
+	"google.golang.org/grpc"
+)
+
+// drainReason is sent in the StreamEnd notice of drained streams
+const drainReason = "server is shutting down, reconnect to resume"
+
+// drainSignal tells open streams to wrap up
+type drainSignal struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+func newDrainSignal() *drainSignal { return &drainSignal{ch: make(chan struct{})} }
+
+func (d *drainSignal) Done() <-chan struct{} { return d.ch }
+
+func (d *drainSignal) fire() { d.once.Do(func() { close(d.ch) }) }
+
+// Drain ends every open stream: queued updates are flushed, a StreamEnd notice with the last position
+// is sent and the stream returns, so clients reconnect elsewhere without a gap. New streams opened
+// afterwards end right away.
+func (s *GRPCServer) Drain() { s.drain.fire() }
+
+func endNotice(cursor, lastSeq string) *pb.StreamEnd {
+	return &pb.StreamEnd{Reason: drainReason, Cursor: cursor, LastSeq: lastSeq}
+}
+
+// flushQueued sends what is already queued on c without waiting for more
+func flushQueued[T any](c <-chan T, send func(T) error) error {
+	for {
+		select {
+		case v, ok := <-c:
+			if !ok {
+				return nil
+			}
+			if err := send(v); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// Server is the gRPC listener of the exchange service
+type Server struct {
+	*grpc.Server
+	svc *GRPCServer
+}
+
+// Shutdown drains the streams and stops the server gracefully, forcing it closed once ctx is done
+func (s *Server) Shutdown(ctx context.Context) {
+	s.svc.Drain()
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
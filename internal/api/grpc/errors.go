@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/core"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Errors carry google.rpc details so that clients in any language branch on them without parsing
+// messages: BadRequest names the offending request fields, ErrorInfo in errorDomain carries a reject
+// code or one of the reasons below, ResourceInfo names what was not found, and RetryInfo says when a
+// retry may succeed.
+
+// errorDomain is the ErrorInfo domain of this server's errors
+const errorDomain = "exchange-engine"
+
+// ErrorInfo reasons of the errors that are not order rejections; rejections use their reject code
+const (
+	reasonInternal       = "INTERNAL"
+	reasonTimeout        = "TIMEOUT"
+	reasonSlowConsumer   = "SLOW_CONSUMER"
+	reasonUnknownAPIKey  = "UNKNOWN_API_KEY"
+	reasonBadCredentials = "INVALID_CREDENTIALS"
+	reasonUnknownSession = "UNKNOWN_SESSION"
+	reasonNotAllowed     = "NOT_ALLOWED"
+	reasonSubscriptionID = "SUBSCRIPTION_ID_IN_USE"
+	reasonStreamLimit    = "STREAM_LIMIT"
+	reasonWarmingUp      = "WARMING_UP"
+)
+
+// how long clients should wait before retrying what is expected to clear up
+const (
+	cancelOnlyRetryDelay   = 30 * time.Second
+	timeoutRetryDelay      = time.Second
+	slowConsumerRetryDelay = time.Second
+	warmupRetryDelay       = 5 * time.Second
+)
+
+// detailed returns a status error with the details that could be attached
+func detailed(c codes.Code, msg string, details ...protoadapt.MessageV1) error {
+	st := status.New(c, msg)
+	if d, err := st.WithDetails(details...); err == nil {
+		st = d
+	}
+	return st.Err()
+}
+
+func errorInfo(reason string, metadata map[string]string) *errdetails.ErrorInfo {
+	return &errdetails.ErrorInfo{Reason: reason, Domain: errorDomain, Metadata: metadata}
+}
+
+func retryAfter(d time.Duration) *errdetails.RetryInfo {
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(d)}
+}
+
+// fieldError is an InvalidArgument naming the request field at fault
+func fieldError(field, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	return detailed(codes.InvalidArgument, msg, &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: msg}},
+	})
+}
+
+// notFound is a NotFound naming the missing resource
+func notFound(resourceType, name, msg string) error {
+	return detailed(codes.NotFound, msg, &errdetails.ResourceInfo{ResourceType: resourceType, ResourceName: name, Description: msg})
+}
+
+// failure maps an engine error to a status: rejections keep their code, a bad cursor is the caller's,
+// timeouts may be retried and anything else is internal
+func failure(op string, err error) error {
+	switch {
+	case domain.IsReject(err):
+		return rejectStatus(err)
+	case errors.Is(err, core.ErrUnknownSession):
+		return detailed(codes.Unauthenticated, err.Error(), errorInfo(reasonUnknownSession, nil))
+	case errors.Is(err, page.ErrInvalidCursor):
+		return fieldError("cursor", "%v", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return detailed(codes.DeadlineExceeded, fmt.Sprintf("%s timed out: %v", op, err),
+			errorInfo(reasonTimeout, map[string]string{"operation": op}), retryAfter(timeoutRetryDelay))
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return detailed(codes.Internal, fmt.Sprintf("%s failed: %v", op, err), errorInfo(reasonInternal, map[string]string{"operation": op}))
+}
+
+// rejectStatus is the status of a rejected order, its reject code in an ErrorInfo detail. Cancel-only
+// rejections are worth retrying later; malformed orders also name the field at fault.
+func rejectStatus(err error) error {
+	code := domain.RejectCodeOf(err)
+	info := errorInfo(string(code), nil)
+	switch code {
+	case domain.RejectCancelOnly:
+		return detailed(codes.Unavailable, err.Error(), info, retryAfter(cancelOnlyRetryDelay))
+	case domain.RejectInvalidPrice, domain.RejectInvalidTick:
+		return detailed(codes.InvalidArgument, err.Error(), info, violation("price", err))
+	case domain.RejectInvalidQuantity, domain.RejectMinNotional:
+		return detailed(codes.InvalidArgument, err.Error(), info, violation("quantity", err))
+	case domain.RejectInvalidOrder:
+		return detailed(codes.InvalidArgument, err.Error(), info)
+	}
+	return detailed(codes.FailedPrecondition, err.Error(), info)
+}
+
+func violation(field string, err error) *errdetails.BadRequest {
+	return &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: err.Error()}}}
+}
+
+// rejectCode recovers the reject code of an error, whether still a domain error or already a status
+func rejectCode(err error) string {
+	if domain.IsReject(err) {
+		return string(domain.RejectCodeOf(err))
+	}
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain {
+			return info.Reason
+		}
+	}
+	return string(domain.RejectOther)
+}
@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// maskNode is a parsed field mask over one message: a field is kept whole or, when only some of its
+// sub-fields are selected, pruned by its own node. Repeated messages are pruned element by element.
+type maskNode struct {
+	whole  bool
+	fields map[protoreflect.Name]*maskNode
+}
+
+// parseMask checks the mask paths against md; an empty mask selects everything and yields nil
+func parseMask(md protoreflect.MessageDescriptor, mask *fieldmaskpb.FieldMask) (*maskNode, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, nil
+	}
+	root := &maskNode{}
+	for _, path := range mask.GetPaths() {
+		node, desc := root, md
+		segments := strings.Split(path, ".")
+		for i, seg := range segments {
+			fd := desc.Fields().ByName(protoreflect.Name(seg))
+			if fd == nil {
+				return nil, fmt.Errorf("%s has no field %q", desc.Name(), seg)
+			}
+			if node.fields == nil {
+				node.fields = make(map[protoreflect.Name]*maskNode)
+			}
+			child := node.fields[fd.Name()]
+			if child == nil {
+				child = &maskNode{}
+				node.fields[fd.Name()] = child
+			}
+			if i == len(segments)-1 {
+				child.whole = true
+				break
+			}
+			if fd.Message() == nil || fd.IsMap() {
+				return nil, fmt.Errorf("cannot select inside %s", fd.Name())
+			}
+			node, desc = child, fd.Message()
+		}
+	}
+	return root, nil
+}
+
+// prune clears every field of m the node does not select
+func (n *maskNode) prune(m protoreflect.Message) {
+	var clear []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		child, ok := n.fields[fd.Name()]
+		switch {
+		case !ok:
+			clear = append(clear, fd)
+		case child.whole:
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				child.prune(list.Get(i).Message())
+			}
+		default:
+			child.prune(v.Message())
+		}
+		return true
+	})
+	for _, fd := range clear {
+		m.Clear(fd)
+	}
+}
+
+// checkMask validates a request's field mask against the response type before any work is done
+func checkMask(resp proto.Message, mask *fieldmaskpb.FieldMask) (*maskNode, error) {
+	node, err := parseMask(resp.ProtoReflect().Descriptor(), mask)
+	if err != nil {
+		return nil, fieldError("field_mask", "invalid field_mask: %v", err)
+	}
+	return node, nil
+}
+
+// applyMask trims resp to the fields selected by a mask from checkMask; a nil mask keeps it whole
+func applyMask[M proto.Message](resp M, node *maskNode) M {
+	if node != nil {
+		node.prune(resp.ProtoReflect())
+	}
+	return resp
+}
@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"testing"
+
+	pb "github.com/olyamironova/exchange-engine/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCheckMask(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		ok    bool
+	}{
+		{"empty mask", nil, true},
+		{"whole field", []string{"order"}, true},
+		{"sub-field", []string{"order.price"}, true},
+		{"several sub-fields", []string{"order.id", "order.created_at.seconds"}, true},
+		{"unknown field", []string{"orders"}, false},
+		{"unknown sub-field", []string{"order.nope"}, false},
+		{"one unknown path among known ones", []string{"order.id", "order.prices"}, false},
+		{"inside a scalar", []string{"order.price.units"}, false},
+		{"empty path", []string{""}, false},
+		{"trailing dot", []string{"order."}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := checkMask(&pb.GetOrderResponse{}, &fieldmaskpb.FieldMask{Paths: tc.paths})
+			if tc.ok {
+				if err != nil {
+					t.Fatalf("mask %q: %v", tc.paths, err)
+				}
+				return
+			}
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("mask %q: %v, want INVALID_ARGUMENT", tc.paths, err)
+			}
+		})
+	}
+}
+
+func TestApplyMask(t *testing.T) {
+	order := func() *pb.Order {
+		return &pb.Order{Id: "o1", Symbol: "BTC/USD", Price: "100", Quantity: "2",
+			CreatedAt: &timestamppb.Timestamp{Seconds: 10, Nanos: 5}}
+	}
+	tests := []struct {
+		name  string
+		paths []string
+		want  *pb.ListOrdersResponse
+	}{
+		{"empty mask keeps everything", nil,
+			&pb.ListOrdersResponse{Orders: []*pb.Order{order(), order()}, NextCursor: "c"}},
+		{"top-level field", []string{"next_cursor"},
+			&pb.ListOrdersResponse{NextCursor: "c"}},
+		{"fields of each element", []string{"orders.id", "orders.price"},
+			&pb.ListOrdersResponse{Orders: []*pb.Order{{Id: "o1", Price: "100"}, {Id: "o1", Price: "100"}}}},
+		{"nested message field", []string{"orders.created_at.seconds"},
+			&pb.ListOrdersResponse{Orders: []*pb.Order{
+				{CreatedAt: &timestamppb.Timestamp{Seconds: 10}}, {CreatedAt: &timestamppb.Timestamp{Seconds: 10}}}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			node, err := checkMask(&pb.ListOrdersResponse{}, &fieldmaskpb.FieldMask{Paths: tc.paths})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := applyMask(&pb.ListOrdersResponse{Orders: []*pb.Order{order(), order()}, NextCursor: "c"}, node)
+			if !proto.Equal(got, tc.want) {
+				t.Errorf("masked to %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,18 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/olyamironova/exchange-engine/internal/api/view"
+	"github.com/olyamironova/exchange-engine/internal/auth"
 	"github.com/olyamironova/exchange-engine/internal/core"
 	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
 	"github.com/shopspring/decimal"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"slices"
+	"sort"
 	"time"
 
 	_ "github.com/olyamironova/exchange-engine/internal/core"
@@ -14,140 +22,653 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+const (
+	maxRecentTrades = 1000
+	maxBatchSize    = 500
+	maxSnapshots    = 200
+	maxOrders       = 1000
+	maxTrades       = 1000
+)
+
 type GRPCServer struct {
 	pb.UnimplementedExchangeServer
-	Eng *core.Engine
+	Eng core.Exchange
+	// MaxBatchSize bounds the orders of a batch submit, cancel or modify
+	MaxBatchSize int
+	subs         subscriptions
+	drain        *drainSignal
 }
 
-func NewGRPCServer(eng *core.Engine) *GRPCServer {
-	return &GRPCServer{Eng: eng}
+func NewGRPCServer(eng core.Exchange) *GRPCServer {
+	return &GRPCServer{Eng: eng, MaxBatchSize: maxBatchSize, drain: newDrainSignal()}
 }
 
 func (s *GRPCServer) SubmitOrder(ctx context.Context, req *pb.SubmitOrderRequest) (*pb.SubmitOrderResponse, error) {
-	if err := ValidateOrder(req); err != nil {
+	o, err := orderFromPb(ctx, req)
+	if req.ValidateOnly {
+		if err == nil {
+			err = s.Eng.ValidateOrder(ctx, o)
+		}
+		if err != nil {
+			return &pb.SubmitOrderResponse{RejectReason: status.Convert(err).Message(), RejectCode: rejectCode(err),
+				UserData: req.UserData, ClientOrderId: req.ClientOrderId}, nil
+		}
+		return &pb.SubmitOrderResponse{Accepted: true, UserData: req.UserData, ClientOrderId: req.ClientOrderId}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if o.SessionID, err = s.orderSession(ctx, o.ClientID); err != nil {
 		return nil, err
 	}
 
-	price, err := decimal.NewFromString(req.Price)
+	trades, err := s.Eng.SubmitOrder(ctx, o)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid price: %v", err)
+		return nil, failure("submit", err)
 	}
-	quantity, err := decimal.NewFromString(req.Quantity)
-	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid quantity: %v", err)
+
+	return s.submitResponse(o, trades), nil
+}
+
+func (s *GRPCServer) BatchSubmitOrders(ctx context.Context, req *pb.BatchSubmitOrdersRequest) (*pb.BatchSubmitOrdersResponse, error) {
+	if len(req.Orders) == 0 {
+		return nil, fieldError("orders", "orders must not be empty")
+	}
+	if len(req.Orders) > s.MaxBatchSize {
+		return nil, fieldError("orders", "batch too large: %d > %d", len(req.Orders), s.MaxBatchSize)
 	}
 
-	o := &domain.Order{
-		ClientID: req.ClientId,
-		Symbol:   req.Symbol,
-		Side:     domain.Side(req.Side),
-		Type:     domain.OrderType(req.Type),
-		Price:    price,
-		Quantity: quantity,
+	results := make([]*pb.BatchSubmitOrderResult, len(req.Orders))
+	orders := make([]*domain.Order, 0, len(req.Orders))
+	index := make([]int, 0, len(req.Orders))
+	for i, r := range req.Orders {
+		o, err := orderFromPb(ctx, r)
+		if err != nil {
+			results[i] = &pb.BatchSubmitOrderResult{Index: int32(i), Error: status.Convert(err).Message(), RejectCode: rejectCode(err)}
+			continue
+		}
+		orders = append(orders, o)
+		index = append(index, i)
 	}
 
-	trades, err := s.Eng.SubmitOrder(ctx, o)
+	for j, res := range s.Eng.BatchSubmitOrders(ctx, orders, req.Parallel) {
+		i := index[j]
+		if res.Err != nil {
+			results[i] = &pb.BatchSubmitOrderResult{Index: int32(i), Error: res.Err.Error(), RejectCode: rejectCode(res.Err)}
+			continue
+		}
+		results[i] = &pb.BatchSubmitOrderResult{
+			Index:    int32(i),
+			Ok:       true,
+			Response: s.submitResponse(res.Order, res.Trades),
+		}
+	}
+	return &pb.BatchSubmitOrdersResponse{Results: results}, nil
+}
+
+func (s *GRPCServer) SubmitOrderGroup(ctx context.Context, req *pb.SubmitOrderGroupRequest) (*pb.SubmitOrderGroupResponse, error) {
+	if len(req.Orders) == 0 {
+		return nil, fieldError("orders", "orders must not be empty")
+	}
+	legs := make([]*domain.Order, len(req.Orders))
+	for i, r := range req.Orders {
+		o, err := orderFromPb(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if o.SessionID, err = s.orderSession(ctx, o.ClientID); err != nil {
+			return nil, err
+		}
+		legs[i] = o
+	}
+	res, err := s.Eng.SubmitOrderGroup(ctx, legs, req.CancelOnFill)
+	if res.GroupID == "" {
+		return nil, failure("submit group", err)
+	}
+	out := &pb.SubmitOrderGroupResponse{GroupId: res.GroupID, Legs: make([]*pb.BatchSubmitOrderResult, len(res.Legs))}
+	for i, leg := range res.Legs {
+		if leg.Err != nil {
+			out.Legs[i] = &pb.BatchSubmitOrderResult{Index: int32(i), Error: leg.Err.Error(), RejectCode: rejectCode(leg.Err)}
+			continue
+		}
+		out.Legs[i] = &pb.BatchSubmitOrderResult{Index: int32(i), Ok: true, Response: s.submitResponse(leg.Order, leg.Trades)}
+	}
+	return out, nil
+}
+
+func (s *GRPCServer) CancelOrderGroup(ctx context.Context, req *pb.CancelOrderGroupRequest) (*pb.CancelOrderGroupResponse, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "submit failed: %v", err)
+		return nil, err
+	}
+	if req.GroupId == "" {
+		return nil, fieldError("group_id", "group_id is required")
 	}
+	ids, err := s.Eng.CancelOrderGroup(ctx, clientID, req.GroupId)
+	if err != nil {
+		return nil, failure("cancel group", err)
+	}
+	return &pb.CancelOrderGroupResponse{CancelledOrderIds: ids}, nil
+}
 
-	pbTrades := make([]*pb.Trade, 0, len(trades))
-	for _, t := range trades {
-		pbTrades = append(pbTrades, &pb.Trade{
-			Id:        t.ID,
-			BuyOrder:  t.BuyOrder,
-			SellOrder: t.SellOrder,
-			Price:     t.Price.String(),
-			Quantity:  t.Quantity.String(),
-			Timestamp: TimeToProto(t.Timestamp),
-		})
+func (s *GRPCServer) PreviewOrder(ctx context.Context, req *pb.SubmitOrderRequest) (*pb.PreviewOrderResponse, error) {
+	o, err := orderFromPb(ctx, req)
+	if err != nil {
+		return nil, err
 	}
+	p, err := s.Eng.PreviewOrder(ctx, o)
+	if err != nil {
+		return nil, failure("preview", err)
+	}
+	fills := make([]*pb.PreviewFill, len(p.Fills))
+	for i, f := range p.Fills {
+		fills[i] = &pb.PreviewFill{Price: f.Price.String(), Quantity: f.Quantity.String()}
+	}
+	return &pb.PreviewOrderResponse{
+		Symbol:       p.Symbol,
+		Side:         string(p.Side),
+		Fills:        fills,
+		Filled:       p.Filled.String(),
+		Remaining:    p.Remaining.String(),
+		Notional:     p.Notional.String(),
+		AvgPrice:     nullDecimalString(p.AvgPrice),
+		BestPrice:    nullDecimalString(p.BestPrice),
+		Slippage:     nullDecimalString(p.Slippage),
+		EstimatedFee: p.EstimatedFee.String(),
+	}, nil
+}
 
-	return &pb.SubmitOrderResponse{
-		OrderId:   o.ID,
-		Trades:    pbTrades,
-		Remaining: o.Remaining.String(),
+func orderFromPb(ctx context.Context, req *pb.SubmitOrderRequest) (*domain.Order, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateOrder(req); err != nil {
+		return nil, err
+	}
+	price := decimal.Zero
+	if req.Price != "" || domain.OrderType(req.Type).HasLimit() {
+		p, err := decimal.NewFromString(req.Price)
+		if err != nil {
+			return nil, rejectStatus(domain.Reject(domain.RejectInvalidPrice, "invalid price: %v", err))
+		}
+		price = p
+	}
+	quantity, err := decimal.NewFromString(req.Quantity)
+	if err != nil {
+		return nil, rejectStatus(domain.Reject(domain.RejectInvalidQuantity, "invalid quantity: %v", err))
+	}
+	trigger := decimal.Zero
+	if req.TriggerPrice != "" || domain.OrderType(req.Type).IsStop() {
+		if trigger, err = decimal.NewFromString(req.TriggerPrice); err != nil {
+			return nil, rejectStatus(domain.Reject(domain.RejectInvalidPrice, "invalid trigger_price: %v", err))
+		}
+	}
+	return &domain.Order{
+		ClientID:      clientID,
+		Symbol:        req.Symbol,
+		Side:          domain.Side(req.Side),
+		Type:          domain.OrderType(req.Type),
+		Price:         price,
+		Quantity:      quantity,
+		TriggerPrice:  trigger,
+		Tags:          req.Tags,
+		UserData:      req.UserData,
+		ClientOrderID: req.ClientOrderId,
 	}, nil
 }
 
+func (s *GRPCServer) submitResponse(o *domain.Order, trades []*domain.Trade) *pb.SubmitOrderResponse {
+	return &pb.SubmitOrderResponse{
+		OrderId:       o.ID,
+		Trades:        s.convertTradesToPb(trades),
+		Remaining:     view.PrecisionOf(s.Eng, o.Symbol).FormatQuantity(o.Remaining),
+		Accepted:      true,
+		UserData:      o.UserData,
+		ClientOrderId: o.ClientOrderID,
+	}
+}
+
 func (s *GRPCServer) ModifyOrder(ctx context.Context, req *pb.ModifyOrderRequest) (*pb.ModifyOrderResponse, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
 	price, err := decimal.NewFromString(req.NewPrice)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid new_price: %v", err)
+		return nil, fieldError("new_price", "invalid new_price: %v", err)
 	}
 	quantity, err := decimal.NewFromString(req.NewQuantity)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid new_quantity: %v", err)
+		return nil, fieldError("new_quantity", "invalid new_quantity: %v", err)
+	}
+	orderID, err := s.orderIDOf(ctx, clientID, req.OrderId, req.ClientOrderId)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.Eng.ModifyOrder(ctx, req.OrderId, req.ClientId, price, quantity); err != nil {
-		return nil, status.Errorf(codes.Internal, "modify failed: %v", err)
+	trades, err := s.Eng.ModifyOrder(ctx, orderID, clientID, price, quantity)
+	if err != nil {
+		return nil, failure("modify", err)
 	}
 	return &pb.ModifyOrderResponse{
-		OrderId:  req.OrderId,
+		OrderId:  orderID,
 		Modified: true,
 		Message:  "",
+		Trades:   s.convertTradesToPb(trades),
 	}, nil
 }
 
 func (s *GRPCServer) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
-	ok, err := s.Eng.CancelOrder(ctx, req.OrderId, req.ClientId)
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	orderID, err := s.orderIDOf(ctx, clientID, req.OrderId, req.ClientOrderId)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.Eng.CancelOrder(ctx, orderID, clientID)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "cancel failed: %v", err)
+		return nil, failure("cancel", err)
 	}
 	return &pb.CancelOrderResponse{
-		OrderId:   req.OrderId,
+		OrderId:   orderID,
 		Cancelled: ok,
 		Message:   "",
 	}, nil
 }
 
+// orderIDOf returns the order a cancel or modify names: orderID, or else the client's order with
+// clientOrderID
+func (s *GRPCServer) orderIDOf(ctx context.Context, clientID, orderID, clientOrderID string) (string, error) {
+	if orderID != "" {
+		return orderID, nil
+	}
+	if clientOrderID == "" {
+		return "", fieldError("order_id", "order_id or client_order_id is required")
+	}
+	o, err := s.Eng.GetOrderByClientOrderID(ctx, clientID, clientOrderID)
+	if err != nil {
+		return "", notFound("order", clientOrderID, "no order with this client_order_id")
+	}
+	return o.ID, nil
+}
+
+func (s *GRPCServer) BatchCancelOrders(ctx context.Context, req *pb.BatchCancelOrdersRequest) (*pb.BatchCancelOrdersResponse, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	n := len(req.OrderIds) + len(req.ClientOrderIds)
+	if n == 0 {
+		return nil, fieldError("order_ids", "order_ids or client_order_ids must not be empty")
+	}
+	if n > s.MaxBatchSize {
+		return nil, fieldError("order_ids", "batch too large: %d > %d", n, s.MaxBatchSize)
+	}
+	results, err := s.Eng.BatchCancelOrders(ctx, clientID, req.OrderIds, req.ClientOrderIds)
+	if err != nil {
+		return nil, failure("batch cancel", err)
+	}
+	out := make([]*pb.CancelOrderResponse, len(results))
+	for i, r := range results {
+		out[i] = &pb.CancelOrderResponse{OrderId: r.OrderID, ClientOrderId: r.ClientOrderID, Cancelled: r.Cancelled}
+		if r.Err != nil {
+			out[i].Message = r.Err.Error()
+		}
+	}
+	return &pb.BatchCancelOrdersResponse{Results: out}, nil
+}
+
+func (s *GRPCServer) BatchModifyOrders(ctx context.Context, req *pb.BatchModifyOrdersRequest) (*pb.BatchModifyOrdersResponse, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Orders) == 0 {
+		return nil, fieldError("orders", "orders must not be empty")
+	}
+	if len(req.Orders) > s.MaxBatchSize {
+		return nil, fieldError("orders", "batch too large: %d > %d", len(req.Orders), s.MaxBatchSize)
+	}
+	amends := make([]core.Amend, len(req.Orders))
+	for i, a := range req.Orders {
+		price, err := decimal.NewFromString(a.NewPrice)
+		if err != nil {
+			return nil, fieldError(fmt.Sprintf("orders[%d].new_price", i), "invalid new_price: %v", err)
+		}
+		quantity, err := decimal.NewFromString(a.NewQuantity)
+		if err != nil {
+			return nil, fieldError(fmt.Sprintf("orders[%d].new_quantity", i), "invalid new_quantity: %v", err)
+		}
+		amends[i] = core.Amend{OrderID: a.OrderId, Price: price, Quantity: quantity}
+	}
+	results, err := s.Eng.BatchModifyOrders(ctx, clientID, amends)
+	if err != nil {
+		return nil, failure("batch modify", err)
+	}
+	out := make([]*pb.ModifyOrderResponse, len(results))
+	for i, r := range results {
+		out[i] = &pb.ModifyOrderResponse{OrderId: r.OrderID, Modified: r.Modified, Trades: s.convertTradesToPb(r.Trades)}
+		if r.Err != nil {
+			out[i].Message = r.Err.Error()
+		}
+	}
+	return &pb.BatchModifyOrdersResponse{Results: out}, nil
+}
+
+func (s *GRPCServer) CancelBySide(ctx context.Context, req *pb.CancelBySideRequest) (*pb.CancelBySideResponse, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Side != "BUY" && req.Side != "SELL" {
+		return nil, fieldError("side", "invalid side: %s", req.Side)
+	}
+	if req.Symbol == "" {
+		return nil, fieldError("symbol", "symbol is required")
+	}
+	ids, err := s.Eng.CancelBySide(ctx, clientID, req.Symbol, domain.Side(req.Side))
+	if err != nil {
+		return nil, failure("cancel by side", err)
+	}
+	return &pb.CancelBySideResponse{CancelledOrderIds: ids}, nil
+}
+
+func (s *GRPCServer) CancelAllOrders(ctx context.Context, req *pb.CancelAllOrdersRequest) (*pb.CancelAllOrdersResponse, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Side != "" && req.Side != "BUY" && req.Side != "SELL" {
+		return nil, fieldError("side", "invalid side: %s", req.Side)
+	}
+	ids, err := s.Eng.CancelAllOrders(ctx, clientID, req.Symbol, domain.Side(req.Side))
+	if err != nil {
+		return nil, failure("cancel all", err)
+	}
+	return &pb.CancelAllOrdersResponse{CancelledOrderIds: ids}, nil
+}
+
+func (s *GRPCServer) ForceCancelOrder(ctx context.Context, req *pb.ForceCancelRequest) (*pb.CancelOrderResponse, error) {
+	ok, err := s.Eng.ForceCancelOrder(ctx, req.OrderId, req.Reason)
+	if err != nil {
+		return nil, failure("force cancel", err)
+	}
+	return &pb.CancelOrderResponse{OrderId: req.OrderId, Cancelled: ok}, nil
+}
+
+// GetOrder returns one of the client's orders; another client's is reported as not found
 func (s *GRPCServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	mask, err := checkMask(&pb.GetOrderResponse{}, req.FieldMask)
+	if err != nil {
+		return nil, err
+	}
 	order, err := s.Eng.GetOrder(ctx, req.OrderId)
+	if err != nil || order.ClientID != clientID {
+		return nil, notFound("order", req.OrderId, "order not found")
+	}
+	return applyMask(&pb.GetOrderResponse{
+		Order: s.convertOrderToPb(order),
+	}, mask), nil
+}
+
+// GetOrderByClientOrderID returns the client's order with the client order ID: the open one, or
+// else the latest
+func (s *GRPCServer) GetOrderByClientOrderID(ctx context.Context, req *pb.GetOrderByClientOrderIDRequest) (*pb.GetOrderResponse, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "order not found")
+		return nil, err
 	}
-	return &pb.GetOrderResponse{
-		Order: convertOrderToPb(order),
+	if req.ClientOrderId == "" {
+		return nil, fieldError("client_order_id", "client_order_id is required")
+	}
+	order, err := s.Eng.GetOrderByClientOrderID(ctx, clientID, req.ClientOrderId)
+	if err != nil {
+		return nil, notFound("order", req.ClientOrderId, "order not found")
+	}
+	return &pb.GetOrderResponse{Order: s.convertOrderToPb(order)}, nil
+}
+
+func (s *GRPCServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	mask, err := checkMask(&pb.ListOrdersResponse{}, req.FieldMask)
+	if err != nil {
+		return nil, err
+	}
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	status := domain.OrderStatus(req.Status)
+	switch status {
+	case "", domain.Pending, domain.Open, domain.PartiallyFilled, domain.Filled, domain.Cancelled:
+	default:
+		return nil, fieldError("status", "invalid status: %s", req.Status)
+	}
+	f := domain.OrderFilter{ClientID: clientID, Symbol: req.Symbol, Status: status}
+	orders, err := s.Eng.ListOrders(ctx, f, page.Request{Cursor: req.Cursor, Limit: int(req.Limit)}.Capped(maxOrders))
+	if err != nil {
+		return nil, failure("list orders", err)
+	}
+	resp := &pb.ListOrdersResponse{Orders: make([]*pb.Order, len(orders.Items)), NextCursor: orders.NextCursor}
+	for i, o := range orders.Items {
+		resp.Orders[i] = s.convertOrderToPb(o)
+	}
+	return applyMask(resp, mask), nil
+}
+
+func (s *GRPCServer) GetQueuePosition(ctx context.Context, req *pb.GetQueuePositionRequest) (*pb.GetQueuePositionResponse, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	qp, err := s.Eng.GetQueuePosition(ctx, req.OrderId, clientID)
+	if err != nil {
+		return nil, notFound("order", req.OrderId, fmt.Sprintf("queue position unavailable: %v", err))
+	}
+	return &pb.GetQueuePositionResponse{
+		OrderId:       qp.OrderID,
+		Symbol:        qp.Symbol,
+		Side:          string(qp.Side),
+		Price:         qp.Price.String(),
+		Position:      int32(qp.Position),
+		OrdersAhead:   int32(qp.OrdersAhead),
+		QuantityAhead: qp.QuantityAhead.String(),
+		LevelOrders:   int32(qp.LevelOrders),
+		LevelQuantity: qp.LevelQuantity.String(),
+		Remaining:     qp.OrderRemaining.String(),
 	}, nil
 }
 
 func (s *GRPCServer) GetTradesForOrder(ctx context.Context, req *pb.GetTradesRequest) (*pb.GetTradesResponse, error) {
-	trades, err := s.Eng.GetTradesForOrder(ctx, req.OrderId)
+	mask, err := checkMask(&pb.GetTradesResponse{}, req.FieldMask)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "get trades failed: %v", err)
+		return nil, err
 	}
-	pbTrades := make([]*pb.Trade, 0, len(trades))
-	for _, t := range trades {
-		pbTrades = append(pbTrades, &pb.Trade{
-			Id:        t.ID,
-			BuyOrder:  t.BuyOrder,
-			SellOrder: t.SellOrder,
-			Price:     t.Price.String(),
-			Quantity:  t.Quantity.String(),
-			Timestamp: TimeToProto(t.Timestamp),
-		})
+	pg := page.Request{Cursor: req.Cursor, Limit: int(req.Limit)}.Capped(maxRecentTrades)
+	trades, err := s.Eng.GetTradesForOrder(ctx, req.OrderId, pg)
+	if err != nil {
+		return nil, failure("get trades", err)
+	}
+	pbTrades := s.convertTradesToPb(view.FromContext(ctx).Trades(trades.Items))
+	return applyMask(&pb.GetTradesResponse{Trades: pbTrades, NextCursor: trades.NextCursor}, mask), nil
+}
+
+func (s *GRPCServer) GetTrades(ctx context.Context, req *pb.GetTradesRequest) (*pb.GetTradesResponse, error) {
+	mask, err := checkMask(&pb.GetTradesResponse{}, req.FieldMask)
+	if err != nil {
+		return nil, err
+	}
+	if req.Symbol == "" {
+		return nil, fieldError("symbol", "symbol is required")
+	}
+	from, to := timeOf(req.From), timeOf(req.To)
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, fieldError("from", "from must be before to")
+	}
+	pg := page.Request{Cursor: req.Cursor, Limit: int(req.Limit)}.Capped(maxTrades)
+	trades, err := s.Eng.GetTrades(ctx, req.Symbol, from, to, pg)
+	if err != nil {
+		return nil, failure("get trades", err)
 	}
-	return &pb.GetTradesResponse{Trades: pbTrades}, nil
+	pbTrades := s.convertTradesToPb(view.FromContext(ctx).Trades(trades.Items))
+	return applyMask(&pb.GetTradesResponse{Trades: pbTrades, NextCursor: trades.NextCursor}, mask), nil
+}
+
+func (s *GRPCServer) GetTrade(ctx context.Context, req *pb.GetTradeRequest) (*pb.GetTradeResponse, error) {
+	mask, err := checkMask(&pb.GetTradeResponse{}, req.FieldMask)
+	if err != nil {
+		return nil, err
+	}
+	trade, err := s.Eng.GetTrade(ctx, req.TradeId)
+	if err != nil {
+		return nil, notFound("trade", req.TradeId, "trade not found")
+	}
+	return applyMask(&pb.GetTradeResponse{Trade: s.convertTradeToPb(view.FromContext(ctx).Trade(trade))}, mask), nil
 }
 
 func (s *GRPCServer) GetOrderbook(ctx context.Context, req *pb.GetOrderbookRequest) (*pb.GetOrderbookResponse, error) {
-	ob, err := s.Eng.GetOrderbook(ctx, req.Symbol)
+	mask, err := checkMask(&pb.GetOrderbookResponse{}, req.FieldMask)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "symbol not found")
+		return nil, err
 	}
-	copySnapshot := ob.DeepCopy()
-	return &pb.GetOrderbookResponse{
-		Bids:      convertOrdersToPb(copySnapshot.Bids),
-		Asks:      convertOrdersToPb(copySnapshot.Asks),
+	get := s.Eng.GetOrderbook
+	if req.ReadYourWrites {
+		get = s.Eng.GetOrderbookConsistent
+	}
+	ob, err := get(ctx, req.Symbol)
+	if err != nil {
+		return nil, notFound("symbol", req.Symbol, "symbol not found")
+	}
+	copySnapshot := view.FromContext(ctx).Book(ob)
+	return applyMask(&pb.GetOrderbookResponse{
+		Bids:      s.convertOrdersToPb(copySnapshot.Bids),
+		Asks:      s.convertOrdersToPb(copySnapshot.Asks),
 		Timestamp: timestamppb.New(time.Now()),
+		Sequence:  copySnapshot.Sequence,
+	}, mask), nil
+}
+
+// GetDepth returns the book aggregated by price level; levels carry no orders or clients, so every
+// reader sees the same depth
+func (s *GRPCServer) GetDepth(ctx context.Context, req *pb.GetDepthRequest) (*pb.GetDepthResponse, error) {
+	if req.Symbol == "" {
+		return nil, fieldError("symbol", "symbol is required")
+	}
+	if req.Levels < 0 {
+		return nil, fieldError("levels", "levels must be >= 0")
+	}
+	step := decimal.Zero
+	if req.Step != "" {
+		var err error
+		if step, err = decimal.NewFromString(req.Step); err != nil || step.IsNegative() {
+			return nil, fieldError("step", "step must be a decimal >= 0")
+		}
+	}
+	d, err := s.Eng.GetDepth(ctx, req.Symbol, int(req.Levels), step)
+	if err != nil {
+		return nil, notFound("symbol", req.Symbol, "symbol not found")
+	}
+	return &pb.GetDepthResponse{
+		Symbol:    d.Symbol,
+		Step:      d.Step.String(),
+		Bids:      depthLevelsToPb(d.Bids),
+		Asks:      depthLevelsToPb(d.Asks),
+		Timestamp: timestamppb.New(d.Timestamp),
+		Sequence:  d.Sequence,
+	}, nil
+}
+
+func depthLevelsToPb(levels []domain.DepthLevel) []*pb.DepthLevel {
+	out := make([]*pb.DepthLevel, len(levels))
+	for i, l := range levels {
+		out[i] = &pb.DepthLevel{Price: l.Price.String(), Quantity: l.Quantity.String(), Orders: int32(l.Orders)}
+	}
+	return out
+}
+
+func (s *GRPCServer) GetCandles(ctx context.Context, req *pb.GetCandlesRequest) (*pb.GetCandlesResponse, error) {
+	if req.Symbol == "" {
+		return nil, fieldError("symbol", "symbol is required")
+	}
+	if domain.CandleInterval(req.Interval).Duration() == 0 {
+		return nil, fieldError("interval", "interval must be 1m, 5m, 1h or 1d")
+	}
+	if req.Limit < 0 {
+		return nil, fieldError("limit", "limit must be >= 0")
+	}
+	from, to := timeOf(req.From), timeOf(req.To)
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, fieldError("from", "from must be before to")
+	}
+	candles, err := s.Eng.GetCandles(ctx, req.Symbol, domain.CandleInterval(req.Interval), from, to, int(req.Limit))
+	if err != nil {
+		return nil, failure("get candles", err)
+	}
+	resp := &pb.GetCandlesResponse{Symbol: req.Symbol, Interval: req.Interval, Candles: make([]*pb.Candle, len(candles))}
+	for i, b := range candles {
+		resp.Candles[i] = &pb.Candle{
+			Start: timestamppb.New(b.Start), Open: b.Open.String(), High: b.High.String(), Low: b.Low.String(),
+			Close: b.Close.String(), Volume: b.Volume.String(), Notional: b.Notional.String(), Trades: int32(b.Trades),
+		}
+	}
+	return resp, nil
+}
+
+// timeOf is the time of an optional timestamp, zero when it is unset
+func timeOf(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func (s *GRPCServer) GetQuote(ctx context.Context, req *pb.GetQuoteRequest) (*pb.GetQuoteResponse, error) {
+	if req.Symbol == "" {
+		return nil, fieldError("symbol", "symbol is required")
+	}
+	q, err := s.Eng.GetQuote(ctx, req.Symbol)
+	if err != nil {
+		return nil, failure("get quote", err)
+	}
+	return &pb.GetQuoteResponse{
+		Symbol:    q.Symbol,
+		BidPrice:  nullDecimalString(q.BidPrice),
+		BidQty:    q.BidQty.String(),
+		AskPrice:  nullDecimalString(q.AskPrice),
+		AskQty:    q.AskQty.String(),
+		Spread:    nullDecimalString(q.Spread),
+		Mid:       nullDecimalString(q.Mid),
+		Timestamp: TimeToProto(q.Timestamp),
 	}, nil
 }
 
+func (s *GRPCServer) GetRecentTrades(ctx context.Context, req *pb.GetRecentTradesRequest) (*pb.GetRecentTradesResponse, error) {
+	if req.Symbol == "" {
+		return nil, fieldError("symbol", "symbol is required")
+	}
+	pg := page.Request{Cursor: req.Cursor, Limit: int(req.Limit)}.Capped(maxRecentTrades)
+	entries, err := s.Eng.ListTrades(ctx, req.Symbol, pg)
+	if err != nil {
+		return nil, failure("get recent trades", err)
+	}
+	viewer := view.FromContext(ctx)
+	resp := &pb.GetRecentTradesResponse{Trades: make([]*pb.Trade, len(entries.Items)), NextCursor: entries.NextCursor}
+	for i, e := range entries.Items {
+		resp.Trades[i] = s.convertTradeToPb(viewer.Trade(e.Trade))
+	}
+	return resp, nil
+}
+
 func (s *GRPCServer) SnapshotOrderbook(ctx context.Context, req *pb.SnapshotRequest) (*pb.SnapshotResponse, error) {
 	id, err := s.Eng.SnapshotOrderbook(ctx, req.Symbol)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "snapshot failed: %v", err)
+		return nil, failure("snapshot", err)
 	}
 	return &pb.SnapshotResponse{
 		SnapshotId: id,
@@ -158,7 +679,7 @@ func (s *GRPCServer) SnapshotOrderbook(ctx context.Context, req *pb.SnapshotRequ
 func (s *GRPCServer) RestoreOrderbook(ctx context.Context, req *pb.RestoreRequest) (*pb.RestoreResponse, error) {
 	ok, err := s.Eng.RestoreOrderbook(ctx, req.SnapshotId)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "restore failed: %v", err)
+		return nil, failure("restore", err)
 	}
 	return &pb.RestoreResponse{
 		Ok:      ok,
@@ -166,37 +687,655 @@ func (s *GRPCServer) RestoreOrderbook(ctx context.Context, req *pb.RestoreReques
 	}, nil
 }
 
-func convertOrderToPb(o *domain.Order) *pb.Order {
-	return &pb.Order{
-		Id:        o.ID,
-		ClientId:  o.ClientID,
-		Symbol:    o.Symbol,
-		Side:      string(o.Side),
-		Type:      string(o.Type),
-		Price:     o.Price.String(),
-		Quantity:  o.Quantity.String(),
-		Remaining: o.Remaining.String(),
-		CreatedAt: TimeToProto(o.CreatedAt),
+func (s *GRPCServer) ListSnapshots(ctx context.Context, req *pb.ListSnapshotsRequest) (*pb.ListSnapshotsResponse, error) {
+	if req.Symbol == "" {
+		return nil, fieldError("symbol", "symbol is required")
+	}
+	pg := page.Request{Cursor: req.Cursor, Limit: int(req.Limit)}.Capped(maxSnapshots)
+	metas, err := s.Eng.ListSnapshots(ctx, req.Symbol, pg)
+	if err != nil {
+		return nil, failure("list snapshots", err)
+	}
+	resp := &pb.ListSnapshotsResponse{Snapshots: make([]*pb.SnapshotMeta, len(metas.Items)), NextCursor: metas.NextCursor}
+	for i := range metas.Items {
+		resp.Snapshots[i] = convertSnapshotMetaToPb(&metas.Items[i])
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetSnapshot(ctx context.Context, req *pb.GetSnapshotRequest) (*pb.GetSnapshotResponse, error) {
+	if req.SnapshotId == "" {
+		return nil, fieldError("snapshot_id", "snapshot_id is required")
+	}
+	meta, err := s.Eng.GetSnapshotMeta(ctx, req.SnapshotId)
+	if err != nil {
+		return nil, notFound("snapshot", req.SnapshotId, fmt.Sprintf("get snapshot failed: %v", err))
+	}
+	resp := &pb.GetSnapshotResponse{Meta: convertSnapshotMetaToPb(meta)}
+	if req.IncludeOrders {
+		ob, err := s.Eng.GetSnapshot(ctx, req.SnapshotId)
+		if err != nil {
+			return nil, notFound("snapshot", req.SnapshotId, fmt.Sprintf("get snapshot failed: %v", err))
+		}
+		resp.Bids = s.convertOrdersToPb(ob.Bids)
+		resp.Asks = s.convertOrdersToPb(ob.Asks)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) DeleteSnapshot(ctx context.Context, req *pb.DeleteSnapshotRequest) (*pb.DeleteSnapshotResponse, error) {
+	if req.SnapshotId == "" {
+		return nil, fieldError("snapshot_id", "snapshot_id is required")
+	}
+	if err := s.Eng.DeleteSnapshot(ctx, req.SnapshotId); err != nil {
+		return nil, failure("delete snapshot", err)
+	}
+	return &pb.DeleteSnapshotResponse{Ok: true}, nil
+}
+
+func (s *GRPCServer) ExportSnapshot(ctx context.Context, req *pb.ExportSnapshotRequest) (*pb.ExportSnapshotResponse, error) {
+	if req.SnapshotId == "" {
+		return nil, fieldError("snapshot_id", "snapshot_id is required")
+	}
+	key, err := s.Eng.ExportSnapshot(ctx, req.SnapshotId)
+	if err != nil {
+		return nil, failure("export snapshot", err)
+	}
+	return &pb.ExportSnapshotResponse{Key: key}, nil
+}
+
+func (s *GRPCServer) ImportSnapshot(ctx context.Context, req *pb.ImportSnapshotRequest) (*pb.SnapshotResponse, error) {
+	if req.Key == "" {
+		return nil, fieldError("key", "key is required")
+	}
+	id, err := s.Eng.ImportSnapshot(ctx, req.Key)
+	if err != nil {
+		return nil, failure("import snapshot", err)
+	}
+	return &pb.SnapshotResponse{SnapshotId: id}, nil
+}
+
+func convertSnapshotMetaToPb(m *domain.SnapshotMeta) *pb.SnapshotMeta {
+	return &pb.SnapshotMeta{
+		SnapshotId: m.ID,
+		Symbol:     m.Symbol,
+		Sequence:   m.Sequence,
+		CreatedAt:  timestamppb.New(m.CreatedAt),
+		ExpiresAt:  timestamppb.New(m.ExpiresAt),
+		Bids:       int32(m.Bids),
+		Asks:       int32(m.Asks),
+		Bytes:      int32(m.Bytes),
+	}
+}
+
+func (s *GRPCServer) StreamImbalance(req *pb.StreamImbalanceRequest, stream pb.Exchange_StreamImbalanceServer) error {
+	if req.Symbol == "" {
+		return fieldError("symbol", "symbol is required")
+	}
+	sub := s.Eng.SubscribeImbalance(stream.Context(), req.Symbol)
+	defer sub.Close()
+	updates := pubsub.Throttle(stream.Context(), sub.C, pubsub.RateInterval(req.MaxRate))
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.drain.Done():
+			// conflated updates are superseded by the next instance's first one
+			return stream.Send(&pb.ImbalanceUpdate{Symbol: req.Symbol, End: endNotice("", "")})
+		case imb, ok := <-updates:
+			if !ok {
+				return streamClosed(sub.Err())
+			}
+			if err := stream.Send(&pb.ImbalanceUpdate{
+				Symbol:    imb.Symbol,
+				Levels:    int32(imb.Levels),
+				BidVolume: imb.BidVolume.String(),
+				AskVolume: imb.AskVolume.String(),
+				Imbalance: imb.Imbalance.String(),
+				Timestamp: TimeToProto(imb.Timestamp),
+				Dropped:   sub.Dropped(),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamQuotes sends the current quote of every requested symbol, then a quote whenever its best bid
+// or ask changes
+func (s *GRPCServer) StreamQuotes(req *pb.StreamQuotesRequest, stream pb.Exchange_StreamQuotesServer) error {
+	symbols := streamSymbols(req.Symbol, req.Symbols)
+	if len(symbols) == 0 {
+		return fieldError("symbol", "symbol is required")
+	}
+	ctx := stream.Context()
+	sub := s.Eng.SubscribeQuotes(ctx, symbols...)
+	defer sub.Close()
+	for _, sym := range symbols {
+		if sym == core.AllSymbols {
+			continue
+		}
+		q, err := s.Eng.GetQuote(ctx, sym)
+		if err != nil {
+			return failure("get quote", err)
+		}
+		if err := stream.Send(quoteUpdateToPb(q, 0)); err != nil {
+			return err
+		}
+	}
+	updates := pubsub.ThrottleBy(ctx, sub.C, pubsub.RateInterval(req.MaxRate),
+		func(q *domain.Quote) string { return q.Symbol })
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.drain.Done():
+			return stream.Send(&pb.QuoteUpdate{End: endNotice("", "")})
+		case q, ok := <-updates:
+			if !ok {
+				return streamClosed(sub.Err())
+			}
+			if err := stream.Send(quoteUpdateToPb(q, sub.Dropped())); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func quoteUpdateToPb(q *domain.Quote, dropped uint64) *pb.QuoteUpdate {
+	return &pb.QuoteUpdate{
+		Symbol:    q.Symbol,
+		BidPrice:  nullDecimalString(q.BidPrice),
+		BidQty:    q.BidQty.String(),
+		AskPrice:  nullDecimalString(q.AskPrice),
+		AskQty:    q.AskQty.String(),
+		Spread:    nullDecimalString(q.Spread),
+		Mid:       nullDecimalString(q.Mid),
+		Timestamp: TimeToProto(q.Timestamp),
+		Dropped:   dropped,
+	}
+}
+
+func (s *GRPCServer) StreamOrderbook(req *pb.StreamOrderbookRequest, stream pb.Exchange_StreamOrderbookServer) error {
+	symbols := streamSymbols(req.Symbol, req.Symbols)
+	if len(symbols) == 0 {
+		return fieldError("symbol", "symbol is required")
+	}
+	ctx := stream.Context()
+	if p, _ := auth.PrincipalFromContext(ctx); req.L3 && !p.Entitled(auth.EntitlementL3) {
+		return detailed(codes.PermissionDenied, "the order-by-order book needs the L3 entitlement",
+			errorInfo(reasonNotAllowed, map[string]string{"entitlement": string(auth.EntitlementL3)}))
+	}
+	viewer := view.FromContext(ctx)
+	show := func(ob *domain.OrderbookSnapshot) *domain.OrderbookSnapshot {
+		if req.L3 {
+			return viewer.Book(ob)
+		}
+		return view.Levels(viewer.Book(ob))
+	}
+	sub := s.Eng.SubscribeOrderbook(ctx, symbols...)
+	defer sub.Close()
+
+	// symbols added mid-stream start from their current book, like the initial ones
+	added := make(chan []string, 16)
+	unregister, err := s.subs.register(ctx, req.SubscriptionId, sub, func(syms []string) {
+		select {
+		case added <- syms:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer unregister()
+
+	sendBooks := func(syms []string) error {
+		for _, symbol := range syms {
+			if symbol == core.AllSymbols {
+				continue
+			}
+			ob, err := s.Eng.GetOrderbook(ctx, symbol)
+			if err != nil {
+				return failure("get orderbook", err)
+			}
+			if err := stream.Send(s.convertBookUpdateToPb(symbol, show(ob), 0)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := sendBooks(symbols); err != nil {
+		return err
+	}
+
+	updates := pubsub.ThrottleBy(ctx, sub.C, pubsub.RateInterval(req.MaxRate),
+		func(ob *domain.OrderbookSnapshot) string { return ob.Symbol })
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.drain.Done():
+			// clients start over from the full book after reconnecting, pending changes are not needed
+			return stream.Send(&pb.OrderbookUpdate{End: endNotice("", "")})
+		case syms := <-added:
+			if err := sendBooks(syms); err != nil {
+				return err
+			}
+		case ob, ok := <-updates:
+			if !ok {
+				return streamClosed(sub.Err())
+			}
+			if err := stream.Send(s.convertBookUpdateToPb(ob.Symbol, show(ob), sub.Dropped())); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// streamSymbols merges the single-symbol and multi-symbol fields of a stream request
+func streamSymbols(symbol string, symbols []string) []string {
+	out := make([]string, 0, len(symbols)+1)
+	if symbol != "" {
+		out = append(out, symbol)
+	}
+	for _, s := range symbols {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (s *GRPCServer) convertBookUpdateToPb(symbol string, ob *domain.OrderbookSnapshot, dropped uint64) *pb.OrderbookUpdate {
+	return &pb.OrderbookUpdate{
+		Symbol:    symbol,
+		Bids:      s.convertOrdersToPb(ob.Bids),
+		Asks:      s.convertOrdersToPb(ob.Asks),
+		Timestamp: TimeToProto(ob.Timestamp),
+		Dropped:   dropped,
+	}
+}
+
+func (s *GRPCServer) StreamTrades(req *pb.StreamTradesRequest, stream pb.Exchange_StreamTradesServer) error {
+	symbols := streamSymbols(req.Symbol, req.Symbols)
+	if len(symbols) == 0 {
+		return fieldError("symbol", "symbol is required")
+	}
+	filter, err := tradeFilterFromPb(req)
+	if err != nil {
+		return fieldError("filter", "invalid filter: %v", err)
+	}
+	// filtering by another participant would single out their trades
+	viewer := view.FromContext(stream.Context())
+	if filter.ClientID != "" && !viewer.Owns(filter.ClientID) {
+		return detailed(codes.PermissionDenied, "trades can only be filtered by the caller's own client",
+			errorInfo(reasonNotAllowed, map[string]string{"client_id": filter.ClientID}))
+	}
+	cur, err := core.ParseCursor(req.Cursor)
+	if err != nil {
+		return fieldError("cursor", "invalid cursor: %v", err)
+	}
+	if len(cur) > 0 && req.AfterSeq != "" {
+		return fieldError("after_seq", "cursor and after_seq are mutually exclusive")
+	}
+	// subscribe before the backfill so nothing executed in between is lost
+	sub := s.Eng.SubscribeTrades(stream.Context(), symbols...)
+	defer sub.Close()
+	unregister, err := s.subs.register(stream.Context(), req.SubscriptionId, sub, nil)
+	if err != nil {
+		return err
+	}
+	defer unregister()
+
+	// send skips what the cursor has already covered and moves it past e, filtered out or not
+	var lastSeq string
+	send := func(e domain.TapeEntry, dropped uint64) error {
+		if !cur.Advance(e.Trade.Symbol, e.Seq) || !filter.Match(e.Trade) {
+			return nil
+		}
+		if e.Seq != "" {
+			lastSeq = e.Seq
+		}
+		return stream.Send(&pb.TradeUpdate{Trade: s.convertTradeToPb(viewer.Trade(e.Trade)), Seq: e.Seq, Dropped: dropped, Cursor: cur.Token()})
+	}
+
+	switch {
+	case len(cur) > 0:
+		if err := s.replayTrades(stream.Context(), symbols, cur, send); err != nil {
+			return err
+		}
+	case req.AfterSeq != "" || req.Backfill > 0:
+		if len(symbols) > 1 && req.AfterSeq != "" {
+			return fieldError("after_seq", "after_seq needs a single symbol")
+		}
+		limit := int(req.Backfill)
+		if limit <= 0 || limit > maxRecentTrades {
+			limit = maxRecentTrades
+		}
+		for _, symbol := range symbols {
+			if symbol == core.AllSymbols {
+				continue
+			}
+			entries, err := s.Eng.TradeBackfill(stream.Context(), symbol, req.AfterSeq, limit)
+			if err != nil {
+				return failure("backfill", err)
+			}
+			for _, e := range entries {
+				if err := send(e, 0); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.drain.Done():
+			if err := flushQueued(sub.C, func(e domain.TapeEntry) error { return send(e, sub.Dropped()) }); err != nil {
+				return err
+			}
+			return stream.Send(&pb.TradeUpdate{End: endNotice(cur.Token(), lastSeq)})
+		case e, ok := <-sub.C:
+			if !ok {
+				return streamClosed(sub.Err())
+			}
+			if err := send(e, sub.Dropped()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// replayTrades sends everything on the tape after the cursor's position for each subscribed symbol it
+// covers, every symbol of the cursor on a wildcard stream
+func (s *GRPCServer) replayTrades(ctx context.Context, symbols []string, cur core.Cursor, send func(domain.TapeEntry, uint64) error) error {
+	var resume []string
+	for symbol := range cur {
+		if slices.Contains(symbols, symbol) || slices.Contains(symbols, core.AllSymbols) {
+			resume = append(resume, symbol)
+		}
+	}
+	sort.Strings(resume)
+	for _, symbol := range resume {
+		for {
+			entries, err := s.Eng.TradeBackfill(ctx, symbol, cur[symbol], maxRecentTrades)
+			if err != nil {
+				return failure("replay", err)
+			}
+			for _, e := range entries {
+				if err := send(e, 0); err != nil {
+					return err
+				}
+			}
+			if len(entries) < maxRecentTrades {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func tradeFilterFromPb(req *pb.StreamTradesRequest) (domain.TradeFilter, error) {
+	f := domain.TradeFilter{ClientID: req.ClientId}
+	var err error
+	if f.MinQty, err = optionalDecimal(req.MinQty); err != nil {
+		return f, fmt.Errorf("min_qty: %w", err)
+	}
+	if f.MinPrice, err = optionalDecimal(req.MinPrice); err != nil {
+		return f, fmt.Errorf("min_price: %w", err)
+	}
+	if f.MaxPrice, err = optionalDecimal(req.MaxPrice); err != nil {
+		return f, fmt.Errorf("max_price: %w", err)
+	}
+	return f, nil
+}
+
+func optionalDecimal(s string) (decimal.Decimal, error) {
+	if s == "" {
+		return decimal.Zero, nil
 	}
+	return decimal.NewFromString(s)
 }
 
-func convertOrdersToPb(in []domain.Order) []*pb.Order {
+func (s *GRPCServer) StreamOrderEvents(req *pb.StreamOrderEventsRequest, stream pb.Exchange_StreamOrderEventsServer) error {
+	if req.ClientId == core.AllClients {
+		if p, ok := auth.PrincipalFromContext(stream.Context()); !ok || !p.Entitled(auth.EntitlementDropCopy) {
+			return detailed(codes.PermissionDenied, "the all-client event stream needs the drop-copy entitlement",
+				errorInfo(reasonNotAllowed, map[string]string{"client_id": req.ClientId}))
+		}
+	} else {
+		clientID, err := actingClient(stream.Context(), req.ClientId)
+		if err != nil {
+			return err
+		}
+		req.ClientId = clientID
+	}
+	cur, err := core.ParseCursor(req.Cursor)
+	if err != nil {
+		return fieldError("cursor", "invalid cursor: %v", err)
+	}
+	// subscribe before the replay so nothing emitted in between is lost
+	sub := s.Eng.SubscribeOrderEvents(stream.Context(), req.ClientId)
+	defer sub.Close()
+
+	var lastSeq string
+	send := func(ev *domain.OrderEvent, dropped uint64) error {
+		if !cur.Advance(req.ClientId, ev.Seq) {
+			return nil
+		}
+		if ev.Seq != "" {
+			lastSeq = ev.Seq
+		}
+		msg := convertEventToPb(ev)
+		msg.Dropped = dropped
+		msg.Cursor = cur.Token()
+		return stream.Send(msg)
+	}
+
+	if after, ok := cur[req.ClientId]; ok {
+		for {
+			evs, err := s.Eng.EventsAfter(stream.Context(), req.ClientId, after, maxRecentTrades)
+			if err != nil {
+				return failure("replay", err)
+			}
+			for _, ev := range evs {
+				if err := send(ev, 0); err != nil {
+					return err
+				}
+				after = ev.Seq
+			}
+			if len(evs) < maxRecentTrades {
+				break
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.drain.Done():
+			if err := flushQueued(sub.C, func(ev *domain.OrderEvent) error { return send(ev, sub.Dropped()) }); err != nil {
+				return err
+			}
+			return stream.Send(&pb.OrderEvent{End: endNotice(cur.Token(), lastSeq)})
+		case ev, ok := <-sub.C:
+			if !ok {
+				return streamClosed(sub.Err())
+			}
+			if err := send(ev, sub.Dropped()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamSurveillanceAlerts streams the caller's tenant's surveillance alerts as they are raised
+func (s *GRPCServer) StreamSurveillanceAlerts(req *pb.StreamSurveillanceAlertsRequest, stream pb.Exchange_StreamSurveillanceAlertsServer) error {
+	f := domain.AlertFilter{Kind: domain.AlertKind(req.Kind), Symbol: req.Symbol, ClientID: req.ClientId}
+	sub := s.Eng.SubscribeSurveillanceAlerts(stream.Context())
+	defer sub.Close()
+	send := func(a *domain.SurveillanceAlert) error {
+		if !f.Match(a) {
+			return nil
+		}
+		msg := convertAlertToPb(a)
+		msg.Dropped = sub.Dropped()
+		return stream.Send(msg)
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.drain.Done():
+			if err := flushQueued(sub.C, send); err != nil {
+				return err
+			}
+			return stream.Send(&pb.SurveillanceAlert{End: endNotice("", "")})
+		case a, ok := <-sub.C:
+			if !ok {
+				return streamClosed(sub.Err())
+			}
+			if err := send(a); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func convertAlertToPb(a *domain.SurveillanceAlert) *pb.SurveillanceAlert {
+	return &pb.SurveillanceAlert{
+		Id:         a.ID,
+		Kind:       string(a.Kind),
+		Symbol:     a.Symbol,
+		ClientIds:  a.ClientIDs,
+		TradeIds:   a.TradeIDs,
+		Detail:     a.Detail,
+		At:         TimeToProto(a.At),
+		DetectedAt: TimeToProto(a.DetectedAt),
+		Status:     string(a.Status),
+	}
+}
+
+// streamClosed ends a stream whose subscription the engine closed, telling slow consumers why
+func streamClosed(err error) error {
+	if errors.Is(err, pubsub.ErrSlowConsumer) {
+		return detailed(codes.ResourceExhausted, err.Error(), errorInfo(reasonSlowConsumer, nil), retryAfter(slowConsumerRetryDelay))
+	}
+	return nil
+}
+
+func convertEventToPb(ev *domain.OrderEvent) *pb.OrderEvent {
+	return &pb.OrderEvent{
+		OrderId:    ev.OrderID,
+		ClientId:   ev.ClientID,
+		Symbol:     ev.Symbol,
+		Side:       string(ev.Side),
+		Type:       string(ev.Type),
+		ExecType:   string(ev.ExecType),
+		Status:     string(ev.Status),
+		Price:      ev.Price.String(),
+		Quantity:   ev.Quantity.String(),
+		Remaining:  ev.Remaining.String(),
+		LastPrice:  ev.LastPrice.String(),
+		LastQty:    ev.LastQty.String(),
+		TradeId:    ev.TradeID,
+		Reason:     ev.Reason,
+		RejectCode: string(ev.RejectCode),
+		Tags:       ev.Tags,
+		UserData:   ev.UserData,
+		GroupId:    ev.GroupID,
+		Timestamp:  TimeToProto(ev.Timestamp),
+		Sequence:   ev.Sequence,
+	}
+}
+
+func (s *GRPCServer) convertOrderToPb(o *domain.Order) *pb.Order {
+	prec := view.PrecisionOf(s.Eng, o.Symbol)
+	out := &pb.Order{
+		Id:            o.ID,
+		ClientId:      o.ClientID,
+		Symbol:        o.Symbol,
+		Side:          string(o.Side),
+		Type:          string(o.Type),
+		Price:         prec.FormatPrice(o.Price),
+		Quantity:      prec.FormatQuantity(o.Quantity),
+		Remaining:     prec.FormatQuantity(o.Remaining),
+		CreatedAt:     TimeToProto(o.CreatedAt),
+		UpdatedAt:     TimeToProto(o.UpdatedAt),
+		SessionId:     o.SessionID,
+		Tags:          o.Tags,
+		UserData:      o.UserData,
+		GroupId:       o.GroupID,
+		ClientOrderId: o.ClientOrderID,
+	}
+	if o.Type.IsStop() {
+		out.TriggerPrice = prec.FormatPrice(o.TriggerPrice)
+	}
+	return out
+}
+
+func (s *GRPCServer) convertOrdersToPb(in []domain.Order) []*pb.Order {
 	out := make([]*pb.Order, 0, len(in))
 	for _, o := range in {
 		cpy := o
-		out = append(out, convertOrderToPb(&cpy))
+		out = append(out, s.convertOrderToPb(&cpy))
+	}
+	return out
+}
+
+func (s *GRPCServer) convertTradeToPb(t *domain.Trade) *pb.Trade {
+	prec := view.PrecisionOf(s.Eng, t.Symbol)
+	return &pb.Trade{
+		Id:            t.ID,
+		Symbol:        t.Symbol,
+		BuyOrder:      t.BuyOrder,
+		SellOrder:     t.SellOrder,
+		Price:         prec.FormatPrice(t.Price),
+		Quantity:      prec.FormatQuantity(t.Quantity),
+		Timestamp:     TimeToProto(t.Timestamp),
+		MakerFee:      t.MakerFee.String(),
+		TakerFee:      t.TakerFee.String(),
+		MakerFeeAsset: t.MakerFeeAsset,
+		TakerFeeAsset: t.TakerFeeAsset,
+		TakerSide:     string(t.TakerSide),
+		TakerType:     string(t.TakerType),
+		BuyLiquidity:  string(t.BuyLiquidity),
+		SellLiquidity: string(t.SellLiquidity),
+		Sequence:      t.Sequence,
+	}
+}
+
+func (s *GRPCServer) convertTradesToPb(in []*domain.Trade) []*pb.Trade {
+	out := make([]*pb.Trade, 0, len(in))
+	for _, t := range in {
+		out = append(out, s.convertTradeToPb(t))
 	}
 	return out
 }
 
 func ValidateOrder(req *pb.SubmitOrderRequest) error {
 	if req.Side != "BUY" && req.Side != "SELL" {
-		return status.Errorf(codes.InvalidArgument, "invalid side: %s", req.Side)
+		return rejectStatus(domain.Reject(domain.RejectInvalidOrder, "invalid side: %s", req.Side))
+	}
+	switch domain.OrderType(req.Type) {
+	case domain.Limit, domain.Market, domain.Stop, domain.StopLimit:
+	default:
+		return rejectStatus(domain.Reject(domain.RejectInvalidOrder, "invalid type: %s", req.Type))
 	}
-	if req.Type != "LIMIT" && req.Type != "MARKET" {
-		return status.Errorf(codes.InvalidArgument, "invalid type: %s", req.Type)
+	if err := domain.ValidateClientOrderID(req.ClientOrderId); err != nil {
+		return rejectStatus(err)
 	}
 	return nil
 }
 
+func nullDecimalString(d decimal.NullDecimal) string {
+	if !d.Valid {
+		return ""
+	}
+	return d.Decimal.String()
+}
+
 func TimeToProto(t time.Time) *timestamppb.Timestamp { return timestamppb.New(t) }
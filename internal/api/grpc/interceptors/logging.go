@@ -0,0 +1,54 @@
+// Package interceptors holds the gRPC server interceptors that know nothing of the exchange service:
+// request logging and panic recovery. Authentication and role checks live with the service, see
+// grpc.UnaryRBAC.
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryLogging logs every call with its status code and latency; failed calls are logged at warn
+// level with the error
+func UnaryLogging(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, "unary", info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamLogging logs every stream once it ends, with its status code and how long it was open
+func StreamLogging(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, "stream", info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(ctx context.Context, logger *slog.Logger, kind, method string, start time.Time, err error) {
+	st := status.Convert(err)
+	attrs := []slog.Attr{
+		slog.String("kind", kind),
+		slog.String("method", method),
+		slog.String("code", st.Code().String()),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", st.Message()))
+	}
+	logger.LogAttrs(ctx, level, "grpc call", attrs...)
+}
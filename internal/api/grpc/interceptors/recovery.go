@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errPanic is what the caller of a call that panicked gets; the panic itself is only logged
+var errPanic = status.Error(codes.Internal, "internal error")
+
+// UnaryRecovery turns a panic in the handler into an Internal error, logging the panic and its stack,
+// so one bad request does not take the server down
+func UnaryRecovery(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logPanic(ctx, logger, info.FullMethod, r)
+				resp, err = nil, errPanic
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery is UnaryRecovery for streams
+func StreamRecovery(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logPanic(ss.Context(), logger, info.FullMethod, r)
+				err = errPanic
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func logPanic(ctx context.Context, logger *slog.Logger, method string, r any) {
+	logger.LogAttrs(ctx, slog.LevelError, "grpc handler panicked",
+		slog.String("method", method), slog.Any("panic", r), slog.String("stack", string(debug.Stack())))
+}
@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/olyamironova/exchange-engine/internal/auth"
+	pb "github.com/olyamironova/exchange-engine/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// methodRoles lists who may call each RPC; methods not listed need auth.ReadRoles
+var methodRoles = map[string][]auth.Role{
+	pb.Exchange_SubmitOrder_FullMethodName:         auth.TradeRoles,
+	pb.Exchange_BatchSubmitOrders_FullMethodName:   auth.TradeRoles,
+	pb.Exchange_SubmitOrderGroup_FullMethodName:    auth.TradeRoles,
+	pb.Exchange_CancelOrderGroup_FullMethodName:    auth.TradeRoles,
+	pb.Exchange_ModifyOrder_FullMethodName:         auth.TradeRoles,
+	pb.Exchange_CancelOrder_FullMethodName:         auth.TradeRoles,
+	pb.Exchange_BatchCancelOrders_FullMethodName:   auth.TradeRoles,
+	pb.Exchange_BatchModifyOrders_FullMethodName:   auth.TradeRoles,
+	pb.Exchange_CancelBySide_FullMethodName:        auth.TradeRoles,
+	pb.Exchange_CancelAllOrders_FullMethodName:     auth.TradeRoles,
+	pb.Exchange_Login_FullMethodName:               auth.TradeRoles,
+	pb.Exchange_Heartbeat_FullMethodName:           auth.TradeRoles,
+	pb.Exchange_Logout_FullMethodName:              auth.TradeRoles,
+	pb.Exchange_CancelSessionOrders_FullMethodName: auth.TradeRoles,
+	pb.Exchange_SnapshotOrderbook_FullMethodName:   auth.AdminRoles,
+	pb.Exchange_RestoreOrderbook_FullMethodName:    auth.AdminRoles,
+	pb.Exchange_ListSnapshots_FullMethodName:       auth.AdminRoles,
+	pb.Exchange_GetSnapshot_FullMethodName:         auth.AdminRoles,
+	pb.Exchange_DeleteSnapshot_FullMethodName:      auth.AdminRoles,
+	pb.Exchange_ExportSnapshot_FullMethodName:      auth.AdminRoles,
+	pb.Exchange_ImportSnapshot_FullMethodName:      auth.AdminRoles,
+	pb.Exchange_ForceCancelOrder_FullMethodName:    auth.AdminRoles,
+
+	pb.Exchange_StreamSurveillanceAlerts_FullMethodName: auth.ComplianceRoles,
+}
+
+func authorize(ctx context.Context, store *auth.KeyStore, method string) (context.Context, error) {
+	creds := auth.Credentials{Path: method}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		first := func(name string) string {
+			if v := md.Get(name); len(v) > 0 {
+				return v[0]
+			}
+			return ""
+		}
+		creds.APIKey = first("x-api-key")
+		creds.Timestamp = first("x-timestamp")
+		creds.Signature = first("x-signature")
+		creds.Bearer, _ = strings.CutPrefix(first("authorization"), "Bearer ")
+	}
+	p, err := store.Authenticate(creds)
+	switch {
+	case errors.Is(err, auth.ErrUnknownKey):
+		return nil, detailed(codes.Unauthenticated, err.Error(), errorInfo(reasonUnknownAPIKey, nil))
+	case err != nil:
+		return nil, detailed(codes.Unauthenticated, err.Error(), errorInfo(reasonBadCredentials, nil))
+	}
+	roles, ok := methodRoles[method]
+	if !ok {
+		roles = auth.ReadRoles
+	}
+	if !p.HasAny(roles...) {
+		return nil, detailed(codes.PermissionDenied, method+" is not allowed", errorInfo(reasonNotAllowed, map[string]string{"method": method}))
+	}
+	return auth.WithPrincipal(ctx, p), nil
+}
+
+// actingClient returns the client the caller acts for given the client_id it names, see
+// auth.ClientID
+func actingClient(ctx context.Context, claimed string) (string, error) {
+	id, err := auth.ClientID(ctx, claimed)
+	switch {
+	case errors.Is(err, auth.ErrClientRequired):
+		return "", fieldError("client_id", "%v", err)
+	case err != nil:
+		return "", detailed(codes.PermissionDenied, err.Error(), errorInfo(reasonNotAllowed, map[string]string{"client_id": claimed}))
+	}
+	return id, nil
+}
+
+// UnaryRBAC rejects unary calls the caller's roles do not allow
+func UnaryRBAC(store *auth.KeyStore) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, store, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRBAC rejects streams the caller's roles do not allow, and streams beyond the API key's limit
+// of open streams
+func StreamRBAC(store *auth.KeyStore) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), store, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		p, _ := auth.PrincipalFromContext(ctx)
+		release, err := store.OpenStream(p)
+		if err != nil {
+			return detailed(codes.ResourceExhausted, err.Error(), errorInfo(reasonStreamLimit, nil))
+		}
+		defer release()
+		return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context { return s.ctx }
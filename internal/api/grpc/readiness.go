@@ -0,0 +1,38 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/core"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func notReady(eng core.Exchange) error {
+	st := eng.WarmupStatus()
+	if st.Ready {
+		return nil
+	}
+	return detailed(codes.Unavailable, "the exchange is loading its order books",
+		errorInfo(reasonWarmingUp, nil), retryAfter(warmupRetryDelay))
+}
+
+// UnaryReady rejects calls until the engine has loaded its books
+func UnaryReady(eng core.Exchange) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := notReady(eng); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamReady rejects streams until the engine has loaded its books
+func StreamReady(eng core.Exchange) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := notReady(eng); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
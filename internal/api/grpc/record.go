@@ -0,0 +1,38 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/record"
+	pb "github.com/olyamironova/exchange-engine/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// recordedMethods are the order-entry RPCs written to ServerConfig.Recorder
+var recordedMethods = map[string]bool{
+	pb.Exchange_SubmitOrder_FullMethodName:       true,
+	pb.Exchange_BatchSubmitOrders_FullMethodName: true,
+	pb.Exchange_ModifyOrder_FullMethodName:       true,
+	pb.Exchange_CancelOrder_FullMethodName:       true,
+	pb.Exchange_BatchCancelOrders_FullMethodName: true,
+	pb.Exchange_BatchModifyOrders_FullMethodName: true,
+	pb.Exchange_CancelBySide_FullMethodName:      true,
+	pb.Exchange_CancelAllOrders_FullMethodName:   true,
+	pb.Exchange_ForceCancelOrder_FullMethodName:  true,
+}
+
+// UnaryRecord writes order-entry calls to rec before they are handled
+func UnaryRecord(rec *record.Recorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if m, ok := req.(proto.Message); ok && recordedMethods[info.FullMethod] {
+			at := time.Now().UTC()
+			if body, err := protojson.Marshal(m); err == nil {
+				rec.Record(record.Entry{At: at, Proto: record.ProtoGRPC, Path: info.FullMethod, Body: body})
+			}
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/api/grpc/interceptors"
+	"github.com/olyamironova/exchange-engine/internal/auth"
+	"github.com/olyamironova/exchange-engine/internal/core"
+	"github.com/olyamironova/exchange-engine/internal/record"
+	pb "github.com/olyamironova/exchange-engine/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ServerConfig tunes connection management of the gRPC server; zero fields keep the grpc-go defaults
+type ServerConfig struct {
+	// KeepaliveTime pings a client after this long without activity, KeepaliveTimeout closes the
+	// connection when the ping is not acknowledged in time
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	// MinPingInterval and PermitPingWithoutStream are the enforcement policy for client pings;
+	// clients pinging more often are disconnected
+	MinPingInterval         time.Duration
+	PermitPingWithoutStream bool
+	MaxConnectionIdle       time.Duration
+	// MaxConnectionAge recycles connections so clients rebalance across instances after deploys;
+	// MaxConnectionAgeGrace is left to in-flight RPCs and streams
+	MaxConnectionAge      time.Duration
+	MaxConnectionAgeGrace time.Duration
+	MaxConcurrentStreams  uint32
+	MaxRecvMsgSize        int
+	MaxSendMsgSize        int
+	// MaxBatchSize bounds the orders of a batch call; zero keeps the server's default
+	MaxBatchSize int
+	// Recorder, if set, records order-entry calls for replay
+	Recorder *record.Recorder
+	// Logger receives a line per call and the panics recovered from handlers; nil is slog.Default()
+	Logger *slog.Logger
+}
+
+// DefaultServerConfig keeps idle stream connections alive through proxies and leaves room for full orderbooks
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		KeepaliveTime:           30 * time.Second,
+		KeepaliveTimeout:        10 * time.Second,
+		MinPingInterval:         10 * time.Second,
+		PermitPingWithoutStream: true,
+		MaxConcurrentStreams:    1000,
+		MaxRecvMsgSize:          4 << 20,
+		MaxSendMsgSize:          16 << 20,
+	}
+}
+
+// ServerOptions translates the config into grpc-go server options
+func (c ServerConfig) ServerOptions() []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     c.MaxConnectionIdle,
+			MaxConnectionAge:      c.MaxConnectionAge,
+			MaxConnectionAgeGrace: c.MaxConnectionAgeGrace,
+			Time:                  c.KeepaliveTime,
+			Timeout:               c.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             c.MinPingInterval,
+			PermitWithoutStream: c.PermitPingWithoutStream,
+		}),
+	}
+	if c.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+	if c.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(c.MaxRecvMsgSize))
+	}
+	if c.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(c.MaxSendMsgSize))
+	}
+	return opts
+}
+
+// NewServer builds a gRPC server exposing the exchange service behind logging, panic recovery,
+// readiness and RBAC checks, tuned by cfg
+func NewServer(eng core.Exchange, keys *auth.KeyStore, cfg ServerConfig) *Server {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	// logging comes first so it also reports calls rejected or recovered by the others
+	unary := []grpc.UnaryServerInterceptor{
+		interceptors.UnaryLogging(logger), interceptors.UnaryRecovery(logger), UnaryReady(eng), UnaryRBAC(keys),
+	}
+	if cfg.Recorder != nil {
+		unary = append(unary, UnaryRecord(cfg.Recorder))
+	}
+	opts := append(cfg.ServerOptions(),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(interceptors.StreamLogging(logger), interceptors.StreamRecovery(logger),
+			StreamReady(eng), StreamRBAC(keys)),
+	)
+	srv := &Server{Server: grpc.NewServer(opts...), svc: NewGRPCServer(eng)}
+	if cfg.MaxBatchSize > 0 {
+		srv.svc.MaxBatchSize = cfg.MaxBatchSize
+	}
+	pb.RegisterExchangeServer(srv.Server, srv.svc)
+	return srv
+}
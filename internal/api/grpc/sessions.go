@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	pb "github.com/olyamironova/exchange-engine/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// sessionToken is the metadata key carrying the token of the session a call is made through
+const sessionToken = "x-session-token"
+
+func (s *GRPCServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Session, error) {
+	clientID, err := actingClient(ctx, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.Eng.Login(ctx, clientID, req.CancelOnDisconnect)
+	if err != nil {
+		return nil, failure("login", err)
+	}
+	res := sessionToPb(sess)
+	res.Token = sess.Token
+	return res, nil
+}
+
+func (s *GRPCServer) Heartbeat(ctx context.Context, _ *pb.HeartbeatRequest) (*pb.Session, error) {
+	sess, err := s.Eng.Heartbeat(ctx, tokenFrom(ctx))
+	if err != nil {
+		return nil, failure("heartbeat", err)
+	}
+	return sessionToPb(sess), nil
+}
+
+func (s *GRPCServer) Logout(ctx context.Context, _ *pb.LogoutRequest) (*pb.SessionCancelResponse, error) {
+	ids, err := s.Eng.Logout(ctx, tokenFrom(ctx))
+	if err != nil {
+		return nil, failure("logout", err)
+	}
+	return &pb.SessionCancelResponse{CancelledOrderIds: ids}, nil
+}
+
+func (s *GRPCServer) CancelSessionOrders(ctx context.Context, _ *pb.CancelSessionOrdersRequest) (*pb.SessionCancelResponse, error) {
+	ids, err := s.Eng.CancelSessionOrders(ctx, tokenFrom(ctx))
+	if err != nil {
+		return nil, failure("cancel session orders", err)
+	}
+	return &pb.SessionCancelResponse{CancelledOrderIds: ids}, nil
+}
+
+// orderSession returns the ID of the session an order of the client is placed through, empty if
+// the call carries no session token
+func (s *GRPCServer) orderSession(ctx context.Context, clientID string) (string, error) {
+	token := tokenFrom(ctx)
+	if token == "" {
+		return "", nil
+	}
+	sess, err := s.Eng.Heartbeat(ctx, token)
+	if err != nil {
+		return "", failure("submit", err)
+	}
+	if sess.ClientID != clientID {
+		return "", detailed(codes.PermissionDenied, "session belongs to another client",
+			errorInfo(reasonNotAllowed, map[string]string{"client_id": clientID}))
+	}
+	return sess.ID, nil
+}
+
+func tokenFrom(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(sessionToken); len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+func sessionToPb(sess domain.Session) *pb.Session {
+	return &pb.Session{
+		SessionId:          sess.ID,
+		ClientId:           sess.ClientID,
+		CancelOnDisconnect: sess.CancelOnDisconnect,
+		CreatedAt:          TimeToProto(sess.CreatedAt),
+		LastSeen:           TimeToProto(sess.LastSeen),
+	}
+}
@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/olyamironova/exchange-engine/internal/auth"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	pb "github.com/olyamironova/exchange-engine/proto"
+	"google.golang.org/grpc/codes"
+)
+
+// symbolSet is an open multi-symbol stream that UpdateSubscription can change
+type symbolSet interface {
+	AddSymbols(symbols ...string)
+	RemoveSymbols(symbols ...string)
+	Symbols() []string
+}
+
+type namedStream struct {
+	owner string // API key that opened the stream
+	set   symbolSet
+	added func(symbols []string)
+}
+
+// subscriptions maps client-chosen subscription ids of open streams, scoped by tenant
+type subscriptions struct {
+	mu      sync.Mutex
+	streams map[string]*namedStream
+}
+
+func callerKey(ctx context.Context) string {
+	p, _ := auth.PrincipalFromContext(ctx)
+	return p.Key
+}
+
+// register names an open stream; added, if set, is called with symbols added later.
+// The returned func unregisters it.
+func (r *subscriptions) register(ctx context.Context, id string, set symbolSet, added func([]string)) (func(), error) {
+	if id == "" {
+		return func() {}, nil
+	}
+	key := tenant.Scope(ctx, id)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.streams == nil {
+		r.streams = make(map[string]*namedStream)
+	}
+	if _, ok := r.streams[key]; ok {
+		return nil, detailed(codes.AlreadyExists, "subscription_id is already in use", errorInfo(reasonSubscriptionID, nil))
+	}
+	r.streams[key] = &namedStream{owner: callerKey(ctx), set: set, added: added}
+	return func() {
+		r.mu.Lock()
+		delete(r.streams, key)
+		r.mu.Unlock()
+	}, nil
+}
+
+func (r *subscriptions) lookup(ctx context.Context, id string) (*namedStream, error) {
+	r.mu.Lock()
+	ns, ok := r.streams[tenant.Scope(ctx, id)]
+	r.mu.Unlock()
+	if !ok || ns.owner != callerKey(ctx) {
+		return nil, notFound("subscription", id, "no open stream with this subscription_id")
+	}
+	return ns, nil
+}
+
+// UpdateSubscription adds and removes symbols of a StreamTrades or StreamOrderbook call opened with subscription_id
+func (s *GRPCServer) UpdateSubscription(ctx context.Context, req *pb.UpdateSubscriptionRequest) (*pb.UpdateSubscriptionResponse, error) {
+	if req.SubscriptionId == "" {
+		return nil, fieldError("subscription_id", "subscription_id is required")
+	}
+	ns, err := s.subs.lookup(ctx, req.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	ns.set.RemoveSymbols(req.Remove...)
+	ns.set.AddSymbols(req.Add...)
+	if ns.added != nil && len(req.Add) > 0 {
+		ns.added(req.Add)
+	}
+	return &pb.UpdateSubscriptionResponse{Symbols: ns.set.Symbols()}, nil
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func (s *HTTPServer) getBalances(c *gin.Context) {
+	clientID := c.Query("client_id")
+	if !actingClient(c, &clientID) {
+		return
+	}
+	s.writeBalances(c, clientID)
+}
+
+func (s *HTTPServer) writeBalances(c *gin.Context, clientID string) {
+	accounts, err := s.Eng.Balances(c.Request.Context(), clientID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	resp := dto.BalancesResponse{ClientID: clientID, Balances: make([]dto.Balance, len(accounts))}
+	for i, a := range accounts {
+		resp.Balances[i] = dto.Balance{Asset: a.Asset, Available: a.Available, Locked: a.Locked, Total: a.Total(), UpdatedAt: a.UpdatedAt}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *HTTPServer) deposit(c *gin.Context) {
+	s.transfer(c, s.Eng.Deposit)
+}
+
+func (s *HTTPServer) withdraw(c *gin.Context) {
+	s.transfer(c, s.Eng.Withdraw)
+}
+
+// transfer applies a deposit or withdrawal and answers with the client's balances after it
+func (s *HTTPServer) transfer(c *gin.Context, move func(ctx context.Context, clientID, asset string, amount decimal.Decimal) error) {
+	var req dto.AccountTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := move(c.Request.Context(), req.ClientID, req.Asset, req.Amount); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, domain.ErrInsufficientFunds) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	s.writeBalances(c, req.ClientID)
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// dumpOrderbook renders a symbol's full book as JSON or, with format=text, as an aligned ladder with
+// the asks above the bids
+func (s *HTTPServer) dumpOrderbook(c *gin.Context) {
+	var req dto.BookDumpRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Format != "" && req.Format != "json" && req.Format != "text" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or text"})
+		return
+	}
+	d, err := s.Eng.DumpOrderbook(c.Request.Context(), req.Symbol)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Format != "text" {
+		c.JSON(http.StatusOK, s.convertBookDump(d))
+		return
+	}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	writeBookDump(c.Writer, d)
+}
+
+// replayOrderbook returns the book rebuilt from the ledger, to compare with GET /orderbook when
+// recovering or auditing
+func (s *HTTPServer) replayOrderbook(c *gin.Context) {
+	symbol := c.Query("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol is required"})
+		return
+	}
+	ob, err := s.Eng.ReplayOrderbook(c.Request.Context(), symbol)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.GetOrderbookResponse{
+		Bids:      s.convertOrders(ob.Bids),
+		Asks:      s.convertOrders(ob.Asks),
+		Timestamp: ob.Timestamp,
+		Sequence:  ob.Sequence,
+	})
+}
+
+func writeBookDump(w io.Writer, d *domain.BookDump) {
+	fmt.Fprintf(w, "%s at %s: %d bid levels, %d ask levels, %d triggers\n\n",
+		d.Symbol, d.At.Format(time.RFC3339Nano), len(d.Bids), len(d.Asks), len(d.Triggers))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "side\tprice\tlevel\tpos\tahead\tremaining\tdisplay\thidden\tquantity\tclient\torder\tage\t")
+	row := func(side string, l domain.DumpLevel, o domain.DumpOrder) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", side, l.Price, l.Remaining, o.Position, o.Ahead,
+			o.Order.Remaining, o.Display, o.Hidden, o.Order.Quantity, o.Order.ClientID, o.Order.ID,
+			d.At.Sub(o.Order.CreatedAt).Round(time.Millisecond))
+	}
+	// worst ask first, so the spread sits in the middle of the ladder
+	for i := len(d.Asks) - 1; i >= 0; i-- {
+		for j := len(d.Asks[i].Orders) - 1; j >= 0; j-- {
+			row("ASK", d.Asks[i], d.Asks[i].Orders[j])
+		}
+	}
+	fmt.Fprintln(tw, "\t\t\t\t\t\t\t\t\t\t\t\t")
+	for _, l := range d.Bids {
+		for _, o := range l.Orders {
+			row("BID", l, o)
+		}
+	}
+	for _, o := range d.Triggers {
+		row("TRIGGER", domain.DumpLevel{Price: o.Order.TriggerPrice}, o)
+	}
+	_ = tw.Flush()
+}
+
+func (s *HTTPServer) convertBookDump(d *domain.BookDump) dto.BookDump {
+	levels := func(in []domain.DumpLevel) []dto.DumpLevel {
+		out := make([]dto.DumpLevel, 0, len(in))
+		for _, l := range in {
+			out = append(out, dto.DumpLevel{
+				Price: l.Price, Remaining: l.Remaining, Display: l.Display, Hidden: l.Hidden, Orders: s.convertDumpOrders(l.Orders),
+			})
+		}
+		return out
+	}
+	return dto.BookDump{Symbol: d.Symbol, At: d.At, Bids: levels(d.Bids), Asks: levels(d.Asks), Triggers: s.convertDumpOrders(d.Triggers)}
+}
+
+func (s *HTTPServer) convertDumpOrders(in []domain.DumpOrder) []dto.DumpOrder {
+	out := make([]dto.DumpOrder, 0, len(in))
+	for _, o := range in {
+		out = append(out, dto.DumpOrder{Order: s.convertOrder(&o.Order), Position: o.Position, Ahead: o.Ahead, Display: o.Display, Hidden: o.Hidden})
+	}
+	return out
+}
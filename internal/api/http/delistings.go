@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+func (s *HTTPServer) delistSymbol(c *gin.Context) {
+	var req dto.DelistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	d, err := s.Eng.AnnounceDelisting(c.Request.Context(), req.Symbol, req.HaltAt, req.DelistAt, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, convertDelisting(d, time.Now()))
+}
+
+func (s *HTTPServer) withdrawDelisting(c *gin.Context) {
+	var req dto.WithdrawDelistingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.Eng.WithdrawDelisting(c.Request.Context(), req.Symbol); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"withdrawn": true})
+}
+
+func (s *HTTPServer) getDelistings(c *gin.Context) {
+	list, err := s.Eng.Delistings(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	out := make([]dto.Delisting, 0, len(list))
+	for _, d := range list {
+		out = append(out, convertDelisting(d, now))
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func convertDelisting(d domain.Delisting, now time.Time) dto.Delisting {
+	return dto.Delisting{
+		Symbol:      d.Symbol,
+		Phase:       string(d.Phase(now)),
+		Reason:      d.Reason,
+		AnnouncedAt: d.AnnouncedAt,
+		HaltAt:      d.HaltAt,
+		DelistAt:    d.DelistAt,
+		DelistedAt:  d.DelistedAt,
+	}
+}
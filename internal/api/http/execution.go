@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/auth"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// getExecutionStats returns the execution quality of a client's or a symbol's orders
+func (s *HTTPServer) getExecutionStats(c *gin.Context) {
+	var req dto.ExecutionStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// a symbol's statistics over all its clients need no client, unless the caller is bound to one
+	if p, ok := auth.PrincipalFromContext(c.Request.Context()); req.ClientID != "" || req.Symbol == "" || (ok && p.ClientID != "") {
+		if !actingClient(c, &req.ClientID) {
+			return
+		}
+	}
+	st, err := s.Eng.ExecutionStats(c.Request.Context(), req.ClientID, req.Symbol)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, convertExecutionStats(st))
+}
+
+func convertExecutionStats(st *domain.ExecutionStats) dto.ExecutionStats {
+	out := dto.ExecutionStats{
+		ClientID: st.ClientID, Symbol: st.Symbol,
+		Orders: st.Orders, Rejected: st.Rejected, Filled: st.Filled, Cancelled: st.Cancelled,
+		Quantity: st.Quantity, FilledQuantity: st.FilledQuantity, FillRate: st.FillRate,
+		SlippageBps: st.SlippageBps, EffectiveSpreadBps: st.EffectiveSpreadBps,
+		TimeToFill: make([]dto.DurationBucket, 0, len(st.TimeToFill)),
+	}
+	for _, b := range st.TimeToFill {
+		var upTo string
+		if b.UpTo > 0 {
+			upTo = b.UpTo.String()
+		}
+		out.TimeToFill = append(out.TimeToFill, dto.DurationBucket{UpTo: upTo, Count: b.Count})
+	}
+	return out
+}
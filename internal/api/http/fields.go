@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondFields writes v as JSON like c.JSON, trimming every entry of its lists (orders of a book,
+// trades) to the fields named in the comma-separated "fields" query parameter, e.g. fields=price,remaining
+func respondFields(c *gin.Context, code int, v any) {
+	keep := make(map[string]bool)
+	for _, f := range strings.Split(c.Query("fields"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			keep[f] = true
+		}
+	}
+	if len(keep) == 0 {
+		c.JSON(code, v)
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		c.JSON(code, v)
+		return
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(b, &doc); err != nil {
+		c.JSON(code, v)
+		return
+	}
+	for name, raw := range doc {
+		var entries []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			for field := range entry {
+				if !keep[field] {
+					delete(entry, field)
+				}
+			}
+		}
+		if doc[name], err = json.Marshal(entries); err != nil {
+			c.JSON(code, v)
+			return
+		}
+	}
+	c.JSON(code, doc)
+}
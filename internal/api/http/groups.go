@@ -0,0 +1,94 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/api/view"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// submitOrderGroup answers 200 once every order of the basket was submitted; if one failed at
+// submission the basket was cancelled and the response, with 422, says what happened to each order
+func (s *HTTPServer) submitOrderGroup(c *gin.Context) {
+	var req dto.SubmitOrderGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	legs := make([]*domain.Order, len(req.Orders))
+	for i := range req.Orders {
+		r := &req.Orders[i]
+		if err := ValidateOrder(r); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("leg %d: %v", i, err), "reject_code": domain.RejectCodeOf(err)})
+			return
+		}
+		if !actingClient(c, &r.ClientID) {
+			return
+		}
+		sessionID, ok := s.orderSession(c, r.ClientID)
+		if !ok {
+			return
+		}
+		legs[i] = &domain.Order{
+			ID:            r.OrderID,
+			ClientID:      r.ClientID,
+			ClientOrderID: r.ClientOrderID,
+			SessionID:     sessionID,
+			Symbol:        r.Symbol,
+			Side:          domain.Side(r.Side),
+			Type:          domain.OrderType(r.Type),
+			Price:         r.Price,
+			Quantity:      r.Quantity,
+			Tags:          r.Tags,
+			UserData:      r.UserData,
+		}
+	}
+
+	res, err := s.Eng.SubmitOrderGroup(c.Request.Context(), legs, req.CancelOnFill)
+	if res.GroupID == "" {
+		orderError(c, err)
+		return
+	}
+	out := dto.SubmitOrderGroupResponse{GroupID: res.GroupID, Legs: make([]dto.SubmitOrderResponse, len(res.Legs))}
+	for i, leg := range res.Legs {
+		out.Legs[i] = dto.SubmitOrderResponse{
+			OrderID:   leg.Order.ID,
+			Trades:    s.convertTrades(leg.Trades),
+			Remaining: view.PrecisionOf(s.Eng, leg.Order.Symbol).FormatQuantity(leg.Order.Remaining),
+			Accepted:  leg.Err == nil,
+			UserData:  leg.Order.UserData,
+		}
+		if leg.Err != nil {
+			out.Legs[i].RejectReason, out.Legs[i].RejectCode = leg.Err.Error(), string(domain.RejectCodeOf(leg.Err))
+		}
+	}
+	if err != nil {
+		out.Error = err.Error()
+		c.JSON(http.StatusUnprocessableEntity, out)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func (s *HTTPServer) cancelOrderGroup(c *gin.Context) {
+	var req dto.CancelOrderGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	ids, err := s.Eng.CancelOrderGroup(c.Request.Context(), req.ClientID, req.GroupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	c.JSON(http.StatusOK, dto.CancelOrderGroupResponse{CancelledOrderIDs: ids})
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"net/http"
@@ -8,36 +9,197 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/adapter/breaker"
+	"github.com/olyamironova/exchange-engine/internal/adapter/pg"
 	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/api/view"
+	"github.com/olyamironova/exchange-engine/internal/auth"
 	"github.com/olyamironova/exchange-engine/internal/core"
 	"github.com/olyamironova/exchange-engine/internal/domain"
 	"github.com/olyamironova/exchange-engine/internal/middleware"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/record"
 	"github.com/shopspring/decimal"
 )
 
+const (
+	maxRecentTrades = 1000
+	maxBatchSize    = 500
+	maxSnapshots    = 200
+	maxOrders       = 1000
+	maxTrades       = 1000
+	// compressMinSize is the smallest response body worth compressing
+	compressMinSize = 1024
+)
+
+// RouteWeights is how much of the usage quota heavier routes consume; others weigh 1
+var RouteWeights = map[string]int64{
+	"GET /orderbook":                   5,
+	"GET /orderbook/depth":             2,
+	"GET /candles":                     2,
+	"POST /orderbook/snapshot":         10,
+	"POST /orderbook/restore":          10,
+	"GET /orderbook/snapshots/content": 5,
+	"POST /orderbook/snapshots/export": 10,
+	"POST /orderbook/snapshots/import": 10,
+	"POST /orders/cancel_batch":        5,
+	"POST /orders/modify_batch":        5,
+	"POST /orders/cancel_side":         5,
+	"POST /orders/cancel_all":          5,
+	"GET /orders":                      2,
+	"POST /orders/groups":              5,
+	"POST /orders/groups/cancel":       5,
+	"POST /sessions/cancel_all":        5,
+	"POST /orders/preview":             2,
+	"GET /trades/recent":               2,
+	"GET /trades":                      2,
+	"GET /orders/queue_position":       2,
+	"POST /admin/reports/daily":        10,
+	"GET /statements":                  5,
+	"GET /metrics/execution":           2,
+	"GET /admin/orderbook/dump":        5,
+	"GET /admin/orderbook/replay":      10,
+	"POST /ticks/jobs":                 10,
+	"GET /ticks/jobs/:id/download":     10,
+}
+
+// RecordedRoutes are the order-entry routes written to HTTPServer.Recorder
+var RecordedRoutes = map[string]bool{
+	"POST /orders":               true,
+	"POST /orders/modify":        true,
+	"POST /orders/modify_batch":  true,
+	"POST /orders/cancel":        true,
+	"POST /orders/cancel_batch":  true,
+	"POST /orders/cancel_side":   true,
+	"POST /orders/cancel_all":    true,
+	"POST /orders/groups":        true,
+	"POST /orders/groups/cancel": true,
+}
+
 type HTTPServer struct {
-	Eng         *core.Engine
-	submittedID sync.Map // for deduplication by OrderID
+	Eng          core.Exchange
+	Usage        *middleware.UsageTracker
+	Keys         *auth.KeyStore
+	Recorder     *record.Recorder     // nil records nothing
+	PoolStats    func() pg.PoolStats  // nil when orders are not stored in Postgres
+	CacheBreaker func() breaker.Stats // nil when the book cache has no circuit breaker
+	// RateLimit is the shortest time between two requests of a client
+	RateLimit time.Duration
+	// MaxBatchSize bounds the orders of a batch submit or cancel
+	MaxBatchSize int
+	submittedID  sync.Map // for deduplication by OrderID
 }
 
-func NewHTTPServer(eng *core.Engine) *HTTPServer {
-	return &HTTPServer{Eng: eng}
+func NewHTTPServer(eng core.Exchange) *HTTPServer {
+	return &HTTPServer{
+		Eng:          eng,
+		Usage:        middleware.NewUsageTracker(middleware.Quota{}, RouteWeights),
+		Keys:         auth.NewKeyStore(nil, auth.RoleTrader),
+		RateLimit:    100 * time.Millisecond,
+		MaxBatchSize: maxBatchSize,
+	}
 }
 
 func (s *HTTPServer) Run(addr string) error {
+	return s.Handler().Run(addr)
+}
+
+// Handler returns the routes with their middleware, for serving from an http.Server of the caller's
+func (s *HTTPServer) Handler() *gin.Engine {
 	r := gin.Default()
+	r.GET("/readyz", s.getReadiness)
+	r.Use(s.requireReady)
+	r.Use(middleware.Compress(compressMinSize))
 
-	rl := middleware.NewRateLimiter(time.Millisecond * 100)
+	rl := middleware.NewRateLimiter(s.RateLimit)
 	r.Use(rl.Middleware())
+	r.Use(middleware.Authenticate(s.Keys))
+	r.Use(s.Usage.Middleware())
+	if s.Recorder != nil {
+		r.Use(middleware.Record(s.Recorder, RecordedRoutes))
+	}
+
+	read := middleware.RequireRole(auth.ReadRoles...)
+	trade := middleware.RequireRole(auth.TradeRoles...)
+	admin := middleware.RequireRole(auth.AdminRoles...)
+	compliance := middleware.RequireRole(auth.ComplianceRoles...)
+
+	r.POST("/orders", trade, s.submitOrder)
+	r.GET("/orders", read, s.listOrders)
+	r.POST("/orders/preview", read, s.previewOrder)
+	r.POST("/orders/modify", trade, s.modifyOrder)
+	r.POST("/orders/modify_batch", trade, s.batchModifyOrders)
+	r.POST("/orders/cancel", trade, s.cancelOrder)
+	r.POST("/orders/cancel_batch", trade, s.batchCancelOrders)
+	r.POST("/orders/cancel_side", trade, s.cancelBySide)
+	r.POST("/orders/cancel_all", trade, s.cancelAllOrders)
+	r.POST("/orders/groups", trade, s.submitOrderGroup)
+	r.POST("/orders/groups/cancel", trade, s.cancelOrderGroup)
+	r.GET("/orders/queue_position", read, s.getQueuePosition)
+	r.GET("/orders/:id/history", read, s.getOrderHistory)
+	r.GET("/orders/by-client-id/:clOrdID", read, s.getOrderByClientOrderID)
+	r.POST("/sessions/login", trade, s.login)
+	r.POST("/sessions/heartbeat", trade, s.heartbeat)
+	r.POST("/sessions/logout", trade, s.logout)
+	r.POST("/sessions/cancel_all", trade, s.cancelSessionOrders)
+	r.GET("/orderbook", read, s.getOrderbook)
+	r.GET("/orderbook/depth", read, s.getDepth)
+	r.GET("/candles", read, s.getCandles)
+	r.GET("/quote", read, s.getQuote)
+	r.GET("/trades", read, s.getTrades)
+	r.GET("/trades/recent", read, s.getRecentTrades)
+	r.GET("/trades/:id", read, s.getTrade)
+	r.GET("/usage", read, s.Usage.UsageHandler)
+	r.GET("/notifications/preferences", read, s.getNotificationPreferences)
+	r.POST("/notifications/preferences", trade, s.setNotificationPreference)
+	r.POST("/notifications/preferences/delete", trade, s.deleteNotificationPreference)
+	r.GET("/statements", read, s.getStatement)
+	r.GET("/metrics/execution", read, s.getExecutionStats)
+	r.GET("/symbols/delistings", read, s.getDelistings)
+	r.GET("/instruments", read, s.getInstruments)
+	r.POST("/ticks/jobs", read, s.requestTickData)
+	r.GET("/ticks/jobs/:id", read, s.getTickDataJob)
+	r.GET("/ticks/jobs/:id/download", read, s.downloadTickData)
+	r.GET("/sandbox/balances", read, s.getSandboxBalances)
+	r.POST("/sandbox/reset", trade, s.resetSandboxBalances)
+	r.GET("/accounts/balances", read, s.getBalances)
 
-	r.POST("/orders", s.submitOrder)
-	r.POST("/orders/modify", s.modifyOrder)
-	r.POST("/orders/cancel", s.cancelOrder)
-	r.GET("/orderbook", s.getOrderbook)
-	r.POST("/orderbook/snapshot", s.snapshotOrderbook)
-	r.POST("/orderbook/restore", s.restoreOrderbook)
+	r.POST("/orderbook/snapshot", admin, s.snapshotOrderbook)
+	r.POST("/orderbook/restore", admin, s.restoreOrderbook)
+	r.GET("/orderbook/snapshots", admin, s.listSnapshots)
+	r.GET("/orderbook/snapshots/meta", admin, s.getSnapshotMeta)
+	r.GET("/orderbook/snapshots/content", admin, s.getSnapshot)
+	r.POST("/orderbook/snapshots/delete", admin, s.deleteSnapshot)
+	r.POST("/orderbook/snapshots/prune", admin, s.pruneSnapshots)
+	r.POST("/orderbook/snapshots/export", admin, s.exportSnapshot)
+	r.POST("/orderbook/snapshots/import", admin, s.importSnapshot)
+	r.GET("/orderbook/snapshots/exports", admin, s.listSnapshotExports)
+	r.POST("/admin/orders/cancel", admin, s.forceCancelOrder)
+	r.GET("/admin/shards", admin, s.getWorkerAssignments)
+	r.POST("/admin/shards/isolate", admin, s.isolateSymbol)
+	r.POST("/admin/shards/release", admin, s.releaseSymbol)
+	r.GET("/admin/streams", admin, s.getStreamStats)
+	r.GET("/admin/streams/:stream/topics", admin, s.getStreamTopicStats)
+	r.GET("/admin/db/pool", admin, s.getPoolStats)
+	r.GET("/admin/cache/breaker", admin, s.getCacheBreaker)
+	r.GET("/admin/orderbook/dump", admin, s.dumpOrderbook)
+	r.GET("/admin/orderbook/replay", admin, s.replayOrderbook)
+	r.POST("/admin/retention", admin, s.applyRetention)
+	r.POST("/admin/accounts/deposit", admin, s.deposit)
+	r.POST("/admin/accounts/withdraw", admin, s.withdraw)
+	r.GET("/admin/cancel_only", admin, s.getCancelOnly)
+	r.POST("/admin/cancel_only", admin, s.setCancelOnly)
+	r.POST("/admin/symbols/delist", admin, s.delistSymbol)
+	r.POST("/admin/symbols/delist/withdraw", admin, s.withdrawDelisting)
+	r.POST("/admin/instruments", admin, s.saveInstrument)
+	r.POST("/admin/reports/daily", admin, s.generateDailyReport)
+	r.GET("/admin/reports/daily", admin, s.getDailyReport)
+	r.GET("/export/orders", compliance, s.exportOrders)
+	r.GET("/export/trades", compliance, s.exportTrades)
+	r.GET("/compliance/alerts", compliance, s.listAlerts)
+	r.POST("/compliance/alerts/review", compliance, s.reviewAlert)
 
-	return r.Run(addr)
+	return r
 }
 
 func (s *HTTPServer) submitOrder(c *gin.Context) {
@@ -46,16 +208,25 @@ func (s *HTTPServer) submitOrder(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+
+	if req.ValidateOnly {
+		s.validateOnly(c, &req)
+		return
+	}
 
 	if err := ValidateOrder(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "reject_code": domain.RejectCodeOf(err)})
 		return
 	}
 
 	// deduplication
 	if req.OrderID != "" {
 		if _, exists := s.submittedID.LoadOrStore(req.OrderID, struct{}{}); exists {
-			c.JSON(http.StatusOK, gin.H{"message": "duplicate order", "order_id": req.OrderID})
+			c.JSON(http.StatusOK, gin.H{"message": "duplicate order", "order_id": req.OrderID,
+				"reject_code": domain.RejectDuplicateClientOrderID})
 			return
 		}
 	}
@@ -65,26 +236,110 @@ func (s *HTTPServer) submitOrder(c *gin.Context) {
 		orderID = uuid.NewString()
 	}
 
+	sessionID, ok := s.orderSession(c, req.ClientID)
+	if !ok {
+		return
+	}
 	o := &domain.Order{
-		ID:       req.OrderID,
-		ClientID: req.ClientID,
-		Symbol:   req.Symbol,
-		Side:     domain.Side(req.Side),
-		Type:     domain.OrderType(req.Type),
-		Price:    req.Price,
-		Quantity: req.Quantity,
+		ID:            req.OrderID,
+		ClientID:      req.ClientID,
+		ClientOrderID: req.ClientOrderID,
+		SessionID:     sessionID,
+		Symbol:        req.Symbol,
+		Side:          domain.Side(req.Side),
+		Type:          domain.OrderType(req.Type),
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		TriggerPrice:  req.TriggerPrice,
+		Tags:          req.Tags,
+		UserData:      req.UserData,
 	}
 
-	trades, err := s.Eng.SubmitOrder(c, o)
+	trades, err := s.Eng.SubmitOrder(c.Request.Context(), o)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		orderError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.SubmitOrderResponse{
-		OrderID:   o.ID,
-		Trades:    convertTrades(trades),
-		Remaining: o.Remaining,
+		OrderID:       o.ID,
+		ClientOrderID: o.ClientOrderID,
+		Trades:        s.convertTrades(trades),
+		Remaining:     view.PrecisionOf(s.Eng, o.Symbol).FormatQuantity(o.Remaining),
+		Accepted:      true,
+		UserData:      o.UserData,
+	})
+}
+
+// validateOnly answers a dry-run submission: nothing is persisted, matched or deduplicated
+func (s *HTTPServer) validateOnly(c *gin.Context, req *dto.SubmitOrderRequest) {
+	err := ValidateOrder(req)
+	if err == nil {
+		err = s.Eng.ValidateOrder(c.Request.Context(), &domain.Order{
+			ID:            req.OrderID,
+			ClientID:      req.ClientID,
+			ClientOrderID: req.ClientOrderID,
+			Symbol:        req.Symbol,
+			Side:          domain.Side(req.Side),
+			Type:          domain.OrderType(req.Type),
+			Price:         req.Price,
+			Quantity:      req.Quantity,
+			TriggerPrice:  req.TriggerPrice,
+			Tags:          req.Tags,
+			UserData:      req.UserData,
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusOK, dto.SubmitOrderResponse{OrderID: req.OrderID, RejectReason: err.Error(),
+			RejectCode: string(domain.RejectCodeOf(err)), UserData: req.UserData})
+		return
+	}
+	c.JSON(http.StatusOK, dto.SubmitOrderResponse{OrderID: req.OrderID, Accepted: true, UserData: req.UserData})
+}
+
+func (s *HTTPServer) previewOrder(c *gin.Context) {
+	var req dto.SubmitOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	if err := ValidateOrder(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := s.Eng.PreviewOrder(c.Request.Context(), &domain.Order{
+		ClientID:     req.ClientID,
+		Symbol:       req.Symbol,
+		Side:         domain.Side(req.Side),
+		Type:         domain.OrderType(req.Type),
+		Price:        req.Price,
+		Quantity:     req.Quantity,
+		TriggerPrice: req.TriggerPrice,
+		Tags:         req.Tags,
+		UserData:     req.UserData,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fills := make([]dto.PreviewFill, len(p.Fills))
+	for i, f := range p.Fills {
+		fills[i] = dto.PreviewFill{Price: f.Price, Quantity: f.Quantity}
+	}
+	c.JSON(http.StatusOK, dto.PreviewOrderResponse{
+		Symbol:       p.Symbol,
+		Side:         dto.Side(p.Side),
+		Fills:        fills,
+		Filled:       p.Filled,
+		Remaining:    p.Remaining,
+		Notional:     p.Notional,
+		AvgPrice:     p.AvgPrice,
+		BestPrice:    p.BestPrice,
+		Slippage:     p.Slippage,
+		EstimatedFee: p.EstimatedFee,
 	})
 }
 
@@ -94,23 +349,232 @@ func (s *HTTPServer) modifyOrder(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := s.Eng.ModifyOrder(c, req.OrderID, req.ClientID, req.NewPrice, req.NewQty); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	orderID, ok := s.orderIDOf(c, req.ClientID, req.OrderID, req.ClientOrderID)
+	if !ok {
+		return
+	}
+	trades, err := s.Eng.ModifyOrder(c.Request.Context(), orderID, req.ClientID, req.NewPrice, req.NewQty)
+	if err != nil {
+		orderError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, dto.ModifyOrderResponse{
-		OrderID:  req.OrderID,
+		OrderID:  orderID,
 		Modified: true,
+		Trades:   s.convertTrades(trades),
 	})
 }
 
+func (s *HTTPServer) batchModifyOrders(c *gin.Context) {
+	var req dto.BatchModifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	if len(req.Orders) > s.MaxBatchSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("batch too large: %d > %d", len(req.Orders), s.MaxBatchSize)})
+		return
+	}
+	amends := make([]core.Amend, len(req.Orders))
+	for i, a := range req.Orders {
+		amends[i] = core.Amend{OrderID: a.OrderID, Price: a.NewPrice, Quantity: a.NewQty}
+	}
+	results, err := s.Eng.BatchModifyOrders(c.Request.Context(), req.ClientID, amends)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := dto.BatchModifyResponse{Results: make([]dto.ModifyOrderResponse, len(results))}
+	for i, r := range results {
+		resp.Results[i] = dto.ModifyOrderResponse{OrderID: r.OrderID, Modified: r.Modified, Trades: s.convertTrades(r.Trades)}
+		if r.Err != nil {
+			resp.Results[i].Message = r.Err.Error()
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 func (s *HTTPServer) cancelOrder(c *gin.Context) {
 	var req dto.CancelOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	ok, err := s.Eng.CancelOrder(c, req.OrderID, req.ClientID)
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	orderID, ok := s.orderIDOf(c, req.ClientID, req.OrderID, req.ClientOrderID)
+	if !ok {
+		return
+	}
+	ok, err := s.Eng.CancelOrder(c.Request.Context(), orderID, req.ClientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.CancelOrderResponse{
+		OrderID:   orderID,
+		Cancelled: ok,
+	})
+}
+
+// orderIDOf returns the order a cancel or modify names: orderID, or else the client's order with
+// clientOrderID
+func (s *HTTPServer) orderIDOf(c *gin.Context, clientID, orderID, clientOrderID string) (string, bool) {
+	if orderID != "" {
+		return orderID, true
+	}
+	if clientOrderID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order_id or client_order_id is required"})
+		return "", false
+	}
+	o, err := s.Eng.GetOrderByClientOrderID(c.Request.Context(), clientID, clientOrderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return "", false
+	}
+	return o.ID, true
+}
+
+// getOrderByClientOrderID returns the client's order with the client order ID: the open one, or
+// else the latest
+func (s *HTTPServer) getOrderByClientOrderID(c *gin.Context) {
+	var req dto.OrderByClientOrderIDRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	o, err := s.Eng.GetOrderByClientOrderID(c.Request.Context(), req.ClientID, c.Param("clOrdID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+	c.JSON(http.StatusOK, s.convertOrder(o))
+}
+
+func (s *HTTPServer) batchCancelOrders(c *gin.Context) {
+	var req dto.BatchCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	n := len(req.OrderIDs) + len(req.ClientOrderIDs)
+	if n == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order_ids or client_order_ids is required"})
+		return
+	}
+	if n > s.MaxBatchSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("batch too large: %d > %d", n, s.MaxBatchSize)})
+		return
+	}
+	results, err := s.Eng.BatchCancelOrders(c.Request.Context(), req.ClientID, req.OrderIDs, req.ClientOrderIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := dto.BatchCancelResponse{Results: make([]dto.CancelOrderResponse, len(results))}
+	for i, r := range results {
+		resp.Results[i] = dto.CancelOrderResponse{OrderID: r.OrderID, ClientOrderID: r.ClientOrderID, Cancelled: r.Cancelled}
+		if r.Err != nil {
+			resp.Results[i].Message = r.Err.Error()
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *HTTPServer) cancelBySide(c *gin.Context) {
+	var req dto.CancelBySideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	if req.Side != dto.Buy && req.Side != dto.Sell {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid side: %s", req.Side)})
+		return
+	}
+	ids, err := s.Eng.CancelBySide(c.Request.Context(), req.ClientID, req.Symbol, domain.Side(req.Side))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	c.JSON(http.StatusOK, dto.CancelBySideResponse{CancelledOrderIDs: ids})
+}
+
+func (s *HTTPServer) cancelAllOrders(c *gin.Context) {
+	var req dto.CancelAllRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	if req.Side != "" && req.Side != dto.Buy && req.Side != dto.Sell {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid side: %s", req.Side)})
+		return
+	}
+	ids, err := s.Eng.CancelAllOrders(c.Request.Context(), req.ClientID, req.Symbol, domain.Side(req.Side))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.CancelAllResponse{CancelledOrderIDs: ids})
+}
+
+func (s *HTTPServer) listOrders(c *gin.Context) {
+	var req dto.ListOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	status := domain.OrderStatus(req.Status)
+	switch status {
+	case "", domain.Pending, domain.Open, domain.PartiallyFilled, domain.Filled, domain.Cancelled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid status: %s", req.Status)})
+		return
+	}
+	f := domain.OrderFilter{ClientID: req.ClientID, Symbol: req.Symbol, Status: status}
+	orders, err := s.Eng.ListOrders(c.Request.Context(), f, page.Request{Cursor: req.Cursor, Limit: req.Limit}.Capped(maxOrders))
+	if err != nil {
+		pageError(c, err)
+		return
+	}
+	resp := dto.ListOrdersResponse{Orders: make([]dto.Order, len(orders.Items)), NextCursor: orders.NextCursor}
+	for i, o := range orders.Items {
+		resp.Orders[i] = s.convertOrder(o)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *HTTPServer) forceCancelOrder(c *gin.Context) {
+	var req dto.ForceCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ok, err := s.Eng.ForceCancelOrder(c.Request.Context(), req.OrderID, req.Reason)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -128,36 +592,239 @@ func (s *HTTPServer) getOrder(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
-	copySnapshot := ob.DeepCopy()
-	c.JSON(http.StatusOK, dto.GetOrderbookResponse{
-		Bids:      convertOrders(copySnapshot.Bids),
-		Asks:      convertOrders(copySnapshot.Asks),
+	copySnapshot := view.FromContext(c.Request.Context()).Book(ob)
+	respondFields(c, http.StatusOK, dto.GetOrderbookResponse{
+		Bids:      s.convertOrders(copySnapshot.Bids),
+		Asks:      s.convertOrders(copySnapshot.Asks),
 		Timestamp: copySnapshot.Timestamp,
 	})
 }
 
+func (s *HTTPServer) getQueuePosition(c *gin.Context) {
+	var req dto.GetQueuePositionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	qp, err := s.Eng.GetQueuePosition(c.Request.Context(), req.OrderID, req.ClientID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.GetQueuePositionResponse{
+		OrderID:       qp.OrderID,
+		Symbol:        qp.Symbol,
+		Side:          dto.Side(qp.Side),
+		Price:         qp.Price,
+		Position:      qp.Position,
+		OrdersAhead:   qp.OrdersAhead,
+		QuantityAhead: qp.QuantityAhead,
+		LevelOrders:   qp.LevelOrders,
+		LevelQuantity: qp.LevelQuantity,
+		Remaining:     qp.OrderRemaining,
+	})
+}
+
+func (s *HTTPServer) getOrderHistory(c *gin.Context) {
+	var req dto.OrderHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	ts, err := s.Eng.OrderHistory(c.Request.Context(), c.Param("id"), req.ClientID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	res := dto.OrderHistoryResponse{OrderID: c.Param("id"), Transitions: make([]dto.OrderTransition, len(ts))}
+	for i, tr := range ts {
+		res.Transitions[i] = dto.OrderTransition{
+			ExecType:  string(tr.ExecType),
+			Status:    string(tr.Status),
+			Cause:     string(tr.Cause),
+			Reason:    tr.Reason,
+			Remaining: tr.Remaining,
+			TradeID:   tr.TradeID,
+			At:        tr.At,
+			Sequence:  tr.Sequence,
+		}
+	}
+	c.JSON(http.StatusOK, res)
+}
+
 /*
 func (s *HTTPServer) getTrades(c *gin.Context) {
 	id := c.Param("id")
 	trades, _ := s.Eng.GetTradesForOrder(c.Request.Context(), id)
-	c.JSON(http.StatusOK, dto.GetTradesResponse{Trades: convertTrades(trades)})
+	c.JSON(http.StatusOK, dto.GetTradesResponse{Trades: s.convertTrades(trades)})
 }*/
 
+// readYourWrites is the X-Consistency header or consistency parameter value asking for a book that
+// reflects the caller's own completed order operations
+const readYourWrites = "read-your-writes"
+
 func (s *HTTPServer) getOrderbook(c *gin.Context) {
 	symbol := c.Query("symbol")
-	ob, err := s.Eng.GetOrderbook(c.Request.Context(), symbol)
+	get := s.Eng.GetOrderbook
+	if c.GetHeader("X-Consistency") == readYourWrites || c.Query("consistency") == readYourWrites {
+		get = s.Eng.GetOrderbookConsistent
+	}
+	ob, err := get(c.Request.Context(), symbol)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
-	copySnapshot := ob.DeepCopy()
-	c.JSON(http.StatusOK, dto.GetOrderbookResponse{
-		Bids:      convertOrders(copySnapshot.Bids),
-		Asks:      convertOrders(copySnapshot.Asks),
+	copySnapshot := view.FromContext(c.Request.Context()).Book(ob)
+	respondFields(c, http.StatusOK, dto.GetOrderbookResponse{
+		Bids:      s.convertOrders(copySnapshot.Bids),
+		Asks:      s.convertOrders(copySnapshot.Asks),
 		Timestamp: copySnapshot.Timestamp,
+		Sequence:  copySnapshot.Sequence,
+	})
+}
+
+// getDepth returns the book aggregated by price level, which carries no orders or clients and so
+// needs no view
+func (s *HTTPServer) getDepth(c *gin.Context) {
+	var req dto.GetDepthRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	step := decimal.Zero
+	if req.Step != "" {
+		var err error
+		if step, err = decimal.NewFromString(req.Step); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid step"})
+			return
+		}
+	}
+	if step.IsNegative() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "step must be >= 0"})
+		return
+	}
+	d, err := s.Eng.GetDepth(c.Request.Context(), req.Symbol, req.Levels, step)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.GetDepthResponse{
+		Symbol:    d.Symbol,
+		Step:      d.Step,
+		Bids:      depthLevels(d.Bids),
+		Asks:      depthLevels(d.Asks),
+		Timestamp: d.Timestamp,
+		Sequence:  d.Sequence,
 	})
 }
 
+func depthLevels(levels []domain.DepthLevel) []dto.DepthLevel {
+	out := make([]dto.DepthLevel, len(levels))
+	for i, l := range levels {
+		out[i] = dto.DepthLevel{Price: l.Price, Quantity: l.Quantity, Orders: l.Orders}
+	}
+	return out
+}
+
+func (s *HTTPServer) getCandles(c *gin.Context) {
+	var req dto.CandlesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	candles, err := s.Eng.GetCandles(c.Request.Context(), req.Symbol, domain.CandleInterval(req.Interval), req.From, req.To, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp := dto.CandlesResponse{Symbol: req.Symbol, Interval: req.Interval, Candles: make([]dto.Candle, len(candles))}
+	for i, b := range candles {
+		resp.Candles[i] = dto.Candle{
+			Start: b.Start, Open: b.Open, High: b.High, Low: b.Low, Close: b.Close,
+			Volume: b.Volume, Notional: b.Notional, Trades: b.Trades,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *HTTPServer) getQuote(c *gin.Context) {
+	symbol := c.Query("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol is required"})
+		return
+	}
+	q, err := s.Eng.GetQuote(c.Request.Context(), symbol)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.QuoteResponse{
+		Symbol:    q.Symbol,
+		BidPrice:  q.BidPrice,
+		BidQty:    q.BidQty,
+		AskPrice:  q.AskPrice,
+		AskQty:    q.AskQty,
+		Spread:    q.Spread,
+		Mid:       q.Mid,
+		Timestamp: q.Timestamp,
+	})
+}
+
+func (s *HTTPServer) getRecentTrades(c *gin.Context) {
+	var req dto.GetRecentTradesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pg := page.Request{Cursor: req.Cursor, Limit: req.Limit}.Capped(maxRecentTrades)
+	entries, err := s.Eng.ListTrades(c.Request.Context(), req.Symbol, pg)
+	if err != nil {
+		pageError(c, err)
+		return
+	}
+	viewer := view.FromContext(c.Request.Context())
+	trades := make([]*domain.Trade, len(entries.Items))
+	for i, e := range entries.Items {
+		trades[i] = viewer.Trade(e.Trade)
+	}
+	respondFields(c, http.StatusOK, dto.GetTradesResponse{Trades: s.convertTrades(trades), NextCursor: entries.NextCursor})
+}
+
+func (s *HTTPServer) getTrades(c *gin.Context) {
+	var req dto.SymbolTradesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+	trades, err := s.Eng.GetTrades(c.Request.Context(), req.Symbol, req.From, req.To, page.Request{Cursor: req.Cursor, Limit: req.Limit}.Capped(maxTrades))
+	if err != nil {
+		pageError(c, err)
+		return
+	}
+	items := view.FromContext(c.Request.Context()).Trades(trades.Items)
+	respondFields(c, http.StatusOK, dto.GetTradesResponse{Trades: s.convertTrades(items), NextCursor: trades.NextCursor})
+}
+
+func (s *HTTPServer) getTrade(c *gin.Context) {
+	t, err := s.Eng.GetTrade(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	t = view.FromContext(c.Request.Context()).Trade(t)
+	respondFields(c, http.StatusOK, s.convertTrades([]*domain.Trade{t})[0])
+}
+
 func (s *HTTPServer) snapshotOrderbook(c *gin.Context) {
 	var req dto.SnapshotRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -186,39 +853,341 @@ func (s *HTTPServer) restoreOrderbook(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.RestoreResponse{Ok: ok})
 }
 
-func convertOrder(o *domain.Order) dto.Order {
-	return dto.Order{
-		ID:        o.ID,
-		ClientID:  o.ClientID,
-		Symbol:    o.Symbol,
-		Side:      dto.Side(o.Side),
-		Type:      dto.OrderType(o.Type),
-		Price:     o.Price,
-		Quantity:  o.Quantity,
-		Remaining: o.Remaining,
-		Status:    string(o.Status),
-		CreatedAt: o.CreatedAt,
+func (s *HTTPServer) listSnapshots(c *gin.Context) {
+	var req dto.ListSnapshotsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pg := page.Request{Cursor: req.Cursor, Limit: req.Limit}.Capped(maxSnapshots)
+	metas, err := s.Eng.ListSnapshots(c.Request.Context(), req.Symbol, pg)
+	if err != nil {
+		pageError(c, err)
+		return
+	}
+	resp := dto.ListSnapshotsResponse{Snapshots: make([]dto.SnapshotMeta, len(metas.Items)), NextCursor: metas.NextCursor}
+	for i := range metas.Items {
+		resp.Snapshots[i] = convertSnapshotMeta(&metas.Items[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *HTTPServer) getSnapshotMeta(c *gin.Context) {
+	id := c.Query("snapshot_id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "snapshot_id is required"})
+		return
+	}
+	meta, err := s.Eng.GetSnapshotMeta(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, convertSnapshotMeta(meta))
+}
+
+func (s *HTTPServer) getSnapshot(c *gin.Context) {
+	id := c.Query("snapshot_id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "snapshot_id is required"})
+		return
+	}
+	ob, err := s.Eng.GetSnapshot(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	resp := dto.GetSnapshotResponse{
+		SnapshotMeta: dto.SnapshotMeta{SnapshotID: id, Symbol: ob.Symbol},
+		Bids:         s.convertOrders(ob.Bids),
+		Asks:         s.convertOrders(ob.Asks),
+	}
+	if meta, err := s.Eng.GetSnapshotMeta(c.Request.Context(), id); err == nil {
+		resp.SnapshotMeta = convertSnapshotMeta(meta)
+	}
+	respondFields(c, http.StatusOK, resp)
+}
+
+func (s *HTTPServer) deleteSnapshot(c *gin.Context) {
+	var req dto.DeleteSnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.Eng.DeleteSnapshot(c.Request.Context(), req.SnapshotID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": true})
+}
+
+func (s *HTTPServer) pruneSnapshots(c *gin.Context) {
+	var req dto.PruneSnapshotsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	n, err := s.Eng.PruneSnapshots(c.Request.Context(), req.Symbol, req.OlderThan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.PruneSnapshotsResponse{Deleted: n})
+}
+
+// applyRetention runs the tenant's archive and purge policy now instead of waiting for the next cycle
+func (s *HTTPServer) applyRetention(c *gin.Context) {
+	res, err := s.Eng.ApplyRetention(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.RetentionResponse{Archived: res.Archived, PurgedOrders: res.PurgedOrders, PurgedTrades: res.PurgedTrades})
+}
+
+func (s *HTTPServer) setCancelOnly(c *gin.Context) {
+	var req dto.CancelOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	s.Eng.SetCancelOnly(c.Request.Context(), req.Symbol, req.Enabled, req.Reason)
+	s.getCancelOnly(c)
+}
+
+func (s *HTTPServer) getCancelOnly(c *gin.Context) {
+	modes := s.Eng.CancelOnlyModes(c.Request.Context())
+	out := make([]dto.CancelOnly, 0, len(modes))
+	for _, m := range modes {
+		out = append(out, dto.CancelOnly{Symbol: m.Symbol, Reason: m.Reason, Since: m.Since})
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func (s *HTTPServer) exportSnapshot(c *gin.Context) {
+	var req dto.ExportSnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	key, err := s.Eng.ExportSnapshot(c.Request.Context(), req.SnapshotID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ExportSnapshotResponse{Key: key})
+}
+
+func (s *HTTPServer) importSnapshot(c *gin.Context) {
+	var req dto.ImportSnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	id, err := s.Eng.ImportSnapshot(c.Request.Context(), req.Key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.SnapshotResponse{SnapshotID: id})
+}
+
+func (s *HTTPServer) listSnapshotExports(c *gin.Context) {
+	var req dto.ListSnapshotExportsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pg := page.Request{Cursor: req.Cursor, Limit: req.Limit}.Capped(maxSnapshots)
+	objs, err := s.Eng.ListSnapshotExports(c.Request.Context(), req.Symbol, pg)
+	if err != nil {
+		pageError(c, err)
+		return
+	}
+	resp := dto.ListSnapshotExportsResponse{Exports: make([]dto.StoredObject, len(objs.Items)), NextCursor: objs.NextCursor}
+	for i, o := range objs.Items {
+		resp.Exports[i] = dto.StoredObject{Key: o.Key, Size: o.Size, LastModified: o.LastModified}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// orderError maps a failed submit or modify; a rejection is the client's error and carries its code,
+// except cancel-only, which is worth retrying later
+func orderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, core.ErrCancelOnly):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "reject_code": domain.RejectCodeOf(err)})
+	case domain.IsReject(err):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "reject_code": domain.RejectCodeOf(err)})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
 }
 
-func convertOrders(orders []domain.Order) []dto.Order {
+// pageError answers a list request, blaming the client for a bad cursor
+func pageError(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	if errors.Is(err, page.ErrInvalidCursor) {
+		code = http.StatusBadRequest
+	}
+	c.JSON(code, gin.H{"error": err.Error()})
+}
+
+// actingClient replaces the request's client_id with the client the caller acts for, see
+// auth.ClientID, and answers the request itself if the caller may not act for it
+func actingClient(c *gin.Context, clientID *string) bool {
+	id, err := auth.ClientID(c.Request.Context(), *clientID)
+	switch {
+	case errors.Is(err, auth.ErrClientRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	case err != nil:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return false
+	}
+	*clientID = id
+	return true
+}
+
+func convertSnapshotMeta(m *domain.SnapshotMeta) dto.SnapshotMeta {
+	return dto.SnapshotMeta{
+		SnapshotID: m.ID,
+		Symbol:     m.Symbol,
+		Sequence:   m.Sequence,
+		CreatedAt:  m.CreatedAt,
+		ExpiresAt:  m.ExpiresAt,
+		Bids:       m.Bids,
+		Asks:       m.Asks,
+		Bytes:      m.Bytes,
+	}
+}
+
+func (s *HTTPServer) getNotificationPreferences(c *gin.Context) {
+	clientID := c.Query("client_id")
+	if !actingClient(c, &clientID) {
+		return
+	}
+	prefs, err := s.Eng.GetNotificationPreferences(c.Request.Context(), clientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := dto.NotificationPreferencesResponse{Preferences: make([]dto.NotificationPreference, len(prefs))}
+	for i, p := range prefs {
+		types := make([]string, len(p.ExecTypes))
+		for j, t := range p.ExecTypes {
+			types[j] = string(t)
+		}
+		resp.Preferences[i] = dto.NotificationPreference{
+			ClientID:  p.ClientID,
+			Channel:   string(p.Channel),
+			Target:    p.Target,
+			ExecTypes: types,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *HTTPServer) setNotificationPreference(c *gin.Context) {
+	var req dto.NotificationPreference
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	if err := s.Eng.SetNotificationPreference(c.Request.Context(), convertPreference(req)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+func (s *HTTPServer) deleteNotificationPreference(c *gin.Context) {
+	var req dto.NotificationPreference
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	if err := s.Eng.DeleteNotificationPreference(c.Request.Context(), convertPreference(req)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": true})
+}
+
+func convertPreference(p dto.NotificationPreference) domain.NotificationPreference {
+	types := make([]domain.ExecType, len(p.ExecTypes))
+	for i, t := range p.ExecTypes {
+		types[i] = domain.ExecType(t)
+	}
+	return domain.NotificationPreference{
+		ClientID:  p.ClientID,
+		Channel:   domain.NotificationChannel(p.Channel),
+		Target:    p.Target,
+		ExecTypes: types,
+	}
+}
+
+func (s *HTTPServer) convertOrder(o *domain.Order) dto.Order {
+	prec := view.PrecisionOf(s.Eng, o.Symbol)
+	out := dto.Order{
+		ID:            o.ID,
+		ClientID:      o.ClientID,
+		ClientOrderID: o.ClientOrderID,
+		Symbol:        o.Symbol,
+		Side:          dto.Side(o.Side),
+		Type:          dto.OrderType(o.Type),
+		Price:         prec.FormatPrice(o.Price),
+		Quantity:      prec.FormatQuantity(o.Quantity),
+		Remaining:     prec.FormatQuantity(o.Remaining),
+		Status:        string(o.Status),
+		SessionID:     o.SessionID,
+		Tags:          o.Tags,
+		UserData:      o.UserData,
+		GroupID:       o.GroupID,
+		CreatedAt:     o.CreatedAt,
+		UpdatedAt:     o.UpdatedAt,
+	}
+	if o.Type.IsStop() {
+		out.TriggerPrice = prec.FormatPrice(o.TriggerPrice)
+	}
+	return out
+}
+
+func (s *HTTPServer) convertOrders(orders []domain.Order) []dto.Order {
 	res := make([]dto.Order, len(orders))
 	for i := range orders {
-		res[i] = convertOrder(&orders[i])
+		res[i] = s.convertOrder(&orders[i])
 	}
 	return res
 }
 
-func convertTrades(trades []*domain.Trade) []dto.Trade {
+func (s *HTTPServer) convertTrades(trades []*domain.Trade) []dto.Trade {
 	res := make([]dto.Trade, len(trades))
 	for i, t := range trades {
+		prec := view.PrecisionOf(s.Eng, t.Symbol)
 		res[i] = dto.Trade{
-			ID:        t.ID,
-			BuyOrder:  t.BuyOrder,
-			SellOrder: t.SellOrder,
-			Price:     t.Price,
-			Quantity:  t.Quantity,
-			Timestamp: t.Timestamp,
+			ID:            t.ID,
+			Symbol:        t.Symbol,
+			BuyOrder:      t.BuyOrder,
+			SellOrder:     t.SellOrder,
+			Price:         prec.FormatPrice(t.Price),
+			Quantity:      prec.FormatQuantity(t.Quantity),
+			Timestamp:     t.Timestamp,
+			MakerFee:      t.MakerFee,
+			TakerFee:      t.TakerFee,
+			Sequence:      t.Sequence,
+			MakerFeeAsset: t.MakerFeeAsset,
+			TakerFeeAsset: t.TakerFeeAsset,
+			TakerSide:     string(t.TakerSide),
+			TakerType:     string(t.TakerType),
+			BuyLiquidity:  string(t.BuyLiquidity),
+			SellLiquidity: string(t.SellLiquidity),
 		}
 	}
 	return res
@@ -228,18 +1197,179 @@ func ValidateOrder(req *dto.SubmitOrderRequest) error {
 	switch req.Side {
 	case dto.Buy, dto.Sell:
 	default:
-		return fmt.Errorf("invalid side: %s", req.Side)
+		return domain.Reject(domain.RejectInvalidOrder, "invalid side: %s", req.Side)
 	}
 	switch req.Type {
-	case dto.Limit, dto.Market:
+	case dto.Limit, dto.Market, dto.Stop, dto.StopLimit:
 	default:
-		return fmt.Errorf("invalid order type: %s", req.Type)
+		return domain.Reject(domain.RejectInvalidOrder, "invalid order type: %s", req.Type)
 	}
 	if req.Quantity.LessThanOrEqual(decimal.Zero) {
-		return fmt.Errorf("quantity must be > 0")
+		return domain.Reject(domain.RejectInvalidQuantity, "quantity must be > 0")
+	}
+	if (req.Type == dto.Limit || req.Type == dto.StopLimit) && req.Price.LessThanOrEqual(decimal.Zero) {
+		return domain.Reject(domain.RejectInvalidPrice, "price must be > 0 for %s orders", req.Type)
+	}
+	if (req.Type == dto.Stop || req.Type == dto.StopLimit) && req.TriggerPrice.LessThanOrEqual(decimal.Zero) {
+		return domain.Reject(domain.RejectInvalidPrice, "trigger_price must be > 0 for %s orders", req.Type)
+	}
+	if err := domain.ValidateTags(req.Tags); err != nil {
+		return err
+	}
+	if err := domain.ValidateClientOrderID(req.ClientOrderID); err != nil {
+		return err
+	}
+	return domain.ValidateUserData(req.UserData)
+}
+
+func (s *HTTPServer) getSandboxBalances(c *gin.Context) {
+	clientID := c.Query("client_id")
+	if !actingClient(c, &clientID) {
+		return
+	}
+	balances, err := s.Eng.SandboxBalances(c.Request.Context(), clientID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.SandboxBalancesResponse{ClientID: clientID, Balances: balances})
+}
+
+func (s *HTTPServer) resetSandboxBalances(c *gin.Context) {
+	var req dto.SandboxResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	if err := s.Eng.ResetSandboxBalances(c.Request.Context(), req.ClientID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reset": true})
+}
+
+func (s *HTTPServer) getWorkerAssignments(c *gin.Context) {
+	workers, err := s.Eng.WorkerAssignments()
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	resp := dto.WorkerAssignmentsResponse{Workers: make([]dto.WorkerAssignment, len(workers))}
+	for i, w := range workers {
+		a := dto.WorkerAssignment{Worker: w.Worker, Dedicated: w.Dedicated, Queued: w.Queued, Symbols: []dto.SymbolLoad{}}
+		for _, l := range w.Symbols {
+			a.Symbols = append(a.Symbols, dto.SymbolLoad{Symbol: l.Symbol, Ops: l.Ops})
+		}
+		resp.Workers[i] = a
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *HTTPServer) isolateSymbol(c *gin.Context) {
+	var req dto.SymbolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	w, err := s.Eng.IsolateSymbol(c.Request.Context(), req.Symbol)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
 	}
-	if req.Type == dto.Limit && req.Price.LessThanOrEqual(decimal.Zero) {
-		return fmt.Errorf("price must be > 0 for LIMIT orders")
+	c.JSON(http.StatusOK, dto.IsolateSymbolResponse{Symbol: req.Symbol, Worker: w})
+}
+
+func (s *HTTPServer) releaseSymbol(c *gin.Context) {
+	var req dto.SymbolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	return nil
+	if err := s.Eng.ReleaseSymbol(c.Request.Context(), req.Symbol); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"released": true})
+}
+
+func (s *HTTPServer) getStreamStats(c *gin.Context) {
+	resp := make(map[string]dto.StreamStats)
+	for name, st := range s.Eng.StreamStats() {
+		resp[name] = dto.StreamStats{
+			Topics:       st.Topics,
+			Subscribers:  st.Subscribers,
+			Queued:       st.Queued,
+			Published:    st.Published,
+			Dropped:      st.Dropped,
+			Disconnected: st.Disconnected,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *HTTPServer) getStreamTopicStats(c *gin.Context) {
+	stats, ok := s.Eng.StreamTopicStats(c.Param("stream"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown stream " + c.Param("stream")})
+		return
+	}
+	resp := make([]dto.StreamTopicStats, len(stats))
+	for i, st := range stats {
+		resp[i] = dto.StreamTopicStats{
+			Topic:       st.Topic,
+			Subscribers: st.Subscribers,
+			Queued:      st.Queued,
+			Published:   st.Published,
+			Delivered:   st.Delivered,
+			Dropped:     st.Dropped,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// getPoolStats reports the database connection pool: connections in use and idle, and how long
+// acquires waited for one
+func (s *HTTPServer) getPoolStats(c *gin.Context) {
+	if s.PoolStats == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "storage has no connection pool"})
+		return
+	}
+	st := s.PoolStats()
+	c.JSON(http.StatusOK, dto.PoolStats{
+		MaxConns:          st.MaxConns,
+		TotalConns:        st.TotalConns,
+		InUseConns:        st.InUseConns,
+		IdleConns:         st.IdleConns,
+		ConstructingConns: st.Constructing,
+		Acquires:          st.Acquires,
+		AcquireTime:       st.AcquireTime.String(),
+		Waits:             st.WaitedFor,
+		WaitTime:          st.WaitTime.String(),
+		AvgWait:           st.AvgWait().String(),
+		CancelledAcquires: st.Cancelled,
+		NewConns:          st.NewConns,
+		ExpiredConns:      st.ExpiredConns,
+		IdleClosedConns:   st.IdleClosed,
+	})
+}
+
+// getCacheBreaker reports the book cache's circuit breaker: its state since when, and how often it
+// tripped and turned calls away
+func (s *HTTPServer) getCacheBreaker(c *gin.Context) {
+	if s.CacheBreaker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "the book cache has no circuit breaker"})
+		return
+	}
+	st := s.CacheBreaker()
+	c.JSON(http.StatusOK, dto.CacheBreaker{
+		State:               string(st.State),
+		Since:               st.Since.UTC(),
+		ConsecutiveFailures: st.ConsecutiveFailures,
+		Failures:            st.Failures,
+		Trips:               st.Trips,
+		Rejected:            st.Rejected,
+	})
 }
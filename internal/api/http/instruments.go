@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+func (s *HTTPServer) saveInstrument(c *gin.Context) {
+	var req dto.SaveInstrumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	in, err := s.Eng.SaveInstrument(c.Request.Context(), domain.Instrument{
+		Symbol: req.Symbol, BaseAsset: req.BaseAsset, QuoteAsset: req.QuoteAsset, TickSize: req.TickSize,
+		LotSize: req.LotSize, MinNotional: req.MinNotional, Status: domain.InstrumentStatus(req.Status),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, convertInstrument(in))
+}
+
+func (s *HTTPServer) getInstruments(c *gin.Context) {
+	list, err := s.Eng.Instruments(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]dto.Instrument, 0, len(list))
+	for _, in := range list {
+		out = append(out, convertInstrument(in))
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func convertInstrument(in domain.Instrument) dto.Instrument {
+	return dto.Instrument{
+		Symbol:      in.Symbol,
+		BaseAsset:   in.BaseAsset,
+		QuoteAsset:  in.QuoteAsset,
+		TickSize:    in.TickSize,
+		LotSize:     in.LotSize,
+		MinNotional: in.MinNotional,
+		Status:      string(in.Status),
+		UpdatedAt:   in.UpdatedAt,
+	}
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// ndjsonFlushEvery is how many lines are written between flushes of a bulk export
+const ndjsonFlushEvery = 500
+
+// ndjsonWriter writes one JSON value per line. Writes block while the client is not reading,
+// which in turn stops the database scan feeding it.
+type ndjsonWriter struct {
+	c     *gin.Context
+	enc   *json.Encoder
+	lines int
+}
+
+func newNDJSONWriter(c *gin.Context) *ndjsonWriter {
+	return &ndjsonWriter{c: c, enc: json.NewEncoder(c.Writer)}
+}
+
+func (w *ndjsonWriter) write(v any) error {
+	if w.lines == 0 {
+		w.c.Header("Content-Type", "application/x-ndjson")
+		w.c.Status(http.StatusOK)
+	}
+	if err := w.enc.Encode(v); err != nil {
+		return err
+	}
+	w.lines++
+	if w.lines%ndjsonFlushEvery == 0 {
+		w.c.Writer.Flush()
+	}
+	return w.c.Request.Context().Err()
+}
+
+// finish ends the export; an error after the first line can only be reported as a last line
+func (w *ndjsonWriter) finish(err error) {
+	switch {
+	case err != nil && w.lines == 0:
+		w.c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	case err != nil:
+		_ = w.enc.Encode(gin.H{"error": err.Error()})
+	case w.lines == 0:
+		w.c.Header("Content-Type", "application/x-ndjson")
+		w.c.Status(http.StatusOK)
+	}
+	w.c.Writer.Flush()
+}
+
+func exportFilter(c *gin.Context) (domain.ExportFilter, bool) {
+	var req dto.ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return domain.ExportFilter{}, false
+	}
+	return domain.ExportFilter{Symbol: req.Symbol, From: req.From, To: req.To, Tag: req.Tag}, true
+}
+
+func (s *HTTPServer) exportOrders(c *gin.Context) {
+	f, ok := exportFilter(c)
+	if !ok {
+		return
+	}
+	w := newNDJSONWriter(c)
+	w.finish(s.Eng.ExportOrders(c.Request.Context(), f, func(o *domain.Order) error {
+		return w.write(s.convertOrder(o))
+	}))
+}
+
+func (s *HTTPServer) exportTrades(c *gin.Context) {
+	f, ok := exportFilter(c)
+	if !ok {
+		return
+	}
+	w := newNDJSONWriter(c)
+	w.finish(s.Eng.ExportTrades(c.Request.Context(), f, func(t *domain.Trade) error {
+		tr := s.convertTrades([]*domain.Trade{t})[0]
+		tr.BuyClient, tr.SellClient = t.BuyClient, t.SellClient
+		return w.write(tr)
+	}))
+}
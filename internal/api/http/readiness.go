@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+)
+
+// warmupRetryAfter is how many seconds clients held back during the warm-up are told to wait
+const warmupRetryAfter = "5"
+
+// getReadiness answers 200 once the engine is ready and 503 while it loads its books, with the
+// progress either way; it needs no API key, for load balancers and orchestrators
+func (s *HTTPServer) getReadiness(c *gin.Context) {
+	st := s.Eng.WarmupStatus()
+	code := http.StatusOK
+	if !st.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	failed := st.Failed
+	if failed == nil {
+		failed = []string{}
+	}
+	out := dto.Readiness{
+		Ready:   st.Ready,
+		Symbols: st.Symbols,
+		Loaded:  st.Loaded,
+		Orders:  st.Orders,
+		Failed:  failed,
+	}
+	if !st.StartedAt.IsZero() {
+		out.StartedAt = &st.StartedAt
+	}
+	if !st.FinishedAt.IsZero() {
+		out.FinishedAt = &st.FinishedAt
+	}
+	c.JSON(code, out)
+}
+
+// requireReady holds every request back with 503 until the engine is ready
+func (s *HTTPServer) requireReady(c *gin.Context) {
+	if !s.Eng.WarmupStatus().Ready {
+		c.Header("Retry-After", warmupRetryAfter)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "the exchange is loading its order books"})
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+const dayFormat = "2006-01-02"
+
+func (s *HTTPServer) generateDailyReport(c *gin.Context) {
+	var req dto.DailyReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rep, err := s.Eng.GenerateDailyReport(c.Request.Context(), req.Day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, convertDailyReport(rep))
+}
+
+// getDailyReport downloads a stored report as JSON, or one of its parts as CSV
+func (s *HTTPServer) getDailyReport(c *gin.Context) {
+	var req dto.DailyReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Format != "" && req.Format != "json" && req.Format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+	if req.Format == "csv" && req.Part != "symbols" && req.Part != "clients" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "csv download needs part=symbols or part=clients"})
+		return
+	}
+	rep, err := s.Eng.DailyReport(c.Request.Context(), req.Day)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := "report-" + rep.Day.Format(dayFormat)
+	if req.Format != "csv" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, name))
+		c.JSON(http.StatusOK, convertDailyReport(rep))
+		return
+	}
+	var header []string
+	var rows [][]string
+	if req.Part == "symbols" {
+		header = []string{"symbol", "trades", "volume", "notional", "vwap", "high", "low"}
+		for _, r := range rep.Symbols {
+			rows = append(rows, []string{r.Symbol, strconv.Itoa(r.Trades), r.Volume.String(), r.Notional.String(),
+				r.VWAP.String(), r.High.String(), r.Low.String()})
+		}
+	} else {
+		header = []string{"client_id", "orders", "cancelled", "fills", "volume", "notional"}
+		for _, r := range rep.Clients {
+			rows = append(rows, []string{r.ClientID, strconv.Itoa(r.Orders), strconv.Itoa(r.Cancelled), strconv.Itoa(r.Fills),
+				r.Volume.String(), r.Notional.String()})
+		}
+	}
+	writeCSV(c, name+"-"+req.Part+".csv", header, rows)
+}
+
+// writeCSV sends rows under header as a CSV attachment
+func writeCSV(c *gin.Context, filename string, header []string, rows [][]string) {
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(header)
+	_ = w.WriteAll(rows)
+}
+
+func convertDailyReport(rep *domain.DailyReport) dto.DailyReport {
+	out := dto.DailyReport{
+		Day:         rep.Day.Format(dayFormat),
+		GeneratedAt: rep.GeneratedAt,
+		Symbols:     make([]dto.SymbolDayReport, 0, len(rep.Symbols)),
+		Clients:     make([]dto.ClientDayReport, 0, len(rep.Clients)),
+	}
+	for _, r := range rep.Symbols {
+		out.Symbols = append(out.Symbols, dto.SymbolDayReport{
+			Symbol: r.Symbol, Trades: r.Trades, Volume: r.Volume, Notional: r.Notional,
+			VWAP: r.VWAP, High: r.High, Low: r.Low,
+		})
+	}
+	for _, r := range rep.Clients {
+		out.Clients = append(out.Clients, dto.ClientDayReport{
+			ClientID: r.ClientID, Orders: r.Orders, Cancelled: r.Cancelled, Fills: r.Fills,
+			Volume: r.Volume, Notional: r.Notional,
+		})
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/core"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// sessionHeader carries the token of the trading session a request is made through
+const sessionHeader = "X-Session-Token"
+
+func (s *HTTPServer) login(c *gin.Context) {
+	var req dto.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	sess, err := s.Eng.Login(c.Request.Context(), req.ClientID, req.CancelOnDisconnect)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	res := convertSession(sess)
+	res.Token = sess.Token
+	c.JSON(http.StatusOK, res)
+}
+
+func (s *HTTPServer) heartbeat(c *gin.Context) {
+	sess, err := s.Eng.Heartbeat(c.Request.Context(), c.GetHeader(sessionHeader))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, convertSession(sess))
+}
+
+func (s *HTTPServer) logout(c *gin.Context) {
+	ids, err := s.Eng.Logout(c.Request.Context(), c.GetHeader(sessionHeader))
+	sessionCancelled(c, ids, err)
+}
+
+func (s *HTTPServer) cancelSessionOrders(c *gin.Context) {
+	ids, err := s.Eng.CancelSessionOrders(c.Request.Context(), c.GetHeader(sessionHeader))
+	sessionCancelled(c, ids, err)
+}
+
+func sessionCancelled(c *gin.Context, ids []string, err error) {
+	switch {
+	case errors.Is(err, core.ErrUnknownSession):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	c.JSON(http.StatusOK, dto.SessionCancelResponse{Cancelled: ids})
+}
+
+// orderSession returns the ID of the session an order of the client is placed through, empty
+// without a session header; it answers the request itself and returns false if the token is unknown
+// or belongs to another client
+func (s *HTTPServer) orderSession(c *gin.Context, clientID string) (string, bool) {
+	token := c.GetHeader(sessionHeader)
+	if token == "" {
+		return "", true
+	}
+	sess, err := s.Eng.Heartbeat(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return "", false
+	}
+	if sess.ClientID != clientID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "session belongs to another client"})
+		return "", false
+	}
+	return sess.ID, true
+}
+
+func convertSession(sess domain.Session) dto.Session {
+	return dto.Session{
+		SessionID:          sess.ID,
+		ClientID:           sess.ClientID,
+		CancelOnDisconnect: sess.CancelOnDisconnect,
+		CreatedAt:          sess.CreatedAt,
+		LastSeen:           sess.LastSeen,
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// getStatement returns a client's statement as JSON, or one of its parts as CSV
+func (s *HTTPServer) getStatement(c *gin.Context) {
+	var req dto.StatementRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !actingClient(c, &req.ClientID) {
+		return
+	}
+	if req.Format != "" && req.Format != "json" && req.Format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+	if req.Format == "csv" && req.Part != "fills" && req.Part != "movements" && req.Part != "positions" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "csv download needs part=fills, part=movements or part=positions"})
+		return
+	}
+	st, err := s.Eng.Statement(c.Request.Context(), req.ClientID, req.From, req.To, req.Tag)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := fmt.Sprintf("statement-%s-%s-%s", st.ClientID, st.From.UTC().Format(dayFormat), st.To.UTC().Format(dayFormat))
+	if req.Format != "csv" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, name))
+		c.JSON(http.StatusOK, convertStatement(st))
+		return
+	}
+	var header []string
+	var rows [][]string
+	switch req.Part {
+	case "fills":
+		header = []string{"timestamp", "trade_id", "order_id", "symbol", "side", "liquidity", "price", "quantity", "fee", "user_data"}
+		for _, f := range st.Fills {
+			rows = append(rows, []string{f.Timestamp.Format(time.RFC3339Nano), f.TradeID, f.OrderID, f.Symbol, string(f.Side),
+				string(f.Liquidity), f.Price.String(), f.Quantity.String(), f.Fee.String(), f.UserData})
+		}
+	case "movements":
+		header = []string{"timestamp", "trade_id", "kind", "asset", "amount"}
+		for _, m := range st.Movements {
+			rows = append(rows, []string{m.Timestamp.Format(time.RFC3339Nano), m.TradeID, string(m.Kind), m.Asset, m.Amount.String()})
+		}
+	default:
+		header = []string{"asset", "net", "fees"}
+		fees := make(map[string]string, len(st.Fees))
+		for _, f := range st.Fees {
+			fees[f.Asset] = f.Amount.String()
+		}
+		for _, p := range st.Positions {
+			fee, ok := fees[p.Asset]
+			if !ok {
+				fee = "0"
+			}
+			rows = append(rows, []string{p.Asset, p.Amount.String(), fee})
+		}
+	}
+	writeCSV(c, name+"-"+req.Part+".csv", header, rows)
+}
+
+func convertStatement(st *domain.Statement) dto.Statement {
+	out := dto.Statement{
+		ClientID:  st.ClientID,
+		From:      st.From,
+		To:        st.To,
+		Fills:     make([]dto.StatementFill, 0, len(st.Fills)),
+		Movements: make([]dto.LedgerMovement, 0, len(st.Movements)),
+		Positions: convertAssetAmounts(st.Positions),
+		Fees:      convertAssetAmounts(st.Fees),
+	}
+	for _, f := range st.Fills {
+		out.Fills = append(out.Fills, dto.StatementFill{
+			TradeID: f.TradeID, OrderID: f.OrderID, Symbol: f.Symbol, Side: dto.Side(f.Side), Liquidity: string(f.Liquidity),
+			Price: f.Price, Quantity: f.Quantity, Fee: f.Fee, Timestamp: f.Timestamp, Tags: f.Tags,
+			UserData: f.UserData,
+		})
+	}
+	for _, m := range st.Movements {
+		out.Movements = append(out.Movements, dto.LedgerMovement{
+			Timestamp: m.Timestamp, TradeID: m.TradeID, Kind: string(m.Kind), Asset: m.Asset, Amount: m.Amount,
+		})
+	}
+	return out
+}
+
+func convertAssetAmounts(in []domain.AssetAmount) []dto.AssetAmount {
+	out := make([]dto.AssetAmount, 0, len(in))
+	for _, a := range in {
+		out = append(out, dto.AssetAmount{Asset: a.Asset, Amount: a.Amount})
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+)
+
+const maxAlerts = 500
+
+func (s *HTTPServer) listAlerts(c *gin.Context) {
+	var req dto.ListAlertsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	f := domain.AlertFilter{
+		Status: domain.AlertStatus(req.Status), Kind: domain.AlertKind(req.Kind),
+		Symbol: req.Symbol, ClientID: req.ClientID,
+	}
+	alerts, err := s.Eng.SurveillanceAlerts(c.Request.Context(), f, page.Request{Cursor: req.Cursor, Limit: req.Limit}.Capped(maxAlerts))
+	if err != nil {
+		pageError(c, err)
+		return
+	}
+	resp := dto.ListAlertsResponse{Alerts: make([]dto.SurveillanceAlert, len(alerts.Items)), NextCursor: alerts.NextCursor}
+	for i, a := range alerts.Items {
+		resp.Alerts[i] = convertAlert(a)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *HTTPServer) reviewAlert(c *gin.Context) {
+	var req dto.ReviewAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.Eng.ReviewAlert(c.Request.Context(), req.AlertID, domain.AlertStatus(req.Status), req.Reviewer, req.Note); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reviewed": true})
+}
+
+func convertAlert(a domain.SurveillanceAlert) dto.SurveillanceAlert {
+	out := dto.SurveillanceAlert{
+		AlertID: a.ID, Kind: string(a.Kind), Symbol: a.Symbol, ClientIDs: a.ClientIDs, TradeIDs: a.TradeIDs,
+		Detail: a.Detail, At: a.At, DetectedAt: a.DetectedAt, Status: string(a.Status),
+		ReviewedBy: a.ReviewedBy, ReviewNote: a.ReviewNote, ReviewedAt: a.ReviewedAt,
+	}
+	if out.ClientIDs == nil {
+		out.ClientIDs = []string{}
+	}
+	if out.TradeIDs == nil {
+		out.TradeIDs = []string{}
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// requestTickData starts a tick data job and answers with it, still PENDING
+func (s *HTTPServer) requestTickData(c *gin.Context) {
+	var req dto.TickDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	job, err := s.Eng.RequestTickData(c.Request.Context(), req.Symbol, req.From, req.To, req.BookChanges)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, convertTickJob(job))
+}
+
+func (s *HTTPServer) getTickDataJob(c *gin.Context) {
+	job, err := s.Eng.TickDataJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, convertTickJob(job))
+}
+
+// downloadTickData sends a file of a finished job as it is stored, gzip-compressed NDJSON
+func (s *HTTPServer) downloadTickData(c *gin.Context) {
+	var req dto.TickDataFileRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.File == "" {
+		req.File = domain.TickFileTrades
+	}
+	data, err := s.Eng.TickDataFile(c.Request.Context(), c.Param("id"), req.File)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.ndjson.gz"`, c.Param("id"), req.File))
+	c.Data(http.StatusOK, "application/gzip", data)
+}
+
+func convertTickJob(job domain.TickJob) dto.TickJob {
+	out := dto.TickJob{
+		ID: job.ID, Symbol: job.Symbol, From: job.From, To: job.To, BookChanges: job.BookChanges,
+		Status: string(job.Status), Error: job.Error, Files: make([]string, 0, len(job.Files)),
+		Trades: job.Trades, Changes: job.Changes, CreatedAt: job.CreatedAt,
+	}
+	for file := range job.Files {
+		out.Files = append(out.Files, file)
+	}
+	sort.Strings(out.Files)
+	if !job.FinishedAt.IsZero() {
+		out.FinishedAt = &job.FinishedAt
+	}
+	return out
+}
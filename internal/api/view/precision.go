@@ -0,0 +1,35 @@
+package view
+
+import (
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// Precision prints a symbol's prices and quantities the same way over HTTP and gRPC: with its
+// configured decimal places when Fixed, otherwise with as many as each value has
+type Precision struct {
+	domain.Precision
+	Fixed bool
+}
+
+// PrecisionOf is the precision the exchange has configured for the symbol
+func PrecisionOf(eng interface {
+	Precision(symbol string) (domain.Precision, bool)
+}, symbol string) Precision {
+	p, ok := eng.Precision(symbol)
+	return Precision{Precision: p, Fixed: ok}
+}
+
+func (p Precision) FormatPrice(d decimal.Decimal) string {
+	if !p.Fixed {
+		return d.String()
+	}
+	return p.Precision.FormatPrice(d)
+}
+
+func (p Precision) FormatQuantity(d decimal.Decimal) string {
+	if !p.Fixed {
+		return d.String()
+	}
+	return p.Precision.FormatQuantity(d)
+}
@@ -0,0 +1,124 @@
+// Package view decides what market data shows of other participants. Orders in the book and the two
+// sides of a trade name their order and client only to the owner; admins and compliance see every
+// participant. Prices, quantities, sides and times are public.
+package view
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/auth"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// Viewer is the caller market data is shown to
+type Viewer struct {
+	ClientID string // client whose orders and trades are shown in full, empty for none
+	All      bool   // every participant is shown in full
+}
+
+// FromContext is the viewer of the principal making the request; without one nothing is shown in full
+func FromContext(ctx context.Context) Viewer {
+	p, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return Viewer{}
+	}
+	return Viewer{ClientID: p.ClientID, All: p.HasAny(auth.ComplianceRoles...)}
+}
+
+// Owns reports whether the viewer sees the client's orders and trades in full
+func (v Viewer) Owns(clientID string) bool {
+	return v.All || (v.ClientID != "" && clientID == v.ClientID)
+}
+
+// Order returns o as the viewer sees it: another participant's order keeps what the book shows of it
+func (v Viewer) Order(o domain.Order) domain.Order {
+	if v.Owns(o.ClientID) {
+		return o
+	}
+	return domain.Order{
+		Symbol:         o.Symbol,
+		Side:           o.Side,
+		Type:           o.Type,
+		Price:          o.Price,
+		Quantity:       o.Quantity,
+		FilledQuantity: o.FilledQuantity,
+		Remaining:      o.Remaining,
+		Status:         o.Status,
+		CreatedAt:      o.CreatedAt,
+		UpdatedAt:      o.UpdatedAt,
+	}
+}
+
+func (v Viewer) Orders(in []domain.Order) []domain.Order {
+	out := make([]domain.Order, len(in))
+	for i, o := range in {
+		out[i] = v.Order(o)
+	}
+	return out
+}
+
+// Book returns a copy of ob as the viewer sees it
+func (v Viewer) Book(ob *domain.OrderbookSnapshot) *domain.OrderbookSnapshot {
+	return &domain.OrderbookSnapshot{
+		Symbol:    ob.Symbol,
+		Bids:      v.Orders(ob.Bids),
+		Asks:      v.Orders(ob.Asks),
+		Trades:    v.Trades(ob.Trades),
+		Timestamp: ob.Timestamp,
+		Sequence:  ob.Sequence,
+	}
+}
+
+// Levels aggregates the book by price level: each level is one entry with the price, side and the
+// total remaining quantity of its orders, and no order or client. This is what callers without the
+// L3 entitlement stream.
+func Levels(ob *domain.OrderbookSnapshot) *domain.OrderbookSnapshot {
+	return &domain.OrderbookSnapshot{
+		Symbol:    ob.Symbol,
+		Bids:      levels(ob.Bids),
+		Asks:      levels(ob.Asks),
+		Trades:    ob.Trades,
+		Timestamp: ob.Timestamp,
+		Sequence:  ob.Sequence,
+	}
+}
+
+// levels merges the orders of each price; orders are in price priority, so a level's orders are adjacent
+func levels(orders []domain.Order) []domain.Order {
+	out := make([]domain.Order, 0, len(orders))
+	for _, o := range orders {
+		if n := len(out); n > 0 && out[n-1].Price.Equal(o.Price) {
+			out[n-1].Quantity = out[n-1].Quantity.Add(o.Remaining)
+			out[n-1].Remaining = out[n-1].Remaining.Add(o.Remaining)
+			continue
+		}
+		out = append(out, domain.Order{Symbol: o.Symbol, Side: o.Side, Price: o.Price, Quantity: o.Remaining, Remaining: o.Remaining})
+	}
+	return out
+}
+
+// Trade returns t as the viewer sees it: the side of another participant loses its order and client
+func (v Viewer) Trade(t *domain.Trade) *domain.Trade {
+	if t == nil || v.All {
+		return t
+	}
+	out := *t
+	if !v.Owns(t.BuyClient) {
+		out.BuyOrder, out.BuyClient = "", ""
+	}
+	if !v.Owns(t.SellClient) {
+		out.SellOrder, out.SellClient = "", ""
+	}
+	return &out
+}
+
+func (v Viewer) Trades(in []*domain.Trade) []*domain.Trade {
+	if in == nil {
+		return nil
+	}
+	out := make([]*domain.Trade, len(in))
+	for i, t := range in {
+		out[i] = v.Trade(t)
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestViewer(t *testing.T) {
+	book := &domain.OrderbookSnapshot{
+		Symbol: "BTC/USD",
+		Bids:   []domain.Order{{ID: "b1", ClientID: "alice", Price: decimal.NewFromInt(100), Remaining: decimal.NewFromInt(1), UserData: "x"}},
+		Asks:   []domain.Order{{ID: "a1", ClientID: "bob", Price: decimal.NewFromInt(101), Remaining: decimal.NewFromInt(2)}},
+	}
+	trade := &domain.Trade{ID: "t", BuyOrder: "b0", SellOrder: "a1", BuyClient: "alice", SellClient: "bob"}
+
+	alice := Viewer{ClientID: "alice"}
+	got := alice.Book(book)
+	if got.Bids[0].ID != "b1" || got.Bids[0].UserData != "x" {
+		t.Errorf("owner sees own bid as %+v", got.Bids[0])
+	}
+	if a := got.Asks[0]; a.ID != "" || a.ClientID != "" || !a.Price.Equal(decimal.NewFromInt(101)) || !a.Remaining.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("owner sees another client's ask as %+v", a)
+	}
+	if book.Asks[0].ID != "a1" {
+		t.Error("viewing the book changed it")
+	}
+	if tr := alice.Trade(trade); tr.BuyOrder != "b0" || tr.BuyClient != "alice" || tr.SellOrder != "" || tr.SellClient != "" || tr.ID != "t" {
+		t.Errorf("buyer sees the trade as %+v", tr)
+	}
+	if tr := (Viewer{}).Trade(trade); tr.BuyOrder != "" || tr.SellOrder != "" {
+		t.Errorf("anonymous viewer sees the trade as %+v", tr)
+	}
+	if tr := (Viewer{All: true}).Trade(trade); tr.BuyClient != "alice" || tr.SellOrder != "a1" {
+		t.Errorf("admin sees the trade as %+v", tr)
+	}
+	if (Viewer{}).Owns("") {
+		t.Error("a viewer without a client owns orders without one")
+	}
+}
+
+func TestLevels(t *testing.T) {
+	book := &domain.OrderbookSnapshot{Bids: []domain.Order{
+		{ID: "1", Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(5), Remaining: decimal.NewFromInt(2)},
+		{ID: "2", Price: decimal.NewFromInt(100), Remaining: decimal.NewFromInt(3)},
+		{ID: "3", Price: decimal.NewFromInt(99), Remaining: decimal.NewFromInt(1)},
+	}}
+	got := Levels(book).Bids
+	if len(got) != 2 || got[0].ID != "" || !got[0].Remaining.Equal(decimal.NewFromInt(5)) || !got[1].Price.Equal(decimal.NewFromInt(99)) {
+		t.Errorf("Levels() bids = %+v", got)
+	}
+}
@@ -0,0 +1,219 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/olyamironova/exchange-engine/internal/api/view"
+	"github.com/olyamironova/exchange-engine/internal/auth"
+	"github.com/olyamironova/exchange-engine/internal/core"
+	"golang.org/x/net/websocket"
+)
+
+// Channels a connection can subscribe to
+const (
+	ChannelOrderbook = "orderbook" // price levels: a snapshot, then the levels that changed
+	ChannelTrades    = "trades"
+	ChannelQuotes    = "quotes"
+	ChannelOrders    = "orders" // order status updates of one client
+)
+
+// maxSubscriptions caps the subscriptions of one connection
+const maxSubscriptions = 100
+
+// Request is a message from the client. Orderbook, trades and quotes take a symbol, or "*" for every
+// symbol; orders take the client_id, which defaults to the API key's client.
+type Request struct {
+	Op       string  `json:"op"` // subscribe or unsubscribe
+	Channel  string  `json:"channel"`
+	Symbol   string  `json:"symbol,omitempty"`
+	ClientID string  `json:"client_id,omitempty"`
+	MaxRate  float64 `json:"max_rate,omitempty"` // orderbook and quotes updates per second per symbol, 0 for every change
+}
+
+// Message is a message to the client. Type is subscribed, unsubscribed, snapshot, update, error or end;
+// an end without a channel closes the connection.
+type Message struct {
+	Type     string `json:"type"`
+	Channel  string `json:"channel,omitempty"`
+	Symbol   string `json:"symbol,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Data     any    `json:"data,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// Dropped counts the updates missed since the previous message of the subscription
+	Dropped uint64 `json:"dropped,omitempty"`
+}
+
+type conn struct {
+	srv    *Server
+	ws     *websocket.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+	viewer view.Viewer
+
+	writeMu sync.Mutex
+	wg      sync.WaitGroup
+
+	mu   sync.Mutex
+	subs map[string]*subscription
+}
+
+type subscription struct {
+	cancel context.CancelFunc
+}
+
+func newConn(ctx context.Context, srv *Server, ws *websocket.Conn) *conn {
+	ctx, cancel := context.WithCancel(ctx)
+	return &conn{srv: srv, ws: ws, ctx: ctx, cancel: cancel, viewer: view.FromContext(ctx), subs: make(map[string]*subscription)}
+}
+
+// serve reads requests until the client goes away or the server drains
+func (c *conn) serve() {
+	defer c.wg.Wait()
+	defer c.cancel()
+	go func() {
+		select {
+		case <-c.srv.drain:
+			c.send(Message{Type: "end", Reason: drainReason})
+			c.ws.Close()
+		case <-c.ctx.Done():
+		}
+	}()
+	for {
+		var req Request
+		if err := websocket.JSON.Receive(c.ws, &req); err != nil {
+			return
+		}
+		switch req.Op {
+		case "subscribe":
+			c.subscribe(req)
+		case "unsubscribe":
+			c.unsubscribe(req)
+		default:
+			c.send(Message{Type: "error", Channel: req.Channel, Symbol: req.Symbol, Error: fmt.Sprintf("unknown op %q", req.Op)})
+		}
+	}
+}
+
+// send writes one message; pumps of different subscriptions share the connection
+func (c *conn) send(m Message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return websocket.JSON.Send(c.ws, m)
+}
+
+func (c *conn) fail(req Request, format string, args ...any) {
+	c.send(Message{Type: "error", Channel: req.Channel, Symbol: req.Symbol, ClientID: req.ClientID, Error: fmt.Sprintf(format, args...)})
+}
+
+func subscriptionKey(req Request) string {
+	if req.Channel == ChannelOrders {
+		return req.Channel + "/" + req.ClientID
+	}
+	return req.Channel + "/" + req.Symbol
+}
+
+func (c *conn) subscribe(req Request) {
+	var pump func(ctx context.Context, req Request) error
+	switch req.Channel {
+	case ChannelOrderbook:
+		pump = c.pumpOrderbook
+	case ChannelTrades:
+		pump = c.pumpTrades
+	case ChannelQuotes:
+		pump = c.pumpQuotes
+	case ChannelOrders:
+		pump = c.pumpOrders
+	default:
+		c.fail(req, "unknown channel %q", req.Channel)
+		return
+	}
+	if req.Channel == ChannelOrders {
+		if !c.allowOrders(&req) {
+			return
+		}
+	} else if req.Symbol == "" {
+		c.fail(req, "symbol is required")
+		return
+	}
+	key := subscriptionKey(req)
+	ctx, cancel := context.WithCancel(c.ctx)
+	sub := &subscription{cancel: cancel}
+	c.mu.Lock()
+	_, dup := c.subs[key]
+	full := len(c.subs) >= maxSubscriptions
+	if !dup && !full {
+		c.subs[key] = sub
+	}
+	c.mu.Unlock()
+	switch {
+	case dup:
+		cancel()
+		c.fail(req, "already subscribed")
+		return
+	case full:
+		cancel()
+		c.fail(req, "at most %d subscriptions per connection", maxSubscriptions)
+		return
+	}
+
+	c.send(Message{Type: "subscribed", Channel: req.Channel, Symbol: req.Symbol, ClientID: req.ClientID})
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		err := pump(ctx, req)
+		// a subscription that ended on its own can be made again
+		c.mu.Lock()
+		if c.subs[key] == sub {
+			delete(c.subs, key)
+		}
+		c.mu.Unlock()
+		if err != nil && ctx.Err() == nil {
+			c.send(Message{Type: "end", Channel: req.Channel, Symbol: req.Symbol, ClientID: req.ClientID, Reason: err.Error()})
+		}
+		cancel()
+	}()
+}
+
+// allowOrders defaults the client of an orders subscription and checks the caller may follow it:
+// its own client, or every client with the drop-copy entitlement
+func (c *conn) allowOrders(req *Request) bool {
+	if req.ClientID == "" {
+		req.ClientID = c.viewer.ClientID
+	}
+	if req.ClientID == "" {
+		c.fail(*req, "client_id is required")
+		return false
+	}
+	if req.ClientID == core.AllClients {
+		if p, ok := auth.PrincipalFromContext(c.ctx); !ok || !p.Entitled(auth.EntitlementDropCopy) {
+			c.fail(*req, "the all-client order stream needs the drop-copy entitlement")
+			return false
+		}
+		return true
+	}
+	if !c.viewer.Owns(req.ClientID) {
+		c.fail(*req, "orders can only be followed for the caller's own client")
+		return false
+	}
+	return true
+}
+
+func (c *conn) unsubscribe(req Request) {
+	if req.Channel == ChannelOrders && req.ClientID == "" {
+		req.ClientID = c.viewer.ClientID
+	}
+	key := subscriptionKey(req)
+	c.mu.Lock()
+	sub, ok := c.subs[key]
+	delete(c.subs, key)
+	c.mu.Unlock()
+	if !ok {
+		c.fail(req, "not subscribed")
+		return
+	}
+	sub.cancel()
+	c.send(Message{Type: "unsubscribed", Channel: req.Channel, Symbol: req.Symbol, ClientID: req.ClientID})
+}
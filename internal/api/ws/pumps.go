@@ -0,0 +1,291 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/api/dto"
+	"github.com/olyamironova/exchange-engine/internal/api/view"
+	"github.com/olyamironova/exchange-engine/internal/core"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
+	"github.com/shopspring/decimal"
+)
+
+// Level is one price level of the book; in an update a quantity of 0 removes the level
+type Level struct {
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+}
+
+// BookData is the data of orderbook messages: the whole book in a snapshot, the changed levels in an update
+type BookData struct {
+	Bids      []Level   `json:"bids"`
+	Asks      []Level   `json:"asks"`
+	Sequence  uint64    `json:"sequence,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TradeData is the data of trades updates
+type TradeData struct {
+	dto.Trade
+	Seq string `json:"seq,omitempty"`
+}
+
+// OrderData is the data of orders updates, one per order event
+type OrderData struct {
+	OrderID    string    `json:"order_id"`
+	ClientID   string    `json:"client_id"`
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Type       string    `json:"type"`
+	ExecType   string    `json:"exec_type"`
+	Status     string    `json:"status"`
+	Price      string    `json:"price"`
+	Quantity   string    `json:"quantity"`
+	Remaining  string    `json:"remaining"`
+	LastPrice  string    `json:"last_price,omitempty"`
+	LastQty    string    `json:"last_qty,omitempty"`
+	TradeID    string    `json:"trade_id,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	RejectCode string    `json:"reject_code,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Sequence   uint64    `json:"sequence,omitempty"`
+	Seq        string    `json:"seq,omitempty"`
+}
+
+// closed is why a subscription closed by the engine ended, nil if it just shut down
+func closed(err error) error {
+	if errors.Is(err, pubsub.ErrSlowConsumer) {
+		return err
+	}
+	return nil
+}
+
+// pumpOrderbook sends a symbol's price levels, then the levels each change of the book touched.
+// On "*" every symbol starts with a snapshot at its first change.
+func (c *conn) pumpOrderbook(ctx context.Context, req Request) error {
+	sub := c.srv.Eng.SubscribeOrderbook(ctx, req.Symbol)
+	defer sub.Close()
+	last := make(map[string]*bookLevels)
+	send := func(ob *domain.OrderbookSnapshot, dropped uint64) error {
+		cur := levelsOf(view.PrecisionOf(c.srv.Eng, ob.Symbol), view.Levels(ob))
+		prev, ok := last[ob.Symbol]
+		last[ob.Symbol] = cur
+		msg := Message{Type: "update", Channel: req.Channel, Symbol: ob.Symbol, Dropped: dropped}
+		if !ok {
+			msg.Type = "snapshot"
+			msg.Data = BookData{Bids: cur.bids.list, Asks: cur.asks.list, Sequence: ob.Sequence, Timestamp: ob.Timestamp}
+			return c.send(msg)
+		}
+		bids, asks := prev.bids.diff(cur.bids), prev.asks.diff(cur.asks)
+		if len(bids) == 0 && len(asks) == 0 {
+			return nil
+		}
+		msg.Data = BookData{Bids: bids, Asks: asks, Sequence: ob.Sequence, Timestamp: ob.Timestamp}
+		return c.send(msg)
+	}
+
+	if req.Symbol != core.AllSymbols {
+		ob, err := c.srv.Eng.GetOrderbook(ctx, req.Symbol)
+		if err != nil {
+			return err
+		}
+		if err := send(ob, 0); err != nil {
+			return err
+		}
+	}
+	updates := pubsub.ThrottleBy(ctx, sub.C, pubsub.RateInterval(req.MaxRate),
+		func(ob *domain.OrderbookSnapshot) string { return ob.Symbol })
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ob, ok := <-updates:
+			if !ok {
+				return closed(sub.Err())
+			}
+			if err := send(ob, sub.Dropped()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type bookLevels struct {
+	bids, asks side
+}
+
+// side is one side of the book as formatted levels, best first, with their quantities by price
+type side struct {
+	list []Level
+	qty  map[string]string
+}
+
+func levelsOf(prec view.Precision, ob *domain.OrderbookSnapshot) *bookLevels {
+	return &bookLevels{bids: sideOf(prec, ob.Bids), asks: sideOf(prec, ob.Asks)}
+}
+
+func sideOf(prec view.Precision, orders []domain.Order) side {
+	s := side{list: make([]Level, len(orders)), qty: make(map[string]string, len(orders))}
+	for i, o := range orders {
+		l := Level{Price: prec.FormatPrice(o.Price), Quantity: prec.FormatQuantity(o.Remaining)}
+		s.list[i] = l
+		s.qty[l.Price] = l.Quantity
+	}
+	return s
+}
+
+// diff lists the levels of cur that are new or changed since s, and those gone with a quantity of 0
+func (s side) diff(cur side) []Level {
+	var out []Level
+	for _, l := range cur.list {
+		if s.qty[l.Price] != l.Quantity {
+			out = append(out, l)
+		}
+	}
+	for _, l := range s.list {
+		if _, ok := cur.qty[l.Price]; !ok {
+			out = append(out, Level{Price: l.Price, Quantity: "0"})
+		}
+	}
+	return out
+}
+
+func (c *conn) pumpTrades(ctx context.Context, req Request) error {
+	sub := c.srv.Eng.SubscribeTrades(ctx, req.Symbol)
+	defer sub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-sub.C:
+			if !ok {
+				return closed(sub.Err())
+			}
+			data := TradeData{Trade: c.convertTrade(c.viewer.Trade(e.Trade)), Seq: e.Seq}
+			if err := c.send(Message{Type: "update", Channel: req.Channel, Symbol: e.Trade.Symbol, Data: data, Dropped: sub.Dropped()}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *conn) convertTrade(t *domain.Trade) dto.Trade {
+	prec := view.PrecisionOf(c.srv.Eng, t.Symbol)
+	return dto.Trade{
+		ID:            t.ID,
+		Symbol:        t.Symbol,
+		BuyOrder:      t.BuyOrder,
+		SellOrder:     t.SellOrder,
+		Price:         prec.FormatPrice(t.Price),
+		Quantity:      prec.FormatQuantity(t.Quantity),
+		Timestamp:     t.Timestamp,
+		MakerFee:      t.MakerFee,
+		TakerFee:      t.TakerFee,
+		Sequence:      t.Sequence,
+		MakerFeeAsset: t.MakerFeeAsset,
+		TakerFeeAsset: t.TakerFeeAsset,
+		TakerSide:     string(t.TakerSide),
+		TakerType:     string(t.TakerType),
+		BuyLiquidity:  string(t.BuyLiquidity),
+		SellLiquidity: string(t.SellLiquidity),
+	}
+}
+
+func (c *conn) pumpQuotes(ctx context.Context, req Request) error {
+	sub := c.srv.Eng.SubscribeQuotes(ctx, req.Symbol)
+	defer sub.Close()
+	send := func(q *domain.Quote, dropped uint64) error {
+		return c.send(Message{Type: "update", Channel: req.Channel, Symbol: q.Symbol, Data: convertQuote(q), Dropped: dropped})
+	}
+	if req.Symbol != core.AllSymbols {
+		q, err := c.srv.Eng.GetQuote(ctx, req.Symbol)
+		if err != nil {
+			return err
+		}
+		if err := send(q, 0); err != nil {
+			return err
+		}
+	}
+	updates := pubsub.ThrottleBy(ctx, sub.C, pubsub.RateInterval(req.MaxRate),
+		func(q *domain.Quote) string { return q.Symbol })
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case q, ok := <-updates:
+			if !ok {
+				return closed(sub.Err())
+			}
+			if err := send(q, sub.Dropped()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func convertQuote(q *domain.Quote) dto.QuoteResponse {
+	return dto.QuoteResponse{
+		Symbol:    q.Symbol,
+		BidPrice:  q.BidPrice,
+		BidQty:    q.BidQty,
+		AskPrice:  q.AskPrice,
+		AskQty:    q.AskQty,
+		Spread:    q.Spread,
+		Mid:       q.Mid,
+		Timestamp: q.Timestamp,
+	}
+}
+
+func (c *conn) pumpOrders(ctx context.Context, req Request) error {
+	sub := c.srv.Eng.SubscribeOrderEvents(ctx, req.ClientID)
+	defer sub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-sub.C:
+			if !ok {
+				return closed(sub.Err())
+			}
+			msg := Message{Type: "update", Channel: req.Channel, Symbol: ev.Symbol, ClientID: req.ClientID,
+				Data: c.convertEvent(ev), Dropped: sub.Dropped()}
+			if err := c.send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *conn) convertEvent(ev *domain.OrderEvent) OrderData {
+	prec := view.PrecisionOf(c.srv.Eng, ev.Symbol)
+	return OrderData{
+		OrderID:    ev.OrderID,
+		ClientID:   ev.ClientID,
+		Symbol:     ev.Symbol,
+		Side:       string(ev.Side),
+		Type:       string(ev.Type),
+		ExecType:   string(ev.ExecType),
+		Status:     string(ev.Status),
+		Price:      prec.FormatPrice(ev.Price),
+		Quantity:   prec.FormatQuantity(ev.Quantity),
+		Remaining:  prec.FormatQuantity(ev.Remaining),
+		LastPrice:  nonZero(ev.LastPrice, prec.FormatPrice),
+		LastQty:    nonZero(ev.LastQty, prec.FormatQuantity),
+		TradeID:    ev.TradeID,
+		Reason:     ev.Reason,
+		RejectCode: string(ev.RejectCode),
+		Timestamp:  ev.Timestamp,
+		Sequence:   ev.Sequence,
+		Seq:        ev.Seq,
+	}
+}
+
+func nonZero(d decimal.Decimal, format func(decimal.Decimal) string) string {
+	if d.IsZero() {
+		return ""
+	}
+	return format(d)
+}
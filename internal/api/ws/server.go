@@ -0,0 +1,80 @@
+// Package ws serves market data and order updates to web clients over WebSocket. Clients subscribe
+// to channels with JSON requests such as {"op":"subscribe","channel":"trades","symbol":"BTCUSD"}
+// and receive the same streams as the gRPC API, from the engine's pubsub.
+package ws
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/olyamironova/exchange-engine/internal/auth"
+	"github.com/olyamironova/exchange-engine/internal/core"
+	"golang.org/x/net/websocket"
+)
+
+// drainReason is sent in the end message of connections closed on shutdown
+const drainReason = "server is shutting down, reconnect to resume"
+
+// Server is the WebSocket gateway; it is an http.Handler to mount next to the HTTP API
+type Server struct {
+	Eng  core.Exchange
+	Keys *auth.KeyStore
+
+	drainOnce sync.Once
+	drain     chan struct{}
+}
+
+func NewServer(eng core.Exchange, keys *auth.KeyStore) *Server {
+	return &Server{Eng: eng, Keys: keys, drain: make(chan struct{})}
+}
+
+// Drain ends every open connection with an end message, so clients reconnect to another instance.
+// Connections opened afterwards end right away.
+func (s *Server) Drain() { s.drainOnce.Do(func() { close(s.drain) }) }
+
+// ServeHTTP authenticates the upgrade request and then serves the connection. Browsers cannot set
+// headers on a WebSocket, so the API key may also come in the api_key query parameter and a bearer
+// token in access_token.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cred := auth.Credentials{
+		APIKey:    r.Header.Get("X-API-Key"),
+		Timestamp: r.Header.Get("X-Timestamp"),
+		Signature: r.Header.Get("X-Signature"),
+		Method:    r.Method,
+		Path:      r.URL.RequestURI(),
+	}
+	if cred.APIKey == "" {
+		cred.APIKey = r.URL.Query().Get("api_key")
+	}
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		cred.Bearer = strings.TrimSpace(bearer)
+	} else {
+		cred.Bearer = r.URL.Query().Get("access_token")
+	}
+	p, err := s.Keys.Authenticate(cred)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !p.HasAny(auth.ReadRoles...) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	release, err := s.Keys.OpenStream(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	defer release()
+
+	ctx := auth.WithPrincipal(r.Context(), p)
+	// the API key authenticates the connection, so cross-origin pages are allowed
+	srv := websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			newConn(ctx, s, ws).serve()
+		},
+	}
+	srv.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// SignatureWindow bounds how far the timestamp of a signed request may be from the server's clock,
+// so a captured request cannot be replayed later
+const SignatureWindow = 30 * time.Second
+
+var (
+	ErrUnknownKey     = errors.New("unknown API key")
+	ErrBadSignature   = errors.New("invalid request signature")
+	ErrStaleSignature = errors.New("request timestamp is outside the signature window")
+	// ErrNoClient is returned to a principal bound to no client that names none, or may not name one
+	ErrNoClient = errors.New("the credentials are not bound to a client")
+	// ErrClientMismatch is returned when a request names another client than the credentials' own
+	ErrClientMismatch = errors.New("client_id does not match the authenticated client")
+	ErrClientRequired = errors.New("client_id is required")
+)
+
+// Credentials are what a request presents: an API key, which must come with a signature of the
+// request if the key has a secret, or a bearer token
+type Credentials struct {
+	APIKey string
+	Bearer string
+	// Timestamp is the Unix time in milliseconds the request was signed at
+	Timestamp string
+	// Signature is the hex HMAC-SHA256 of the request made with the key's secret, see Sign
+	Signature string
+	Method    string
+	// Path is the request target with its query, or the full method of an RPC
+	Path string
+	Body []byte
+}
+
+// Sign returns the signature of a request: the hex HMAC-SHA256, keyed with the secret, of the
+// timestamp, method, path and body concatenated
+func Sign(secret []byte, timestamp, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + method + path))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AddSecret makes key an HMAC key: every request with it must be signed with secret. The key keeps
+// the tenant, roles and client it was added with.
+func (k *KeyStore) AddSecret(key string, secret []byte) {
+	if key == "" || len(secret) == 0 {
+		return
+	}
+	k.secrets[key] = secret
+}
+
+// Authenticate returns the principal of the credentials. A bearer token takes precedence over an
+// API key; a key with a secret needs a valid signature made within SignatureWindow.
+func (k *KeyStore) Authenticate(c Credentials) (Principal, error) {
+	if c.Bearer != "" {
+		return k.verifyToken(c.Bearer)
+	}
+	secret, signed := k.secrets[c.APIKey]
+	if !signed {
+		p, ok := k.Resolve(c.APIKey)
+		if !ok {
+			return Principal{}, ErrUnknownKey
+		}
+		return p, nil
+	}
+	ms, err := strconv.ParseInt(c.Timestamp, 10, 64)
+	if err != nil || c.Signature == "" {
+		return Principal{}, ErrBadSignature
+	}
+	if skew := k.now().Sub(time.UnixMilli(ms)); skew > SignatureWindow || skew < -SignatureWindow {
+		return Principal{}, ErrStaleSignature
+	}
+	want := Sign(secret, c.Timestamp, c.Method, c.Path, c.Body)
+	if !hmac.Equal([]byte(want), []byte(c.Signature)) {
+		return Principal{}, ErrBadSignature
+	}
+	return k.trusted(k.keys[c.APIKey]), nil
+}
+
+// ClientID returns the client the ctx caller acts for. A principal bound to a client acts for it
+// alone: claimed may be empty or must name it. Admins, and principals allowed to act for any
+// client, act for the claimed one. Without a principal, as when the API runs without
+// authentication, the claimed client is trusted.
+func ClientID(ctx context.Context, claimed string) (string, error) {
+	p, ok := PrincipalFromContext(ctx)
+	switch {
+	case !ok:
+	case p.ClientID != "":
+		if claimed != "" && claimed != p.ClientID {
+			return "", ErrClientMismatch
+		}
+		return p.ClientID, nil
+	case !p.ActForAny && !p.HasAny(RoleAdmin):
+		return "", ErrNoClient
+	}
+	if claimed == "" {
+		return "", ErrClientRequired
+	}
+	return claimed, nil
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateSignedKey(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	k := NewKeyStore(nil)
+	k.now = func() time.Time { return now }
+	k.AddClient("hk", "", "alice", RoleTrader)
+	k.AddSecret("hk", []byte("s3cret"))
+
+	ts := strconv.FormatInt(now.UnixMilli(), 10)
+	body := []byte(`{"symbol":"BTC/USDT"}`)
+	signed := Credentials{APIKey: "hk", Timestamp: ts, Method: "POST", Path: "/orders", Body: body,
+		Signature: Sign([]byte("s3cret"), ts, "POST", "/orders", body)}
+	p, err := k.Authenticate(signed)
+	if err != nil || p.ClientID != "alice" {
+		t.Fatalf("signed request: %+v, %v", p, err)
+	}
+
+	tampered := signed
+	tampered.Body = []byte(`{"symbol":"ETH/USDT"}`)
+	if _, err := k.Authenticate(tampered); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("tampered body: %v", err)
+	}
+	k.now = func() time.Time { return now.Add(SignatureWindow + time.Second) }
+	if _, err := k.Authenticate(signed); !errors.Is(err, ErrStaleSignature) {
+		t.Fatalf("replayed after the window: %v", err)
+	}
+	if _, ok := k.Resolve("hk"); ok {
+		t.Fatal("a key with a secret resolved without a signature")
+	}
+}
+
+func TestAuthenticateBearer(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	secret := []byte("jwt-secret")
+	k := NewKeyStore(nil)
+	k.now = func() time.Time { return now }
+	k.SetTokenSecret(secret, "exchange")
+
+	token, _ := SignToken(secret, Claims{Subject: "bob", Issuer: "exchange", ExpiresAt: now.Add(time.Minute).Unix()})
+	p, err := k.Authenticate(Credentials{Bearer: token})
+	if err != nil || p.ClientID != "bob" || !p.HasAny(RoleTrader) {
+		t.Fatalf("valid token: %+v, %v", p, err)
+	}
+
+	for name, c := range map[string]Claims{
+		"expired":      {Subject: "bob", Issuer: "exchange", ExpiresAt: now.Unix()},
+		"other issuer": {Subject: "bob", Issuer: "elsewhere"},
+		"no subject":   {Issuer: "exchange"},
+	} {
+		token, _ := SignToken(secret, c)
+		if _, err := k.Authenticate(Credentials{Bearer: token}); !errors.Is(err, ErrBadToken) {
+			t.Errorf("%s token: %v", name, err)
+		}
+	}
+	forged, _ := SignToken([]byte("guess"), Claims{Subject: "bob", Issuer: "exchange"})
+	if _, err := k.Authenticate(Credentials{Bearer: forged}); !errors.Is(err, ErrBadToken) {
+		t.Errorf("token signed with another secret: %v", err)
+	}
+}
+
+func TestClientID(t *testing.T) {
+	bound := WithPrincipal(context.Background(), Principal{Roles: []Role{RoleTrader}, ClientID: "alice"})
+	unbound := WithPrincipal(context.Background(), Principal{Roles: []Role{RoleTrader}})
+	trusted := WithPrincipal(context.Background(), Principal{Roles: []Role{RoleTrader}, ActForAny: true})
+	admin := WithPrincipal(context.Background(), Principal{Roles: []Role{RoleAdmin}})
+
+	for _, tc := range []struct {
+		name    string
+		ctx     context.Context
+		claimed string
+		want    string
+		err     error
+	}{
+		{"bound, omitted", bound, "", "alice", nil},
+		{"bound, own", bound, "alice", "alice", nil},
+		{"bound, other", bound, "mallory", "", ErrClientMismatch},
+		{"unbound", unbound, "alice", "", ErrNoClient},
+		{"trusted", trusted, "alice", "alice", nil},
+		{"trusted, omitted", trusted, "", "", ErrClientRequired},
+		{"admin", admin, "alice", "alice", nil},
+		{"unauthenticated", context.Background(), "alice", "alice", nil},
+	} {
+		got, err := ClientID(tc.ctx, tc.claimed)
+		if got != tc.want || !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %q, %v; want %q, %v", tc.name, got, err, tc.want, tc.err)
+		}
+	}
+}
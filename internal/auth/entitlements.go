@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+)
+
+// Entitlement is market data beyond what every reader gets
+type Entitlement string
+
+const (
+	// EntitlementL3 is the order-by-order book; others stream it aggregated by price level
+	EntitlementL3 Entitlement = "l3"
+	// EntitlementDropCopy is the order events of every client at once
+	EntitlementDropCopy Entitlement = "drop-copy"
+)
+
+// EntitlementRoles lists who holds each entitlement; admin holds every one
+var EntitlementRoles = map[Entitlement][]Role{
+	EntitlementL3:       {RoleMarketMaker},
+	EntitlementDropCopy: {RoleCompliance},
+}
+
+// Entitled reports whether the principal holds the entitlement
+func (p Principal) Entitled(e Entitlement) bool {
+	return p.HasAny(EntitlementRoles[e]...)
+}
+
+// ErrStreamLimit is returned when a key already has as many open streams as it may
+var ErrStreamLimit = errors.New("too many open streams for this API key")
+
+// streamLimits counts the open streams of every key, whichever gateway serves them
+type streamLimits struct {
+	mu       sync.Mutex
+	fallback int
+	limits   map[string]int
+	open     map[string]int
+}
+
+// SetStreamLimit caps the concurrent streams of key at n; with an empty key it sets the cap of keys
+// without one. 0 means no cap, which is the default.
+func (k *KeyStore) SetStreamLimit(key string, n int) {
+	k.streams.mu.Lock()
+	defer k.streams.mu.Unlock()
+	if key == "" {
+		k.streams.fallback = n
+		return
+	}
+	if k.streams.limits == nil {
+		k.streams.limits = make(map[string]int)
+	}
+	k.streams.limits[key] = n
+}
+
+// OpenStream counts a stream of the principal's key against its limit; the caller calls release
+// once the stream ends. It fails with ErrStreamLimit if the key is at its limit.
+func (k *KeyStore) OpenStream(p Principal) (release func(), err error) {
+	s := &k.streams
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	limit, ok := s.limits[p.Key]
+	if !ok {
+		limit = s.fallback
+	}
+	if limit > 0 && s.open[p.Key] >= limit {
+		return nil, ErrStreamLimit
+	}
+	if s.open == nil {
+		s.open = make(map[string]int)
+	}
+	s.open[p.Key]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.open[p.Key]--; s.open[p.Key] == 0 {
+				delete(s.open, p.Key)
+			}
+		})
+	}, nil
+}
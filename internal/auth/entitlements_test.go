@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEntitled(t *testing.T) {
+	for _, tc := range []struct {
+		role Role
+		e    Entitlement
+		want bool
+	}{
+		{RoleMarketMaker, EntitlementL3, true},
+		{RoleTrader, EntitlementL3, false},
+		{RoleAdmin, EntitlementL3, true},
+		{RoleCompliance, EntitlementDropCopy, true},
+		{RoleMarketMaker, EntitlementDropCopy, false},
+	} {
+		if got := (Principal{Roles: []Role{tc.role}}).Entitled(tc.e); got != tc.want {
+			t.Errorf("%s entitled to %s = %v, want %v", tc.role, tc.e, got, tc.want)
+		}
+	}
+}
+
+func TestStreamLimit(t *testing.T) {
+	k := NewKeyStore(nil, RoleTrader)
+	k.SetStreamLimit("", 1)
+	k.SetStreamLimit("mm", 2)
+	a, _ := k.Resolve("a")
+	mm, _ := k.Resolve("mm")
+
+	release, err := k.OpenStream(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.OpenStream(a); !errors.Is(err, ErrStreamLimit) {
+		t.Fatalf("second stream of a key limited to one: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := k.OpenStream(mm); err != nil {
+			t.Fatalf("stream %d of a key limited to two: %v", i, err)
+		}
+	}
+	release()
+	release()
+	if _, err := k.OpenStream(a); err != nil {
+		t.Fatalf("stream after the previous one ended: %v", err)
+	}
+	if _, err := k.OpenStream(a); !errors.Is(err, ErrStreamLimit) {
+		t.Fatalf("releasing twice freed two streams: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// ErrBadToken is returned for a bearer token that is malformed, wrongly signed, expired or not
+// accepted by the store
+var ErrBadToken = errors.New("invalid bearer token")
+
+// Claims are the JWT claims the store reads; the subject is the client the bearer acts for
+type Claims struct {
+	Subject string `json:"sub"`
+	Issuer  string `json:"iss,omitempty"`
+	// ExpiresAt and NotBefore are Unix seconds; zero does not bound
+	ExpiresAt int64  `json:"exp,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	Tenant    string `json:"tenant,omitempty"`
+	// Roles default to trader
+	Roles   []Role `json:"roles,omitempty"`
+	Sandbox bool   `json:"sandbox,omitempty"`
+}
+
+type tokenConfig struct {
+	secret []byte
+	issuer string
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// SetTokenSecret makes the store accept HS256 bearer tokens signed with secret, only those of
+// issuer unless it is empty
+func (k *KeyStore) SetTokenSecret(secret []byte, issuer string) {
+	if len(secret) == 0 {
+		k.tokens = nil
+		return
+	}
+	k.tokens = &tokenConfig{secret: secret, issuer: issuer}
+}
+
+// SignToken returns the HS256 JWT of the claims, for tests and tools issuing tokens
+func SignToken(secret []byte, c Claims) (string, error) {
+	header, _ := json.Marshal(tokenHeader{Alg: "HS256", Typ: "JWT"})
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	unsigned := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	return unsigned + "." + b64.EncodeToString(tokenMAC(secret, unsigned)), nil
+}
+
+func tokenMAC(secret []byte, unsigned string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+// verifyToken checks the token's signature and claims and returns the principal of its subject
+func (k *KeyStore) verifyToken(token string) (Principal, error) {
+	if k.tokens == nil {
+		return Principal{}, ErrBadToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, ErrBadToken
+	}
+	var h tokenHeader
+	if raw, err := b64.DecodeString(parts[0]); err != nil || json.Unmarshal(raw, &h) != nil || h.Alg != "HS256" {
+		return Principal{}, ErrBadToken
+	}
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, tokenMAC(k.tokens.secret, parts[0]+"."+parts[1])) {
+		return Principal{}, ErrBadToken
+	}
+	var c Claims
+	if raw, err := b64.DecodeString(parts[1]); err != nil || json.Unmarshal(raw, &c) != nil {
+		return Principal{}, ErrBadToken
+	}
+	now := k.now().Unix()
+	switch {
+	case c.Subject == "",
+		k.tokens.issuer != "" && c.Issuer != k.tokens.issuer,
+		c.ExpiresAt != 0 && now >= c.ExpiresAt,
+		c.NotBefore != 0 && now < c.NotBefore:
+		return Principal{}, ErrBadToken
+	}
+	p := Principal{Key: "jwt:" + c.Subject, Tenant: c.Tenant, Roles: c.Roles, Sandbox: c.Sandbox, ClientID: c.Subject}
+	if p.Tenant == "" {
+		p.Tenant = tenant.Default
+	}
+	if len(p.Roles) == 0 {
+		p.Roles = []Role{RoleTrader}
+	}
+	return p, nil
+}
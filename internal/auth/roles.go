@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+type Role string
+
+const (
+	RoleTrader      Role = "trader"
+	RoleReadOnly    Role = "read-only"
+	RoleMarketMaker Role = "market-maker"
+	RoleAdmin       Role = "admin"
+	RoleCompliance  Role = "compliance"
+)
+
+// Role sets used to guard routes and RPCs
+var (
+	ReadRoles       = []Role{RoleTrader, RoleReadOnly, RoleMarketMaker, RoleAdmin, RoleCompliance}
+	TradeRoles      = []Role{RoleTrader, RoleMarketMaker, RoleAdmin}
+	AdminRoles      = []Role{RoleAdmin}
+	ComplianceRoles = []Role{RoleCompliance, RoleAdmin}
+)
+
+// Principal is the authenticated caller
+type Principal struct {
+	Key     string
+	Tenant  string
+	Roles   []Role
+	Sandbox bool
+	// ClientID is the client the key belongs to, whose orders market data shows in full; empty if
+	// the key is not bound to one
+	ClientID string
+	// ActForAny lets an unbound principal act for whichever client a request names; admins always may
+	ActForAny bool
+}
+
+// HasAny reports whether the principal holds at least one of roles; admin holds every role
+func (p Principal) HasAny(roles ...Role) bool {
+	if slices.Contains(p.Roles, RoleAdmin) {
+		return true
+	}
+	for _, r := range roles {
+		if slices.Contains(p.Roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyStore maps API keys to principals. Unknown or missing keys get the fallback roles
+// in the default tenant; with no fallback they are rejected.
+type KeyStore struct {
+	keys     map[string]Principal
+	fallback []Role
+	streams  streamLimits
+	// secrets are those of the keys whose requests must be signed
+	secrets map[string][]byte
+	tokens  *tokenConfig
+	// trustClaimed lets unbound principals act for any client, as before keys were bound to clients
+	trustClaimed bool
+	now          func() time.Time
+}
+
+// NewKeyStore registers keys of the default tenant; use Add for other tenants
+func NewKeyStore(keys map[string][]Role, fallback ...Role) *KeyStore {
+	k := &KeyStore{keys: make(map[string]Principal, len(keys)), fallback: fallback, secrets: make(map[string][]byte), now: time.Now}
+	for key, roles := range keys {
+		k.Add(key, tenant.Default, roles...)
+	}
+	return k
+}
+
+// Add registers an API key belonging to tenantID; empty keys are ignored
+func (k *KeyStore) Add(key, tenantID string, roles ...Role) {
+	if key == "" {
+		return
+	}
+	if tenantID == "" {
+		tenantID = tenant.Default
+	}
+	k.keys[key] = Principal{Key: key, Tenant: tenantID, Roles: roles}
+}
+
+// AddClient registers an API key of tenantID that belongs to clientID
+func (k *KeyStore) AddClient(key, tenantID, clientID string, roles ...Role) {
+	k.Add(key, tenantID, roles...)
+	if p, ok := k.keys[key]; ok {
+		p.ClientID = clientID
+		k.keys[key] = p
+	}
+}
+
+// AddSandbox registers a paper-trading key: it uses the same APIs as Add but trades in the
+// tenant's sandbox namespace
+func (k *KeyStore) AddSandbox(key, tenantID string, roles ...Role) {
+	k.Add(key, tenantID, roles...)
+	if p, ok := k.keys[key]; ok {
+		p.Sandbox = true
+		k.keys[key] = p
+	}
+}
+
+// TrustClaimedClients lets principals not bound to a client act for whichever client a request
+// names, the behaviour of deployments whose keys are not bound to clients
+func (k *KeyStore) TrustClaimedClients() { k.trustClaimed = true }
+
+// Resolve returns the principal of an API key presented without a signature; keys with a secret
+// resolve only through Authenticate
+func (k *KeyStore) Resolve(key string) (Principal, bool) {
+	if _, signed := k.secrets[key]; signed {
+		return Principal{}, false
+	}
+	if p, ok := k.keys[key]; ok && key != "" {
+		return k.trusted(p), true
+	}
+	if len(k.fallback) == 0 {
+		return Principal{}, false
+	}
+	return k.trusted(Principal{Key: key, Tenant: tenant.Default, Roles: k.fallback}), true
+}
+
+func (k *KeyStore) trusted(p Principal) Principal {
+	p.ActForAny = p.ClientID == "" && k.trustClaimed
+	return p
+}
+
+type principalCtxKey struct{}
+
+// WithPrincipal stores the principal and scopes the context to its tenant, or to the
+// tenant's sandbox for paper-trading keys
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	tenantID := p.Tenant
+	if p.Sandbox {
+		tenantID = tenant.Sandbox(tenantID)
+	}
+	return tenant.With(context.WithValue(ctx, principalCtxKey{}, p), tenantID)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exchange.yaml")
+	yaml := `
+postgres:
+  url: postgres://exchange@db:5432/exchange
+  max_conns: 20
+redis:
+  addr: cache:6379
+listen:
+  http: ":9000"
+  grpc: ":9001"
+cache:
+  ttl: 1m
+  policies:
+    BTC/USD: {ttl: 5s, refresh_ahead: 2s}
+matching:
+  symbol_loops: true
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := load(path, env(map[string]string{"HTTP_ADDR": ":8000", "PG_MIN_CONNS": "5", "GRPC_ADDR": ""}))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	switch {
+	case cfg.Postgres.URL != "postgres://exchange@db:5432/exchange" || cfg.Postgres.MaxConns != 20:
+		t.Errorf("postgres from the file: %+v", cfg.Postgres)
+	case cfg.Postgres.MinConns != 5 || cfg.Listen.HTTP != ":8000":
+		t.Errorf("environment does not override the file: %+v, %+v", cfg.Postgres, cfg.Listen)
+	case cfg.Listen.GRPC != ":9001":
+		t.Errorf("empty variable cleared listen.grpc")
+	case cfg.Cache.TTL != time.Minute || cfg.Cache.CachePolicies()["BTC/USD"].RefreshAhead != 2*time.Second:
+		t.Errorf("cache: %+v", cfg.Cache)
+	case !cfg.Matching.SymbolLoops || cfg.Matching.MaxBatchSize != 500 || cfg.Redis.Addr != "cache:6379":
+		t.Errorf("defaults or file settings lost: %+v", cfg)
+	}
+
+	cfg, err = load("", env(map[string]string{
+		"STORAGE":          "writebehind",
+		"SYMBOL_PRECISION": "BTC/USD:2:6:round, ETH/USD:2:4",
+		"CLIENT_API_KEYS":  "k1:alice, k2:bob",
+		"HMAC_API_KEYS":    "k3:carol:s3cret",
+		"KAFKA_BROKERS":    "a:9092, b:9092",
+		"EVENT_BROKER":     "kafka",
+		"GRPC_LOG_FORMAT":  "json",
+		"MATCH_TIMEOUT":    "500ms",
+	}))
+	if err != nil {
+		t.Fatalf("load from the environment: %v", err)
+	}
+	switch {
+	case cfg.Storage.Backend != "writebehind" || cfg.Storage.Path != "exchange.log":
+		t.Errorf("storage: %+v", cfg.Storage)
+	case cfg.Matching.SymbolPrecision()["BTC/USD"] != (domain.Precision{PriceDecimals: 2, QuantityDecimals: 6, Round: true}) ||
+		cfg.Matching.Precision["ETH/USD"].Round:
+		t.Errorf("precision: %+v", cfg.Matching.Precision)
+	case cfg.Auth.ClientKeys["k2"] != "bob" || len(cfg.Auth.HMACKeys) != 1 || cfg.Auth.HMACKeys[0].Secret != "s3cret":
+		t.Errorf("auth: %+v", cfg.Auth)
+	case len(cfg.Events.KafkaBrokers) != 2 || cfg.Events.KafkaBrokers[1] != "b:9092":
+		t.Errorf("events: %+v", cfg.Events)
+	case cfg.GRPC.LogFormat != "json" || cfg.Matching.MatchTimeout != 500*time.Millisecond || cfg.Matching.CandidateBatch != 200:
+		t.Errorf("grpc or matching: %+v, %+v", cfg.GRPC, cfg.Matching)
+	}
+
+	if err := os.WriteFile(path, []byte("matching:\n  max_batch: 10\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := load(path, env(nil)); err == nil || !strings.Contains(err.Error(), "max_batch") {
+		t.Errorf("misspelt key: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("defaults: %v", err)
+	}
+	_, err := load("", env(map[string]string{
+		"DATABASE_URL":   "mysql://db",
+		"REDIS_ADDR":     "cache",
+		"PG_MAX_CONNS":   "2",
+		"PG_MIN_CONNS":   "4",
+		"MAX_BATCH_SIZE": "0",
+	}))
+	for _, want := range []string{"postgres.url", "redis.addr", "min_conns 4 exceeds max_conns 2", "max_batch_size"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not report %s", err, want)
+		}
+	}
+	_, err = load("", env(map[string]string{
+		"STORAGE":               "cassandra",
+		"PG_FLAVOR":             "mysql",
+		"MATCH_CANDIDATE_BATCH": "-1",
+		"EVENT_BROKER":          "kafka",
+		"EVENT_TOPICS":          "bogus=x",
+		"GRPC_LOG_FORMAT":       "xml",
+		"S3_ENDPOINT":           "https://s3.example.com",
+		"SHADOW":                "pg",
+	}))
+	for _, want := range []string{"storage.backend", "postgres.flavor", "candidate_batch", "kafka_brokers",
+		"events.topics", "grpc.log_format", "s3.bucket", "matching.shadow"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not report %s", err, want)
+		}
+	}
+	for name, v := range map[string]string{"SYMBOL_PRECISION": "BTC/USD:two:6", "HMAC_API_KEYS": "k:c", "CLIENT_API_KEYS": "k"} {
+		if _, err := load("", env(map[string]string{name: v})); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("malformed %s: %v", name, err)
+		}
+	}
+	if _, err := load("", env(map[string]string{"CACHE_TTL": "soon"})); err == nil || !strings.Contains(err.Error(), "CACHE_TTL") {
+		t.Errorf("unparsable variable: %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	errAccountsDisabled = errors.New("account balances are not enabled")
+	errInvalidAmount    = errors.New("amount must be > 0")
+)
+
+// WithAccounts funds-checks orders against the clients' account balances: an order locks what it
+// can spend when it is accepted and is rejected if the available balance cannot cover it, fills
+// settle both sides and the end of an order returns what it still holds, all in the transaction of
+// the change. Without this option orders are not funds-checked. Sandbox orders are checked against
+// their virtual balances instead when WithSandboxBalances is set.
+func WithAccounts() Option {
+	return func(e *Engine) { e.accounts = true }
+}
+
+// funded reports whether the ctx's orders move account balances
+func (e *Engine) funded(ctx context.Context) bool {
+	return e.accounts && (e.sandbox == nil || !tenant.IsSandbox(ctx))
+}
+
+// holdFor returns what o's remaining quantity locks: the base asset for a sell, and for a buy the
+// quote asset at its limit price plus the highest fee it could pay. A buy without a limit price has
+// no price to lock at and pays for its fills from the available balance.
+func (e *Engine) holdFor(ctx context.Context, o *domain.Order) (domain.Hold, error) {
+	base, quote, err := e.assets(ctx, o.Symbol)
+	if err != nil {
+		return domain.Hold{}, domain.Reject(domain.RejectUnknownSymbol, "%v", err)
+	}
+	h := domain.Hold{OrderID: o.ID, ClientID: o.ClientID, Asset: base, Amount: o.Remaining}
+	if o.Side == domain.Buy {
+		h.Asset, h.Amount = quote, decimal.Zero
+		if o.Type.HasLimit() {
+			fs := e.feeSchedule(ctx)
+			rate := decimal.Max(decimal.Zero, fs.MakerRate, fs.TakerRate)
+			h.Amount = o.Price.Mul(o.Remaining).Mul(decimal.NewFromInt(1).Add(rate))
+		}
+	}
+	return h, nil
+}
+
+// insufficient turns an overdraft into the rejection of the order that caused it
+func insufficient(err error, asset string, need decimal.Decimal) error {
+	if errors.Is(err, domain.ErrInsufficientFunds) {
+		return domain.Reject(domain.RejectInsufficientFunds, "insufficient %s balance: need %s", asset, need)
+	}
+	return err
+}
+
+// lockFunds moves what a newly accepted order holds from the client's available balance to its locked one
+func (e *Engine) lockFunds(ctx context.Context, tx port.Tx, o *domain.Order) error {
+	if !e.funded(ctx) {
+		return nil
+	}
+	h, err := e.holdFor(ctx, o)
+	if err != nil || !h.Amount.IsPositive() {
+		return err
+	}
+	if err := tx.AdjustAccount(ctx, o.ClientID, h.Asset, h.Amount.Neg(), h.Amount); err != nil {
+		return insufficient(err, h.Asset, h.Amount)
+	}
+	return tx.SaveHold(ctx, h)
+}
+
+// checkFunds rejects an order whose hold the client's available balance cannot cover, as lockFunds
+// would on submission, without locking anything
+func (e *Engine) checkFunds(ctx context.Context, o *domain.Order) error {
+	if !e.funded(ctx) {
+		return nil
+	}
+	h, err := e.holdFor(ctx, o)
+	if err != nil || !h.Amount.IsPositive() {
+		return err
+	}
+	accounts, err := e.repo.LoadAccounts(ctx, o.ClientID)
+	if err != nil {
+		return err
+	}
+	available := decimal.Zero
+	for _, a := range accounts {
+		if a.Asset == h.Asset {
+			available = a.Available
+		}
+	}
+	if available.LessThan(h.Amount) {
+		return insufficient(domain.ErrInsufficientFunds, h.Asset, h.Amount)
+	}
+	return nil
+}
+
+// relockFunds sets a modified order's hold to what its new price and quantity lock, locking the
+// difference from the available balance or returning it there
+func (e *Engine) relockFunds(ctx context.Context, tx port.Tx, o *domain.Order) error {
+	if !e.funded(ctx) {
+		return nil
+	}
+	h, err := e.holdFor(ctx, o)
+	if err != nil {
+		return err
+	}
+	cur, err := tx.LoadHold(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	delta := h.Amount
+	if cur != nil {
+		delta = delta.Sub(cur.Amount)
+	}
+	if !delta.IsZero() {
+		if err := tx.AdjustAccount(ctx, o.ClientID, h.Asset, delta.Neg(), delta); err != nil {
+			return insufficient(err, h.Asset, delta)
+		}
+	}
+	return tx.SaveHold(ctx, h)
+}
+
+// releaseFunds returns what an order that left the book still holds to the available balance
+func (e *Engine) releaseFunds(ctx context.Context, tx port.Tx, o *domain.Order) error {
+	if !e.funded(ctx) {
+		return nil
+	}
+	h, err := tx.LoadHold(ctx, o.ID)
+	if err != nil || h == nil || !h.Amount.IsPositive() {
+		return err
+	}
+	if err := tx.AdjustAccount(ctx, h.ClientID, h.Asset, h.Amount, h.Amount.Neg()); err != nil {
+		return err
+	}
+	h.Amount = decimal.Zero
+	return tx.SaveHold(ctx, *h)
+}
+
+// settleTrade moves a trade's assets: the buyer pays the notional and its fee in the quote asset
+// and receives the base, the seller delivers the base and receives the notional less its fee
+func (e *Engine) settleTrade(ctx context.Context, tx port.Tx, t *domain.Trade, taker, maker *domain.Order) error {
+	if !e.funded(ctx) {
+		return nil
+	}
+	base, quote, err := e.assets(ctx, t.Symbol)
+	if err != nil {
+		return domain.Reject(domain.RejectUnknownSymbol, "%v", err)
+	}
+	buy, sell, buyFee, sellFee := taker, maker, t.TakerFee, t.MakerFee
+	if taker.Side == domain.Sell {
+		buy, sell, buyFee, sellFee = maker, taker, t.MakerFee, t.TakerFee
+	}
+	notional := t.Price.Mul(t.Quantity)
+	if err := spend(ctx, tx, buy, quote, notional.Add(buyFee)); err != nil {
+		return err
+	}
+	if err := tx.AdjustAccount(ctx, buy.ClientID, base, t.Quantity, decimal.Zero); err != nil {
+		return err
+	}
+	if err := spend(ctx, tx, sell, base, t.Quantity); err != nil {
+		return err
+	}
+	return tx.AdjustAccount(ctx, sell.ClientID, quote, notional.Sub(sellFee), decimal.Zero)
+}
+
+// spend pays amount of asset for an order's fill out of its hold, and what the hold cannot cover
+// out of the client's available balance
+func spend(ctx context.Context, tx port.Tx, o *domain.Order, asset string, amount decimal.Decimal) error {
+	h, err := tx.LoadHold(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	held := decimal.Zero
+	if h != nil && h.Asset == asset {
+		held = decimal.Min(amount, h.Amount)
+		h.Amount = h.Amount.Sub(held)
+		if err := tx.SaveHold(ctx, *h); err != nil {
+			return err
+		}
+	}
+	if err := tx.AdjustAccount(ctx, o.ClientID, asset, held.Sub(amount), held.Neg()); err != nil {
+		return insufficient(err, asset, amount)
+	}
+	return nil
+}
+
+// Balances returns the client's account balances by asset
+func (e *Engine) Balances(ctx context.Context, clientID string) ([]domain.Account, error) {
+	if !e.accounts {
+		return nil, errAccountsDisabled
+	}
+	return e.repo.LoadAccounts(ctx, clientID)
+}
+
+// Deposit credits amount of asset to the client's available balance
+func (e *Engine) Deposit(ctx context.Context, clientID, asset string, amount decimal.Decimal) error {
+	if !e.accounts {
+		return errAccountsDisabled
+	}
+	if !amount.IsPositive() {
+		return errInvalidAmount
+	}
+	return withTx(ctx, e.repo, func(tx port.Tx) error {
+		return tx.AdjustAccount(ctx, clientID, asset, amount, decimal.Zero)
+	})
+}
+
+// Withdraw debits amount of asset from the client's available balance; what open orders hold
+// cannot be withdrawn
+func (e *Engine) Withdraw(ctx context.Context, clientID, asset string, amount decimal.Decimal) error {
+	if !e.accounts {
+		return errAccountsDisabled
+	}
+	if !amount.IsPositive() {
+		return errInvalidAmount
+	}
+	return withTx(ctx, e.repo, func(tx port.Tx) error {
+		return tx.AdjustAccount(ctx, clientID, asset, amount.Neg(), decimal.Zero)
+	})
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestAccountsLockSettleAndRelease(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithAccounts(),
+		WithFeeSchedule(domain.FeeSchedule{MakerRate: decimal.RequireFromString("0.01"), TakerRate: decimal.RequireFromString("0.02")}))
+	d := decimal.RequireFromString
+	deposit := func(clientID, asset, amount string) {
+		t.Helper()
+		if err := e.Deposit(ctx, clientID, asset, d(amount)); err != nil {
+			t.Fatalf("deposit: %v", err)
+		}
+	}
+	balance := func(clientID, asset, available, locked string) {
+		t.Helper()
+		accs, err := e.Balances(ctx, clientID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := domain.Account{}
+		for _, a := range accs {
+			if a.Asset == asset {
+				got = a
+			}
+		}
+		if !got.Available.Equal(d(available)) || !got.Locked.Equal(d(locked)) {
+			t.Errorf("%s %s: available %s locked %s, want %s and %s", clientID, asset, got.Available, got.Locked, available, locked)
+		}
+	}
+	submit := func(clientID string, side domain.Side, price, qty string) (*domain.Order, error) {
+		o := &domain.Order{ClientID: clientID, Symbol: "BTC/USD", Side: side, Type: domain.Limit, Price: d(price), Quantity: d(qty)}
+		_, err := e.SubmitOrder(ctx, o)
+		return o, err
+	}
+
+	deposit("buyer", "USD", "1000")
+	deposit("seller", "BTC", "5")
+
+	// a dry run computes the same hold against the available balance, and locks nothing
+	validate := func(price, qty string) error {
+		return e.ValidateOrder(ctx, &domain.Order{ClientID: "buyer", Symbol: "BTC/USD", Side: domain.Buy, Type: domain.Limit, Price: d(price), Quantity: d(qty)})
+	}
+	if err := validate("100", "10"); domain.RejectCodeOf(err) != domain.RejectInsufficientFunds {
+		t.Fatalf("dry run above the balance: %v, want an insufficient funds rejection", err)
+	}
+	if err := validate("100", "9.8"); err != nil {
+		t.Fatalf("dry run within the balance: %v", err)
+	}
+	balance("buyer", "USD", "1000", "0")
+
+	if _, err := submit("buyer", domain.Buy, "100", "10"); domain.RejectCodeOf(err) != domain.RejectInsufficientFunds {
+		t.Fatalf("order above the balance: %v, want an insufficient funds rejection", err)
+	}
+	balance("buyer", "USD", "1000", "0")
+
+	// 2 at 100 plus the 2% fee it could pay as a taker
+	bid, err := submit("buyer", domain.Buy, "100", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance("buyer", "USD", "796", "204")
+
+	// the seller takes 1: the buyer pays 100 and a 1 maker fee from its hold, the seller gets 100 less 2
+	if _, err := submit("seller", domain.Sell, "100", "1"); err != nil {
+		t.Fatal(err)
+	}
+	balance("buyer", "USD", "796", "103")
+	balance("buyer", "BTC", "1", "0")
+	balance("seller", "BTC", "4", "0")
+	balance("seller", "USD", "98", "0")
+
+	if err := e.Withdraw(ctx, "buyer", "USD", d("797")); err == nil {
+		t.Error("withdrew more than the available balance")
+	}
+	if ids, err := e.CancelBySide(ctx, "buyer", "BTC/USD", domain.Buy); err != nil || len(ids) != 1 || ids[0] != bid.ID {
+		t.Fatalf("cancel: %v, %v", ids, err)
+	}
+	balance("buyer", "USD", "899", "0")
+
+	// a resting ask locks its base until it fills
+	if _, err := submit("seller", domain.Sell, "90", "3"); err != nil {
+		t.Fatal(err)
+	}
+	balance("seller", "BTC", "1", "3")
+	if _, err := submit("seller", domain.Sell, "90", "2"); domain.RejectCodeOf(err) != domain.RejectInsufficientFunds {
+		t.Fatalf("ask above the free balance: %v", err)
+	}
+	if _, err := submit("buyer", domain.Buy, "95", "3"); err != nil {
+		t.Fatal(err)
+	}
+	// the buyer paid 270 and a 5.4 taker fee and got back what it held at 95
+	balance("buyer", "USD", "623.6", "0")
+	balance("buyer", "BTC", "4", "0")
+	balance("seller", "BTC", "1", "0")
+	balance("seller", "USD", "365.3", "0")
+}
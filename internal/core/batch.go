@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// SubmitResult is the outcome of one order in a batch
+type SubmitResult struct {
+	Order  *domain.Order
+	Trades []*domain.Trade
+	Err    error
+}
+
+// BatchSubmitOrders validates every order up front and then submits the valid ones.
+// Orders of the same symbol always run in batch order; with parallel set, different symbols run concurrently.
+// Results are returned in input order and one failure does not affect the others.
+func (e *Engine) BatchSubmitOrders(ctx context.Context, orders []*domain.Order, parallel bool) []SubmitResult {
+	results := make([]SubmitResult, len(orders))
+	valid := make([]int, 0, len(orders))
+	for i, o := range orders {
+		results[i].Order = o
+		if err := e.checkOrder(ctx, o); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	run := func(idx []int) {
+		for _, i := range idx {
+			results[i].Trades, results[i].Err = e.SubmitOrder(ctx, orders[i])
+		}
+	}
+
+	if !parallel {
+		run(valid)
+		return results
+	}
+
+	bySymbol := make(map[string][]int)
+	var symbols []string
+	for _, i := range valid {
+		sym := orders[i].Symbol
+		if _, ok := bySymbol[sym]; !ok {
+			symbols = append(symbols, sym)
+		}
+		bySymbol[sym] = append(bySymbol[sym], i)
+	}
+
+	var wg sync.WaitGroup
+	for _, sym := range symbols {
+		wg.Add(1)
+		go func(idx []int) {
+			defer wg.Done()
+			run(idx)
+		}(bySymbol[sym])
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+var errNotCancellable = errors.New("order not found or not OPEN")
+
+// CancelResult is the outcome of one order in a batch cancel
+type CancelResult struct {
+	OrderID string
+	// ClientOrderID is set on the results of orders named by their client order ID
+	ClientOrderID string
+	Cancelled     bool
+	Err           error
+}
+
+// BatchCancelOrders cancels the client's open orders named by order ID or by client order ID, in a
+// single repository call unless the engine is sharded, and reports per-order outcomes: those of the
+// order IDs first, then those of the client order IDs, each in request order. A client order ID names
+// the client's open order with it.
+func (e *Engine) BatchCancelOrders(ctx context.Context, clientID string, orderIDs, clientOrderIDs []string) ([]CancelResult, error) {
+	results := make([]CancelResult, len(orderIDs)+len(clientOrderIDs))
+	ids := make([]string, len(results))
+	valid := make([]string, 0, len(results))
+	for i, id := range orderIDs {
+		results[i].OrderID = id
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			results[i].Err = errors.New("invalid order id")
+			continue
+		}
+		ids[i] = parsed.String()
+		valid = append(valid, ids[i])
+	}
+	for j, clOrdID := range clientOrderIDs {
+		i := len(orderIDs) + j
+		results[i].ClientOrderID = clOrdID
+		o, err := e.repo.LoadOrderByClientOrderID(ctx, clientID, clOrdID)
+		if clOrdID == "" || err != nil {
+			results[i].Err = errNotCancellable
+			continue
+		}
+		results[i].OrderID, ids[i] = o.ID, o.ID
+		valid = append(valid, o.ID)
+	}
+
+	var bySymbol map[string][]string
+	if e.sharded() {
+		// the orders' symbols say which workers the cancels run on; one that does not load is not
+		// the client's, and the repository reports it as not cancellable
+		bySymbol = make(map[string][]string)
+		for _, id := range valid {
+			if o, err := e.repo.LoadOrderByIDForClient(ctx, id, clientID); err == nil {
+				bySymbol[o.Symbol] = append(bySymbol[o.Symbol], id)
+			}
+		}
+	}
+	cancelled, err := e.cancelOnSymbolWorkers(ctx, slices.Sorted(maps.Keys(bySymbol)), func(symbol string) (map[string]string, error) {
+		if symbol == "" {
+			return e.repo.CancelOrders(ctx, clientID, valid)
+		}
+		return e.repo.CancelOrders(ctx, clientID, bySymbol[symbol])
+	})
+	// what was cancelled before a failure is published all the same
+	symbols := make(map[string]struct{})
+	events := make([]*domain.OrderEvent, 0, len(cancelled))
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		sym, ok := cancelled[ids[i]]
+		if !ok {
+			results[i].Err = errNotCancellable
+			continue
+		}
+		results[i].Cancelled = true
+		symbols[sym] = struct{}{}
+		events = append(events, cancelledEvent(ids[i], clientID, sym, "", "cancelled by client"))
+	}
+	for sym := range symbols {
+		e.bookChanged(ctx, sym)
+	}
+	e.emit(ctx, events...)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CancelBySide cancels all of the client's open orders on one side of a symbol and returns their IDs
+func (e *Engine) CancelBySide(ctx context.Context, clientID, symbol string, side domain.Side) ([]string, error) {
+	if symbol == "" {
+		return nil, errors.New("symbol is required")
+	}
+	if side != domain.Buy && side != domain.Sell {
+		return nil, fmt.Errorf("invalid side: %s", side)
+	}
+	return e.CancelAllOrders(ctx, clientID, symbol, side)
+}
+
+// CancelAllOrders cancels all of the client's open orders, optionally only those of symbol and of
+// side ("" = any), in a single repository call unless the engine is sharded, and returns their IDs
+func (e *Engine) CancelAllOrders(ctx context.Context, clientID, symbol string, side domain.Side) ([]string, error) {
+	if clientID == "" {
+		return nil, errors.New("client_id is required")
+	}
+	if side != "" && side != domain.Buy && side != domain.Sell {
+		return nil, fmt.Errorf("invalid side: %s", side)
+	}
+	var symbols []string
+	switch {
+	case !e.sharded():
+	case symbol != "":
+		symbols = []string{symbol}
+	default:
+		var err error
+		if symbols, err = e.repo.ListSymbols(ctx); err != nil {
+			return nil, err
+		}
+	}
+	cancelled, err := e.cancelOnSymbolWorkers(ctx, symbols, func(sym string) (map[string]string, error) {
+		if sym == "" {
+			sym = symbol
+		}
+		return e.repo.CancelOpenOrders(ctx, clientID, sym, side)
+	})
+	// what was cancelled before a failure is published all the same
+	ids := make([]string, 0, len(cancelled))
+	for id := range cancelled {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	touched := make(map[string]struct{})
+	events := make([]*domain.OrderEvent, 0, len(ids))
+	for _, id := range ids {
+		touched[cancelled[id]] = struct{}{}
+		events = append(events, cancelledEvent(id, clientID, cancelled[id], side, "cancelled by client"))
+	}
+	for sym := range touched {
+		e.bookChanged(ctx, sym)
+	}
+	e.emit(ctx, events...)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// cancelOnSymbolWorkers runs a bulk cancel on the worker of each of symbols in turn, so it keeps
+// its place among the symbols' submissions and a shutdown waits for it. An unsharded engine makes
+// one call for every symbol at once, with symbol "". cancel returns cancelled order ID -> symbol;
+// what was cancelled before a call failed is returned along with the error.
+func (e *Engine) cancelOnSymbolWorkers(ctx context.Context, symbols []string, cancel func(symbol string) (map[string]string, error)) (map[string]string, error) {
+	if !e.sharded() {
+		e.ops.start()
+		defer e.ops.done()
+		return cancel("")
+	}
+	out := make(map[string]string)
+	for _, symbol := range symbols {
+		var (
+			cancelled map[string]string
+			err       error
+		)
+		if perr := e.onSymbolWorker(ctx, symbol, func() { cancelled, err = cancel(symbol) }); perr != nil {
+			return out, perr
+		}
+		if err != nil {
+			return out, err
+		}
+		maps.Copy(out, cancelled)
+	}
+	return out, nil
+}
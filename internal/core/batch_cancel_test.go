@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestCancelAllOrdersAcrossSymbols(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	place := func(client, symbol string, side domain.Side, price int64) *domain.Order {
+		t.Helper()
+		o := &domain.Order{ClientID: client, Symbol: symbol, Side: side, Type: domain.Limit,
+			Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(1)}
+		if _, err := e.SubmitOrder(ctx, o); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		return o
+	}
+	btcBid := place("mm", "BTC/USD", domain.Buy, 90)
+	btcAsk := place("mm", "BTC/USD", domain.Sell, 110)
+	ethBid := place("mm", "ETH/USD", domain.Buy, 9)
+	other := place("other", "BTC/USD", domain.Buy, 95)
+
+	events := e.SubscribeOrderEvents(ctx, "mm")
+	defer events.Close()
+
+	ids, err := e.CancelAllOrders(ctx, "mm", "", domain.Buy)
+	if err != nil {
+		t.Fatalf("cancel bids: %v", err)
+	}
+	if sortedList(ids) != sortedList([]string{btcBid.ID, ethBid.ID}) {
+		t.Fatalf("cancelled %v, want the bids of both symbols", ids)
+	}
+	if ids, err = e.CancelAllOrders(ctx, "mm", "", ""); err != nil || len(ids) != 1 || ids[0] != btcAsk.ID {
+		t.Fatalf("cancel the rest: %v, %v; want only %s", ids, err, btcAsk.ID)
+	}
+	if len(events.C) != 3 {
+		t.Errorf("%d events, want a CANCELED for each order", len(events.C))
+	}
+	if o, _ := e.GetOrder(ctx, other.ID); o == nil || o.Status != domain.Open {
+		t.Errorf("another client's order read back as %+v", o)
+	}
+	ob, err := e.GetOrderbook(ctx, "ETH/USD")
+	if err != nil || len(ob.Bids) != 0 {
+		t.Errorf("ETH/USD book %+v, %v; want it empty", ob, err)
+	}
+	if _, err := e.CancelAllOrders(ctx, "mm", "", "BOTH"); err == nil {
+		t.Error("cancelled with an invalid side")
+	}
+}
+
+func TestBatchCancelByClientOrderID(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	place := func(client, clOrdID string) *domain.Order {
+		t.Helper()
+		o := &domain.Order{ClientID: client, ClientOrderID: clOrdID, Symbol: "BTC/USD", Side: domain.Buy, Type: domain.Limit,
+			Price: decimal.NewFromInt(90), Quantity: decimal.NewFromInt(1)}
+		if _, err := e.SubmitOrder(ctx, o); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		return o
+	}
+	byID := place("mm", "")
+	byClOrdID := place("mm", "quote-1")
+	place("other", "quote-2")
+
+	results, err := e.BatchCancelOrders(ctx, "mm", []string{byID.ID}, []string{"quote-1", "quote-2", ""})
+	if err != nil {
+		t.Fatalf("batch cancel: %v", err)
+	}
+	want := []CancelResult{
+		{OrderID: byID.ID, Cancelled: true},
+		{OrderID: byClOrdID.ID, ClientOrderID: "quote-1", Cancelled: true},
+		{ClientOrderID: "quote-2", Err: errNotCancellable}, // another client's
+		{Err: errNotCancellable},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("%d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		if results[i] != w {
+			t.Errorf("result %d: %+v, want %+v", i, results[i], w)
+		}
+	}
+	// a done order is no longer cancellable by its client order ID
+	if results, _ := e.BatchCancelOrders(ctx, "mm", nil, []string{"quote-1"}); results[0].Cancelled {
+		t.Errorf("cancelled quote-1 twice: %+v", results[0])
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/shopspring/decimal"
+)
+
+// Amend is the new price and quantity of one order in a batch modify
+type Amend struct {
+	OrderID  string
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
+}
+
+// ModifyResult is the outcome of one order in a batch modify
+type ModifyResult struct {
+	OrderID  string
+	Modified bool
+	// Trades are those of the amended order crossing the book
+	Trades []*domain.Trade
+	Err    error
+}
+
+// BatchModifyOrders amends the client's open orders and reports per-order outcomes in input order.
+// Each amend is a cancel-replace like ModifyOrder and matches at once if it crosses the book. An
+// order that cannot be amended fails alone; the book of each symbol touched is refreshed and
+// published once, after its amends are stored, so a ladder of quotes is repriced in one step. The
+// batch is a single transaction unless the engine is sharded, where each symbol's amends commit
+// together on its worker, in the order of the symbols' first amends. A storage failure fails the
+// batch with nothing amended on the symbol it hit.
+func (e *Engine) BatchModifyOrders(ctx context.Context, clientID string, amends []Amend) ([]ModifyResult, error) {
+	results := make([]ModifyResult, len(amends))
+	valid := make([]int, 0, len(amends))
+	for i, a := range amends {
+		results[i] = ModifyResult{OrderID: a.OrderID}
+		if _, err := uuid.Parse(a.OrderID); err != nil {
+			results[i].Err = errors.New("invalid order id")
+			continue
+		}
+		valid = append(valid, i)
+	}
+	if !e.sharded() {
+		e.ops.start()
+		defer e.ops.done()
+		if err := e.modifyBatch(ctx, clientID, amends, valid, results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	// the orders' symbols say which workers the amends run on
+	var symbols []string
+	bySymbol := make(map[string][]int)
+	for _, i := range valid {
+		o, err := e.repo.LoadOrderByIDForClient(ctx, amends[i].OrderID, clientID)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if _, ok := bySymbol[o.Symbol]; !ok {
+			symbols = append(symbols, o.Symbol)
+		}
+		bySymbol[o.Symbol] = append(bySymbol[o.Symbol], i)
+	}
+	for _, symbol := range symbols {
+		var err error
+		if perr := e.onSymbolWorker(ctx, symbol, func() { err = e.modifyBatch(ctx, clientID, amends, bySymbol[symbol], results) }); perr != nil {
+			return nil, perr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// modifyBatch applies the amends at the indexes in one transaction, filling in their results, then
+// publishes each symbol touched and fires the stops its trades crossed
+func (e *Engine) modifyBatch(ctx context.Context, clientID string, amends []Amend, indexes []int, results []ModifyResult) error {
+	var (
+		symbols []string
+		seen    map[string]bool
+		events  []*domain.OrderEvent
+		trades  map[string][]*domain.Trade
+	)
+	err := withTx(ctx, e.repo, func(tx port.Tx) error {
+		// a retried transaction amends from scratch
+		symbols, seen, events, trades = nil, make(map[string]bool), nil, make(map[string][]*domain.Trade)
+		for _, i := range indexes {
+			a := amends[i]
+			results[i] = ModifyResult{OrderID: a.OrderID}
+			if err := checkAmend(a); err != nil {
+				results[i].Err = err
+				continue
+			}
+			o, err := tx.LoadOrderByIDForClient(ctx, a.OrderID, clientID)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			if !o.Status.Resting() {
+				results[i].Err = errors.New("cannot modify non-open order")
+				continue
+			}
+			if err := e.checkCancelOnly(ctx, o.Symbol); err != nil {
+				results[i].Err = err
+				continue
+			}
+			if err := e.normalize(o.Symbol, o.Side, &a.Price, &a.Quantity); err != nil {
+				results[i].Err = err
+				continue
+			}
+			if err := e.checkInstrument(ctx, o.Symbol, a.Price, decimal.Zero, a.Quantity); err != nil {
+				results[i].Err = err
+				continue
+			}
+			if err := amend(o, a.Price, a.Quantity); err != nil {
+				results[i].Err = err
+				continue
+			}
+			if err := e.relockFunds(ctx, tx, o); err != nil {
+				if !domain.IsReject(err) {
+					return err
+				}
+				results[i].Err = err
+				continue
+			}
+			executed, evs, err := e.replace(ctx, tx, o)
+			if err != nil {
+				return err
+			}
+			results[i].Modified, results[i].Trades = true, executed
+			events = append(events, evs...)
+			trades[o.Symbol] = append(trades[o.Symbol], executed...)
+			if !seen[o.Symbol] {
+				seen[o.Symbol] = true
+				symbols = append(symbols, o.Symbol)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bySymbol := make(map[string][]*domain.OrderEvent, len(symbols))
+	for _, ev := range events {
+		bySymbol[ev.Symbol] = append(bySymbol[ev.Symbol], ev)
+	}
+	for _, sym := range symbols {
+		e.publishMatch(ctx, sym, trades[sym], bySymbol[sym])
+	}
+	for _, sym := range symbols {
+		e.fireTriggers(ctx, sym, trades[sym])
+	}
+	return nil
+}
+
+func checkAmend(a Amend) error {
+	if a.Price.LessThanOrEqual(decimal.Zero) {
+		return domain.Reject(domain.RejectInvalidPrice, "price must be > 0")
+	}
+	if a.Quantity.LessThanOrEqual(decimal.Zero) {
+		return domain.Reject(domain.RejectInvalidQuantity, "quantity must be > 0")
+	}
+	return nil
+}
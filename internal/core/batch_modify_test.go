@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestBatchModifyOrders(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"one transaction", nil},
+		{"symbol loops", []Option{WithSymbolLoops()}},
+		{"worker pool", []Option{WithWorkerPool(2)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) { testBatchModifyOrders(t, tc.opts...) })
+	}
+}
+
+func testBatchModifyOrders(t *testing.T, opts ...Option) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), &mapCache{books: make(map[string]*domain.OrderbookSnapshot)}, opts...)
+	defer e.StopSymbolLoops()
+	ids := []string{uuid.NewString(), uuid.NewString()}
+	for _, id := range ids {
+		seedSell(t, e, id)
+	}
+	books := e.SubscribeOrderbook(ctx, "BTC/USD")
+	defer books.Close()
+
+	results, err := e.BatchModifyOrders(ctx, "maker", []Amend{
+		{OrderID: ids[0], Price: decimal.NewFromInt(105), Quantity: decimal.NewFromInt(2)},
+		{OrderID: ids[1], Price: decimal.NewFromInt(106), Quantity: decimal.NewFromInt(3)},
+		{OrderID: uuid.NewString(), Price: decimal.NewFromInt(107), Quantity: decimal.NewFromInt(1)},
+		{OrderID: ids[0], Price: decimal.Zero, Quantity: decimal.NewFromInt(1)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{true, true, false, false} {
+		if results[i].Modified != want || (results[i].Err == nil) != want {
+			t.Errorf("result %d = %+v, want modified %v", i, results[i], want)
+		}
+	}
+
+	ob, err := e.GetOrderbook(ctx, "BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ob.Asks) != 2 || !ob.Asks[0].Price.Equal(decimal.NewFromInt(105)) || !ob.Asks[1].Remaining.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("asks after the batch = %+v", ob.Asks)
+	}
+	if n := len(books.C); n != 1 {
+		t.Errorf("the book was published %d times, want once", n)
+	}
+}
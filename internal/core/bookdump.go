@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// DumpOrderbook reads the ctx tenant's resting orders of symbol from the repository, bypassing the
+// cached snapshot, and lays them out level by level in price-time priority; pending stops follow,
+// oldest first
+func (e *Engine) DumpOrderbook(ctx context.Context, symbol string) (*domain.BookDump, error) {
+	orders, err := e.repo.LoadOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	stops, err := e.repo.LoadTriggerOrders(ctx, symbol, nil)
+	if err != nil {
+		return nil, err
+	}
+	triggers := make([]domain.DumpOrder, len(stops))
+	for i, o := range stops {
+		triggers[i] = domain.DumpOrder{Order: *o, Position: i + 1, Ahead: decimal.Zero, Display: decimal.Zero, Hidden: o.Remaining}
+	}
+	var bids, asks []*domain.Order
+	for _, o := range orders {
+		if o.Side == domain.Buy {
+			bids = append(bids, o)
+		} else {
+			asks = append(asks, o)
+		}
+	}
+	return &domain.BookDump{
+		Symbol:   symbol,
+		At:       time.Now().UTC(),
+		Bids:     dumpLevels(bids, true),
+		Asks:     dumpLevels(asks, false),
+		Triggers: triggers,
+	}, nil
+}
+
+// dumpLevels groups one side's orders into levels, best price first
+func dumpLevels(orders []*domain.Order, desc bool) []domain.DumpLevel {
+	sort.Slice(orders, func(i, j int) bool {
+		a, b := orders[i], orders[j]
+		if !a.Price.Equal(b.Price) {
+			return a.Price.GreaterThan(b.Price) == desc
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	var levels []domain.DumpLevel
+	for _, o := range orders {
+		if len(levels) == 0 || !levels[len(levels)-1].Price.Equal(o.Price) {
+			levels = append(levels, domain.DumpLevel{Price: o.Price})
+		}
+		l := &levels[len(levels)-1]
+		// every order is shown in full; there are no reserve orders to hide part of it
+		l.Orders = append(l.Orders, domain.DumpOrder{
+			Order: *o, Position: len(l.Orders) + 1, Ahead: l.Remaining, Display: o.Remaining, Hidden: decimal.Zero,
+		})
+		l.Remaining = l.Remaining.Add(o.Remaining)
+		l.Display = l.Display.Add(o.Remaining)
+	}
+	return levels
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestDumpOrderbook(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	for _, o := range []struct {
+		id, client string
+		side       domain.Side
+		price, qty int64
+	}{
+		{"b1", "a", domain.Buy, 99, 1},
+		{"b2", "b", domain.Buy, 100, 2},
+		{"b3", "c", domain.Buy, 99, 3},
+		{"s1", "a", domain.Sell, 102, 4},
+		{"s2", "b", domain.Sell, 101, 5},
+	} {
+		if _, err := e.SubmitOrder(ctx, &domain.Order{ID: o.id, ClientID: o.client, Symbol: "BTC/USD", Side: o.side,
+			Type: domain.Limit, Price: decimal.NewFromInt(o.price), Quantity: decimal.NewFromInt(o.qty)}); err != nil {
+			t.Fatalf("submit %s: %v", o.id, err)
+		}
+	}
+
+	d, err := e.DumpOrderbook(ctx, "BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Bids) != 2 || len(d.Asks) != 2 || !d.Bids[0].Price.Equal(decimal.NewFromInt(100)) || !d.Asks[0].Price.Equal(decimal.NewFromInt(101)) {
+		t.Fatalf("levels %+v / %+v, want bids 100, 99 and asks 101, 102", d.Bids, d.Asks)
+	}
+	level := d.Bids[1]
+	if len(level.Orders) != 2 || level.Orders[0].Order.ID != "b1" || level.Orders[1].Order.ID != "b3" {
+		t.Fatalf("queue at 99 is %+v, want b1 then b3", level.Orders)
+	}
+	if !level.Remaining.Equal(decimal.NewFromInt(4)) || !level.Display.Equal(level.Remaining) || !level.Hidden.IsZero() {
+		t.Errorf("level 99 remaining %s display %s hidden %s", level.Remaining, level.Display, level.Hidden)
+	}
+	if second := level.Orders[1]; second.Position != 2 || !second.Ahead.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("b3 at position %d with %s ahead, want 2 with 1", second.Position, second.Ahead)
+	}
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// bookChanges remembers the sequence number of the latest change to each book, by tenant-scoped symbol
+type bookChanges struct {
+	mu   sync.Mutex
+	last map[string]uint64
+}
+
+// bookChanged refreshes the cached book after a mutation and publishes it to book subscribers
+func (e *Engine) bookChanged(ctx context.Context, symbol string) {
+	// the change is committed: a book read from now on reflects it
+	e.changes.mu.Lock()
+	e.changes.last[tenant.Scope(ctx, symbol)] = sequence.next(time.Now())
+	e.changes.mu.Unlock()
+	// readers arriving from now on must not join a load that may predate the change
+	e.snapshotLoads.Forget(tenant.Scope(ctx, symbol))
+	snap := updateCache(ctx, e.repo, e.cache, symbol)
+	if snap != nil {
+		e.bookCached(ctx, symbol)
+		e.books.Publish(tenant.Scope(ctx, symbol), snap)
+		e.books.Publish(tenant.Scope(ctx, AllSymbols), snap)
+	}
+	e.topChanged(ctx, symbol, snap)
+}
+
+// SubscribeOrderbook subscribes to the ctx tenant's books, or all of them with AllSymbols, receiving
+// the full book after every change. Snapshots are shared between subscribers and must not be modified.
+func (e *Engine) SubscribeOrderbook(ctx context.Context, symbols ...string) *SymbolSubscription[*domain.OrderbookSnapshot] {
+	return subscribeSymbols(ctx, e.books, symbols)
+}
+
+// GetOrderbookConsistent serves the book reflecting at least every change this engine made to it
+// before the call, such as the caller's own order just submitted, cancelled or modified: the cached
+// book if it is recent enough, otherwise one read from the repository, which then replaces it in the
+// cache. Changes made through other engine instances are not known to it.
+func (e *Engine) GetOrderbookConsistent(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	e.changes.mu.Lock()
+	changed := e.changes.last[tenant.Scope(ctx, symbol)]
+	e.changes.mu.Unlock()
+	if e.cache != nil {
+		if ob, err := e.cache.GetOrderbook(ctx, symbol); err == nil && ob != nil && ob.Sequence > changed {
+			return ob, nil
+		}
+	}
+	ob, err := loadStamped(ctx, e.repo, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if e.cache != nil {
+		_ = e.cache.SetOrderbook(ctx, symbol, ob.DeepCopy())
+	}
+	return ob, nil
+}
@@ -0,0 +1,17 @@
+package core
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// ExportOrders streams the ctx tenant's orders matching f to fn, oldest first, straight from the database
+func (e *Engine) ExportOrders(ctx context.Context, f domain.ExportFilter, fn func(*domain.Order) error) error {
+	return e.repo.ScanOrders(ctx, f, fn)
+}
+
+// ExportTrades streams the ctx tenant's trades matching f to fn, oldest first
+func (e *Engine) ExportTrades(ctx context.Context, f domain.ExportFilter, fn func(*domain.Trade) error) error {
+	return e.repo.ScanTrades(ctx, f, fn)
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// cacheRefresh remembers when each refreshed-ahead book was last cached, by tenant-scoped symbol
+type cacheRefresh struct {
+	policies map[string]domain.CachePolicy
+
+	mu     sync.Mutex
+	cached map[string]time.Time
+}
+
+// WithCachePolicies sets the symbols' cache policies; books of those refreshed ahead are reloaded by
+// RunCacheRefresh before they expire. The cache applies the TTLs itself.
+func WithCachePolicies(policies map[string]domain.CachePolicy) Option {
+	return func(e *Engine) {
+		e.refresh = &cacheRefresh{policies: policies, cached: make(map[string]time.Time)}
+	}
+}
+
+// bookCached records that the ctx tenant's book of symbol was cached now
+func (e *Engine) bookCached(ctx context.Context, symbol string) {
+	if e.refresh == nil || e.refresh.policies[symbol].RefreshEvery() == 0 {
+		return
+	}
+	e.refresh.mu.Lock()
+	e.refresh.cached[tenant.Scope(ctx, symbol)] = time.Now()
+	e.refresh.mu.Unlock()
+}
+
+// refreshDue recaches the ctx tenant's books whose refresh is due and returns the symbols refreshed
+func (e *Engine) refreshDue(ctx context.Context) ([]string, error) {
+	cfg, configured := e.tenantConfig(ctx)
+	symbols := make([]string, 0, len(e.refresh.policies))
+	for symbol := range e.refresh.policies {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var done []string
+	now := time.Now()
+	for _, symbol := range symbols {
+		every := e.refresh.policies[symbol].RefreshEvery()
+		if every == 0 || configured && !cfg.Lists(symbol) {
+			continue
+		}
+		e.refresh.mu.Lock()
+		last := e.refresh.cached[tenant.Scope(ctx, symbol)]
+		e.refresh.mu.Unlock()
+		if now.Sub(last) < every {
+			continue
+		}
+		if updateCache(ctx, e.repo, e.cache, symbol) == nil {
+			return done, errors.New("refreshing the cached book of " + symbol + " failed")
+		}
+		e.bookCached(ctx, symbol)
+		done = append(done, symbol)
+	}
+	return done, nil
+}
+
+// RunCacheRefresh recaches the books of symbols refreshed ahead, in the default and every configured
+// tenant, each interval until ctx is done, and passes each tenant's outcome to report if it is not
+// nil. The interval should be well below the shortest RefreshAhead.
+func (e *Engine) RunCacheRefresh(ctx context.Context, interval time.Duration, report func(tenantID string, refreshed []string, err error)) {
+	if e.refresh == nil || e.cache == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, id := range e.tenantIDs() {
+			refreshed, err := e.refreshDue(tenant.With(ctx, id))
+			if report != nil && (err != nil || len(refreshed) > 0) {
+				report(id, refreshed, err)
+			}
+		}
+	}
+}
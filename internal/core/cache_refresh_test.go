@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+func TestCacheRefreshAhead(t *testing.T) {
+	cache := &mapCache{books: make(map[string]*domain.OrderbookSnapshot)}
+	e := NewEngine(memory.NewRepository(), cache, WithCachePolicies(map[string]domain.CachePolicy{
+		"BTC/USD": {TTL: 100 * time.Millisecond, RefreshAhead: 60 * time.Millisecond},
+		"XYZ/USD": {TTL: 100 * time.Millisecond}, // illiquid: short-lived but not worth reloading
+	}))
+	seedSell(t, e, "s1")
+
+	var (
+		mu        sync.Mutex
+		refreshes = make(map[string]int)
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	e.RunCacheRefresh(ctx, 5*time.Millisecond, func(tenantID string, refreshed []string, err error) {
+		if err != nil {
+			t.Errorf("refresh %s: %v", tenantID, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, s := range refreshed {
+			refreshes[s]++
+		}
+	})
+
+	// the book cached by the seed is due every 40ms: about three times in 150ms
+	if n := refreshes["BTC/USD"]; n < 2 || n > 5 {
+		t.Errorf("BTC/USD refreshed %d times, want about 3", n)
+	}
+	if n := refreshes["XYZ/USD"]; n != 0 {
+		t.Errorf("XYZ/USD refreshed %d times, want none", n)
+	}
+	if cache.books["BTC/USD"] == nil {
+		t.Error("refreshed book not cached")
+	}
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// Candles returned by GetCandles
+const (
+	DefaultCandleLimit = 500
+	MaxCandleLimit     = 1000
+)
+
+var errCandlesDisabled = errors.New("candles are not enabled")
+
+// WithCandles makes the engine keep OHLCV bars of every interval in domain.CandleIntervals in the
+// repository, merging each batch of executed trades into its bars, and serve them from GetCandles
+func WithCandles() Option {
+	return func(e *Engine) { e.candles = true }
+}
+
+// recordCandles merges committed trades into their bars. Like order histories the bars are written
+// after the commit, so a failed write leaves trades out of them but never loses a trade.
+func (e *Engine) recordCandles(ctx context.Context, trades []*domain.Trade) {
+	_ = e.repo.MergeCandles(ctx, domain.CandlesOf(trades))
+}
+
+// GetCandles returns the last limit bars of symbol at interval starting in [from, to), oldest first.
+// A zero from or to leaves that end open and limit 0 means DefaultCandleLimit. Intervals without
+// trades have no bar.
+func (e *Engine) GetCandles(ctx context.Context, symbol string, interval domain.CandleInterval, from, to time.Time, limit int) ([]domain.Candle, error) {
+	switch {
+	case !e.candles:
+		return nil, errCandlesDisabled
+	case interval.Duration() == 0:
+		return nil, fmt.Errorf("unknown interval %q", interval)
+	case !from.IsZero() && !to.IsZero() && !from.Before(to):
+		return nil, errors.New("from must be before to")
+	}
+	if limit <= 0 {
+		limit = DefaultCandleLimit
+	}
+	return e.repo.LoadCandles(ctx, symbol, interval, from, to, min(limit, MaxCandleLimit))
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestCandlesFollowExecutedTrades(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithCandles())
+	submit := func(id string, side domain.Side, price, qty int64) {
+		t.Helper()
+		o := &domain.Order{ID: id, ClientID: "c-" + id, Symbol: "BTC/USD", Side: side, Type: domain.Limit,
+			Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(qty)}
+		if _, err := e.SubmitOrder(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	submit("s1", domain.Sell, 100, 1)
+	submit("s2", domain.Sell, 103, 1)
+	submit("s3", domain.Sell, 98, 2)
+	submit("b1", domain.Buy, 103, 4) // 98 x 2, 100 x 1, 103 x 1
+
+	for _, iv := range domain.CandleIntervals {
+		bars, err := e.GetCandles(ctx, "BTC/USD", iv, time.Time{}, time.Time{}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the trades may straddle a bar boundary, so fold whatever bars they fell in
+		var all domain.Candle
+		for _, b := range bars {
+			if !b.Start.Equal(b.Start.Truncate(iv.Duration())) {
+				t.Errorf("%s bar starts at %s", iv, b.Start)
+			}
+			all.Merge(b)
+		}
+		if !all.Open.Equal(decimal.NewFromInt(98)) || !all.Close.Equal(decimal.NewFromInt(103)) ||
+			!all.High.Equal(decimal.NewFromInt(103)) || !all.Low.Equal(decimal.NewFromInt(98)) {
+			t.Errorf("%s OHLC = %s %s %s %s, want 98 103 98 103", iv, all.Open, all.High, all.Low, all.Close)
+		}
+		if all.Trades != 3 || !all.Volume.Equal(decimal.NewFromInt(4)) || !all.Notional.Equal(decimal.NewFromInt(399)) {
+			t.Errorf("%s: %d trades, volume %s, notional %s; want 3, 4, 399", iv, all.Trades, all.Volume, all.Notional)
+		}
+	}
+
+	if _, err := e.GetCandles(ctx, "BTC/USD", "2m", time.Time{}, time.Time{}, 0); err == nil {
+		t.Error("unknown interval accepted")
+	}
+	if _, err := NewEngine(memory.NewRepository(), nil).GetCandles(ctx, "BTC/USD", domain.Candle1m, time.Time{}, time.Time{}, 0); err == nil {
+		t.Error("candles served without WithCandles")
+	}
+}
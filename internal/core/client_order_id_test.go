@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestClientOrderIDs(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	order := func(client, clOrdID string) *domain.Order {
+		return &domain.Order{ClientID: client, ClientOrderID: clOrdID, Symbol: "BTC/USD", Side: domain.Buy, Type: domain.Limit,
+			Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)}
+	}
+
+	first := order("c1", "abc-1")
+	if _, err := e.SubmitOrder(ctx, first); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if _, err := e.SubmitOrder(ctx, order("c1", "abc-1")); domain.RejectCodeOf(err) != domain.RejectDuplicateClientOrderID {
+		t.Fatalf("reused while open: %v", err)
+	}
+	if _, err := e.SubmitOrder(ctx, order("c2", "abc-1")); err != nil {
+		t.Fatalf("another client's ID: %v", err)
+	}
+	if _, err := e.SubmitOrder(ctx, order("c1", "bad id")); domain.RejectCodeOf(err) != domain.RejectInvalidOrder {
+		t.Fatalf("ID with a space: %v", err)
+	}
+
+	got, err := e.GetOrderByClientOrderID(ctx, "c1", "abc-1")
+	if err != nil || got.ID != first.ID {
+		t.Fatalf("lookup: %+v, %v; want %s", got, err, first.ID)
+	}
+	if _, err := e.CancelOrder(ctx, first.ID, "c1"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	second := order("c1", "abc-1")
+	if _, err := e.SubmitOrder(ctx, second); err != nil {
+		t.Fatalf("reused after cancel: %v", err)
+	}
+	if got, err := e.GetOrderByClientOrderID(ctx, "c1", "abc-1"); err != nil || got.ID != second.ID {
+		t.Fatalf("lookup prefers the open order: %+v, %v; want %s", got, err, second.ID)
+	}
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+func TestGetOrderbookConsistentSkipsStaleCache(t *testing.T) {
+	ctx := context.Background()
+	cache := &mapCache{books: make(map[string]*domain.OrderbookSnapshot)}
+	e := NewEngine(memory.NewRepository(), cache)
+	seedSell(t, e, "s1")
+
+	fresh := cache.books["BTC/USD"]
+	if fresh == nil || fresh.Sequence == 0 {
+		t.Fatalf("book cached after the change = %+v", fresh)
+	}
+	if ob, err := e.GetOrderbookConsistent(ctx, "BTC/USD"); err != nil || ob.Sequence != fresh.Sequence {
+		t.Fatalf("the book refreshed after the change was not served from the cache: %+v, %v", ob, err)
+	}
+
+	// a load that started before the change overwrote the cache with the empty book
+	cache.books["BTC/USD"] = &domain.OrderbookSnapshot{Symbol: "BTC/USD", Sequence: 1}
+	if ob, _ := e.GetOrderbook(ctx, "BTC/USD"); len(ob.Asks) != 0 {
+		t.Fatal("GetOrderbook did not serve the cached book")
+	}
+	ob, err := e.GetOrderbookConsistent(ctx, "BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ob.Asks) != 1 || ob.Sequence <= fresh.Sequence {
+		t.Errorf("consistent read served %+v", ob)
+	}
+	if len(cache.books["BTC/USD"].Asks) != 1 {
+		t.Error("the fresh book did not replace the stale one in the cache")
+	}
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// delistings caches each tenant's delistings, loaded on first use and refreshed by RunDelistings,
+// so order checks do not query the database
+type delistings struct {
+	mu       sync.RWMutex
+	byTenant map[string]map[string]domain.Delisting
+}
+
+func newDelistings() *delistings {
+	return &delistings{byTenant: make(map[string]map[string]domain.Delisting)}
+}
+
+func (e *Engine) loadDelistings(ctx context.Context) (map[string]domain.Delisting, error) {
+	list, err := e.repo.LoadDelistings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]domain.Delisting, len(list))
+	for _, d := range list {
+		m[d.Symbol] = d
+	}
+	e.delistings.mu.Lock()
+	e.delistings.byTenant[tenant.From(ctx)] = m
+	e.delistings.mu.Unlock()
+	return m, nil
+}
+
+func (e *Engine) tenantDelistings(ctx context.Context) (map[string]domain.Delisting, error) {
+	e.delistings.mu.RLock()
+	m, ok := e.delistings.byTenant[tenant.From(ctx)]
+	e.delistings.mu.RUnlock()
+	if ok {
+		return m, nil
+	}
+	return e.loadDelistings(ctx)
+}
+
+// checkListed rejects orders for symbols halted or delisted in the ctx tenant
+func (e *Engine) checkListed(ctx context.Context, symbol string) error {
+	m, err := e.tenantDelistings(ctx)
+	if err != nil {
+		return err
+	}
+	d, ok := m[symbol]
+	if !ok {
+		return nil
+	}
+	switch d.Phase(time.Now()) {
+	case domain.DelistHalted:
+		return domain.Reject(domain.RejectSymbolHalted, "symbol %s is halted ahead of its delisting at %s", symbol, d.DelistAt.UTC().Format(time.RFC3339))
+	case domain.Delisted:
+		return domain.Reject(domain.RejectSymbolDelisted, "symbol %s is delisted", symbol)
+	}
+	return nil
+}
+
+// AnnounceDelisting schedules the symbol's delisting in the ctx tenant: new orders are rejected from
+// haltAt (now if zero) and resting orders are cancelled at delistAt
+func (e *Engine) AnnounceDelisting(ctx context.Context, symbol string, haltAt, delistAt time.Time, reason string) (domain.Delisting, error) {
+	now := time.Now().UTC()
+	if haltAt.IsZero() {
+		haltAt = now
+	}
+	switch {
+	case symbol == "":
+		return domain.Delisting{}, errors.New("symbol is required")
+	case !delistAt.After(now):
+		return domain.Delisting{}, errors.New("delist_at must be in the future")
+	case haltAt.After(delistAt):
+		return domain.Delisting{}, errors.New("halt_at must not be after delist_at")
+	}
+	d := domain.Delisting{Symbol: symbol, Reason: reason, AnnouncedAt: now, HaltAt: haltAt.UTC(), DelistAt: delistAt.UTC()}
+	if err := e.repo.SaveDelisting(ctx, d); err != nil {
+		return domain.Delisting{}, err
+	}
+	_, err := e.loadDelistings(ctx)
+	return d, err
+}
+
+// WithdrawDelisting removes the symbol's delisting: a pending one is called off, a delisted symbol
+// is listed again with an empty book
+func (e *Engine) WithdrawDelisting(ctx context.Context, symbol string) error {
+	if err := e.repo.DeleteDelisting(ctx, symbol); err != nil {
+		return err
+	}
+	_, err := e.loadDelistings(ctx)
+	return err
+}
+
+// Delistings returns the ctx tenant's delistings, soonest first
+func (e *Engine) Delistings(ctx context.Context) ([]domain.Delisting, error) {
+	return e.repo.LoadDelistings(ctx)
+}
+
+// completeDueDelistings cancels the resting orders of every ctx tenant delisting that is due,
+// notifying their owners, and returns the symbols delisted
+func (e *Engine) completeDueDelistings(ctx context.Context) ([]string, error) {
+	m, err := e.loadDelistings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []string
+	now := time.Now().UTC()
+	for _, d := range m {
+		if !d.Due(now) {
+			continue
+		}
+		cancelled, err := e.repo.CompleteDelisting(ctx, d.Symbol, now)
+		if err != nil {
+			return done, err
+		}
+		done = append(done, d.Symbol)
+		e.bookChanged(ctx, d.Symbol)
+		events := make([]*domain.OrderEvent, 0, len(cancelled))
+		for _, o := range cancelled {
+			ev := newEvent(o, domain.ExecCanceled)
+			ev.Reason, ev.Cause = "symbol delisted", domain.CauseDelisted
+			events = append(events, ev)
+		}
+		e.emit(ctx, events...)
+	}
+	if len(done) > 0 {
+		_, err = e.loadDelistings(ctx)
+	}
+	return done, err
+}
+
+// RunDelistings carries out due delistings of the default and every configured tenant each interval
+// until ctx is done, refreshing the cached schedule on the way, and passes each tenant's outcome to
+// report if it is not nil
+func (e *Engine) RunDelistings(ctx context.Context, interval time.Duration, report func(tenantID string, delisted []string, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, id := range e.tenantIDs() {
+			delisted, err := e.completeDueDelistings(tenant.With(ctx, id))
+			if report != nil && (err != nil || len(delisted) > 0) {
+				report(id, delisted, err)
+			}
+		}
+	}
+}
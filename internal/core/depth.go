@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// Depth levels per side returned by GetDepth
+const (
+	DefaultDepthLevels = 20
+	MaxDepthLevels     = 500
+)
+
+var errInvalidStep = errors.New("step must be >= 0")
+
+// GetDepth returns up to levels price levels of each side of the book, merging the orders of a price,
+// or of a step-wide bucket when step is positive. Levels of 0 means DefaultDepthLevels.
+func (e *Engine) GetDepth(ctx context.Context, symbol string, levels int, step decimal.Decimal) (*domain.Depth, error) {
+	if step.IsNegative() {
+		return nil, errInvalidStep
+	}
+	if levels <= 0 {
+		levels = DefaultDepthLevels
+	}
+	levels = min(levels, MaxDepthLevels)
+	ob, err := e.GetOrderbook(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Depth{
+		Symbol:    ob.Symbol,
+		Step:      step,
+		Bids:      aggregate(ob.Bids, levels, step, false),
+		Asks:      aggregate(ob.Asks, levels, step, true),
+		Timestamp: ob.Timestamp,
+		Sequence:  ob.Sequence,
+	}, nil
+}
+
+// aggregate merges one side of the book, in price priority, into at most n levels. Prices are
+// bucketed to multiples of step, rounding asks up and bids down.
+func aggregate(orders []domain.Order, n int, step decimal.Decimal, up bool) []domain.DepthLevel {
+	out := make([]domain.DepthLevel, 0, min(n, len(orders)))
+	for _, o := range orders {
+		if o.Remaining.Sign() <= 0 {
+			continue
+		}
+		price := o.Price
+		if step.IsPositive() {
+			buckets := price.Div(step)
+			if up {
+				buckets = buckets.Ceil()
+			} else {
+				buckets = buckets.Floor()
+			}
+			price = buckets.Mul(step)
+		}
+		if k := len(out); k > 0 && out[k-1].Price.Equal(price) {
+			out[k-1].Quantity = out[k-1].Quantity.Add(o.Remaining)
+			out[k-1].Orders++
+			continue
+		}
+		if len(out) == n {
+			break
+		}
+		out = append(out, domain.DepthLevel{Price: price, Quantity: o.Remaining, Orders: 1})
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestGetDepthAggregatesAndBuckets(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	place := func(id string, side domain.Side, price string, qty int64) {
+		t.Helper()
+		o := &domain.Order{ID: id, ClientID: "c-" + id, Symbol: "BTC/USD", Side: side, Type: domain.Limit,
+			Price: decimal.RequireFromString(price), Quantity: decimal.NewFromInt(qty)}
+		if _, err := e.SubmitOrder(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	place("b1", domain.Buy, "99.8", 1)
+	place("b2", domain.Buy, "99.6", 2)
+	place("b3", domain.Buy, "99.4", 4)
+	place("a1", domain.Sell, "100.2", 1)
+	place("a2", domain.Sell, "100.2", 3)
+	place("a3", domain.Sell, "101", 5)
+
+	type level struct {
+		price, qty string
+		orders     int
+	}
+	check := func(name string, got []domain.DepthLevel, want []level) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d levels %+v, want %d", name, len(got), got, len(want))
+		}
+		for i, w := range want {
+			g := got[i]
+			if !g.Price.Equal(decimal.RequireFromString(w.price)) || !g.Quantity.Equal(decimal.RequireFromString(w.qty)) || g.Orders != w.orders {
+				t.Fatalf("%s[%d]: got %s x %s (%d orders), want %s x %s (%d)", name, i, g.Price, g.Quantity, g.Orders, w.price, w.qty, w.orders)
+			}
+		}
+	}
+
+	d, err := e.GetDepth(ctx, "BTC/USD", 0, decimal.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("exact bids", d.Bids, []level{{"99.8", "1", 1}, {"99.6", "2", 1}, {"99.4", "4", 1}})
+	check("exact asks", d.Asks, []level{{"100.2", "4", 2}, {"101", "5", 1}})
+
+	// bids round down and asks up, so a bucket never quotes better than its orders
+	d, err = e.GetDepth(ctx, "BTC/USD", 0, decimal.RequireFromString("0.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("bucketed bids", d.Bids, []level{{"99.5", "3", 2}, {"99", "4", 1}})
+	check("bucketed asks", d.Asks, []level{{"100.5", "4", 2}, {"101", "5", 1}})
+
+	d, err = e.GetDepth(ctx, "BTC/USD", 1, decimal.NewFromInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("one bid level", d.Bids, []level{{"99", "7", 3}})
+	check("one ask level", d.Asks, []level{{"101", "9", 3}})
+
+	if _, err := e.GetDepth(ctx, "BTC/USD", 0, decimal.NewFromInt(-1)); err == nil {
+		t.Fatal("negative step accepted")
+	}
+}
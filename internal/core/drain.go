@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// shutdownReason is the cancel-only reason given to orders submitted during a shutdown
+const shutdownReason = "the exchange is shutting down"
+
+// orderOps counts the order operations running on symbol workers, or inline on an unsharded
+// engine, so a shutdown can wait for their transactions
+type orderOps struct {
+	mu sync.Mutex
+	n  int
+	// idle is closed while no operation runs and replaced when one starts
+	idle chan struct{}
+}
+
+func newOrderOps() *orderOps {
+	idle := make(chan struct{})
+	close(idle)
+	return &orderOps{idle: idle}
+}
+
+func (o *orderOps) start() {
+	o.mu.Lock()
+	if o.n == 0 {
+		o.idle = make(chan struct{})
+	}
+	o.n++
+	o.mu.Unlock()
+}
+
+func (o *orderOps) done() {
+	o.mu.Lock()
+	if o.n--; o.n == 0 {
+		close(o.idle)
+	}
+	o.mu.Unlock()
+}
+
+func (o *orderOps) idleCh() <-chan struct{} {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.idle
+}
+
+// StopOrderEntry puts the whole engine in cancel-only mode for a shutdown: submissions and amends
+// are rejected from then on, while clients can still cancel what rests on the books
+func (e *Engine) StopOrderEntry() {
+	e.SetCancelOnly(context.Background(), "", true, shutdownReason)
+}
+
+// Drain stops order entry, waits for the order operations in flight to commit, bulk amends and
+// cancels included, and stops the symbol loops. It returns ctx's error if they do not finish in time,
+// leaving them running.
+func (e *Engine) Drain(ctx context.Context) error {
+	e.StopOrderEntry()
+	select {
+	case <-e.ops.idleCh():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	e.StopSymbolLoops()
+	return nil
+}
+
+// FlushCache rewrites every book of every tenant in the cache from the repository, so a cache write
+// lost to a failure or the circuit breaker does not outlive the instance and the next one, or
+// another sharing the cache, starts from the books as committed. Run it once Drain returned.
+func (e *Engine) FlushCache(ctx context.Context) error {
+	if e.cache == nil {
+		return nil
+	}
+	var errs []error
+	for _, id := range e.tenantIDs() {
+		tctx := tenant.With(ctx, id)
+		symbols, err := e.repo.ListSymbols(tctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, symbol := range symbols {
+			if err := ctx.Err(); err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+			// a book that did not load is dropped from the cache and read from the repository next time
+			updateCache(tctx, e.repo, e.cache, symbol)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/shopspring/decimal"
+)
+
+func TestDrain(t *testing.T) {
+	ctx := context.Background()
+	cache := &mapCache{books: make(map[string]*domain.OrderbookSnapshot)}
+	e := NewEngine(memory.NewRepository(), cache, WithSymbolLoops())
+	sell := func(id, symbol string) error {
+		_, err := e.SubmitOrder(ctx, &domain.Order{ID: id, ClientID: "maker", Symbol: symbol, Side: domain.Sell,
+			Type: domain.Limit, Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)})
+		return err
+	}
+	if err := sell("s1", "BTC/USD"); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// an operation in flight holds the drain back until it finishes
+	release := make(chan struct{})
+	running := make(chan struct{})
+	go e.onSymbolWorker(ctx, "BTC/USD", func() { close(running); <-release })
+	<-running
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := e.Drain(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("drain with an operation in flight: %v", err)
+	}
+	// another symbol's loop rejects the submission at once
+	if err := sell("s2", "ETH/USD"); !errors.Is(err, ErrCancelOnly) {
+		t.Fatalf("submit while draining: %v", err)
+	}
+	close(release)
+	if ok, err := e.CancelOrder(ctx, "s1", "maker"); !ok || err != nil {
+		t.Fatalf("cancel while draining: %v, %v", ok, err)
+	}
+	if err := e.Drain(ctx); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if err := sell("s3", "BTC/USD"); !errors.Is(err, ErrSymbolLoopsStopped) && !errors.Is(err, ErrCancelOnly) {
+		t.Fatalf("submit after the drain: %v", err)
+	}
+
+	cache.SetOrderbook(ctx, "BTC/USD", &domain.OrderbookSnapshot{Symbol: "BTC/USD",
+		Asks: []domain.Order{{ID: "stale", Price: decimal.NewFromInt(1), Remaining: decimal.NewFromInt(1)}}})
+	if err := e.FlushCache(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if ob, _ := cache.GetOrderbook(ctx, "BTC/USD"); ob == nil || len(ob.Asks) != 0 {
+		t.Fatalf("flushed book %+v, want the committed empty book", ob)
+	}
+}
+
+// gatedRepo holds the first call of the gated method, once armed, until release is closed
+type gatedRepo struct {
+	port.Repository
+	method  string
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (r *gatedRepo) gate(method string) {
+	if method == r.method {
+		r.once.Do(func() { close(r.entered); <-r.release })
+	}
+}
+
+func (r *gatedRepo) BeginTx(ctx context.Context) (port.Tx, error) {
+	r.gate("BeginTx")
+	return r.Repository.BeginTx(ctx)
+}
+
+func (r *gatedRepo) CancelGroupOrders(ctx context.Context, clientID, groupID, symbol string) (map[string]string, error) {
+	r.gate("CancelGroupOrders")
+	return r.Repository.CancelGroupOrders(ctx, clientID, groupID, symbol)
+}
+
+func (r *gatedRepo) CancelSessionOrders(ctx context.Context, clientID, sessionID, symbol string) (map[string]string, error) {
+	r.gate("CancelSessionOrders")
+	return r.Repository.CancelSessionOrders(ctx, clientID, sessionID, symbol)
+}
+
+func TestDrainWaitsForBulkOperations(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		// run places what the operation needs and returns the operation
+		run func(t *testing.T, e *Engine) func(ctx context.Context) error
+	}{
+		{"batch modify", "BeginTx", func(t *testing.T, e *Engine) func(ctx context.Context) error {
+			id := uuid.NewString()
+			seedSell(t, e, id)
+			return func(ctx context.Context) error {
+				_, err := e.BatchModifyOrders(ctx, "maker", []Amend{{OrderID: id, Price: decimal.NewFromInt(101), Quantity: decimal.NewFromInt(1)}})
+				return err
+			}
+		}},
+		{"group cancel", "CancelGroupOrders", func(t *testing.T, e *Engine) func(ctx context.Context) error {
+			res, err := e.SubmitOrderGroup(context.Background(), []*domain.Order{groupLeg("l1", "c", "BTC/USD", domain.Buy, 90)}, false)
+			if err != nil {
+				t.Fatalf("submit group: %v", err)
+			}
+			return func(ctx context.Context) error {
+				_, err := e.CancelOrderGroup(ctx, "c", res.GroupID)
+				return err
+			}
+		}},
+		{"session cancel", "CancelSessionOrders", func(t *testing.T, e *Engine) func(ctx context.Context) error {
+			s, err := e.Login(context.Background(), "c", false)
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			o := groupLeg("o1", "c", "BTC/USD", domain.Buy, 90)
+			o.SessionID = s.ID
+			if _, err := e.SubmitOrder(context.Background(), o); err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+			return func(ctx context.Context) error {
+				_, err := e.CancelSessionOrders(ctx, s.Token)
+				return err
+			}
+		}},
+	}
+	for _, tc := range tests {
+		for _, sharded := range []bool{false, true} {
+			name := tc.name
+			if sharded {
+				name += " on symbol loops"
+			}
+			t.Run(name, func(t *testing.T) {
+				ctx := context.Background()
+				repo := &gatedRepo{Repository: memory.NewRepository(), entered: make(chan struct{}), release: make(chan struct{})}
+				var opts []Option
+				if sharded {
+					opts = append(opts, WithSymbolLoops())
+				}
+				e := NewEngine(repo, nil, opts...)
+				op := tc.run(t, e)
+				repo.method = tc.method
+
+				done := make(chan error, 1)
+				go func() { done <- op(ctx) }()
+				<-repo.entered
+				short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+				defer cancel()
+				if err := e.Drain(short); !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("drain with the operation in flight: %v", err)
+				}
+				close(repo.release)
+				if err := <-done; err != nil {
+					t.Fatalf("operation: %v", err)
+				}
+				if err := e.Drain(ctx); err != nil {
+					t.Fatalf("drain: %v", err)
+				}
+			})
+		}
+	}
+}
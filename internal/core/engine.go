@@ -4,35 +4,136 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
 	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
 	"github.com/shopspring/decimal"
+	"golang.org/x/sync/singleflight"
 )
 
 // Engine implements business logic (matching, submit, cancel, modify, snapshot)
 type Engine struct {
-	repo  port.Repository
-	cache port.Cache
+	repo         port.Repository
+	cache        port.Cache
+	tape         port.TradeTape
+	tops         port.TopOfBookStore
+	journal      port.EventJournal
+	recent       *recentTrades
+	fees         domain.FeeSchedule
+	tenants      map[string]domain.TenantConfig
+	retention    domain.RetentionPolicy
+	delistings   *delistings
+	instruments  *instruments
+	maintenance  *maintenance
+	sessions     *sessions
+	tickJobs     *tickJobs
+	sandbox      *virtualBalances
+	accounts     bool
+	candles      bool
+	execution    *executionMetrics
+	surveillance *surveillance
+	refresh      *cacheRefresh
+	catalog      port.SnapshotCatalog
+	objects      port.ObjectStore
+	pool         *workerPool
+	loops        *symbolLoops
+	ops          *orderOps
+	streams      map[string]pubsub.Options
+	imbalances   *pubsub.PubSub[*domain.Imbalance]
+	trades       *pubsub.PubSub[domain.TapeEntry]
+	events       *pubsub.PubSub[*domain.OrderEvent]
+	books        *pubsub.PubSub[*domain.OrderbookSnapshot]
+	alerts       *pubsub.PubSub[*domain.SurveillanceAlert]
+	quotes       *pubsub.PubSub[*domain.Quote]
+	bbo          *bbo
+	warm         *warmup
+	precision    map[string]domain.Precision
+	changes      *bookChanges
+	matchBatch   int
+	matchTimeout time.Duration
+
+	// snapshotLoads coalesces concurrent repository loads of a book missing from the cache
+	snapshotLoads singleflight.Group
 }
 
-func NewEngine(repo port.Repository, cache port.Cache) *Engine {
-	return &Engine{
-		repo:  repo,
-		cache: cache,
+// Option configures optional engine components
+type Option func(*Engine)
+
+const (
+	// defaultMatchBatch is the resting orders a match loads per round trip to the repository
+	defaultMatchBatch = 200
+	// defaultMatchTimeout bounds a match, and the transaction that holds the symbol's lock meanwhile
+	defaultMatchTimeout = 2 * time.Second
+)
+
+// WithMatchLimits sets how many resting orders a match loads at a time and how long it may take;
+// zero keeps a default
+func WithMatchLimits(batch int, timeout time.Duration) Option {
+	return func(e *Engine) {
+		if batch > 0 {
+			e.matchBatch = batch
+		}
+		if timeout > 0 {
+			e.matchTimeout = timeout
+		}
+	}
+}
+
+func NewEngine(repo port.Repository, cache port.Cache, opts ...Option) *Engine {
+	e := &Engine{
+		repo:         repo,
+		cache:        cache,
+		recent:       newRecentTrades(defaultRecentTrades, 0),
+		streams:      make(map[string]pubsub.Options),
+		delistings:   newDelistings(),
+		instruments:  newInstruments(),
+		maintenance:  newMaintenance(),
+		ops:          newOrderOps(),
+		sessions:     newSessions(),
+		tickJobs:     newTickJobs(),
+		warm:         &warmup{status: domain.WarmupStatus{Ready: true}},
+		changes:      &bookChanges{last: make(map[string]uint64)},
+		bbo:          &bbo{last: make(map[string]bboKey)},
+		matchBatch:   defaultMatchBatch,
+		matchTimeout: defaultMatchTimeout,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.imbalances = pubsub.NewWithOptions[*domain.Imbalance](16, e.streams[StreamImbalance])
+	e.trades = pubsub.NewWithOptions[domain.TapeEntry](256, e.streams[StreamTrades])
+	e.events = pubsub.NewWithOptions[*domain.OrderEvent](256, e.streams[StreamOrderEvents])
+	e.books = pubsub.NewWithOptions[*domain.OrderbookSnapshot](16, e.streams[StreamOrderbook])
+	e.alerts = pubsub.NewWithOptions[*domain.SurveillanceAlert](64, e.streams[StreamSurveillanceAlerts])
+	e.quotes = pubsub.NewWithOptions[*domain.Quote](64, e.streams[StreamQuotes])
+	return e
 }
 
 func validateOrder(o *domain.Order) error {
-	if o.Type == domain.Limit && o.Price.LessThanOrEqual(decimal.Zero) {
-		return errors.New("limit price must be > 0")
+	if o.Type.HasLimit() && o.Price.LessThanOrEqual(decimal.Zero) {
+		return domain.Reject(domain.RejectInvalidPrice, "limit price must be > 0")
+	}
+	if o.Type.IsStop() && o.TriggerPrice.LessThanOrEqual(decimal.Zero) {
+		return domain.Reject(domain.RejectInvalidPrice, "trigger price must be > 0")
+	}
+	if !o.Type.IsStop() && !o.TriggerPrice.IsZero() {
+		return domain.Reject(domain.RejectInvalidOrder, "trigger price is only allowed on stop orders")
 	}
 	if o.Quantity.LessThanOrEqual(decimal.Zero) {
-		return errors.New("quantity must be > 0")
+		return domain.Reject(domain.RejectInvalidQuantity, "quantity must be > 0")
 	}
-	return nil
+	if err := domain.ValidateTags(o.Tags); err != nil {
+		return err
+	}
+	if err := domain.ValidateClientOrderID(o.ClientOrderID); err != nil {
+		return err
+	}
+	return domain.ValidateUserData(o.UserData)
 }
 
 func updateOrderStatus(o *domain.Order) {
@@ -46,69 +147,149 @@ func updateOrderStatus(o *domain.Order) {
 	}
 }
 func (e *Engine) SubmitOrder(ctx context.Context, o *domain.Order) ([]*domain.Trade, error) {
+	if err := e.checkSymbol(ctx, o.Symbol); err != nil {
+		// rejected before it reaches a symbol worker, which an unknown symbol must not start
+		stampNew(o)
+		e.emit(ctx, rejectEvent(o, err))
+		return nil, err
+	}
+	var (
+		trades []*domain.Trade
+		err    error
+	)
+	if perr := e.onSymbolWorker(ctx, o.Symbol, func() { trades, err = e.submitOrder(ctx, o) }); perr != nil {
+		return nil, perr
+	}
+	return trades, err
+}
+
+// stampNew gives a new order its ID, unless the client chose one, its time and its initial state
+func stampNew(o *domain.Order) {
 	if o.ID == "" {
 		o.ID = uuid.New().String()
 	}
 	o.CreatedAt = time.Now().UTC()
+	o.UpdatedAt = o.CreatedAt
 	o.Status = domain.Open
 	o.Remaining = o.Quantity
+}
 
-	if err := validateOrder(o); err != nil {
+func (e *Engine) submitOrder(ctx context.Context, o *domain.Order) ([]*domain.Trade, error) {
+	stampNew(o)
+	if err := e.checkOrder(ctx, o); err != nil {
+		e.emit(ctx, rejectEvent(o, err))
 		return nil, err
 	}
 
-	var executed []*domain.Trade
+	mid := e.arrivalMid(ctx, o.Symbol)
+	var (
+		executed []*domain.Trade
+		events   []*domain.OrderEvent
+	)
 	err := withTx(ctx, e.repo, func(tx port.Tx) error {
-		if err := tx.SaveOrder(ctx, o); err != nil {
+		// a retried transaction matches the order from scratch
+		o.Status, o.Remaining, o.UpdatedAt = domain.Open, o.Quantity, o.CreatedAt
+		if o.Type.IsStop() {
+			o.Status = domain.Pending
+		}
+		if err := saveOrder(ctx, tx, domain.OrderAccepted, o); err != nil {
 			return err
 		}
-		var err error
-		executed, err = e.matchOrder(ctx, tx, o)
-		return err
+		if err := e.lockFunds(ctx, tx, o); err != nil {
+			return err
+		}
+		events = []*domain.OrderEvent{newEvent(o, domain.ExecNew)}
+		if o.Status == domain.Pending {
+			// a stop waits off the book until a trade triggers it
+			return nil
+		}
+		var (
+			err error
+			evs []*domain.OrderEvent
+		)
+		executed, evs, err = e.matchOrder(ctx, tx, o)
+		events = append(events, evs...)
+		if err != nil {
+			return err
+		}
+		// the final status commits together with the trades that produced it, so a crash leaves
+		// either the whole submission or none of it
+		updateOrderStatus(o)
+		return saveMatched(ctx, tx, o, executed)
 	})
+	if errors.Is(err, port.ErrDuplicateClientOrderID) {
+		err = domain.Reject(domain.RejectDuplicateClientOrderID, "client_order_id %q is in use by an open order", o.ClientOrderID)
+	}
 	if err != nil {
+		if domain.IsReject(err) {
+			e.emit(ctx, rejectEvent(o, err))
+		}
 		return nil, err
 	}
+	if o.Status == domain.Pending {
+		e.emit(ctx, events...)
+		return executed, nil
+	}
+	e.matched(ctx, o, mid, executed, events)
+	e.fireTriggers(ctx, o.Symbol, executed)
+	return executed, nil
+}
 
-	updateOrderStatus(o)
-	err = withTx(ctx, e.repo, func(tx port.Tx) error {
-		return tx.SaveOrder(ctx, o)
-	})
-	if err != nil {
-		return nil, err
+// matched publishes the outcome of an order's committed matching
+func (e *Engine) matched(ctx context.Context, o *domain.Order, mid decimal.NullDecimal, executed []*domain.Trade, events []*domain.OrderEvent) {
+	if e.execution != nil {
+		e.execution.arrive(ctx, o, mid)
 	}
+	e.publishMatch(ctx, o.Symbol, executed, events)
+}
 
-	updateCache(ctx, e.repo, e.cache, o.Symbol)
-	return executed, nil
+// publishMatch publishes the committed events and trades of matching on symbol
+func (e *Engine) publishMatch(ctx context.Context, symbol string, executed []*domain.Trade, events []*domain.OrderEvent) {
+	e.bookChanged(ctx, symbol)
+	e.settleSandbox(ctx, events)
+	e.emit(ctx, events...)
+	e.publishTrades(ctx, symbol, executed)
+	e.completeGroups(ctx, events)
 }
 
-func (e *Engine) matchOrder(ctx context.Context, tx port.Tx, o *domain.Order) ([]*domain.Trade, error) {
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+// matchOrder executes o against resting orders and returns the trades plus the fill events of both sides
+func (e *Engine) matchOrder(ctx context.Context, tx port.Tx, o *domain.Order) ([]*domain.Trade, []*domain.OrderEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.matchTimeout)
 	defer cancel()
 
 	executed := []*domain.Trade{}
-	const batchSize = 200
+	var events []*domain.OrderEvent
 	now := time.Now().UTC()
+	var after *domain.BookKey
+
+	// one matcher per symbol at a time: submissions queue on the lock in arrival order instead of
+	// racing for the same rows, while other symbols match in parallel
+	if err := tx.LockSymbol(ctx, o.Symbol); err != nil {
+		return executed, events, err
+	}
 
 	for o.Remaining.GreaterThan(decimal.Zero) {
 		select {
 		case <-ctx.Done():
-			return executed, ctx.Err()
+			return executed, events, ctx.Err()
 		default:
 		}
 
 		var lp *decimal.Decimal
-		if o.Type == domain.Limit {
+		if o.Type.HasLimit() {
 			lp = &o.Price
 		}
 
-		cands, err := tx.LoadCandidatesForMatch(ctx, o.Symbol, o.Side, lp, batchSize)
+		cands, err := tx.LoadCandidatesForMatch(ctx, o.Symbol, o.Side, lp, after, e.matchBatch)
 		if err != nil {
-			return executed, err
+			return executed, events, err
 		}
 		if len(cands) == 0 {
 			break
 		}
+		// the next batch continues behind this one rather than from the top of the book
+		last := cands[len(cands)-1].Key()
+		after = &last
 
 		progressed := false
 		for _, other := range cands {
@@ -125,41 +306,62 @@ func (e *Engine) matchOrder(ctx context.Context, tx port.Tx, o *domain.Order) ([
 			}
 
 			tr := &domain.Trade{
-				ID:        uuid.New().String(),
-				Symbol:    o.Symbol,
-				BuyOrder:  chooseOrderID(o, other, domain.Buy),
-				SellOrder: chooseOrderID(o, other, domain.Sell),
-				Price:     other.Price,
-				Quantity:  q,
-				Timestamp: now,
+				ID:            uuid.New().String(),
+				Symbol:        o.Symbol,
+				BuyOrder:      chooseOrderID(o, other, domain.Buy),
+				SellOrder:     chooseOrderID(o, other, domain.Sell),
+				Price:         other.Price,
+				Quantity:      q,
+				Timestamp:     now,
+				Sequence:      sequence.next(now),
+				BuyClient:     chooseClientID(o, other, domain.Buy),
+				SellClient:    chooseClientID(o, other, domain.Sell),
+				TakerSide:     o.Side,
+				TakerType:     o.Type,
+				BuyLiquidity:  domain.LiquidityOf(o.Side, domain.Buy),
+				SellLiquidity: domain.LiquidityOf(o.Side, domain.Sell),
 			}
+			e.applyFees(ctx, tr)
 
-			if err := tx.SaveTrade(ctx, tr); err != nil {
-				return executed, err
+			if err := saveTrade(ctx, tx, tr); err != nil {
+				return executed, events, err
+			}
+			if err := e.settleTrade(ctx, tx, tr, o, other); err != nil {
+				return executed, events, err
 			}
 			executed = append(executed, tr)
 
 			o.Remaining = o.Remaining.Sub(q)
 			other.Remaining = other.Remaining.Sub(q)
 
-			updateOrderStatus(other)
-			if err := tx.SaveOrder(ctx, other); err != nil {
-				return executed, err
+			events = append(events, fillEvent(other, tr), fillEvent(o, tr))
+			if err := saveOrder(ctx, tx, domain.OrderMatched, other); err != nil {
+				return executed, events, err
+			}
+			if other.Remaining.IsZero() {
+				if err := e.releaseFunds(ctx, tx, other); err != nil {
+					return executed, events, err
+				}
 			}
 
 			progressed = true
 		}
 
-		if !progressed {
+		if !progressed || len(cands) < e.matchBatch {
 			break
 		}
 	}
 
-	return executed, nil
+	if o.Remaining.IsZero() {
+		if err := e.releaseFunds(ctx, tx, o); err != nil {
+			return executed, events, err
+		}
+	}
+	return executed, events, nil
 }
 
 func priceMatch(o, other *domain.Order) bool {
-	if o.Type != domain.Limit {
+	if !o.Type.HasLimit() {
 		return true
 	}
 	if o.Side == domain.Buy && o.Price.LessThan(other.Price) {
@@ -178,6 +380,13 @@ func chooseOrderID(o1, o2 *domain.Order, side domain.Side) string {
 	return o2.ID
 }
 
+func chooseClientID(o1, o2 *domain.Order, side domain.Side) string {
+	if o1.Side == side {
+		return o1.ClientID
+	}
+	return o2.ClientID
+}
+
 func (e *Engine) loadSnapshot(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
 	if e.cache != nil {
 		if ob, err := e.cache.GetOrderbook(ctx, symbol); err == nil && ob != nil {
@@ -200,53 +409,165 @@ func (e *Engine) loadSnapshot(ctx context.Context, symbol string) (*domain.Order
 	}, nil
 }
 
-func (e *Engine) ModifyOrder(ctx context.Context, orderID, clientID string, newPrice, newQty decimal.Decimal) error {
-	var symbol string
+// ModifyOrder replaces the price and quantity of the client's open order as a cancel-replace: the
+// order loses its time priority, and if the new price crosses the book it matches at once, in the
+// same transaction. It returns the trades of that matching.
+func (e *Engine) ModifyOrder(ctx context.Context, orderID, clientID string, newPrice, newQty decimal.Decimal) ([]*domain.Trade, error) {
+	o, err := e.repo.LoadOrderByIDForClient(ctx, orderID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	var trades []*domain.Trade
+	if perr := e.onSymbolWorker(ctx, o.Symbol, func() { trades, err = e.modifyOrder(ctx, orderID, clientID, newPrice, newQty) }); perr != nil {
+		return nil, perr
+	}
+	return trades, err
+}
+
+func (e *Engine) modifyOrder(ctx context.Context, orderID, clientID string, newPrice, newQty decimal.Decimal) ([]*domain.Trade, error) {
+	var (
+		o        *domain.Order
+		executed []*domain.Trade
+		events   []*domain.OrderEvent
+	)
 	err := withTx(ctx, e.repo, func(tx port.Tx) error {
-		o, err := tx.LoadOrderByIDForClient(ctx, orderID, clientID)
+		cur, err := tx.LoadOrderByIDForClient(ctx, orderID, clientID)
 		if err != nil {
 			return err
 		}
-		if o.Status != domain.Open {
+		if !cur.Status.Resting() {
 			return errors.New("cannot modify non-open order")
 		}
-		o.Price = newPrice
-		o.Quantity = newQty
-		o.Remaining = newQty
-		symbol = o.Symbol
-		return tx.SaveOrder(ctx, o)
+		if err := e.checkCancelOnly(ctx, cur.Symbol); err != nil {
+			return err
+		}
+		if err := e.normalize(cur.Symbol, cur.Side, &newPrice, &newQty); err != nil {
+			return err
+		}
+		if err := e.checkInstrument(ctx, cur.Symbol, newPrice, decimal.Zero, newQty); err != nil {
+			return err
+		}
+		if err := amend(cur, newPrice, newQty); err != nil {
+			return err
+		}
+		o = cur
+		if err := e.relockFunds(ctx, tx, o); err != nil {
+			return err
+		}
+		executed, events, err = e.replace(ctx, tx, o)
+		return err
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	e.publishMatch(ctx, o.Symbol, executed, events)
+	e.fireTriggers(ctx, o.Symbol, executed)
+	return executed, nil
+}
 
-	updateCache(ctx, e.repo, e.cache, symbol)
+// amend gives the resting order o a new price and total quantity. What already filled stays filled,
+// so qty must exceed it, and the rest of qty is left to match.
+func amend(o *domain.Order, price, qty decimal.Decimal) error {
+	filled := o.Quantity.Sub(o.Remaining)
+	if !qty.GreaterThan(filled) {
+		return domain.Reject(domain.RejectInvalidQuantity, "quantity %s does not exceed the %s already filled", qty, filled)
+	}
+	o.Price, o.Quantity, o.Remaining = price, qty, qty.Sub(filled)
 	return nil
 }
 
+// replace stores the resting order o, whose new price and quantity the caller set with amend and
+// locked funds for, as if placed now, behind the orders resting at its price, then matches it like a
+// submission. It returns the trades plus the replace and fill events.
+func (e *Engine) replace(ctx context.Context, tx port.Tx, o *domain.Order) ([]*domain.Trade, []*domain.OrderEvent, error) {
+	now := time.Now().UTC()
+	updateOrderStatus(o)
+	o.CreatedAt, o.UpdatedAt = now, now
+	if err := tx.AppendLedger(ctx, []domain.LedgerEvent{domain.OrderLedgerEvent(domain.OrderReplaced, o)}); err != nil {
+		return nil, nil, err
+	}
+	if err := tx.ModifyOrder(ctx, o.ID, o.ClientID, &o.Price, &o.Quantity, now); err != nil {
+		return nil, nil, err
+	}
+	events := []*domain.OrderEvent{newEvent(o, domain.ExecReplaced)}
+	executed, evs, err := e.matchOrder(ctx, tx, o)
+	events = append(events, evs...)
+	if err != nil {
+		return executed, events, err
+	}
+	updateOrderStatus(o)
+	return executed, events, saveMatched(ctx, tx, o, executed)
+}
+
 func (e *Engine) CancelOrder(ctx context.Context, orderID, clientID string) (bool, error) {
-	var symbol string
+	if !e.sharded() {
+		e.ops.start()
+		defer e.ops.done()
+		return e.cancelOrder(ctx, orderID, clientID, "cancelled by client", domain.CauseCancelledByUser)
+	}
+	o, err := e.repo.LoadOrderByIDForClient(ctx, orderID, clientID)
+	if err != nil {
+		return false, err
+	}
+	return e.cancelOnSymbolWorker(ctx, o, "cancelled by client", domain.CauseCancelledByUser)
+}
+
+// ForceCancelOrder cancels any client's open order on behalf of an operator
+func (e *Engine) ForceCancelOrder(ctx context.Context, orderID, reason string) (bool, error) {
+	o, err := e.repo.LoadOrderByID(ctx, orderID)
+	if err != nil {
+		return false, err
+	}
+	if reason == "" {
+		reason = "cancelled by admin"
+	}
+	return e.cancelOnSymbolWorker(ctx, o, reason, domain.CauseAdmin)
+}
+
+// cancelOnSymbolWorker cancels the order on its symbol's worker, so the cancel keeps its place among
+// the symbol's submissions
+func (e *Engine) cancelOnSymbolWorker(ctx context.Context, o *domain.Order, reason string, cause domain.TransitionCause) (bool, error) {
+	var (
+		ok  bool
+		err error
+	)
+	if perr := e.onSymbolWorker(ctx, o.Symbol, func() { ok, err = e.cancelOrder(ctx, o.ID, o.ClientID, reason, cause) }); perr != nil {
+		return false, perr
+	}
+	return ok, err
+}
+
+func (e *Engine) cancelOrder(ctx context.Context, orderID, clientID, reason string, cause domain.TransitionCause) (bool, error) {
+	var (
+		symbol string
+		ev     *domain.OrderEvent
+	)
 	err := withTx(ctx, e.repo, func(tx port.Tx) error {
 		o, err := tx.LoadOrderByIDForClient(ctx, orderID, clientID)
 		if err != nil {
 			return err
 		}
-		if o.Status != domain.Open {
+		if !o.Status.Resting() && o.Status != domain.Pending {
 			return errors.New("cannot cancel non-open order")
 		}
 		symbol = o.Symbol
+		o.Status = domain.Cancelled
+		o.Remaining = decimal.Zero
+		ev = newEvent(o, domain.ExecCanceled)
+		ev.Reason, ev.Cause = reason, cause
 		return tx.CancelOrder(ctx, orderID, clientID)
 	})
 	if err != nil {
 		return false, err
 	}
 
-	updateCache(ctx, e.repo, e.cache, symbol)
+	e.bookChanged(ctx, symbol)
+	e.emit(ctx, ev)
 	return true, nil
 }
 
 func (e *Engine) GetOrderbook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
-	return getOrLoadSnapshot(ctx, e.repo, e.cache, symbol)
+	return getOrLoadSnapshot(ctx, e.repo, e.cache, &e.snapshotLoads, symbol)
 }
 
 func (e *Engine) SnapshotOrderbook(ctx context.Context, symbol string) (string, error) {
@@ -264,31 +585,14 @@ func (e *Engine) SnapshotOrderbook(ctx context.Context, symbol string) (string,
 		return "", err
 	}
 
-	snapshotID := uuid.NewString()
-	ttl := 24 * time.Hour
-	if err := e.cache.SetSnapshot(ctx, snapshotID, data, ttl); err != nil {
-		return "", err
-	}
-	return snapshotID, nil
+	return e.storeSnapshot(ctx, ob, data)
 }
 
 func (e *Engine) RestoreOrderbook(ctx context.Context, snapshotID string) (bool, error) {
-	if e.cache == nil {
-		return false, errors.New("cache not configured")
-	}
-
-	data, err := e.cache.GetSnapshot(ctx, snapshotID)
+	ob, err := e.GetSnapshot(ctx, snapshotID)
 	if err != nil {
 		return false, err
 	}
-	if data == nil {
-		return false, errors.New("snapshot not found")
-	}
-
-	var ob domain.OrderbookSnapshot
-	if err := json.Unmarshal(data, &ob); err != nil {
-		return false, err
-	}
 
 	if err := e.cache.SetOrderbook(ctx, ob.Symbol, ob.DeepCopy()); err != nil {
 		return false, err
@@ -298,17 +602,63 @@ func (e *Engine) RestoreOrderbook(ctx context.Context, snapshotID string) (bool,
 }
 
 func (e *Engine) GetOrder(ctx context.Context, orderID string) (*domain.Order, error) {
-	order, err := e.repo.LoadOrderByIDForClient(ctx, orderID, "")
+	order, err := e.repo.LoadOrderByID(ctx, orderID)
 	if err != nil {
 		return nil, err
 	}
 	return order, nil
 }
 
-func (e *Engine) GetTradesForOrder(ctx context.Context, orderID string) ([]*domain.Trade, error) {
-	trades, err := e.repo.LoadTradesForOrder(ctx, orderID)
+// GetOrderByClientOrderID returns the client's order with the client order ID: its open order with
+// it if there is one, otherwise the latest, as IDs are reused once their order is done
+func (e *Engine) GetOrderByClientOrderID(ctx context.Context, clientID, clientOrderID string) (*domain.Order, error) {
+	if clientOrderID == "" {
+		return nil, errors.New("client_order_id is required")
+	}
+	return e.repo.LoadOrderByClientOrderID(ctx, clientID, clientOrderID)
+}
+
+// GetTrade returns one execution by its ID, as settlement and support refer to it
+func (e *Engine) GetTrade(ctx context.Context, tradeID string) (*domain.Trade, error) {
+	return e.repo.LoadTradeByID(ctx, tradeID)
+}
+
+// ListOrders returns a page of the client's orders matching f, live and archived, oldest first
+func (e *Engine) ListOrders(ctx context.Context, f domain.OrderFilter, req page.Request) (page.Page[*domain.Order], error) {
+	if f.ClientID == "" {
+		return page.Page[*domain.Order]{}, errors.New("client_id is required")
+	}
+	switch f.Status {
+	case "", domain.Pending, domain.Open, domain.PartiallyFilled, domain.Filled, domain.Cancelled:
+	default:
+		return page.Page[*domain.Order]{}, fmt.Errorf("invalid status: %s", f.Status)
+	}
+	var after *page.Key
+	var pos page.Key
+	if ok, err := page.Decode(req.Cursor, &pos); err != nil || (ok && pos.ID == "") {
+		return page.Page[*domain.Order]{}, page.ErrInvalidCursor
+	} else if ok {
+		after = &pos
+	}
+	orders, err := e.repo.ListOrders(ctx, f, after, page.Fetch(req.Limit))
 	if err != nil {
-		return nil, err
+		return page.Page[*domain.Order]{}, err
+	}
+	return page.Build(orders, req.Limit, func(o *domain.Order) any { return page.Key{At: o.CreatedAt, ID: o.ID} }), nil
+}
+
+// GetTradesForOrder returns a page of the order's trades, oldest first
+func (e *Engine) GetTradesForOrder(ctx context.Context, orderID string, req page.Request) (page.Page[*domain.Trade], error) {
+	var after *page.Key
+	var pos page.Key
+	if ok, err := page.Decode(req.Cursor, &pos); err != nil || (ok && pos.ID == "") {
+		return page.Page[*domain.Trade]{}, page.ErrInvalidCursor
+	} else if ok {
+		after = &pos
+	}
+	trades, err := e.repo.LoadTradesForOrder(ctx, orderID, after, page.Fetch(req.Limit))
+	if err != nil {
+		return page.Page[*domain.Trade]{}, err
 	}
-	return trades, nil
+	return page.Build(trades, req.Limit, func(t *domain.Trade) any { return page.Key{At: t.Timestamp, ID: t.ID} }), nil
 }
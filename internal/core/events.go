@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// AllClients is the event topic that receives every client's order events within a tenant
+const AllClients = "*"
+
+// allTenants is the unscoped topic behind SubscribeAllOrderEvents and SubscribeAllTrades
+const allTenants = "*/*"
+
+// newEvent describes a transition of o, stamping o with its time
+func newEvent(o *domain.Order, et domain.ExecType) *domain.OrderEvent {
+	now := time.Now().UTC()
+	o.UpdatedAt = now
+	return &domain.OrderEvent{
+		OrderID:   o.ID,
+		ClientID:  o.ClientID,
+		Symbol:    o.Symbol,
+		Side:      o.Side,
+		Type:      o.Type,
+		ExecType:  et,
+		Status:    o.Status,
+		Price:     o.Price,
+		Quantity:  o.Quantity,
+		Remaining: o.Remaining,
+		Tags:      o.Tags,
+		UserData:  o.UserData,
+		GroupID:   o.GroupID,
+		Cause:     domain.CauseOf(et),
+		Timestamp: now,
+		Sequence:  sequence.next(now),
+	}
+}
+
+// fillEvent moves o to its post-fill status and describes the transition
+func fillEvent(o *domain.Order, tr *domain.Trade) *domain.OrderEvent {
+	updateOrderStatus(o)
+	et := domain.ExecPartialFill
+	if o.Status == domain.Filled {
+		et = domain.ExecFill
+	}
+	ev := newEvent(o, et)
+	ev.TradeID = tr.ID
+	ev.LastPrice = tr.Price
+	ev.LastQty = tr.Quantity
+	ev.Timestamp = tr.Timestamp
+	o.UpdatedAt = tr.Timestamp
+	return ev
+}
+
+func rejectEvent(o *domain.Order, reason error) *domain.OrderEvent {
+	ev := newEvent(o, domain.ExecRejected)
+	ev.Reason = reason.Error()
+	ev.RejectCode = domain.RejectCodeOf(reason)
+	return ev
+}
+
+// cancelledEvent describes a cancel performed in bulk, where only the order's identity is known
+func cancelledEvent(orderID, clientID, symbol string, side domain.Side, reason string) *domain.OrderEvent {
+	now := time.Now().UTC()
+	return &domain.OrderEvent{
+		OrderID:   orderID,
+		ClientID:  clientID,
+		Symbol:    symbol,
+		Side:      side,
+		ExecType:  domain.ExecCanceled,
+		Status:    domain.Cancelled,
+		Reason:    reason,
+		Cause:     domain.CauseCancelledByUser,
+		Timestamp: now,
+		Sequence:  sequence.next(now),
+	}
+}
+
+// WithEventJournal records order events so that streams can be resumed from a cursor
+func WithEventJournal(j port.EventJournal) Option {
+	return func(e *Engine) { e.journal = j }
+}
+
+// emit stamps committed transitions with the ctx tenant, records them in the journal and the orders'
+// histories and publishes them to the owner's topic, the tenant's AllClients topic and the cross-tenant topic; surveillance
+// then checks them
+func (e *Engine) emit(ctx context.Context, evs ...*domain.OrderEvent) {
+	tenantID := tenant.From(ctx)
+	for _, ev := range evs {
+		ev.Tenant = tenantID
+	}
+	if e.journal != nil {
+		_ = e.journal.AppendEvents(ctx, evs)
+	}
+	e.recordTransitions(ctx, evs)
+	if e.execution != nil {
+		e.execution.observe(tenantID, evs)
+	}
+	for _, ev := range evs {
+		e.events.Publish(tenant.Scope(ctx, ev.ClientID), ev)
+		e.events.Publish(tenant.Scope(ctx, AllClients), ev)
+		e.events.Publish(allTenants, ev)
+	}
+	if e.surveillance != nil {
+		e.watchOrderFlow(ctx, evs)
+	}
+}
+
+// SubscribeOrderEvents subscribes to one client's order events, or to all of them with AllClients,
+// within the ctx tenant
+func (e *Engine) SubscribeOrderEvents(ctx context.Context, clientID string) *pubsub.Subscription[*domain.OrderEvent] {
+	return e.events.Subscribe(tenant.Scope(ctx, clientID))
+}
+
+// EventsAfter replays the journaled events of one client, or of all of them with AllClients, after afterSeq,
+// oldest first
+func (e *Engine) EventsAfter(ctx context.Context, clientID, afterSeq string, limit int) ([]*domain.OrderEvent, error) {
+	if e.journal == nil {
+		return nil, errors.New("event journal not configured")
+	}
+	return e.journal.EventsAfter(ctx, clientID, afterSeq, int64(limit))
+}
+
+// SubscribeAllOrderEvents subscribes to the order events of every tenant, for in-process consumers.
+// It buffers generously and is never disconnected by the stream's slow-consumer policy.
+func (e *Engine) SubscribeAllOrderEvents() *pubsub.Subscription[*domain.OrderEvent] {
+	return e.events.SubscribeWith(allTenants, pubsub.Options{Policy: pubsub.Buffer, Bound: 1 << 16})
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
+	"github.com/shopspring/decimal"
+)
+
+var _ Exchange = (*Engine)(nil)
+
+// Exchange is what the API servers need from an engine. Engine implements it; mocks, decorators
+// (metrics, risk checks) and alternative engines can stand in for it.
+type Exchange interface {
+	Trading
+	Sessions
+	MarketData
+	Streams
+	Snapshots
+	Administration
+	Reporting
+	Surveillance
+	Accounts
+}
+
+// Trading is the order entry of clients
+type Trading interface {
+	SubmitOrder(ctx context.Context, o *domain.Order) ([]*domain.Trade, error)
+	BatchSubmitOrders(ctx context.Context, orders []*domain.Order, parallel bool) []SubmitResult
+	SubmitOrderGroup(ctx context.Context, legs []*domain.Order, cancelOnFill bool) (GroupResult, error)
+	CancelOrderGroup(ctx context.Context, clientID, groupID string) ([]string, error)
+	ValidateOrder(ctx context.Context, o *domain.Order) error
+	PreviewOrder(ctx context.Context, o *domain.Order) (*domain.MatchPreview, error)
+	CancelOrder(ctx context.Context, orderID, clientID string) (bool, error)
+	BatchCancelOrders(ctx context.Context, clientID string, orderIDs, clientOrderIDs []string) ([]CancelResult, error)
+	CancelBySide(ctx context.Context, clientID, symbol string, side domain.Side) ([]string, error)
+	CancelAllOrders(ctx context.Context, clientID, symbol string, side domain.Side) ([]string, error)
+	ModifyOrder(ctx context.Context, orderID, clientID string, newPrice, newQty decimal.Decimal) ([]*domain.Trade, error)
+	BatchModifyOrders(ctx context.Context, clientID string, amends []Amend) ([]ModifyResult, error)
+	GetOrder(ctx context.Context, orderID string) (*domain.Order, error)
+	GetOrderByClientOrderID(ctx context.Context, clientID, clientOrderID string) (*domain.Order, error)
+	ListOrders(ctx context.Context, f domain.OrderFilter, req page.Request) (page.Page[*domain.Order], error)
+	GetQueuePosition(ctx context.Context, orderID, clientID string) (*domain.QueuePosition, error)
+	OrderHistory(ctx context.Context, orderID, clientID string) ([]domain.OrderTransition, error)
+	GetTradesForOrder(ctx context.Context, orderID string, req page.Request) (page.Page[*domain.Trade], error)
+	GetTrade(ctx context.Context, tradeID string) (*domain.Trade, error)
+	SandboxBalances(ctx context.Context, clientID string) (map[string]decimal.Decimal, error)
+	ResetSandboxBalances(ctx context.Context, clientID string) error
+	GetNotificationPreferences(ctx context.Context, clientID string) ([]domain.NotificationPreference, error)
+	SetNotificationPreference(ctx context.Context, p domain.NotificationPreference) error
+	DeleteNotificationPreference(ctx context.Context, p domain.NotificationPreference) error
+}
+
+// Sessions are the logged-in trading connections whose orders are tagged with the session and can
+// be cancelled together
+type Sessions interface {
+	Login(ctx context.Context, clientID string, cancelOnDisconnect bool) (domain.Session, error)
+	Heartbeat(ctx context.Context, token string) (domain.Session, error)
+	Logout(ctx context.Context, token string) ([]string, error)
+	CancelSessionOrders(ctx context.Context, token string) ([]string, error)
+}
+
+// MarketData is the read side of the market
+type MarketData interface {
+	GetOrderbook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error)
+	GetOrderbookConsistent(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error)
+	GetQuote(ctx context.Context, symbol string) (*domain.Quote, error)
+	GetDepth(ctx context.Context, symbol string, levels int, step decimal.Decimal) (*domain.Depth, error)
+	GetCandles(ctx context.Context, symbol string, interval domain.CandleInterval, from, to time.Time, limit int) ([]domain.Candle, error)
+	ListTrades(ctx context.Context, symbol string, req page.Request) (page.Page[domain.TapeEntry], error)
+	GetTrades(ctx context.Context, symbol string, from, to time.Time, req page.Request) (page.Page[*domain.Trade], error)
+	TradeBackfill(ctx context.Context, symbol, afterSeq string, limit int) ([]domain.TapeEntry, error)
+	Delistings(ctx context.Context) ([]domain.Delisting, error)
+	Instruments(ctx context.Context) ([]domain.Instrument, error)
+	Precision(symbol string) (domain.Precision, bool)
+}
+
+// Streams are the live feeds and the journal that resumes them
+type Streams interface {
+	SubscribeOrderbook(ctx context.Context, symbols ...string) *SymbolSubscription[*domain.OrderbookSnapshot]
+	SubscribeTrades(ctx context.Context, symbols ...string) *SymbolSubscription[domain.TapeEntry]
+	SubscribeQuotes(ctx context.Context, symbols ...string) *SymbolSubscription[*domain.Quote]
+	SubscribeImbalance(ctx context.Context, symbol string) *pubsub.Subscription[*domain.Imbalance]
+	SubscribeOrderEvents(ctx context.Context, clientID string) *pubsub.Subscription[*domain.OrderEvent]
+	EventsAfter(ctx context.Context, clientID, afterSeq string, limit int) ([]*domain.OrderEvent, error)
+	StreamStats() map[string]pubsub.Stats
+	StreamTopicStats(stream string) ([]pubsub.TopicStats, bool)
+	CloseStreams()
+}
+
+// Snapshots save, list, export and restore order books
+type Snapshots interface {
+	SnapshotOrderbook(ctx context.Context, symbol string) (string, error)
+	RestoreOrderbook(ctx context.Context, snapshotID string) (bool, error)
+	GetSnapshot(ctx context.Context, snapshotID string) (*domain.OrderbookSnapshot, error)
+	GetSnapshotMeta(ctx context.Context, snapshotID string) (*domain.SnapshotMeta, error)
+	ListSnapshots(ctx context.Context, symbol string, req page.Request) (page.Page[domain.SnapshotMeta], error)
+	DeleteSnapshot(ctx context.Context, snapshotID string) error
+	PruneSnapshots(ctx context.Context, symbol string, before time.Time) (int, error)
+	ExportSnapshot(ctx context.Context, snapshotID string) (string, error)
+	ImportSnapshot(ctx context.Context, key string) (string, error)
+	ListSnapshotExports(ctx context.Context, symbol string, req page.Request) (page.Page[domain.StoredObject], error)
+}
+
+// Administration is the operators' control of the market
+type Administration interface {
+	ForceCancelOrder(ctx context.Context, orderID, reason string) (bool, error)
+	SetCancelOnly(ctx context.Context, symbol string, on bool, reason string)
+	CancelOnlyModes(ctx context.Context) []domain.CancelOnly
+	AnnounceDelisting(ctx context.Context, symbol string, haltAt, delistAt time.Time, reason string) (domain.Delisting, error)
+	WithdrawDelisting(ctx context.Context, symbol string) error
+	SaveInstrument(ctx context.Context, in domain.Instrument) (domain.Instrument, error)
+	IsolateSymbol(ctx context.Context, symbol string) (int, error)
+	ReleaseSymbol(ctx context.Context, symbol string) error
+	WorkerAssignments() ([]domain.WorkerAssignment, error)
+	ApplyRetention(ctx context.Context) (domain.RetentionResult, error)
+	DumpOrderbook(ctx context.Context, symbol string) (*domain.BookDump, error)
+	ReplayOrderbook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error)
+	WarmupStatus() domain.WarmupStatus
+}
+
+// Reporting reads the market's history in bulk
+type Reporting interface {
+	ExportOrders(ctx context.Context, f domain.ExportFilter, fn func(*domain.Order) error) error
+	ExportTrades(ctx context.Context, f domain.ExportFilter, fn func(*domain.Trade) error) error
+	GenerateDailyReport(ctx context.Context, day time.Time) (*domain.DailyReport, error)
+	DailyReport(ctx context.Context, day time.Time) (*domain.DailyReport, error)
+	Statement(ctx context.Context, clientID string, from, to time.Time, tag string) (*domain.Statement, error)
+	ExecutionStats(ctx context.Context, clientID, symbol string) (*domain.ExecutionStats, error)
+	RequestTickData(ctx context.Context, symbol string, from, to time.Time, bookChanges bool) (domain.TickJob, error)
+	TickDataJob(ctx context.Context, id string) (domain.TickJob, error)
+	TickDataFile(ctx context.Context, id, file string) ([]byte, error)
+}
+
+// Surveillance is the compliance review of the alerts raised by trade surveillance and their live feed
+type Surveillance interface {
+	SurveillanceAlerts(ctx context.Context, f domain.AlertFilter, req page.Request) (page.Page[domain.SurveillanceAlert], error)
+	ReviewAlert(ctx context.Context, id string, status domain.AlertStatus, reviewer, note string) error
+	SubscribeSurveillanceAlerts(ctx context.Context) *pubsub.Subscription[*domain.SurveillanceAlert]
+}
+
+// Accounts are the client balances orders are funds-checked against
+type Accounts interface {
+	Balances(ctx context.Context, clientID string) ([]domain.Account, error)
+	Deposit(ctx context.Context, clientID, asset string, amount decimal.Decimal) error
+	Withdraw(ctx context.Context, clientID, asset string, amount decimal.Decimal) error
+}
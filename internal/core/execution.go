@@ -0,0 +1,213 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+var errExecutionMetricsOff = errors.New("execution metrics not enabled")
+
+var bps = decimal.NewFromInt(10_000)
+
+// timeToFillBuckets are the upper bounds of the time-to-fill distribution
+var timeToFillBuckets = []time.Duration{
+	time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond,
+	time.Second, 10 * time.Second, time.Minute, 10 * time.Minute, time.Hour,
+}
+
+// WithExecutionMetrics measures execution quality per client and per symbol from the order events
+// of orders that arrive while the engine runs. Each submission then also reads the top of the book,
+// for the mid price it arrived at.
+func WithExecutionMetrics() Option {
+	return func(e *Engine) { e.execution = newExecutionMetrics() }
+}
+
+// ExecutionStats returns the execution quality of one client's orders or, without clientID, of one
+// symbol's, within the ctx tenant
+func (e *Engine) ExecutionStats(ctx context.Context, clientID, symbol string) (*domain.ExecutionStats, error) {
+	if e.execution == nil {
+		return nil, errExecutionMetricsOff
+	}
+	if (clientID == "") == (symbol == "") {
+		return nil, errors.New("exactly one of client_id or symbol is required")
+	}
+	if clientID != "" {
+		return e.execution.stats(tenant.Scope(ctx, "client:"+clientID), clientID, ""), nil
+	}
+	return e.execution.stats(tenant.Scope(ctx, "symbol:"+symbol), "", symbol), nil
+}
+
+// arrivalMid is the symbol's mid price before o is matched; not valid if either side of the book is empty
+func (e *Engine) arrivalMid(ctx context.Context, symbol string) decimal.NullDecimal {
+	if e.execution == nil {
+		return decimal.NullDecimal{}
+	}
+	tob, err := e.topOfBook(ctx, symbol)
+	if err != nil {
+		return decimal.NullDecimal{}
+	}
+	return buildQuote(tob).Mid
+}
+
+// executionMetrics accumulates statistics keyed by tenant-scoped "client:<id>" and "symbol:<symbol>",
+// following each order from its arrival to the event that ends it
+type executionMetrics struct {
+	mu     sync.Mutex
+	orders map[string]*arrival
+	accums map[string]*execAccum
+}
+
+type arrival struct {
+	at       time.Time
+	mid      decimal.NullDecimal
+	quantity decimal.Decimal
+}
+
+type execAccum struct {
+	orders, rejected, filled, cancelled int64
+	quantity, filledQty                 decimal.Decimal
+	slippage, slippageQty               decimal.Decimal
+	spread, spreadQty                   decimal.Decimal
+	timeToFill                          []int64
+}
+
+func newExecutionMetrics() *executionMetrics {
+	return &executionMetrics{orders: make(map[string]*arrival), accums: make(map[string]*execAccum)}
+}
+
+// arrive starts following an accepted order; mid is the book's mid before it was matched
+func (m *executionMetrics) arrive(ctx context.Context, o *domain.Order, mid decimal.NullDecimal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders[tenant.Scope(ctx, o.ID)] = &arrival{at: o.CreatedAt, mid: mid}
+}
+
+// accumsOf returns the client's and the symbol's accumulators; the caller holds mu
+func (m *executionMetrics) accumsOf(tenantID string, ev *domain.OrderEvent) [2]*execAccum {
+	var out [2]*execAccum
+	for i, key := range []string{"client:" + ev.ClientID, "symbol:" + ev.Symbol} {
+		key = tenant.ScopeID(tenantID, key)
+		a, ok := m.accums[key]
+		if !ok {
+			a = &execAccum{timeToFill: make([]int64, len(timeToFillBuckets)+1)}
+			m.accums[key] = a
+		}
+		out[i] = a
+	}
+	return out
+}
+
+// observe folds one committed batch of events in; fills of an order arriving in the same batch took
+// liquidity and count towards its effective spread
+func (m *executionMetrics) observe(tenantID string, evs []*domain.OrderEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	arriving := make(map[string]bool)
+	for _, ev := range evs {
+		if ev.ExecType == domain.ExecRejected {
+			for _, a := range m.accumsOf(tenantID, ev) {
+				a.rejected++
+			}
+			continue
+		}
+		key := tenant.ScopeID(tenantID, ev.OrderID)
+		o, ok := m.orders[key]
+		if !ok {
+			// arrived before the engine started
+			continue
+		}
+		accums := m.accumsOf(tenantID, ev)
+		switch ev.ExecType {
+		case domain.ExecNew:
+			arriving[ev.OrderID] = true
+			o.quantity = ev.Quantity
+			for _, a := range accums {
+				a.orders++
+				a.quantity = a.quantity.Add(ev.Quantity)
+			}
+		case domain.ExecReplaced:
+			for _, a := range accums {
+				a.quantity = a.quantity.Add(ev.Quantity.Sub(o.quantity))
+			}
+			o.quantity = ev.Quantity
+		case domain.ExecPartialFill, domain.ExecFill:
+			for _, a := range accums {
+				a.fill(ev, o.mid, arriving[ev.OrderID])
+			}
+			if ev.ExecType == domain.ExecFill {
+				bucket := bucketOf(ev.Timestamp.Sub(o.at))
+				for _, a := range accums {
+					a.filled++
+					a.timeToFill[bucket]++
+				}
+				delete(m.orders, key)
+			}
+		case domain.ExecCanceled, domain.ExecExpired:
+			for _, a := range accums {
+				a.cancelled++
+			}
+			delete(m.orders, key)
+		}
+	}
+}
+
+func (a *execAccum) fill(ev *domain.OrderEvent, mid decimal.NullDecimal, aggressive bool) {
+	a.filledQty = a.filledQty.Add(ev.LastQty)
+	if !mid.Valid || !mid.Decimal.IsPositive() {
+		return
+	}
+	diff := ev.LastPrice.Sub(mid.Decimal).Div(mid.Decimal).Mul(bps)
+	if ev.Side == domain.Sell {
+		diff = diff.Neg()
+	}
+	a.slippage = a.slippage.Add(diff.Mul(ev.LastQty))
+	a.slippageQty = a.slippageQty.Add(ev.LastQty)
+	if aggressive {
+		a.spread = a.spread.Add(diff.Abs().Mul(two).Mul(ev.LastQty))
+		a.spreadQty = a.spreadQty.Add(ev.LastQty)
+	}
+}
+
+func bucketOf(d time.Duration) int {
+	for i, upTo := range timeToFillBuckets {
+		if d <= upTo {
+			return i
+		}
+	}
+	return len(timeToFillBuckets)
+}
+
+func (m *executionMetrics) stats(key, clientID, symbol string) *domain.ExecutionStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := &domain.ExecutionStats{ClientID: clientID, Symbol: symbol}
+	a, ok := m.accums[key]
+	if !ok {
+		a = &execAccum{timeToFill: make([]int64, len(timeToFillBuckets)+1)}
+	}
+	st.Orders, st.Rejected, st.Filled, st.Cancelled = a.orders, a.rejected, a.filled, a.cancelled
+	st.Quantity, st.FilledQuantity = a.quantity, a.filledQty
+	if a.quantity.IsPositive() {
+		st.FillRate = a.filledQty.Div(a.quantity)
+	}
+	if a.slippageQty.IsPositive() {
+		st.SlippageBps = a.slippage.Div(a.slippageQty)
+	}
+	if a.spreadQty.IsPositive() {
+		st.EffectiveSpreadBps = a.spread.Div(a.spreadQty)
+	}
+	for i, n := range a.timeToFill {
+		var upTo time.Duration
+		if i < len(timeToFillBuckets) {
+			upTo = timeToFillBuckets[i]
+		}
+		st.TimeToFill = append(st.TimeToFill, domain.DurationBucket{UpTo: upTo, Count: n})
+	}
+	return st
+}
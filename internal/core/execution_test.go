@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestExecutionStats(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithExecutionMetrics())
+	submit := func(id, client string, side domain.Side, price, qty int64) error {
+		_, err := e.SubmitOrder(ctx, &domain.Order{ID: id, ClientID: client, Symbol: "BTC/USD", Side: side,
+			Type: domain.Limit, Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(qty)})
+		return err
+	}
+	// the maker quotes 98/102 into an empty book, so its orders arrive without a mid
+	for _, err := range []error{
+		submit("bid", "maker", domain.Buy, 98, 1),
+		submit("ask", "maker", domain.Sell, 102, 2),
+		// arrives at mid 100, takes the ask at 102 and rests 1 at 103
+		submit("take", "taker", domain.Buy, 103, 3),
+	} {
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+	if _, err := e.CancelOrder(ctx, "bid", "maker"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if err := submit("bad", "taker", domain.Buy, 100, 0); err == nil {
+		t.Fatal("order without quantity accepted")
+	}
+
+	dec := decimal.RequireFromString
+	taker, err := e.ExecutionStats(ctx, "taker", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if taker.Orders != 1 || taker.Rejected != 1 || taker.Filled != 0 || taker.Cancelled != 0 {
+		t.Errorf("taker counts = %d/%d/%d/%d", taker.Orders, taker.Rejected, taker.Filled, taker.Cancelled)
+	}
+	if !taker.FilledQuantity.Equal(dec("2")) || !taker.FillRate.Round(4).Equal(dec("0.6667")) {
+		t.Errorf("taker filled %s, fill rate %s", taker.FilledQuantity, taker.FillRate)
+	}
+	// (102 - 100) / 100 is 200 bps worse than the arrival mid, twice that is the effective spread
+	if !taker.SlippageBps.Equal(dec("200")) || !taker.EffectiveSpreadBps.Equal(dec("400")) {
+		t.Errorf("taker slippage %s bps, effective spread %s bps", taker.SlippageBps, taker.EffectiveSpreadBps)
+	}
+
+	maker, err := e.ExecutionStats(ctx, "maker", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maker.Orders != 2 || maker.Filled != 1 || maker.Cancelled != 1 {
+		t.Errorf("maker counts = %d orders, %d filled, %d cancelled", maker.Orders, maker.Filled, maker.Cancelled)
+	}
+	if !maker.SlippageBps.IsZero() || !maker.EffectiveSpreadBps.IsZero() {
+		t.Errorf("maker without arrival mid has slippage %s, spread %s", maker.SlippageBps, maker.EffectiveSpreadBps)
+	}
+	var filled int64
+	for _, b := range maker.TimeToFill {
+		filled += b.Count
+	}
+	if filled != 1 {
+		t.Errorf("time to fill counts %d orders, want 1", filled)
+	}
+
+	symbol, err := e.ExecutionStats(ctx, "", "BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if symbol.Orders != 3 || !symbol.Quantity.Equal(dec("6")) || !symbol.FilledQuantity.Equal(dec("4")) {
+		t.Errorf("symbol: %d orders, quantity %s, filled %s", symbol.Orders, symbol.Quantity, symbol.FilledQuantity)
+	}
+
+	if _, err := e.ExecutionStats(ctx, "maker", "BTC/USD"); err == nil {
+		t.Error("client and symbol together accepted")
+	}
+	if _, err := NewEngine(memory.NewRepository(), nil).ExecutionStats(ctx, "maker", ""); err == nil {
+		t.Error("stats served without WithExecutionMetrics")
+	}
+}
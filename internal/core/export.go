@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+const snapshotExportPrefix = "snapshots/"
+
+var errNoObjectStore = errors.New("object store not configured")
+
+// WithObjectStore enables exporting snapshots to, and importing them from, a bucket
+func WithObjectStore(s port.ObjectStore) Option {
+	return func(e *Engine) { e.objects = s }
+}
+
+// ExportSnapshot copies a stored snapshot to the object store and returns its object key.
+// Keys are snapshots/<tenant>/<symbol>/<snapshot id>.json.
+func (e *Engine) ExportSnapshot(ctx context.Context, snapshotID string) (string, error) {
+	if e.objects == nil {
+		return "", errNoObjectStore
+	}
+	if e.cache == nil {
+		return "", errors.New("cache not configured")
+	}
+	data, err := e.cache.GetSnapshot(ctx, snapshotID)
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		return "", errSnapshotNotFound
+	}
+	ob, err := decodeSnapshot(data)
+	if err != nil {
+		return "", err
+	}
+	key := path.Join(snapshotExportPrefix, tenant.From(ctx), ob.Symbol, snapshotID+".json")
+	if err := e.objects.PutObject(ctx, key, data); err != nil {
+		return "", fmt.Errorf("export snapshot: %w", err)
+	}
+	return key, nil
+}
+
+// ImportSnapshot loads an exported snapshot, possibly written by another deployment or tenant,
+// and stores it as a new local snapshot ready for RestoreOrderbook
+func (e *Engine) ImportSnapshot(ctx context.Context, key string) (string, error) {
+	if e.objects == nil {
+		return "", errNoObjectStore
+	}
+	if e.cache == nil {
+		return "", errors.New("cache not configured")
+	}
+	data, err := e.objects.GetObject(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("import snapshot: %w", err)
+	}
+	if data == nil {
+		return "", fmt.Errorf("object %s not found", key)
+	}
+	ob, err := decodeSnapshot(data)
+	if err != nil {
+		return "", err
+	}
+	return e.storeSnapshot(ctx, ob, data)
+}
+
+// exportPos is the page position in a listing of exported snapshots
+type exportPos struct {
+	Key string `json:"key"`
+}
+
+// ListSnapshotExports lists a page of the ctx tenant's exported snapshots of a symbol, or of every symbol
+// if it is empty, in key order
+func (e *Engine) ListSnapshotExports(ctx context.Context, symbol string, req page.Request) (page.Page[domain.StoredObject], error) {
+	if e.objects == nil {
+		return page.Page[domain.StoredObject]{}, errNoObjectStore
+	}
+	prefix := path.Join(snapshotExportPrefix, tenant.From(ctx)) + "/"
+	if symbol != "" {
+		prefix += symbol + "/"
+	}
+	var pos exportPos
+	if ok, err := page.Decode(req.Cursor, &pos); err != nil || (ok && !strings.HasPrefix(pos.Key, prefix)) {
+		return page.Page[domain.StoredObject]{}, page.ErrInvalidCursor
+	}
+	objs, err := e.objects.ListObjects(ctx, prefix, pos.Key, page.Fetch(req.Limit))
+	if err != nil {
+		return page.Page[domain.StoredObject]{}, err
+	}
+	return page.Build(objs, req.Limit, func(o domain.StoredObject) any { return exportPos{Key: o.Key} }), nil
+}
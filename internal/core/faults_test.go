@@ -0,0 +1,175 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/chaos"
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/shopspring/decimal"
+)
+
+// mapCache is a port.Cache holding books in a map
+type mapCache struct {
+	port.Cache
+
+	mu    sync.Mutex
+	books map[string]*domain.OrderbookSnapshot
+}
+
+func (c *mapCache) SetOrderbook(ctx context.Context, symbol string, ob *domain.OrderbookSnapshot) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.books[symbol] = ob
+	return nil
+}
+
+func (c *mapCache) GetOrderbook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.books[symbol], nil
+}
+
+func (c *mapCache) Invalidate(ctx context.Context, symbol string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.books, symbol)
+	return nil
+}
+
+func faultyEngine(in *chaos.Injector) (*Engine, *memory.Repository) {
+	repo := memory.NewRepository()
+	cache := &mapCache{books: make(map[string]*domain.OrderbookSnapshot)}
+	return NewEngine(chaos.NewRepository(repo, in), chaos.NewCache(cache, in)), repo
+}
+
+func seedSell(t *testing.T, e *Engine, id string) {
+	t.Helper()
+	o := &domain.Order{ID: id, ClientID: "maker", Symbol: "BTC/USD", Side: domain.Sell, Type: domain.Limit,
+		Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)}
+	if _, err := e.SubmitOrder(context.Background(), o); err != nil {
+		t.Fatalf("seed %s: %v", id, err)
+	}
+}
+
+func TestSubmitOrderFailedCommitLeavesNothing(t *testing.T) {
+	tests := []struct {
+		name string
+		rule chaos.Rule
+	}{
+		{name: "commit fails after trades were saved", rule: chaos.Rule{Op: "Tx.Commit"}},
+		{name: "trade write fails", rule: chaos.Rule{Op: "Tx.SaveTrade"}},
+		{name: "final status write fails", rule: chaos.Rule{Op: "Tx.SaveOrder", Skip: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := chaos.NewInjector(1)
+			e, repo := faultyEngine(in)
+			seedSell(t, e, "s1")
+
+			in.Set(tt.rule)
+			if _, err := e.SubmitOrder(context.Background(), buy("b1", 100, 1)); !errors.Is(err, chaos.ErrInjected) {
+				t.Fatalf("submit error %v, want the injected fault", err)
+			}
+			ctx := context.Background()
+			if _, err := repo.LoadOrderByID(ctx, "b1"); err == nil {
+				t.Error("failed submission left the order behind")
+			}
+			if trades, _ := repo.LoadTradesForOrder(ctx, "s1", nil, 0); len(trades) != 0 {
+				t.Errorf("failed submission left %d trades behind", len(trades))
+			}
+
+			in.Set()
+			if _, err := e.SubmitOrder(ctx, buy("b1", 100, 1)); err != nil {
+				t.Fatalf("resubmit after healing: %v", err)
+			}
+			if got, _ := repo.LoadOrderByID(ctx, "s1"); got == nil || got.Status != domain.Filled {
+				t.Errorf("maker after resubmission: %v", got)
+			}
+		})
+	}
+}
+
+func TestSubmitOrderLostCommitAcknowledgement(t *testing.T) {
+	in := chaos.NewInjector(1)
+	e, repo := faultyEngine(in)
+	seedSell(t, e, "s1")
+
+	// the commit lands but its acknowledgement is lost: the caller sees an error and must look the
+	// order up before resubmitting it
+	in.Set(chaos.Rule{Op: "Tx.Commit", AfterCall: true})
+	if _, err := e.SubmitOrder(context.Background(), buy("b1", 100, 1)); !errors.Is(err, chaos.ErrInjected) {
+		t.Fatalf("submit error %v, want the injected fault", err)
+	}
+	got, err := repo.LoadOrderByID(context.Background(), "b1")
+	if err != nil || got.Status != domain.Filled {
+		t.Errorf("order after a lost acknowledgement: %v, %v, want FILLED", got, err)
+	}
+}
+
+func TestSubmitOrderRetriesConflicts(t *testing.T) {
+	in := chaos.NewInjector(1)
+	e, repo := faultyEngine(in)
+	seedSell(t, e, "s1")
+	ctx := context.Background()
+
+	// each aborted run rolled back its trade, so the run that commits fills the order exactly once
+	in.Set(chaos.Rule{Op: "Tx.Commit", Err: port.ErrTxConflict, Times: maxTxAttempts - 1})
+	trades, err := e.SubmitOrder(ctx, buy("b1", 100, 1))
+	if err != nil || len(trades) != 1 {
+		t.Fatalf("submit after conflicts: %d trades, %v, want 1 trade", len(trades), err)
+	}
+	if got, _ := repo.LoadOrderByID(ctx, "b1"); got == nil || got.Status != domain.Filled || !got.Remaining.IsZero() {
+		t.Errorf("order after retries: %+v, want FILLED", got)
+	}
+	if stored, _ := repo.LoadTradesForOrder(ctx, "s1", nil, 0); len(stored) != 1 {
+		t.Errorf("maker has %d trades, want 1", len(stored))
+	}
+
+	// a transaction that keeps losing gives up with the conflict
+	seedSell(t, e, "s2")
+	in.Set(chaos.Rule{Op: "Tx.Commit", Err: port.ErrTxConflict})
+	if _, err := e.SubmitOrder(ctx, buy("b2", 100, 1)); !errors.Is(err, port.ErrTxConflict) {
+		t.Fatalf("submit error %v, want the conflict", err)
+	}
+	if _, err := repo.LoadOrderByID(ctx, "b2"); err == nil {
+		t.Error("abandoned submission left the order behind")
+	}
+}
+
+func TestSubmitOrderSlowStorageTimesOut(t *testing.T) {
+	in := chaos.NewInjector(1, chaos.Rule{Op: "Tx.LockSymbol", Latency: time.Second, LatencyOnly: true})
+	e, repo := faultyEngine(in)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := e.SubmitOrder(ctx, buy("b1", 100, 1)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("submit error %v, want deadline exceeded", err)
+	}
+	if _, err := repo.LoadOrderByID(context.Background(), "b1"); err == nil {
+		t.Error("timed out submission left the order behind")
+	}
+}
+
+func TestFailedCacheWriteDoesNotServeStaleBook(t *testing.T) {
+	in := chaos.NewInjector(1)
+	e, _ := faultyEngine(in)
+	seedSell(t, e, "s1")
+
+	in.Set(chaos.Rule{Op: "Cache.SetOrderbook"})
+	seedSell(t, e, "s2")
+	in.Set()
+
+	ob, err := e.GetOrderbook(context.Background(), "BTC/USD")
+	if err != nil {
+		t.Fatalf("get orderbook: %v", err)
+	}
+	if len(ob.Asks) != 2 {
+		t.Errorf("book has %d asks, want 2: the failed cache write left the old book cached", len(ob.Asks))
+	}
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// WithFeeSchedule sets the maker/taker fees charged on every execution
+func WithFeeSchedule(fs domain.FeeSchedule) Option {
+	return func(e *Engine) { e.fees = fs }
+}
+
+// applyFees charges the ctx tenant's fees on t's notional, in the symbol's quote asset
+func (e *Engine) applyFees(ctx context.Context, t *domain.Trade) {
+	fs := e.feeSchedule(ctx)
+	t.MakerFee = fs.MakerFee(t.Price, t.Quantity)
+	t.TakerFee = fs.TakerFee(t.Price, t.Quantity)
+	if _, quote, err := e.assets(ctx, t.Symbol); err == nil {
+		t.MakerFeeAsset, t.TakerFeeAsset = quote, quote
+	}
+}
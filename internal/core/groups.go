@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// maxGroupLegs bounds the orders of one basket
+const maxGroupLegs = 20
+
+// errGroupAborted is the outcome of the legs left unsubmitted because the group was cancelled
+var errGroupAborted = errors.New("not submitted: the group was cancelled")
+
+// GroupResult is the outcome of a basket: its group ID and the result of every leg in input order
+type GroupResult struct {
+	GroupID string
+	Legs    []SubmitResult
+}
+
+// SubmitOrderGroup submits the legs, possibly of different symbols, as a basket under a new group ID.
+// Every leg is checked first and one failing check rejects the whole basket with nothing submitted;
+// the legs are then submitted in order. A leg failing at submission cancels the legs already resting
+// and the rest are not submitted. Fills made before that stand. With cancelOnFill, the first leg to
+// fill completely cancels the others, whether resting or not yet submitted.
+func (e *Engine) SubmitOrderGroup(ctx context.Context, legs []*domain.Order, cancelOnFill bool) (GroupResult, error) {
+	if len(legs) == 0 {
+		return GroupResult{}, domain.Reject(domain.RejectInvalidOrder, "order group has no legs")
+	}
+	if len(legs) > maxGroupLegs {
+		return GroupResult{}, domain.Reject(domain.RejectInvalidOrder, "order group has %d legs, at most %d allowed", len(legs), maxGroupLegs)
+	}
+	clientID := legs[0].ClientID
+	for i, o := range legs {
+		if o.ClientID != clientID {
+			return GroupResult{}, domain.Reject(domain.RejectInvalidOrder, "leg %d: all legs must belong to client %s", i, clientID)
+		}
+		if err := e.ValidateOrder(ctx, o); err != nil {
+			return GroupResult{}, fmt.Errorf("leg %d: %w", i, err)
+		}
+	}
+
+	res := GroupResult{GroupID: uuid.NewString(), Legs: make([]SubmitResult, len(legs))}
+	for _, o := range legs {
+		o.GroupID, o.CancelGroupOnFill = res.GroupID, cancelOnFill
+	}
+	var (
+		failed error
+		filled bool
+	)
+	for i, o := range legs {
+		res.Legs[i].Order = o
+		if failed != nil || filled {
+			res.Legs[i].Err = errGroupAborted
+			continue
+		}
+		res.Legs[i].Trades, res.Legs[i].Err = e.SubmitOrder(ctx, o)
+		// a leg filling on entry has already cancelled the resting ones
+		filled = cancelOnFill && o.Status == domain.Filled
+		if res.Legs[i].Err != nil {
+			failed = fmt.Errorf("leg %d: %w", i, res.Legs[i].Err)
+			if _, err := e.cancelGroup(ctx, clientID, res.GroupID, "another leg of the group was rejected", domain.CauseCancelledByUser); err != nil {
+				failed = errors.Join(failed, err)
+			}
+		}
+	}
+	if failed == nil && !filled && cancelOnFill && e.groupFilled(ctx, legs) {
+		// a resting leg may have filled while later legs were still being placed
+		_, failed = e.cancelGroup(ctx, clientID, res.GroupID, "another leg of the group filled", domain.CauseGroupFilled)
+	}
+	return res, failed
+}
+
+// groupFilled reports whether one of the legs has filled completely since it was submitted
+func (e *Engine) groupFilled(ctx context.Context, legs []*domain.Order) bool {
+	for _, o := range legs {
+		if cur, err := e.repo.LoadOrderByID(ctx, o.ID); err == nil && cur.Status == domain.Filled {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelOrderGroup cancels every resting order of the client's group, whichever symbol it is on, and
+// returns their IDs
+func (e *Engine) CancelOrderGroup(ctx context.Context, clientID, groupID string) ([]string, error) {
+	if groupID == "" {
+		return nil, errors.New("group_id is required")
+	}
+	return e.cancelGroup(ctx, clientID, groupID, "group cancelled by client", domain.CauseCancelledByUser)
+}
+
+// cancelGroup cancels the group's resting orders on the worker of each symbol, publishing what was
+// cancelled before a failure all the same
+func (e *Engine) cancelGroup(ctx context.Context, clientID, groupID, reason string, cause domain.TransitionCause) ([]string, error) {
+	var symbols []string
+	if e.sharded() {
+		var err error
+		if symbols, err = e.repo.ListSymbols(ctx); err != nil {
+			return nil, err
+		}
+	}
+	cancelled, err := e.cancelOnSymbolWorkers(ctx, symbols, func(symbol string) (map[string]string, error) {
+		return e.repo.CancelGroupOrders(ctx, clientID, groupID, symbol)
+	})
+	ids := make([]string, 0, len(cancelled))
+	touched := make(map[string]struct{})
+	events := make([]*domain.OrderEvent, 0, len(cancelled))
+	for id, sym := range cancelled {
+		ids = append(ids, id)
+		touched[sym] = struct{}{}
+		ev := cancelledEvent(id, clientID, sym, "", reason)
+		ev.Cause, ev.GroupID = cause, groupID
+		events = append(events, ev)
+	}
+	for sym := range touched {
+		e.bookChanged(ctx, sym)
+	}
+	e.emit(ctx, events...)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// completeGroups cancels the rest of every group whose cancel-on-fill leg the events filled completely.
+// It runs on the worker of the fill's symbol, so on a sharded engine the cancel, which waits for the
+// workers of the legs' symbols, goes to a goroutine of its own; a shutdown still waits for it.
+func (e *Engine) completeGroups(ctx context.Context, events []*domain.OrderEvent) {
+	for _, ev := range events {
+		if ev.GroupID == "" || ev.ExecType != domain.ExecFill {
+			continue
+		}
+		o, err := e.repo.LoadOrderByID(ctx, ev.OrderID)
+		if err != nil || !o.CancelGroupOnFill {
+			continue
+		}
+		// a failed cancel leaves the legs resting; the client can still cancel the group
+		if !e.sharded() {
+			_, _ = e.cancelGroup(ctx, ev.ClientID, ev.GroupID, "another leg of the group filled", domain.CauseGroupFilled)
+			continue
+		}
+		e.ops.start()
+		go func(clientID, groupID string) {
+			defer e.ops.done()
+			_, _ = e.cancelGroup(context.WithoutCancel(ctx), clientID, groupID, "another leg of the group filled", domain.CauseGroupFilled)
+		}(ev.ClientID, ev.GroupID)
+	}
+}
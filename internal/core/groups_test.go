@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func groupLeg(id, clientID, symbol string, side domain.Side, price int64) *domain.Order {
+	return &domain.Order{ID: id, ClientID: clientID, Symbol: symbol, Side: side, Type: domain.Limit,
+		Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(1)}
+}
+
+func TestOrderGroupRejectedAsUnit(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	bad := groupLeg("l2", "c", "ETH/USD", domain.Buy, 100)
+	bad.Quantity = decimal.Zero
+	_, err := e.SubmitOrderGroup(ctx, []*domain.Order{groupLeg("l1", "c", "BTC/USD", domain.Buy, 100), bad}, false)
+	if domain.RejectCodeOf(err) != domain.RejectInvalidQuantity {
+		t.Fatalf("submitting a group with an invalid leg: %v", err)
+	}
+	if o, err := e.GetOrder(ctx, "l1"); err == nil {
+		t.Errorf("valid leg of a rejected group was submitted: %+v", o)
+	}
+	_, err = e.SubmitOrderGroup(ctx, []*domain.Order{groupLeg("m1", "c", "BTC/USD", domain.Buy, 100), groupLeg("m2", "d", "BTC/USD", domain.Buy, 100)}, false)
+	if !domain.IsReject(err) {
+		t.Errorf("submitting legs of two clients: %v", err)
+	}
+}
+
+func TestOrderGroupCancel(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	res, err := e.SubmitOrderGroup(ctx, []*domain.Order{
+		groupLeg("l1", "c", "BTC/USD", domain.Buy, 100),
+		groupLeg("l2", "c", "ETH/USD", domain.Sell, 200),
+	}, false)
+	if err != nil || res.GroupID == "" || len(res.Legs) != 2 {
+		t.Fatalf("submit group: %+v, %v", res, err)
+	}
+	ids, err := e.CancelOrderGroup(ctx, "c", res.GroupID)
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("cancel group: %v, %v", ids, err)
+	}
+	for _, id := range []string{"l1", "l2"} {
+		if o, err := e.GetOrder(ctx, id); err != nil || o.Status != domain.Cancelled || o.GroupID != res.GroupID {
+			t.Errorf("leg %s read back as %+v, %v", id, o, err)
+		}
+	}
+}
+
+func TestOrderGroupCancelOnFill(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	res, err := e.SubmitOrderGroup(ctx, []*domain.Order{
+		groupLeg("l1", "c", "BTC/USD", domain.Buy, 100),
+		groupLeg("l2", "c", "ETH/USD", domain.Buy, 200),
+	}, true)
+	if err != nil {
+		t.Fatalf("submit group: %v", err)
+	}
+	// a resting leg filled by another client cancels the other leg
+	if _, err := e.SubmitOrder(ctx, groupLeg("s", "d", "BTC/USD", domain.Sell, 100)); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if o, _ := e.GetOrder(ctx, "l1"); o == nil || o.Status != domain.Filled {
+		t.Fatalf("filled leg read back as %+v", o)
+	}
+	if o, _ := e.GetOrder(ctx, "l2"); o == nil || o.Status != domain.Cancelled {
+		t.Errorf("other leg read back as %+v, want it cancelled", o)
+	}
+	hist, err := e.OrderHistory(ctx, "l2", "c")
+	if err != nil || hist[len(hist)-1].Cause != domain.CauseGroupFilled {
+		t.Errorf("history of l2 %+v, %v, want it to end with the group filled", hist, err)
+	}
+
+	// a leg filling on entry keeps the later legs from being submitted
+	if _, err := e.SubmitOrder(ctx, groupLeg("r", "d", "ETH/USD", domain.Sell, 300)); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	res, err = e.SubmitOrderGroup(ctx, []*domain.Order{
+		groupLeg("k1", "c", "ETH/USD", domain.Buy, 300),
+		groupLeg("k2", "c", "BTC/USD", domain.Buy, 90),
+	}, true)
+	if err != nil || res.Legs[0].Order.Status != domain.Filled || res.Legs[1].Err == nil {
+		t.Errorf("group filling on entry: %+v, %v", res, err)
+	}
+	if o, err := e.GetOrder(ctx, "k2"); err == nil {
+		t.Errorf("leg after a filled one was submitted: %+v", o)
+	}
+}
+
+func TestOrderGroupOnSymbolLoops(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithSymbolLoops())
+	res, err := e.SubmitOrderGroup(ctx, []*domain.Order{
+		groupLeg("l1", "c", "BTC/USD", domain.Buy, 100),
+		groupLeg("l2", "c", "ETH/USD", domain.Buy, 200),
+		groupLeg("l3", "c", "BTC/USD", domain.Buy, 90),
+	}, true)
+	if err != nil {
+		t.Fatalf("submit group: %v", err)
+	}
+	// the fill on BTC/USD's loop cancels the legs on both symbols' loops, and the drain waits for it
+	if _, err := e.SubmitOrder(ctx, groupLeg("s", "d", "BTC/USD", domain.Sell, 100)); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if err := e.Drain(ctx); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	for _, id := range []string{"l2", "l3"} {
+		if o, _ := e.GetOrder(ctx, id); o == nil || o.Status != domain.Cancelled {
+			t.Errorf("leg %s read back as %+v, want it cancelled", id, o)
+		}
+	}
+	if ids, err := e.CancelOrderGroup(ctx, "c", res.GroupID); !errors.Is(err, ErrSymbolLoopsStopped) || len(ids) != 0 {
+		t.Errorf("cancel group after the drain: %v, %v", ids, err)
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+// SubscribeImbalance subscribes to imbalance updates of the ctx tenant's book produced by RunImbalanceFeed
+func (e *Engine) SubscribeImbalance(ctx context.Context, symbol string) *pubsub.Subscription[*domain.Imbalance] {
+	return e.imbalances.Subscribe(tenant.Scope(ctx, symbol))
+}
+
+// RunImbalanceFeed publishes the imbalance of every subscribed symbol each interval until ctx is done
+func (e *Engine) RunImbalanceFeed(ctx context.Context, interval time.Duration, levels int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, topic := range e.imbalances.Topics() {
+			tenantID, symbol := tenant.Split(topic)
+			ob, err := e.GetOrderbook(tenant.With(ctx, tenantID), symbol)
+			if err != nil {
+				continue
+			}
+			e.imbalances.Publish(topic, computeImbalance(ob.DeepCopy(), levels))
+		}
+	}
+}
+
+func computeImbalance(ob *domain.OrderbookSnapshot, levels int) *domain.Imbalance {
+	sortOrders(ob)
+	bid := levelVolume(ob.Bids, levels)
+	ask := levelVolume(ob.Asks, levels)
+	imb := decimal.Zero
+	if total := bid.Add(ask); total.IsPositive() {
+		imb = bid.Sub(ask).Div(total)
+	}
+	return &domain.Imbalance{
+		Symbol:    ob.Symbol,
+		Levels:    levels,
+		BidVolume: bid,
+		AskVolume: ask,
+		Imbalance: imb,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// levelVolume sums remaining quantity over the first n distinct prices of price-sorted orders
+func levelVolume(orders []domain.Order, n int) decimal.Decimal {
+	vol := decimal.Zero
+	seen := 0
+	for i, o := range orders {
+		if i == 0 || !o.Price.Equal(orders[i-1].Price) {
+			seen++
+		}
+		if seen > n {
+			break
+		}
+		vol = vol.Add(o.Remaining)
+	}
+	return vol
+}
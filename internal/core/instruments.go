@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+// instruments caches each tenant's instrument registry, loaded on first use and reloaded whenever
+// the engine saves an instrument, so order checks do not query the database
+type instruments struct {
+	mu       sync.RWMutex
+	byTenant map[string]map[string]domain.Instrument
+}
+
+func newInstruments() *instruments {
+	return &instruments{byTenant: make(map[string]map[string]domain.Instrument)}
+}
+
+func (e *Engine) loadInstruments(ctx context.Context) (map[string]domain.Instrument, error) {
+	list, err := e.repo.LoadInstruments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]domain.Instrument, len(list))
+	for _, in := range list {
+		m[in.Symbol] = in
+	}
+	e.instruments.mu.Lock()
+	e.instruments.byTenant[tenant.From(ctx)] = m
+	e.instruments.mu.Unlock()
+	return m, nil
+}
+
+func (e *Engine) tenantInstruments(ctx context.Context) (map[string]domain.Instrument, error) {
+	e.instruments.mu.RLock()
+	m, ok := e.instruments.byTenant[tenant.From(ctx)]
+	e.instruments.mu.RUnlock()
+	if ok {
+		return m, nil
+	}
+	return e.loadInstruments(ctx)
+}
+
+// checkInstrument rejects an order price, trigger price (zero if none) and quantity the symbol's
+// instrument does not accept. A tenant without instruments takes any symbol; once it registers one,
+// only registered symbols trade.
+func (e *Engine) checkInstrument(ctx context.Context, symbol string, price, trigger, quantity decimal.Decimal) error {
+	m, err := e.tenantInstruments(ctx)
+	if err != nil || len(m) == 0 {
+		return err
+	}
+	in, ok := m[symbol]
+	if !ok {
+		return domain.Reject(domain.RejectUnknownSymbol, "symbol %s is not a registered instrument", symbol)
+	}
+	if err := in.CheckOrder(price, quantity); err != nil {
+		return err
+	}
+	return in.CheckPrice(trigger)
+}
+
+// assets returns the base and quote asset of the symbol: those of its instrument if it is
+// registered, otherwise the two halves of a BASE/QUOTE symbol
+func (e *Engine) assets(ctx context.Context, symbol string) (base, quote string, err error) {
+	m, err := e.tenantInstruments(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	if in, ok := m[symbol]; ok {
+		return in.BaseAsset, in.QuoteAsset, nil
+	}
+	return splitSymbol(symbol)
+}
+
+// SaveInstrument registers the instrument in the ctx tenant or replaces its definition. Assets
+// default to the halves of a BASE/QUOTE symbol and the status to TRADING.
+func (e *Engine) SaveInstrument(ctx context.Context, in domain.Instrument) (domain.Instrument, error) {
+	if in.BaseAsset == "" && in.QuoteAsset == "" {
+		in.BaseAsset, in.QuoteAsset, _ = strings.Cut(in.Symbol, "/")
+	}
+	if in.Status == "" {
+		in.Status = domain.InstrumentTrading
+	}
+	if err := in.Validate(); err != nil {
+		return domain.Instrument{}, err
+	}
+	in.UpdatedAt = time.Now().UTC()
+	if err := e.repo.SaveInstrument(ctx, in); err != nil {
+		return domain.Instrument{}, err
+	}
+	_, err := e.loadInstruments(ctx)
+	return in, err
+}
+
+// Instruments returns the ctx tenant's instruments ordered by symbol
+func (e *Engine) Instruments(ctx context.Context) ([]domain.Instrument, error) {
+	return e.repo.LoadInstruments(ctx)
+}
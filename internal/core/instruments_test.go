@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestInstrumentsValidateOrders(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	order := func(symbol, price, qty string) *domain.Order {
+		o := &domain.Order{ClientID: "c", Symbol: symbol, Side: domain.Buy, Type: domain.Limit,
+			Price: decimal.RequireFromString(price), Quantity: decimal.RequireFromString(qty)}
+		if o.Price.IsZero() {
+			o.Type = domain.Market
+		}
+		return o
+	}
+	if err := e.ValidateOrder(ctx, order("ANY/THING", "1.234", "0.1")); err != nil {
+		t.Fatalf("without instruments any symbol trades: %v", err)
+	}
+
+	in, err := e.SaveInstrument(ctx, domain.Instrument{Symbol: "BTC/USD", TickSize: decimal.RequireFromString("0.5"),
+		LotSize: decimal.RequireFromString("0.01"), MinNotional: decimal.NewFromInt(10)})
+	if err != nil {
+		t.Fatalf("save instrument: %v", err)
+	}
+	if in.BaseAsset != "BTC" || in.QuoteAsset != "USD" || in.Status != domain.InstrumentTrading {
+		t.Errorf("defaults %+v, want BTC/USD TRADING", in)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		order *domain.Order
+		want  domain.RejectCode
+	}{
+		{"on the grid", order("BTC/USD", "100.5", "0.2"), ""},
+		{"market", order("BTC/USD", "0", "0.01"), ""},
+		{"unregistered symbol", order("ANY/THING", "1", "1"), domain.RejectUnknownSymbol},
+		{"off tick", order("BTC/USD", "100.2", "0.2"), domain.RejectInvalidTick},
+		{"off lot", order("BTC/USD", "100", "0.205"), domain.RejectInvalidQuantity},
+		{"below notional", order("BTC/USD", "100", "0.05"), domain.RejectMinNotional},
+	} {
+		err := e.ValidateOrder(ctx, tc.order)
+		if tc.want == "" && err != nil {
+			t.Errorf("%s: rejected: %v", tc.name, err)
+		} else if tc.want != "" && domain.RejectCodeOf(err) != tc.want {
+			t.Errorf("%s: %v, want %s", tc.name, err, tc.want)
+		}
+	}
+
+	resting := order("BTC/USD", "100", "0.2")
+	if _, err := e.SubmitOrder(ctx, resting); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if _, err := e.ModifyOrder(ctx, resting.ID, "c", decimal.RequireFromString("100.25"), resting.Quantity); domain.RejectCodeOf(err) != domain.RejectInvalidTick {
+		t.Errorf("modify off tick: %v", err)
+	}
+
+	in.Status = domain.InstrumentHalted
+	if _, err := e.SaveInstrument(ctx, in); err != nil {
+		t.Fatalf("halt instrument: %v", err)
+	}
+	if err := e.ValidateOrder(ctx, order("BTC/USD", "100", "0.2")); domain.RejectCodeOf(err) != domain.RejectSymbolHalted {
+		t.Errorf("halted instrument: %v, want SYMBOL_HALTED", err)
+	}
+	if ok, err := e.CancelOrder(ctx, resting.ID, "c"); err != nil || !ok {
+		t.Errorf("cancel on a halted instrument: %v, %v", ok, err)
+	}
+}
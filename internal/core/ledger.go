@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+)
+
+// saveOrder appends the order's new state to the ledger as kind, then writes it; both commit or
+// roll back together
+func saveOrder(ctx context.Context, tx port.Tx, kind domain.LedgerKind, o *domain.Order) error {
+	if err := tx.AppendLedger(ctx, []domain.LedgerEvent{domain.OrderLedgerEvent(kind, o)}); err != nil {
+		return err
+	}
+	return tx.SaveOrder(ctx, o)
+}
+
+// saveTrade appends the trade to the ledger, then writes it
+func saveTrade(ctx context.Context, tx port.Tx, t *domain.Trade) error {
+	if err := tx.AppendLedger(ctx, []domain.LedgerEvent{domain.TradeLedgerEvent(t)}); err != nil {
+		return err
+	}
+	return tx.SaveTrade(ctx, t)
+}
+
+// saveMatched writes a taker's state after matching, recorded as OrderMatched if it traded; without
+// trades it is unchanged since it was accepted
+func saveMatched(ctx context.Context, tx port.Tx, o *domain.Order, executed []*domain.Trade) error {
+	if len(executed) == 0 {
+		return tx.SaveOrder(ctx, o)
+	}
+	return saveOrder(ctx, tx, domain.OrderMatched, o)
+}
+
+// ReplayOrderbook rebuilds the symbol's book from the ledger alone, without reading the order
+// tables or the cache; it reflects only what was written since the ledger was introduced
+func (e *Engine) ReplayOrderbook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	r := domain.NewLedgerReplay()
+	err := e.repo.ScanLedger(ctx, symbol, 0, func(ev domain.LedgerEvent) error {
+		r.Apply(ev)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Book(symbol), nil
+}
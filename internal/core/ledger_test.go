@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestReplayOrderbookMatchesTheBook(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepository()
+	e := NewEngine(repo, nil)
+	place := func(clientID string, side domain.Side, typ domain.OrderType, price, qty int64) *domain.Order {
+		t.Helper()
+		o := &domain.Order{ClientID: clientID, Symbol: "BTC/USD", Side: side, Type: typ,
+			Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(qty)}
+		if typ == domain.Stop {
+			o.Price, o.TriggerPrice = decimal.Zero, decimal.NewFromInt(price)
+		}
+		if _, err := e.SubmitOrder(ctx, o); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		return o
+	}
+
+	place("a", domain.Sell, domain.Limit, 101, 3)
+	place("a", domain.Sell, domain.Limit, 102, 2)
+	cancelled := place("b", domain.Buy, domain.Limit, 95, 1)
+	modified := place("b", domain.Buy, domain.Limit, 96, 1)
+	place("c", domain.Buy, domain.Stop, 101, 1)
+	place("d", domain.Buy, domain.Limit, 101, 2) // partially fills the 101 ask and sets off the stop
+	place("b", domain.Buy, domain.Limit, 90, 1)
+	if _, err := e.CancelOrder(ctx, cancelled.ID, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.ModifyOrder(ctx, modified.ID, "b", decimal.NewFromInt(97), decimal.NewFromInt(4)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.CancelBySide(ctx, "b", "BTC/USD", domain.Buy); err != nil {
+		t.Fatal(err)
+	}
+	place("e", domain.Buy, domain.Limit, 93, 5)
+
+	want, err := repo.LoadSnapshot(ctx, "BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := e.ReplayOrderbook(ctx, "BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	same := func(side string, got, want []domain.Order) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s: replayed %d orders, book has %d", side, len(got), len(want))
+		}
+		for i := range want {
+			if got[i].ID != want[i].ID || !got[i].Remaining.Equal(want[i].Remaining) || got[i].Status != want[i].Status {
+				t.Errorf("%s[%d]: replayed %s %s %s, book has %s %s %s", side, i,
+					got[i].ID, got[i].Remaining, got[i].Status, want[i].ID, want[i].Remaining, want[i].Status)
+			}
+		}
+	}
+	sortOrders(want)
+	same("bids", got.Bids, want.Bids)
+	same("asks", got.Asks, want.Asks)
+	if len(got.Asks) != 1 || !got.Asks[0].Price.Equal(decimal.NewFromInt(102)) || len(got.Bids) != 1 {
+		t.Errorf("replayed book %+v, want the 102 ask and the 93 bid", got)
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/shopspring/decimal"
+)
+
+func TestListOrdersPagesByCursor(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	place := func(client string, side domain.Side, price int64) *domain.Order {
+		t.Helper()
+		o := &domain.Order{ClientID: client, Symbol: "BTC/USD", Side: side, Type: domain.Limit,
+			Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(1)}
+		if _, err := e.SubmitOrder(ctx, o); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		return o
+	}
+	ask := place("mm", domain.Sell, 110)
+	bid := place("mm", domain.Buy, 90)
+	place("taker", domain.Buy, 110)
+	last := place("mm", domain.Buy, 95)
+
+	var got []string
+	req := page.Request{Limit: 2}
+	for {
+		p, err := e.ListOrders(ctx, domain.OrderFilter{ClientID: "mm"}, req)
+		if err != nil {
+			t.Fatalf("list orders: %v", err)
+		}
+		for _, o := range p.Items {
+			got = append(got, o.ID)
+		}
+		if p.NextCursor == "" {
+			break
+		}
+		req.Cursor = p.NextCursor
+	}
+	if sortedList(got) != sortedList([]string{ask.ID, bid.ID, last.ID}) || len(got) != 3 {
+		t.Fatalf("listed %v, want the three orders of mm", got)
+	}
+
+	open, err := e.ListOrders(ctx, domain.OrderFilter{ClientID: "mm", Status: domain.Open}, page.Request{Limit: 10})
+	if err != nil || len(open.Items) != 2 || open.Items[0].ID != bid.ID || open.Items[1].ID != last.ID {
+		t.Errorf("open orders %+v, %v; want %s and %s", open.Items, err, bid.ID, last.ID)
+	}
+	if _, err := e.ListOrders(ctx, domain.OrderFilter{}, page.Request{}); err == nil {
+		t.Error("listed orders without a client")
+	}
+	if _, err := e.ListOrders(ctx, domain.OrderFilter{ClientID: "mm", Status: "DONE"}, page.Request{}); err == nil {
+		t.Error("listed orders with an invalid status")
+	}
+	if _, err := e.ListOrders(ctx, domain.OrderFilter{ClientID: "mm"}, page.Request{Cursor: "x"}); !errors.Is(err, page.ErrInvalidCursor) {
+		t.Errorf("bad cursor: %v, want ErrInvalidCursor", err)
+	}
+}
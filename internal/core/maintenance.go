@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// ErrCancelOnly wraps the rejection of submissions and modifications in cancel-only mode
+var ErrCancelOnly = errors.New("cancel-only mode")
+
+// maintenance holds the engine-wide cancel-only switch and the per-symbol ones, keyed by
+// tenant-scoped symbol. It lives in memory: each instance is switched through its admin API.
+type maintenance struct {
+	mu      sync.RWMutex
+	global  *domain.CancelOnly
+	symbols map[string]domain.CancelOnly
+}
+
+func newMaintenance() *maintenance {
+	return &maintenance{symbols: make(map[string]domain.CancelOnly)}
+}
+
+// SetCancelOnly switches cancel-only mode on or off for a symbol of the ctx tenant, or for the whole
+// engine when symbol is empty
+func (e *Engine) SetCancelOnly(ctx context.Context, symbol string, on bool, reason string) {
+	m := e.maintenance
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mode := domain.CancelOnly{Symbol: symbol, Reason: reason, Since: time.Now().UTC()}
+	switch {
+	case symbol == "" && on:
+		m.global = &mode
+	case symbol == "":
+		m.global = nil
+	case on:
+		m.symbols[tenant.Scope(ctx, symbol)] = mode
+	default:
+		delete(m.symbols, tenant.Scope(ctx, symbol))
+	}
+}
+
+// CancelOnlyModes returns the engine-wide mode, if on, followed by the ctx tenant's symbols in
+// cancel-only mode sorted by symbol
+func (e *Engine) CancelOnlyModes(ctx context.Context) []domain.CancelOnly {
+	m := e.maintenance
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []domain.CancelOnly
+	if m.global != nil {
+		out = append(out, *m.global)
+	}
+	var symbols []domain.CancelOnly
+	for key, mode := range m.symbols {
+		if tenantID, _ := tenant.Split(key); tenantID == tenant.From(ctx) {
+			symbols = append(symbols, mode)
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].Symbol < symbols[j].Symbol })
+	return append(out, symbols...)
+}
+
+// checkCancelOnly rejects changes to the book of symbol while it or the engine is cancel-only
+func (e *Engine) checkCancelOnly(ctx context.Context, symbol string) error {
+	m := e.maintenance
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.global != nil {
+		return cancelOnlyError("", m.global.Reason)
+	}
+	if mode, ok := m.symbols[tenant.Scope(ctx, symbol)]; ok {
+		return cancelOnlyError(symbol, mode.Reason)
+	}
+	return nil
+}
+
+func cancelOnlyError(symbol, reason string) error {
+	msg := "only cancels are accepted"
+	if symbol != "" {
+		msg = fmt.Sprintf("only cancels are accepted for %s", symbol)
+	}
+	if reason != "" {
+		msg += ": " + reason
+	}
+	return fmt.Errorf("%w, %w", ErrCancelOnly, domain.Reject(domain.RejectCancelOnly, "%s", msg))
+}
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+func (e *Engine) GetNotificationPreferences(ctx context.Context, clientID string) ([]domain.NotificationPreference, error) {
+	return e.repo.LoadNotificationPreferences(ctx, clientID)
+}
+
+func (e *Engine) SetNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	switch p.Channel {
+	case domain.ChannelWebhook:
+		if p.Target == "" {
+			return fmt.Errorf("webhook preference needs a target URL")
+		}
+	case domain.ChannelWebSocket, domain.ChannelLog:
+	default:
+		return fmt.Errorf("invalid channel: %s", p.Channel)
+	}
+	return e.repo.SaveNotificationPreference(ctx, p)
+}
+
+func (e *Engine) DeleteNotificationPreference(ctx context.Context, p domain.NotificationPreference) error {
+	return e.repo.DeleteNotificationPreference(ctx, p)
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultOrderFlowWindow = 30 * time.Second
+	defaultMinCancels      = 20
+	defaultLayeringLevels  = 3
+)
+
+var (
+	defaultCancelRatio    = decimal.RequireFromString("0.95")
+	defaultSpoofFactor    = decimal.NewFromInt(10)
+	defaultPriceImpactBps = decimal.NewFromInt(200)
+)
+
+// alertsTopic is the tenant-scoped topic of SubscribeSurveillanceAlerts
+const alertsTopic = "alerts"
+
+// orderFlow follows what each client placed, cancelled and traded in each symbol within the policy's
+// OrderFlowWindow, for the checks that run on every committed batch of order events
+type orderFlow struct {
+	mu      sync.Mutex
+	placed  map[string]*placement // tenant-scoped order ID -> order placed within the window
+	expiry  []placedKey           // placed in arrival order, to forget orders once the window passes them
+	clients map[string]*clientFlow
+}
+
+type placement struct {
+	at        time.Time
+	side      domain.Side
+	price     decimal.Decimal
+	remaining decimal.Decimal
+}
+
+type placedKey struct {
+	key string
+	at  time.Time
+}
+
+// clientFlow is one client's activity in one symbol, oldest first
+type clientFlow struct {
+	placed  []time.Time
+	cancels []flowCancel
+	fills   []flowFill
+	raised  map[domain.AlertKind]time.Time
+}
+
+type flowCancel struct {
+	at     time.Time
+	side   domain.Side
+	price  decimal.Decimal
+	qty    decimal.Decimal
+	recent bool // placed within the window, so its price and quantity are known
+}
+
+type flowFill struct {
+	at      time.Time
+	side    domain.Side
+	qty     decimal.Decimal
+	tradeID string
+}
+
+func newOrderFlow() *orderFlow {
+	return &orderFlow{placed: make(map[string]*placement), clients: make(map[string]*clientFlow)}
+}
+
+// flowOf returns the client's activity in the event's symbol with everything before since dropped;
+// the caller holds mu
+func (f *orderFlow) flowOf(tenantID string, ev *domain.OrderEvent, since time.Time) *clientFlow {
+	key := tenant.ScopeID(tenantID, ev.ClientID+"|"+ev.Symbol)
+	cf, ok := f.clients[key]
+	if !ok {
+		cf = &clientFlow{raised: make(map[domain.AlertKind]time.Time)}
+		f.clients[key] = cf
+	}
+	for len(cf.placed) > 0 && cf.placed[0].Before(since) {
+		cf.placed = cf.placed[1:]
+	}
+	for len(cf.cancels) > 0 && cf.cancels[0].at.Before(since) {
+		cf.cancels = cf.cancels[1:]
+	}
+	for len(cf.fills) > 0 && cf.fills[0].at.Before(since) {
+		cf.fills = cf.fills[1:]
+	}
+	return cf
+}
+
+// expire forgets the placements older than since; the caller holds mu
+func (f *orderFlow) expire(since time.Time) {
+	for len(f.expiry) > 0 && f.expiry[0].at.Before(since) {
+		if p, ok := f.placed[f.expiry[0].key]; ok && p.at.Equal(f.expiry[0].at) {
+			delete(f.placed, f.expiry[0].key)
+		}
+		f.expiry = f.expiry[1:]
+	}
+}
+
+// aggression is the fills of an order in the batch that placed it
+type aggression struct {
+	ev       *domain.OrderEvent
+	low      decimal.Decimal
+	high     decimal.Decimal
+	last     time.Time
+	tradeIDs []string
+}
+
+// observe folds one committed batch of the tenant's events in and returns the alerts it raises
+func (f *orderFlow) observe(tenantID string, evs []*domain.OrderEvent, p domain.SurveillancePolicy) []domain.SurveillanceAlert {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now().UTC()
+	var alerts []domain.SurveillanceAlert
+	raise := func(cf *clientFlow, kind domain.AlertKind, ev *domain.OrderEvent, at time.Time, tradeIDs []string, detail string) {
+		if last, ok := cf.raised[kind]; ok && at.Sub(last) < p.OrderFlowWindow {
+			return
+		}
+		cf.raised[kind] = at
+		alerts = append(alerts, domain.SurveillanceAlert{
+			ID:   uuid.NewSHA1(alertNamespace, []byte(string(kind)+"|"+ev.OrderID)).String(),
+			Kind: kind, Symbol: ev.Symbol, ClientIDs: []string{ev.ClientID}, TradeIDs: tradeIDs, Detail: detail,
+			At: at, DetectedAt: now, Status: domain.AlertOpen,
+		})
+	}
+
+	var aggressive []*aggression
+	arriving := make(map[string]*aggression)
+	for _, ev := range evs {
+		since := ev.Timestamp.Add(-p.OrderFlowWindow)
+		f.expire(since)
+		key := tenant.ScopeID(tenantID, ev.OrderID)
+		cf := f.flowOf(tenantID, ev, since)
+		switch ev.ExecType {
+		case domain.ExecNew:
+			f.placed[key] = &placement{at: ev.Timestamp, side: ev.Side, price: ev.Price, remaining: ev.Remaining}
+			f.expiry = append(f.expiry, placedKey{key, ev.Timestamp})
+			cf.placed = append(cf.placed, ev.Timestamp)
+			a := &aggression{ev: ev}
+			arriving[ev.OrderID] = a
+			aggressive = append(aggressive, a)
+		case domain.ExecReplaced:
+			if pl, ok := f.placed[key]; ok {
+				pl.price, pl.remaining = ev.Price, ev.Remaining
+			}
+		case domain.ExecPartialFill, domain.ExecFill:
+			cf.fills = append(cf.fills, flowFill{at: ev.Timestamp, side: ev.Side, qty: ev.LastQty, tradeID: ev.TradeID})
+			if pl, ok := f.placed[key]; ok {
+				pl.remaining = ev.Remaining
+				if ev.ExecType == domain.ExecFill {
+					delete(f.placed, key)
+				}
+			}
+			if a, ok := arriving[ev.OrderID]; ok {
+				if len(a.tradeIDs) == 0 || ev.LastPrice.LessThan(a.low) {
+					a.low = ev.LastPrice
+				}
+				if len(a.tradeIDs) == 0 || ev.LastPrice.GreaterThan(a.high) {
+					a.high = ev.LastPrice
+				}
+				a.last = ev.Timestamp
+				a.tradeIDs = append(a.tradeIDs, ev.TradeID)
+			}
+		case domain.ExecCanceled:
+			c := flowCancel{at: ev.Timestamp, side: ev.Side}
+			if pl, ok := f.placed[key]; ok {
+				c.price, c.qty, c.recent = pl.price, pl.remaining, true
+				delete(f.placed, key)
+			}
+			cf.cancels = append(cf.cancels, c)
+			f.checkCancels(cf, ev, c, p, raise)
+		case domain.ExecExpired:
+			delete(f.placed, key)
+		}
+	}
+
+	for _, a := range aggressive {
+		if len(a.tradeIDs) < 2 || !a.low.IsPositive() {
+			continue
+		}
+		move := a.high.Sub(a.low).Div(a.low).Mul(bps)
+		if move.GreaterThanOrEqual(p.PriceImpactBps) {
+			cf := f.flowOf(tenantID, a.ev, a.last.Add(-p.OrderFlowWindow))
+			raise(cf, domain.AlertPriceImpact, a.ev, a.last, a.tradeIDs,
+				fmt.Sprintf("%s order filled from %s to %s (%s bps) in %d trades", a.ev.Side, a.low, a.high, move.Round(2), len(a.tradeIDs)))
+		}
+	}
+	return alerts
+}
+
+// checkCancels runs the cancel checks after c was added to the client's activity
+func (f *orderFlow) checkCancels(cf *clientFlow, ev *domain.OrderEvent, c flowCancel, p domain.SurveillancePolicy,
+	raise func(*clientFlow, domain.AlertKind, *domain.OrderEvent, time.Time, []string, string)) {
+	cancels := len(cf.cancels)
+	if cancels >= p.MinCancels && decimal.NewFromInt(int64(cancels)).GreaterThanOrEqual(p.CancelRatio.Mul(decimal.NewFromInt(int64(len(cf.placed))))) {
+		raise(cf, domain.AlertExcessiveCancels, ev, c.at, nil,
+			fmt.Sprintf("cancelled %d orders against %d placed within %s", cancels, len(cf.placed), p.OrderFlowWindow))
+	}
+	if !c.recent {
+		return
+	}
+
+	// what the client traded on the other side, and what it cancelled of this one
+	filled := decimal.Zero
+	var tradeIDs []string
+	for _, fl := range cf.fills {
+		if fl.side != c.side {
+			filled = filled.Add(fl.qty)
+			tradeIDs = append(tradeIDs, fl.tradeID)
+		}
+	}
+	if !filled.IsPositive() {
+		return
+	}
+	cancelled := decimal.Zero
+	levels := make(map[string]bool)
+	for _, cc := range cf.cancels {
+		if cc.recent && cc.side == c.side {
+			cancelled = cancelled.Add(cc.qty)
+			levels[cc.price.String()] = true
+		}
+	}
+	switch {
+	case len(levels) >= p.LayeringLevels:
+		raise(cf, domain.AlertLayering, ev, c.at, tradeIDs,
+			fmt.Sprintf("cancelled %s %s at %d price levels after trading %s on the other side", cancelled, c.side, len(levels), filled))
+	case cancelled.GreaterThanOrEqual(filled.Mul(p.SpoofFactor)):
+		raise(cf, domain.AlertSpoofing, ev, c.at, tradeIDs,
+			fmt.Sprintf("cancelled %s %s after trading %s on the other side", cancelled, c.side, filled))
+	}
+}
+
+// watchOrderFlow runs the order-flow checks on a committed batch of the ctx tenant's events, storing
+// and publishing what they raise
+func (e *Engine) watchOrderFlow(ctx context.Context, evs []*domain.OrderEvent) {
+	alerts := e.surveillance.flow.observe(tenant.From(ctx), evs, e.surveillance.policy)
+	if len(alerts) == 0 {
+		return
+	}
+	_ = e.repo.SaveAlerts(ctx, alerts)
+	e.publishAlerts(ctx, alerts)
+}
+
+// publishAlerts publishes new alerts to the ctx tenant's alert stream and the cross-tenant one
+func (e *Engine) publishAlerts(ctx context.Context, alerts []domain.SurveillanceAlert) {
+	for i := range alerts {
+		a := alerts[i]
+		a.Tenant = tenant.From(ctx)
+		e.alerts.Publish(tenant.Scope(ctx, alertsTopic), &a)
+		e.alerts.Publish(allTenants, &a)
+	}
+}
+
+// SubscribeSurveillanceAlerts subscribes to the alerts raised within the ctx tenant as they are raised
+func (e *Engine) SubscribeSurveillanceAlerts(ctx context.Context) *pubsub.Subscription[*domain.SurveillanceAlert] {
+	return e.alerts.Subscribe(tenant.Scope(ctx, alertsTopic))
+}
+
+// SubscribeAllSurveillanceAlerts subscribes to the alerts of every tenant, for in-process consumers
+func (e *Engine) SubscribeAllSurveillanceAlerts() *pubsub.Subscription[*domain.SurveillanceAlert] {
+	return e.alerts.SubscribeWith(allTenants, pubsub.Options{Policy: pubsub.Buffer, Bound: 1 << 12})
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/shopspring/decimal"
+)
+
+func TestOrderFlowAlerts(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithSurveillance(domain.SurveillancePolicy{MinCancels: 4}))
+	sub := e.SubscribeSurveillanceAlerts(ctx)
+	defer sub.Close()
+
+	submit := func(id, client, symbol string, side domain.Side, price, qty int64) {
+		t.Helper()
+		if _, err := e.SubmitOrder(ctx, &domain.Order{ID: id, ClientID: client, Symbol: symbol, Side: side, Type: domain.Limit,
+			Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(qty)}); err != nil {
+			t.Fatalf("submit %s: %v", id, err)
+		}
+	}
+	cancel := func(id, client string) {
+		t.Helper()
+		if _, err := e.CancelOrder(ctx, id, client); err != nil {
+			t.Fatalf("cancel %s: %v", id, err)
+		}
+	}
+
+	// p sells 1 into a bid while its own bid for 20 props the book up, then pulls it
+	submit("p-bid", "p", "BTC/USD", domain.Buy, 90, 20)
+	submit("b-bid", "b", "BTC/USD", domain.Buy, 100, 1)
+	submit("p-ask", "p", "BTC/USD", domain.Sell, 100, 1)
+	cancel("p-bid", "p")
+
+	// l does the same behind three small bids at different prices
+	for i, price := range []int64{95, 96, 97} {
+		submit(fmt.Sprintf("l-bid%d", i), "l", "BTC/USD", domain.Buy, price, 1)
+	}
+	submit("b-bid2", "b", "BTC/USD", domain.Buy, 100, 1)
+	submit("l-ask", "l", "BTC/USD", domain.Sell, 100, 1)
+	for i := range 3 {
+		cancel(fmt.Sprintf("l-bid%d", i), "l")
+	}
+
+	// c cancels every order it places
+	for i := range 4 {
+		submit(fmt.Sprintf("c-bid%d", i), "c", "BTC/USD", domain.Buy, 80, 1)
+		cancel(fmt.Sprintf("c-bid%d", i), "c")
+	}
+
+	// t sweeps two asks 450 bps apart
+	submit("a-ask1", "a", "ETH/USD", domain.Sell, 110, 1)
+	submit("a-ask2", "a", "ETH/USD", domain.Sell, 115, 1)
+	submit("t-bid", "t", "ETH/USD", domain.Buy, 120, 2)
+
+	want := map[domain.AlertKind]string{
+		domain.AlertSpoofing:         "p",
+		domain.AlertLayering:         "l",
+		domain.AlertExcessiveCancels: "c",
+		domain.AlertPriceImpact:      "t",
+	}
+	got := make(map[domain.AlertKind]string)
+	for len(sub.C) > 0 {
+		a := <-sub.C
+		if _, dup := got[a.Kind]; dup {
+			t.Errorf("%s raised twice", a.Kind)
+		}
+		got[a.Kind] = a.ClientIDs[0]
+	}
+	if len(got) != len(want) {
+		t.Fatalf("alerts %v, want %v", got, want)
+	}
+	for kind, client := range want {
+		if got[kind] != client {
+			t.Errorf("%s raised for %q, want %q", kind, got[kind], client)
+		}
+	}
+
+	listed, err := e.SurveillanceAlerts(ctx, domain.AlertFilter{}, page.Request{Limit: 10})
+	if err != nil || len(listed.Items) != len(want) {
+		t.Fatalf("stored %d alerts, %v, want %d", len(listed.Items), err, len(want))
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// WithPrecision sets the symbols' price and quantity precision; orders and amends of those symbols
+// are normalized to it before they are checked further. Other symbols take any precision.
+func WithPrecision(precision map[string]domain.Precision) Option {
+	return func(e *Engine) { e.precision = precision }
+}
+
+// Precision returns the symbol's configured precision; ok is false if it has none
+func (e *Engine) Precision(symbol string) (p domain.Precision, ok bool) {
+	p, ok = e.precision[symbol]
+	return p, ok
+}
+
+// normalize rounds or rejects a price and quantity of the symbol finer than its precision. A zero
+// price, as of a market order, is left alone.
+func (e *Engine) normalize(symbol string, side domain.Side, price, quantity *decimal.Decimal) error {
+	p, ok := e.precision[symbol]
+	if !ok {
+		return nil
+	}
+	if !price.IsZero() && !fits(*price, p.PriceDecimals) {
+		if !p.Round {
+			return domain.Reject(domain.RejectInvalidTick, "price %s has more than %d decimals for %s", price, p.PriceDecimals, symbol)
+		}
+		if side == domain.Sell {
+			*price = price.RoundCeil(p.PriceDecimals)
+		} else {
+			*price = price.RoundFloor(p.PriceDecimals)
+		}
+		if price.IsZero() {
+			return domain.Reject(domain.RejectInvalidPrice, "price rounds to 0 at %d decimals for %s", p.PriceDecimals, symbol)
+		}
+	}
+	if !fits(*quantity, p.QuantityDecimals) {
+		if !p.Round {
+			return domain.Reject(domain.RejectInvalidQuantity, "quantity %s has more than %d decimals for %s", quantity, p.QuantityDecimals, symbol)
+		}
+		*quantity = quantity.RoundFloor(p.QuantityDecimals)
+		if quantity.IsZero() {
+			return domain.Reject(domain.RejectInvalidQuantity, "quantity rounds to 0 at %d decimals for %s", p.QuantityDecimals, symbol)
+		}
+	}
+	return nil
+}
+
+// fits reports whether d has no more than places significant decimals
+func fits(d decimal.Decimal, places int32) bool {
+	return d.Equal(d.Truncate(places))
+}
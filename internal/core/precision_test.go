@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestPrecisionNormalization(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithPrecision(map[string]domain.Precision{
+		"BTC/USD": {PriceDecimals: 2, QuantityDecimals: 3, Round: true},
+		"ETH/USD": {PriceDecimals: 2, QuantityDecimals: 3},
+	}))
+	order := func(symbol string, side domain.Side, price, qty string) *domain.Order {
+		return &domain.Order{ClientID: "c", Symbol: symbol, Side: side, Type: domain.Limit,
+			Price: decimal.RequireFromString(price), Quantity: decimal.RequireFromString(qty)}
+	}
+
+	buy := order("BTC/USD", domain.Buy, "100.129", "1.23456")
+	if _, err := e.SubmitOrder(ctx, buy); err != nil {
+		t.Fatal(err)
+	}
+	if buy.Price.String() != "100.12" || buy.Quantity.String() != "1.234" || !buy.Remaining.Equal(buy.Quantity) {
+		t.Errorf("rounded buy = %s x %s, remaining %s", buy.Price, buy.Quantity, buy.Remaining)
+	}
+	sell := order("BTC/USD", domain.Sell, "100.131", "1")
+	if _, err := e.SubmitOrder(ctx, sell); err != nil {
+		t.Fatal(err)
+	}
+	if sell.Price.String() != "100.14" {
+		t.Errorf("rounded sell price = %s, want 100.14", sell.Price)
+	}
+
+	for _, tc := range []struct {
+		o    *domain.Order
+		want domain.RejectCode
+	}{
+		{order("ETH/USD", domain.Buy, "10.001", "1"), domain.RejectInvalidTick},
+		{order("ETH/USD", domain.Buy, "10.00", "0.0001"), domain.RejectInvalidQuantity},
+		{order("BTC/USD", domain.Buy, "100", "0.0004"), domain.RejectInvalidQuantity},
+	} {
+		if _, err := e.SubmitOrder(ctx, tc.o); domain.RejectCodeOf(err) != tc.want {
+			t.Errorf("%s %s x %s: %v, want %s", tc.o.Symbol, tc.o.Price, tc.o.Quantity, err, tc.want)
+		}
+	}
+	if _, err := e.SubmitOrder(ctx, order("XRP/USD", domain.Buy, "0.123456789", "1")); err != nil {
+		t.Errorf("symbol without precision: %v", err)
+	}
+}
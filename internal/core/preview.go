@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"sort"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// PreviewOrder simulates matching o against a copy of the current book without executing anything
+func (e *Engine) PreviewOrder(ctx context.Context, o *domain.Order) (*domain.MatchPreview, error) {
+	if err := e.ValidateOrder(ctx, o); err != nil {
+		return nil, err
+	}
+	ob, err := e.GetOrderbook(ctx, o.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	ob = ob.DeepCopy()
+	book := ob.Asks
+	if o.Side == domain.Sell {
+		book = ob.Bids
+	}
+	p := simulateMatch(o, book)
+	p.EstimatedFee = p.Notional.Mul(e.feeSchedule(ctx).TakerRate)
+	return p, nil
+}
+
+func simulateMatch(o *domain.Order, book []domain.Order) *domain.MatchPreview {
+	sort.Slice(book, func(i, j int) bool {
+		if !book[i].Price.Equal(book[j].Price) {
+			if o.Side == domain.Buy {
+				return book[i].Price.LessThan(book[j].Price)
+			}
+			return book[i].Price.GreaterThan(book[j].Price)
+		}
+		return book[i].CreatedAt.Before(book[j].CreatedAt)
+	})
+
+	p := &domain.MatchPreview{
+		Symbol:    o.Symbol,
+		Side:      o.Side,
+		Fills:     []domain.PreviewFill{},
+		Filled:    decimal.Zero,
+		Remaining: o.Quantity,
+		Notional:  decimal.Zero,
+	}
+	if len(book) > 0 {
+		p.BestPrice = decimal.NewNullDecimal(book[0].Price)
+	}
+	for i := range book {
+		other := &book[i]
+		if p.Remaining.LessThanOrEqual(decimal.Zero) || !priceMatch(o, other) {
+			break
+		}
+		q := decimal.Min(p.Remaining, other.Remaining)
+		if q.LessThanOrEqual(decimal.Zero) {
+			continue
+		}
+		p.Fills = append(p.Fills, domain.PreviewFill{Price: other.Price, Quantity: q})
+		p.Filled = p.Filled.Add(q)
+		p.Remaining = p.Remaining.Sub(q)
+		p.Notional = p.Notional.Add(q.Mul(other.Price))
+	}
+	if p.Filled.IsPositive() {
+		avg := p.Notional.Div(p.Filled)
+		p.AvgPrice = decimal.NewNullDecimal(avg)
+		best := p.BestPrice.Decimal
+		if best.IsPositive() {
+			diff := avg.Sub(best)
+			if o.Side == domain.Sell {
+				diff = diff.Neg()
+			}
+			p.Slippage = decimal.NewNullDecimal(diff.Div(best))
+		}
+	}
+	return p
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// GetQueuePosition estimates the order's place in its price level from the current book
+func (e *Engine) GetQueuePosition(ctx context.Context, orderID, clientID string) (*domain.QueuePosition, error) {
+	o, err := e.repo.LoadOrderByIDForClient(ctx, orderID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != domain.Open || !o.Type.HasLimit() {
+		return nil, errors.New("order is not resting in the book")
+	}
+	ob, err := e.GetOrderbook(ctx, o.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	side := ob.Bids
+	if o.Side == domain.Sell {
+		side = ob.Asks
+	}
+	return queuePosition(o, side), nil
+}
+
+func queuePosition(o *domain.Order, side []domain.Order) *domain.QueuePosition {
+	level := make([]domain.Order, 0, 8)
+	for _, other := range side {
+		if other.Price.Equal(o.Price) {
+			level = append(level, other)
+		}
+	}
+	sort.SliceStable(level, func(i, j int) bool {
+		return level[i].CreatedAt.Before(level[j].CreatedAt)
+	})
+
+	qp := &domain.QueuePosition{
+		OrderID:        o.ID,
+		Symbol:         o.Symbol,
+		Side:           o.Side,
+		Price:          o.Price,
+		QuantityAhead:  decimal.Zero,
+		LevelOrders:    len(level),
+		LevelQuantity:  decimal.Zero,
+		OrderRemaining: o.Remaining,
+	}
+	found := false
+	for _, other := range level {
+		qp.LevelQuantity = qp.LevelQuantity.Add(other.Remaining)
+		if other.ID == o.ID {
+			found = true
+			continue
+		}
+		if !found {
+			qp.OrdersAhead++
+			qp.QuantityAhead = qp.QuantityAhead.Add(other.Remaining)
+		}
+	}
+	if !found {
+		// the cached book does not have the order yet, so it is last in line
+		qp.LevelOrders++
+		qp.LevelQuantity = qp.LevelQuantity.Add(o.Remaining)
+	}
+	qp.Position = qp.OrdersAhead + 1
+	return qp
+}
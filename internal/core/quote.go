@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+var two = decimal.NewFromInt(2)
+
+// GetQuote returns best bid/ask, spread and mid price without building a full snapshot
+func (e *Engine) GetQuote(ctx context.Context, symbol string) (*domain.Quote, error) {
+	tob, err := e.topOfBook(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return buildQuote(tob), nil
+}
+
+func buildQuote(tob *domain.OrderbookSnapshot) *domain.Quote {
+	q := &domain.Quote{
+		Symbol:    tob.Symbol,
+		Timestamp: time.Now().UTC(),
+	}
+	if len(tob.Bids) > 0 {
+		q.BidPrice = decimal.NewNullDecimal(tob.Bids[0].Price)
+		q.BidQty = levelQty(tob.Bids)
+	}
+	if len(tob.Asks) > 0 {
+		q.AskPrice = decimal.NewNullDecimal(tob.Asks[0].Price)
+		q.AskQty = levelQty(tob.Asks)
+	}
+	if q.BidPrice.Valid && q.AskPrice.Valid {
+		q.Spread = decimal.NewNullDecimal(q.AskPrice.Decimal.Sub(q.BidPrice.Decimal))
+		q.Mid = decimal.NewNullDecimal(q.AskPrice.Decimal.Add(q.BidPrice.Decimal).Div(two))
+	}
+	return q
+}
+
+// levelQty sums the remaining quantity of the orders at the price of the first
+func levelQty(orders []domain.Order) decimal.Decimal {
+	qty := decimal.Zero
+	for _, o := range orders {
+		if o.Price.Equal(orders[0].Price) {
+			qty = qty.Add(o.Remaining)
+		}
+	}
+	return qty
+}
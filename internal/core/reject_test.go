@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestRejectCodes(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	events := e.SubscribeAllOrderEvents()
+	defer events.Close()
+	e.SetCancelOnly(ctx, "ETH/USD", true, "maintenance")
+	if _, err := e.AnnounceDelisting(ctx, "XRP/USD", time.Time{}, time.Now().Add(time.Hour), "issuer request"); err != nil {
+		t.Fatalf("announce: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		symbol string
+		qty    int64
+		want   domain.RejectCode
+	}{
+		{"zero quantity", "BTC/USD", 0, domain.RejectInvalidQuantity},
+		{"cancel-only", "ETH/USD", 1, domain.RejectCancelOnly},
+		{"halted", "XRP/USD", 1, domain.RejectSymbolHalted},
+	} {
+		_, err := e.SubmitOrder(ctx, &domain.Order{ClientID: "c1", Symbol: tt.symbol, Side: domain.Buy, Type: domain.Limit,
+			Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(tt.qty)})
+		if got := domain.RejectCodeOf(err); got != tt.want {
+			t.Errorf("%s: %v with code %s, want %s", tt.name, err, got, tt.want)
+		}
+		if ev := <-events.C; ev.ExecType != domain.ExecRejected || ev.RejectCode != tt.want {
+			t.Errorf("%s: event %s with code %s, want REJECTED with %s", tt.name, ev.ExecType, ev.RejectCode, tt.want)
+		}
+	}
+	if _, err := e.SubmitOrder(ctx, &domain.Order{ClientID: "c1", Symbol: "ETH/USD", Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)}); !errors.Is(err, ErrCancelOnly) {
+		t.Errorf("cancel-only rejection %v no longer matches ErrCancelOnly", err)
+	}
+	if got := domain.RejectCodeOf(errors.New("disk full")); got != domain.RejectOther {
+		t.Errorf("unclassified error has code %s, want %s", got, domain.RejectOther)
+	}
+}
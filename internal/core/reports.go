@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+var errReportNotFound = errors.New("report not generated for this day")
+
+// reportDelay lets trades committed right before midnight settle before the day is reported
+const reportDelay = 5 * time.Minute
+
+// GenerateDailyReport builds the ctx tenant's report of the UTC day containing day, replacing an
+// earlier run, and returns it
+func (e *Engine) GenerateDailyReport(ctx context.Context, day time.Time) (*domain.DailyReport, error) {
+	day = utcDay(day)
+	if err := e.repo.SaveDailyReport(ctx, day); err != nil {
+		return nil, err
+	}
+	return e.DailyReport(ctx, day)
+}
+
+// DailyReport returns the ctx tenant's stored report of the UTC day containing day
+func (e *Engine) DailyReport(ctx context.Context, day time.Time) (*domain.DailyReport, error) {
+	rep, err := e.repo.LoadDailyReport(ctx, utcDay(day))
+	if err != nil {
+		return nil, err
+	}
+	if rep == nil {
+		return nil, errReportNotFound
+	}
+	return rep, nil
+}
+
+// RunDailyReports generates the previous day's report of the default and every configured tenant
+// shortly after each UTC midnight until ctx is done, passing each outcome to report if it is not nil
+func (e *Engine) RunDailyReports(ctx context.Context, report func(tenantID string, day time.Time, err error)) {
+	for {
+		now := time.Now().UTC()
+		next := utcDay(now).AddDate(0, 0, 1).Add(reportDelay)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		day := utcDay(next).AddDate(0, 0, -1)
+		for _, id := range e.tenantIDs() {
+			_, err := e.GenerateDailyReport(tenant.With(ctx, id), day)
+			if report != nil {
+				report(id, day, err)
+			}
+		}
+	}
+}
+
+func utcDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// archiveBatch bounds the rows one archive statement moves, keeping its locks short
+const archiveBatch = 1000
+
+// WithRetention sets the engine-wide order retention policy; tenants may override it in their config
+func WithRetention(p domain.RetentionPolicy) Option {
+	return func(e *Engine) { e.retention = p }
+}
+
+func (e *Engine) retentionPolicy(ctx context.Context) domain.RetentionPolicy {
+	if cfg, ok := e.tenantConfig(ctx); ok && cfg.Retention != nil {
+		return *cfg.Retention
+	}
+	return e.retention
+}
+
+// ApplyRetention archives and purges the ctx tenant's orders according to its retention policy
+func (e *Engine) ApplyRetention(ctx context.Context) (domain.RetentionResult, error) {
+	var res domain.RetentionResult
+	p := e.retentionPolicy(ctx)
+	now := time.Now().UTC()
+
+	if p.ArchiveAfter > 0 {
+		for {
+			n, err := e.repo.ArchiveOrders(ctx, now.Add(-p.ArchiveAfter), archiveBatch)
+			res.Archived += n
+			if err != nil {
+				return res, err
+			}
+			if n < archiveBatch {
+				break
+			}
+		}
+	}
+	if p.PurgeAfter > 0 {
+		orders, trades, err := e.repo.PurgeArchive(ctx, now.Add(-p.PurgeAfter))
+		res.PurgedOrders, res.PurgedTrades = orders, trades
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// RunRetention applies the retention policy of the default and every configured tenant each interval
+// until ctx is done, passing each tenant's outcome to report if it is not nil
+func (e *Engine) RunRetention(ctx context.Context, interval time.Duration, report func(tenantID string, res domain.RetentionResult, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, id := range e.tenantIDs() {
+			res, err := e.ApplyRetention(tenant.With(ctx, id))
+			if report != nil {
+				report(id, res, err)
+			}
+		}
+	}
+}
+
+// tenantIDs returns the default tenant and every configured one, sorted
+func (e *Engine) tenantIDs() []string {
+	ids := []string{tenant.Default}
+	for id := range e.tenants {
+		if id != tenant.Default {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+var errNotSandbox = errors.New("virtual balances are only available to sandbox keys")
+
+// WithSandboxBalances enables virtual balances for paper-trading keys: every sandbox client
+// starts with initial (asset -> amount), orders it cannot fund are rejected and fills settle
+// against it. Without this option sandbox orders are not funds-checked.
+func WithSandboxBalances(initial map[string]decimal.Decimal) Option {
+	return func(e *Engine) { e.sandbox = newVirtualBalances(initial) }
+}
+
+// virtualBalances holds the in-memory accounts of sandbox clients keyed by tenant-scoped client id
+type virtualBalances struct {
+	mu       sync.Mutex
+	initial  map[string]decimal.Decimal
+	accounts map[string]map[string]decimal.Decimal
+}
+
+func newVirtualBalances(initial map[string]decimal.Decimal) *virtualBalances {
+	return &virtualBalances{
+		initial:  initial,
+		accounts: make(map[string]map[string]decimal.Decimal),
+	}
+}
+
+// account returns the client's balances, opening it on first use; the caller holds mu
+func (v *virtualBalances) account(key string) map[string]decimal.Decimal {
+	acc, ok := v.accounts[key]
+	if !ok {
+		acc = maps.Clone(v.initial)
+		if acc == nil {
+			acc = make(map[string]decimal.Decimal)
+		}
+		v.accounts[key] = acc
+	}
+	return acc
+}
+
+// splitSymbol splits a BASE/QUOTE symbol into its assets
+func splitSymbol(symbol string) (base, quote string, err error) {
+	base, quote, ok := strings.Cut(symbol, "/")
+	if !ok || base == "" || quote == "" {
+		return "", "", fmt.Errorf("symbol %s is not of the form BASE/QUOTE", symbol)
+	}
+	return base, quote, nil
+}
+
+// checkSandboxFunds rejects sandbox orders the client's virtual balance cannot cover.
+// Market buys have no price to check against and are let through.
+func (e *Engine) checkSandboxFunds(ctx context.Context, o *domain.Order) error {
+	if e.sandbox == nil || !tenant.IsSandbox(ctx) {
+		return nil
+	}
+	base, quote, err := e.assets(ctx, o.Symbol)
+	if err != nil {
+		return domain.Reject(domain.RejectUnknownSymbol, "%v", err)
+	}
+	asset, need := base, o.Quantity
+	if o.Side == domain.Buy {
+		if !o.Type.HasLimit() {
+			return nil
+		}
+		asset, need = quote, o.Price.Mul(o.Quantity)
+	}
+
+	e.sandbox.mu.Lock()
+	defer e.sandbox.mu.Unlock()
+	if have := e.sandbox.account(tenant.Scope(ctx, o.ClientID))[asset]; have.LessThan(need) {
+		return domain.Reject(domain.RejectInsufficientFunds, "insufficient virtual %s balance: have %s, need %s", asset, have, need)
+	}
+	return nil
+}
+
+// settleSandbox moves virtual assets for every fill in evs
+func (e *Engine) settleSandbox(ctx context.Context, evs []*domain.OrderEvent) {
+	if e.sandbox == nil || !tenant.IsSandbox(ctx) {
+		return
+	}
+	e.sandbox.mu.Lock()
+	defer e.sandbox.mu.Unlock()
+	for _, ev := range evs {
+		if ev.ExecType != domain.ExecFill && ev.ExecType != domain.ExecPartialFill {
+			continue
+		}
+		base, quote, err := e.assets(ctx, ev.Symbol)
+		if err != nil {
+			continue
+		}
+		notional := ev.LastPrice.Mul(ev.LastQty)
+		acc := e.sandbox.account(tenant.Scope(ctx, ev.ClientID))
+		if ev.Side == domain.Buy {
+			acc[base] = acc[base].Add(ev.LastQty)
+			acc[quote] = acc[quote].Sub(notional)
+		} else {
+			acc[base] = acc[base].Sub(ev.LastQty)
+			acc[quote] = acc[quote].Add(notional)
+		}
+	}
+}
+
+// SandboxBalances returns a sandbox client's virtual balances
+func (e *Engine) SandboxBalances(ctx context.Context, clientID string) (map[string]decimal.Decimal, error) {
+	if e.sandbox == nil || !tenant.IsSandbox(ctx) {
+		return nil, errNotSandbox
+	}
+	e.sandbox.mu.Lock()
+	defer e.sandbox.mu.Unlock()
+	return maps.Clone(e.sandbox.account(tenant.Scope(ctx, clientID))), nil
+}
+
+// ResetSandboxBalances restores a sandbox client's virtual balances to the initial amounts
+func (e *Engine) ResetSandboxBalances(ctx context.Context, clientID string) error {
+	if e.sandbox == nil || !tenant.IsSandbox(ctx) {
+		return errNotSandbox
+	}
+	e.sandbox.mu.Lock()
+	delete(e.sandbox.accounts, tenant.Scope(ctx, clientID))
+	e.sandbox.mu.Unlock()
+	return nil
+}
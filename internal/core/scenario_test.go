@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the matching scenarios")
+
+const scenarioSymbol = "BTC/USD"
+
+// TestMatchingScenarios replays every testdata/scenarios/*.txt against an engine on the in-memory
+// repository and compares the transcript with the .golden file next to it. A scenario is one command
+// per line, '#' starting a comment:
+//
+//	submit <order> <client> BUY|SELL LIMIT <price> <qty>
+//	submit <order> <client> BUY|SELL MARKET <qty>
+//	cancel <order> <client>
+//	amend  <order> <client> <price> <qty>
+//
+// Orders are named by the scenario; the transcript shows each command's trades or error and ends
+// with the book in price-time priority. Run with -update to accept new output.
+func TestMatchingScenarios(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no scenarios")
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".txt")
+		t.Run(name, func(t *testing.T) {
+			script, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := runScenario(t, string(script))
+
+			golden := strings.TrimSuffix(file, ".txt") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("transcript differs from %s\n--- got\n%s--- want\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+type scenario struct {
+	t      *testing.T
+	ctx    context.Context
+	e      *Engine
+	repo   *memory.Repository
+	ids    map[string]string // order name -> ID
+	names  map[string]string // ID -> order name
+	out    bytes.Buffer
+	lineNo int
+}
+
+func runScenario(t *testing.T, script string) []byte {
+	repo := memory.NewRepository()
+	s := &scenario{
+		t: t, ctx: context.Background(), e: NewEngine(repo, nil), repo: repo,
+		ids: make(map[string]string), names: make(map[string]string),
+	}
+	for i, line := range strings.Split(script, "\n") {
+		s.lineNo = i + 1
+		if c, _, _ := strings.Cut(line, "#"); strings.TrimSpace(c) != "" {
+			s.run(strings.Fields(c))
+		}
+	}
+	s.book()
+	return s.out.Bytes()
+}
+
+func (s *scenario) fail(format string, args ...any) {
+	s.t.Helper()
+	s.t.Fatalf("line %d: "+format, append([]any{s.lineNo}, args...)...)
+}
+
+func (s *scenario) decimal(v string) decimal.Decimal {
+	s.t.Helper()
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		s.fail("bad number %q", v)
+	}
+	return d
+}
+
+func (s *scenario) id(name string) string {
+	if id, ok := s.ids[name]; ok {
+		return id
+	}
+	return name
+}
+
+func (s *scenario) name(id string) string {
+	if name, ok := s.names[id]; ok {
+		return name
+	}
+	return id
+}
+
+func (s *scenario) run(args []string) {
+	s.t.Helper()
+	fmt.Fprintf(&s.out, "> %s\n", strings.Join(args, " "))
+	var err error
+	switch {
+	case args[0] == "submit" && len(args) >= 5:
+		err = s.submit(args[1], args[2], domain.Side(args[3]), domain.OrderType(args[4]), args[5:])
+	case args[0] == "cancel" && len(args) == 3:
+		_, err = s.e.CancelOrder(s.ctx, s.id(args[1]), args[2])
+	case args[0] == "amend" && len(args) == 5:
+		var trades []*domain.Trade
+		trades, err = s.e.ModifyOrder(s.ctx, s.id(args[1]), args[2], s.decimal(args[3]), s.decimal(args[4]))
+		s.trades(trades)
+	default:
+		s.fail("unknown command %q", strings.Join(args, " "))
+	}
+	if err != nil {
+		fmt.Fprintf(&s.out, "  error: %v\n", err)
+	}
+}
+
+func (s *scenario) submit(name, clientID string, side domain.Side, typ domain.OrderType, rest []string) error {
+	s.t.Helper()
+	if _, dup := s.ids[name]; dup {
+		s.fail("order %s submitted twice", name)
+	}
+	o := &domain.Order{ClientID: clientID, Symbol: scenarioSymbol, Side: side, Type: typ}
+	switch {
+	case typ == domain.Limit && len(rest) == 2:
+		o.Price, o.Quantity = s.decimal(rest[0]), s.decimal(rest[1])
+	case typ == domain.Market && len(rest) == 1:
+		o.Quantity = s.decimal(rest[0])
+	default:
+		s.fail("submit wants LIMIT <price> <qty> or MARKET <qty>")
+	}
+	// IDs in submission order keep time priority stable when two orders share a timestamp
+	o.ID = fmt.Sprintf("%06d", len(s.ids)+1)
+	s.ids[name], s.names[o.ID] = o.ID, name
+
+	trades, err := s.e.SubmitOrder(s.ctx, o)
+	s.trades(trades)
+	return err
+}
+
+func (s *scenario) trades(trades []*domain.Trade) {
+	for _, tr := range trades {
+		fmt.Fprintf(&s.out, "  trade %s buys from %s %s @ %s\n", s.name(tr.BuyOrder), s.name(tr.SellOrder), tr.Quantity, tr.Price)
+	}
+}
+
+func (s *scenario) book() {
+	s.t.Helper()
+	open, err := s.repo.LoadOpenOrders(s.ctx, scenarioSymbol)
+	if err != nil {
+		s.t.Fatalf("load book: %v", err)
+	}
+	var bids, asks []*domain.Order
+	for _, o := range open {
+		if o.Side == domain.Buy {
+			bids = append(bids, o)
+		} else {
+			asks = append(asks, o)
+		}
+	}
+	priority := func(side []*domain.Order, better func(a, b decimal.Decimal) bool) {
+		sort.SliceStable(side, func(i, j int) bool {
+			if !side[i].Price.Equal(side[j].Price) {
+				return better(side[i].Price, side[j].Price)
+			}
+			return side[i].ID < side[j].ID
+		})
+	}
+	priority(asks, decimal.Decimal.LessThan)
+	priority(bids, decimal.Decimal.GreaterThan)
+
+	s.out.WriteString("= book\n")
+	for _, o := range asks {
+		s.bookLine(o)
+	}
+	s.out.WriteString("  --\n")
+	for _, o := range bids {
+		s.bookLine(o)
+	}
+}
+
+func (s *scenario) bookLine(o *domain.Order) {
+	fmt.Fprintf(&s.out, "  %-4s %s %s %s %s/%s %s\n", o.Side, s.name(o.ID), o.ClientID, o.Price, o.Remaining, o.Quantity, o.Status)
+}
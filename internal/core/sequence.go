@@ -0,0 +1,23 @@
+package core
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// sequence numbers the transitions of every engine in the process: order events and trades
+var sequence sequencer
+
+// sequencer hands out strictly increasing numbers that are at least the wall clock in nanoseconds, so
+// they keep increasing across restarts without being stored anywhere
+type sequencer struct{ last atomic.Uint64 }
+
+func (s *sequencer) next(at time.Time) uint64 {
+	for {
+		last := s.last.Load()
+		n := max(last+1, uint64(at.UnixNano()))
+		if s.last.CompareAndSwap(last, n) {
+			return n
+		}
+	}
+}
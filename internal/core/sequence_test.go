@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestEventsAndTradesAreSequenced(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	events := e.SubscribeAllOrderEvents()
+	defer events.Close()
+
+	submit := func(id string, side domain.Side, qty int64) []*domain.Trade {
+		t.Helper()
+		trades, err := e.SubmitOrder(ctx, &domain.Order{ID: id, ClientID: "c-" + id, Symbol: "BTC/USD", Side: side,
+			Type: domain.Limit, Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(qty)})
+		if err != nil {
+			t.Fatalf("submit %s: %v", id, err)
+		}
+		return trades
+	}
+	submit("ask", domain.Sell, 1)
+	submit("rest", domain.Sell, 1)
+	trades := submit("bid", domain.Buy, 1)
+	if _, err := e.CancelOrder(ctx, "rest", "c-rest"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if len(trades) != 1 || trades[0].Sequence == 0 {
+		t.Fatalf("trades %+v, want one with a sequence", trades)
+	}
+
+	var last uint64
+	lastAt := make(map[string]*domain.OrderEvent)
+	for len(events.C) > 0 {
+		ev := <-events.C
+		if ev.Sequence <= last {
+			t.Errorf("%s %s: sequence %d after %d", ev.OrderID, ev.ExecType, ev.Sequence, last)
+		}
+		if ev.TradeID == trades[0].ID && ev.Sequence <= trades[0].Sequence {
+			t.Errorf("%s fill sequenced %d before its trade %d", ev.OrderID, ev.Sequence, trades[0].Sequence)
+		}
+		last = ev.Sequence
+		lastAt[ev.OrderID] = ev
+	}
+	for id, ev := range lastAt {
+		o, err := e.GetOrder(ctx, id)
+		if err != nil {
+			t.Fatalf("get %s: %v", id, err)
+		}
+		// cancels are stamped by the storage as it applies them, the rest carry the event's time
+		if ev.ExecType == domain.ExecCanceled && o.UpdatedAt.Before(ev.Timestamp) ||
+			ev.ExecType != domain.ExecCanceled && !o.UpdatedAt.Equal(ev.Timestamp) {
+			t.Errorf("%s updated at %s, want its last %s event at %s", id, o.UpdatedAt, ev.ExecType, ev.Timestamp)
+		}
+	}
+}
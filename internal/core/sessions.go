@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+const defaultSessionTimeout = 2 * time.Minute
+
+// ErrUnknownSession is returned for a token that was never issued, has logged out or has expired
+var ErrUnknownSession = errors.New("unknown or expired session")
+
+// sessions are the logged-in trading connections of every tenant, by token. They live in memory only:
+// a restart logs every connection out without cancelling its orders.
+type sessions struct {
+	mu       sync.Mutex
+	timeout  time.Duration
+	byTenant map[string]map[string]*domain.Session
+}
+
+func newSessions() *sessions {
+	return &sessions{timeout: defaultSessionTimeout, byTenant: make(map[string]map[string]*domain.Session)}
+}
+
+// WithSessionTimeout sets how long a session may go without a heartbeat before it expires
+func WithSessionTimeout(d time.Duration) Option {
+	return func(e *Engine) { e.sessions.timeout = d }
+}
+
+// Login opens a session for the client and returns it with its token
+func (e *Engine) Login(ctx context.Context, clientID string, cancelOnDisconnect bool) (domain.Session, error) {
+	if clientID == "" {
+		return domain.Session{}, errors.New("client_id is required")
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return domain.Session{}, err
+	}
+	now := time.Now().UTC()
+	s := &domain.Session{
+		ID:                 uuid.NewString(),
+		Token:              hex.EncodeToString(secret),
+		ClientID:           clientID,
+		CancelOnDisconnect: cancelOnDisconnect,
+		CreatedAt:          now,
+		LastSeen:           now,
+	}
+	e.sessions.mu.Lock()
+	defer e.sessions.mu.Unlock()
+	id := tenant.From(ctx)
+	if e.sessions.byTenant[id] == nil {
+		e.sessions.byTenant[id] = make(map[string]*domain.Session)
+	}
+	e.sessions.byTenant[id][s.Token] = s
+	return *s, nil
+}
+
+// Heartbeat keeps the token's session alive and returns it; every request made through a session
+// counts as one
+func (e *Engine) Heartbeat(ctx context.Context, token string) (domain.Session, error) {
+	e.sessions.mu.Lock()
+	defer e.sessions.mu.Unlock()
+	s, ok := e.sessions.byTenant[tenant.From(ctx)][token]
+	now := time.Now().UTC()
+	if !ok || s.Expired(now, e.sessions.timeout) {
+		return domain.Session{}, ErrUnknownSession
+	}
+	s.LastSeen = now
+	return *s, nil
+}
+
+// Logout closes the token's session and, if it was opened with cancel-on-disconnect, cancels its
+// resting orders, returning their IDs
+func (e *Engine) Logout(ctx context.Context, token string) ([]string, error) {
+	e.sessions.mu.Lock()
+	s, ok := e.sessions.byTenant[tenant.From(ctx)][token]
+	delete(e.sessions.byTenant[tenant.From(ctx)], token)
+	e.sessions.mu.Unlock()
+	if !ok {
+		return nil, ErrUnknownSession
+	}
+	if !s.CancelOnDisconnect {
+		return nil, nil
+	}
+	return e.cancelSession(ctx, *s, "session logged out", domain.CauseDisconnected)
+}
+
+// CancelSessionOrders cancels every resting order placed through the token's session, whichever
+// symbol it is on, and returns their IDs; the session stays logged in
+func (e *Engine) CancelSessionOrders(ctx context.Context, token string) ([]string, error) {
+	s, err := e.Heartbeat(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return e.cancelSession(ctx, s, "cancelled by session", domain.CauseCancelledByUser)
+}
+
+// cancelSession cancels the session's resting orders on the worker of each symbol, publishing what was
+// cancelled before a failure all the same
+func (e *Engine) cancelSession(ctx context.Context, s domain.Session, reason string, cause domain.TransitionCause) ([]string, error) {
+	var symbols []string
+	if e.sharded() {
+		var err error
+		if symbols, err = e.repo.ListSymbols(ctx); err != nil {
+			return nil, err
+		}
+	}
+	cancelled, err := e.cancelOnSymbolWorkers(ctx, symbols, func(symbol string) (map[string]string, error) {
+		return e.repo.CancelSessionOrders(ctx, s.ClientID, s.ID, symbol)
+	})
+	ids := make([]string, 0, len(cancelled))
+	touched := make(map[string]struct{})
+	events := make([]*domain.OrderEvent, 0, len(cancelled))
+	for id, sym := range cancelled {
+		ids = append(ids, id)
+		touched[sym] = struct{}{}
+		ev := cancelledEvent(id, s.ClientID, sym, "", reason)
+		ev.Cause = cause
+		events = append(events, ev)
+	}
+	for sym := range touched {
+		e.bookChanged(ctx, sym)
+	}
+	e.emit(ctx, events...)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// expireSessions logs out the ctx tenant's sessions that missed their heartbeats, cancelling the
+// orders of those opened with cancel-on-disconnect, and returns them
+func (e *Engine) expireSessions(ctx context.Context) ([]domain.Session, error) {
+	now := time.Now().UTC()
+	var expired []domain.Session
+	e.sessions.mu.Lock()
+	for token, s := range e.sessions.byTenant[tenant.From(ctx)] {
+		if s.Expired(now, e.sessions.timeout) {
+			expired = append(expired, *s)
+			delete(e.sessions.byTenant[tenant.From(ctx)], token)
+		}
+	}
+	e.sessions.mu.Unlock()
+	var errs []error
+	for _, s := range expired {
+		if !s.CancelOnDisconnect {
+			continue
+		}
+		if _, err := e.cancelSession(ctx, s, "session timed out", domain.CauseDisconnected); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return expired, errors.Join(errs...)
+}
+
+// RunSessionExpiry expires the sessions of the default and every configured tenant that missed
+// their heartbeats each interval until ctx is done, and passes each tenant's outcome to report if
+// it is not nil
+func (e *Engine) RunSessionExpiry(ctx context.Context, interval time.Duration, report func(tenantID string, expired []domain.Session, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, id := range e.tenantIDs() {
+			expired, err := e.expireSessions(tenant.With(ctx, id))
+			if report != nil && (err != nil || len(expired) > 0) {
+				report(id, expired, err)
+			}
+		}
+	}
+}
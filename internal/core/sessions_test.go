@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestSessionOrders(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"unsharded", nil},
+		{"symbol loops", []Option{WithSymbolLoops()}},
+		{"worker pool", []Option{WithWorkerPool(2)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) { testSessionOrders(t, tc.opts...) })
+	}
+}
+
+func testSessionOrders(t *testing.T, opts ...Option) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, opts...)
+	defer e.StopSymbolLoops()
+	login := func(cancelOnDisconnect bool) domain.Session {
+		t.Helper()
+		s, err := e.Login(ctx, "c", cancelOnDisconnect)
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		return s
+	}
+	submit := func(id, symbol string, s domain.Session) {
+		t.Helper()
+		if _, err := e.SubmitOrder(ctx, &domain.Order{ID: id, ClientID: "c", SessionID: s.ID, Symbol: symbol, Side: domain.Buy,
+			Type: domain.Limit, Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)}); err != nil {
+			t.Fatalf("submit %s: %v", id, err)
+		}
+	}
+	wantCancelled := func(what string, got []string, err error, want ...string) {
+		t.Helper()
+		sort.Strings(got)
+		if err != nil || len(got) != len(want) {
+			t.Fatalf("%s cancelled %v, %v, want %v", what, got, err, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s cancelled %v, want %v", what, got, want)
+			}
+		}
+	}
+	dropping, keeping := login(true), login(false)
+	submit("a1", "BTC/USD", dropping)
+	submit("a2", "ETH/USD", dropping)
+	submit("b1", "BTC/USD", keeping)
+	submit("b2", "ETH/USD", keeping)
+	submit("none", "BTC/USD", domain.Session{})
+
+	ids, err := e.CancelSessionOrders(ctx, keeping.Token)
+	wantCancelled("session cancel", ids, err, "b1", "b2")
+	ids, err = e.Logout(ctx, dropping.Token)
+	wantCancelled("logout", ids, err, "a1", "a2")
+	if _, err := e.Heartbeat(ctx, dropping.Token); err == nil {
+		t.Error("heartbeat of a logged out session succeeded")
+	}
+	if o, err := e.GetOrder(ctx, "none"); err != nil || o.Status != domain.Open {
+		t.Errorf("order without a session read back as %+v, %v", o, err)
+	}
+	hist, err := e.OrderHistory(ctx, "a1", "c")
+	if err != nil || hist[len(hist)-1].Cause != domain.CauseDisconnected {
+		t.Errorf("history of a1 %+v, %v, want it to end disconnected", hist, err)
+	}
+	ids, err = e.Logout(ctx, keeping.Token)
+	wantCancelled("logout without cancel-on-disconnect", ids, err)
+}
+
+func TestSessionExpiry(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithSessionTimeout(time.Millisecond))
+	s, err := e.Login(ctx, "c", true)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if _, err := e.SubmitOrder(ctx, &domain.Order{ID: "o", ClientID: "c", SessionID: s.ID, Symbol: "BTC/USD", Side: domain.Sell,
+		Type: domain.Limit, Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)}); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := e.Heartbeat(ctx, s.Token); err == nil {
+		t.Error("heartbeat of an expired session succeeded")
+	}
+	expired, err := e.expireSessions(ctx)
+	if err != nil || len(expired) != 1 || expired[0].ID != s.ID {
+		t.Fatalf("expired %+v, %v, want the session", expired, err)
+	}
+	if o, err := e.GetOrder(ctx, "o"); err != nil || o.Status != domain.Cancelled {
+		t.Errorf("order of the expired session read back as %+v, %v", o, err)
+	}
+}
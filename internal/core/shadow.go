@@ -0,0 +1,353 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+// Divergence is a command on which the shadow exchange disagreed with the primary. What is "result"
+// (one failed, the other did not), "trades" or "book"; Primary and Shadow render the two outcomes.
+type Divergence struct {
+	At      time.Time
+	Command string
+	OrderID string
+	Symbol  string
+	What    string
+	Primary string
+	Shadow  string
+}
+
+// ShadowStats counts the commands mirrored to the shadow, those it has replayed so far, those it
+// disagreed on, and those dropped because it fell behind
+type ShadowStats struct {
+	Mirrored int64
+	Replayed int64
+	Diverged int64
+	Dropped  int64
+}
+
+// Shadow is an Exchange serving every call from the primary while replaying order entry on a shadow
+// exchange, e.g. a new matching implementation, and reporting where their trades, outcomes and books
+// differ. Replays run in the background in completion order, so the primary's latency is unaffected;
+// under concurrent entry on one symbol the primary's book is captured after neighbouring commands too,
+// so a book divergence is worth confirming before it is trusted.
+type Shadow struct {
+	Exchange
+	shadow Exchange
+	report func(Divergence)
+	queue  chan func()
+	stats  struct{ mirrored, replayed, diverged, dropped atomic.Int64 }
+}
+
+// NewShadow wraps primary, mirroring to shadow; backlog commands may wait for the shadow before new
+// ones are dropped. report is called from the replay goroutine, which runs until ctx ends.
+func NewShadow(ctx context.Context, primary, shadow Exchange, backlog int, report func(Divergence)) *Shadow {
+	s := &Shadow{Exchange: primary, shadow: shadow, report: report, queue: make(chan func(), backlog)}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case replay := <-s.queue:
+				replay()
+				s.stats.replayed.Add(1)
+			}
+		}
+	}()
+	return s
+}
+
+func (s *Shadow) Stats() ShadowStats {
+	return ShadowStats{
+		Mirrored: s.stats.mirrored.Load(), Replayed: s.stats.replayed.Load(),
+		Diverged: s.stats.diverged.Load(), Dropped: s.stats.dropped.Load(),
+	}
+}
+
+// mirror queues a replay on the shadow; ctx keeps the request's values, tenant included, but not its deadline
+func (s *Shadow) mirror(ctx context.Context, replay func(ctx context.Context)) {
+	ctx = context.WithoutCancel(ctx)
+	select {
+	case s.queue <- func() { replay(ctx) }:
+		s.stats.mirrored.Add(1)
+	default:
+		s.stats.dropped.Add(1)
+	}
+}
+
+func (s *Shadow) diverged(d Divergence) {
+	s.stats.diverged.Add(1)
+	d.At = time.Now().UTC()
+	if s.report != nil {
+		s.report(d)
+	}
+}
+
+// compare reports a divergence of what unless the renderings agree
+func (s *Shadow) compare(d Divergence, what, primary, shadow string) {
+	if primary != shadow {
+		d.What, d.Primary, d.Shadow = what, primary, shadow
+		s.diverged(d)
+	}
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return "ok"
+}
+
+// sameOutcome compares success only: the two implementations may word their errors differently
+func (s *Shadow) sameOutcome(d Divergence, primary, shadow error) bool {
+	if (primary == nil) != (shadow == nil) {
+		s.compare(d, "result", outcome(primary), outcome(shadow))
+		return false
+	}
+	return true
+}
+
+func renderTrades(trades []*domain.Trade) string {
+	parts := make([]string, len(trades))
+	for i, t := range trades {
+		parts[i] = fmt.Sprintf("%s<-%s %s@%s", t.BuyOrder, t.SellOrder, t.Quantity, t.Price)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderBook aggregates a book into price levels, best first, ignoring which orders make them up
+func renderBook(ob *domain.OrderbookSnapshot) string {
+	if ob == nil {
+		return ""
+	}
+	side := func(orders []domain.Order, better func(a, b decimal.Decimal) bool) string {
+		levels := make(map[string]decimal.Decimal)
+		var prices []decimal.Decimal
+		for _, o := range orders {
+			k := o.Price.String()
+			if _, ok := levels[k]; !ok {
+				prices = append(prices, o.Price)
+			}
+			levels[k] = levels[k].Add(o.Remaining)
+		}
+		sort.Slice(prices, func(i, j int) bool { return better(prices[i], prices[j]) })
+		parts := make([]string, len(prices))
+		for i, p := range prices {
+			parts[i] = levels[p.String()].String() + "@" + p.String()
+		}
+		return strings.Join(parts, " ")
+	}
+	return "bids " + side(ob.Bids, decimal.Decimal.GreaterThan) + " | asks " + side(ob.Asks, decimal.Decimal.LessThan)
+}
+
+func (s *Shadow) compareBooks(ctx context.Context, d Divergence, primary *domain.OrderbookSnapshot) {
+	if primary == nil {
+		return
+	}
+	shadow, err := s.shadow.GetOrderbook(ctx, d.Symbol)
+	if err != nil {
+		s.compare(d, "book", renderBook(primary), outcome(err))
+		return
+	}
+	s.compare(d, "book", renderBook(primary), renderBook(shadow))
+}
+
+// bookOf captures the primary's book of symbol for a later comparison; nil if it cannot be read
+func (s *Shadow) bookOf(ctx context.Context, symbol string) *domain.OrderbookSnapshot {
+	if symbol == "" {
+		return nil
+	}
+	ob, err := s.Exchange.GetOrderbook(ctx, symbol)
+	if err != nil {
+		return nil
+	}
+	return ob.DeepCopy()
+}
+
+// replayOrder returns the order as the shadow should receive it: the primary's ID, so trades can be
+// compared, and what the client sent
+func replayOrder(o *domain.Order) *domain.Order {
+	return &domain.Order{
+		ID: o.ID, ClientID: o.ClientID, ClientOrderID: o.ClientOrderID, Symbol: o.Symbol,
+		Side: o.Side, Type: o.Type, Price: o.Price, Quantity: o.Quantity,
+	}
+}
+
+func (s *Shadow) SubmitOrder(ctx context.Context, o *domain.Order) ([]*domain.Trade, error) {
+	trades, err := s.Exchange.SubmitOrder(ctx, o)
+	s.mirrorSubmit(ctx, o, trades, err)
+	return trades, err
+}
+
+func (s *Shadow) mirrorSubmit(ctx context.Context, o *domain.Order, trades []*domain.Trade, err error) {
+	replay, book := replayOrder(o), s.bookOf(ctx, o.Symbol)
+	s.mirror(ctx, func(ctx context.Context) {
+		d := Divergence{Command: "submit", OrderID: replay.ID, Symbol: replay.Symbol}
+		shadowTrades, shadowErr := s.shadow.SubmitOrder(ctx, replay)
+		if s.sameOutcome(d, err, shadowErr) {
+			s.compare(d, "trades", renderTrades(trades), renderTrades(shadowTrades))
+		}
+		s.compareBooks(ctx, d, book)
+	})
+}
+
+// BatchSubmitOrders mirrors the batch order by order, in batch order
+func (s *Shadow) BatchSubmitOrders(ctx context.Context, orders []*domain.Order, parallel bool) []SubmitResult {
+	results := s.Exchange.BatchSubmitOrders(ctx, orders, parallel)
+	for _, r := range results {
+		s.mirrorSubmit(ctx, r.Order, r.Trades, r.Err)
+	}
+	return results
+}
+
+func (s *Shadow) CancelOrder(ctx context.Context, orderID, clientID string) (bool, error) {
+	ok, err := s.Exchange.CancelOrder(ctx, orderID, clientID)
+	s.mirrorChange(ctx, "cancel", orderID, err, func(ctx context.Context) error {
+		_, err := s.shadow.CancelOrder(ctx, orderID, clientID)
+		return err
+	})
+	return ok, err
+}
+
+func (s *Shadow) ForceCancelOrder(ctx context.Context, orderID, reason string) (bool, error) {
+	ok, err := s.Exchange.ForceCancelOrder(ctx, orderID, reason)
+	s.mirrorChange(ctx, "force cancel", orderID, err, func(ctx context.Context) error {
+		_, err := s.shadow.ForceCancelOrder(ctx, orderID, reason)
+		return err
+	})
+	return ok, err
+}
+
+func (s *Shadow) ModifyOrder(ctx context.Context, orderID, clientID string, newPrice, newQty decimal.Decimal) ([]*domain.Trade, error) {
+	trades, err := s.Exchange.ModifyOrder(ctx, orderID, clientID, newPrice, newQty)
+	s.mirrorChange(ctx, "modify", orderID, err, func(ctx context.Context) error {
+		_, err := s.shadow.ModifyOrder(ctx, orderID, clientID, newPrice, newQty)
+		return err
+	})
+	return trades, err
+}
+
+// mirrorChange replays a command on one existing order and compares its outcome and the book after it
+func (s *Shadow) mirrorChange(ctx context.Context, command, orderID string, err error, replay func(context.Context) error) {
+	var symbol string
+	if o, lerr := s.Exchange.GetOrder(ctx, orderID); lerr == nil {
+		symbol = o.Symbol
+	}
+	book := s.bookOf(ctx, symbol)
+	s.mirror(ctx, func(ctx context.Context) {
+		d := Divergence{Command: command, OrderID: orderID, Symbol: symbol}
+		s.sameOutcome(d, err, replay(ctx))
+		s.compareBooks(ctx, d, book)
+	})
+}
+
+func (s *Shadow) CancelBySide(ctx context.Context, clientID, symbol string, side domain.Side) ([]string, error) {
+	ids, err := s.Exchange.CancelBySide(ctx, clientID, symbol, side)
+	book := s.bookOf(ctx, symbol)
+	s.mirror(ctx, func(ctx context.Context) {
+		d := Divergence{Command: "cancel by side", Symbol: symbol}
+		shadowIDs, shadowErr := s.shadow.CancelBySide(ctx, clientID, symbol, side)
+		if s.sameOutcome(d, err, shadowErr) {
+			s.compare(d, "cancelled", sortedList(ids), sortedList(shadowIDs))
+		}
+		s.compareBooks(ctx, d, book)
+	})
+	return ids, err
+}
+
+func (s *Shadow) CancelAllOrders(ctx context.Context, clientID, symbol string, side domain.Side) ([]string, error) {
+	ids, err := s.Exchange.CancelAllOrders(ctx, clientID, symbol, side)
+	book := s.bookOf(ctx, symbol)
+	s.mirror(ctx, func(ctx context.Context) {
+		d := Divergence{Command: "cancel all", Symbol: symbol}
+		shadowIDs, shadowErr := s.shadow.CancelAllOrders(ctx, clientID, symbol, side)
+		if s.sameOutcome(d, err, shadowErr) {
+			s.compare(d, "cancelled", sortedList(ids), sortedList(shadowIDs))
+		}
+		s.compareBooks(ctx, d, book)
+	})
+	return ids, err
+}
+
+func (s *Shadow) BatchCancelOrders(ctx context.Context, clientID string, orderIDs, clientOrderIDs []string) ([]CancelResult, error) {
+	results, err := s.Exchange.BatchCancelOrders(ctx, clientID, orderIDs, clientOrderIDs)
+	s.mirror(ctx, func(ctx context.Context) {
+		d := Divergence{Command: "batch cancel"}
+		shadowResults, shadowErr := s.shadow.BatchCancelOrders(ctx, clientID, orderIDs, clientOrderIDs)
+		if s.sameOutcome(d, err, shadowErr) {
+			s.compare(d, "cancelled", cancelledList(results), cancelledList(shadowResults))
+		}
+	})
+	return results, err
+}
+
+func (s *Shadow) BatchModifyOrders(ctx context.Context, clientID string, amends []Amend) ([]ModifyResult, error) {
+	results, err := s.Exchange.BatchModifyOrders(ctx, clientID, amends)
+	s.mirror(ctx, func(ctx context.Context) {
+		d := Divergence{Command: "batch modify"}
+		shadowResults, shadowErr := s.shadow.BatchModifyOrders(ctx, clientID, amends)
+		if s.sameOutcome(d, err, shadowErr) {
+			s.compare(d, "modified", modifiedList(results), modifiedList(shadowResults))
+		}
+	})
+	return results, err
+}
+
+func sortedList(ids []string) string {
+	ids = append([]string(nil), ids...)
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func modifiedList(results []ModifyResult) string {
+	var ids []string
+	for _, r := range results {
+		if r.Modified {
+			ids = append(ids, r.OrderID)
+		}
+	}
+	return sortedList(ids)
+}
+
+func cancelledList(results []CancelResult) string {
+	var ids []string
+	for _, r := range results {
+		if r.Cancelled {
+			ids = append(ids, r.OrderID)
+		}
+	}
+	return sortedList(ids)
+}
+
+// CopyOpenOrders copies every tenant's resting orders into repo, so a shadow started next to a live
+// primary begins from the same book
+func (e *Engine) CopyOpenOrders(ctx context.Context, repo port.Repository) error {
+	for _, id := range e.tenantIDs() {
+		ctx := tenant.With(ctx, id)
+		symbols, err := e.repo.ListSymbols(ctx)
+		if err != nil {
+			return err
+		}
+		for _, symbol := range symbols {
+			orders, err := e.repo.LoadOpenOrders(ctx, symbol)
+			if err != nil {
+				return err
+			}
+			for _, o := range orders {
+				if err := repo.SaveOrder(ctx, o); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
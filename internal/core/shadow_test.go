@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// replayed waits until the shadow has replayed every mirrored command
+func replayed(t *testing.T, s *Shadow) ShadowStats {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st := s.Stats()
+		if st.Replayed == st.Mirrored {
+			return st
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("shadow still behind: %+v", st)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestShadowReportsDivergentMatching(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	primary := NewEngine(memory.NewRepository(), nil)
+	shadowRepo := memory.NewRepository()
+	shadowEngine := NewEngine(shadowRepo, nil)
+
+	var (
+		mu    sync.Mutex
+		found []Divergence
+	)
+	s := NewShadow(ctx, primary, shadowEngine, 16, func(d Divergence) {
+		mu.Lock()
+		defer mu.Unlock()
+		found = append(found, d)
+	})
+
+	sell := &domain.Order{ID: "s1", ClientID: "maker", Symbol: "BTC/USD", Side: domain.Sell, Type: domain.Limit,
+		Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)}
+	if _, err := s.SubmitOrder(ctx, sell); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if _, err := s.ModifyOrder(ctx, "s1", "maker", decimal.NewFromInt(101), decimal.NewFromInt(1)); err != nil {
+		t.Fatalf("modify: %v", err)
+	}
+	if st := replayed(t, s); st.Diverged != 0 {
+		t.Fatalf("identical engines diverged: %+v", found)
+	}
+
+	// a resting order only the shadow knows makes it match differently
+	extra := &domain.Order{ID: "s0", ClientID: "maker", Symbol: "BTC/USD", Side: domain.Sell, Type: domain.Limit,
+		Price: decimal.NewFromInt(99), Quantity: decimal.NewFromInt(1), Remaining: decimal.NewFromInt(1),
+		Status: domain.Open, CreatedAt: time.Now().UTC()}
+	if err := shadowRepo.SaveOrder(ctx, extra); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SubmitOrder(ctx, buy("b1", 101, 1)); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	replayed(t, s)
+
+	mu.Lock()
+	defer mu.Unlock()
+	whats := make(map[string]bool)
+	for _, d := range found {
+		if d.OrderID != "b1" {
+			t.Errorf("divergence on %s, want only b1", d.OrderID)
+		}
+		whats[d.What] = true
+	}
+	if !whats["trades"] || !whats["book"] {
+		t.Errorf("divergences %+v, want trades and book", found)
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// slowLoads counts book loads and holds each until release is closed
+type slowLoads struct {
+	*memory.Repository
+	loads   atomic.Int32
+	release chan struct{}
+}
+
+func (r *slowLoads) LoadSnapshot(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	r.loads.Add(1)
+	<-r.release
+	return r.Repository.LoadSnapshot(ctx, symbol)
+}
+
+func TestConcurrentMissesShareOneLoad(t *testing.T) {
+	repo := &slowLoads{Repository: memory.NewRepository(), release: make(chan struct{})}
+	e := NewEngine(repo, &mapCache{books: make(map[string]*domain.OrderbookSnapshot)})
+	ctx := context.Background()
+
+	const readers = 20
+	books := make([]*domain.OrderbookSnapshot, readers)
+	var wg sync.WaitGroup
+	for i := range books {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ob, err := e.GetOrderbook(ctx, "BTC/USD")
+			if err != nil {
+				t.Errorf("get orderbook: %v", err)
+			}
+			books[i] = ob
+		}()
+	}
+	// a reader that gives up leaves the load to the others
+	gone, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := e.GetOrderbook(gone, "BTC/USD"); err == nil {
+		t.Error("cancelled reader got a book")
+	}
+	// another tenant's book is a different load
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = e.GetOrderbook(tenant.With(ctx, "other"), "BTC/USD")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(repo.release)
+	wg.Wait()
+
+	if n := repo.loads.Load(); n != 2 {
+		t.Errorf("%d loads, want one per tenant", n)
+	}
+	for i, ob := range books {
+		for _, other := range books[:i] {
+			if ob == other {
+				t.Fatal("readers share one book")
+			}
+		}
+	}
+}
@@ -4,34 +4,69 @@ import (
 	"context"
 	"github.com/olyamironova/exchange-engine/internal/domain"
 	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"golang.org/x/sync/singleflight"
 	"sort"
+	"time"
 )
 
-func updateCache(ctx context.Context, repo port.Repository, cache port.Cache, symbol string) {
+// updateCache refreshes the cached book from the repository and returns it, nil if it could not be loaded
+func updateCache(ctx context.Context, repo port.Repository, cache port.Cache, symbol string) *domain.OrderbookSnapshot {
 	if cache == nil {
-		return
+		return nil
 	}
-	snap, err := repo.LoadSnapshot(ctx, symbol)
+	snap, err := loadStamped(ctx, repo, symbol)
 	if err == nil {
-		_ = cache.SetOrderbook(ctx, symbol, snap.DeepCopy())
-	} else {
-		_ = cache.Invalidate(ctx, symbol)
+		// a book that failed to cache must not leave the previous one to be served
+		if cache.SetOrderbook(ctx, symbol, snap.DeepCopy()) != nil {
+			_ = cache.Invalidate(ctx, symbol)
+		}
+		return snap
+	}
+	_ = cache.Invalidate(ctx, symbol)
+	return nil
+}
+
+// loadStamped reads the book from the repository stamped with the sequence number it is fresh as of
+func loadStamped(ctx context.Context, repo port.Repository, symbol string) (*domain.OrderbookSnapshot, error) {
+	seq := sequence.next(time.Now())
+	snap, err := repo.LoadSnapshot(ctx, symbol)
+	if err != nil {
+		return nil, err
 	}
+	snap.Sequence = seq
+	return snap, nil
 }
 
-func getOrLoadSnapshot(ctx context.Context, repo port.Repository, cache port.Cache, symbol string) (*domain.OrderbookSnapshot, error) {
+// getOrLoadSnapshot serves the cached book or, on a miss, loads it from the repository and caches it.
+// Concurrent misses for one book share a single load through loads, each caller getting its own copy;
+// the load runs detached from the caller that started it, so its cancellation fails no one else.
+func getOrLoadSnapshot(ctx context.Context, repo port.Repository, cache port.Cache, loads *singleflight.Group, symbol string) (*domain.OrderbookSnapshot, error) {
 	if cache != nil {
 		if ob, err := cache.GetOrderbook(ctx, symbol); err == nil && ob != nil {
 			return ob, nil
 		}
 	}
 	if repo != nil {
-		ob, err := repo.LoadSnapshot(ctx, symbol)
-		if err == nil {
-			if cache != nil {
-				_ = cache.SetOrderbook(ctx, symbol, ob.DeepCopy())
+		load := context.WithoutCancel(ctx)
+		loaded := loads.DoChan(tenant.Scope(ctx, symbol), func() (any, error) {
+			ob, err := loadStamped(load, repo, symbol)
+			if err == nil && cache != nil {
+				_ = cache.SetOrderbook(load, symbol, ob.DeepCopy())
 			}
-			return ob, nil
+			return ob, err
+		})
+		select {
+		case res := <-loaded:
+			if res.Err == nil {
+				ob := res.Val.(*domain.OrderbookSnapshot)
+				if res.Shared {
+					ob = ob.DeepCopy()
+				}
+				return ob, nil
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
 	}
 	return &domain.OrderbookSnapshot{
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/port"
+)
+
+const snapshotTTL = 24 * time.Hour
+
+var (
+	errNoCatalog        = errors.New("snapshot catalog not configured")
+	errSnapshotNotFound = errors.New("snapshot not found")
+)
+
+// WithSnapshotCatalog records every snapshot taken so it can be listed, inspected and deleted
+func WithSnapshotCatalog(c port.SnapshotCatalog) Option {
+	return func(e *Engine) { e.catalog = c }
+}
+
+// storeSnapshot saves the encoded book under a new id and records it in the catalog
+func (e *Engine) storeSnapshot(ctx context.Context, ob *domain.OrderbookSnapshot, data []byte) (string, error) {
+	snapshotID := uuid.NewString()
+	if err := e.cache.SetSnapshot(ctx, snapshotID, data, snapshotTTL); err != nil {
+		return "", err
+	}
+	if e.catalog != nil {
+		now := time.Now().UTC()
+		meta := &domain.SnapshotMeta{
+			ID:        snapshotID,
+			Symbol:    ob.Symbol,
+			CreatedAt: now,
+			ExpiresAt: now.Add(snapshotTTL),
+			Bids:      len(ob.Bids),
+			Asks:      len(ob.Asks),
+			Bytes:     len(data),
+		}
+		if err := e.catalog.AddSnapshot(ctx, meta, snapshotTTL); err != nil {
+			return "", err
+		}
+	}
+	return snapshotID, nil
+}
+
+// snapshotPos is the page position in a symbol's snapshot list
+type snapshotPos struct {
+	Seq int64 `json:"seq"`
+}
+
+// ListSnapshots returns a page of the stored snapshots of a symbol, newest first
+func (e *Engine) ListSnapshots(ctx context.Context, symbol string, req page.Request) (page.Page[domain.SnapshotMeta], error) {
+	if e.catalog == nil {
+		return page.Page[domain.SnapshotMeta]{}, errNoCatalog
+	}
+	var pos snapshotPos
+	if ok, err := page.Decode(req.Cursor, &pos); err != nil || (ok && pos.Seq <= 0) {
+		return page.Page[domain.SnapshotMeta]{}, page.ErrInvalidCursor
+	}
+	metas, err := e.catalog.ListSnapshots(ctx, symbol, pos.Seq, int64(page.Fetch(req.Limit)))
+	if err != nil {
+		return page.Page[domain.SnapshotMeta]{}, err
+	}
+	return page.Build(metas, req.Limit, func(m domain.SnapshotMeta) any { return snapshotPos{Seq: m.Sequence} }), nil
+}
+
+func (e *Engine) GetSnapshotMeta(ctx context.Context, snapshotID string) (*domain.SnapshotMeta, error) {
+	if e.catalog == nil {
+		return nil, errNoCatalog
+	}
+	meta, err := e.catalog.GetSnapshotMeta(ctx, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, errSnapshotNotFound
+	}
+	return meta, nil
+}
+
+// GetSnapshot returns the orderbook stored in a snapshot without restoring it
+func (e *Engine) GetSnapshot(ctx context.Context, snapshotID string) (*domain.OrderbookSnapshot, error) {
+	if e.cache == nil {
+		return nil, errors.New("cache not configured")
+	}
+	data, err := e.cache.GetSnapshot(ctx, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, errSnapshotNotFound
+	}
+	return decodeSnapshot(data)
+}
+
+func decodeSnapshot(data []byte) (*domain.OrderbookSnapshot, error) {
+	var ob domain.OrderbookSnapshot
+	if err := json.Unmarshal(data, &ob); err != nil {
+		return nil, err
+	}
+	if ob.Symbol == "" {
+		return nil, errors.New("snapshot has no symbol")
+	}
+	return &ob, nil
+}
+
+func (e *Engine) DeleteSnapshot(ctx context.Context, snapshotID string) error {
+	if e.catalog == nil {
+		return errNoCatalog
+	}
+	return e.catalog.DeleteSnapshot(ctx, snapshotID)
+}
+
+// PruneSnapshots deletes the symbol's snapshots taken before the cutoff and returns how many were removed
+func (e *Engine) PruneSnapshots(ctx context.Context, symbol string, before time.Time) (int, error) {
+	if e.catalog == nil {
+		return 0, errNoCatalog
+	}
+	metas, err := e.catalog.ListSnapshots(ctx, symbol, 0, -1)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, m := range metas {
+		if !m.CreatedAt.Before(before) {
+			continue
+		}
+		if err := e.catalog.DeleteSnapshot(ctx, m.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// maxStatementPeriod bounds one statement to keep its query and response reasonable
+const maxStatementPeriod = 366 * 24 * time.Hour
+
+// Statement builds the client's statement over [from, to) from its trade history, only from the fills
+// of orders carrying tag if it is not empty
+func (e *Engine) Statement(ctx context.Context, clientID string, from, to time.Time, tag string) (*domain.Statement, error) {
+	if clientID == "" {
+		return nil, errors.New("client_id is required")
+	}
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	if to.Sub(from) > maxStatementPeriod {
+		return nil, errors.New("statement period must not exceed 366 days")
+	}
+	fills, err := e.repo.LoadClientFills(ctx, clientID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if tag != "" {
+		fills = slices.DeleteFunc(fills, func(f domain.StatementFill) bool { return !domain.HasTag(f.Tags, tag) })
+	}
+
+	st := &domain.Statement{ClientID: clientID, From: from, To: to, Fills: fills}
+	positions := make(map[string]decimal.Decimal)
+	fees := make(map[string]decimal.Decimal)
+	move := func(f domain.StatementFill, kind domain.MovementKind, asset string, amount decimal.Decimal) {
+		st.Movements = append(st.Movements, domain.LedgerMovement{
+			Timestamp: f.Timestamp, TradeID: f.TradeID, Kind: kind, Asset: asset, Amount: amount,
+		})
+		positions[asset] = positions[asset].Add(amount)
+	}
+	for _, f := range fills {
+		base, quote, err := e.assets(ctx, f.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		notional := f.Price.Mul(f.Quantity)
+		if f.Side == domain.Buy {
+			move(f, domain.MovementTrade, base, f.Quantity)
+			move(f, domain.MovementTrade, quote, notional.Neg())
+		} else {
+			move(f, domain.MovementTrade, base, f.Quantity.Neg())
+			move(f, domain.MovementTrade, quote, notional)
+		}
+		if !f.Fee.IsZero() {
+			move(f, domain.MovementFee, quote, f.Fee.Neg())
+			fees[quote] = fees[quote].Add(f.Fee)
+		}
+	}
+	st.Positions = assetAmounts(positions)
+	st.Fees = assetAmounts(fees)
+	return st, nil
+}
+
+func assetAmounts(m map[string]decimal.Decimal) []domain.AssetAmount {
+	out := make([]domain.AssetAmount, 0, len(m))
+	for asset, amount := range m {
+		out = append(out, domain.AssetAmount{Asset: asset, Amount: amount})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Asset < out[j].Asset })
+	return out
+}
@@ -0,0 +1,64 @@
+package core
+
+import "github.com/olyamironova/exchange-engine/internal/pubsub"
+
+// Stream names for WithStreamPolicy and StreamStats
+const (
+	StreamImbalance   = "imbalance"
+	StreamTrades      = "trades"
+	StreamOrderEvents = "order_events"
+	StreamOrderbook   = "orderbook"
+	StreamQuotes      = "quotes"
+
+	StreamSurveillanceAlerts = "surveillance_alerts"
+)
+
+// WithStreamPolicy sets how subscribers of a stream that fall behind are handled; the default drops
+// updates and reports the gap
+func WithStreamPolicy(stream string, opts pubsub.Options) Option {
+	return func(e *Engine) { e.streams[stream] = opts }
+}
+
+// StreamStats returns subscriber, queue and drop counters per stream
+func (e *Engine) StreamStats() map[string]pubsub.Stats {
+	return map[string]pubsub.Stats{
+		StreamImbalance:   e.imbalances.Stats(),
+		StreamTrades:      e.trades.Stats(),
+		StreamOrderEvents: e.events.Stats(),
+		StreamOrderbook:   e.books.Stats(),
+		StreamQuotes:      e.quotes.Stats(),
+
+		StreamSurveillanceAlerts: e.alerts.Stats(),
+	}
+}
+
+// StreamTopicStats returns the counters of every topic of the stream that has subscribers, e.g.
+// per symbol of the trade stream; ok is false for an unknown stream
+func (e *Engine) StreamTopicStats(stream string) (stats []pubsub.TopicStats, ok bool) {
+	switch stream {
+	case StreamImbalance:
+		return e.imbalances.TopicStats(), true
+	case StreamTrades:
+		return e.trades.TopicStats(), true
+	case StreamOrderEvents:
+		return e.events.TopicStats(), true
+	case StreamOrderbook:
+		return e.books.TopicStats(), true
+	case StreamQuotes:
+		return e.quotes.TopicStats(), true
+	case StreamSurveillanceAlerts:
+		return e.alerts.TopicStats(), true
+	}
+	return nil, false
+}
+
+// CloseStreams ends every stream subscription with pubsub.ErrClosed, so consumers stop on shutdown
+// instead of waiting for updates that will not come
+func (e *Engine) CloseStreams() {
+	e.imbalances.Close()
+	e.trades.Close()
+	e.events.Close()
+	e.books.Close()
+	e.quotes.Close()
+	e.alerts.Close()
+}
@@ -0,0 +1,266 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/shopspring/decimal"
+)
+
+var errCrash = errors.New("crash")
+
+// crashRepo keeps committed orders and trades in memory. A crash step makes that step fail, which
+// aborts the transaction exactly as a process dying before commit would: nothing it wrote survives.
+type crashRepo struct {
+	port.Repository
+
+	mu     sync.Mutex
+	orders map[string]domain.Order
+	trades []domain.Trade
+	begun  int
+	crash  func(step string, o *domain.Order) bool
+}
+
+func newCrashRepo() *crashRepo {
+	return &crashRepo{orders: make(map[string]domain.Order)}
+}
+
+func (r *crashRepo) BeginTx(ctx context.Context) (port.Tx, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.begun++
+	return &crashTx{repo: r, orders: make(map[string]domain.Order)}, nil
+}
+
+func (r *crashRepo) LoadDelistings(ctx context.Context) ([]domain.Delisting, error) { return nil, nil }
+
+func (r *crashRepo) LoadInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return nil, nil
+}
+
+func (r *crashRepo) LoadTriggerOrders(ctx context.Context, symbol string, crossed *domain.PriceRange) ([]*domain.Order, error) {
+	return nil, nil
+}
+
+func (r *crashRepo) SaveTransitions(ctx context.Context, ts []domain.OrderTransition) error {
+	return nil
+}
+
+func (r *crashRepo) order(id string) (domain.Order, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.orders[id]
+	return o, ok
+}
+
+func (r *crashRepo) crashes(step string, o *domain.Order) bool {
+	return r.crash != nil && r.crash(step, o)
+}
+
+type crashTx struct {
+	port.Tx
+
+	repo   *crashRepo
+	orders map[string]domain.Order
+	trades []domain.Trade
+}
+
+func (t *crashTx) LockSymbol(ctx context.Context, symbol string) error { return nil }
+
+func (t *crashTx) AppendLedger(ctx context.Context, evs []domain.LedgerEvent) error { return nil }
+
+func (t *crashTx) SaveOrder(ctx context.Context, o *domain.Order) error {
+	if t.repo.crashes("save-order", o) {
+		return errCrash
+	}
+	t.orders[o.ID] = *o
+	return nil
+}
+
+func (t *crashTx) SaveTrade(ctx context.Context, tr *domain.Trade) error {
+	if t.repo.crashes("save-trade", nil) {
+		return errCrash
+	}
+	t.trades = append(t.trades, *tr)
+	return nil
+}
+
+func (t *crashTx) LoadCandidatesForMatch(ctx context.Context, symbol string, side domain.Side, limitPrice *decimal.Decimal, after *domain.BookKey, limit int) ([]*domain.Order, error) {
+	t.repo.mu.Lock()
+	view := make(map[string]domain.Order, len(t.repo.orders))
+	for id, o := range t.repo.orders {
+		view[id] = o
+	}
+	t.repo.mu.Unlock()
+	for id, o := range t.orders {
+		view[id] = o
+	}
+
+	var out []*domain.Order
+	for _, o := range view {
+		if o.Symbol != symbol || o.Side == side || (o.Status != domain.Open && o.Status != domain.PartiallyFilled) {
+			continue
+		}
+		if limitPrice != nil && ((side == domain.Buy && o.Price.GreaterThan(*limitPrice)) || (side == domain.Sell && o.Price.LessThan(*limitPrice))) {
+			continue
+		}
+		o := o
+		out = append(out, &o)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Price.Equal(out[j].Price) {
+			return out[i].Price.LessThan(out[j].Price) == (side == domain.Buy)
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	if after != nil {
+		for i, o := range out {
+			if o.ID == after.ID {
+				out = out[i+1:]
+				break
+			}
+		}
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (t *crashTx) Commit(ctx context.Context) error {
+	if t.repo.crashes("commit", nil) {
+		return errCrash
+	}
+	t.repo.mu.Lock()
+	defer t.repo.mu.Unlock()
+	for id, o := range t.orders {
+		t.repo.orders[id] = o
+	}
+	t.repo.trades = append(t.repo.trades, t.trades...)
+	return nil
+}
+
+func (t *crashTx) Rollback(ctx context.Context) error { return nil }
+
+func restingSell(r *crashRepo, id string, price, qty int64) {
+	r.orders[id] = domain.Order{
+		ID: id, ClientID: "maker", Symbol: "BTC/USD", Side: domain.Sell, Type: domain.Limit,
+		Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(qty), Remaining: decimal.NewFromInt(qty),
+		Status: domain.Open, CreatedAt: time.Now().UTC().Add(-time.Minute),
+	}
+}
+
+func buy(id string, price, qty int64) *domain.Order {
+	return &domain.Order{
+		ID: id, ClientID: "taker", Symbol: "BTC/USD", Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(qty),
+	}
+}
+
+func TestSubmitOrderCommitsFinalStatusWithTrades(t *testing.T) {
+	tests := []struct {
+		name      string
+		qty       int64
+		status    domain.OrderStatus
+		remaining int64
+		makerLeft int64
+	}{
+		{name: "filled", qty: 1, status: domain.Filled, remaining: 0, makerLeft: 1},
+		{name: "partially filled", qty: 3, status: domain.PartiallyFilled, remaining: 1, makerLeft: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newCrashRepo()
+			restingSell(repo, "s1", 100, 2)
+			e := NewEngine(repo, nil)
+
+			trades, err := e.SubmitOrder(context.Background(), buy("b1", 100, tt.qty))
+			if err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+			if len(trades) != 1 || len(repo.trades) != 1 {
+				t.Fatalf("trades returned %d, committed %d, want 1", len(trades), len(repo.trades))
+			}
+			if repo.begun != 1 {
+				t.Errorf("submission used %d transactions, want 1", repo.begun)
+			}
+			got, _ := repo.order("b1")
+			if got.Status != tt.status || !got.Remaining.Equal(decimal.NewFromInt(tt.remaining)) {
+				t.Errorf("taker committed as %s with %s remaining, want %s with %d", got.Status, got.Remaining, tt.status, tt.remaining)
+			}
+			maker, _ := repo.order("s1")
+			if !maker.Remaining.Equal(decimal.NewFromInt(tt.makerLeft)) {
+				t.Errorf("maker remaining %s, want %d", maker.Remaining, tt.makerLeft)
+			}
+		})
+	}
+}
+
+func TestSubmitOrderMatchesAcrossCandidateBatches(t *testing.T) {
+	repo := newCrashRepo()
+	for _, id := range []string{"s1", "s2", "s3", "s4", "s5"} {
+		restingSell(repo, id, 100, 1)
+	}
+	e := NewEngine(repo, nil, WithMatchLimits(2, time.Second))
+	trades, err := e.SubmitOrder(context.Background(), buy("b1", 100, 5))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if len(trades) != 5 {
+		t.Fatalf("%d trades over batches of 2, want 5", len(trades))
+	}
+	if got, _ := repo.order("b1"); got.Status != domain.Filled {
+		t.Errorf("taker %s, want FILLED", got.Status)
+	}
+}
+
+func TestSubmitOrderCrashLeavesNoPartialState(t *testing.T) {
+	tests := []struct {
+		name  string
+		crash func(step string, o *domain.Order) bool
+	}{
+		{name: "during match", crash: func(step string, _ *domain.Order) bool { return step == "save-trade" }},
+		{name: "writing final status", crash: func(step string, o *domain.Order) bool {
+			return step == "save-order" && o.ID == "b1" && o.Status == domain.Filled
+		}},
+		{name: "at commit", crash: func(step string, _ *domain.Order) bool { return step == "commit" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newCrashRepo()
+			restingSell(repo, "s1", 100, 1)
+			repo.crash = tt.crash
+
+			if _, err := NewEngine(repo, nil).SubmitOrder(context.Background(), buy("b1", 100, 1)); !errors.Is(err, errCrash) {
+				t.Fatalf("submit error %v, want crash", err)
+			}
+			if _, ok := repo.order("b1"); ok {
+				t.Error("crashed submission left the order behind")
+			}
+			if len(repo.trades) != 0 {
+				t.Errorf("crashed submission left %d trades behind", len(repo.trades))
+			}
+			if maker, _ := repo.order("s1"); maker.Status != domain.Open || !maker.Remaining.Equal(decimal.NewFromInt(1)) {
+				t.Errorf("maker left as %s with %s remaining, want untouched", maker.Status, maker.Remaining)
+			}
+
+			// after a restart the order is resubmitted against the untouched book
+			repo.crash = nil
+			if _, err := NewEngine(repo, nil).SubmitOrder(context.Background(), buy("b1", 100, 1)); err != nil {
+				t.Fatalf("resubmit: %v", err)
+			}
+			if got, _ := repo.order("b1"); got.Status != domain.Filled {
+				t.Errorf("resubmitted order committed as %s, want FILLED", got.Status)
+			}
+			if len(repo.trades) != 1 {
+				t.Errorf("resubmission committed %d trades, want 1", len(repo.trades))
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultRoundTripWindow = time.Minute
+	defaultVolumeBaseline  = 24
+	// minVolumeBaseline scans must have run before a symbol's volume can be anomalous
+	minVolumeBaseline = 3
+)
+
+var defaultVolumeFactor = decimal.NewFromInt(5)
+
+// alertNamespace derives alert IDs from findings
+var alertNamespace = uuid.MustParse("0d7c3b0e-8f7e-4d55-9d35-6f0b8f3a51c2")
+
+// WithSurveillance enables RunSurveillance, which scans executed trades for wash trading and volume
+// anomalies under p, and the order-flow checks, which look for spoofing, layering, excessive cancels
+// and price impact as order events are committed. Findings are stored for compliance review and
+// published to SubscribeSurveillanceAlerts.
+func WithSurveillance(p domain.SurveillancePolicy) Option {
+	if p.RoundTripWindow <= 0 {
+		p.RoundTripWindow = defaultRoundTripWindow
+	}
+	if !p.VolumeFactor.IsPositive() {
+		p.VolumeFactor = defaultVolumeFactor
+	}
+	if p.VolumeBaseline <= 0 {
+		p.VolumeBaseline = defaultVolumeBaseline
+	}
+	if p.OrderFlowWindow <= 0 {
+		p.OrderFlowWindow = defaultOrderFlowWindow
+	}
+	if p.MinCancels <= 0 {
+		p.MinCancels = defaultMinCancels
+	}
+	if !p.CancelRatio.IsPositive() {
+		p.CancelRatio = defaultCancelRatio
+	}
+	if !p.SpoofFactor.IsPositive() {
+		p.SpoofFactor = defaultSpoofFactor
+	}
+	if p.LayeringLevels <= 0 {
+		p.LayeringLevels = defaultLayeringLevels
+	}
+	if !p.PriceImpactBps.IsPositive() {
+		p.PriceImpactBps = defaultPriceImpactBps
+	}
+	return func(e *Engine) { e.surveillance = newSurveillance(p) }
+}
+
+// surveillance remembers, per tenant, where the last scan ended and the volume each symbol traded in
+// the last scans
+type surveillance struct {
+	policy  domain.SurveillancePolicy
+	flow    *orderFlow
+	mu      sync.Mutex
+	scanned map[string]time.Time
+	volumes map[string][]decimal.Decimal // tenant-scoped symbol -> volume per scan, oldest first
+}
+
+func newSurveillance(p domain.SurveillancePolicy) *surveillance {
+	return &surveillance{policy: p, flow: newOrderFlow(), scanned: make(map[string]time.Time), volumes: make(map[string][]decimal.Decimal)}
+}
+
+// RunSurveillance scans the trades of the default and every configured tenant executed since its
+// previous scan each interval until ctx is done, passing each outcome to report if it is not nil
+func (e *Engine) RunSurveillance(ctx context.Context, interval time.Duration, report func(tenantID string, alerts int, err error)) {
+	if e.surveillance == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := time.Now().UTC()
+		for _, id := range e.tenantIDs() {
+			e.surveillance.mu.Lock()
+			from, ok := e.surveillance.scanned[id]
+			e.surveillance.mu.Unlock()
+			if !ok {
+				from = now.Add(-interval)
+			}
+			alerts, err := e.surveil(tenant.With(ctx, id), from, now)
+			if report != nil {
+				report(id, len(alerts), err)
+			}
+		}
+	}
+}
+
+// surveil scans the ctx tenant's trades executed in [from, to) and stores the alerts raised. Round trips
+// are looked for from one window before from, so a pair split by two scans is still found.
+func (e *Engine) surveil(ctx context.Context, from, to time.Time) ([]domain.SurveillanceAlert, error) {
+	s := e.surveillance
+	var trades []*domain.Trade
+	f := domain.ExportFilter{From: from.Add(-s.policy.RoundTripWindow), To: to}
+	if err := e.repo.ScanTrades(ctx, f, func(t *domain.Trade) error {
+		trades = append(trades, t)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var alerts []domain.SurveillanceAlert
+	raise := func(kind domain.AlertKind, symbol string, at time.Time, clients, tradeIDs []string, detail string, key ...string) {
+		key = append(append([]string(nil), tradeIDs...), key...)
+		alerts = append(alerts, domain.SurveillanceAlert{
+			ID:   uuid.NewSHA1(alertNamespace, []byte(string(kind)+"|"+strings.Join(key, ","))).String(),
+			Kind: kind, Symbol: symbol, ClientIDs: clients, TradeIDs: tradeIDs, Detail: detail,
+			At: at, DetectedAt: now, Status: domain.AlertOpen,
+		})
+	}
+
+	for _, t := range trades {
+		if t.Timestamp.Before(from) || t.BuyClient == "" || t.SellClient == "" {
+			continue
+		}
+		if t.BuyClient == t.SellClient {
+			raise(domain.AlertSelfMatch, t.Symbol, t.Timestamp, []string{t.BuyClient}, []string{t.ID},
+				fmt.Sprintf("client traded %s @ %s with itself", t.Quantity, t.Price))
+		} else if g := s.policy.Groups[t.BuyClient]; g != "" && g == s.policy.Groups[t.SellClient] {
+			raise(domain.AlertGroupMatch, t.Symbol, t.Timestamp, []string{t.BuyClient, t.SellClient}, []string{t.ID},
+				fmt.Sprintf("clients of group %s traded %s @ %s with each other", g, t.Quantity, t.Price))
+		}
+	}
+
+	for _, rt := range roundTrips(trades, s.policy.RoundTripWindow) {
+		if rt.second.trade.Timestamp.Before(from) {
+			continue
+		}
+		first, second := rt.first.trade, rt.second.trade
+		raise(domain.AlertRoundTrip, first.Symbol, second.Timestamp, []string{rt.first.client}, []string{first.ID, second.ID},
+			fmt.Sprintf("%s %s @ %s then %s @ %s within %s", rt.first.side, first.Quantity, first.Price,
+				rt.second.side, second.Price, second.Timestamp.Sub(first.Timestamp)))
+	}
+
+	anomalies, volumes := s.volumeAnomalies(tenant.From(ctx), trades, from)
+	for _, v := range anomalies {
+		raise(domain.AlertVolumeAnomaly, v.symbol, v.at, nil, nil,
+			fmt.Sprintf("traded %s against an average of %s over the last %d scans", v.volume, v.baseline.Round(8), v.scans),
+			v.symbol, from.Format(time.RFC3339Nano))
+	}
+
+	if len(alerts) > 0 {
+		if err := e.repo.SaveAlerts(ctx, alerts); err != nil {
+			return nil, err
+		}
+		e.publishAlerts(ctx, alerts)
+	}
+	s.recordScan(tenant.From(ctx), to, volumes)
+	return alerts, nil
+}
+
+type fill struct {
+	trade  *domain.Trade
+	client string
+	side   domain.Side
+}
+
+type roundTrip struct{ first, second fill }
+
+// roundTrips pairs each client's fills in a symbol with the earliest unpaired fill on the other side of
+// the same quantity at most window before it. Self matches are left to their own alert.
+func roundTrips(trades []*domain.Trade, window time.Duration) []roundTrip {
+	open := make(map[string][]fill) // client|symbol -> unpaired fills, oldest first
+	var out []roundTrip
+	for _, t := range trades {
+		if t.BuyClient == t.SellClient {
+			continue
+		}
+		for _, f := range []fill{{t, t.BuyClient, domain.Buy}, {t, t.SellClient, domain.Sell}} {
+			if f.client == "" {
+				continue
+			}
+			key := f.client + "|" + t.Symbol
+			fills := open[key]
+			for len(fills) > 0 && t.Timestamp.Sub(fills[0].trade.Timestamp) > window {
+				fills = fills[1:]
+			}
+			paired := false
+			for i, prev := range fills {
+				if prev.side != f.side && prev.trade.Quantity.Equal(t.Quantity) {
+					out = append(out, roundTrip{prev, f})
+					fills = append(fills[:i:i], fills[i+1:]...)
+					paired = true
+					break
+				}
+			}
+			if !paired {
+				fills = append(fills, f)
+			}
+			open[key] = fills
+		}
+	}
+	return out
+}
+
+type volumeAnomaly struct {
+	symbol   string
+	at       time.Time
+	volume   decimal.Decimal
+	baseline decimal.Decimal
+	scans    int
+}
+
+// volumeAnomalies compares each symbol's volume since from with its average over the previous scans;
+// it returns the volumes to record once the scan's alerts are stored
+func (s *surveillance) volumeAnomalies(tenantID string, trades []*domain.Trade, from time.Time) ([]volumeAnomaly, map[string]decimal.Decimal) {
+	volumes := make(map[string]decimal.Decimal)
+	last := make(map[string]time.Time)
+	for _, t := range trades {
+		if !t.Timestamp.Before(from) {
+			volumes[t.Symbol] = volumes[t.Symbol].Add(t.Quantity)
+			last[t.Symbol] = t.Timestamp
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.volumes {
+		if id, symbol := tenant.Split(key); id == tenantID {
+			if _, ok := volumes[symbol]; !ok {
+				volumes[symbol] = decimal.Zero
+			}
+		}
+	}
+	var out []volumeAnomaly
+	for symbol, v := range volumes {
+		history := s.volumes[tenant.ScopeID(tenantID, symbol)]
+		if len(history) < minVolumeBaseline || !v.IsPositive() {
+			continue
+		}
+		baseline := decimal.Sum(decimal.Zero, history...).Div(decimal.NewFromInt(int64(len(history))))
+		if v.GreaterThan(baseline.Mul(s.policy.VolumeFactor)) {
+			out = append(out, volumeAnomaly{symbol: symbol, at: last[symbol], volume: v, baseline: baseline, scans: len(history)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].symbol < out[j].symbol })
+	return out, volumes
+}
+
+// recordScan ends a scan at to, keeping the last VolumeBaseline volumes of every symbol
+func (s *surveillance) recordScan(tenantID string, to time.Time, volumes map[string]decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for symbol, v := range volumes {
+		key := tenant.ScopeID(tenantID, symbol)
+		history := append(s.volumes[key], v)
+		if len(history) > s.policy.VolumeBaseline {
+			history = history[len(history)-s.policy.VolumeBaseline:]
+		}
+		s.volumes[key] = history
+	}
+	s.scanned[tenantID] = to
+}
+
+// SurveillanceAlerts lists the ctx tenant's alerts matching f, oldest first
+func (e *Engine) SurveillanceAlerts(ctx context.Context, f domain.AlertFilter, req page.Request) (page.Page[domain.SurveillanceAlert], error) {
+	var after *page.Key
+	var pos page.Key
+	if ok, err := page.Decode(req.Cursor, &pos); err != nil || (ok && pos.ID == "") {
+		return page.Page[domain.SurveillanceAlert]{}, page.ErrInvalidCursor
+	} else if ok {
+		after = &pos
+	}
+	alerts, err := e.repo.LoadAlerts(ctx, f, after, page.Fetch(req.Limit))
+	if err != nil {
+		return page.Page[domain.SurveillanceAlert]{}, err
+	}
+	return page.Build(alerts, req.Limit, func(a domain.SurveillanceAlert) any { return page.Key{At: a.At, ID: a.ID} }), nil
+}
+
+// ReviewAlert records a compliance decision on an alert; reopening it with AlertOpen is allowed
+func (e *Engine) ReviewAlert(ctx context.Context, id string, status domain.AlertStatus, reviewer, note string) error {
+	switch status {
+	case domain.AlertOpen, domain.AlertEscalated, domain.AlertDismissed:
+	default:
+		return fmt.Errorf("status must be %s, %s or %s", domain.AlertOpen, domain.AlertEscalated, domain.AlertDismissed)
+	}
+	if id == "" || reviewer == "" {
+		return errors.New("alert_id and reviewer are required")
+	}
+	return e.repo.ReviewAlert(ctx, id, status, reviewer, note, time.Now().UTC())
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/shopspring/decimal"
+)
+
+func TestSurveillanceFindsWashTrades(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithSurveillance(domain.SurveillancePolicy{
+		Groups: map[string]string{"g1": "fund", "g2": "fund"},
+	}))
+	start := time.Now().UTC()
+	submit := func(client string, side domain.Side, price, qty int64) {
+		t.Helper()
+		if _, err := e.SubmitOrder(ctx, &domain.Order{ClientID: client, Symbol: "BTC/USD", Side: side, Type: domain.Limit,
+			Price: decimal.NewFromInt(price), Quantity: decimal.NewFromInt(qty)}); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+	submit("w", domain.Sell, 100, 1) // w trades with itself
+	submit("w", domain.Buy, 100, 1)
+	submit("g1", domain.Sell, 101, 1) // two accounts of one fund trade with each other
+	submit("g2", domain.Buy, 101, 1)
+	submit("m", domain.Sell, 102, 2) // r buys 2 and sells them straight back
+	submit("r", domain.Buy, 102, 2)
+	submit("n", domain.Buy, 99, 2)
+	submit("r", domain.Sell, 99, 2)
+
+	end := time.Now().UTC().Add(time.Second)
+	alerts, err := e.surveil(ctx, start, end)
+	if err != nil {
+		t.Fatalf("surveil: %v", err)
+	}
+	kinds := make(map[domain.AlertKind][]string)
+	for _, a := range alerts {
+		kinds[a.Kind] = append(kinds[a.Kind], a.ClientIDs...)
+	}
+	if len(alerts) != 3 || len(kinds[domain.AlertSelfMatch]) != 1 || kinds[domain.AlertSelfMatch][0] != "w" ||
+		len(kinds[domain.AlertGroupMatch]) != 2 || len(kinds[domain.AlertRoundTrip]) != 1 || kinds[domain.AlertRoundTrip][0] != "r" {
+		t.Fatalf("alerts %+v, want a self match of w, a group match of g1 and g2 and a round trip of r", alerts)
+	}
+
+	// a rescan finds the same alerts and stores nothing new
+	if _, err := e.surveil(ctx, start, end); err != nil {
+		t.Fatalf("rescan: %v", err)
+	}
+	listed, err := e.SurveillanceAlerts(ctx, domain.AlertFilter{}, page.Request{Limit: 10})
+	if err != nil || len(listed.Items) != 3 {
+		t.Fatalf("listed %d alerts, %v, want 3", len(listed.Items), err)
+	}
+
+	if err := e.ReviewAlert(ctx, alerts[0].ID, "CLOSED", "compliance", ""); err == nil {
+		t.Error("review accepted an unknown status")
+	}
+	if err := e.ReviewAlert(ctx, alerts[0].ID, domain.AlertDismissed, "compliance", "test account"); err != nil {
+		t.Fatalf("review: %v", err)
+	}
+	open, err := e.SurveillanceAlerts(ctx, domain.AlertFilter{Status: domain.AlertOpen}, page.Request{Limit: 10})
+	if err != nil || len(open.Items) != 2 {
+		t.Errorf("%d open alerts after a dismissal, %v, want 2", len(open.Items), err)
+	}
+}
+
+func TestSurveillanceVolumeAnomaly(t *testing.T) {
+	s := newSurveillance(domain.SurveillancePolicy{VolumeFactor: decimal.NewFromInt(5), VolumeBaseline: 4})
+	at := time.Now().UTC()
+	trade := func(qty int64) []*domain.Trade {
+		return []*domain.Trade{{ID: "t", Symbol: "BTC/USD", Quantity: decimal.NewFromInt(qty), Timestamp: at}}
+	}
+	for i := 0; i < minVolumeBaseline; i++ {
+		anomalies, volumes := s.volumeAnomalies("default", trade(2), at)
+		if len(anomalies) != 0 {
+			t.Fatalf("scan %d raised %+v before the baseline was built", i, anomalies)
+		}
+		s.recordScan("default", at, volumes)
+	}
+	if anomalies, _ := s.volumeAnomalies("default", trade(10), at); len(anomalies) != 0 {
+		t.Errorf("5x the baseline raised %+v, want only more than 5x", anomalies)
+	}
+	anomalies, _ := s.volumeAnomalies("default", trade(11), at)
+	if len(anomalies) != 1 || !anomalies[0].baseline.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("anomalies %+v, want one against a baseline of 2", anomalies)
+	}
+}
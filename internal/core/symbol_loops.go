@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// ErrSymbolLoopsStopped is returned for work submitted after StopSymbolLoops
+var ErrSymbolLoopsStopped = errors.New("symbol loops stopped")
+
+// symbolLoopIdle is how long a symbol's loop waits for work before it exits; the next order for
+// the symbol starts a new one
+const symbolLoopIdle = time.Minute
+
+// WithSymbolLoops runs each tenant's symbol on a goroutine of its own, started on first use and
+// stopped once idle, which takes the symbol's submissions, amends and cancels from its input channel
+// one at a time in arrival order. Matching on one symbol never waits for another, and a symbol's
+// book sees its orders in a deterministic sequence. It replaces a worker pool.
+func WithSymbolLoops() Option {
+	return func(e *Engine) {
+		e.pool = nil
+		e.loops = &symbolLoops{bySymbol: make(map[string]*symbolLoop), inflight: e.ops, idle: symbolLoopIdle}
+	}
+}
+
+type symbolLoops struct {
+	mu       sync.Mutex
+	bySymbol map[string]*symbolLoop
+	inflight *orderOps
+	idle     time.Duration
+	stopped  bool
+	// sending counts callers between finding a loop and queuing their job on it, which stop waits
+	// for before closing the channels
+	sending sync.WaitGroup
+	running sync.WaitGroup
+}
+
+type symbolLoop struct {
+	jobs chan func()
+	// pending counts the jobs queued on the loop or about to be, under symbolLoops.mu; the loop
+	// exits only while it is zero
+	pending int
+}
+
+// run executes fn on the loop of the tenant-scoped symbol and waits for it, once queued even if ctx
+// ends, as workerPool.run does
+func (l *symbolLoops) run(ctx context.Context, symbol string, fn func()) error {
+	key := tenant.Scope(ctx, symbol)
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		return ErrSymbolLoopsStopped
+	}
+	sl, ok := l.bySymbol[key]
+	if !ok {
+		sl = &symbolLoop{jobs: make(chan func(), workerQueueSize)}
+		l.bySymbol[key] = sl
+		l.running.Add(1)
+		go l.loop(key, sl)
+	}
+	sl.pending++
+	l.sending.Add(1)
+	l.mu.Unlock()
+
+	var err error
+	done := make(chan struct{})
+	l.inflight.start()
+	select {
+	case sl.jobs <- func() { defer l.finish(sl); err = runQueued(ctx, fn); close(done) }:
+		l.sending.Done()
+	case <-ctx.Done():
+		l.finish(sl)
+		l.sending.Done()
+		return ctx.Err()
+	}
+	<-done
+	return err
+}
+
+// finish accounts for a job that ran or was given up before it was queued
+func (l *symbolLoops) finish(sl *symbolLoop) {
+	l.mu.Lock()
+	sl.pending--
+	l.mu.Unlock()
+	l.inflight.done()
+}
+
+// loop runs the symbol's jobs until stop closes its channel, or until it has been idle for l.idle
+// with nothing pending, when it leaves the symbol to a loop started by the next job
+func (l *symbolLoops) loop(key string, sl *symbolLoop) {
+	defer l.running.Done()
+	idle := time.NewTimer(l.idle)
+	defer idle.Stop()
+	for {
+		select {
+		case job, ok := <-sl.jobs:
+			if !ok {
+				return
+			}
+			job()
+		case <-idle.C:
+			l.mu.Lock()
+			if sl.pending == 0 && !l.stopped {
+				delete(l.bySymbol, key)
+				l.mu.Unlock()
+				return
+			}
+			l.mu.Unlock()
+		}
+		idle.Reset(l.idle)
+	}
+}
+
+// count returns the number of running loops
+func (l *symbolLoops) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.bySymbol)
+}
+
+// stop refuses new work and returns once every loop ran the jobs queued on it
+func (l *symbolLoops) stop() {
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		l.running.Wait()
+		return
+	}
+	l.stopped = true
+	l.mu.Unlock()
+	l.sending.Wait()
+	// no loop leaves the map once stopped is set
+	for _, sl := range l.bySymbol {
+		close(sl.jobs)
+	}
+	l.running.Wait()
+}
+
+// StopSymbolLoops makes the engine refuse further submissions, amends and cancels with
+// ErrSymbolLoopsStopped and returns once those already queued have run. Without symbol loops it does
+// nothing.
+func (e *Engine) StopSymbolLoops() {
+	if e.loops != nil {
+		e.loops.stop()
+	}
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestSymbolLoops(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithSymbolLoops())
+
+	// a symbol stuck on its loop does not hold up another
+	release := make(chan struct{})
+	blocked := make(chan error, 1)
+	go func() { blocked <- e.onSymbolWorker(ctx, "BTC/USD", func() { <-release }) }()
+	if err := e.onSymbolWorker(ctx, "ETH/USD", func() {}); err != nil {
+		t.Fatalf("other symbol: %v", err)
+	}
+	close(release)
+	if err := <-blocked; err != nil {
+		t.Fatalf("blocked symbol: %v", err)
+	}
+
+	// concurrent sells on one symbol all rest, one at a time, and a buy fills every one
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o := &domain.Order{ClientID: "maker", Symbol: "BTC/USD", Side: domain.Sell, Type: domain.Limit,
+				Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)}
+			if _, err := e.SubmitOrder(ctx, o); err != nil {
+				t.Errorf("sell: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	trades, err := e.SubmitOrder(ctx, &domain.Order{ClientID: "taker", Symbol: "BTC/USD", Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(20)})
+	if err != nil || len(trades) != 20 {
+		t.Fatalf("buy: %d trades, %v", len(trades), err)
+	}
+
+	if _, err := e.CancelOrder(ctx, "missing", "maker"); err == nil {
+		t.Fatal("cancelled a missing order")
+	}
+	stopped := make(chan struct{})
+	go func() { e.StopSymbolLoops(); close(stopped) }()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("stop did not return")
+	}
+	_, err = e.SubmitOrder(ctx, &domain.Order{ClientID: "maker", Symbol: "BTC/USD", Side: domain.Sell, Type: domain.Limit,
+		Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)})
+	if !errors.Is(err, ErrSymbolLoopsStopped) {
+		t.Fatalf("submit after stop: %v", err)
+	}
+}
+
+func TestSymbolLoopLifecycle(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithSymbolLoops())
+	e.loops.idle = 10 * time.Millisecond
+	sell := func(symbol string) (*domain.Order, error) {
+		o := &domain.Order{ClientID: "maker", Symbol: symbol, Side: domain.Sell, Type: domain.Limit,
+			Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)}
+		_, err := e.SubmitOrder(ctx, o)
+		return o, err
+	}
+
+	// an unknown symbol is rejected without a loop of its own
+	var rej *domain.RejectError
+	if _, err := sell("no such symbol"); !errors.As(err, &rej) || rej.Code != domain.RejectUnknownSymbol {
+		t.Fatalf("made-up symbol: %v", err)
+	}
+	if n := e.loops.count(); n != 0 {
+		t.Fatalf("%d loops after a rejected symbol", n)
+	}
+
+	// an idle loop exits and the next order starts another
+	if _, err := sell("BTC/USD"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for e.loops.count() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("idle loop was not reaped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	o, err := sell("BTC/USD")
+	if err != nil {
+		t.Fatalf("submit after the loop was reaped: %v", err)
+	}
+
+	// bulk cancels wait their turn on the symbol's loop
+	release := make(chan struct{})
+	running := make(chan struct{})
+	go e.onSymbolWorker(ctx, "BTC/USD", func() { close(running); <-release })
+	<-running
+	batch := make(chan []CancelResult, 1)
+	go func() {
+		results, _ := e.BatchCancelOrders(ctx, "maker", []string{o.ID}, nil)
+		batch <- results
+	}()
+	select {
+	case <-batch:
+		t.Fatal("batch cancel ran while the symbol's loop was busy")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if results := <-batch; len(results) != 1 || !results[0].Cancelled {
+		t.Fatalf("batch cancel: %+v", results)
+	}
+	if _, err := sell("ETH/USD"); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := e.CancelAllOrders(ctx, "maker", "", ""); err != nil || len(ids) != 2 {
+		t.Fatalf("cancel all: %v, %v", ids, err)
+	}
+}
+
+func TestSymbolWorkerCallerGivesUp(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{"symbol loops", WithSymbolLoops()},
+		{"worker pool", WithWorkerPool(2)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEngine(memory.NewRepository(), nil, tc.opt)
+			defer e.StopSymbolLoops()
+
+			// a job whose caller gave up while it waited in the queue never runs
+			release := make(chan struct{})
+			running := make(chan struct{})
+			go e.onSymbolWorker(context.Background(), "BTC/USD", func() { close(running); <-release })
+			<-running
+			short, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			queued := make(chan error, 1)
+			ran := false
+			go func() { queued <- e.onSymbolWorker(short, "BTC/USD", func() { ran = true }) }()
+			<-short.Done()
+			close(release)
+			if err := <-queued; !errors.Is(err, context.DeadlineExceeded) || ran {
+				t.Fatalf("queued job: %v, ran %v; want it skipped", err, ran)
+			}
+
+			// one that started reports success even if its caller gives up meanwhile
+			ctx, cancel := context.WithCancel(context.Background())
+			if err := e.onSymbolWorker(ctx, "BTC/USD", cancel); err != nil {
+				t.Fatalf("job cancelled while it ran: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// AllSymbols subscribes to every symbol of the tenant
+const AllSymbols = "*"
+
+// SymbolSubscription follows a set of a tenant's symbols, or AllSymbols, on one channel.
+// Symbols can be added and removed while it is open.
+type SymbolSubscription[T any] struct {
+	*pubsub.Subscription[T]
+	tenant string
+}
+
+func subscribeSymbols[T any](ctx context.Context, ps *pubsub.PubSub[T], symbols []string) *SymbolSubscription[T] {
+	s := &SymbolSubscription[T]{tenant: tenant.From(ctx)}
+	topics := s.topics(symbols)
+	if len(topics) == 0 {
+		s.Subscription = ps.Subscribe(tenant.ScopeID(s.tenant, AllSymbols))
+		return s
+	}
+	s.Subscription = ps.Subscribe(topics[0])
+	s.Subscription.Add(topics[1:]...)
+	return s
+}
+
+func (s *SymbolSubscription[T]) topics(symbols []string) []string {
+	out := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		if sym != "" {
+			out = append(out, tenant.ScopeID(s.tenant, sym))
+		}
+	}
+	return out
+}
+
+func (s *SymbolSubscription[T]) AddSymbols(symbols ...string) {
+	s.Subscription.Add(s.topics(symbols)...)
+}
+
+func (s *SymbolSubscription[T]) RemoveSymbols(symbols ...string) {
+	s.Subscription.Remove(s.topics(symbols)...)
+}
+
+// Symbols returns the symbols currently followed
+func (s *SymbolSubscription[T]) Symbols() []string {
+	topics := s.Subscription.Topics()
+	out := make([]string, len(topics))
+	for i, t := range topics {
+		_, out[i] = tenant.Split(t)
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestOrderTagsAndUserData(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	events := e.SubscribeAllOrderEvents()
+	defer events.Close()
+	submit := func(id string, side domain.Side, tags ...string) error {
+		_, err := e.SubmitOrder(ctx, &domain.Order{ID: id, ClientID: "c", Symbol: "BTC/USD", Side: side, Type: domain.Limit,
+			Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1), Tags: tags, UserData: "ref:" + id})
+		return err
+	}
+	for _, tags := range [][]string{{""}, {"a b"}, {"dup", "dup"}, {strings.Repeat("x", domain.MaxOrderTagLen+1)}} {
+		if err := submit("bad", domain.Buy, tags...); domain.RejectCodeOf(err) != domain.RejectInvalidOrder {
+			t.Errorf("tags %q: %v, want an invalid order", tags, err)
+		}
+	}
+	if err := submit("ask", domain.Sell, "maker"); err != nil {
+		t.Fatalf("submit ask: %v", err)
+	}
+	if err := submit("bid", domain.Buy, "strategy-a"); err != nil {
+		t.Fatalf("submit bid: %v", err)
+	}
+	for len(events.C) > 0 {
+		ev := <-events.C
+		if ev.OrderID == "bid" && !domain.HasTag(ev.Tags, "strategy-a") || ev.OrderID == "ask" && !domain.HasTag(ev.Tags, "maker") {
+			t.Errorf("%s %s event tagged %v", ev.OrderID, ev.ExecType, ev.Tags)
+		}
+		if ev.UserData != "ref:"+ev.OrderID {
+			t.Errorf("%s %s event carries user data %q", ev.OrderID, ev.ExecType, ev.UserData)
+		}
+	}
+
+	now := time.Now()
+	st, err := e.Statement(ctx, "c", now.Add(-time.Hour), now.Add(time.Hour), "strategy-a")
+	if err != nil {
+		t.Fatalf("statement: %v", err)
+	}
+	if len(st.Fills) != 1 || st.Fills[0].OrderID != "bid" || st.Fills[0].UserData != "ref:bid" {
+		t.Errorf("fills tagged strategy-a %+v, want the bid's with its user data", st.Fills)
+	}
+	_, err = e.SubmitOrder(ctx, &domain.Order{ClientID: "c", Symbol: "BTC/USD", Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(1), Quantity: decimal.NewFromInt(1), UserData: strings.Repeat("x", domain.MaxUserDataLen+1)})
+	if domain.RejectCodeOf(err) != domain.RejectInvalidOrder {
+		t.Errorf("oversized user data: %v, want an invalid order", err)
+	}
+}
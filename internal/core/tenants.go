@@ -0,0 +1,34 @@
+package core
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// WithTenants sets per-tenant symbol sets and fee schedules. Tenants without a config trade any symbol
+// at the engine-wide fees.
+func WithTenants(cfg map[string]domain.TenantConfig) Option {
+	return func(e *Engine) { e.tenants = cfg }
+}
+
+func (e *Engine) tenantConfig(ctx context.Context) (domain.TenantConfig, bool) {
+	cfg, ok := e.tenants[tenant.From(ctx)]
+	return cfg, ok
+}
+
+func (e *Engine) checkTenantSymbol(ctx context.Context, symbol string) error {
+	if cfg, ok := e.tenantConfig(ctx); ok && !cfg.Lists(symbol) {
+		return domain.Reject(domain.RejectUnknownSymbol, "symbol %s is not listed for tenant %s", symbol, tenant.From(ctx))
+	}
+	return nil
+}
+
+// feeSchedule returns the ctx tenant's fee schedule
+func (e *Engine) feeSchedule(ctx context.Context) domain.FeeSchedule {
+	if cfg, ok := e.tenantConfig(ctx); ok && cfg.Fees != nil {
+		return *cfg.Fees
+	}
+	return e.fees
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+const (
+	tickDataPrefix = "ticks/"
+	// maxTickRange bounds the history one job covers
+	maxTickRange = 31 * 24 * time.Hour
+	// tickJobRetention is how long a finished job can be looked up; its files stay in the object store
+	tickJobRetention = 24 * time.Hour
+	// tickJobWorkers bounds the jobs generating files at once, the others wait as PENDING
+	tickJobWorkers = 2
+)
+
+var errTickJobNotFound = errors.New("tick data job not found")
+
+// tickJobs tracks each tenant's tick data jobs by ID
+type tickJobs struct {
+	mu       sync.Mutex
+	byTenant map[string]map[string]*domain.TickJob
+	workers  chan struct{}
+}
+
+func newTickJobs() *tickJobs {
+	return &tickJobs{byTenant: make(map[string]map[string]*domain.TickJob), workers: make(chan struct{}, tickJobWorkers)}
+}
+
+// update applies fn to the job under the lock
+func (j *tickJobs) update(tenantID, id string, fn func(*domain.TickJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if job, ok := j.byTenant[tenantID][id]; ok {
+		fn(job)
+	}
+}
+
+// RequestTickData starts generating the symbol's trades between from, inclusive, and to, exclusive,
+// and with bookChanges every change of its book, as gzip-compressed NDJSON files in the object store.
+// It returns the PENDING job at once; TickDataJob follows it and TickDataFile downloads its files.
+func (e *Engine) RequestTickData(ctx context.Context, symbol string, from, to time.Time, bookChanges bool) (domain.TickJob, error) {
+	switch {
+	case e.objects == nil:
+		return domain.TickJob{}, errNoObjectStore
+	case symbol == "":
+		return domain.TickJob{}, errors.New("symbol is required")
+	case from.IsZero() || to.IsZero() || !from.Before(to):
+		return domain.TickJob{}, errors.New("from must be before to")
+	case to.Sub(from) > maxTickRange:
+		return domain.TickJob{}, fmt.Errorf("range must not exceed %s", maxTickRange)
+	}
+	if err := e.checkTenantSymbol(ctx, symbol); err != nil {
+		return domain.TickJob{}, err
+	}
+	now := time.Now().UTC()
+	job := &domain.TickJob{
+		ID: uuid.NewString(), Symbol: symbol, From: from.UTC(), To: to.UTC(), BookChanges: bookChanges,
+		Status: domain.TickJobPending, Files: make(map[string]string), CreatedAt: now,
+	}
+	id := tenant.From(ctx)
+	e.tickJobs.mu.Lock()
+	jobs := e.tickJobs.byTenant[id]
+	if jobs == nil {
+		jobs = make(map[string]*domain.TickJob)
+		e.tickJobs.byTenant[id] = jobs
+	}
+	for jobID, old := range jobs {
+		if !old.FinishedAt.IsZero() && now.Sub(old.FinishedAt) > tickJobRetention {
+			delete(jobs, jobID)
+		}
+	}
+	jobs[job.ID] = job
+	out := *job
+	e.tickJobs.mu.Unlock()
+
+	// the job outlives the request that started it
+	go e.runTickJob(context.WithoutCancel(ctx), out)
+	return out, nil
+}
+
+func (e *Engine) runTickJob(ctx context.Context, job domain.TickJob) {
+	e.tickJobs.workers <- struct{}{}
+	defer func() { <-e.tickJobs.workers }()
+	id := tenant.From(ctx)
+	e.tickJobs.update(id, job.ID, func(j *domain.TickJob) { j.Status = domain.TickJobRunning })
+
+	f := domain.ExportFilter{Symbol: job.Symbol, From: job.From, To: job.To}
+	files := make(map[string]string)
+	trades, err := e.writeTickFile(ctx, job, domain.TickFileTrades, files, func(add func(any) error) error {
+		return e.repo.ScanTrades(ctx, f, func(t *domain.Trade) error {
+			return add(domain.TickTrade{Sequence: t.Sequence, At: t.Timestamp, TradeID: t.ID, Price: t.Price,
+				Quantity: t.Quantity, TakerSide: t.TakerSide})
+		})
+	})
+	var changes int
+	if err == nil && job.BookChanges {
+		changes, err = e.writeTickFile(ctx, job, domain.TickFileBook, files, func(add func(any) error) error {
+			return e.repo.ScanBookChanges(ctx, f, func(c domain.BookChange) error {
+				return add(domain.TickBookChange{Sequence: c.Sequence, At: c.At, OrderID: c.OrderID, Side: c.Side,
+					Price: c.Price, ExecType: c.ExecType, Remaining: c.Remaining})
+			})
+		})
+	}
+	e.tickJobs.update(id, job.ID, func(j *domain.TickJob) {
+		j.Status, j.Files, j.Trades, j.Changes, j.FinishedAt = domain.TickJobDone, files, trades, changes, time.Now().UTC()
+		if err != nil {
+			j.Status, j.Error = domain.TickJobFailed, err.Error()
+		}
+	})
+}
+
+// writeTickFile compresses the lines scan adds into the job's file and stores it, returning the
+// number of lines. Keys are ticks/<tenant>/<symbol>/<job id>/<file>.ndjson.gz.
+func (e *Engine) writeTickFile(ctx context.Context, job domain.TickJob, file string, files map[string]string, scan func(add func(any) error) error) (int, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	n := 0
+	err := scan(func(line any) error {
+		n++
+		return enc.Encode(line)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("read %s: %w", file, err)
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	key := path.Join(tickDataPrefix, tenant.From(ctx), job.Symbol, job.ID, file+".ndjson.gz")
+	if err := e.objects.PutObject(ctx, key, buf.Bytes()); err != nil {
+		return 0, fmt.Errorf("store %s: %w", file, err)
+	}
+	files[file] = key
+	return n, nil
+}
+
+// TickDataJob returns the ctx tenant's tick data job
+func (e *Engine) TickDataJob(ctx context.Context, id string) (domain.TickJob, error) {
+	e.tickJobs.mu.Lock()
+	defer e.tickJobs.mu.Unlock()
+	job, ok := e.tickJobs.byTenant[tenant.From(ctx)][id]
+	if !ok {
+		return domain.TickJob{}, errTickJobNotFound
+	}
+	out := *job
+	out.Files = maps.Clone(job.Files)
+	return out, nil
+}
+
+// TickDataFile returns a file of a finished tick data job, gzip-compressed NDJSON
+func (e *Engine) TickDataFile(ctx context.Context, id, file string) ([]byte, error) {
+	job, err := e.TickDataJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.TickJobDone {
+		return nil, fmt.Errorf("tick data job is %s", job.Status)
+	}
+	key, ok := job.Files[file]
+	if !ok {
+		return nil, fmt.Errorf("tick data job has no %s file", file)
+	}
+	data, err := e.objects.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("object %s not found", key)
+	}
+	return data, nil
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/shopspring/decimal"
+)
+
+// mapStore is a port.ObjectStore holding objects in a map
+type mapStore struct {
+	port.ObjectStore
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *mapStore) PutObject(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *mapStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.objects[key], nil
+}
+
+// ndjsonLines decompresses a tick data file into its lines
+func ndjsonLines[T any](t *testing.T, data []byte) []T {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gunzip: %v", err)
+	}
+	var out []T
+	sc := bufio.NewScanner(zr)
+	for sc.Scan() {
+		var line T
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func TestTickDataJob(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil, WithObjectStore(&mapStore{objects: make(map[string][]byte)}))
+	from := time.Now().Add(-time.Minute)
+	seedSell(t, e, "s1")
+	seedSell(t, e, "s2")
+	if _, err := e.SubmitOrder(ctx, buy("b1", 100, 1)); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	job, err := e.RequestTickData(ctx, "BTC/USD", from, time.Now().Add(time.Minute), true)
+	if err != nil || job.Status != domain.TickJobPending {
+		t.Fatalf("request: %+v, %v, want a pending job", job, err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != domain.TickJobDone {
+		if job.Status == domain.TickJobFailed || time.Now().After(deadline) {
+			t.Fatalf("job %+v did not finish", job)
+		}
+		time.Sleep(5 * time.Millisecond)
+		if job, err = e.TickDataJob(ctx, job.ID); err != nil {
+			t.Fatalf("job: %v", err)
+		}
+	}
+	if job.Trades != 1 || job.Changes != 5 {
+		t.Errorf("job counted %d trades and %d changes, want 1 and 5", job.Trades, job.Changes)
+	}
+
+	data, err := e.TickDataFile(ctx, job.ID, domain.TickFileTrades)
+	if err != nil {
+		t.Fatalf("download trades: %v", err)
+	}
+	trades := ndjsonLines[domain.TickTrade](t, data)
+	if len(trades) != 1 || !trades[0].Price.Equal(decimal.NewFromInt(100)) || trades[0].TakerSide != domain.Buy {
+		t.Errorf("trades %+v, want the fill at 100 taken by a buyer", trades)
+	}
+	data, err = e.TickDataFile(ctx, job.ID, domain.TickFileBook)
+	if err != nil {
+		t.Fatalf("download book: %v", err)
+	}
+	// both asks placed, then the buy placed and filled against s1, which is filled too
+	book := ndjsonLines[domain.TickBookChange](t, data)
+	if len(book) != 5 || book[0].OrderID != "s1" || book[0].ExecType != domain.ExecNew || book[0].Side != domain.Sell {
+		t.Errorf("book changes %+v, want five starting with s1 placed", book)
+	}
+
+	if _, err := e.TickDataJob(ctx, "unknown"); err == nil {
+		t.Error("unknown job found")
+	}
+	if _, err := e.RequestTickData(ctx, "BTC/USD", from, from.Add(60*24*time.Hour), false); err == nil {
+		t.Error("a 60-day range was accepted")
+	}
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+// WithTopOfBook makes the engine keep every book's best bid and ask in the store as the book changes
+// and serve quotes from it, reading the repository only for a top the store lost
+func WithTopOfBook(s port.TopOfBookStore) Option {
+	return func(e *Engine) { e.tops = s }
+}
+
+// bbo remembers the last quote published per tenant-scoped symbol, so unchanged tops are not republished
+type bbo struct {
+	mu   sync.Mutex
+	last map[string]bboKey
+}
+
+type bboKey struct {
+	bidPrice, bidQty, askPrice, askQty string
+}
+
+func keyOf(q *domain.Quote) bboKey {
+	return bboKey{
+		bidPrice: q.BidPrice.Decimal.String(), bidQty: q.BidQty.String(),
+		askPrice: q.AskPrice.Decimal.String(), askQty: q.AskQty.String(),
+	}
+}
+
+// changed records the quote as the symbol's last and reports whether it differs from the one before
+func (b *bbo) changed(scope string, q *domain.Quote) bool {
+	k := keyOf(q)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	prev, ok := b.last[scope]
+	b.last[scope] = k
+	return !ok || prev != k
+}
+
+// SubscribeQuotes subscribes to the best bid and ask of the ctx tenant's books, or all of them with
+// AllSymbols, receiving a quote whenever either changes
+func (e *Engine) SubscribeQuotes(ctx context.Context, symbols ...string) *SymbolSubscription[*domain.Quote] {
+	return subscribeSymbols(ctx, e.quotes, symbols)
+}
+
+// topChanged stores the top of the book just refreshed into snap, or read from the repository when
+// the book could not be, and publishes its quote if it moved. A top that cannot be stored is dropped,
+// so it is read from the repository until the book changes again.
+func (e *Engine) topChanged(ctx context.Context, symbol string, snap *domain.OrderbookSnapshot) {
+	var top *domain.OrderbookSnapshot
+	switch {
+	case snap != nil:
+		top = topOf(snap)
+	case e.tops != nil || e.quotes.Stats().Subscribers > 0:
+		var err error
+		if top, err = loadTop(ctx, e.repo, symbol); err != nil {
+			if e.tops != nil {
+				_ = e.tops.InvalidateTopOfBook(ctx, symbol)
+			}
+			return
+		}
+	default:
+		return
+	}
+	if e.tops != nil && e.tops.SetTopOfBook(ctx, symbol, top) != nil {
+		_ = e.tops.InvalidateTopOfBook(ctx, symbol)
+	}
+	q := buildQuote(top)
+	if e.bbo.changed(tenant.Scope(ctx, symbol), q) {
+		e.quotes.Publish(tenant.Scope(ctx, symbol), q)
+		e.quotes.Publish(tenant.Scope(ctx, AllSymbols), q)
+	}
+}
+
+// topOfBook serves the stored top of the book or, once the store lost it, reads it from the
+// repository and stores it again
+func (e *Engine) topOfBook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	if e.tops != nil {
+		if top, err := e.tops.GetTopOfBook(ctx, symbol); err == nil && top != nil {
+			return top, nil
+		}
+	}
+	top, err := loadTop(ctx, e.repo, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if e.tops != nil {
+		// a top stored meanwhile by a change to the book is newer and stays
+		_ = e.tops.SetTopOfBook(ctx, symbol, top)
+	}
+	return top, nil
+}
+
+// loadTop reads the top of the book from the repository stamped like loadStamped
+func loadTop(ctx context.Context, repo port.Repository, symbol string) (*domain.OrderbookSnapshot, error) {
+	seq := sequence.next(time.Now())
+	top, err := repo.LoadTopOfBook(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	top.Sequence = seq
+	return top, nil
+}
+
+// topOf picks the orders at the best bid and ask of the book, in time priority
+func topOf(ob *domain.OrderbookSnapshot) *domain.OrderbookSnapshot {
+	return &domain.OrderbookSnapshot{
+		Symbol:   ob.Symbol,
+		Sequence: ob.Sequence,
+		Bids:     bestLevel(ob.Bids, func(a, b decimal.Decimal) bool { return a.GreaterThan(b) }),
+		Asks:     bestLevel(ob.Asks, func(a, b decimal.Decimal) bool { return a.LessThan(b) }),
+	}
+}
+
+func bestLevel(orders []domain.Order, better func(a, b decimal.Decimal) bool) []domain.Order {
+	var level []domain.Order
+	for _, o := range orders {
+		switch {
+		case len(level) == 0 || better(o.Price, level[0].Price):
+			level = append(level[:0], o)
+		case o.Price.Equal(level[0].Price):
+			level = append(level, o)
+		}
+	}
+	sort.SliceStable(level, func(i, j int) bool {
+		if !level[i].CreatedAt.Equal(level[j].CreatedAt) {
+			return level[i].CreatedAt.Before(level[j].CreatedAt)
+		}
+		return level[i].ID < level[j].ID
+	})
+	return level
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/shopspring/decimal"
+)
+
+type mapTops struct {
+	tops map[string]*domain.OrderbookSnapshot
+}
+
+func (m *mapTops) SetTopOfBook(_ context.Context, symbol string, top *domain.OrderbookSnapshot) error {
+	if cur := m.tops[symbol]; cur == nil || cur.Sequence <= top.Sequence {
+		m.tops[symbol] = top
+	}
+	return nil
+}
+
+func (m *mapTops) GetTopOfBook(_ context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	return m.tops[symbol], nil
+}
+
+func (m *mapTops) InvalidateTopOfBook(_ context.Context, symbol string) error {
+	delete(m.tops, symbol)
+	return nil
+}
+
+// topReads counts the top-of-book queries reaching the repository
+type topReads struct {
+	port.Repository
+	n int
+}
+
+func (r *topReads) LoadTopOfBook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error) {
+	r.n++
+	return r.Repository.LoadTopOfBook(ctx, symbol)
+}
+
+func TestQuoteServedFromMaintainedTop(t *testing.T) {
+	ctx := context.Background()
+	repo := &topReads{Repository: memory.NewRepository()}
+	tops := &mapTops{tops: make(map[string]*domain.OrderbookSnapshot)}
+	e := NewEngine(repo, &mapCache{books: make(map[string]*domain.OrderbookSnapshot)}, WithTopOfBook(tops))
+	sub := e.SubscribeQuotes(ctx, "BTC/USD")
+	defer sub.Close()
+
+	seedSell(t, e, "s1")
+	better := &domain.Order{ID: "s2", ClientID: "maker", Symbol: "BTC/USD", Side: domain.Sell, Type: domain.Limit,
+		Price: decimal.NewFromInt(99), Quantity: decimal.NewFromInt(2)}
+	if _, err := e.SubmitOrder(ctx, better); err != nil {
+		t.Fatal(err)
+	}
+	q, err := e.GetQuote(ctx, "BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.AskPrice.Decimal.Equal(decimal.NewFromInt(99)) || !q.AskQty.Equal(decimal.NewFromInt(2)) || q.BidPrice.Valid {
+		t.Errorf("quote = %+v", q)
+	}
+	if repo.n != 0 {
+		t.Errorf("%d top-of-book reads reached the repository", repo.n)
+	}
+	for _, want := range []int64{100, 99} {
+		select {
+		case got := <-sub.C:
+			if !got.AskPrice.Decimal.Equal(decimal.NewFromInt(want)) {
+				t.Errorf("published ask %s, want %d", got.AskPrice.Decimal, want)
+			}
+		default:
+			t.Fatalf("no quote published for the ask at %d", want)
+		}
+	}
+
+	// a top lost from the store is read from the repository once and stored again
+	delete(tops.tops, "BTC/USD")
+	for range 2 {
+		if q, err := e.GetQuote(ctx, "BTC/USD"); err != nil || !q.AskPrice.Decimal.Equal(decimal.NewFromInt(99)) {
+			t.Fatalf("quote after the loss = %+v, %v", q, err)
+		}
+	}
+	if repo.n != 1 {
+		t.Errorf("%d top-of-book reads reached the repository after the loss, want 1", repo.n)
+	}
+}
+
+func TestQuoteSumsBestLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"from the repository", nil},
+		{"from the maintained top", []Option{WithTopOfBook(&mapTops{tops: make(map[string]*domain.OrderbookSnapshot)})}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			e := NewEngine(memory.NewRepository(), &mapCache{books: make(map[string]*domain.OrderbookSnapshot)}, tc.opts...)
+			seedSell(t, e, "s1")
+			seedSell(t, e, "s2")
+			worse := &domain.Order{ID: "s3", ClientID: "maker", Symbol: "BTC/USD", Side: domain.Sell, Type: domain.Limit,
+				Price: decimal.NewFromInt(101), Quantity: decimal.NewFromInt(5)}
+			if _, err := e.SubmitOrder(ctx, worse); err != nil {
+				t.Fatal(err)
+			}
+			q, err := e.GetQuote(ctx, "BTC/USD")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !q.AskPrice.Decimal.Equal(decimal.NewFromInt(100)) || !q.AskQty.Equal(decimal.NewFromInt(2)) {
+				t.Errorf("quote = %+v, want 2 at 100", q)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+const defaultRecentTrades = 1000
+
+// WithRecentTradesBuffer sets how many trades per symbol are kept in memory and, if maxAge > 0,
+// how long they stay there. A size of zero disables the buffer.
+func WithRecentTradesBuffer(size int, maxAge time.Duration) Option {
+	return func(e *Engine) {
+		if size <= 0 {
+			e.recent = nil
+			return
+		}
+		e.recent = newRecentTrades(size, maxAge)
+	}
+}
+
+// recentTrades keeps the last N trades per symbol in fixed-size ring buffers
+type recentTrades struct {
+	mu     sync.RWMutex
+	size   int
+	maxAge time.Duration
+	rings  map[string]*tradeRing
+}
+
+type tradeRing struct {
+	buf   []*domain.Trade
+	start int
+	n     int
+}
+
+func newRecentTrades(size int, maxAge time.Duration) *recentTrades {
+	return &recentTrades{
+		size:   size,
+		maxAge: maxAge,
+		rings:  make(map[string]*tradeRing),
+	}
+}
+
+func (r *recentTrades) add(symbol string, trades []*domain.Trade) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ring := r.rings[symbol]
+	if ring == nil {
+		ring = &tradeRing{buf: make([]*domain.Trade, r.size)}
+		r.rings[symbol] = ring
+	}
+	for _, t := range trades {
+		ring.push(t)
+	}
+}
+
+// last returns up to limit trades, newest first, skipping those older than maxAge
+func (r *recentTrades) last(symbol string, limit int) []*domain.Trade {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ring := r.rings[symbol]
+	if ring == nil {
+		return nil
+	}
+	var cutoff time.Time
+	if r.maxAge > 0 {
+		cutoff = time.Now().UTC().Add(-r.maxAge)
+	}
+	out := make([]*domain.Trade, 0, min(limit, ring.n))
+	for i := ring.n - 1; i >= 0 && len(out) < limit; i-- {
+		t := ring.at(i)
+		if !cutoff.IsZero() && t.Timestamp.Before(cutoff) {
+			break
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func (b *tradeRing) push(t *domain.Trade) {
+	if b.n < len(b.buf) {
+		b.buf[(b.start+b.n)%len(b.buf)] = t
+		b.n++
+		return
+	}
+	b.buf[b.start] = t
+	b.start = (b.start + 1) % len(b.buf)
+}
+
+// at returns the i-th oldest trade
+func (b *tradeRing) at(i int) *domain.Trade {
+	return b.buf[(b.start+i)%len(b.buf)]
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/pubsub"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// WithTradeTape makes the engine record executions on the tape and serve recent trades from it
+func WithTradeTape(t port.TradeTape) Option {
+	return func(e *Engine) { e.tape = t }
+}
+
+func (e *Engine) publishTrades(ctx context.Context, symbol string, trades []*domain.Trade) {
+	if len(trades) == 0 {
+		return
+	}
+	topic := tenant.Scope(ctx, symbol)
+	if e.recent != nil {
+		e.recent.add(topic, trades)
+	}
+	if e.candles {
+		e.recordCandles(ctx, trades)
+	}
+	var seqs []string
+	if e.tape != nil {
+		seqs, _ = e.tape.AppendTrades(ctx, symbol, trades)
+	}
+	all := tenant.Scope(ctx, AllSymbols)
+	for i, t := range trades {
+		entry := domain.TapeEntry{Trade: t}
+		if i < len(seqs) {
+			entry.Seq = seqs[i]
+		}
+		e.trades.Publish(topic, entry)
+		e.trades.Publish(all, entry)
+		entry.Tenant = tenant.From(ctx)
+		e.trades.Publish(allTenants, entry)
+	}
+}
+
+// GetRecentTrades returns the latest trades for a symbol, newest first.
+// The in-memory buffer answers when it holds enough trades, otherwise the tape is read.
+func (e *Engine) GetRecentTrades(ctx context.Context, symbol string, limit int) ([]*domain.Trade, error) {
+	var buffered []*domain.Trade
+	if e.recent != nil {
+		buffered = e.recent.last(tenant.Scope(ctx, symbol), limit)
+		if len(buffered) >= limit || e.tape == nil {
+			return buffered, nil
+		}
+	}
+	if e.tape == nil {
+		return nil, errors.New("trade tape not configured")
+	}
+	return e.tape.RecentTrades(ctx, symbol, int64(limit))
+}
+
+// tapePos is the page position in a symbol's trade tape
+type tapePos struct {
+	Seq string `json:"seq"`
+}
+
+// ListTrades returns a page of a symbol's trades, newest first. Without a trade tape only the
+// in-memory buffer is listed, on a single page.
+func (e *Engine) ListTrades(ctx context.Context, symbol string, req page.Request) (page.Page[domain.TapeEntry], error) {
+	var pos tapePos
+	ok, err := page.Decode(req.Cursor, &pos)
+	if err != nil || (ok && pos.Seq == "") {
+		return page.Page[domain.TapeEntry]{}, page.ErrInvalidCursor
+	}
+	if e.tape == nil {
+		if ok {
+			return page.Page[domain.TapeEntry]{}, errors.New("trade tape not configured")
+		}
+		trades, err := e.GetRecentTrades(ctx, symbol, req.Limit)
+		if err != nil {
+			return page.Page[domain.TapeEntry]{}, err
+		}
+		entries := make([]domain.TapeEntry, len(trades))
+		for i, t := range trades {
+			entries[i] = domain.TapeEntry{Trade: t}
+		}
+		return page.Page[domain.TapeEntry]{Items: entries}, nil
+	}
+	entries, err := e.tape.TradesBefore(ctx, symbol, pos.Seq, int64(page.Fetch(req.Limit)))
+	if err != nil {
+		return page.Page[domain.TapeEntry]{}, err
+	}
+	return page.Build(entries, req.Limit, func(en domain.TapeEntry) any { return tapePos{Seq: en.Seq} }), nil
+}
+
+// GetTrades returns a page of the symbol's stored trades executed in [from, to), oldest first; a
+// zero from or to leaves that end open
+func (e *Engine) GetTrades(ctx context.Context, symbol string, from, to time.Time, req page.Request) (page.Page[*domain.Trade], error) {
+	if symbol == "" {
+		return page.Page[*domain.Trade]{}, errors.New("symbol is required")
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return page.Page[*domain.Trade]{}, errors.New("from must be before to")
+	}
+	var after *page.Key
+	var pos page.Key
+	if ok, err := page.Decode(req.Cursor, &pos); err != nil || (ok && pos.ID == "") {
+		return page.Page[*domain.Trade]{}, page.ErrInvalidCursor
+	} else if ok {
+		after = &pos
+	}
+	trades, err := e.repo.LoadTrades(ctx, symbol, from, to, after, page.Fetch(req.Limit))
+	if err != nil {
+		return page.Page[*domain.Trade]{}, err
+	}
+	return page.Build(trades, req.Limit, func(t *domain.Trade) any { return page.Key{At: t.Timestamp, ID: t.ID} }), nil
+}
+
+// TradeBackfill returns trades after afterSeq, oldest first, so a new subscriber can catch up before going live.
+// Without afterSeq the last limit trades are replayed from the in-memory buffer when possible.
+func (e *Engine) TradeBackfill(ctx context.Context, symbol, afterSeq string, limit int) ([]domain.TapeEntry, error) {
+	if afterSeq == "" {
+		latest, err := e.GetRecentTrades(ctx, symbol, limit)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]domain.TapeEntry, len(latest))
+		for i, t := range latest {
+			out[len(latest)-1-i] = domain.TapeEntry{Trade: t}
+		}
+		return out, nil
+	}
+	if e.tape == nil {
+		return nil, errors.New("trade tape not configured")
+	}
+	return e.tape.TradesAfter(ctx, symbol, afterSeq, int64(limit))
+}
+
+// SubscribeAllTrades subscribes to the executions of every tenant, for in-process consumers. It
+// buffers generously and is never disconnected by the stream's slow-consumer policy.
+func (e *Engine) SubscribeAllTrades() *pubsub.Subscription[domain.TapeEntry] {
+	return e.trades.SubscribeWith(allTenants, pubsub.Options{Policy: pubsub.Buffer, Bound: 1 << 16})
+}
+
+// SubscribeTrades subscribes to executions of the ctx tenant's symbols, or of all of them with AllSymbols.
+// Entries carry their tape position when a trade tape is configured.
+func (e *Engine) SubscribeTrades(ctx context.Context, symbols ...string) *SymbolSubscription[domain.TapeEntry] {
+	return subscribeSymbols(ctx, e.trades, symbols)
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+func TestSubscribeAllTradesSpansTenants(t *testing.T) {
+	e := NewEngine(memory.NewRepository(), nil)
+	trades := e.SubscribeAllTrades()
+	defer trades.Close()
+
+	for _, tenantID := range []string{"acme", "globex"} {
+		ctx := tenant.With(context.Background(), tenantID)
+		for _, side := range []domain.Side{domain.Sell, domain.Buy} {
+			if _, err := e.SubmitOrder(ctx, &domain.Order{ClientID: "c-" + string(side), Symbol: "BTC/USD", Side: side,
+				Type: domain.Limit, Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)}); err != nil {
+				t.Fatalf("%s: submit %s: %v", tenantID, side, err)
+			}
+		}
+	}
+
+	var got []string
+	for len(trades.C) > 0 {
+		entry := <-trades.C
+		got = append(got, entry.Tenant)
+	}
+	if len(got) != 2 || got[0] != "acme" || got[1] != "globex" {
+		t.Fatalf("trades of tenants %v, want one of acme then one of globex", got)
+	}
+}
+
+func TestGetTradesPagesASymbolInRange(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	var trades []*domain.Trade
+	for _, symbol := range []string{"BTC/USD", "BTC/USD", "ETH/USD", "BTC/USD"} {
+		for _, side := range []domain.Side{domain.Sell, domain.Buy} {
+			executed, err := e.SubmitOrder(ctx, &domain.Order{ClientID: "c-" + string(side), Symbol: symbol, Side: side,
+				Type: domain.Limit, Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)})
+			if err != nil {
+				t.Fatalf("submit %s %s: %v", symbol, side, err)
+			}
+			trades = append(trades, executed...)
+		}
+	}
+
+	var got []string
+	req := page.Request{Limit: 1}
+	from, to := trades[1].Timestamp, trades[3].Timestamp.Add(time.Nanosecond)
+	for {
+		p, err := e.GetTrades(ctx, "BTC/USD", from, to, req)
+		if err != nil {
+			t.Fatalf("get trades: %v", err)
+		}
+		for _, tr := range p.Items {
+			got = append(got, tr.ID)
+		}
+		if p.NextCursor == "" {
+			break
+		}
+		req.Cursor = p.NextCursor
+	}
+	if len(got) != 2 || got[0] != trades[1].ID || got[1] != trades[3].ID {
+		t.Errorf("trades %v, want %s and %s", got, trades[1].ID, trades[3].ID)
+	}
+	if _, err := e.GetTrades(ctx, "", time.Time{}, time.Time{}, page.Request{}); err == nil {
+		t.Error("listed trades without a symbol")
+	}
+	if _, err := e.GetTrades(ctx, "BTC/USD", to, from, page.Request{}); err == nil {
+		t.Error("listed trades of an empty range")
+	}
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+var errNoHistory = errors.New("order not found")
+
+// recordTransitions adds committed transitions to their orders' histories. Like the journal it is
+// written after the commit, so a failed write loses history entries but never an order or a trade.
+func (e *Engine) recordTransitions(ctx context.Context, evs []*domain.OrderEvent) {
+	ts := make([]domain.OrderTransition, len(evs))
+	for i, ev := range evs {
+		ts[i] = domain.TransitionOf(ev)
+	}
+	_ = e.repo.SaveTransitions(ctx, ts)
+}
+
+// OrderHistory returns every recorded transition of the client's order, oldest first, including the
+// rejection of an order that never reached the book
+func (e *Engine) OrderHistory(ctx context.Context, orderID, clientID string) ([]domain.OrderTransition, error) {
+	ts, err := e.repo.LoadTransitions(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ts) == 0 || ts[0].ClientID != clientID {
+		return nil, errNoHistory
+	}
+	return ts, nil
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestOrderHistoryCauses(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	submit := func(id, client string, side domain.Side, qty int64) {
+		t.Helper()
+		if _, err := e.SubmitOrder(ctx, &domain.Order{ID: id, ClientID: client, Symbol: "BTC/USD", Side: side,
+			Type: domain.Limit, Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(qty)}); err != nil {
+			t.Fatalf("submit %s: %v", id, err)
+		}
+	}
+	submit("ask", "maker", domain.Sell, 1)
+	submit("bid", "taker", domain.Buy, 1)
+	submit("other", "maker", domain.Sell, 5)
+	if _, err := e.ForceCancelOrder(ctx, "other", "fat finger"); err != nil {
+		t.Fatalf("force cancel: %v", err)
+	}
+	submit("mine", "maker", domain.Sell, 5)
+	if _, err := e.CancelOrder(ctx, "mine", "maker"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	for _, tt := range []struct {
+		id, client string
+		want       []domain.TransitionCause
+	}{
+		{"ask", "maker", []domain.TransitionCause{domain.CausePlaced, domain.CauseMatched}},
+		{"bid", "taker", []domain.TransitionCause{domain.CausePlaced, domain.CauseMatched}},
+		{"other", "maker", []domain.TransitionCause{domain.CausePlaced, domain.CauseAdmin}},
+		{"mine", "maker", []domain.TransitionCause{domain.CausePlaced, domain.CauseCancelledByUser}},
+	} {
+		ts, err := e.OrderHistory(ctx, tt.id, tt.client)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.id, err)
+		}
+		var got []domain.TransitionCause
+		for _, tr := range ts {
+			got = append(got, tr.Cause)
+		}
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+			t.Errorf("%s: causes %v, want %v", tt.id, got, tt.want)
+		}
+	}
+	if _, err := e.OrderHistory(ctx, "ask", "taker"); err == nil {
+		t.Error("another client read the history")
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/shopspring/decimal"
+)
+
+// maxTriggerRounds bounds a cascade of stops whose fills trigger further stops
+const maxTriggerRounds = 16
+
+// errNotPending is the outcome of a stop cancelled between reading the trigger book and activating it
+var errNotPending = errors.New("stop order is no longer pending")
+
+// fireTriggers activates, oldest first, the symbol's pending stops the trades crossed, then those
+// crossed by the trades of the activated ones, and so on. It runs on the symbol's worker once the
+// trades are committed; a stop that fails to activate stays pending for the next trade.
+func (e *Engine) fireTriggers(ctx context.Context, symbol string, trades []*domain.Trade) {
+	for round := 0; len(trades) > 0 && round < maxTriggerRounds; round++ {
+		crossed := domain.RangeOf(trades)
+		stops, err := e.repo.LoadTriggerOrders(ctx, symbol, &crossed)
+		if err != nil {
+			return
+		}
+		trades = nil
+		for _, stop := range stops {
+			if executed, err := e.activate(ctx, stop); err == nil {
+				trades = append(trades, executed...)
+			}
+		}
+	}
+}
+
+// activate puts a triggered stop on the book, as a market order for STOP and a limit order for
+// STOP_LIMIT, and matches it like a newly submitted order: what a STOP does not fill expires
+func (e *Engine) activate(ctx context.Context, stop *domain.Order) ([]*domain.Trade, error) {
+	mid := e.arrivalMid(ctx, stop.Symbol)
+	var (
+		o        *domain.Order
+		executed []*domain.Trade
+		events   []*domain.OrderEvent
+	)
+	err := withTx(ctx, e.repo, func(tx port.Tx) error {
+		cur, err := tx.LoadOrderByIDForClient(ctx, stop.ID, stop.ClientID)
+		if err != nil {
+			return err
+		}
+		if cur.Status != domain.Pending {
+			return errNotPending
+		}
+		o = cur
+		o.Status = domain.Open
+		events = []*domain.OrderEvent{newEvent(o, domain.ExecTriggered)}
+		if err := saveOrder(ctx, tx, domain.OrderTriggered, o); err != nil {
+			return err
+		}
+		var evs []*domain.OrderEvent
+		executed, evs, err = e.matchOrder(ctx, tx, o)
+		events = append(events, evs...)
+		if err != nil {
+			return err
+		}
+		evs, err = e.closeActivated(ctx, tx, o, executed)
+		events = append(events, evs...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	e.matched(ctx, o, mid, executed, events)
+	return executed, nil
+}
+
+// closeActivated sets the activated stop's status after matching and writes it. A STOP_LIMIT rests at
+// its price like any limit order; a STOP has no price to rest at, so what the book could not fill
+// expires at once, its hold is released and the expiry event is returned.
+func (e *Engine) closeActivated(ctx context.Context, tx port.Tx, o *domain.Order, executed []*domain.Trade) ([]*domain.OrderEvent, error) {
+	updateOrderStatus(o)
+	if o.Type.HasLimit() || o.Remaining.IsZero() {
+		return nil, saveMatched(ctx, tx, o, executed)
+	}
+	o.Status, o.Remaining = domain.Cancelled, decimal.Zero
+	ev := newEvent(o, domain.ExecExpired)
+	ev.Reason = "no liquidity left for the rest of the stop order"
+	if err := saveOrder(ctx, tx, domain.OrderCancelled, o); err != nil {
+		return nil, err
+	}
+	return []*domain.OrderEvent{ev}, e.releaseFunds(ctx, tx, o)
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestStopOrderTriggersOnTrade(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepository()
+	e := NewEngine(repo, nil)
+	place := func(o *domain.Order) []*domain.Trade {
+		t.Helper()
+		o.Symbol, o.Quantity = "BTC/USD", decimal.NewFromInt(1)
+		trades, err := e.SubmitOrder(ctx, o)
+		if err != nil {
+			t.Fatalf("submit %s: %v", o.ID, err)
+		}
+		return trades
+	}
+	for _, p := range []int64{100, 94, 93} {
+		place(&domain.Order{ClientID: "maker", Side: domain.Buy, Type: domain.Limit, Price: decimal.NewFromInt(p)})
+	}
+	stop := &domain.Order{ClientID: "stopper", Side: domain.Sell, Type: domain.Stop, TriggerPrice: decimal.NewFromInt(95)}
+	if trades := place(stop); len(trades) != 0 || stop.Status != domain.Pending {
+		t.Fatalf("stop placed as %s with %d trades", stop.Status, len(trades))
+	}
+	dump, err := e.DumpOrderbook(ctx, "BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dump.Triggers) != 1 || dump.Triggers[0].Order.ID != stop.ID || len(dump.Asks) != 0 {
+		t.Fatalf("dump holds %d triggers and %d ask levels, want the stop off the book", len(dump.Triggers), len(dump.Asks))
+	}
+
+	// a trade above the trigger leaves the stop waiting
+	place(&domain.Order{ClientID: "taker", Side: domain.Sell, Type: domain.Limit, Price: decimal.NewFromInt(100)})
+	if got, _ := repo.LoadOrderByID(ctx, stop.ID); got.Status != domain.Pending {
+		t.Fatalf("stop is %s after a trade at 100", got.Status)
+	}
+	// a trade through it sends the stop to the market, where it takes the next bid
+	place(&domain.Order{ClientID: "taker", Side: domain.Sell, Type: domain.Limit, Price: decimal.NewFromInt(94)})
+	got, _ := repo.LoadOrderByID(ctx, stop.ID)
+	if got.Status != domain.Filled {
+		t.Fatalf("stop is %s after a trade at 94", got.Status)
+	}
+	trades, err := repo.LoadTradesForOrder(ctx, stop.ID, nil, 0)
+	if err != nil || len(trades) != 1 || !trades[0].Price.Equal(decimal.NewFromInt(93)) {
+		t.Errorf("stop traded %+v, %v; want one fill at 93", trades, err)
+	}
+	history, _ := repo.LoadTransitions(ctx, stop.ID)
+	if len(history) < 2 || history[1].ExecType != domain.ExecTriggered {
+		t.Errorf("stop history %+v, want TRIGGERED after NEW", history)
+	}
+}
+
+func TestTriggeredStopRemainder(t *testing.T) {
+	tests := []struct {
+		name       string
+		typ        domain.OrderType
+		status     domain.OrderStatus
+		remaining  int64
+		lastExec   domain.ExecType
+		lateTrades int
+	}{
+		// nothing rests at price 0 for a later bid to trade against
+		{"stop expires", domain.Stop, domain.Cancelled, 0, domain.ExecExpired, 0},
+		// the rest of a stop-limit rests at 50, where the later bid takes it
+		{"stop-limit rests", domain.StopLimit, domain.PartiallyFilled, 1, domain.ExecPartialFill, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := memory.NewRepository()
+			e := NewEngine(repo, nil)
+			submit := func(o *domain.Order) []*domain.Trade {
+				t.Helper()
+				o.Symbol = "BTC/USD"
+				trades, err := e.SubmitOrder(ctx, o)
+				if err != nil {
+					t.Fatalf("submit %s %s: %v", o.Side, o.Type, err)
+				}
+				return trades
+			}
+			submit(&domain.Order{ClientID: "maker", Side: domain.Buy, Type: domain.Limit, Price: decimal.NewFromInt(95), Quantity: decimal.NewFromInt(2)})
+			stop := &domain.Order{ClientID: "stopper", Side: domain.Sell, Type: tc.typ, TriggerPrice: decimal.NewFromInt(96), Quantity: decimal.NewFromInt(2)}
+			if tc.typ == domain.StopLimit {
+				stop.Price = decimal.NewFromInt(50)
+			}
+			submit(stop)
+			// the trade at 95 triggers the stop, which takes the last bid and has nothing left to sell into
+			submit(&domain.Order{ClientID: "taker", Side: domain.Sell, Type: domain.Limit, Price: decimal.NewFromInt(95), Quantity: decimal.NewFromInt(1)})
+
+			got, _ := repo.LoadOrderByID(ctx, stop.ID)
+			if got.Status != tc.status || !got.Remaining.Equal(decimal.NewFromInt(tc.remaining)) {
+				t.Fatalf("stop is %s with %s left once the book ran out, want %s with %d", got.Status, got.Remaining, tc.status, tc.remaining)
+			}
+			history, _ := repo.LoadTransitions(ctx, stop.ID)
+			if last := history[len(history)-1]; last.ExecType != tc.lastExec {
+				t.Errorf("stop history ends with %s, want %s", last.ExecType, tc.lastExec)
+			}
+			bid := &domain.Order{ClientID: "late", Side: domain.Buy, Type: domain.Limit, Price: decimal.NewFromInt(50), Quantity: decimal.NewFromInt(1)}
+			if trades := submit(bid); len(trades) != tc.lateTrades {
+				t.Errorf("bid at 50 made %d trades, want %d", len(trades), tc.lateTrades)
+			}
+		})
+	}
+}
+
+func TestStopLimitRestsAtItsPrice(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	stop := &domain.Order{ClientID: "stopper", Symbol: "BTC/USD", Side: domain.Buy, Type: domain.StopLimit,
+		Price: decimal.NewFromInt(106), TriggerPrice: decimal.NewFromInt(105), Quantity: decimal.NewFromInt(1)}
+	if _, err := e.SubmitOrder(ctx, stop); err != nil {
+		t.Fatal(err)
+	}
+	seedSell(t, e, "s1") // at 100
+	buy := &domain.Order{ClientID: "taker", Symbol: "BTC/USD", Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(105), Quantity: decimal.NewFromInt(2)}
+	if _, err := e.SubmitOrder(ctx, buy); err != nil {
+		t.Fatal(err)
+	}
+	// the trade at 100 is below the buy stop's trigger
+	if ob, _ := e.GetOrderbook(ctx, "BTC/USD"); len(ob.Bids) != 1 {
+		t.Fatalf("book has %d bids before the trigger, want the taker's rest", len(ob.Bids))
+	}
+
+	sell := &domain.Order{ClientID: "maker", Symbol: "BTC/USD", Side: domain.Sell, Type: domain.Limit,
+		Price: decimal.NewFromInt(105), Quantity: decimal.NewFromInt(1)}
+	if _, err := e.SubmitOrder(ctx, sell); err != nil {
+		t.Fatal(err)
+	}
+	ob, err := e.GetOrderbook(ctx, "BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ob.Bids) != 1 || ob.Bids[0].ID != stop.ID || !ob.Bids[0].Price.Equal(decimal.NewFromInt(106)) {
+		t.Errorf("bids after the trigger %+v, want the stop resting at 106", ob.Bids)
+	}
+}
+
+func TestStopOrderChecks(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(memory.NewRepository(), nil)
+	tests := []struct {
+		name string
+		o    domain.Order
+		code domain.RejectCode
+	}{
+		{"stop without trigger", domain.Order{Type: domain.Stop}, domain.RejectInvalidPrice},
+		{"stop limit without price", domain.Order{Type: domain.StopLimit, TriggerPrice: decimal.NewFromInt(10)}, domain.RejectInvalidPrice},
+		{"limit with trigger", domain.Order{Type: domain.Limit, Price: decimal.NewFromInt(10), TriggerPrice: decimal.NewFromInt(10)}, domain.RejectInvalidOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := tt.o
+			o.ClientID, o.Symbol, o.Side, o.Quantity = "c", "BTC/USD", domain.Buy, decimal.NewFromInt(1)
+			_, err := e.SubmitOrder(ctx, &o)
+			var rej *domain.RejectError
+			if !errors.As(err, &rej) || rej.Code != tt.code {
+				t.Errorf("got %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
@@ -2,10 +2,32 @@ package core
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"github.com/olyamironova/exchange-engine/internal/port"
 )
 
+// maxTxAttempts bounds how often withTx runs a transaction that keeps losing conflicts
+const maxTxAttempts = 5
+
+// withTx runs fn in a transaction and commits it, running it again in a new transaction when the
+// repository reports a conflict; fn must therefore set its results afresh on every run
 func withTx(ctx context.Context, repo port.Repository, fn func(port.Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, repo, fn)
+		if err == nil || !errors.Is(err, port.ErrTxConflict) || attempt == maxTxAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * 5 * time.Millisecond):
+		}
+	}
+}
+
+func runTx(ctx context.Context, repo port.Repository, fn func(port.Tx) error) error {
 	tx, err := repo.BeginTx(ctx)
 	if err != nil {
 		return err
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// checkOrder runs every pre-trade check an order must pass before it is persisted or matched
+func (e *Engine) checkOrder(ctx context.Context, o *domain.Order) error {
+	if err := validateOrder(o); err != nil {
+		return err
+	}
+	if err := e.normalize(o.Symbol, o.Side, &o.Price, &o.Quantity); err != nil {
+		return err
+	}
+	if o.Type.IsStop() {
+		qty := o.Quantity
+		if err := e.normalize(o.Symbol, o.Side, &o.TriggerPrice, &qty); err != nil {
+			return err
+		}
+	}
+	if !o.Remaining.IsZero() {
+		o.Remaining = o.Quantity
+	}
+	if err := e.checkCancelOnly(ctx, o.Symbol); err != nil {
+		return err
+	}
+	if err := e.checkSymbol(ctx, o.Symbol); err != nil {
+		return err
+	}
+	if err := e.checkListed(ctx, o.Symbol); err != nil {
+		return err
+	}
+	if err := e.checkInstrument(ctx, o.Symbol, o.Price, o.TriggerPrice, o.Quantity); err != nil {
+		return err
+	}
+	return e.checkSandboxFunds(ctx, o)
+}
+
+// maxSymbolLen bounds the length of a symbol without an instrument, far above any real pair
+const maxSymbolLen = 32
+
+// checkSymbol rejects a symbol the engine does not trade: one the tenant does not list, one without
+// an instrument when the tenant has registered instruments, and otherwise one that is not BASE/QUOTE
+// in printable ASCII. SubmitOrder runs it before an order reaches a symbol worker, so a made-up
+// symbol never starts one.
+func (e *Engine) checkSymbol(ctx context.Context, symbol string) error {
+	if err := e.checkTenantSymbol(ctx, symbol); err != nil {
+		return err
+	}
+	m, err := e.tenantInstruments(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := m[symbol]; ok {
+		return nil
+	}
+	if len(m) > 0 {
+		return domain.Reject(domain.RejectUnknownSymbol, "symbol %s is not a registered instrument", symbol)
+	}
+	if _, _, err := splitSymbol(symbol); err != nil || len(symbol) > maxSymbolLen {
+		return domain.Reject(domain.RejectUnknownSymbol, "symbol %q is not of the form BASE/QUOTE", symbol)
+	}
+	for i := 0; i < len(symbol); i++ {
+		if symbol[i] <= ' ' || symbol[i] > '~' {
+			return domain.Reject(domain.RejectUnknownSymbol, "symbol %q must be printable ASCII without spaces", symbol)
+		}
+	}
+	return nil
+}
+
+// ValidateOrder runs the same checks as SubmitOrder without persisting or matching anything,
+// including whether the client's available balance covers what the order would lock. A nil error
+// means the order would be accepted.
+func (e *Engine) ValidateOrder(ctx context.Context, o *domain.Order) error {
+	cpy := *o
+	cpy.Remaining = cpy.Quantity
+	cpy.Status = domain.Open
+	if err := e.checkOrder(ctx, &cpy); err != nil {
+		return err
+	}
+	return e.checkFunds(ctx, &cpy)
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// Discrepancy kinds reported by VerifyBook
+const (
+	DiscrepancyOverfill      = "OVERFILL"
+	DiscrepancyRemaining     = "REMAINING"
+	DiscrepancyStatus        = "STATUS"
+	DiscrepancyMissingCached = "MISSING_IN_CACHE"
+	DiscrepancyExtraCached   = "EXTRA_IN_CACHE"
+	DiscrepancyCachedQty     = "CACHE_REMAINING"
+	DiscrepancyCrossedBook   = "CROSSED_BOOK"
+)
+
+// VerifyBook rebuilds a symbol's book from persisted orders and trades and compares it with the stored
+// order state and with the book the cache serves
+func (e *Engine) VerifyBook(ctx context.Context, symbol string) (*domain.BookReport, error) {
+	fills, err := e.repo.LoadOrderFills(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	rep := &domain.BookReport{Symbol: symbol, Orders: len(fills)}
+	report := func(id, kind, expected, actual string) {
+		rep.Discrepancies = append(rep.Discrepancies, domain.Discrepancy{OrderID: id, Kind: kind, Expected: expected, Actual: actual})
+	}
+
+	resting := make(map[string]domain.Order)
+	var order []string
+	var bestBid, bestAsk *domain.Order
+	for _, f := range fills {
+		o := f.Order
+		remaining := o.Quantity.Sub(f.Filled)
+		if remaining.IsNegative() {
+			report(o.ID, DiscrepancyOverfill, o.Quantity.String(), f.Filled.String())
+			continue
+		}
+		if o.Status == domain.Cancelled {
+			// cancelling zeroes remaining, so only overfills can be detected
+			continue
+		}
+		if !o.Remaining.Equal(remaining) {
+			report(o.ID, DiscrepancyRemaining, remaining.String(), o.Remaining.String())
+		}
+		rebuilt := o
+		rebuilt.Remaining = remaining
+		updateOrderStatus(&rebuilt)
+		if o.Status != rebuilt.Status {
+			report(o.ID, DiscrepancyStatus, string(rebuilt.Status), string(o.Status))
+		}
+		if rebuilt.Status == domain.Filled {
+			continue
+		}
+		o = rebuilt
+		resting[o.ID] = o
+		order = append(order, o.ID)
+		if o.Side == domain.Buy && (bestBid == nil || o.Price.GreaterThan(bestBid.Price)) {
+			bid := o
+			bestBid = &bid
+		}
+		if o.Side == domain.Sell && (bestAsk == nil || o.Price.LessThan(bestAsk.Price)) {
+			ask := o
+			bestAsk = &ask
+		}
+	}
+	rep.Resting = len(resting)
+	if bestBid != nil && bestAsk != nil && bestBid.Type.HasLimit() && bestAsk.Type.HasLimit() &&
+		!bestBid.Price.LessThan(bestAsk.Price) {
+		report("", DiscrepancyCrossedBook, "bid < ask", bestBid.Price.String()+" >= "+bestAsk.Price.String())
+	}
+
+	if e.cache == nil {
+		return rep, nil
+	}
+	cached, err := e.cache.GetOrderbook(ctx, symbol)
+	if err != nil || cached == nil {
+		return rep, nil
+	}
+	rep.Cached = true
+	seen := make(map[string]bool, len(resting))
+	for _, side := range [][]domain.Order{cached.Bids, cached.Asks} {
+		for _, c := range side {
+			seen[c.ID] = true
+			want, ok := resting[c.ID]
+			if !ok {
+				report(c.ID, DiscrepancyExtraCached, "not resting", c.Remaining.String())
+				continue
+			}
+			if !want.Remaining.Equal(c.Remaining) {
+				report(c.ID, DiscrepancyCachedQty, want.Remaining.String(), c.Remaining.String())
+			}
+		}
+	}
+	for _, id := range order {
+		if !seen[id] {
+			report(id, DiscrepancyMissingCached, resting[id].Remaining.String(), "absent")
+		}
+	}
+	return rep, nil
+}
+
+// ListSymbols returns every symbol with persisted orders
+func (e *Engine) ListSymbols(ctx context.Context) ([]string, error) {
+	return e.repo.ListSymbols(ctx)
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+// warmup tracks the loading of the books at boot
+type warmup struct {
+	mu     sync.Mutex
+	status domain.WarmupStatus
+}
+
+// WithWarmStart keeps the engine not ready until WarmUp has loaded the books, so gateways can hold
+// traffic back from a restarted instance whose cache does not agree with the repository yet
+func WithWarmStart() Option {
+	return func(e *Engine) { e.warm.status.Ready = false }
+}
+
+// WarmupStatus returns the progress of WarmUp
+func (e *Engine) WarmupStatus() domain.WarmupStatus {
+	e.warm.mu.Lock()
+	defer e.warm.mu.Unlock()
+	st := e.warm.status
+	st.Failed = append([]string(nil), st.Failed...)
+	return st
+}
+
+func (e *Engine) warmupUpdate(fn func(*domain.WarmupStatus)) {
+	e.warm.mu.Lock()
+	fn(&e.warm.status)
+	e.warm.mu.Unlock()
+}
+
+// WarmUp loads the book of every symbol with orders, in the default and every configured tenant,
+// from the repository into the cache, replacing whatever the cache held from before, and then marks
+// the engine ready. A book that fails to load is dropped from the cache and read from the repository
+// instead; listing a tenant's symbols failing stops the warm-up with the engine not ready.
+func (e *Engine) WarmUp(ctx context.Context) error {
+	e.warmupUpdate(func(st *domain.WarmupStatus) { st.StartedAt = time.Now() })
+	if e.cache != nil {
+		symbols := make(map[string][]string)
+		for _, id := range e.tenantIDs() {
+			list, err := e.repo.ListSymbols(tenant.With(ctx, id))
+			if err != nil {
+				return err
+			}
+			symbols[id] = list
+			e.warmupUpdate(func(st *domain.WarmupStatus) { st.Symbols += len(list) })
+		}
+		for _, id := range e.tenantIDs() {
+			tctx := tenant.With(ctx, id)
+			for _, symbol := range symbols[id] {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				snap := updateCache(tctx, e.repo, e.cache, symbol)
+				if snap != nil {
+					e.bookCached(tctx, symbol)
+					e.topChanged(tctx, symbol, snap)
+				}
+				e.warmupUpdate(func(st *domain.WarmupStatus) {
+					st.Loaded++
+					if snap == nil {
+						st.Failed = append(st.Failed, tenant.Scope(tctx, symbol))
+						return
+					}
+					st.Orders += len(snap.Bids) + len(snap.Asks)
+				})
+			}
+		}
+	}
+	e.warmupUpdate(func(st *domain.WarmupStatus) { st.Ready, st.FinishedAt = true, time.Now() })
+	return nil
+}
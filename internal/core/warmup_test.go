@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olyamironova/exchange-engine/internal/adapter/memory"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+func TestWarmUpLoadsBooksBeforeReady(t *testing.T) {
+	repo := memory.NewRepository()
+	before := NewEngine(repo, &mapCache{books: make(map[string]*domain.OrderbookSnapshot)})
+	seedSell(t, before, "s1")
+	seedSell(t, before, "s2")
+
+	// a restart: the cache starts empty
+	cache := &mapCache{books: make(map[string]*domain.OrderbookSnapshot)}
+	e := NewEngine(repo, cache, WithWarmStart())
+	if e.WarmupStatus().Ready {
+		t.Fatal("engine started warm is ready before WarmUp")
+	}
+	if err := e.WarmUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	st := e.WarmupStatus()
+	if !st.Ready || st.Symbols != 1 || st.Loaded != 1 || st.Orders != 2 || len(st.Failed) != 0 || st.FinishedAt.IsZero() {
+		t.Errorf("status after WarmUp = %+v", st)
+	}
+	if ob := cache.books["BTC/USD"]; ob == nil || len(ob.Asks) != 2 {
+		t.Errorf("cached book after WarmUp = %+v", ob)
+	}
+	if !NewEngine(repo, cache).WarmupStatus().Ready {
+		t.Error("engine not started warm is not ready")
+	}
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/shard"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+)
+
+const workerQueueSize = 1024
+
+var errNoWorkerPool = errors.New("worker pool not configured")
+
+// WithWorkerPool runs order submission on n workers, each symbol always on the same one as chosen
+// by a consistent-hash ring, so a symbol's orders are processed one at a time and a hot symbol
+// only slows its own worker. Hooks are told which symbols moved when the assignment changes.
+func WithWorkerPool(n int, hooks ...shard.RebalanceHook) Option {
+	return func(e *Engine) {
+		p := &workerPool{
+			ring:     shard.NewRing(n, 0),
+			queues:   make([]chan func(), n),
+			ops:      make(map[string]uint64),
+			inflight: e.ops,
+		}
+		for _, h := range hooks {
+			p.ring.OnRebalance(h)
+		}
+		for i := range p.queues {
+			p.queues[i] = make(chan func(), workerQueueSize)
+			go p.work(p.queues[i])
+		}
+		e.pool = p
+	}
+}
+
+type workerPool struct {
+	ring     *shard.Ring
+	queues   []chan func()
+	inflight *orderOps
+
+	mu  sync.Mutex
+	ops map[string]uint64
+}
+
+func (p *workerPool) work(jobs <-chan func()) {
+	for job := range jobs {
+		job()
+	}
+}
+
+// run executes fn on the symbol's worker and waits for it. Once queued the job is waited for even if
+// ctx ends, so the caller never hears of a failure for an operation that went through; a job whose
+// ctx ended while it was queued skips fn and returns ctx's error. Jobs queued before a rebalance
+// finish on the old worker, so for a moment a moved symbol may run on two workers; the repository
+// transaction still keeps its book consistent.
+func (p *workerPool) run(ctx context.Context, symbol string, fn func()) error {
+	key := tenant.Scope(ctx, symbol)
+	w := p.ring.Lookup(key)
+	p.mu.Lock()
+	p.ops[key]++
+	p.mu.Unlock()
+
+	var err error
+	done := make(chan struct{})
+	p.inflight.start()
+	select {
+	case p.queues[w] <- func() { defer p.inflight.done(); err = runQueued(ctx, fn); close(done) }:
+	case <-ctx.Done():
+		p.inflight.done()
+		return ctx.Err()
+	}
+	<-done
+	return err
+}
+
+// runQueued runs a job taken off a queue unless its caller gave up while it waited there
+func runQueued(ctx context.Context, fn func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	fn()
+	return nil
+}
+
+// sharded reports whether symbols run on workers of their own rather than on the caller's goroutine
+func (e *Engine) sharded() bool { return e.pool != nil || e.loops != nil }
+
+// onSymbolWorker runs fn on the symbol's loop or worker when the engine is sharded, inline otherwise
+func (e *Engine) onSymbolWorker(ctx context.Context, symbol string, fn func()) error {
+	switch {
+	case e.loops != nil:
+		return e.loops.run(ctx, symbol, fn)
+	case e.pool != nil:
+		return e.pool.run(ctx, symbol, fn)
+	}
+	e.ops.start()
+	defer e.ops.done()
+	fn()
+	return nil
+}
+
+// WorkerAssignments reports every worker with its tenant-scoped symbols, busiest first, and queue depth
+func (e *Engine) WorkerAssignments() ([]domain.WorkerAssignment, error) {
+	if e.pool == nil {
+		return nil, errNoWorkerPool
+	}
+	bySymbol, dedicated := e.pool.ring.Assignment()
+	e.pool.mu.Lock()
+	defer e.pool.mu.Unlock()
+	out := make([]domain.WorkerAssignment, len(e.pool.queues))
+	for w := range out {
+		_, ded := dedicated[w]
+		a := domain.WorkerAssignment{Worker: w, Dedicated: ded, Queued: len(e.pool.queues[w])}
+		for _, s := range bySymbol[w] {
+			a.Symbols = append(a.Symbols, domain.SymbolLoad{Symbol: s, Ops: e.pool.ops[s]})
+		}
+		sort.SliceStable(a.Symbols, func(i, j int) bool { return a.Symbols[i].Ops > a.Symbols[j].Ops })
+		out[w] = a
+	}
+	return out, nil
+}
+
+// IsolateSymbol dedicates a worker to the ctx tenant's hot symbol and returns it
+func (e *Engine) IsolateSymbol(ctx context.Context, symbol string) (int, error) {
+	if e.pool == nil {
+		return 0, errNoWorkerPool
+	}
+	return e.pool.ring.Isolate(tenant.Scope(ctx, symbol))
+}
+
+// ReleaseSymbol returns an isolated symbol to the shared workers
+func (e *Engine) ReleaseSymbol(ctx context.Context, symbol string) error {
+	if e.pool == nil {
+		return errNoWorkerPool
+	}
+	return e.pool.ring.Release(tenant.Scope(ctx, symbol))
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInsufficientFunds is returned by balance changes that would take an account below zero
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Account is a client's balance of one asset: Available funds new orders and withdrawals, Locked is
+// held by the client's open orders
+type Account struct {
+	ClientID  string
+	Asset     string
+	Available decimal.Decimal
+	Locked    decimal.Decimal
+	UpdatedAt time.Time
+}
+
+// Total is the balance held in the asset, available or locked
+func (a Account) Total() decimal.Decimal { return a.Available.Add(a.Locked) }
+
+// Hold is the part of a client's locked balance one order holds while it is pending or on the book.
+// Fills draw on it and whatever is left returns to the available balance when the order ends.
+type Hold struct {
+	OrderID  string
+	ClientID string
+	Asset    string
+	Amount   decimal.Decimal
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BookDump is a symbol's full order-by-order (L3) book as stored, for operators inspecting it
+type BookDump struct {
+	Symbol string
+	At     time.Time
+	Bids   []DumpLevel // best first
+	Asks   []DumpLevel // best first
+	// Triggers are the stop orders waiting off the book for their trigger price, oldest first; none of
+	// their quantity is displayed
+	Triggers []DumpOrder
+}
+
+// DumpLevel is one price of a side with its orders in queue order. Display is the quantity shown in
+// market data and Hidden the rest of the remaining quantity.
+type DumpLevel struct {
+	Price     decimal.Decimal
+	Remaining decimal.Decimal
+	Display   decimal.Decimal
+	Hidden    decimal.Decimal
+	Orders    []DumpOrder
+}
+
+// DumpOrder is a resting order and its place in its level's queue (Position is 1-based)
+type DumpOrder struct {
+	Order    Order
+	Position int
+	Ahead    decimal.Decimal
+	Display  decimal.Decimal
+	Hidden   decimal.Decimal
+}
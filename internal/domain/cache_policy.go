@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// CachePolicy is how long a symbol's cached book lives. A zero TTL keeps the cache's default.
+// RefreshAhead, for hot symbols, reloads the cached book that long before it would expire, so readers
+// do not hit the miss; zero lets it expire.
+type CachePolicy struct {
+	TTL          time.Duration
+	RefreshAhead time.Duration
+}
+
+// RefreshEvery is how often a book under the policy is reloaded, 0 if it is not refreshed ahead
+func (p CachePolicy) RefreshEvery() time.Duration {
+	if p.RefreshAhead <= 0 || p.RefreshAhead >= p.TTL {
+		return 0
+	}
+	return p.TTL - p.RefreshAhead
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CandleInterval is the width of a candle
+type CandleInterval string
+
+const (
+	Candle1m CandleInterval = "1m"
+	Candle5m CandleInterval = "5m"
+	Candle1h CandleInterval = "1h"
+	Candle1d CandleInterval = "1d"
+)
+
+// CandleIntervals are the intervals candles are kept at
+var CandleIntervals = []CandleInterval{Candle1m, Candle5m, Candle1h, Candle1d}
+
+// Duration is the width of the interval, 0 if it is not one of CandleIntervals
+func (i CandleInterval) Duration() time.Duration {
+	switch i {
+	case Candle1m:
+		return time.Minute
+	case Candle5m:
+		return 5 * time.Minute
+	case Candle1h:
+		return time.Hour
+	case Candle1d:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// Candle is the OHLCV bar of a symbol's trades executed in [Start, Start + interval). Volume is the
+// base quantity traded and Notional the sum of price times quantity.
+type Candle struct {
+	Symbol   string
+	Interval CandleInterval
+	Start    time.Time
+	Open     decimal.Decimal
+	High     decimal.Decimal
+	Low      decimal.Decimal
+	Close    decimal.Decimal
+	Volume   decimal.Decimal
+	Notional decimal.Decimal
+	Trades   int
+}
+
+// Merge folds later, a bar of the same interval built from trades executed after c's, into c
+func (c *Candle) Merge(later Candle) {
+	if c.Trades == 0 {
+		*c = later
+		return
+	}
+	c.High = decimal.Max(c.High, later.High)
+	c.Low = decimal.Min(c.Low, later.Low)
+	c.Close = later.Close
+	c.Volume = c.Volume.Add(later.Volume)
+	c.Notional = c.Notional.Add(later.Notional)
+	c.Trades += later.Trades
+}
+
+// CandlesOf builds the bars of every interval from trades in execution order, one per interval and
+// start the trades fall in
+func CandlesOf(trades []*Trade) []Candle {
+	var out []Candle
+	index := make(map[candleSlot]int)
+	for _, t := range trades {
+		for _, iv := range CandleIntervals {
+			bar := Candle{
+				Symbol: t.Symbol, Interval: iv, Start: t.Timestamp.UTC().Truncate(iv.Duration()),
+				Open: t.Price, High: t.Price, Low: t.Price, Close: t.Price,
+				Volume: t.Quantity, Notional: t.Price.Mul(t.Quantity), Trades: 1,
+			}
+			slot := candleSlot{symbol: bar.Symbol, interval: iv, start: bar.Start}
+			if i, ok := index[slot]; ok {
+				out[i].Merge(bar)
+				continue
+			}
+			index[slot] = len(out)
+			out = append(out, bar)
+		}
+	}
+	return out
+}
+
+type candleSlot struct {
+	symbol   string
+	interval CandleInterval
+	start    time.Time
+}
@@ -0,0 +1,18 @@
+package domain
+
+// MaxClientOrderIDLen is the longest ClientOrderID in bytes, that of a FIX ClOrdID in common use
+const MaxClientOrderIDLen = 64
+
+// ValidateClientOrderID rejects a client order ID too long or with characters other than printable
+// ASCII, which the APIs could not carry in a path or a FIX field; empty is no ID
+func ValidateClientOrderID(id string) error {
+	if len(id) > MaxClientOrderIDLen {
+		return Reject(RejectInvalidOrder, "client_order_id must not exceed %d bytes", MaxClientOrderIDLen)
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return Reject(RejectInvalidOrder, "client_order_id must be printable ASCII without spaces")
+		}
+	}
+	return nil
+}
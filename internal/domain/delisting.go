@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// DelistPhase is where a symbol stands in its delisting
+type DelistPhase string
+
+const (
+	// DelistAnnounced trades normally until HaltAt
+	DelistAnnounced DelistPhase = "ANNOUNCED"
+	// DelistHalted rejects new orders; resting orders can still be cancelled until DelistAt
+	DelistHalted DelistPhase = "HALTED"
+	// Delisted has had its resting orders cancelled and is inactive
+	Delisted DelistPhase = "DELISTED"
+)
+
+// Delisting schedules the removal of a symbol: new orders stop at HaltAt and the orders still resting
+// are cancelled at DelistAt. DelistedAt is set once that has been carried out.
+type Delisting struct {
+	Symbol      string
+	Reason      string
+	AnnouncedAt time.Time
+	HaltAt      time.Time
+	DelistAt    time.Time
+	DelistedAt  *time.Time
+}
+
+// Phase returns the delisting's phase at now
+func (d Delisting) Phase(now time.Time) DelistPhase {
+	switch {
+	case d.DelistedAt != nil:
+		return Delisted
+	case !now.Before(d.HaltAt):
+		return DelistHalted
+	default:
+		return DelistAnnounced
+	}
+}
+
+// Due reports whether the resting orders should be cancelled at now
+func (d Delisting) Due(now time.Time) bool {
+	return d.DelistedAt == nil && !now.Before(d.DelistAt)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DepthLevel is one aggregated price level: the total remaining quantity of the orders it covers
+type DepthLevel struct {
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
+	Orders   int
+}
+
+// Depth is a book aggregated by price level, best first, without orders or clients. With a step,
+// bids are bucketed down and asks up to multiples of it, so a level never looks better than the
+// orders behind it.
+type Depth struct {
+	Symbol    string
+	Step      decimal.Decimal // zero when levels are exact prices
+	Bids      []DepthLevel
+	Asks      []DepthLevel
+	Timestamp time.Time
+	Sequence  uint64
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// ExecType is the kind of transition an OrderEvent reports
+type ExecType string
+
+const (
+	ExecNew         ExecType = "NEW"
+	ExecPartialFill ExecType = "PARTIAL_FILL"
+	ExecFill        ExecType = "FILL"
+	ExecCanceled    ExecType = "CANCELED"
+	ExecReplaced    ExecType = "REPLACED"
+	ExecRejected    ExecType = "REJECTED"
+	ExecExpired     ExecType = "EXPIRED"
+	ExecTriggered   ExecType = "TRIGGERED"
+)
+
+// OrderEvent is emitted by the engine for every order state transition.
+// LastPrice/LastQty/TradeID are set for fills, Reason for rejections and cancels, RejectCode for rejections.
+type OrderEvent struct {
+	Tenant     string
+	OrderID    string
+	ClientID   string
+	Symbol     string
+	Side       Side
+	Type       OrderType
+	ExecType   ExecType
+	Status     OrderStatus
+	Price      decimal.Decimal
+	Quantity   decimal.Decimal
+	Remaining  decimal.Decimal
+	LastPrice  decimal.Decimal
+	LastQty    decimal.Decimal
+	TradeID    string
+	Reason     string
+	RejectCode RejectCode
+	Tags       []string
+	UserData   string
+	GroupID    string
+	// Cause classifies the transition for the order's history; Reason is the free-text detail
+	Cause     TransitionCause
+	Timestamp time.Time
+	// Sequence is the engine-assigned number of the transition, increasing across every event and trade
+	// of the process
+	Sequence uint64
+	// Seq is the event's position in the event journal, empty when the engine has none
+	Seq string
+}
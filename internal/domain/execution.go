@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ExecutionStats measures the execution quality of one client's or one symbol's orders. Slippage and
+// effective spread are in basis points of the mid price when the order arrived; positive slippage
+// is a fill worse than that mid.
+type ExecutionStats struct {
+	ClientID string
+	Symbol   string
+	// Orders were accepted, Rejected were not; Filled and Cancelled are accepted orders that ended so
+	Orders    int64
+	Rejected  int64
+	Filled    int64
+	Cancelled int64
+	// FillRate is FilledQuantity over Quantity, the quantity of accepted orders
+	Quantity       decimal.Decimal
+	FilledQuantity decimal.Decimal
+	FillRate       decimal.Decimal
+	// SlippageBps averages every fill weighted by its quantity, EffectiveSpreadBps twice the distance
+	// from the mid of the fills an order took on arrival
+	SlippageBps        decimal.Decimal
+	EffectiveSpreadBps decimal.Decimal
+	// TimeToFill counts completely filled orders by the time from arrival to their last fill
+	TimeToFill []DurationBucket
+}
+
+// DurationBucket counts the durations up to UpTo and above the previous bucket's; the last bucket,
+// with UpTo 0, counts the rest
+type DurationBucket struct {
+	UpTo  time.Duration
+	Count int64
+}
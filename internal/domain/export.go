@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// ExportFilter narrows a bulk export; zero fields do not filter. From is inclusive, To exclusive.
+type ExportFilter struct {
+	Symbol string
+	From   time.Time
+	To     time.Time
+	// Tag keeps orders carrying it and trades with such an order on either side
+	Tag string
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// FeeSchedule holds maker/taker rates as fractions of trade notional (0.001 = 10 bps).
+// A negative maker rate is a rebate.
+type FeeSchedule struct {
+	MakerRate decimal.Decimal
+	TakerRate decimal.Decimal
+}
+
+func (f FeeSchedule) MakerFee(price, qty decimal.Decimal) decimal.Decimal {
+	return price.Mul(qty).Mul(f.MakerRate)
+}
+
+func (f FeeSchedule) TakerFee(price, qty decimal.Decimal) decimal.Decimal {
+	return price.Mul(qty).Mul(f.TakerRate)
+}
@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Imbalance is (bid - ask) / (bid + ask) volume over the top Levels price levels, in [-1, 1]
+type Imbalance struct {
+	Symbol    string
+	Levels    int
+	BidVolume decimal.Decimal
+	AskVolume decimal.Decimal
+	Imbalance decimal.Decimal
+	Timestamp time.Time
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// InstrumentStatus is whether an instrument takes new orders
+type InstrumentStatus string
+
+const (
+	InstrumentTrading InstrumentStatus = "TRADING"
+	// InstrumentHalted rejects new orders and amends; resting orders can still be cancelled
+	InstrumentHalted InstrumentStatus = "HALTED"
+)
+
+// Instrument is a symbol registered for trading with the granularity its orders must respect. Zero
+// TickSize, LotSize and MinNotional do not constrain.
+type Instrument struct {
+	Symbol     string
+	BaseAsset  string
+	QuoteAsset string
+	// TickSize is the step of prices: every limit and trigger price is a multiple of it
+	TickSize decimal.Decimal
+	// LotSize is the step of quantities: every quantity is a multiple of it
+	LotSize decimal.Decimal
+	// MinNotional is the smallest price times quantity of a priced order
+	MinNotional decimal.Decimal
+	Status      InstrumentStatus
+	UpdatedAt   time.Time
+}
+
+// Validate checks the instrument's definition itself
+func (in Instrument) Validate() error {
+	switch {
+	case in.Symbol == "":
+		return errors.New("symbol is required")
+	case in.BaseAsset == "" || in.QuoteAsset == "":
+		return errors.New("base_asset and quote_asset are required")
+	case in.TickSize.IsNegative() || in.LotSize.IsNegative() || in.MinNotional.IsNegative():
+		return errors.New("tick_size, lot_size and min_notional must not be negative")
+	}
+	switch in.Status {
+	case InstrumentTrading, InstrumentHalted:
+		return nil
+	}
+	return fmt.Errorf("status must be %s or %s", InstrumentTrading, InstrumentHalted)
+}
+
+// CheckPrice rejects a price off the tick grid; a zero price, as of a market order, has no grid
+func (in Instrument) CheckPrice(price decimal.Decimal) error {
+	if price.IsZero() || in.TickSize.IsZero() || price.Mod(in.TickSize).IsZero() {
+		return nil
+	}
+	return Reject(RejectInvalidTick, "price %s is not a multiple of the tick size %s of %s", price, in.TickSize, in.Symbol)
+}
+
+// CheckOrder rejects a price and quantity the instrument does not accept
+func (in Instrument) CheckOrder(price, quantity decimal.Decimal) error {
+	if in.Status != InstrumentTrading {
+		return Reject(RejectSymbolHalted, "symbol %s is %s", in.Symbol, in.Status)
+	}
+	if err := in.CheckPrice(price); err != nil {
+		return err
+	}
+	if !in.LotSize.IsZero() && !quantity.Mod(in.LotSize).IsZero() {
+		return Reject(RejectInvalidQuantity, "quantity %s is not a multiple of the lot size %s of %s", quantity, in.LotSize, in.Symbol)
+	}
+	if !price.IsZero() && price.Mul(quantity).LessThan(in.MinNotional) {
+		return Reject(RejectMinNotional, "notional %s is below the minimum %s of %s", price.Mul(quantity), in.MinNotional, in.Symbol)
+	}
+	return nil
+}
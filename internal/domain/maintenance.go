@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// CancelOnly puts a symbol, or the whole engine when Symbol is empty, in cancel-only mode:
+// submissions and modifications are rejected while cancels and market data keep working
+type CancelOnly struct {
+	Symbol string
+	Reason string
+	Since  time.Time
+}
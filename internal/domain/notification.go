@@ -0,0 +1,30 @@
+package domain
+
+type NotificationChannel string
+
+const (
+	ChannelWebhook   NotificationChannel = "webhook"
+	ChannelWebSocket NotificationChannel = "websocket"
+	ChannelLog       NotificationChannel = "log"
+)
+
+// NotificationPreference says where a client's order events are delivered.
+// An empty ExecTypes list subscribes to every event type.
+type NotificationPreference struct {
+	ClientID  string
+	Channel   NotificationChannel
+	Target    string
+	ExecTypes []ExecType
+}
+
+func (p NotificationPreference) Wants(et ExecType) bool {
+	if len(p.ExecTypes) == 0 {
+		return true
+	}
+	for _, t := range p.ExecTypes {
+		if t == et {
+			return true
+		}
+	}
+	return false
+}
@@ -14,16 +14,27 @@ const (
 	Sell            Side        = "SELL"
 	Limit           OrderType   = "LIMIT"
 	Market          OrderType   = "MARKET"
+	Stop            OrderType   = "STOP"       // a market order once triggered
+	StopLimit       OrderType   = "STOP_LIMIT" // a limit order once triggered
+	Pending         OrderStatus = "PENDING"    // a stop order waiting off the book for its trigger price
 	Open            OrderStatus = "OPEN"
 	Filled          OrderStatus = "FILLED"
 	Cancelled       OrderStatus = "CANCELLED"
-	PartiallyFilled OrderStatus = "PARTIALLY FILLED"
+	PartiallyFilled OrderStatus = "PARTIALLY_FILLED"
 )
 
+// Resting reports whether an order of the status is on the book, untouched or partially filled
+func (s OrderStatus) Resting() bool {
+	return s == Open || s == PartiallyFilled
+}
+
 type Order struct {
 	ID             string
 	ClientID       string
 	ClientOrderID  string
+	SessionID      string   // trading session that placed the order, empty if none
+	Tags           []string // client labels such as a strategy name, echoed on the order's events
+	UserData       string   // opaque client metadata, stored and echoed verbatim
 	Symbol         string
 	Side           Side
 	Type           OrderType
@@ -34,9 +45,42 @@ type Order struct {
 	Status         OrderStatus
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+
+	// TriggerPrice activates a stop order: a buy stop once a trade executes at or above it, a sell
+	// stop at or below it; zero for other types
+	TriggerPrice decimal.Decimal
+
+	// GroupID is the basket the order was submitted in, empty if none; with CancelGroupOnFill a
+	// complete fill of the order cancels the rest of the group
+	GroupID           string
+	CancelGroupOnFill bool
+}
+
+// BookKey is an order's place in price-time priority, used to resume a scan of one side of the book
+type BookKey struct {
+	Price     decimal.Decimal
+	CreatedAt time.Time
+	ID        string
+}
+
+// Key returns the order's place in price-time priority
+func (o *Order) Key() BookKey {
+	return BookKey{Price: o.Price, CreatedAt: o.CreatedAt, ID: o.ID}
 }
 
 func (o *Order) PartiallyFilled() bool {
 	return o.FilledQuantity.GreaterThan(decimal.Zero) &&
 		o.FilledQuantity.LessThan(o.Quantity)
 }
+
+// OrderFilter narrows a listing of orders; zero fields do not filter
+type OrderFilter struct {
+	ClientID string
+	Symbol   string
+	Status   OrderStatus
+}
+
+func (f OrderFilter) Match(o *Order) bool {
+	return (f.ClientID == "" || o.ClientID == f.ClientID) && (f.Symbol == "" || o.Symbol == f.Symbol) &&
+		(f.Status == "" || o.Status == f.Status)
+}
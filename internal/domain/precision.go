@@ -0,0 +1,18 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// Precision is how many decimal places a symbol's prices and quantities carry. Incoming values with
+// more places are rejected, or rounded with Round: limit prices toward the passive side (buys down,
+// sells up) so the order never trades worse than asked, and quantities down.
+type Precision struct {
+	PriceDecimals    int32
+	QuantityDecimals int32
+	Round            bool
+}
+
+// FormatPrice prints a price of the symbol with exactly its decimal places
+func (p Precision) FormatPrice(d decimal.Decimal) string { return d.StringFixed(p.PriceDecimals) }
+
+// FormatQuantity prints a quantity of the symbol with exactly its decimal places
+func (p Precision) FormatQuantity(d decimal.Decimal) string { return d.StringFixed(p.QuantityDecimals) }
@@ -0,0 +1,24 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+type PreviewFill struct {
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
+}
+
+// MatchPreview is the expected execution of an order against the current book.
+// Slippage is the relative distance of AvgPrice from BestPrice, positive when worse.
+type MatchPreview struct {
+	Symbol    string
+	Side      Side
+	Fills     []PreviewFill
+	Filled    decimal.Decimal
+	Remaining decimal.Decimal
+	Notional  decimal.Decimal
+	AvgPrice  decimal.NullDecimal
+	BestPrice decimal.NullDecimal
+	Slippage  decimal.NullDecimal
+	// EstimatedFee is the taker fee on Notional
+	EstimatedFee decimal.Decimal
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// QueuePosition describes where a resting order sits in its price level (Position is 1-based)
+type QueuePosition struct {
+	OrderID        string
+	Symbol         string
+	Side           Side
+	Price          decimal.Decimal
+	Position       int
+	OrdersAhead    int
+	QuantityAhead  decimal.Decimal
+	LevelOrders    int
+	LevelQuantity  decimal.Decimal
+	OrderRemaining decimal.Decimal
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Quote is the best bid/ask view of a symbol. Prices are null when the side is empty.
+type Quote struct {
+	Symbol    string
+	BidPrice  decimal.NullDecimal
+	BidQty    decimal.Decimal
+	AskPrice  decimal.NullDecimal
+	AskQty    decimal.Decimal
+	Spread    decimal.NullDecimal
+	Mid       decimal.NullDecimal
+	Timestamp time.Time
+}
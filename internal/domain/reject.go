@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RejectCode is the machine-readable reason an order was refused before reaching the book
+type RejectCode string
+
+const (
+	RejectInvalidOrder           RejectCode = "INVALID_ORDER" // malformed side, type or identifiers
+	RejectInvalidPrice           RejectCode = "INVALID_PRICE"
+	RejectInvalidQuantity        RejectCode = "INVALID_QUANTITY"
+	RejectInvalidTick            RejectCode = "INVALID_TICK"
+	RejectMinNotional            RejectCode = "BELOW_MIN_NOTIONAL"
+	RejectInsufficientFunds      RejectCode = "INSUFFICIENT_FUNDS"
+	RejectUnknownSymbol          RejectCode = "UNKNOWN_SYMBOL"
+	RejectSymbolHalted           RejectCode = "SYMBOL_HALTED"
+	RejectSymbolDelisted         RejectCode = "SYMBOL_DELISTED"
+	RejectCancelOnly             RejectCode = "CANCEL_ONLY"
+	RejectDuplicateClientOrderID RejectCode = "DUPLICATE_CLIENT_ORDER_ID"
+	RejectOther                  RejectCode = "OTHER" // anything not classified, e.g. a storage failure
+)
+
+// RejectError is a rejection carrying its code; the message is for people, the code for programs
+type RejectError struct {
+	Code    RejectCode
+	Message string
+}
+
+func (e *RejectError) Error() string { return e.Message }
+
+// Reject returns a RejectError with a formatted message
+func Reject(code RejectCode, format string, args ...any) error {
+	return &RejectError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// RejectCodeOf returns the code of the rejection in err's chain, RejectOther if there is none
+func RejectCodeOf(err error) RejectCode {
+	var re *RejectError
+	if errors.As(err, &re) {
+		return re.Code
+	}
+	return RejectOther
+}
+
+// IsReject reports whether err's chain holds a rejection, as opposed to a failure of the engine
+func IsReject(err error) bool {
+	var re *RejectError
+	return errors.As(err, &re)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DailyReport summarizes one UTC day of a tenant's market
+type DailyReport struct {
+	Day         time.Time
+	GeneratedAt time.Time
+	Symbols     []SymbolDayReport
+	Clients     []ClientDayReport
+}
+
+// SymbolDayReport is the day's trading in one symbol; Notional is the sum of price times quantity
+type SymbolDayReport struct {
+	Symbol   string
+	Trades   int
+	Volume   decimal.Decimal
+	Notional decimal.Decimal
+	VWAP     decimal.Decimal
+	High     decimal.Decimal
+	Low      decimal.Decimal
+}
+
+// ClientDayReport is one client's activity over the day: orders placed and cancelled, and its side
+// of every trade
+type ClientDayReport struct {
+	ClientID  string
+	Orders    int
+	Cancelled int
+	Fills     int
+	Volume    decimal.Decimal
+	Notional  decimal.Decimal
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// RetentionPolicy moves FILLED and CANCELLED orders to the archive ArchiveAfter their last update
+// and deletes archived orders, with trades no live order refers to, PurgeAfter their last update.
+// A zero duration disables that step.
+type RetentionPolicy struct {
+	ArchiveAfter time.Duration
+	PurgeAfter   time.Duration
+}
+
+// RetentionResult counts the rows one retention run moved or deleted
+type RetentionResult struct {
+	Archived     int
+	PurgedOrders int
+	PurgedTrades int
+}
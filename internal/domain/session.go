@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Session is a logged-in trading connection. Orders placed through it carry its ID; the token is the
+// secret the connection presents and is never put on orders or events.
+type Session struct {
+	ID       string
+	Token    string
+	ClientID string
+	// CancelOnDisconnect cancels the session's resting orders when it logs out or times out
+	CancelOnDisconnect bool
+	CreatedAt          time.Time
+	LastSeen           time.Time
+}
+
+// Expired reports whether the session has been silent for longer than timeout at now
+func (s Session) Expired(now time.Time, timeout time.Duration) bool {
+	return now.Sub(s.LastSeen) > timeout
+}
@@ -0,0 +1,15 @@
+package domain
+
+// SymbolLoad counts the operations a symbol has sent to its worker
+type SymbolLoad struct {
+	Symbol string
+	Ops    uint64
+}
+
+// WorkerAssignment is one worker of the sharded engine and the symbols it serves
+type WorkerAssignment struct {
+	Worker    int
+	Dedicated bool
+	Queued    int
+	Symbols   []SymbolLoad
+}
@@ -12,6 +12,9 @@ type OrderbookSnapshot struct {
 	Trades    []*Trade
 	Timestamp time.Time
 	Symbol    string
+	// Sequence, if not 0, is an engine sequence number taken before the book was read from the
+	// repository: the book reflects every change made before it
+	Sequence uint64
 }
 
 func (o *OrderbookSnapshot) DeepCopy() *OrderbookSnapshot {
@@ -37,6 +40,7 @@ func (o *OrderbookSnapshot) DeepCopy() *OrderbookSnapshot {
 		Timestamp: o.Timestamp,
 		Symbol:    o.Symbol,
 		Trades:    copyTrades,
+		Sequence:  o.Sequence,
 	}
 }
 
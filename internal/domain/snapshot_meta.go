@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// SnapshotMeta describes a stored orderbook snapshot without its contents.
+// Sequence increases per symbol with every snapshot taken.
+type SnapshotMeta struct {
+	ID        string
+	Symbol    string
+	Sequence  int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Bids      int
+	Asks      int
+	Bytes     int
+}
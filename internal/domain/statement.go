@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MovementKind is why a ledger movement happened
+type MovementKind string
+
+const (
+	MovementTrade MovementKind = "TRADE"
+	MovementFee   MovementKind = "FEE"
+)
+
+// StatementFill is the client's side of one trade. Liquidity is empty for trades recorded before
+// the taker side was stored.
+type StatementFill struct {
+	TradeID   string
+	OrderID   string
+	Symbol    string
+	Side      Side
+	Liquidity Liquidity
+	Price     decimal.Decimal
+	Quantity  decimal.Decimal
+	Fee       decimal.Decimal
+	Timestamp time.Time
+	Tags      []string // of the client's order
+	UserData  string   // of the client's order
+}
+
+// LedgerMovement is a signed change of one asset; a fill moves the base and quote assets and its
+// fee, charged in the quote asset, moves the quote asset again
+type LedgerMovement struct {
+	Timestamp time.Time
+	TradeID   string
+	Kind      MovementKind
+	Asset     string
+	Amount    decimal.Decimal
+}
+
+// AssetAmount is an amount of one asset
+type AssetAmount struct {
+	Asset  string
+	Amount decimal.Decimal
+}
+
+// Statement is a client's activity over [From, To): fills, the ledger movements they caused, the net
+// change per asset and the fees paid per asset (negative for rebates)
+type Statement struct {
+	ClientID  string
+	From      time.Time
+	To        time.Time
+	Fills     []StatementFill
+	Movements []LedgerMovement
+	Positions []AssetAmount
+	Fees      []AssetAmount
+}
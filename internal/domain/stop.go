@@ -0,0 +1,38 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// IsStop reports whether orders of the type wait off the book for a trigger price
+func (t OrderType) IsStop() bool {
+	return t == Stop || t == StopLimit
+}
+
+// HasLimit reports whether orders of the type execute only at their price or better
+func (t OrderType) HasLimit() bool {
+	return t == Limit || t == StopLimit
+}
+
+// PriceRange is the lowest and highest price of a run of trades
+type PriceRange struct {
+	Low, High decimal.Decimal
+}
+
+// RangeOf returns the price range of the trades, which must not be empty
+func RangeOf(trades []*Trade) PriceRange {
+	r := PriceRange{Low: trades[0].Price, High: trades[0].Price}
+	for _, t := range trades[1:] {
+		r.Low, r.High = decimal.Min(r.Low, t.Price), decimal.Max(r.High, t.Price)
+	}
+	return r
+}
+
+// TriggeredBy reports whether trades across the range activate the pending stop order
+func (o *Order) TriggeredBy(r PriceRange) bool {
+	if o.Status != Pending {
+		return false
+	}
+	if o.Side == Buy {
+		return r.High.GreaterThanOrEqual(o.TriggerPrice)
+	}
+	return r.Low.LessThanOrEqual(o.TriggerPrice)
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// StoredObject describes a blob in an object store
+type StoredObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
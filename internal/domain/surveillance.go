@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AlertKind names the pattern a surveillance alert was raised for
+type AlertKind string
+
+const (
+	// AlertSelfMatch is a trade between two orders of the same client
+	AlertSelfMatch AlertKind = "SELF_MATCH"
+	// AlertGroupMatch is a trade between two clients of the same group, e.g. one beneficial owner
+	AlertGroupMatch AlertKind = "GROUP_MATCH"
+	// AlertRoundTrip is a client buying and selling the same quantity of a symbol within a short window
+	AlertRoundTrip AlertKind = "ROUND_TRIP"
+	// AlertVolumeAnomaly is a symbol trading far more in one scan than in the scans before it
+	AlertVolumeAnomaly AlertKind = "VOLUME_ANOMALY"
+	// AlertSpoofing is a client cancelling far more than it then traded on the other side of a symbol
+	AlertSpoofing AlertKind = "SPOOFING"
+	// AlertLayering is a client cancelling orders at several price levels of one side after trading on the other
+	AlertLayering AlertKind = "LAYERING"
+	// AlertExcessiveCancels is a client cancelling nearly every order it places in a symbol
+	AlertExcessiveCancels AlertKind = "EXCESSIVE_CANCELS"
+	// AlertPriceImpact is one aggressive order moving a symbol's price sharply
+	AlertPriceImpact AlertKind = "PRICE_IMPACT"
+)
+
+// AlertStatus is where an alert stands in compliance review
+type AlertStatus string
+
+const (
+	AlertOpen      AlertStatus = "OPEN"
+	AlertEscalated AlertStatus = "ESCALATED"
+	AlertDismissed AlertStatus = "DISMISSED"
+)
+
+// SurveillanceAlert is a finding for compliance review. At is when the pattern occurred, the last
+// trade or order event involved; the ID is derived from the kind and what triggered it, so a finding
+// seen again by an overlapping scan is stored once.
+type SurveillanceAlert struct {
+	Tenant     string // set on alerts published to streams
+	ID         string
+	Kind       AlertKind
+	Symbol     string
+	ClientIDs  []string
+	TradeIDs   []string
+	Detail     string
+	At         time.Time
+	DetectedAt time.Time
+	Status     AlertStatus
+	ReviewedBy string
+	ReviewNote string
+	ReviewedAt *time.Time
+}
+
+// AlertFilter narrows the alerts listed for review; zero fields do not filter
+type AlertFilter struct {
+	Status   AlertStatus
+	Kind     AlertKind
+	Symbol   string
+	ClientID string
+}
+
+func (f AlertFilter) Match(a *SurveillanceAlert) bool {
+	if (f.Status != "" && a.Status != f.Status) || (f.Kind != "" && a.Kind != f.Kind) || (f.Symbol != "" && a.Symbol != f.Symbol) {
+		return false
+	}
+	if f.ClientID == "" {
+		return true
+	}
+	for _, c := range a.ClientIDs {
+		if c == f.ClientID {
+			return true
+		}
+	}
+	return false
+}
+
+// SurveillancePolicy tunes the scans; zero fields take the defaults of the engine
+type SurveillancePolicy struct {
+	// Groups maps client IDs to the group they trade for; trades within a group raise AlertGroupMatch
+	Groups map[string]string
+	// RoundTripWindow is how close a buy and a sell of the same quantity must be to raise AlertRoundTrip
+	RoundTripWindow time.Duration
+	// VolumeFactor raises AlertVolumeAnomaly when a scan's volume in a symbol exceeds this multiple of
+	// its average over the last VolumeBaseline scans
+	VolumeFactor   decimal.Decimal
+	VolumeBaseline int
+
+	// The order-flow checks run on each order event, over each client's activity in a symbol within
+	// OrderFlowWindow
+	OrderFlowWindow time.Duration
+	// MinCancels and CancelRatio raise AlertExcessiveCancels when a client cancels at least MinCancels
+	// orders, and at least CancelRatio of the orders it placed
+	MinCancels  int
+	CancelRatio decimal.Decimal
+	// SpoofFactor raises AlertSpoofing when a client cancels at least this multiple of the quantity it
+	// traded on the other side; LayeringLevels raises AlertLayering when those cancels span this many prices
+	SpoofFactor    decimal.Decimal
+	LayeringLevels int
+	// PriceImpactBps raises AlertPriceImpact when one order's fills span this many basis points
+	PriceImpactBps decimal.Decimal
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "slices"
+
+// limits of the tags a client may attach to an order, e.g. the name of the strategy that placed it
+const (
+	MaxOrderTags   = 8
+	MaxOrderTagLen = 32
+)
+
+// ValidateTags rejects more than MaxOrderTags tags, repeated or empty ones, tags longer than
+// MaxOrderTagLen and characters other than letters, digits and - _ . : /
+func ValidateTags(tags []string) error {
+	if len(tags) > MaxOrderTags {
+		return Reject(RejectInvalidOrder, "at most %d tags per order", MaxOrderTags)
+	}
+	for i, t := range tags {
+		if t == "" || len(t) > MaxOrderTagLen {
+			return Reject(RejectInvalidOrder, "tags must be 1 to %d characters", MaxOrderTagLen)
+		}
+		for _, r := range t {
+			if !tagRune(r) {
+				return Reject(RejectInvalidOrder, "tag %q: only letters, digits and - _ . : / are allowed", t)
+			}
+		}
+		if slices.Contains(tags[:i], t) {
+			return Reject(RejectInvalidOrder, "tag %q is repeated", t)
+		}
+	}
+	return nil
+}
+
+func tagRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' || r == ':' || r == '/'
+}
+
+// HasTag reports whether tag is among tags; the empty tag matches anything, as an unset filter
+func HasTag(tags []string, tag string) bool {
+	return tag == "" || slices.Contains(tags, tag)
+}
@@ -0,0 +1,21 @@
+package domain
+
+// TenantConfig isolates a tenant's market. An empty Symbols list allows any symbol,
+// a nil Fees or Retention falls back to the engine-wide setting.
+type TenantConfig struct {
+	Symbols   []string
+	Fees      *FeeSchedule
+	Retention *RetentionPolicy
+}
+
+func (c TenantConfig) Lists(symbol string) bool {
+	if len(c.Symbols) == 0 {
+		return true
+	}
+	for _, s := range c.Symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
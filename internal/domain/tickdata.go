@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BookChange is one recorded change of a resting order: placed, filled, modified or removed. Side and
+// Price are the order's as stored now, so the changes of a modified order carry its last price.
+type BookChange struct {
+	OrderID   string
+	Symbol    string
+	Side      Side
+	Price     decimal.Decimal
+	ExecType  ExecType
+	Remaining decimal.Decimal
+	At        time.Time
+	Sequence  uint64
+}
+
+// TickJobStatus is the stage of a tick data job
+type TickJobStatus string
+
+const (
+	TickJobPending TickJobStatus = "PENDING"
+	TickJobRunning TickJobStatus = "RUNNING"
+	TickJobDone    TickJobStatus = "DONE"
+	TickJobFailed  TickJobStatus = "FAILED"
+)
+
+// Tick data files a job can produce
+const (
+	TickFileTrades = "trades"
+	TickFileBook   = "book"
+)
+
+// TickJob is a request for a symbol's tick history between From, inclusive, and To, exclusive.
+// Files maps each file written, trades and with BookChanges also book, to its object key.
+type TickJob struct {
+	ID          string
+	Symbol      string
+	From        time.Time
+	To          time.Time
+	BookChanges bool
+	Status      TickJobStatus
+	Error       string
+	Files       map[string]string
+	Trades      int
+	Changes     int
+	CreatedAt   time.Time
+	FinishedAt  time.Time
+}
+
+// TickTrade is a trade line of a tick data file; it names no client
+type TickTrade struct {
+	Sequence  uint64          `json:"seq"`
+	At        time.Time       `json:"at"`
+	TradeID   string          `json:"trade_id"`
+	Price     decimal.Decimal `json:"price"`
+	Quantity  decimal.Decimal `json:"quantity"`
+	TakerSide Side            `json:"taker_side,omitempty"`
+}
+
+// TickBookChange is a book change line of a tick data file
+type TickBookChange struct {
+	Sequence  uint64          `json:"seq"`
+	At        time.Time       `json:"at"`
+	OrderID   string          `json:"order_id"`
+	Side      Side            `json:"side"`
+	Price     decimal.Decimal `json:"price"`
+	ExecType  ExecType        `json:"exec_type"`
+	Remaining decimal.Decimal `json:"remaining"`
+}
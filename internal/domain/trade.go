@@ -13,4 +13,74 @@ type Trade struct {
 	Price     decimal.Decimal
 	Quantity  decimal.Decimal
 	Timestamp time.Time
+	MakerFee  decimal.Decimal
+	TakerFee  decimal.Decimal
+	// Sequence is the engine-assigned number of the execution, ordered with the order events
+	Sequence uint64
+	// MakerFeeAsset and TakerFeeAsset are what the fees are charged in, the symbol's quote asset
+	MakerFeeAsset string
+	TakerFeeAsset string
+	// TakerSide and TakerType describe the incoming order that executed against the resting one
+	TakerSide Side
+	TakerType OrderType
+	// BuyLiquidity and SellLiquidity tell which of the two orders was the maker and which the taker
+	BuyLiquidity  Liquidity
+	SellLiquidity Liquidity
+	// BuyClient and SellClient are the owners of the two orders
+	BuyClient  string
+	SellClient string
+}
+
+// LiquidityOf returns whether the order on side made or took liquidity; empty on trades executed
+// before the taker side was recorded
+func LiquidityOf(takerSide, side Side) Liquidity {
+	switch takerSide {
+	case "":
+		return ""
+	case side:
+		return Taker
+	default:
+		return Maker
+	}
+}
+
+// Liquidity tells whether an order's fill added liquidity (rested in the book) or took it
+type Liquidity string
+
+const (
+	Maker Liquidity = "MAKER"
+	Taker Liquidity = "TAKER"
+)
+
+// TradeFilter selects trades for a subscriber; zero fields do not filter
+type TradeFilter struct {
+	MinQty   decimal.Decimal
+	MinPrice decimal.Decimal
+	MaxPrice decimal.Decimal
+	// ClientID keeps only trades in which the client owns one of the orders
+	ClientID string
+}
+
+func (f TradeFilter) Match(t *Trade) bool {
+	if !f.MinQty.IsZero() && t.Quantity.LessThan(f.MinQty) {
+		return false
+	}
+	if !f.MinPrice.IsZero() && t.Price.LessThan(f.MinPrice) {
+		return false
+	}
+	if !f.MaxPrice.IsZero() && t.Price.GreaterThan(f.MaxPrice) {
+		return false
+	}
+	if f.ClientID != "" && t.BuyClient != f.ClientID && t.SellClient != f.ClientID {
+		return false
+	}
+	return true
+}
+
+// TapeEntry is a trade read back from the trade tape together with its position in it
+type TapeEntry struct {
+	Seq   string
+	Trade *Trade
+	// Tenant is set on entries of the cross-tenant feed
+	Tenant string
 }
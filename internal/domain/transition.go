@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransitionCause is why an order changed state, as support would explain it to the client
+type TransitionCause string
+
+const (
+	CausePlaced          TransitionCause = "PLACED"
+	CauseRejected        TransitionCause = "REJECTED"
+	CauseMatched         TransitionCause = "MATCHED"
+	CauseModified        TransitionCause = "MODIFIED"
+	CauseCancelledByUser TransitionCause = "CANCELLED_BY_USER"
+	CauseAdmin           TransitionCause = "ADMIN"
+	CauseDelisted        TransitionCause = "DELISTED"
+	CauseExpired         TransitionCause = "EXPIRED"
+	CauseTriggered       TransitionCause = "TRIGGERED"
+	CauseDisconnected    TransitionCause = "DISCONNECTED" // the placing session logged out or timed out
+	CauseGroupFilled     TransitionCause = "GROUP_FILLED" // another leg of the order's group filled completely
+)
+
+// CauseOf is the cause of a transition of the exec type when nothing more specific is known;
+// cancels default to the client's own
+func CauseOf(et ExecType) TransitionCause {
+	switch et {
+	case ExecNew:
+		return CausePlaced
+	case ExecRejected:
+		return CauseRejected
+	case ExecPartialFill, ExecFill:
+		return CauseMatched
+	case ExecReplaced:
+		return CauseModified
+	case ExecExpired:
+		return CauseExpired
+	case ExecTriggered:
+		return CauseTriggered
+	default:
+		return CauseCancelledByUser
+	}
+}
+
+// OrderTransition is one recorded step of an order's history: the status it moved to, when and why
+type OrderTransition struct {
+	OrderID   string
+	ClientID  string
+	Symbol    string
+	ExecType  ExecType
+	Status    OrderStatus
+	Cause     TransitionCause
+	Reason    string
+	Remaining decimal.Decimal
+	TradeID   string
+	At        time.Time
+	Sequence  uint64
+}
+
+// TransitionOf is the history entry of an order event
+func TransitionOf(ev *OrderEvent) OrderTransition {
+	return OrderTransition{
+		OrderID:   ev.OrderID,
+		ClientID:  ev.ClientID,
+		Symbol:    ev.Symbol,
+		ExecType:  ev.ExecType,
+		Status:    ev.Status,
+		Cause:     ev.Cause,
+		Reason:    ev.Reason,
+		Remaining: ev.Remaining,
+		TradeID:   ev.TradeID,
+		At:        ev.Timestamp,
+		Sequence:  ev.Sequence,
+	}
+}
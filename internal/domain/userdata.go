@@ -0,0 +1,18 @@
+package domain
+
+import "unicode/utf8"
+
+// MaxUserDataLen is the largest UserData in bytes an order may carry
+const MaxUserDataLen = 256
+
+// ValidateUserData rejects metadata over MaxUserDataLen bytes or not valid UTF-8, which could not be
+// echoed verbatim in JSON
+func ValidateUserData(s string) error {
+	if len(s) > MaxUserDataLen {
+		return Reject(RejectInvalidOrder, "user_data must not exceed %d bytes", MaxUserDataLen)
+	}
+	if !utf8.ValidString(s) {
+		return Reject(RejectInvalidOrder, "user_data must be valid UTF-8")
+	}
+	return nil
+}
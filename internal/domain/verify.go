@@ -0,0 +1,28 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// OrderFill is a persisted order together with the quantity its persisted trades executed
+type OrderFill struct {
+	Order  Order
+	Filled decimal.Decimal
+}
+
+// Discrepancy is one mismatch found while verifying a book against its persisted history
+type Discrepancy struct {
+	OrderID  string `json:"order_id,omitempty"`
+	Kind     string `json:"kind"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// BookReport is the outcome of rebuilding one symbol's book from orders and trades
+type BookReport struct {
+	Symbol        string        `json:"symbol"`
+	Orders        int           `json:"orders"`
+	Resting       int           `json:"resting"`
+	Cached        bool          `json:"cached"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+func (r *BookReport) OK() bool { return len(r.Discrepancies) == 0 }
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// WarmupStatus is the progress of loading the books into the cache at boot. An engine not started
+// warm is ready from the start.
+type WarmupStatus struct {
+	Ready bool
+	// Symbols is how many books are to be loaded, across tenants; known once every tenant's symbols are listed
+	Symbols int
+	Loaded  int
+	// Orders is how many open orders the loaded books hold
+	Orders int
+	// Failed lists the tenant-scoped symbols whose book did not load; they are read from the
+	// repository until they next change
+	Failed     []string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
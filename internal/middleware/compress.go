@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	gzipPool  = sync.Pool{New: func() any { w, _ := gzip.NewWriterLevel(io.Discard, gzip.BestSpeed); return w }}
+	flatePool = sync.Pool{New: func() any { w, _ := flate.NewWriter(io.Discard, flate.BestSpeed); return w }}
+)
+
+// Compress gzip- or deflate-encodes responses of at least minSize bytes for clients that accept it.
+// Smaller responses are sent as is, where compression would cost more than it saves.
+func Compress(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+		cw := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize}
+		c.Writer = cw
+		c.Header("Vary", "Accept-Encoding")
+		c.Next()
+		cw.finish()
+	}
+}
+
+// negotiateEncoding picks gzip over deflate among the encodings the client accepts
+func negotiateEncoding(accept string) string {
+	var deflate bool
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.ReplaceAll(params, " ", "") == "q=0" {
+			continue
+		}
+		switch strings.ToLower(name) {
+		case "gzip":
+			return "gzip"
+		case "deflate":
+			deflate = true
+		}
+	}
+	if deflate {
+		return "deflate"
+	}
+	return ""
+}
+
+// compressWriter buffers the body until it reaches minSize, then switches to a compressing stream
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	buf      bytes.Buffer
+	enc      io.WriteCloser
+}
+
+// WriteHeaderNow is deferred until the encoding is decided; WriteHeader only records the status in gin
+func (w *compressWriter) WriteHeaderNow() {}
+
+func (w *compressWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	w.buf.Write(p)
+	if w.buf.Len() >= w.minSize {
+		if err := w.startEncoding(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *compressWriter) startEncoding() error {
+	h := w.ResponseWriter.Header()
+	if h.Get("Content-Encoding") != "" {
+		// the handler encoded the body itself
+		w.enc = nopCloser{w.ResponseWriter}
+	} else {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		if w.encoding == "gzip" {
+			gz := gzipPool.Get().(*gzip.Writer)
+			gz.Reset(w.ResponseWriter)
+			w.enc = pooled{gz, func() { gzipPool.Put(gz) }}
+		} else {
+			fl := flatePool.Get().(*flate.Writer)
+			fl.Reset(w.ResponseWriter)
+			w.enc = pooled{fl, func() { flatePool.Put(fl) }}
+		}
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	_, err := w.enc.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// Flush pushes what the handler wrote so far to the client, compressed if it is long enough to be
+// worth it, for streaming responses
+func (w *compressWriter) Flush() {
+	if w.enc == nil && w.buf.Len() > 0 {
+		if err := w.startEncoding(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish sends a body that stayed below minSize uncompressed, or closes the compressing stream
+func (w *compressWriter) finish() {
+	if w.enc != nil {
+		_ = w.enc.Close()
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+}
+
+// pooled returns its encoder to the pool once closed
+type pooled struct {
+	io.WriteCloser
+	release func()
+}
+
+func (p pooled) Flush() error {
+	if f, ok := p.WriteCloser.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (p pooled) Close() error {
+	err := p.WriteCloser.Close()
+	p.release()
+	return err
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	long := strings.Repeat("orderbook ", 100)
+	tests := []struct {
+		name     string
+		accept   string
+		body     string
+		encoded  bool // the handler sets Content-Encoding itself
+		encoding string
+	}{
+		{"no Accept-Encoding", "", long, false, ""},
+		{"gzip", "gzip", long, false, "gzip"},
+		{"deflate", "deflate", long, false, "deflate"},
+		{"gzip preferred", "deflate, gzip;q=0.5", long, false, "gzip"},
+		{"gzip refused", "gzip;q=0, deflate", long, false, "deflate"},
+		{"everything refused", "gzip; q=0, deflate;q=0", long, false, ""},
+		{"unsupported only", "br", long, false, ""},
+		{"case-insensitive", "GZIP", long, false, "gzip"},
+		{"below the minimum size", "gzip", "short", false, ""},
+		{"empty body", "gzip", "", false, ""},
+		{"encoded by the handler", "gzip", long, true, "identity"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(Compress(256))
+			r.GET("/", func(c *gin.Context) {
+				if tc.encoded {
+					c.Header("Content-Encoding", "identity")
+				}
+				c.String(http.StatusTeapot, tc.body)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept-Encoding", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusTeapot {
+				t.Errorf("status %d, want %d", w.Code, http.StatusTeapot)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tc.encoding {
+				t.Fatalf("Content-Encoding %q, want %q", got, tc.encoding)
+			}
+			var body io.Reader = w.Body
+			switch tc.encoding {
+			case "gzip":
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = gz
+			case "deflate":
+				body = flate.NewReader(w.Body)
+			}
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.body {
+				t.Errorf("decoded body of %d bytes, want %d", len(got), len(tc.body))
+			}
+			if compressed := tc.encoding == "gzip" || tc.encoding == "deflate"; compressed && w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Vary %q, want Accept-Encoding", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
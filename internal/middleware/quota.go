@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Quota limits the total request weight an API key may spend per UTC day and month; zero means unlimited
+type Quota struct {
+	Daily   int64
+	Monthly int64
+}
+
+// Usage is the consumption of one API key in the current day and month
+type Usage struct {
+	Key             string `json:"key"`
+	DailyRequests   int64  `json:"daily_requests"`
+	DailyWeight     int64  `json:"daily_weight"`
+	DailyLimit      int64  `json:"daily_limit"`
+	MonthlyRequests int64  `json:"monthly_requests"`
+	MonthlyWeight   int64  `json:"monthly_weight"`
+	MonthlyLimit    int64  `json:"monthly_limit"`
+}
+
+type counter struct {
+	period   string
+	requests int64
+	weight   int64
+}
+
+type keyUsage struct {
+	day   counter
+	month counter
+}
+
+// UsageTracker counts weighted requests per authenticated API key and enforces quotas; its
+// middleware runs after Authenticate. Counters live in memory and restart from zero with the process.
+type UsageTracker struct {
+	mu      sync.Mutex
+	usage   map[string]*keyUsage
+	quota   Quota
+	weights map[string]int64
+	now     func() time.Time
+}
+
+// NewUsageTracker creates a tracker; weights maps "METHOD /path" to a request weight, default 1
+func NewUsageTracker(quota Quota, weights map[string]int64) *UsageTracker {
+	return &UsageTracker{
+		usage:   make(map[string]*keyUsage),
+		quota:   quota,
+		weights: weights,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (u *UsageTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, ok := usageKey(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		weight := u.weight(c.Request.Method + " " + c.FullPath())
+
+		u.mu.Lock()
+		ku := u.current(key)
+		remaining := u.remaining(ku)
+		if remaining >= 0 && weight > remaining {
+			u.mu.Unlock()
+			setLimitHeaders(c, u.quota, 0)
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(u.nextReset(ku).Unix(), 10))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "usage quota exceeded"})
+			c.Abort()
+			return
+		}
+		ku.day.requests++
+		ku.day.weight += weight
+		ku.month.requests++
+		ku.month.weight += weight
+		remaining = u.remaining(ku)
+		u.mu.Unlock()
+
+		setLimitHeaders(c, u.quota, remaining)
+		c.Next()
+	}
+}
+
+// Usage reports the key's consumption in the current periods
+func (u *UsageTracker) Usage(key string) Usage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	ku := u.current(key)
+	return Usage{
+		Key:             key,
+		DailyRequests:   ku.day.requests,
+		DailyWeight:     ku.day.weight,
+		DailyLimit:      u.quota.Daily,
+		MonthlyRequests: ku.month.requests,
+		MonthlyWeight:   ku.month.weight,
+		MonthlyLimit:    u.quota.Monthly,
+	}
+}
+
+// UsageHandler serves the caller's own usage
+func (u *UsageTracker) UsageHandler(c *gin.Context) {
+	key, ok := usageKey(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "usage is counted for API keys only"})
+		return
+	}
+	c.JSON(http.StatusOK, u.Usage(key))
+}
+
+// usageKey is the authenticated key the request is charged to, never a header the caller may set
+func usageKey(c *gin.Context) (string, bool) {
+	p, ok := PrincipalFrom(c)
+	if !ok || p.Key == "" {
+		return "", false
+	}
+	return p.Key, true
+}
+
+func (u *UsageTracker) weight(route string) int64 {
+	if w, ok := u.weights[route]; ok {
+		return w
+	}
+	return 1
+}
+
+// current returns the key's counters, resetting those whose period has rolled over; caller holds mu
+func (u *UsageTracker) current(key string) *keyUsage {
+	now := u.now()
+	day, month := now.Format("2006-01-02"), now.Format("2006-01")
+	ku, ok := u.usage[key]
+	if !ok {
+		ku = &keyUsage{}
+		u.usage[key] = ku
+	}
+	if ku.day.period != day {
+		ku.day = counter{period: day}
+	}
+	if ku.month.period != month {
+		ku.month = counter{period: month}
+	}
+	return ku
+}
+
+// remaining returns the weight left before the tighter quota is hit, or -1 when unlimited
+func (u *UsageTracker) remaining(ku *keyUsage) int64 {
+	left := int64(-1)
+	if u.quota.Daily > 0 {
+		left = max(u.quota.Daily-ku.day.weight, 0)
+	}
+	if u.quota.Monthly > 0 {
+		m := max(u.quota.Monthly-ku.month.weight, 0)
+		if left < 0 || m < left {
+			left = m
+		}
+	}
+	return left
+}
+
+func (u *UsageTracker) nextReset(ku *keyUsage) time.Time {
+	now := u.now()
+	if u.quota.Monthly > 0 && ku.month.weight >= u.quota.Monthly {
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+func setLimitHeaders(c *gin.Context, q Quota, remaining int64) {
+	if remaining < 0 {
+		return
+	}
+	limit := q.Daily
+	if limit == 0 || (q.Monthly > 0 && q.Monthly < limit) {
+		limit = q.Monthly
+	}
+	c.Header("X-RateLimit-Limit", strconv.FormatInt(limit, 10))
+	c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/auth"
+)
+
+const principalKey = "principal"
+
+// Authenticate resolves the request's credentials to a principal and stores it in the gin context
+// and, together with its tenant, in the request context. The credentials are a bearer token in
+// Authorization, or the X-API-Key header with, for keys with a secret, X-Timestamp and X-Signature
+// signing the method, target and body.
+func Authenticate(store *auth.KeyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cred := auth.Credentials{
+			APIKey:    c.GetHeader("X-API-Key"),
+			Timestamp: c.GetHeader("X-Timestamp"),
+			Signature: c.GetHeader("X-Signature"),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.RequestURI(),
+		}
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			cred.Bearer = strings.TrimSpace(bearer)
+		}
+		if cred.Signature != "" && c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			cred.Body = body
+		}
+		p, err := store.Authenticate(cred)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		c.Set(principalKey, p)
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+// RequireRole lets the request through only if the principal holds one of roles
+func RequireRole(roles ...auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := PrincipalFrom(c)
+		if !ok || !p.HasAny(roles...) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func PrincipalFrom(c *gin.Context) (auth.Principal, bool) {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return auth.Principal{}, false
+	}
+	p, ok := v.(auth.Principal)
+	return p, ok
+}
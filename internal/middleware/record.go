@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olyamironova/exchange-engine/internal/record"
+)
+
+// Record writes the requests to routes, keyed "METHOD /path", to rec before they are handled
+func Record(rec *record.Recorder, routes map[string]bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !routes[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+		at := time.Now().UTC()
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		e := record.Entry{
+			At: at, Proto: record.ProtoHTTP, Method: c.Request.Method, Path: c.Request.URL.Path,
+			Query: c.Request.URL.RawQuery, ClientID: c.GetHeader("X-Client-ID"),
+		}
+		// a body that is not JSON is rejected by the handler; replaying it empty is rejected the same way
+		if json.Valid(body) {
+			e.Body = body
+		}
+		rec.Record(e)
+		c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// AlertPayload is the wire form of a surveillance alert sent by AlertWebhook
+type AlertPayload struct {
+	Tenant     string    `json:"tenant"`
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Symbol     string    `json:"symbol"`
+	ClientIDs  []string  `json:"client_ids"`
+	TradeIDs   []string  `json:"trade_ids"`
+	Detail     string    `json:"detail"`
+	At         time.Time `json:"at"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+func NewAlertPayload(a *domain.SurveillanceAlert) AlertPayload {
+	return AlertPayload{
+		Tenant:     a.Tenant,
+		ID:         a.ID,
+		Kind:       string(a.Kind),
+		Symbol:     a.Symbol,
+		ClientIDs:  a.ClientIDs,
+		TradeIDs:   a.TradeIDs,
+		Detail:     a.Detail,
+		At:         a.At,
+		DetectedAt: a.DetectedAt,
+	}
+}
+
+// AlertWebhook POSTs each surveillance alert to one compliance endpoint
+type AlertWebhook struct {
+	url    string
+	client *http.Client
+}
+
+func NewAlertWebhook(url string, client *http.Client) *AlertWebhook {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &AlertWebhook{url: url, client: client}
+}
+
+// Run delivers alerts from the channel until it is closed or ctx is done
+func (w *AlertWebhook) Run(ctx context.Context, alerts <-chan *domain.SurveillanceAlert) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case a, ok := <-alerts:
+			if !ok {
+				return
+			}
+			nctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+			if err := w.Notify(nctx, a); err != nil {
+				log.Printf("notify: surveillance alert %s to %s: %v", a.ID, w.url, err)
+			}
+			cancel()
+		}
+	}
+}
+
+func (w *AlertWebhook) Notify(ctx context.Context, a *domain.SurveillanceAlert) error {
+	body, err := json.Marshal(NewAlertPayload(a))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// LogNotifier writes events to the standard logger
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier { return &LogNotifier{} }
+
+func (LogNotifier) Channel() domain.NotificationChannel { return domain.ChannelLog }
+
+func (LogNotifier) Notify(_ context.Context, _ domain.NotificationPreference, ev *domain.OrderEvent) error {
+	log.Printf("order event: client=%s order=%s %s status=%s remaining=%s",
+		ev.ClientID, ev.OrderID, ev.ExecType, ev.Status, ev.Remaining)
+	return nil
+}
+
+// WebhookNotifier POSTs the JSON payload to the preference target URL
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier(client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookNotifier{client: client}
+}
+
+func (*WebhookNotifier) Channel() domain.NotificationChannel { return domain.ChannelWebhook }
+
+func (w *WebhookNotifier) Notify(ctx context.Context, pref domain.NotificationPreference, ev *domain.OrderEvent) error {
+	if pref.Target == "" {
+		return errors.New("webhook target is empty")
+	}
+	body, err := json.Marshal(NewPayload(ev))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pref.Target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Pusher sends a message to a connected client; implemented by the WebSocket gateway
+type Pusher interface {
+	Push(clientID string, msg []byte) error
+}
+
+// WSPushNotifier forwards events to the client's open WebSocket connections
+type WSPushNotifier struct {
+	pusher Pusher
+}
+
+func NewWSPushNotifier(p Pusher) *WSPushNotifier { return &WSPushNotifier{pusher: p} }
+
+func (*WSPushNotifier) Channel() domain.NotificationChannel { return domain.ChannelWebSocket }
+
+func (w *WSPushNotifier) Notify(_ context.Context, _ domain.NotificationPreference, ev *domain.OrderEvent) error {
+	msg, err := json.Marshal(NewPayload(ev))
+	if err != nil {
+		return err
+	}
+	return w.pusher.Push(ev.ClientID, msg)
+}
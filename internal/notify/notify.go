@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+// Notifier delivers one order event over one channel
+type Notifier interface {
+	Channel() domain.NotificationChannel
+	Notify(ctx context.Context, pref domain.NotificationPreference, ev *domain.OrderEvent) error
+}
+
+// Payload is the wire form of an order event sent by notifiers
+type Payload struct {
+	Tenant     string          `json:"tenant"`
+	OrderID    string          `json:"order_id"`
+	ClientID   string          `json:"client_id"`
+	Symbol     string          `json:"symbol"`
+	Side       string          `json:"side"`
+	Type       string          `json:"type"`
+	ExecType   string          `json:"exec_type"`
+	Status     string          `json:"status"`
+	Price      decimal.Decimal `json:"price"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	Remaining  decimal.Decimal `json:"remaining"`
+	LastPrice  decimal.Decimal `json:"last_price"`
+	LastQty    decimal.Decimal `json:"last_qty"`
+	TradeID    string          `json:"trade_id,omitempty"`
+	Reason     string          `json:"reason,omitempty"`
+	RejectCode string          `json:"reject_code,omitempty"`
+	Tags       []string        `json:"tags,omitempty"`
+	UserData   string          `json:"user_data,omitempty"`
+	GroupID    string          `json:"group_id,omitempty"`
+	Timestamp  time.Time       `json:"timestamp"`
+	Sequence   uint64          `json:"sequence"`
+}
+
+func NewPayload(ev *domain.OrderEvent) Payload {
+	return Payload{
+		Tenant:     ev.Tenant,
+		OrderID:    ev.OrderID,
+		ClientID:   ev.ClientID,
+		Symbol:     ev.Symbol,
+		Side:       string(ev.Side),
+		Type:       string(ev.Type),
+		ExecType:   string(ev.ExecType),
+		Status:     string(ev.Status),
+		Price:      ev.Price,
+		Quantity:   ev.Quantity,
+		Remaining:  ev.Remaining,
+		LastPrice:  ev.LastPrice,
+		LastQty:    ev.LastQty,
+		TradeID:    ev.TradeID,
+		Reason:     ev.Reason,
+		RejectCode: string(ev.RejectCode),
+		Tags:       ev.Tags,
+		UserData:   ev.UserData,
+		GroupID:    ev.GroupID,
+		Timestamp:  ev.Timestamp,
+		Sequence:   ev.Sequence,
+	}
+}
+
+const (
+	prefsTTL      = 30 * time.Second
+	notifyTimeout = 5 * time.Second
+)
+
+// Dispatcher fans order events out to notifiers according to each client's stored preferences
+type Dispatcher struct {
+	repo      port.Repository
+	notifiers map[domain.NotificationChannel]Notifier
+
+	mu    sync.Mutex
+	prefs map[string]cachedPrefs
+}
+
+type cachedPrefs struct {
+	prefs   []domain.NotificationPreference
+	expires time.Time
+}
+
+func NewDispatcher(repo port.Repository, notifiers ...Notifier) *Dispatcher {
+	d := &Dispatcher{
+		repo:      repo,
+		notifiers: make(map[domain.NotificationChannel]Notifier, len(notifiers)),
+		prefs:     make(map[string]cachedPrefs),
+	}
+	for _, n := range notifiers {
+		d.notifiers[n.Channel()] = n
+	}
+	return d
+}
+
+// Run delivers events from the channel until it is closed or ctx is done
+func (d *Dispatcher) Run(ctx context.Context, events <-chan *domain.OrderEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			d.Dispatch(ctx, ev)
+		}
+	}
+}
+
+func (d *Dispatcher) Dispatch(ctx context.Context, ev *domain.OrderEvent) {
+	ctx = tenant.With(ctx, ev.Tenant)
+	prefs, err := d.preferences(ctx, ev.ClientID)
+	if err != nil {
+		log.Printf("notify: load preferences for %s: %v", ev.ClientID, err)
+		return
+	}
+	for _, p := range prefs {
+		if !p.Wants(ev.ExecType) {
+			continue
+		}
+		n, ok := d.notifiers[p.Channel]
+		if !ok {
+			continue
+		}
+		nctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		if err := n.Notify(nctx, p, ev); err != nil {
+			log.Printf("notify: %s to %s for order %s: %v", p.Channel, ev.ClientID, ev.OrderID, err)
+		}
+		cancel()
+	}
+}
+
+// Forget drops the cached preferences of the ctx tenant's client so the next event reloads them
+func (d *Dispatcher) Forget(ctx context.Context, clientID string) {
+	d.mu.Lock()
+	delete(d.prefs, tenant.Scope(ctx, clientID))
+	d.mu.Unlock()
+}
+
+func (d *Dispatcher) preferences(ctx context.Context, clientID string) ([]domain.NotificationPreference, error) {
+	key := tenant.Scope(ctx, clientID)
+	d.mu.Lock()
+	c, ok := d.prefs[key]
+	d.mu.Unlock()
+	if ok && time.Now().Before(c.expires) {
+		return c.prefs, nil
+	}
+	prefs, err := d.repo.LoadNotificationPreferences(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	d.prefs[key] = cachedPrefs{prefs: prefs, expires: time.Now().Add(prefsTTL)}
+	d.mu.Unlock()
+	return prefs, nil
+}
@@ -0,0 +1,73 @@
+// Package page is the pagination shared by list endpoints: opaque cursors, limit caps and pages
+// carrying the cursor of the next one.
+package page
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not issued for the list it is used on
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// Request selects one page of a list. Cursor is the NextCursor of the previous page, empty for the first.
+type Request struct {
+	Cursor string
+	Limit  int
+}
+
+// Capped returns r with Limit in (0, max]; a non-positive limit asks for max
+func (r Request) Capped(max int) Request {
+	if r.Limit <= 0 || r.Limit > max {
+		r.Limit = max
+	}
+	return r
+}
+
+// Page is one page of a list; NextCursor is empty on the last page
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// Key is the position of a row in lists ordered by time, the id breaking ties
+type Key struct {
+	At time.Time `json:"t"`
+	ID string    `json:"id"`
+}
+
+// Encode makes an opaque cursor from the position of the last item of a page
+func Encode(pos any) string {
+	b, _ := json.Marshal(pos)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode reads a cursor made by Encode into pos and reports whether there was one
+func Decode(cursor string, pos any) (bool, error) {
+	if cursor == "" {
+		return false, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return false, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, pos); err != nil {
+		return false, ErrInvalidCursor
+	}
+	return true, nil
+}
+
+// Build makes a page from items fetched with Fetch(limit): the extra item only shows that there is a
+// next page, whose cursor is the position of the last item kept
+func Build[T any](items []T, limit int, pos func(T) any) Page[T] {
+	if len(items) <= limit {
+		return Page[T]{Items: items}
+	}
+	items = items[:limit]
+	return Page[T]{Items: items, NextCursor: Encode(pos(items[limit-1]))}
+}
+
+// Fetch is how many items to read for a page of limit items, one more to detect a next page
+func Fetch(limit int) int { return limit + 1 }
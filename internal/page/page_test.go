@@ -0,0 +1,88 @@
+package page
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 15, 123456789, time.UTC)
+	tests := []struct {
+		name string
+		key  Key
+	}{
+		{"nanoseconds", Key{At: at, ID: "7f3c2a10-1b2d-4c5e-8f90-a1b2c3d4e5f6"}},
+		{"whole seconds", Key{At: at.Truncate(time.Second), ID: "t1"}},
+		{"other zone", Key{At: at.In(time.FixedZone("UTC+3", 3*3600)), ID: "t2"}},
+		{"zero time", Key{ID: "t3"}},
+		{"id needing escapes", Key{At: at, ID: `a/b+c="d"`}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cursor := Encode(tc.key)
+			var got Key
+			ok, err := Decode(cursor, &got)
+			if err != nil || !ok {
+				t.Fatalf("decode %q: %v, %v", cursor, ok, err)
+			}
+			if !got.At.Equal(tc.key.At) || got.ID != tc.key.ID {
+				t.Errorf("round trip = %+v, want %+v", got, tc.key)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+		ok     bool
+		err    error
+	}{
+		{"empty is the first page", "", false, nil},
+		{"not base64", "%%%", false, ErrInvalidCursor},
+		{"padded base64", "eyJ0IjoxfQ==", false, ErrInvalidCursor},
+		{"not JSON", Encode("x")[:2], false, ErrInvalidCursor},
+		{"JSON of another shape", Encode([]int{1}), false, ErrInvalidCursor},
+		{"wrong field type", Encode(map[string]any{"t": 5}), false, ErrInvalidCursor},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var k Key
+			ok, err := Decode(tc.cursor, &k)
+			if ok != tc.ok || !errors.Is(err, tc.err) {
+				t.Errorf("decode %q = %v, %v; want %v, %v", tc.cursor, ok, err, tc.ok, tc.err)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []int
+		limit int
+		next  bool
+	}{
+		{"empty", nil, 2, false},
+		{"short page", []int{1}, 2, false},
+		{"exactly the limit", []int{1, 2}, 2, false},
+		{"one extra item", []int{1, 2, 3}, 2, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := Build(tc.items, tc.limit, func(i int) any { return Key{ID: string(rune('a' + i))} })
+			if (p.NextCursor != "") != tc.next || len(p.Items) > tc.limit {
+				t.Fatalf("page = %+v", p)
+			}
+			if !tc.next {
+				return
+			}
+			var k Key
+			if _, err := Decode(p.NextCursor, &k); err != nil || k.ID != "c" {
+				t.Errorf("next cursor decodes to %+v, %v; want the last item kept", k, err)
+			}
+		})
+	}
+}
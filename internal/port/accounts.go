@@ -0,0 +1,28 @@
+package port
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// AccountRepository reads client balances. Every cancel method of Repository and Tx releases the
+// holds of the orders it cancels back to the available balance in the same transaction.
+type AccountRepository interface {
+	// LoadAccounts returns the client's balances by asset, empty if it never held any
+	LoadAccounts(ctx context.Context, clientID string) ([]domain.Account, error)
+}
+
+// AccountTx moves balances in a transaction, so they commit or roll back with the orders and trades
+// that moved them
+type AccountTx interface {
+	// AdjustAccount adds the deltas to the client's available and locked balance of asset, opening
+	// the account on first use; it fails with domain.ErrInsufficientFunds if either would go below zero
+	AdjustAccount(ctx context.Context, clientID, asset string, available, locked decimal.Decimal) error
+	// LoadHold returns the order's hold, nil if it holds nothing
+	LoadHold(ctx context.Context, orderID string) (*domain.Hold, error)
+	// SaveHold sets the order's hold; a zero amount removes it. The balance it holds is moved with
+	// AdjustAccount.
+	SaveHold(ctx context.Context, h domain.Hold) error
+}
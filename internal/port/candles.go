@@ -0,0 +1,18 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// CandleRepository stores the OHLCV bars of executed trades
+type CandleRepository interface {
+	// MergeCandles folds bars of trades executed after those already merged into the stored bars of
+	// the same symbol, interval and start, storing those not stored yet
+	MergeCandles(ctx context.Context, candles []domain.Candle) error
+	// LoadCandles returns the last limit bars of symbol at interval starting in [from, to), oldest
+	// first; a zero from or to leaves that end open
+	LoadCandles(ctx context.Context, symbol string, interval domain.CandleInterval, from, to time.Time, limit int) ([]domain.Candle, error)
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// EventJournal is a capped, append-only log of a tenant's order events, read back to resume streams
+type EventJournal interface {
+	// AppendEvents records events in order and sets their Seq
+	AppendEvents(ctx context.Context, evs []*domain.OrderEvent) error
+	// EventsAfter returns up to limit events of clientID ("*" for every client) strictly after afterSeq, oldest first
+	EventsAfter(ctx context.Context, clientID, afterSeq string, limit int64) ([]*domain.OrderEvent, error)
+}
@@ -0,0 +1,18 @@
+package port
+
+import "context"
+
+// BrokerMessage is one message published to a broker topic
+type BrokerMessage struct {
+	// Key picks the partition on brokers that partition topics, so messages of one key keep their order
+	Key   string
+	Value []byte
+}
+
+// EventPublisher sends messages to a message broker (a Kafka topic, a NATS subject) for consumers
+// outside the process
+type EventPublisher interface {
+	// Publish sends msgs to topic in order and returns once the broker has accepted all of them
+	Publish(ctx context.Context, topic string, msgs []BrokerMessage) error
+	Close() error
+}
@@ -0,0 +1,21 @@
+package port
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// LiveState is the part of every tenant's state the write path works on: the orders that are
+// pending or on the book, the account balances and the holds of those orders, each by tenant
+type LiveState struct {
+	Orders   map[string][]domain.Order
+	Accounts map[string][]domain.Account
+	Holds    map[string][]domain.Hold
+}
+
+// LiveStateLoader reads the live state of all tenants at once, for a repository that keeps the
+// write path in memory to start from
+type LiveStateLoader interface {
+	LoadLiveState(ctx context.Context) (*LiveState, error)
+}
@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// ObjectStore is a bucket of immutable blobs used for exports and disaster recovery
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, data []byte) error
+	// GetObject returns nil data when the key does not exist
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	// ListObjects returns up to limit objects under prefix in key order, starting after the key startAfter;
+	// limit <= 0 returns all
+	ListObjects(ctx context.Context, prefix, startAfter string, limit int) ([]domain.StoredObject, error)
+}
@@ -0,0 +1,1462 @@
+// Package porttest checks that an implementation of port.Repository and port.Tx keeps the contract
+// the engine relies on: price-time ordering, locking, errors for rows that are not there, and reads
+// returning what was written. Every adapter runs the same suite, so they cannot drift apart.
+package porttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/olyamironova/exchange-engine/internal/tenant"
+	"github.com/shopspring/decimal"
+)
+
+const symbol = "BTC/USD"
+
+// TestRepository runs the suite against the repositories returned by newRepo. Each test works in a
+// tenant of its own, so newRepo may return the same repository, e.g. one backed by a shared database.
+func TestRepository(t *testing.T, newRepo func(t *testing.T) port.Repository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, f *fixture)
+	}{
+		{"candidates in price-time priority", testCandidateOrder},
+		{"candidates resume after key", testCandidateBatches},
+		{"only resting orders are candidates", testRestingCandidates},
+		{"top of book", testTopOfBook},
+		{"snapshot round trip", testSnapshotRoundTrip},
+		{"terminal orders leave the book", testTerminalOrders},
+		{"writes apply on commit", testCommitRollback},
+		{"locked candidates are skipped", testSkipLocked},
+		{"symbol lock serializes transactions", testSymbolLock},
+		{"locked order read waits for commit", testOrderLockWait},
+		{"cancel and modify need a resting order", testRowErrors},
+		{"modified orders lose time priority", testModifyPriority},
+		{"bulk cancels", testBulkCancels},
+		{"session cancels", testSessionCancels},
+		{"group cancels", testGroupCancels},
+		{"stop orders wait off the book", testTriggerOrders},
+		{"trades of an order page in time order", testTradePages},
+		{"trades of a symbol page in a time range", testSymbolTrades},
+		{"orders of a client page in creation order", testOrderPages},
+		{"tenants are isolated", testTenantIsolation},
+		{"archive and purge", testArchivePurge},
+		{"delistings", testDelistings},
+		{"instruments", testInstruments},
+		{"notification preferences", testNotificationPreferences},
+		{"trades keep clients, liquidity, fees and taker type", testTradeFields},
+		{"surveillance alerts", testAlerts},
+		{"nanosecond timestamps and sequences", testNanoseconds},
+		{"order histories", testTransitions},
+		{"order tags and user data", testTags},
+		{"client order IDs", testClientOrderIDs},
+		{"ledger", testLedger},
+		{"accounts", testAccounts},
+		{"candles", testCandles},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tenant.With(context.Background(), "porttest-"+uuid.NewString())
+			tt.run(t, &fixture{t: t, ctx: ctx, r: newRepo(t), at: time.Now().UTC().Truncate(time.Microsecond).Add(-time.Hour)})
+		})
+	}
+}
+
+// fixture saves orders with increasing creation times, so the order of saving is time priority
+type fixture struct {
+	t   *testing.T
+	ctx context.Context
+	r   port.Repository
+	at  time.Time
+}
+
+func (f *fixture) tick() time.Time {
+	f.at = f.at.Add(time.Millisecond)
+	return f.at
+}
+
+func (f *fixture) order(clientID string, side domain.Side, price string, status domain.OrderStatus) *domain.Order {
+	f.t.Helper()
+	return f.orderAt(clientID, side, price, status, f.tick())
+}
+
+func (f *fixture) orderAt(clientID string, side domain.Side, price string, status domain.OrderStatus, at time.Time) *domain.Order {
+	f.t.Helper()
+	o := &domain.Order{
+		ID: uuid.NewString(), ClientID: clientID, Symbol: symbol, Side: side, Type: domain.Limit,
+		Price: decimal.RequireFromString(price), Quantity: decimal.NewFromInt(2), Remaining: decimal.NewFromInt(2),
+		Status: status, CreatedAt: at,
+	}
+	switch status {
+	case domain.PartiallyFilled:
+		o.Remaining = decimal.NewFromInt(1)
+	case domain.Filled, domain.Cancelled:
+		o.Remaining = decimal.Zero
+	}
+	if err := f.r.SaveOrder(f.ctx, o); err != nil {
+		f.t.Fatalf("save order: %v", err)
+	}
+	return o
+}
+
+func (f *fixture) trade(buy, sell *domain.Order, at time.Time) *domain.Trade {
+	f.t.Helper()
+	tr := &domain.Trade{
+		ID: uuid.NewString(), Symbol: symbol, BuyOrder: buy.ID, SellOrder: sell.ID,
+		Price: sell.Price, Quantity: decimal.NewFromInt(1), Timestamp: at, Sequence: uint64(at.UnixNano()),
+		MakerFee: decimal.Zero, TakerFee: decimal.Zero, TakerSide: domain.Buy, TakerType: domain.Limit,
+		BuyClient: buy.ClientID, SellClient: sell.ClientID, BuyLiquidity: domain.Taker, SellLiquidity: domain.Maker,
+	}
+	if err := f.r.SaveTrade(f.ctx, tr); err != nil {
+		f.t.Fatalf("save trade: %v", err)
+	}
+	return tr
+}
+
+func (f *fixture) begin() port.Tx {
+	f.t.Helper()
+	tx, err := f.r.BeginTx(f.ctx)
+	if err != nil {
+		f.t.Fatalf("begin: %v", err)
+	}
+	f.t.Cleanup(func() { tx.Rollback(context.Background()) })
+	return tx
+}
+
+func (f *fixture) candidates(tx port.Tx, side domain.Side, limitPrice string, after *domain.BookKey, limit int) []*domain.Order {
+	f.t.Helper()
+	var lp *decimal.Decimal
+	if limitPrice != "" {
+		p := decimal.RequireFromString(limitPrice)
+		lp = &p
+	}
+	cands, err := tx.LoadCandidatesForMatch(f.ctx, symbol, side, lp, after, limit)
+	if err != nil {
+		f.t.Fatalf("load candidates: %v", err)
+	}
+	return cands
+}
+
+func wantIDs[T any](t *testing.T, what string, got []T, id func(T) string, want ...*domain.Order) {
+	t.Helper()
+	ids := make([]string, len(got))
+	for i, g := range got {
+		ids[i] = id(g)
+	}
+	ok := len(ids) == len(want)
+	for i := 0; ok && i < len(want); i++ {
+		ok = ids[i] == want[i].ID
+	}
+	if !ok {
+		wantIDs := make([]string, len(want))
+		for i, w := range want {
+			wantIDs[i] = w.ID
+		}
+		t.Errorf("%s: got %v, want %v", what, ids, wantIDs)
+	}
+}
+
+func keyOf(o *domain.Order) *domain.BookKey {
+	k := o.Key()
+	return &k
+}
+
+func orderID(o *domain.Order) string { return o.ID }
+func valueID(o domain.Order) string  { return o.ID }
+
+func testCandidateOrder(t *testing.T, f *fixture) {
+	a101 := f.order("m", domain.Sell, "101", domain.Open)
+	a100old := f.order("m", domain.Sell, "100", domain.Open)
+	at := f.tick()
+	tieA := f.orderAt("m", domain.Sell, "100", domain.Open, at)
+	tieB := f.orderAt("m", domain.Sell, "100", domain.Open, at)
+	if tieB.ID < tieA.ID {
+		tieA, tieB = tieB, tieA
+	}
+	a102 := f.order("m", domain.Sell, "102", domain.Open)
+	b99 := f.order("m", domain.Buy, "99", domain.Open)
+	b98old := f.order("m", domain.Buy, "98", domain.Open)
+	b98 := f.order("m", domain.Buy, "98", domain.Open)
+
+	tx := f.begin()
+	wantIDs(t, "buyer without limit", f.candidates(tx, domain.Buy, "", nil, 10), orderID, a100old, tieA, tieB, a101, a102)
+	wantIDs(t, "buyer limited to 101", f.candidates(tx, domain.Buy, "101", nil, 10), orderID, a100old, tieA, tieB, a101)
+	wantIDs(t, "seller limited to 98", f.candidates(tx, domain.Sell, "98", nil, 10), orderID, b99, b98old, b98)
+	wantIDs(t, "seller limited to 99", f.candidates(tx, domain.Sell, "99", nil, 10), orderID, b99)
+}
+
+func testCandidateBatches(t *testing.T, f *fixture) {
+	a100 := f.order("m", domain.Sell, "100", domain.Open)
+	a101old := f.order("m", domain.Sell, "101", domain.Open)
+	a101 := f.order("m", domain.Sell, "101", domain.Open)
+	a102 := f.order("m", domain.Sell, "102", domain.Open)
+	b99 := f.order("m", domain.Buy, "99", domain.Open)
+	b98 := f.order("m", domain.Buy, "98", domain.Open)
+
+	tx := f.begin()
+	first := f.candidates(tx, domain.Buy, "", nil, 2)
+	wantIDs(t, "first batch", first, orderID, a100, a101old)
+	wantIDs(t, "second batch", f.candidates(tx, domain.Buy, "", keyOf(first[1]), 2), orderID, a101, a102)
+	wantIDs(t, "past the end", f.candidates(tx, domain.Buy, "", keyOf(a102), 2), orderID)
+	wantIDs(t, "bids after the best", f.candidates(tx, domain.Sell, "", keyOf(b99), 2), orderID, b98)
+}
+
+func testRestingCandidates(t *testing.T, f *fixture) {
+	open := f.order("m", domain.Sell, "100", domain.Open)
+	partial := f.order("m", domain.Sell, "100", domain.PartiallyFilled)
+	f.order("m", domain.Sell, "100", domain.Filled)
+	f.order("m", domain.Sell, "100", domain.Cancelled)
+	other := &domain.Order{
+		ID: uuid.NewString(), ClientID: "m", Symbol: "ETH/USD", Side: domain.Sell, Type: domain.Limit,
+		Price: open.Price, Quantity: open.Quantity, Remaining: open.Remaining, Status: domain.Open, CreatedAt: f.tick(),
+	}
+	if err := f.r.SaveOrder(f.ctx, other); err != nil {
+		t.Fatalf("save order: %v", err)
+	}
+
+	tx := f.begin()
+	cands := f.candidates(tx, domain.Buy, "", nil, 10)
+	wantIDs(t, "candidates", cands, orderID, open, partial)
+	if len(cands) == 2 && !cands[1].Remaining.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("partially filled candidate has %s remaining, want 1", cands[1].Remaining)
+	}
+}
+
+func testTopOfBook(t *testing.T, f *fixture) {
+	top, err := f.r.LoadTopOfBook(f.ctx, symbol)
+	if err != nil {
+		t.Fatalf("top of empty book: %v", err)
+	}
+	if len(top.Bids) != 0 || len(top.Asks) != 0 {
+		t.Errorf("empty book has top %v / %v", top.Bids, top.Asks)
+	}
+
+	f.order("m", domain.Buy, "98", domain.Open)
+	bid := f.order("m", domain.Buy, "99", domain.Open)
+	next := f.order("m", domain.Buy, "99", domain.Open)
+	f.order("m", domain.Buy, "100", domain.Filled)
+	ask := f.order("m", domain.Sell, "101", domain.Open)
+	f.order("m", domain.Sell, "102", domain.Open)
+
+	top, err = f.r.LoadTopOfBook(f.ctx, symbol)
+	if err != nil {
+		t.Fatalf("top of book: %v", err)
+	}
+	wantIDs(t, "best bid", top.Bids, valueID, bid, next)
+	wantIDs(t, "best ask", top.Asks, valueID, ask)
+}
+
+func testSnapshotRoundTrip(t *testing.T, f *fixture) {
+	bid := f.order("b", domain.Buy, "99.5", domain.Open)
+	ask := f.order("s", domain.Sell, "100.25", domain.PartiallyFilled)
+	f.order("s", domain.Sell, "100", domain.Filled)
+
+	snap, err := f.r.LoadSnapshot(f.ctx, symbol)
+	if err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+	if snap.Symbol != symbol {
+		t.Errorf("snapshot of %q, want %q", snap.Symbol, symbol)
+	}
+	wantIDs(t, "bids", snap.Bids, valueID, bid)
+	wantIDs(t, "asks", snap.Asks, valueID, ask)
+	for _, pair := range [][2]*domain.Order{{bid, first(snap.Bids)}, {ask, first(snap.Asks)}} {
+		want, got := pair[0], pair[1]
+		if got == nil {
+			continue
+		}
+		if got.ClientID != want.ClientID || got.Side != want.Side || got.Type != want.Type || got.Status != want.Status ||
+			!got.Price.Equal(want.Price) || !got.Quantity.Equal(want.Quantity) || !got.Remaining.Equal(want.Remaining) ||
+			!got.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("order read back as %+v, saved %+v", *got, *want)
+		}
+	}
+}
+
+func first(orders []domain.Order) *domain.Order {
+	if len(orders) == 0 {
+		return nil
+	}
+	return &orders[0]
+}
+
+func testTerminalOrders(t *testing.T, f *fixture) {
+	o := f.order("m", domain.Sell, "100", domain.Open)
+	o.Status, o.Remaining = domain.Filled, decimal.Zero
+	if err := f.r.SaveOrder(f.ctx, o); err != nil {
+		t.Fatalf("save filled order: %v", err)
+	}
+
+	open, err := f.r.LoadOpenOrders(f.ctx, symbol)
+	if err != nil {
+		t.Fatalf("load open orders: %v", err)
+	}
+	wantIDs(t, "open orders", open, orderID)
+	got, err := f.r.LoadOrderByID(f.ctx, o.ID)
+	if err != nil {
+		t.Fatalf("load filled order: %v", err)
+	}
+	if got.Status != domain.Filled || !got.Remaining.IsZero() {
+		t.Errorf("filled order read back as %s with %s remaining", got.Status, got.Remaining)
+	}
+	if _, err := f.r.LoadOrderByIDForClient(f.ctx, o.ID, "m"); err != nil {
+		t.Errorf("load filled order for its client: %v", err)
+	}
+}
+
+func testCommitRollback(t *testing.T, f *fixture) {
+	maker := f.order("m", domain.Sell, "100", domain.Open)
+	taker := &domain.Order{
+		ID: uuid.NewString(), ClientID: "t", Symbol: symbol, Side: domain.Buy, Type: domain.Limit,
+		Price: maker.Price, Quantity: decimal.NewFromInt(1), Remaining: decimal.Zero, Status: domain.Filled, CreatedAt: f.tick(),
+	}
+	trade := &domain.Trade{
+		ID: uuid.NewString(), Symbol: symbol, BuyOrder: taker.ID, SellOrder: maker.ID, Price: maker.Price,
+		Quantity: decimal.NewFromInt(1), Timestamp: f.tick(), TakerSide: domain.Buy,
+	}
+	write := func(tx port.Tx) {
+		t.Helper()
+		if err := tx.SaveOrder(f.ctx, taker); err != nil {
+			t.Fatalf("save order: %v", err)
+		}
+		if err := tx.SaveTrade(f.ctx, trade); err != nil {
+			t.Fatalf("save trade: %v", err)
+		}
+		if got, err := tx.LoadOrderByIDForClient(f.ctx, taker.ID, "t"); err != nil || got.Status != domain.Filled {
+			t.Errorf("transaction does not read its own write: %v, %v", got, err)
+		}
+	}
+
+	tx := f.begin()
+	write(tx)
+	if err := tx.Rollback(f.ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if _, err := f.r.LoadOrderByID(f.ctx, taker.ID); err == nil {
+		t.Error("rolled back order is visible")
+	}
+	if trades, _ := f.r.LoadTradesForOrder(f.ctx, maker.ID, nil, 0); len(trades) != 0 {
+		t.Errorf("rolled back trade is visible: %d trades", len(trades))
+	}
+
+	tx = f.begin()
+	write(tx)
+	if err := tx.Commit(f.ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if _, err := f.r.LoadOrderByID(f.ctx, taker.ID); err != nil {
+		t.Errorf("committed order: %v", err)
+	}
+	trades, err := f.r.LoadTradesForOrder(f.ctx, maker.ID, nil, 0)
+	if err != nil || len(trades) != 1 || trades[0].ID != trade.ID {
+		t.Errorf("committed trades %v, %v, want %s", trades, err, trade.ID)
+	}
+}
+
+func testSkipLocked(t *testing.T, f *fixture) {
+	a := f.order("m", domain.Sell, "100", domain.Open)
+	b := f.order("m", domain.Sell, "101", domain.Open)
+
+	holder := f.begin()
+	wantIDs(t, "holder", f.candidates(holder, domain.Buy, "100", nil, 10), orderID, a)
+	other := f.begin()
+	wantIDs(t, "while held", f.candidates(other, domain.Buy, "", nil, 10), orderID, b)
+	if err := holder.Rollback(f.ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	wantIDs(t, "after release", f.candidates(other, domain.Buy, "", nil, 10), orderID, a, b)
+}
+
+func testSymbolLock(t *testing.T, f *fixture) {
+	holder := f.begin()
+	if err := holder.LockSymbol(f.ctx, symbol); err != nil {
+		t.Fatalf("lock symbol: %v", err)
+	}
+
+	other := f.begin()
+	if err := other.LockSymbol(f.ctx, "ETH/USD"); err != nil {
+		t.Errorf("lock another symbol: %v", err)
+	}
+	waiting := f.begin()
+	ctx, cancel := context.WithTimeout(f.ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := waiting.LockSymbol(ctx, symbol); err == nil {
+		t.Error("second transaction locked a held symbol")
+	}
+
+	if err := holder.Commit(f.ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	next := f.begin()
+	if err := next.LockSymbol(f.ctx, symbol); err != nil {
+		t.Errorf("lock released symbol: %v", err)
+	}
+}
+
+func testOrderLockWait(t *testing.T, f *fixture) {
+	o := f.order("m", domain.Sell, "100", domain.Open)
+
+	holder := f.begin()
+	if _, err := holder.LoadOrderByIDForClient(f.ctx, o.ID, "m"); err != nil {
+		t.Fatalf("lock order: %v", err)
+	}
+	price, qty := decimal.NewFromInt(105), decimal.NewFromInt(3)
+	if err := holder.ModifyOrder(f.ctx, o.ID, "m", &price, &qty, f.tick()); err != nil {
+		t.Fatalf("modify order: %v", err)
+	}
+
+	waiter := f.begin()
+	read := make(chan *domain.Order, 1)
+	go func() {
+		got, err := waiter.LoadOrderByIDForClient(f.ctx, o.ID, "m")
+		if err != nil {
+			t.Errorf("wait for order: %v", err)
+		}
+		read <- got
+	}()
+	select {
+	case <-read:
+		t.Fatal("order read did not wait for the transaction holding it")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := holder.Commit(f.ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	select {
+	case got := <-read:
+		if got != nil && !got.Price.Equal(price) {
+			t.Errorf("waiter read price %s, want the committed %s", got.Price, price)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("order read still waiting after commit")
+	}
+}
+
+func testRowErrors(t *testing.T, f *fixture) {
+	open := f.order("m", domain.Sell, "100", domain.Open)
+	partial := f.order("m", domain.Sell, "100", domain.PartiallyFilled)
+	filled := f.order("m", domain.Sell, "100", domain.Filled)
+	price, qty := decimal.NewFromInt(99), decimal.NewFromInt(1)
+
+	if err := f.r.CancelOrder(f.ctx, uuid.NewString(), "m"); err == nil {
+		t.Error("cancelled an unknown order")
+	}
+	if err := f.r.CancelOrder(f.ctx, open.ID, "someone else"); err == nil {
+		t.Error("cancelled another client's order")
+	}
+	if err := f.r.CancelOrder(f.ctx, filled.ID, "m"); err == nil {
+		t.Error("cancelled a filled order")
+	}
+	if err := f.r.ModifyOrder(f.ctx, filled.ID, "m", price, qty); err == nil {
+		t.Error("modified a filled order")
+	}
+	if err := f.r.ModifyOrder(f.ctx, open.ID, "someone else", price, qty); err == nil {
+		t.Error("modified another client's order")
+	}
+	if _, err := f.r.LoadOrderByIDForClient(f.ctx, open.ID, "someone else"); err == nil {
+		t.Error("loaded another client's order")
+	}
+	if _, err := f.r.LoadOrderByID(f.ctx, uuid.NewString()); err == nil {
+		t.Error("loaded an unknown order")
+	}
+
+	if err := f.r.ModifyOrder(f.ctx, open.ID, "m", price, qty); err != nil {
+		t.Fatalf("modify open order: %v", err)
+	}
+	if got, _ := f.r.LoadOrderByID(f.ctx, open.ID); got == nil || !got.Price.Equal(price) || !got.Remaining.Equal(qty) {
+		t.Errorf("modified order read back as %v", got)
+	}
+	// a partially filled order keeps what filled: 1 of the new 3 is done, 2 remain
+	if err := f.r.ModifyOrder(f.ctx, partial.ID, "m", price, decimal.NewFromInt(3)); err != nil {
+		t.Fatalf("modify partially filled order: %v", err)
+	}
+	if got, _ := f.r.LoadOrderByID(f.ctx, partial.ID); got == nil || !got.Quantity.Equal(decimal.NewFromInt(3)) || !got.Remaining.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("modified partially filled order read back as %v", got)
+	}
+	if err := f.r.CancelOrder(f.ctx, partial.ID, "m"); err != nil {
+		t.Fatalf("cancel partially filled order: %v", err)
+	}
+	if err := f.r.CancelOrder(f.ctx, partial.ID, "m"); err == nil {
+		t.Error("cancelled an order twice")
+	}
+	if got, _ := f.r.LoadOrderByID(f.ctx, partial.ID); got == nil || got.Status != domain.Cancelled || !got.Remaining.IsZero() {
+		t.Errorf("cancelled order read back as %v", got)
+	}
+
+	tx := f.begin()
+	if err := tx.CancelOrder(f.ctx, filled.ID, "m"); err == nil {
+		t.Error("transaction cancelled a filled order")
+	}
+	tx = f.begin()
+	if err := tx.ModifyOrder(f.ctx, filled.ID, "m", &price, &qty, f.tick()); err == nil {
+		t.Error("transaction modified a filled order")
+	}
+}
+
+func testModifyPriority(t *testing.T, f *fixture) {
+	first := f.order("m", domain.Sell, "100", domain.Open)
+	second := f.order("m", domain.Sell, "100", domain.Open)
+	price, qty := decimal.NewFromInt(100), decimal.NewFromInt(3)
+	at := f.tick()
+
+	tx := f.begin()
+	if err := tx.ModifyOrder(f.ctx, first.ID, "m", &price, &qty, at); err != nil {
+		t.Fatalf("modify: %v", err)
+	}
+	if err := tx.Commit(f.ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	got, err := f.r.LoadOrderByID(f.ctx, first.ID)
+	if err != nil || !got.CreatedAt.Equal(at) || !got.Remaining.Equal(qty) {
+		t.Errorf("modified order read back as %v, %v, want created %s with %s remaining", got, err, at, qty)
+	}
+	wantIDs(t, "candidates", f.candidates(f.begin(), domain.Buy, "", nil, 10), orderID, second, first)
+}
+
+func testClientOrderIDs(t *testing.T, f *fixture) {
+	save := func(clientID, clOrdID string, status domain.OrderStatus) (*domain.Order, error) {
+		o := &domain.Order{
+			ID: uuid.NewString(), ClientID: clientID, ClientOrderID: clOrdID, Symbol: symbol, Side: domain.Buy, Type: domain.Limit,
+			Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1), Remaining: decimal.NewFromInt(1),
+			Status: status, CreatedAt: f.tick(),
+		}
+		return o, f.r.SaveOrder(f.ctx, o)
+	}
+	done, err := save("c", "a", domain.Filled)
+	if err != nil {
+		t.Fatalf("save a done order: %v", err)
+	}
+	open, err := save("c", "a", domain.Open)
+	if err != nil {
+		t.Fatalf("reuse the ID of a done order: %v", err)
+	}
+	if _, err := save("c", "a", domain.Open); !errors.Is(err, port.ErrDuplicateClientOrderID) {
+		t.Errorf("second open order with the ID: %v, want ErrDuplicateClientOrderID", err)
+	}
+	if _, err := save("other", "a", domain.Open); err != nil {
+		t.Errorf("another client's order with the ID: %v", err)
+	}
+	if got, err := f.r.LoadOrderByClientOrderID(f.ctx, "c", "a"); err != nil || got.ID != open.ID || got.ClientOrderID != "a" {
+		t.Errorf("lookup read back %v, %v, want the open order %s", got, err, open.ID)
+	}
+
+	open.Status, open.Remaining, open.UpdatedAt = domain.Cancelled, decimal.Zero, f.tick()
+	if err := f.r.SaveOrder(f.ctx, open); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if got, err := f.r.LoadOrderByClientOrderID(f.ctx, "c", "a"); err != nil || got.ID != open.ID {
+		t.Errorf("lookup of done orders read back %v, %v, want the latest %s, not %s", got, err, open.ID, done.ID)
+	}
+	if _, err := f.r.LoadOrderByClientOrderID(f.ctx, "c", "missing"); err == nil {
+		t.Error("lookup of an unknown ID succeeded")
+	}
+}
+
+func testBulkCancels(t *testing.T, f *fixture) {
+	mine := f.order("c", domain.Sell, "100", domain.Open)
+	filled := f.order("c", domain.Sell, "100", domain.Filled)
+	theirs := f.order("other", domain.Sell, "100", domain.Open)
+
+	cancelled, err := f.r.CancelOrders(f.ctx, "c", []string{mine.ID, filled.ID, theirs.ID, uuid.NewString()})
+	if err != nil {
+		t.Fatalf("cancel orders: %v", err)
+	}
+	if len(cancelled) != 1 || cancelled[mine.ID] != symbol {
+		t.Errorf("cancelled %v, want only %s", cancelled, mine.ID)
+	}
+
+	bid := f.order("c", domain.Buy, "90", domain.Open)
+	ask := f.order("c", domain.Sell, "110", domain.Open)
+	cancelled, err = f.r.CancelOpenOrders(f.ctx, "c", symbol, domain.Buy)
+	if err != nil {
+		t.Fatalf("cancel bids: %v", err)
+	}
+	if len(cancelled) != 1 || cancelled[bid.ID] != symbol {
+		t.Errorf("cancelled bids %v, want %s", cancelled, bid.ID)
+	}
+	cancelled, err = f.r.CancelOpenOrders(f.ctx, "c", "", "")
+	if err != nil {
+		t.Fatalf("cancel all: %v", err)
+	}
+	if len(cancelled) != 1 || cancelled[ask.ID] != symbol {
+		t.Errorf("cancelled %v, want %s", cancelled, ask.ID)
+	}
+	if got, _ := f.r.LoadOrderByID(f.ctx, theirs.ID); got == nil || got.Status != domain.Open {
+		t.Errorf("another client's order read back as %v", got)
+	}
+}
+
+func testGroupCancels(t *testing.T, f *fixture) {
+	inGroup := func(clientID, groupID string, status domain.OrderStatus) *domain.Order {
+		t.Helper()
+		o := f.order(clientID, domain.Sell, "110", status)
+		o.GroupID, o.CancelGroupOnFill = groupID, groupID != ""
+		if err := f.r.SaveOrder(f.ctx, o); err != nil {
+			t.Fatalf("save order: %v", err)
+		}
+		return o
+	}
+	open := inGroup("c", "g1", domain.Open)
+	partial := inGroup("c", "g1", domain.PartiallyFilled)
+	filled := inGroup("c", "g1", domain.Filled)
+	otherGroup := inGroup("c", "g2", domain.Open)
+	noGroup := inGroup("c", "", domain.Open)
+	otherClient := inGroup("other", "g1", domain.Open)
+	otherSymbol := &domain.Order{ID: uuid.NewString(), ClientID: "c", Symbol: "ETH/USD", Side: domain.Sell, Type: domain.Limit,
+		Price: decimal.NewFromInt(110), Quantity: decimal.NewFromInt(2), Remaining: decimal.NewFromInt(2), Status: domain.Open,
+		CreatedAt: f.tick(), GroupID: "g1", CancelGroupOnFill: true}
+	if err := f.r.SaveOrder(f.ctx, otherSymbol); err != nil {
+		t.Fatalf("save order: %v", err)
+	}
+
+	cancelled, err := f.r.CancelGroupOrders(f.ctx, "c", "g1", "ETH/USD")
+	if err != nil || len(cancelled) != 1 || cancelled[otherSymbol.ID] != "ETH/USD" {
+		t.Errorf("cancelled %v, %v for one symbol, want only %s", cancelled, err, otherSymbol.ID)
+	}
+	cancelled, err = f.r.CancelGroupOrders(f.ctx, "c", "g1", "")
+	if err != nil {
+		t.Fatalf("cancel group orders: %v", err)
+	}
+	if len(cancelled) != 2 || cancelled[open.ID] != symbol || cancelled[partial.ID] != symbol {
+		t.Errorf("cancelled %v, want %s and %s", cancelled, open.ID, partial.ID)
+	}
+	got, err := f.r.LoadOrderByID(f.ctx, open.ID)
+	if err != nil || got.Status != domain.Cancelled || got.GroupID != "g1" || !got.CancelGroupOnFill {
+		t.Errorf("cancelled order read back as %+v, %v", got, err)
+	}
+	if got, _ := f.r.LoadOrderByID(f.ctx, filled.ID); got == nil || got.Status != domain.Filled {
+		t.Errorf("filled order read back as %v", got)
+	}
+	for _, o := range []*domain.Order{otherGroup, noGroup, otherClient} {
+		if got, _ := f.r.LoadOrderByID(f.ctx, o.ID); got == nil || got.Status != domain.Open || got.GroupID != o.GroupID {
+			t.Errorf("order %s of group %q read back as %+v", o.ID, o.GroupID, got)
+		}
+	}
+	if cancelled, err := f.r.CancelGroupOrders(f.ctx, "c", "", ""); err != nil || len(cancelled) != 0 {
+		t.Errorf("cancelling orders without a group: %v, %v", cancelled, err)
+	}
+}
+
+func testTriggerOrders(t *testing.T, f *fixture) {
+	stop := func(side domain.Side, trigger string) *domain.Order {
+		t.Helper()
+		o := &domain.Order{
+			ID: uuid.NewString(), ClientID: "c", Symbol: symbol, Side: side, Type: domain.Stop,
+			Quantity: decimal.NewFromInt(2), Remaining: decimal.NewFromInt(2), Status: domain.Pending,
+			TriggerPrice: decimal.RequireFromString(trigger), CreatedAt: f.tick(),
+		}
+		if err := f.r.SaveOrder(f.ctx, o); err != nil {
+			t.Fatalf("save order: %v", err)
+		}
+		return o
+	}
+	bid := f.order("m", domain.Buy, "100", domain.Open)
+	buyStop := stop(domain.Buy, "105")
+	sellStop := stop(domain.Sell, "95")
+	farSellStop := stop(domain.Sell, "90")
+
+	all, err := f.r.LoadTriggerOrders(f.ctx, symbol, nil)
+	if err != nil {
+		t.Fatalf("load trigger orders: %v", err)
+	}
+	wantIDs(t, "trigger book", all, orderID, buyStop, sellStop, farSellStop)
+	if len(all) > 0 && (!all[0].TriggerPrice.Equal(decimal.NewFromInt(105)) || all[0].Type != domain.Stop) {
+		t.Errorf("stop read back as %+v", all[0])
+	}
+	crossed := func(low, high string) []*domain.Order {
+		t.Helper()
+		r := domain.PriceRange{Low: decimal.RequireFromString(low), High: decimal.RequireFromString(high)}
+		got, err := f.r.LoadTriggerOrders(f.ctx, symbol, &r)
+		if err != nil {
+			t.Fatalf("load trigger orders: %v", err)
+		}
+		return got
+	}
+	wantIDs(t, "triggered inside the stops", crossed("96", "104"), orderID)
+	wantIDs(t, "triggered at the triggers", crossed("95", "105"), orderID, buyStop, sellStop)
+	wantIDs(t, "triggered through every stop", crossed("80", "120"), orderID, buyStop, sellStop, farSellStop)
+
+	snap, err := f.r.LoadSnapshot(f.ctx, symbol)
+	if err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+	if len(snap.Bids) != 1 || snap.Bids[0].ID != bid.ID || len(snap.Asks) != 0 {
+		t.Errorf("book holds %d bids and %d asks, want the resting bid only", len(snap.Bids), len(snap.Asks))
+	}
+	tx := f.begin()
+	wantIDs(t, "candidates", f.candidates(tx, domain.Buy, "", nil, 10), orderID)
+	if err := tx.Rollback(f.ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+
+	if err := f.r.CancelOrder(f.ctx, sellStop.ID, "c"); err != nil {
+		t.Fatalf("cancel pending stop: %v", err)
+	}
+	ids, err := f.r.CancelOpenOrders(f.ctx, "c", symbol, "")
+	if err != nil || len(ids) != 2 {
+		t.Errorf("cancel open orders cancelled %v, %v; want both remaining stops", ids, err)
+	}
+	if left, _ := f.r.LoadTriggerOrders(f.ctx, symbol, nil); len(left) != 0 {
+		t.Errorf("%d stops left after cancelling them", len(left))
+	}
+}
+
+func testSessionCancels(t *testing.T, f *fixture) {
+	inSession := func(clientID, sessionID string, status domain.OrderStatus) *domain.Order {
+		t.Helper()
+		o := f.order(clientID, domain.Buy, "90", status)
+		o.SessionID = sessionID
+		if err := f.r.SaveOrder(f.ctx, o); err != nil {
+			t.Fatalf("save order: %v", err)
+		}
+		return o
+	}
+	open := inSession("c", "s1", domain.Open)
+	partial := inSession("c", "s1", domain.PartiallyFilled)
+	filled := inSession("c", "s1", domain.Filled)
+	otherSession := inSession("c", "s2", domain.Open)
+	noSession := f.order("c", domain.Buy, "90", domain.Open)
+	otherClient := inSession("other", "s1", domain.Open)
+	otherSymbol := &domain.Order{ID: uuid.NewString(), ClientID: "c", Symbol: "ETH/USD", Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(90), Quantity: decimal.NewFromInt(2), Remaining: decimal.NewFromInt(2), Status: domain.Open,
+		CreatedAt: f.tick(), SessionID: "s1"}
+	if err := f.r.SaveOrder(f.ctx, otherSymbol); err != nil {
+		t.Fatalf("save order: %v", err)
+	}
+
+	cancelled, err := f.r.CancelSessionOrders(f.ctx, "c", "s1", "ETH/USD")
+	if err != nil || len(cancelled) != 1 || cancelled[otherSymbol.ID] != "ETH/USD" {
+		t.Errorf("cancelled %v, %v for one symbol, want only %s", cancelled, err, otherSymbol.ID)
+	}
+	cancelled, err = f.r.CancelSessionOrders(f.ctx, "c", "s1", "")
+	if err != nil {
+		t.Fatalf("cancel session orders: %v", err)
+	}
+	if len(cancelled) != 2 || cancelled[open.ID] != symbol || cancelled[partial.ID] != symbol {
+		t.Errorf("cancelled %v, want %s and %s", cancelled, open.ID, partial.ID)
+	}
+	got, err := f.r.LoadOrderByID(f.ctx, partial.ID)
+	if err != nil || got.Status != domain.Cancelled || got.SessionID != "s1" {
+		t.Errorf("cancelled order read back as %+v, %v", got, err)
+	}
+	if got, _ := f.r.LoadOrderByID(f.ctx, filled.ID); got == nil || got.Status != domain.Filled {
+		t.Errorf("filled order read back as %v", got)
+	}
+	for _, o := range []*domain.Order{otherSession, noSession, otherClient} {
+		if got, _ := f.r.LoadOrderByID(f.ctx, o.ID); got == nil || got.Status != domain.Open || got.SessionID != o.SessionID {
+			t.Errorf("order %s of session %q read back as %+v", o.ID, o.SessionID, got)
+		}
+	}
+	if cancelled, err := f.r.CancelSessionOrders(f.ctx, "c", "", ""); err != nil || len(cancelled) != 0 {
+		t.Errorf("cancelling orders without a session: %v, %v", cancelled, err)
+	}
+}
+
+func testTradePages(t *testing.T, f *fixture) {
+	buy := f.order("b", domain.Buy, "100", domain.PartiallyFilled)
+	sell := f.order("s", domain.Sell, "100", domain.PartiallyFilled)
+	at := f.tick()
+	t2 := f.trade(buy, sell, at.Add(time.Second))
+	t1 := f.trade(buy, sell, at)
+	t3 := f.trade(buy, sell, at.Add(2*time.Second))
+
+	all, err := f.r.LoadTradesForOrder(f.ctx, sell.ID, nil, 0)
+	if err != nil {
+		t.Fatalf("load trades: %v", err)
+	}
+	tradeIDs := func(trades []*domain.Trade) []string {
+		ids := make([]string, len(trades))
+		for i, tr := range trades {
+			ids[i] = tr.ID
+		}
+		return ids
+	}
+	if got := tradeIDs(all); len(got) != 3 || got[0] != t1.ID || got[1] != t2.ID || got[2] != t3.ID {
+		t.Errorf("trades %v, want %v", got, []string{t1.ID, t2.ID, t3.ID})
+	}
+
+	head, err := f.r.LoadTradesForOrder(f.ctx, buy.ID, nil, 2)
+	if err != nil || len(head) != 2 {
+		t.Fatalf("first page: %d trades, %v", len(head), err)
+	}
+	last := head[1]
+	tail, err := f.r.LoadTradesForOrder(f.ctx, buy.ID, &page.Key{At: last.Timestamp, ID: last.ID}, 2)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if got := tradeIDs(tail); len(got) != 1 || got[0] != t3.ID {
+		t.Errorf("second page %v, want %s", got, t3.ID)
+	}
+}
+
+func testSymbolTrades(t *testing.T, f *fixture) {
+	buy := f.order("b", domain.Buy, "100", domain.PartiallyFilled)
+	sell := f.order("s", domain.Sell, "100", domain.PartiallyFilled)
+	at := f.tick()
+	f.trade(buy, sell, at)
+	t1 := f.trade(buy, sell, at.Add(time.Second))
+	t2 := f.trade(buy, sell, at.Add(time.Second+7*time.Nanosecond))
+	f.trade(buy, sell, at.Add(2*time.Second))
+	other := &domain.Trade{
+		ID: uuid.NewString(), Symbol: "ETH/USD", BuyOrder: buy.ID, SellOrder: sell.ID, Price: sell.Price,
+		Quantity: decimal.NewFromInt(1), Timestamp: at.Add(time.Second), TakerSide: domain.Buy,
+	}
+	if err := f.r.SaveTrade(f.ctx, other); err != nil {
+		t.Fatalf("save trade: %v", err)
+	}
+
+	from, to := at.Add(time.Second), at.Add(2*time.Second)
+	all, err := f.r.LoadTrades(f.ctx, symbol, time.Time{}, time.Time{}, nil, 0)
+	if err != nil || len(all) != 4 {
+		t.Fatalf("unbounded: %d trades, %v; want 4", len(all), err)
+	}
+	head, err := f.r.LoadTrades(f.ctx, symbol, from, to, nil, 1)
+	if err != nil || len(head) != 1 || head[0].ID != t1.ID {
+		t.Fatalf("first page %v, %v; want %s", head, err, t1.ID)
+	}
+	tail, err := f.r.LoadTrades(f.ctx, symbol, from, to, &page.Key{At: head[0].Timestamp, ID: head[0].ID}, 10)
+	if err != nil || len(tail) != 1 || tail[0].ID != t2.ID {
+		t.Errorf("second page %v, %v; want %s", tail, err, t2.ID)
+	}
+}
+
+func testOrderPages(t *testing.T, f *fixture) {
+	// two orders share a nanosecond-precise creation time, so only their IDs order them
+	at := f.tick().Add(7 * time.Nanosecond)
+	a, b := f.orderAt("c", domain.Buy, "90", domain.Open, at), f.orderAt("c", domain.Sell, "110", domain.Open, at)
+	if b.ID < a.ID {
+		a, b = b, a
+	}
+	filled := f.order("c", domain.Buy, "100", domain.Filled)
+	last := f.order("c", domain.Sell, "120", domain.Open)
+	f.order("other", domain.Buy, "90", domain.Open)
+
+	list := func(flt domain.OrderFilter, after *domain.Order, limit int) []*domain.Order {
+		t.Helper()
+		var key *page.Key
+		if after != nil {
+			key = &page.Key{At: after.CreatedAt, ID: after.ID}
+		}
+		orders, err := f.r.ListOrders(f.ctx, flt, key, limit)
+		if err != nil {
+			t.Fatalf("list orders: %v", err)
+		}
+		return orders
+	}
+	mine := domain.OrderFilter{ClientID: "c"}
+	wantIDs(t, "all", list(mine, nil, 0), orderID, a, b, filled, last)
+	head := list(mine, nil, 1)
+	wantIDs(t, "first page", head, orderID, a)
+	wantIDs(t, "second page", list(mine, head[0], 2), orderID, b, filled)
+	wantIDs(t, "open", list(domain.OrderFilter{ClientID: "c", Status: domain.Open}, a, 0), orderID, b, last)
+	wantIDs(t, "other symbol", list(domain.OrderFilter{ClientID: "c", Symbol: "ETH/USD"}, nil, 0), orderID)
+}
+
+func testTenantIsolation(t *testing.T, f *fixture) {
+	o := f.order("m", domain.Sell, "100", domain.Open)
+	other := tenant.With(context.Background(), "porttest-"+uuid.NewString())
+
+	if _, err := f.r.LoadOrderByID(other, o.ID); err == nil {
+		t.Error("another tenant loaded the order")
+	}
+	if open, _ := f.r.LoadOpenOrders(other, symbol); len(open) != 0 {
+		t.Errorf("another tenant sees %d open orders", len(open))
+	}
+	if symbols, _ := f.r.ListSymbols(other); len(symbols) != 0 {
+		t.Errorf("another tenant sees symbols %v", symbols)
+	}
+	if err := f.r.CancelOrder(other, o.ID, "m"); err == nil {
+		t.Error("another tenant cancelled the order")
+	}
+	tx, err := f.r.BeginTx(other)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+	if cands, _ := tx.LoadCandidatesForMatch(other, symbol, domain.Buy, nil, nil, 10); len(cands) != 0 {
+		t.Errorf("another tenant matched against %d orders", len(cands))
+	}
+}
+
+func testArchivePurge(t *testing.T, f *fixture) {
+	open := f.order("m", domain.Sell, "100", domain.Open)
+	done := f.order("m", domain.Buy, "100", domain.Open)
+	done.Status, done.Remaining = domain.Filled, decimal.Zero
+	if err := f.r.SaveOrder(f.ctx, done); err != nil {
+		t.Fatalf("fill order: %v", err)
+	}
+	old := f.trade(done, open, f.tick())
+	cutoff := time.Now().Add(time.Hour)
+
+	n, err := f.r.ArchiveOrders(f.ctx, cutoff, 10)
+	if err != nil || n != 1 {
+		t.Fatalf("archived %d orders, %v, want 1", n, err)
+	}
+	if n, _ := f.r.ArchiveOrders(f.ctx, cutoff, 10); n != 0 {
+		t.Errorf("archived %d orders again", n)
+	}
+	if got, err := f.r.LoadOrderByID(f.ctx, done.ID); err != nil || got.Status != domain.Filled {
+		t.Errorf("archived order read back as %v, %v", got, err)
+	}
+
+	orders, trades, err := f.r.PurgeArchive(f.ctx, cutoff)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	// the open maker still refers to the trade, so the trade stays
+	if orders != 1 || trades != 0 {
+		t.Errorf("purged %d orders and %d trades, want 1 and 0", orders, trades)
+	}
+	if _, err := f.r.LoadOrderByID(f.ctx, done.ID); err == nil {
+		t.Error("purged order is still found")
+	}
+	if got, _ := f.r.LoadTradesForOrder(f.ctx, open.ID, nil, 0); len(got) != 1 || got[0].ID != old.ID {
+		t.Errorf("trade of a live order was purged")
+	}
+	if _, err := f.r.LoadOrderByID(f.ctx, open.ID); err != nil {
+		t.Errorf("open order was purged: %v", err)
+	}
+}
+
+func testDelistings(t *testing.T, f *fixture) {
+	now := f.tick()
+	d := domain.Delisting{Symbol: symbol, Reason: "venue closing", AnnouncedAt: now, HaltAt: now.Add(time.Hour), DelistAt: now.Add(2 * time.Hour)}
+	if err := f.r.SaveDelisting(f.ctx, d); err != nil {
+		t.Fatalf("save delisting: %v", err)
+	}
+	early := domain.Delisting{Symbol: "ETH/USD", Reason: "low volume", AnnouncedAt: now, HaltAt: now, DelistAt: now.Add(time.Hour)}
+	if err := f.r.SaveDelisting(f.ctx, early); err != nil {
+		t.Fatalf("save delisting: %v", err)
+	}
+	d.DelistAt = now.Add(3 * time.Hour)
+	if err := f.r.SaveDelisting(f.ctx, d); err != nil {
+		t.Fatalf("reschedule delisting: %v", err)
+	}
+	list, err := f.r.LoadDelistings(f.ctx)
+	if err != nil {
+		t.Fatalf("load delistings: %v", err)
+	}
+	if len(list) != 2 || list[0].Symbol != early.Symbol || list[1].Symbol != symbol || !list[1].DelistAt.Equal(d.DelistAt) {
+		t.Errorf("delistings %+v, want ETH/USD then the rescheduled %s", list, symbol)
+	}
+
+	resting := f.order("m", domain.Sell, "100", domain.Open)
+	f.order("m", domain.Sell, "100", domain.Filled)
+	cancelled, err := f.r.CompleteDelisting(f.ctx, symbol, d.DelistAt)
+	if err != nil {
+		t.Fatalf("complete delisting: %v", err)
+	}
+	wantIDs(t, "cancelled by delisting", cancelled, orderID, resting)
+	if len(cancelled) == 1 && cancelled[0].Status != domain.Cancelled {
+		t.Errorf("delisting left the order %s", cancelled[0].Status)
+	}
+	if err := f.r.SaveDelisting(f.ctx, d); err == nil {
+		t.Error("rescheduled a delisted symbol")
+	}
+	list, _ = f.r.LoadDelistings(f.ctx)
+	if len(list) != 2 || list[1].DelistedAt == nil || !list[1].DelistedAt.Equal(d.DelistAt) {
+		t.Errorf("delisted symbol read back as %+v", list)
+	}
+
+	if err := f.r.DeleteDelisting(f.ctx, symbol); err != nil {
+		t.Fatalf("relist: %v", err)
+	}
+	if err := f.r.DeleteDelisting(f.ctx, symbol); err == nil {
+		t.Error("deleted a delisting twice")
+	}
+}
+
+func testInstruments(t *testing.T, f *fixture) {
+	btc := domain.Instrument{
+		Symbol: symbol, BaseAsset: "BTC", QuoteAsset: "USD", TickSize: decimal.RequireFromString("0.5"),
+		LotSize: decimal.RequireFromString("0.001"), MinNotional: decimal.NewFromInt(10), Status: domain.InstrumentTrading, UpdatedAt: f.tick(),
+	}
+	eth := domain.Instrument{Symbol: "ETH/USD", BaseAsset: "ETH", QuoteAsset: "USD", Status: domain.InstrumentTrading, UpdatedAt: f.tick()}
+	for _, in := range []domain.Instrument{eth, btc} {
+		if err := f.r.SaveInstrument(f.ctx, in); err != nil {
+			t.Fatalf("save instrument: %v", err)
+		}
+	}
+	btc.Status, btc.TickSize, btc.UpdatedAt = domain.InstrumentHalted, decimal.NewFromInt(1), f.tick()
+	if err := f.r.SaveInstrument(f.ctx, btc); err != nil {
+		t.Fatalf("update instrument: %v", err)
+	}
+	list, err := f.r.LoadInstruments(f.ctx)
+	if err != nil {
+		t.Fatalf("load instruments: %v", err)
+	}
+	if len(list) != 2 || list[0].Symbol != symbol || list[1].Symbol != eth.Symbol {
+		t.Fatalf("instruments %+v, want %s then ETH/USD", list, symbol)
+	}
+	got := list[0]
+	if got.Status != domain.InstrumentHalted || !got.TickSize.Equal(btc.TickSize) || !got.LotSize.Equal(btc.LotSize) ||
+		!got.MinNotional.Equal(btc.MinNotional) || got.BaseAsset != "BTC" || got.QuoteAsset != "USD" || !got.UpdatedAt.Equal(btc.UpdatedAt) {
+		t.Errorf("updated instrument read back as %+v, want %+v", got, btc)
+	}
+}
+
+func testNotificationPreferences(t *testing.T, f *fixture) {
+	p := domain.NotificationPreference{ClientID: "c", Channel: domain.ChannelWebhook, Target: "https://example.com/hook"}
+	if err := f.r.DeleteNotificationPreference(f.ctx, p); err == nil {
+		t.Error("deleted a missing preference")
+	}
+	if err := f.r.SaveNotificationPreference(f.ctx, p); err != nil {
+		t.Fatalf("save preference: %v", err)
+	}
+	p.ExecTypes = []domain.ExecType{domain.ExecFill}
+	if err := f.r.SaveNotificationPreference(f.ctx, p); err != nil {
+		t.Fatalf("update preference: %v", err)
+	}
+	got, err := f.r.LoadNotificationPreferences(f.ctx, "c")
+	if err != nil {
+		t.Fatalf("load preferences: %v", err)
+	}
+	if len(got) != 1 || len(got[0].ExecTypes) != 1 || got[0].ExecTypes[0] != p.ExecTypes[0] {
+		t.Errorf("preferences %+v, want the updated %+v", got, p)
+	}
+	if err := f.r.DeleteNotificationPreference(f.ctx, p); err != nil {
+		t.Errorf("delete preference: %v", err)
+	}
+	if got, _ := f.r.LoadNotificationPreferences(f.ctx, "c"); len(got) != 0 {
+		t.Errorf("deleted preference still listed: %+v", got)
+	}
+}
+
+func testTradeFields(t *testing.T, f *fixture) {
+	sell := f.order("seller", domain.Sell, "100", domain.Open)
+	buy := f.order("buyer", domain.Buy, "100", domain.Open)
+	tr := &domain.Trade{
+		ID: uuid.NewString(), Symbol: symbol, BuyOrder: buy.ID, SellOrder: sell.ID,
+		Price: sell.Price, Quantity: decimal.NewFromInt(2), Timestamp: f.tick(),
+		MakerFee: decimal.RequireFromString("0.2"), TakerFee: decimal.RequireFromString("0.4"),
+		MakerFeeAsset: "USD", TakerFeeAsset: "USD", TakerSide: domain.Sell, TakerType: domain.Market,
+		BuyClient: "buyer", SellClient: "seller", BuyLiquidity: domain.Maker, SellLiquidity: domain.Taker,
+	}
+	if err := f.r.SaveTrade(f.ctx, tr); err != nil {
+		t.Fatalf("save trade: %v", err)
+	}
+	check := func(how string, got []*domain.Trade) {
+		t.Helper()
+		if len(got) != 1 {
+			t.Fatalf("%s: %d trades, want 1", how, len(got))
+		}
+		g := got[0]
+		if g.ID != tr.ID || g.BuyClient != "buyer" || g.SellClient != "seller" || g.BuyLiquidity != domain.Maker ||
+			g.SellLiquidity != domain.Taker || g.TakerSide != domain.Sell || g.TakerType != domain.Market ||
+			!g.MakerFee.Equal(tr.MakerFee) || !g.TakerFee.Equal(tr.TakerFee) || g.MakerFeeAsset != "USD" || g.TakerFeeAsset != "USD" {
+			t.Errorf("%s: %+v, want %+v", how, g, tr)
+		}
+	}
+	var scanned []*domain.Trade
+	if err := f.r.ScanTrades(f.ctx, domain.ExportFilter{}, func(t *domain.Trade) error {
+		scanned = append(scanned, t)
+		return nil
+	}); err != nil {
+		t.Fatalf("scan trades: %v", err)
+	}
+	check("scanned", scanned)
+	loaded, err := f.r.LoadTradesForOrder(f.ctx, buy.ID, nil, 10)
+	if err != nil {
+		t.Fatalf("load trades: %v", err)
+	}
+	check("loaded", loaded)
+	byID, err := f.r.LoadTradeByID(f.ctx, tr.ID)
+	if err != nil {
+		t.Fatalf("load trade: %v", err)
+	}
+	check("by id", []*domain.Trade{byID})
+	if _, err := f.r.LoadTradeByID(tenant.With(f.ctx, "porttest-"+uuid.NewString()), tr.ID); err == nil {
+		t.Error("trade found in another tenant")
+	}
+	if _, err := f.r.LoadTradeByID(f.ctx, uuid.NewString()); err == nil {
+		t.Error("unknown trade found")
+	}
+}
+
+func testNanoseconds(t *testing.T, f *fixture) {
+	o := &domain.Order{
+		ID: uuid.NewString(), ClientID: "c1", Symbol: symbol, Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(2), Remaining: decimal.NewFromInt(2),
+		Status: domain.Open, CreatedAt: f.tick().Add(123 * time.Nanosecond),
+	}
+	o.UpdatedAt = o.CreatedAt
+	if err := f.r.SaveOrder(f.ctx, o); err != nil {
+		t.Fatalf("save order: %v", err)
+	}
+	o.Remaining, o.Status, o.UpdatedAt = decimal.NewFromInt(1), domain.PartiallyFilled, o.CreatedAt.Add(457*time.Nanosecond)
+	if err := f.r.SaveOrder(f.ctx, o); err != nil {
+		t.Fatalf("update order: %v", err)
+	}
+	got, err := f.r.LoadOrderByID(f.ctx, o.ID)
+	if err != nil {
+		t.Fatalf("load order: %v", err)
+	}
+	if !got.CreatedAt.Equal(o.CreatedAt) || !got.UpdatedAt.Equal(o.UpdatedAt) {
+		t.Errorf("order times %s, %s, want %s, %s", got.CreatedAt, got.UpdatedAt, o.CreatedAt, o.UpdatedAt)
+	}
+
+	sell := f.order("c2", domain.Sell, "100", domain.Open)
+	tr := f.trade(o, sell, f.tick().Add(789*time.Nanosecond))
+	trades, err := f.r.LoadTradesForOrder(f.ctx, o.ID, nil, 10)
+	if err != nil {
+		t.Fatalf("load trades: %v", err)
+	}
+	if len(trades) != 1 || !trades[0].Timestamp.Equal(tr.Timestamp) || trades[0].Sequence != tr.Sequence {
+		t.Errorf("trades %+v, want one at %s with sequence %d", trades, tr.Timestamp, tr.Sequence)
+	}
+}
+
+func testTags(t *testing.T, f *fixture) {
+	tagged := func(clientID string, side domain.Side, tags ...string) *domain.Order {
+		t.Helper()
+		o := f.order(clientID, side, "100", domain.Open)
+		o.Tags = tags
+		if err := f.r.SaveOrder(f.ctx, o); err != nil {
+			t.Fatalf("save order: %v", err)
+		}
+		return o
+	}
+	alpha := tagged("c", domain.Buy, "alpha", "desk:1")
+	alpha.UserData = `{"ref":"é-42"}`
+	if err := f.r.SaveOrder(f.ctx, alpha); err != nil {
+		t.Fatalf("save user data: %v", err)
+	}
+	beta := tagged("c", domain.Buy, "beta")
+	maker := tagged("m", domain.Sell)
+	alphaTrade := f.trade(alpha, maker, f.tick())
+	f.trade(beta, maker, f.tick())
+
+	got, err := f.r.LoadOrderByID(f.ctx, alpha.ID)
+	if err != nil || len(got.Tags) != 2 || got.Tags[0] != "alpha" || got.Tags[1] != "desk:1" || got.UserData != alpha.UserData {
+		t.Errorf("tagged order read back as %+v, %v", got, err)
+	}
+	var orders []*domain.Order
+	if err := f.r.ScanOrders(f.ctx, domain.ExportFilter{Tag: "alpha"}, func(o *domain.Order) error {
+		orders = append(orders, o)
+		return nil
+	}); err != nil {
+		t.Fatalf("scan orders: %v", err)
+	}
+	wantIDs(t, "orders tagged alpha", orders, orderID, alpha)
+	var trades []string
+	if err := f.r.ScanTrades(f.ctx, domain.ExportFilter{Tag: "alpha"}, func(tr *domain.Trade) error {
+		trades = append(trades, tr.ID)
+		return nil
+	}); err != nil {
+		t.Fatalf("scan trades: %v", err)
+	}
+	if len(trades) != 1 || trades[0] != alphaTrade.ID {
+		t.Errorf("trades tagged alpha %v, want %s", trades, alphaTrade.ID)
+	}
+	fills, err := f.r.LoadClientFills(f.ctx, "c", f.at.Add(-time.Hour), f.at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("load fills: %v", err)
+	}
+	if len(fills) != 2 || !domain.HasTag(fills[0].Tags, "alpha") || fills[0].UserData != alpha.UserData || !domain.HasTag(fills[1].Tags, "beta") {
+		t.Errorf("fills %+v, want the alpha fill then the beta one with their tags and user data", fills)
+	}
+}
+
+func testTransitions(t *testing.T, f *fixture) {
+	o := f.order("c1", domain.Buy, "100", domain.Open)
+	step := func(seq uint64, et domain.ExecType, status domain.OrderStatus, cause domain.TransitionCause) domain.OrderTransition {
+		return domain.OrderTransition{
+			OrderID: o.ID, ClientID: "c1", Symbol: symbol, ExecType: et, Status: status, Cause: cause,
+			Remaining: decimal.NewFromInt(2), At: f.tick().Add(time.Duration(seq) * time.Nanosecond), Sequence: seq,
+		}
+	}
+	placed := step(1, domain.ExecNew, domain.Open, domain.CausePlaced)
+	cancelled := step(3, domain.ExecCanceled, domain.Cancelled, domain.CauseAdmin)
+	cancelled.Reason, cancelled.Remaining = "fat finger", decimal.Zero
+	filled := step(2, domain.ExecPartialFill, domain.PartiallyFilled, domain.CauseMatched)
+	filled.TradeID, filled.Remaining = uuid.NewString(), decimal.NewFromInt(1)
+	if err := f.r.SaveTransitions(f.ctx, []domain.OrderTransition{placed, cancelled}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	// out of order and repeated, as after a retried write
+	if err := f.r.SaveTransitions(f.ctx, []domain.OrderTransition{filled, placed}); err != nil {
+		t.Fatalf("save again: %v", err)
+	}
+
+	got, err := f.r.LoadTransitions(f.ctx, o.ID)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := []domain.OrderTransition{placed, filled, cancelled}
+	if len(got) != len(want) {
+		t.Fatalf("%d transitions, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Sequence != w.Sequence || g.Cause != w.Cause || g.Status != w.Status || g.ExecType != w.ExecType ||
+			g.Reason != w.Reason || g.TradeID != w.TradeID || !g.Remaining.Equal(w.Remaining) || !g.At.Equal(w.At) ||
+			g.ClientID != w.ClientID || g.Symbol != w.Symbol {
+			t.Errorf("transition %d: %+v, want %+v", i, g, w)
+		}
+	}
+	if none, err := f.r.LoadTransitions(f.ctx, uuid.NewString()); err != nil || len(none) != 0 {
+		t.Errorf("unknown order: %v, %v, want none", none, err)
+	}
+
+	// a rejected order never reached the book and has no row to take a side and price from
+	rejected := domain.OrderTransition{OrderID: uuid.NewString(), ClientID: "c1", Symbol: symbol, ExecType: domain.ExecRejected,
+		Status: domain.Open, Cause: domain.CauseRejected, At: f.tick(), Sequence: 1}
+	if err := f.r.SaveTransitions(f.ctx, []domain.OrderTransition{rejected}); err != nil {
+		t.Fatalf("save rejection: %v", err)
+	}
+	var changes []domain.BookChange
+	err = f.r.ScanBookChanges(f.ctx, domain.ExportFilter{Symbol: symbol}, func(c domain.BookChange) error {
+		changes = append(changes, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("scan book changes: %v", err)
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("%d book changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, c := range changes {
+		w := want[i]
+		if c.OrderID != o.ID || c.Sequence != w.Sequence || c.ExecType != w.ExecType || c.Side != o.Side ||
+			!c.Price.Equal(o.Price) || !c.Remaining.Equal(w.Remaining) || !c.At.Equal(w.At) {
+			t.Errorf("book change %d: %+v, want %+v of %s %s at %s", i, c, w, o.ID, o.Side, o.Price)
+		}
+	}
+}
+
+func testAlerts(t *testing.T, f *fixture) {
+	alert := func(kind domain.AlertKind, clients ...string) domain.SurveillanceAlert {
+		return domain.SurveillanceAlert{
+			ID: uuid.NewString(), Kind: kind, Symbol: symbol, ClientIDs: clients, TradeIDs: []string{uuid.NewString()},
+			Detail: string(kind), At: f.tick(), DetectedAt: f.at, Status: domain.AlertOpen,
+		}
+	}
+	self, group, volume := alert(domain.AlertSelfMatch, "a"), alert(domain.AlertGroupMatch, "a", "b"), alert(domain.AlertVolumeAnomaly)
+	volume.ClientIDs, volume.TradeIDs = nil, nil
+	if err := f.r.SaveAlerts(f.ctx, []domain.SurveillanceAlert{self, group, volume}); err != nil {
+		t.Fatalf("save alerts: %v", err)
+	}
+	if err := f.r.ReviewAlert(f.ctx, self.ID, domain.AlertDismissed, "compliance", "market maker hedge", f.tick()); err != nil {
+		t.Fatalf("review alert: %v", err)
+	}
+	// a rescan saving the same finding keeps its review
+	self.Detail = "seen again"
+	if err := f.r.SaveAlerts(f.ctx, []domain.SurveillanceAlert{self}); err != nil {
+		t.Fatalf("save alert again: %v", err)
+	}
+
+	load := func(flt domain.AlertFilter, after *page.Key, limit int) []domain.SurveillanceAlert {
+		t.Helper()
+		got, err := f.r.LoadAlerts(f.ctx, flt, after, limit)
+		if err != nil {
+			t.Fatalf("load alerts: %v", err)
+		}
+		return got
+	}
+	all := load(domain.AlertFilter{}, nil, 0)
+	if len(all) != 3 || all[0].ID != self.ID || all[1].ID != group.ID || all[2].ID != volume.ID {
+		t.Fatalf("alerts %+v, want the three in time order", all)
+	}
+	if a := all[0]; a.Status != domain.AlertDismissed || a.ReviewedBy != "compliance" || a.ReviewedAt == nil || a.Detail != string(domain.AlertSelfMatch) {
+		t.Errorf("reviewed alert read back as %+v", a)
+	}
+	if got := load(domain.AlertFilter{ClientID: "a", Status: domain.AlertOpen}, nil, 0); len(got) != 1 || got[0].ID != group.ID {
+		t.Errorf("open alerts of a: %+v, want the group match", got)
+	}
+	if got := load(domain.AlertFilter{}, &page.Key{At: all[0].At, ID: all[0].ID}, 1); len(got) != 1 || got[0].ID != group.ID {
+		t.Errorf("page after the first alert: %+v, want the group match", got)
+	}
+	if err := f.r.ReviewAlert(f.ctx, uuid.NewString(), domain.AlertEscalated, "compliance", "", f.tick()); err == nil {
+		t.Error("reviewed a missing alert")
+	}
+}
+
+func (f *fixture) ledger(symbol string, afterSeq uint64) []domain.LedgerEvent {
+	f.t.Helper()
+	var evs []domain.LedgerEvent
+	if err := f.r.ScanLedger(f.ctx, symbol, afterSeq, func(ev domain.LedgerEvent) error {
+		evs = append(evs, ev)
+		return nil
+	}); err != nil {
+		f.t.Fatalf("scan ledger: %v", err)
+	}
+	return evs
+}
+
+func testLedger(t *testing.T, f *fixture) {
+	o := &domain.Order{
+		ID: uuid.NewString(), ClientID: "c", Symbol: symbol, Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(2), Remaining: decimal.NewFromInt(2),
+		Status: domain.Open, CreatedAt: f.tick(), Tags: []string{"s1"},
+	}
+	other := *o
+	other.ID, other.Symbol = uuid.NewString(), "ETH/USD"
+
+	tx := f.begin()
+	if err := tx.AppendLedger(f.ctx, []domain.LedgerEvent{domain.OrderLedgerEvent(domain.OrderAccepted, o)}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := tx.Rollback(f.ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if evs := f.ledger("", 0); len(evs) != 0 {
+		t.Fatalf("rolled back events were kept: %v", evs)
+	}
+
+	tx = f.begin()
+	evs := []domain.LedgerEvent{domain.OrderLedgerEvent(domain.OrderAccepted, o), domain.OrderLedgerEvent(domain.OrderAccepted, &other)}
+	if err := tx.AppendLedger(f.ctx, evs); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	for _, o := range []*domain.Order{o, &other} {
+		if err := tx.SaveOrder(f.ctx, o); err != nil {
+			t.Fatalf("save order: %v", err)
+		}
+	}
+	if err := tx.Commit(f.ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if _, err := f.r.CancelOrders(f.ctx, "c", []string{o.ID}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	got := f.ledger(symbol, 0)
+	if len(got) != 2 || got[0].Kind != domain.OrderAccepted || got[1].Kind != domain.OrderCancelled {
+		t.Fatalf("ledger of %s: %+v, want the acceptance then the cancel", symbol, got)
+	}
+	if got[1].Seq <= got[0].Seq || got[1].OrderID != o.ID {
+		t.Errorf("cancel event %+v after %d", got[1], got[0].Seq)
+	}
+	if a := got[0].Order; a == nil || a.ID != o.ID || !a.Price.Equal(o.Price) || len(a.Tags) != 1 {
+		t.Errorf("accepted order read back as %+v", a)
+	}
+	if all := f.ledger("", 0); len(all) != 3 {
+		t.Errorf("whole ledger has %d events, want 3", len(all))
+	}
+	if after := f.ledger(symbol, got[0].Seq); len(after) != 1 || after[0].Kind != domain.OrderCancelled {
+		t.Errorf("events after %d: %+v", got[0].Seq, after)
+	}
+}
+
+func testAccounts(t *testing.T, f *fixture) {
+	balance := func(asset string) domain.Account {
+		t.Helper()
+		accs, err := f.r.LoadAccounts(f.ctx, "c")
+		if err != nil {
+			t.Fatalf("load accounts: %v", err)
+		}
+		for _, a := range accs {
+			if a.Asset == asset {
+				return a
+			}
+		}
+		return domain.Account{}
+	}
+	hundred, forty := decimal.NewFromInt(100), decimal.NewFromInt(40)
+
+	tx := f.begin()
+	if err := tx.AdjustAccount(f.ctx, "c", "USD", hundred, decimal.Zero); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if err := tx.Commit(f.ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if a := balance("USD"); !a.Available.Equal(hundred) || !a.Locked.IsZero() {
+		t.Fatalf("after deposit: %+v", a)
+	}
+
+	tx = f.begin()
+	if err := tx.AdjustAccount(f.ctx, "c", "USD", hundred.Add(decimal.NewFromInt(1)).Neg(), decimal.Zero); !errors.Is(err, domain.ErrInsufficientFunds) {
+		t.Fatalf("overdraft = %v, want ErrInsufficientFunds", err)
+	}
+	if err := tx.AdjustAccount(f.ctx, "c", "BTC", decimal.NewFromInt(-1), decimal.Zero); !errors.Is(err, domain.ErrInsufficientFunds) {
+		t.Fatalf("debit of an account never opened = %v, want ErrInsufficientFunds", err)
+	}
+	// a failed debit leaves the transaction usable
+	o := &domain.Order{
+		ID: uuid.NewString(), ClientID: "c", Symbol: symbol, Side: domain.Buy, Type: domain.Limit,
+		Price: decimal.NewFromInt(20), Quantity: decimal.NewFromInt(2), Remaining: decimal.NewFromInt(2),
+		Status: domain.Open, CreatedAt: f.tick(),
+	}
+	if err := tx.SaveOrder(f.ctx, o); err != nil {
+		t.Fatalf("save order: %v", err)
+	}
+	if err := tx.AdjustAccount(f.ctx, "c", "USD", forty.Neg(), forty); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if err := tx.SaveHold(f.ctx, domain.Hold{OrderID: o.ID, ClientID: "c", Asset: "USD", Amount: forty}); err != nil {
+		t.Fatalf("save hold: %v", err)
+	}
+	if h, err := tx.LoadHold(f.ctx, o.ID); err != nil || h == nil || !h.Amount.Equal(forty) {
+		t.Fatalf("hold in its transaction = %+v, %v", h, err)
+	}
+	if err := tx.Commit(f.ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if a := balance("USD"); !a.Available.Equal(decimal.NewFromInt(60)) || !a.Locked.Equal(forty) {
+		t.Fatalf("after lock: %+v", a)
+	}
+
+	if _, err := f.r.CancelOrders(f.ctx, "c", []string{o.ID}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if a := balance("USD"); !a.Available.Equal(hundred) || !a.Locked.IsZero() {
+		t.Errorf("cancel did not release the hold: %+v", a)
+	}
+	tx = f.begin()
+	defer tx.Rollback(f.ctx)
+	if h, err := tx.LoadHold(f.ctx, o.ID); err != nil || h != nil {
+		t.Errorf("hold of a cancelled order = %+v, %v", h, err)
+	}
+}
+
+func testCandles(t *testing.T, f *fixture) {
+	start := f.at.Truncate(time.Minute)
+	bar := func(at time.Time, open, high, low, close string, trades int) domain.Candle {
+		return domain.Candle{
+			Symbol: symbol, Interval: domain.Candle1m, Start: at,
+			Open: decimal.RequireFromString(open), High: decimal.RequireFromString(high),
+			Low: decimal.RequireFromString(low), Close: decimal.RequireFromString(close),
+			Volume: decimal.NewFromInt(int64(trades)), Notional: decimal.RequireFromString(close).Mul(decimal.NewFromInt(int64(trades))),
+			Trades: trades,
+		}
+	}
+	next := start.Add(time.Minute)
+	if err := f.r.MergeCandles(f.ctx, []domain.Candle{bar(start, "100", "102", "99", "101", 2)}); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if err := f.r.MergeCandles(f.ctx, []domain.Candle{bar(start, "98", "100", "97", "100", 1), bar(next, "100", "100", "100", "100", 1)}); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	got, err := f.r.LoadCandles(f.ctx, symbol, domain.Candle1m, time.Time{}, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got) != 2 || !got[0].Start.Equal(start) || !got[1].Start.Equal(next) {
+		t.Fatalf("candles = %+v, want bars at %s and %s", got, start, next)
+	}
+	c := got[0]
+	want := bar(start, "100", "102", "97", "100", 3)
+	want.Notional = decimal.NewFromInt(302)
+	if !c.Open.Equal(want.Open) || !c.High.Equal(want.High) || !c.Low.Equal(want.Low) || !c.Close.Equal(want.Close) ||
+		!c.Volume.Equal(want.Volume) || !c.Notional.Equal(want.Notional) || c.Trades != want.Trades {
+		t.Errorf("merged bar = %+v, want %+v", c, want)
+	}
+
+	if got, err := f.r.LoadCandles(f.ctx, symbol, domain.Candle1m, time.Time{}, time.Time{}, 1); err != nil || len(got) != 1 || !got[0].Start.Equal(next) {
+		t.Errorf("last bar = %+v, %v; want the bar at %s", got, err, next)
+	}
+	if got, err := f.r.LoadCandles(f.ctx, symbol, domain.Candle1m, start, next, 0); err != nil || len(got) != 1 || !got[0].Start.Equal(start) {
+		t.Errorf("bars in [%s, %s) = %+v, %v", start, next, got, err)
+	}
+	if got, err := f.r.LoadCandles(f.ctx, symbol, domain.Candle5m, time.Time{}, time.Time{}, 0); err != nil || len(got) != 0 {
+		t.Errorf("5m bars = %+v, %v; want none", got, err)
+	}
+}
@@ -2,30 +2,139 @@ package port
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/page"
 	"github.com/shopspring/decimal"
 )
 
+// ErrTxConflict is returned by Tx methods, Commit included, when the transaction lost a conflict
+// with a concurrent one and was aborted; running it again from the start may succeed
+var ErrTxConflict = errors.New("transaction conflict")
+
+// ErrDuplicateClientOrderID is returned by SaveOrder for a new order whose ClientOrderID another open
+// order of the client already has
+var ErrDuplicateClientOrderID = errors.New("client order ID is in use by an open order of the client")
+
 type Repository interface {
 	SaveOrder(ctx context.Context, o *domain.Order) error
 	SaveTrade(ctx context.Context, t *domain.Trade) error
 	LoadOpenOrders(ctx context.Context, symbol string) ([]*domain.Order, error)
 	CancelOrder(ctx context.Context, orderID, clientID string) error
+	// CancelOrders cancels the client's OPEN orders among orderIDs and returns cancelled order ID -> symbol
+	CancelOrders(ctx context.Context, clientID string, orderIDs []string) (map[string]string, error)
+	// CancelOpenOrders cancels every OPEN order of the client, optionally narrowed by symbol and side ("" = any),
+	// and returns cancelled order ID -> symbol
+	CancelOpenOrders(ctx context.Context, clientID, symbol string, side domain.Side) (map[string]string, error)
+	// CancelSessionOrders cancels the client's resting orders placed by the session, optionally only those
+	// of symbol ("" = any), and returns cancelled order ID -> symbol
+	CancelSessionOrders(ctx context.Context, clientID, sessionID, symbol string) (map[string]string, error)
+	// CancelGroupOrders cancels the client's resting orders of the group, optionally only those of symbol
+	// ("" = any), and returns cancelled order ID -> symbol
+	CancelGroupOrders(ctx context.Context, clientID, groupID, symbol string) (map[string]string, error)
+	// ModifyOrder replaces the price and total quantity of the client's resting order, which keeps
+	// what already filled and loses its time priority as if placed now
 	ModifyOrder(ctx context.Context, orderID, clientID string, price, qty decimal.Decimal) error
 	LoadSnapshot(ctx context.Context, id string) (*domain.OrderbookSnapshot, error)
 	BeginTx(ctx context.Context) (Tx, error)
 	LoadOrderByIDForClient(ctx context.Context, orderID, clientID string) (*domain.Order, error)
+	LoadOrderByID(ctx context.Context, orderID string) (*domain.Order, error)
+	// LoadOrderByClientOrderID returns the client's order with the client order ID: the open one if
+	// there is one, otherwise the most recently created
+	LoadOrderByClientOrderID(ctx context.Context, clientID, clientOrderID string) (*domain.Order, error)
+	// LoadTopOfBook returns every order resting at the best bid price and at the best ask price, each
+	// side in time priority
 	LoadTopOfBook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error)
-	LoadTradesForOrder(ctx context.Context, orderID string) ([]*domain.Trade, error)
+	// LoadTriggerOrders returns the symbol's PENDING stop orders, oldest first; with crossed set, only
+	// those trades across it trigger
+	LoadTriggerOrders(ctx context.Context, symbol string, crossed *domain.PriceRange) ([]*domain.Order, error)
+	// LoadTradesForOrder returns up to limit trades of the order after the page position, oldest first;
+	// a nil position starts from the first trade and limit <= 0 returns all
+	LoadTradesForOrder(ctx context.Context, orderID string, after *page.Key, limit int) ([]*domain.Trade, error)
+	// LoadTrades returns up to limit trades of the symbol executed in [from, to) after the page
+	// position, oldest first; a zero from or to leaves that end open
+	LoadTrades(ctx context.Context, symbol string, from, to time.Time, after *page.Key, limit int) ([]*domain.Trade, error)
+	// LoadTradeByID returns the trade, executed in the ctx tenant, with the given ID
+	LoadTradeByID(ctx context.Context, tradeID string) (*domain.Trade, error)
+	LoadNotificationPreferences(ctx context.Context, clientID string) ([]domain.NotificationPreference, error)
+	SaveNotificationPreference(ctx context.Context, p domain.NotificationPreference) error
+	DeleteNotificationPreference(ctx context.Context, p domain.NotificationPreference) error
+	// ListSymbols returns every symbol that has orders
+	ListSymbols(ctx context.Context) ([]string, error)
+	// LoadOrderFills returns every order of symbol, in any status, with the quantity filled by its trades
+	LoadOrderFills(ctx context.Context, symbol string) ([]domain.OrderFill, error)
+	// ListOrders returns up to limit live and archived orders matching f after the page position,
+	// ordered by CreatedAt and ID
+	ListOrders(ctx context.Context, f domain.OrderFilter, after *page.Key, limit int) ([]*domain.Order, error)
+	// ScanOrders and ScanTrades call fn for every matching row, oldest first, reading rows only as fast
+	// as fn consumes them; an error from fn stops the scan.
+	ScanOrders(ctx context.Context, f domain.ExportFilter, fn func(*domain.Order) error) error
+	ScanTrades(ctx context.Context, f domain.ExportFilter, fn func(*domain.Trade) error) error
+	// ArchiveOrders moves up to limit FILLED or CANCELLED orders last updated before the cutoff to the
+	// archive and returns how many were moved
+	ArchiveOrders(ctx context.Context, before time.Time, limit int) (int, error)
+	// PurgeArchive deletes archived orders last updated before the cutoff and the trades executed before
+	// it that no live order refers to, and with them the histories of orders no longer stored
+	PurgeArchive(ctx context.Context, before time.Time) (orders, trades int, err error)
+	// SaveDailyReport computes and stores the reports of the UTC day starting at day
+	SaveDailyReport(ctx context.Context, day time.Time) error
+	// LoadDailyReport returns the stored reports of the day, nil if it was never generated
+	LoadDailyReport(ctx context.Context, day time.Time) (*domain.DailyReport, error)
+	// LoadClientFills returns the client's side of every trade executed in [from, to), oldest first
+	LoadClientFills(ctx context.Context, clientID string, from, to time.Time) ([]domain.StatementFill, error)
+	// SaveDelisting schedules or reschedules the delisting of d.Symbol; a delisted symbol cannot be rescheduled
+	SaveDelisting(ctx context.Context, d domain.Delisting) error
+	// DeleteDelisting removes the symbol's delisting, withdrawing the announcement or relisting it
+	DeleteDelisting(ctx context.Context, symbol string) error
+	LoadDelistings(ctx context.Context) ([]domain.Delisting, error)
+	// CompleteDelisting cancels the symbol's resting orders, returning them, and marks it delisted at
+	CompleteDelisting(ctx context.Context, symbol string, at time.Time) ([]*domain.Order, error)
+	// SaveInstrument registers the instrument in the ctx tenant or replaces its definition
+	SaveInstrument(ctx context.Context, in domain.Instrument) error
+	// LoadInstruments returns the ctx tenant's instruments ordered by symbol
+	LoadInstruments(ctx context.Context) ([]domain.Instrument, error)
+	// SaveAlerts stores surveillance alerts, skipping those already stored under the same ID
+	SaveAlerts(ctx context.Context, alerts []domain.SurveillanceAlert) error
+	// LoadAlerts returns up to limit alerts matching f after the page position, ordered by At and ID
+	LoadAlerts(ctx context.Context, f domain.AlertFilter, after *page.Key, limit int) ([]domain.SurveillanceAlert, error)
+	// ReviewAlert sets the alert's status and records who reviewed it, when and why
+	ReviewAlert(ctx context.Context, id string, status domain.AlertStatus, reviewer, note string, at time.Time) error
+	// SaveTransitions appends entries to their orders' histories, skipping those already stored
+	SaveTransitions(ctx context.Context, ts []domain.OrderTransition) error
+	// LoadTransitions returns the order's history in sequence order, empty if none was recorded
+	LoadTransitions(ctx context.Context, orderID string) ([]domain.OrderTransition, error)
+	// ScanBookChanges calls fn for every recorded transition of an order that reached the book, of
+	// f's symbol and time range, in sequence order; the filter's tag is ignored
+	ScanBookChanges(ctx context.Context, f domain.ExportFilter, fn func(domain.BookChange) error) error
+	// ScanLedger calls fn for every ledger event of symbol ("" for every symbol) after afterSeq, in
+	// sequence order. Every cancel method records an OrderCancelled event for each order it cancels
+	// in the same transaction.
+	ScanLedger(ctx context.Context, symbol string, afterSeq uint64, fn func(domain.LedgerEvent) error) error
+	AccountRepository
+	CandleRepository
 }
 
 type Tx interface {
+	// SaveOrder returns ErrDuplicateClientOrderID for a new live order whose ClientOrderID an open
+	// order of the client has
 	SaveOrder(ctx context.Context, o *domain.Order) error
 	SaveTrade(ctx context.Context, t *domain.Trade) error
 	CancelOrder(ctx context.Context, orderID, clientID string) error
-	ModifyOrder(ctx context.Context, orderID, clientID string, price, qty *decimal.Decimal) error
+	// ModifyOrder replaces the price and total quantity of the client's OPEN or PARTIALLY_FILLED
+	// order, leaving qty less what already filled remaining, and moves its time priority (CreatedAt)
+	// to at: a replaced order queues behind the orders already resting at its price
+	ModifyOrder(ctx context.Context, orderID, clientID string, price, qty *decimal.Decimal, at time.Time) error
 	LoadOrderByIDForClient(ctx context.Context, orderID, clientID string) (*domain.Order, error)
-	LoadCandidatesForMatch(ctx context.Context, symbol string, side domain.Side, limitPrice *decimal.Decimal, limit int) ([]*domain.Order, error)
+	// LockSymbol serializes the transactions matching in symbol until commit or rollback
+	LockSymbol(ctx context.Context, symbol string) error
+	// LoadCandidatesForMatch locks up to limit resting orders opposite to side in price-time priority,
+	// priced within limitPrice if set and positioned after the key if set
+	LoadCandidatesForMatch(ctx context.Context, symbol string, side domain.Side, limitPrice *decimal.Decimal, after *domain.BookKey, limit int) ([]*domain.Order, error)
+	// AppendLedger appends events to the tenant's ledger; they commit or roll back with the transaction
+	AppendLedger(ctx context.Context, evs []domain.LedgerEvent) error
+	AccountTx
 
 	Commit(ctx context.Context) error
 	Rollback(ctx context.Context) error
@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// SnapshotCatalog indexes stored snapshots so they can be listed, inspected and deleted
+type SnapshotCatalog interface {
+	// AddSnapshot records meta, assigning its Sequence, and forgets it after ttl
+	AddSnapshot(ctx context.Context, meta *domain.SnapshotMeta, ttl time.Duration) error
+	// ListSnapshots returns up to limit snapshots of symbol older than beforeSeq, newest first;
+	// beforeSeq <= 0 starts from the newest and limit <= 0 returns all
+	ListSnapshots(ctx context.Context, symbol string, beforeSeq, limit int64) ([]domain.SnapshotMeta, error)
+	GetSnapshotMeta(ctx context.Context, snapshotID string) (*domain.SnapshotMeta, error)
+	// DeleteSnapshot removes the snapshot and its metadata
+	DeleteSnapshot(ctx context.Context, snapshotID string) error
+}
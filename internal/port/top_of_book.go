@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// TopOfBookStore keeps the best bid and ask of every book, so quotes are served without reading the book
+type TopOfBookStore interface {
+	// SetTopOfBook stores the book's best orders, at most one per side, unless a top with a higher
+	// Sequence is stored already
+	SetTopOfBook(ctx context.Context, symbol string, top *domain.OrderbookSnapshot) error
+	// GetTopOfBook returns nil, nil when the symbol's top is not stored
+	GetTopOfBook(ctx context.Context, symbol string) (*domain.OrderbookSnapshot, error)
+	InvalidateTopOfBook(ctx context.Context, symbol string) error
+}
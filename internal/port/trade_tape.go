@@ -0,0 +1,18 @@
+package port
+
+import (
+	"context"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+)
+
+// TradeTape is a capped, append-only log of executions per symbol
+type TradeTape interface {
+	// AppendTrades records trades in order and returns their tape positions
+	AppendTrades(ctx context.Context, symbol string, trades []*domain.Trade) ([]string, error)
+	RecentTrades(ctx context.Context, symbol string, limit int64) ([]*domain.Trade, error)
+	// TradesBefore returns up to limit entries strictly before beforeSeq, newest first; an empty beforeSeq
+	// starts from the latest
+	TradesBefore(ctx context.Context, symbol, beforeSeq string, limit int64) ([]domain.TapeEntry, error)
+	TradesAfter(ctx context.Context, symbol, afterSeq string, limit int64) ([]domain.TapeEntry, error)
+}
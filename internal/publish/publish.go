@@ -0,0 +1,235 @@
+// Package publish forwards order events and trades of every tenant to a message broker, for risk,
+// settlement and analytics consumers outside the process. Each kind of event goes to its own topic.
+// Messages are JSON and keyed by tenant and symbol, so a partitioned topic keeps each book's events
+// in order. A batch the broker refuses is retried until it is accepted: consumers see every event
+// at least once.
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/olyamironova/exchange-engine/internal/domain"
+	"github.com/olyamironova/exchange-engine/internal/notify"
+	"github.com/olyamironova/exchange-engine/internal/port"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// maxBatch bounds the events gathered into one round of publishing
+	maxBatch      = 512
+	retryInterval = time.Second
+)
+
+// Topics routes events to broker topics
+type Topics struct {
+	// Orders receives the order events of every exec type not in ByExecType; empty publishes none
+	Orders string
+	// ByExecType overrides Orders for some exec types; an empty topic leaves the type unpublished
+	ByExecType map[domain.ExecType]string
+	// Trades receives executed trades; empty publishes none
+	Trades string
+}
+
+// DefaultTopics publishes order events to exchange.orders and trades to exchange.trades
+func DefaultTopics() Topics {
+	return Topics{Orders: "exchange.orders", Trades: "exchange.trades"}
+}
+
+// ParseTopics overrides the defaults with a list like "orders=o,trades=t,FILL=fills,REJECTED=": the
+// kinds are orders, trades and the exec types of order events
+func ParseTopics(s string) (Topics, error) {
+	t := DefaultTopics()
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kind, topic, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Topics{}, fmt.Errorf("topic %q is not kind=topic", pair)
+		}
+		switch kind = strings.TrimSpace(kind); kind {
+		case "orders":
+			t.Orders = strings.TrimSpace(topic)
+		case "trades":
+			t.Trades = strings.TrimSpace(topic)
+		default:
+			et := domain.ExecType(strings.ToUpper(kind))
+			if !knownExecType(et) {
+				return Topics{}, fmt.Errorf("unknown event kind %q", kind)
+			}
+			if t.ByExecType == nil {
+				t.ByExecType = make(map[domain.ExecType]string)
+			}
+			t.ByExecType[et] = strings.TrimSpace(topic)
+		}
+	}
+	return t, nil
+}
+
+func knownExecType(et domain.ExecType) bool {
+	switch et {
+	case domain.ExecNew, domain.ExecPartialFill, domain.ExecFill, domain.ExecCanceled, domain.ExecReplaced,
+		domain.ExecRejected, domain.ExecExpired, domain.ExecTriggered:
+		return true
+	}
+	return false
+}
+
+func (t Topics) order(et domain.ExecType) string {
+	if topic, ok := t.ByExecType[et]; ok {
+		return topic
+	}
+	return t.Orders
+}
+
+// Trade is the wire form of a published trade
+type Trade struct {
+	Tenant      string          `json:"tenant"`
+	TradeID     string          `json:"trade_id"`
+	Symbol      string          `json:"symbol"`
+	Price       decimal.Decimal `json:"price"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	BuyOrderID  string          `json:"buy_order_id"`
+	SellOrderID string          `json:"sell_order_id"`
+	BuyClient   string          `json:"buy_client_id,omitempty"`
+	SellClient  string          `json:"sell_client_id,omitempty"`
+	TakerSide   string          `json:"taker_side,omitempty"`
+	MakerFee    decimal.Decimal `json:"maker_fee"`
+	TakerFee    decimal.Decimal `json:"taker_fee"`
+	FeeAsset    string          `json:"fee_asset,omitempty"`
+	Timestamp   time.Time       `json:"timestamp"`
+	Sequence    uint64          `json:"sequence"`
+	// TapeSeq is the trade's position on the trade tape, when one is configured
+	TapeSeq string `json:"tape_seq,omitempty"`
+}
+
+func NewTrade(e domain.TapeEntry) Trade {
+	t := e.Trade
+	return Trade{
+		Tenant:      e.Tenant,
+		TradeID:     t.ID,
+		Symbol:      t.Symbol,
+		Price:       t.Price,
+		Quantity:    t.Quantity,
+		BuyOrderID:  t.BuyOrder,
+		SellOrderID: t.SellOrder,
+		BuyClient:   t.BuyClient,
+		SellClient:  t.SellClient,
+		TakerSide:   string(t.TakerSide),
+		MakerFee:    t.MakerFee,
+		TakerFee:    t.TakerFee,
+		FeeAsset:    t.TakerFeeAsset,
+		Timestamp:   t.Timestamp,
+		Sequence:    t.Sequence,
+		TapeSeq:     e.Seq,
+	}
+}
+
+// Forwarder publishes the engine's order events and trades
+type Forwarder struct {
+	pub    port.EventPublisher
+	topics Topics
+}
+
+func NewForwarder(pub port.EventPublisher, topics Topics) *Forwarder {
+	return &Forwarder{pub: pub, topics: topics}
+}
+
+// batch is one round of messages, by topic in the order the topics were first met
+type batch struct {
+	topics []string
+	msgs   map[string][]port.BrokerMessage
+	n      int
+}
+
+func (b *batch) add(topic, key string, v any) {
+	if topic == "" {
+		return
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("publish: encode for %s: %v", topic, err)
+		return
+	}
+	if _, ok := b.msgs[topic]; !ok {
+		b.topics = append(b.topics, topic)
+	}
+	b.msgs[topic] = append(b.msgs[topic], port.BrokerMessage{Key: key, Value: value})
+	b.n++
+}
+
+// Run publishes from the channels until both are closed or ctx is done. Events that arrive while a
+// round is published are gathered into the next one.
+func (f *Forwarder) Run(ctx context.Context, events <-chan *domain.OrderEvent, trades <-chan domain.TapeEntry) {
+	for events != nil || trades != nil {
+		b := &batch{msgs: make(map[string][]port.BrokerMessage)}
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			f.addEvent(b, ev)
+		case t, ok := <-trades:
+			if !ok {
+				trades = nil
+				continue
+			}
+			f.addTrade(b, t)
+		}
+	gather:
+		for b.n < maxBatch {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					events = nil
+					continue
+				}
+				f.addEvent(b, ev)
+			case t, ok := <-trades:
+				if !ok {
+					trades = nil
+					continue
+				}
+				f.addTrade(b, t)
+			default:
+				break gather
+			}
+		}
+		f.publish(ctx, b)
+	}
+}
+
+func (f *Forwarder) addEvent(b *batch, ev *domain.OrderEvent) {
+	b.add(f.topics.order(ev.ExecType), ev.Tenant+"/"+ev.Symbol, notify.NewPayload(ev))
+}
+
+func (f *Forwarder) addTrade(b *batch, e domain.TapeEntry) {
+	b.add(f.topics.Trades, e.Tenant+"/"+e.Trade.Symbol, NewTrade(e))
+}
+
+// publish sends the batch topic by topic, retrying each until the broker accepts it or ctx is done
+func (f *Forwarder) publish(ctx context.Context, b *batch) {
+	for _, topic := range b.topics {
+		for {
+			err := f.pub.Publish(ctx, topic, b.msgs[topic])
+			if err == nil {
+				break
+			}
+			log.Printf("publish: %d messages to %s: %v", len(b.msgs[topic]), topic, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package pubsub
+
+import (
+	"errors"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Policy decides what happens to an update for a subscriber whose buffer is full
+type Policy int
+
+const (
+	// Drop skips the update; the subscriber learns about it from Dropped
+	Drop Policy = iota
+	// Disconnect closes the subscription with ErrSlowConsumer
+	Disconnect
+	// Conflate discards the oldest buffered update to make room, so the newest state always arrives
+	Conflate
+	// Buffer queues up to Options.Bound updates before dropping
+	Buffer
+)
+
+// ErrSlowConsumer is the Err of a subscription closed by the Disconnect policy
+var ErrSlowConsumer = errors.New("slow consumer disconnected")
+
+// Options configures how subscriptions are buffered and what happens when they fall behind
+type Options struct {
+	Policy Policy
+	// Bound is the buffer size for the Buffer policy; other policies use the PubSub's buffer
+	Bound int
+}
+
+// shards is how many independently locked parts the topics of a PubSub are spread over, so
+// publishers and subscribers of different topics rarely contend for the same lock
+const shards = 16
+
+// PubSub fans out values published on a topic to every subscriber of that topic.
+// Delivery is non-blocking: a full subscriber is handled according to its Policy.
+// It is safe for concurrent use; a subscription is only closed once no publisher can send to it.
+type PubSub[T any] struct {
+	shards [shards]shard[T]
+	buffer int
+	opts   Options
+	closed atomic.Bool
+
+	dropped      atomic.Uint64
+	disconnected atomic.Uint64
+}
+
+// shard holds the topics hashing to it
+type shard[T any] struct {
+	mu     sync.RWMutex
+	topics map[string]*topic[T]
+}
+
+// topic is a topic's subscribers and its counters, which live as long as it has subscribers
+type topic[T any] struct {
+	subs      map[*Subscription[T]]struct{}
+	published atomic.Uint64
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// Subscription receives the values of one or more topics on C
+type Subscription[T any] struct {
+	C       <-chan T
+	ch      chan T
+	topic   string
+	mu      sync.Mutex // taken before any shard's mu
+	topics  map[string]struct{}
+	closed  bool
+	ps      *PubSub[T]
+	policy  Policy
+	once    sync.Once
+	dropped atomic.Uint64
+	err     error
+}
+
+// Stats is a point-in-time view of a PubSub for metrics
+type Stats struct {
+	Topics       int
+	Subscribers  int
+	Queued       int
+	Published    uint64 // values published to topics with subscribers
+	Dropped      uint64
+	Disconnected uint64
+}
+
+// TopicStats is a point-in-time view of one topic; its counters start when it gets its first
+// subscriber and are gone once it has none
+type TopicStats struct {
+	Topic       string
+	Subscribers int
+	Queued      int
+	Published   uint64
+	Delivered   uint64
+	Dropped     uint64
+}
+
+// ErrClosed is the Err of subscriptions the PubSub's Close ended
+var ErrClosed = errors.New("pubsub closed")
+
+func New[T any](buffer int) *PubSub[T] {
+	return NewWithOptions[T](buffer, Options{})
+}
+
+func NewWithOptions[T any](buffer int, opts Options) *PubSub[T] {
+	p := &PubSub[T]{buffer: buffer, opts: opts}
+	for i := range p.shards {
+		p.shards[i].topics = make(map[string]*topic[T])
+	}
+	return p
+}
+
+func (p *PubSub[T]) shard(topic string) *shard[T] {
+	h := fnv.New32a()
+	h.Write([]byte(topic))
+	return &p.shards[h.Sum32()%shards]
+}
+
+func (p *PubSub[T]) Subscribe(topic string) *Subscription[T] {
+	return p.SubscribeWith(topic, p.opts)
+}
+
+// SubscribeWith subscribes with options other than the PubSub's defaults
+func (p *PubSub[T]) SubscribeWith(topic string, opts Options) *Subscription[T] {
+	size := p.buffer
+	if opts.Policy == Buffer && opts.Bound > 0 {
+		size = opts.Bound
+	}
+	ch := make(chan T, size)
+	s := &Subscription[T]{C: ch, ch: ch, topic: topic, topics: make(map[string]struct{}), ps: p, policy: opts.Policy}
+	s.Add(topic)
+	if p.closed.Load() {
+		// Close may have missed a subscription attached while it ran
+		s.close(ErrClosed)
+	}
+	return s
+}
+
+// attach adds s to topic; the caller holds s.mu
+func (p *PubSub[T]) attach(s *Subscription[T], name string) {
+	sh := p.shard(name)
+	sh.mu.Lock()
+	t := sh.topics[name]
+	if t == nil {
+		t = &topic[T]{subs: make(map[*Subscription[T]]struct{})}
+		sh.topics[name] = t
+	}
+	t.subs[s] = struct{}{}
+	sh.mu.Unlock()
+	s.topics[name] = struct{}{}
+}
+
+// detach removes s from topic; the caller holds s.mu. Once it returns no publisher is delivering to
+// s on that topic.
+func (p *PubSub[T]) detach(s *Subscription[T], name string) {
+	sh := p.shard(name)
+	sh.mu.Lock()
+	if t := sh.topics[name]; t != nil {
+		delete(t.subs, s)
+		if len(t.subs) == 0 {
+			delete(sh.topics, name)
+		}
+	}
+	sh.mu.Unlock()
+	delete(s.topics, name)
+}
+
+func (p *PubSub[T]) Publish(name string, v T) {
+	var slow []*Subscription[T]
+	sh := p.shard(name)
+	sh.mu.RLock()
+	if t := sh.topics[name]; t != nil {
+		t.published.Add(1)
+		for s := range t.subs {
+			if !s.deliver(t, v) {
+				slow = append(slow, s)
+			}
+		}
+	}
+	sh.mu.RUnlock()
+	for _, s := range slow {
+		p.disconnected.Add(1)
+		s.close(ErrSlowConsumer)
+	}
+}
+
+// deliver hands v to the subscriber and reports false if it must be disconnected
+func (s *Subscription[T]) deliver(t *topic[T], v T) bool {
+	select {
+	case s.ch <- v:
+		t.delivered.Add(1)
+		return true
+	default:
+	}
+	switch s.policy {
+	case Disconnect:
+		return false
+	case Conflate:
+		// make room by discarding the oldest update; another publisher may refill it, so retry once
+		for i := 0; i < 2; i++ {
+			select {
+			case <-s.ch:
+				s.miss(t)
+			default:
+			}
+			select {
+			case s.ch <- v:
+				t.delivered.Add(1)
+				return true
+			default:
+			}
+		}
+	}
+	s.miss(t)
+	return true
+}
+
+func (s *Subscription[T]) miss(t *topic[T]) {
+	s.dropped.Add(1)
+	t.dropped.Add(1)
+	s.ps.dropped.Add(1)
+}
+
+// Topics returns topics that currently have at least one subscriber
+func (p *PubSub[T]) Topics() []string {
+	var out []string
+	for i := range p.shards {
+		sh := &p.shards[i]
+		sh.mu.RLock()
+		for name := range sh.topics {
+			out = append(out, name)
+		}
+		sh.mu.RUnlock()
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (p *PubSub[T]) Stats() Stats {
+	st := Stats{
+		Dropped:      p.dropped.Load(),
+		Disconnected: p.disconnected.Load(),
+	}
+	for _, ts := range p.TopicStats() {
+		st.Topics++
+		st.Subscribers += ts.Subscribers
+		st.Queued += ts.Queued
+		st.Published += ts.Published
+	}
+	return st
+}
+
+// TopicStats returns the counters of every topic that has subscribers, sorted by topic
+func (p *PubSub[T]) TopicStats() []TopicStats {
+	var out []TopicStats
+	for i := range p.shards {
+		sh := &p.shards[i]
+		sh.mu.RLock()
+		for name, t := range sh.topics {
+			ts := TopicStats{
+				Topic:       name,
+				Subscribers: len(t.subs),
+				Published:   t.published.Load(),
+				Delivered:   t.delivered.Load(),
+				Dropped:     t.dropped.Load(),
+			}
+			for s := range t.subs {
+				ts.Queued += len(s.ch)
+			}
+			out = append(out, ts)
+		}
+		sh.mu.RUnlock()
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Topic < out[j].Topic })
+	return out
+}
+
+// Close ends every subscription with ErrClosed, and subscriptions made afterwards start closed;
+// publishing after Close reaches no one
+func (p *PubSub[T]) Close() {
+	p.closed.Store(true)
+	for i := range p.shards {
+		sh := &p.shards[i]
+		sh.mu.RLock()
+		var subs []*Subscription[T]
+		for _, t := range sh.topics {
+			for s := range t.subs {
+				subs = append(subs, s)
+			}
+		}
+		sh.mu.RUnlock()
+		for _, s := range subs {
+			s.close(ErrClosed)
+		}
+	}
+}
+
+// Close unsubscribes and closes the channel; safe to call more than once
+func (s *Subscription[T]) Close() {
+	s.close(nil)
+}
+
+func (s *Subscription[T]) close(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.err = err
+		for name := range s.topics {
+			s.ps.detach(s, name)
+		}
+		// detached from every topic, so no publisher can still be sending on ch
+		s.closed = true
+		close(s.ch)
+	})
+}
+
+// Topic returns the topic the subscription was created with
+func (s *Subscription[T]) Topic() string { return s.topic }
+
+// Add subscribes to more topics on the same channel; a value published to several of them is delivered once per topic
+func (s *Subscription[T]) Add(topics ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	for _, t := range topics {
+		s.ps.attach(s, t)
+	}
+}
+
+// Remove unsubscribes from topics while keeping the subscription open
+func (s *Subscription[T]) Remove(topics ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range topics {
+		if _, ok := s.topics[t]; ok {
+			s.ps.detach(s, t)
+		}
+	}
+}
+
+// Topics returns every topic the subscription currently receives
+func (s *Subscription[T]) Topics() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.topics))
+	for t := range s.topics {
+		out = append(out, t)
+	}
+	return out
+}
+
+// Err returns why the subscription was closed by the PubSub, nil if it is open or was closed by its owner.
+// It is valid once C is closed.
+func (s *Subscription[T]) Err() error { return s.err }
+
+// Dropped returns how many updates were missed since the previous call, so the consumer can report a gap
+func (s *Subscription[T]) Dropped() uint64 { return s.dropped.Swap(0) }
@@ -0,0 +1,145 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentPublishAndUnsubscribe(t *testing.T) {
+	p := New[int](1)
+	topics := make([]string, 40)
+	for i := range topics {
+		topics[i] = fmt.Sprintf("SYM%d", i)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				p.Publish(topics[n%len(topics)], n)
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		s := p.Subscribe(topics[i%len(topics)])
+		s.Add(topics...)
+		go func() {
+			for range s.C {
+			}
+		}()
+		s.Remove(topics[:10]...)
+		s.Close()
+	}
+	close(stop)
+	wg.Wait()
+
+	if st := p.Stats(); st.Subscribers != 0 || st.Topics != 0 {
+		t.Errorf("stats after every subscription closed: %+v", st)
+	}
+}
+
+func TestTopicStats(t *testing.T) {
+	p := New[int](1)
+	a := p.Subscribe("A")
+	defer a.Close()
+	b := p.Subscribe("B")
+	defer b.Close()
+
+	p.Publish("A", 1)
+	p.Publish("A", 2) // the buffer is full: dropped
+	p.Publish("C", 3) // no subscribers: not counted
+
+	got := p.TopicStats()
+	want := []TopicStats{
+		{Topic: "A", Subscribers: 1, Queued: 1, Published: 2, Delivered: 1, Dropped: 1},
+		{Topic: "B", Subscribers: 1},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("TopicStats() = %+v, want %+v", got, want)
+	}
+	if st := p.Stats(); st.Published != 2 || st.Dropped != 1 || st.Subscribers != 2 {
+		t.Errorf("Stats() = %+v", st)
+	}
+}
+
+func TestClose(t *testing.T) {
+	p := New[int](1)
+	s := p.Subscribe("A")
+	p.Close()
+	if _, ok := <-s.C; ok || !errors.Is(s.Err(), ErrClosed) {
+		t.Errorf("subscription after Close: open %v, err %v", ok, s.Err())
+	}
+	late := p.Subscribe("A")
+	if _, ok := <-late.C; ok || !errors.Is(late.Err(), ErrClosed) {
+		t.Errorf("subscription made after Close: open %v, err %v", ok, late.Err())
+	}
+	p.Publish("A", 1)
+	if len(p.Topics()) != 0 {
+		t.Errorf("topics after Close: %v", p.Topics())
+	}
+}
+
+func TestSlowConsumerPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		received []int
+		dropped  uint64
+		err      error
+	}{
+		{"drop", Options{Policy: Drop}, []int{1, 2}, 2, nil},
+		{"disconnect", Options{Policy: Disconnect}, []int{1, 2}, 0, ErrSlowConsumer},
+		{"conflate", Options{Policy: Conflate}, []int{3, 4}, 2, nil},
+		{"buffer", Options{Policy: Buffer, Bound: 3}, []int{1, 2, 3}, 1, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := New[int](2)
+			s := p.SubscribeWith("A", tc.opts)
+			defer s.Close()
+			for v := 1; v <= 4; v++ {
+				p.Publish("A", v)
+			}
+
+			var got []int
+			for len(got) < len(tc.received) {
+				v, ok := <-s.C
+				if !ok {
+					break
+				}
+				got = append(got, v)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.received) {
+				t.Errorf("received %v, want %v", got, tc.received)
+			}
+			if n := s.Dropped(); n != tc.dropped {
+				t.Errorf("Dropped() = %d, want %d", n, tc.dropped)
+			}
+			if n := s.Dropped(); n != 0 {
+				t.Errorf("Dropped() again = %d, want the gap reported once", n)
+			}
+			if tc.err != nil {
+				if _, ok := <-s.C; ok || !errors.Is(s.Err(), tc.err) {
+					t.Errorf("subscription after falling behind: open %v, err %v", ok, s.Err())
+				}
+			}
+			st := p.Stats()
+			if st.Dropped != tc.dropped {
+				t.Errorf("Stats().Dropped = %d, want %d", st.Dropped, tc.dropped)
+			}
+			if wantDisconnected := tc.err != nil; (st.Disconnected == 1) != wantDisconnected {
+				t.Errorf("Stats().Disconnected = %d", st.Disconnected)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+)
+
+// Throttle forwards values from in at most once per interval. Values arriving in between are
+// conflated: only the latest is delivered when the interval ends. The returned channel is closed
+// when in is closed or ctx is done; a non-positive interval returns in unchanged.
+func Throttle[T any](ctx context.Context, in <-chan T, interval time.Duration) <-chan T {
+	return ThrottleBy(ctx, in, interval, func(T) struct{} { return struct{}{} })
+}
+
+// ThrottleBy is Throttle conflating separately per key, e.g. per symbol on a multi-symbol stream.
+// At the end of an interval the latest value of every key that changed is delivered.
+func ThrottleBy[T any, K comparable](ctx context.Context, in <-chan T, interval time.Duration, key func(T) K) <-chan T {
+	if interval <= 0 {
+		return in
+	}
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		var (
+			pending = make(map[K]T)
+			order   []K
+			next    time.Time
+			timer   *time.Timer
+			timerC  <-chan time.Time
+		)
+		flush := func() bool {
+			for _, k := range order {
+				select {
+				case out <- pending[k]:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			clear(pending)
+			order = order[:0]
+			next = time.Now().Add(interval)
+			return true
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				k := key(v)
+				if _, seen := pending[k]; !seen {
+					order = append(order, k)
+				}
+				pending[k] = v
+				if timerC != nil {
+					continue
+				}
+				wait := time.Until(next)
+				if wait <= 0 {
+					if !flush() {
+						return
+					}
+					continue
+				}
+				if timer == nil {
+					timer = time.NewTimer(wait)
+					defer timer.Stop()
+				} else {
+					timer.Reset(wait)
+				}
+				timerC = timer.C
+			case <-timerC:
+				timerC = nil
+				if !flush() {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// RateInterval converts a maximum rate in updates per second into a Throttle interval, 0 meaning unlimited
+func RateInterval(perSecond float64) time.Duration {
+	if perSecond <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / perSecond)
+}
@@ -0,0 +1,53 @@
+// Package record captures order-entry traffic as it reaches the APIs and replays it against another
+// environment, at the pace it arrived or faster. Recordings are JSON lines, one Entry per request;
+// API keys are never written, the replayer authenticates with its own.
+package record
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	ProtoHTTP = "http"
+	ProtoGRPC = "grpc"
+)
+
+// Entry is one recorded request. HTTP entries keep the method, path, query and client header; gRPC
+// entries keep the full method name in Path and the request message as protojson in Body.
+type Entry struct {
+	At       time.Time       `json:"at"`
+	Proto    string          `json:"proto"`
+	Method   string          `json:"method,omitempty"`
+	Path     string          `json:"path"`
+	Query    string          `json:"query,omitempty"`
+	ClientID string          `json:"client_id,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+}
+
+// Recorder appends entries to a writer. It is safe for concurrent use; a failed write is passed to
+// report and otherwise ignored, so recording never fails the request it records.
+type Recorder struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	report func(error)
+}
+
+func NewRecorder(w io.Writer, report func(error)) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w), report: report}
+}
+
+// Record stamps e with the current time unless it has one and writes it
+func (r *Recorder) Record(e Entry) {
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	r.mu.Lock()
+	err := r.enc.Encode(e)
+	r.mu.Unlock()
+	if err != nil && r.report != nil {
+		r.report(err)
+	}
+}
@@ -0,0 +1,77 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "github.com/olyamironova/exchange-engine/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReplayHTTP(t *testing.T) {
+	type received struct {
+		path, query, key, client, body string
+	}
+	var got []received
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, received{r.URL.Path, r.URL.RawQuery, r.Header.Get("X-API-Key"), r.Header.Get("X-Client-ID"), string(body)})
+		if r.URL.Path == "/orders/cancel" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer target.Close()
+
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf, func(err error) { t.Errorf("record: %v", err) })
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	rec.Record(Entry{At: at, Proto: ProtoHTTP, Method: "POST", Path: "/orders", ClientID: "c1", Body: json.RawMessage(`{"symbol":"BTC/USD"}`)})
+	rec.Record(Entry{At: at.Add(time.Second), Proto: ProtoGRPC, Path: pb.Exchange_CancelOrder_FullMethodName, Body: json.RawMessage(`{}`)})
+	rec.Record(Entry{At: at.Add(2 * time.Second), Proto: ProtoHTTP, Method: "POST", Path: "/orders/cancel", Query: "x=1", ClientID: "c1"})
+
+	var failed []string
+	p := &Replayer{HTTPBase: target.URL, APIKey: "replay-key", Speed: 20, Report: func(e Entry, err error) { failed = append(failed, e.Path) }}
+	start := time.Now()
+	stats, err := p.Replay(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("2s recorded at 20x replayed in %v, want about 100ms", elapsed)
+	}
+	if stats != (ReplayStats{Sent: 2, Failed: 1, Skipped: 1}) {
+		t.Errorf("stats = %+v", stats)
+	}
+	want := []received{
+		{"/orders", "", "replay-key", "c1", `{"symbol":"BTC/USD"}`},
+		{"/orders/cancel", "x=1", "replay-key", "c1", ""},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("target received %+v, want %+v", got, want)
+	}
+	if len(failed) != 1 || failed[0] != "/orders/cancel" {
+		t.Errorf("reported %v, want the rejected cancel", failed)
+	}
+}
+
+func TestMessagesFor(t *testing.T) {
+	req, resp, err := messagesFor(pb.Exchange_SubmitOrder_FullMethodName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := req.(*pb.SubmitOrderRequest); !ok {
+		t.Errorf("request is %T", req)
+	}
+	if proto.MessageName(resp) == "" {
+		t.Errorf("response has no type")
+	}
+	if _, _, err := messagesFor("/exchange.Exchange/NoSuchMethod"); err == nil {
+		t.Error("unknown method resolved")
+	}
+}
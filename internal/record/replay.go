@@ -0,0 +1,181 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	// registers the exchange service, so recorded gRPC methods resolve to their message types
+	_ "github.com/olyamironova/exchange-engine/proto"
+)
+
+// Replayer re-sends a recording to a target environment. Entries of a protocol without a target are
+// skipped.
+type Replayer struct {
+	// HTTPBase is the target's base URL, e.g. "http://localhost:8080"
+	HTTPBase   string
+	HTTPClient *http.Client
+	GRPC       *grpc.ClientConn
+	// APIKey authenticates every replayed request
+	APIKey string
+	// Speed divides the recorded gaps between requests: 1 keeps the original pace, 10 replays ten
+	// times faster, 0 sends each request as soon as the previous one is answered
+	Speed float64
+	// Report, if set, is called with every entry the target rejected or could not be reached for
+	Report func(Entry, error)
+}
+
+// ReplayStats counts the entries sent, those the target rejected or did not answer, and those skipped
+type ReplayStats struct {
+	Sent    int
+	Failed  int
+	Skipped int
+}
+
+// Replay sends the entries read from r in order, keeping their recorded spacing scaled by Speed. It
+// stops at the end of the recording, when ctx ends, or at an entry it cannot read.
+func (p *Replayer) Replay(ctx context.Context, r io.Reader) (ReplayStats, error) {
+	var stats ReplayStats
+	dec := json.NewDecoder(r)
+	var first time.Time
+	start := time.Now()
+	for {
+		var e Entry
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return stats, nil
+			}
+			return stats, fmt.Errorf("read entry %d: %w", stats.Sent+stats.Skipped+1, err)
+		}
+		if first.IsZero() {
+			first = e.At
+		}
+		if p.Speed > 0 {
+			due := start.Add(time.Duration(float64(e.At.Sub(first)) / p.Speed))
+			if err := sleepUntil(ctx, due); err != nil {
+				return stats, err
+			}
+		} else if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		var err error
+		switch {
+		case e.Proto == ProtoHTTP && p.HTTPBase != "":
+			err = p.sendHTTP(ctx, e)
+		case e.Proto == ProtoGRPC && p.GRPC != nil:
+			err = p.sendGRPC(ctx, e)
+		default:
+			stats.Skipped++
+			continue
+		}
+		stats.Sent++
+		if err != nil {
+			stats.Failed++
+			if p.Report != nil {
+				p.Report(e, err)
+			}
+		}
+	}
+}
+
+func sleepUntil(ctx context.Context, due time.Time) error {
+	d := time.Until(due)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Replayer) sendHTTP(ctx context.Context, e Entry) error {
+	url := strings.TrimSuffix(p.HTTPBase, "/") + e.Path
+	if e.Query != "" {
+		url += "?" + e.Query
+	}
+	req, err := http.NewRequestWithContext(ctx, e.Method, url, bytes.NewReader(e.Body))
+	if err != nil {
+		return err
+	}
+	if len(e.Body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-API-Key", p.APIKey)
+	if e.ClientID != "" {
+		req.Header.Set("X-Client-ID", e.ClientID)
+	}
+	client := p.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+func (p *Replayer) sendGRPC(ctx context.Context, e Entry) error {
+	req, resp, err := messagesFor(e.Path)
+	if err != nil {
+		return err
+	}
+	if err := protojson.Unmarshal(e.Body, req); err != nil {
+		return fmt.Errorf("decode %s request: %w", e.Path, err)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", p.APIKey)
+	return p.GRPC.Invoke(ctx, e.Path, req, resp)
+}
+
+// messagesFor returns empty request and response messages of a full gRPC method name
+func messagesFor(fullMethod string) (proto.Message, proto.Message, error) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil, nil, fmt.Errorf("bad gRPC method %q", fullMethod)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unknown gRPC service %q", service)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, nil, fmt.Errorf("%q is not a gRPC service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, nil, fmt.Errorf("unknown gRPC method %q", fullMethod)
+	}
+	in, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, nil, err
+	}
+	return in.New().Interface(), out.New().Interface(), nil
+}
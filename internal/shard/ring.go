@@ -0,0 +1,211 @@
+// Package shard assigns symbols to a fixed pool of workers with consistent hashing.
+package shard
+
+import (
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Move is a symbol that changed worker during a rebalance
+type Move struct {
+	Symbol string
+	From   int
+	To     int
+}
+
+// RebalanceHook is called, outside the ring's lock, with the symbols a rebalance moved
+type RebalanceHook func(moves []Move)
+
+// Ring maps symbols onto workers 0..n-1. Every worker owns replicas points on a hash ring and
+// a symbol belongs to the first point after its hash, so taking a worker out of the ring only
+// moves the symbols it owned. A worker can be dedicated to a single hot symbol with Isolate.
+type Ring struct {
+	mu        sync.RWMutex
+	workers   int
+	replicas  int
+	points    []uint32
+	owners    map[uint32]int
+	dedicated map[int]string // worker -> the only symbol it serves
+	pinned    map[string]int // symbol -> dedicated worker
+	known     map[string]struct{}
+	hooks     []RebalanceHook
+}
+
+func NewRing(workers, replicas int) *Ring {
+	if workers < 1 {
+		workers = 1
+	}
+	if replicas < 1 {
+		replicas = 64
+	}
+	r := &Ring{
+		workers:   workers,
+		replicas:  replicas,
+		dedicated: make(map[int]string),
+		pinned:    make(map[string]int),
+		known:     make(map[string]struct{}),
+	}
+	r.build()
+	return r
+}
+
+// hash is FNV-1a finished with the splitmix64 mixer; plain FNV clusters short, similar keys
+func hash(s string) uint32 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return uint32(x >> 32)
+}
+
+// build places the points of every worker that is not dedicated; the caller holds mu
+func (r *Ring) build() {
+	r.points = r.points[:0]
+	r.owners = make(map[uint32]int, r.workers*r.replicas)
+	for w := 0; w < r.workers; w++ {
+		if _, ok := r.dedicated[w]; ok {
+			continue
+		}
+		for i := 0; i < r.replicas; i++ {
+			p := hash(strconv.Itoa(w) + "#" + strconv.Itoa(i))
+			if _, taken := r.owners[p]; taken {
+				continue
+			}
+			r.owners[p] = w
+			r.points = append(r.points, p)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+}
+
+// locate returns the symbol's worker; the caller holds mu
+func (r *Ring) locate(symbol string) int {
+	if w, ok := r.pinned[symbol]; ok {
+		return w
+	}
+	h := hash(symbol)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// Lookup returns the worker serving symbol
+func (r *Ring) Lookup(symbol string) int {
+	r.mu.RLock()
+	w := r.locate(symbol)
+	_, seen := r.known[symbol]
+	r.mu.RUnlock()
+	if !seen {
+		r.mu.Lock()
+		r.known[symbol] = struct{}{}
+		r.mu.Unlock()
+	}
+	return w
+}
+
+// Workers returns the pool size
+func (r *Ring) Workers() int { return r.workers }
+
+// OnRebalance registers a hook called after Isolate or Release moved symbols
+func (r *Ring) OnRebalance(h RebalanceHook) {
+	r.mu.Lock()
+	r.hooks = append(r.hooks, h)
+	r.mu.Unlock()
+}
+
+// Isolate dedicates the symbol's current worker to it alone; the worker's other symbols are
+// spread over the remaining shared workers. It returns the dedicated worker.
+func (r *Ring) Isolate(symbol string) (int, error) {
+	r.mu.Lock()
+	if w, ok := r.pinned[symbol]; ok {
+		r.mu.Unlock()
+		return w, nil
+	}
+	if r.workers-len(r.dedicated) < 2 {
+		r.mu.Unlock()
+		return 0, errors.New("no shared worker would be left")
+	}
+	r.known[symbol] = struct{}{}
+	w := r.locate(symbol)
+	moves := r.rebalance(func() {
+		r.dedicated[w] = symbol
+		r.pinned[symbol] = w
+	})
+	r.notify(moves)
+	return w, nil
+}
+
+// Release returns an isolated symbol's worker to the shared pool
+func (r *Ring) Release(symbol string) error {
+	r.mu.Lock()
+	w, ok := r.pinned[symbol]
+	if !ok {
+		r.mu.Unlock()
+		return errors.New("symbol is not isolated")
+	}
+	moves := r.rebalance(func() {
+		delete(r.dedicated, w)
+		delete(r.pinned, symbol)
+	})
+	r.notify(moves)
+	return nil
+}
+
+// rebalance applies change, rebuilds the ring and unlocks mu, returning the known symbols that moved
+func (r *Ring) rebalance(change func()) []Move {
+	before := make(map[string]int, len(r.known))
+	for s := range r.known {
+		before[s] = r.locate(s)
+	}
+	change()
+	r.build()
+	var moves []Move
+	for s, from := range before {
+		if to := r.locate(s); to != from {
+			moves = append(moves, Move{Symbol: s, From: from, To: to})
+		}
+	}
+	sort.Slice(moves, func(i, j int) bool { return moves[i].Symbol < moves[j].Symbol })
+	r.mu.Unlock()
+	return moves
+}
+
+func (r *Ring) notify(moves []Move) {
+	if len(moves) == 0 {
+		return
+	}
+	r.mu.RLock()
+	hooks := append([]RebalanceHook(nil), r.hooks...)
+	r.mu.RUnlock()
+	for _, h := range hooks {
+		h(moves)
+	}
+}
+
+// Assignment returns the symbols seen so far grouped by worker, and which workers are dedicated
+func (r *Ring) Assignment() (map[int][]string, map[int]string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[int][]string, r.workers)
+	for s := range r.known {
+		w := r.locate(s)
+		out[w] = append(out[w], s)
+	}
+	for w := range out {
+		sort.Strings(out[w])
+	}
+	dedicated := make(map[int]string, len(r.dedicated))
+	for w, s := range r.dedicated {
+		dedicated[w] = s
+	}
+	return out, dedicated
+}
@@ -0,0 +1,94 @@
+package shard
+
+import (
+	"fmt"
+	"testing"
+)
+
+// placement must not change between builds: instances sharing the books route a symbol alike
+func TestHashStable(t *testing.T) {
+	tests := []struct {
+		key  string
+		want uint32
+	}{
+		{"", 0xf52a15e9},
+		{"BTC/USD", 0xc718e276},
+		{"ETH/USD", 0x6be9e717},
+		{"SOL/USD", 0xd8e7d33e},
+		{"0#0", 0x9c382807}, // worker 0's first point
+	}
+	for _, tc := range tests {
+		if got := hash(tc.key); got != tc.want {
+			t.Errorf("hash(%q) = %#x, want %#x", tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestLookupStable(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   int
+	}{
+		{"BTC/USD", 0},
+		{"ETH/USD", 2},
+		{"SOL/USD", 3},
+		{"XRP/USD", 0},
+		{"DOGE/USD", 1},
+		{"ADA/USD", 1},
+	}
+	a, b := NewRing(4, 64), NewRing(4, 64)
+	for _, tc := range tests {
+		if got := a.Lookup(tc.symbol); got != tc.want {
+			t.Errorf("Lookup(%q) = %d, want %d", tc.symbol, got, tc.want)
+		}
+		if again, other := a.Lookup(tc.symbol), b.Lookup(tc.symbol); again != tc.want || other != tc.want {
+			t.Errorf("Lookup(%q) again = %d, on another ring = %d, want %d", tc.symbol, again, other, tc.want)
+		}
+	}
+}
+
+func TestIsolateMovesOnlyItsWorkersSymbols(t *testing.T) {
+	r := NewRing(4, 64)
+	btc := r.Lookup("BTC/USD")
+	before := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		s := fmt.Sprintf("SYM%d/USD", i)
+		before[s] = r.Lookup(s)
+	}
+	var moved []Move
+	r.OnRebalance(func(moves []Move) { moved = append(moved, moves...) })
+
+	w, err := r.Isolate("BTC/USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w != btc {
+		t.Fatalf("isolated on worker %d, the symbol was on %d", w, btc)
+	}
+	for s, from := range before {
+		to := r.Lookup(s)
+		switch {
+		case from != w && to != from:
+			t.Errorf("%s moved from %d to %d, but only worker %d was isolated", s, from, to, w)
+		case from == w && to == w:
+			t.Errorf("%s stayed on the dedicated worker %d", s, w)
+		}
+	}
+	if got := r.Lookup("BTC/USD"); got != w {
+		t.Errorf("isolated symbol on worker %d, want %d", got, w)
+	}
+	for _, m := range moved {
+		if m.From != w {
+			t.Errorf("hook reported %+v, want moves off worker %d only", m, w)
+		}
+	}
+
+	if err := r.Release("BTC/USD"); err != nil {
+		t.Fatal(err)
+	}
+	for s, want := range before {
+		if got := r.Lookup(s); got != want {
+			t.Errorf("after release %s is on %d, want %d", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package tenant
+
+import (
+	"context"
+	"strings"
+)
+
+// Default is the tenant of requests that were not resolved to any other
+const Default = "default"
+
+type ctxKey struct{}
+
+// With attaches the tenant id to ctx, an empty id selects Default
+func With(ctx context.Context, id string) context.Context {
+	if id == "" {
+		id = Default
+	}
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// From returns the request's tenant, Default if none was set
+func From(ctx context.Context) string {
+	if id, ok := ctx.Value(ctxKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
+
+const sandboxPrefix = "sandbox:"
+
+// Sandbox returns the paper-trading namespace shadowing tenant id
+func Sandbox(id string) string {
+	if strings.HasPrefix(id, sandboxPrefix) {
+		return id
+	}
+	return sandboxPrefix + id
+}
+
+// IsSandbox reports whether ctx belongs to a paper-trading namespace
+func IsSandbox(ctx context.Context) bool {
+	return strings.HasPrefix(From(ctx), sandboxPrefix)
+}
+
+// Scope prefixes name with the tenant, e.g. for cache keys and stream topics
+func Scope(ctx context.Context, name string) string {
+	return ScopeID(From(ctx), name)
+}
+
+// ScopeID is Scope for a known tenant id
+func ScopeID(tenantID, name string) string {
+	return tenantID + "/" + name
+}
+
+// Split reverses Scope
+func Split(scoped string) (tenantID, name string) {
+	tenantID, name, ok := strings.Cut(scoped, "/")
+	if !ok {
+		return Default, scoped
+	}
+	return tenantID, name
+}
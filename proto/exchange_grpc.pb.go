@@ -19,14 +19,48 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Exchange_SubmitOrder_FullMethodName       = "/proto.Exchange/SubmitOrder"
-	Exchange_ModifyOrder_FullMethodName       = "/proto.Exchange/ModifyOrder"
-	Exchange_CancelOrder_FullMethodName       = "/proto.Exchange/CancelOrder"
-	Exchange_GetOrder_FullMethodName          = "/proto.Exchange/GetOrder"
-	Exchange_GetTradesForOrder_FullMethodName = "/proto.Exchange/GetTradesForOrder"
-	Exchange_GetOrderbook_FullMethodName      = "/proto.Exchange/GetOrderbook"
-	Exchange_SnapshotOrderbook_FullMethodName = "/proto.Exchange/SnapshotOrderbook"
-	Exchange_RestoreOrderbook_FullMethodName  = "/proto.Exchange/RestoreOrderbook"
+	Exchange_SubmitOrder_FullMethodName              = "/proto.Exchange/SubmitOrder"
+	Exchange_BatchSubmitOrders_FullMethodName        = "/proto.Exchange/BatchSubmitOrders"
+	Exchange_SubmitOrderGroup_FullMethodName         = "/proto.Exchange/SubmitOrderGroup"
+	Exchange_CancelOrderGroup_FullMethodName         = "/proto.Exchange/CancelOrderGroup"
+	Exchange_PreviewOrder_FullMethodName             = "/proto.Exchange/PreviewOrder"
+	Exchange_ModifyOrder_FullMethodName              = "/proto.Exchange/ModifyOrder"
+	Exchange_CancelOrder_FullMethodName              = "/proto.Exchange/CancelOrder"
+	Exchange_BatchCancelOrders_FullMethodName        = "/proto.Exchange/BatchCancelOrders"
+	Exchange_BatchModifyOrders_FullMethodName        = "/proto.Exchange/BatchModifyOrders"
+	Exchange_CancelBySide_FullMethodName             = "/proto.Exchange/CancelBySide"
+	Exchange_CancelAllOrders_FullMethodName          = "/proto.Exchange/CancelAllOrders"
+	Exchange_ForceCancelOrder_FullMethodName         = "/proto.Exchange/ForceCancelOrder"
+	Exchange_Login_FullMethodName                    = "/proto.Exchange/Login"
+	Exchange_Heartbeat_FullMethodName                = "/proto.Exchange/Heartbeat"
+	Exchange_Logout_FullMethodName                   = "/proto.Exchange/Logout"
+	Exchange_CancelSessionOrders_FullMethodName      = "/proto.Exchange/CancelSessionOrders"
+	Exchange_GetOrder_FullMethodName                 = "/proto.Exchange/GetOrder"
+	Exchange_GetOrderByClientOrderID_FullMethodName  = "/proto.Exchange/GetOrderByClientOrderID"
+	Exchange_ListOrders_FullMethodName               = "/proto.Exchange/ListOrders"
+	Exchange_GetTradesForOrder_FullMethodName        = "/proto.Exchange/GetTradesForOrder"
+	Exchange_GetTrades_FullMethodName                = "/proto.Exchange/GetTrades"
+	Exchange_GetTrade_FullMethodName                 = "/proto.Exchange/GetTrade"
+	Exchange_GetQueuePosition_FullMethodName         = "/proto.Exchange/GetQueuePosition"
+	Exchange_GetOrderbook_FullMethodName             = "/proto.Exchange/GetOrderbook"
+	Exchange_GetQuote_FullMethodName                 = "/proto.Exchange/GetQuote"
+	Exchange_GetDepth_FullMethodName                 = "/proto.Exchange/GetDepth"
+	Exchange_GetCandles_FullMethodName               = "/proto.Exchange/GetCandles"
+	Exchange_GetRecentTrades_FullMethodName          = "/proto.Exchange/GetRecentTrades"
+	Exchange_SnapshotOrderbook_FullMethodName        = "/proto.Exchange/SnapshotOrderbook"
+	Exchange_RestoreOrderbook_FullMethodName         = "/proto.Exchange/RestoreOrderbook"
+	Exchange_ListSnapshots_FullMethodName            = "/proto.Exchange/ListSnapshots"
+	Exchange_GetSnapshot_FullMethodName              = "/proto.Exchange/GetSnapshot"
+	Exchange_DeleteSnapshot_FullMethodName           = "/proto.Exchange/DeleteSnapshot"
+	Exchange_ExportSnapshot_FullMethodName           = "/proto.Exchange/ExportSnapshot"
+	Exchange_ImportSnapshot_FullMethodName           = "/proto.Exchange/ImportSnapshot"
+	Exchange_StreamImbalance_FullMethodName          = "/proto.Exchange/StreamImbalance"
+	Exchange_StreamOrderbook_FullMethodName          = "/proto.Exchange/StreamOrderbook"
+	Exchange_StreamTrades_FullMethodName             = "/proto.Exchange/StreamTrades"
+	Exchange_StreamQuotes_FullMethodName             = "/proto.Exchange/StreamQuotes"
+	Exchange_StreamOrderEvents_FullMethodName        = "/proto.Exchange/StreamOrderEvents"
+	Exchange_StreamSurveillanceAlerts_FullMethodName = "/proto.Exchange/StreamSurveillanceAlerts"
+	Exchange_UpdateSubscription_FullMethodName       = "/proto.Exchange/UpdateSubscription"
 )
 
 // ExchangeClient is the client API for Exchange service.
@@ -34,13 +68,49 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ExchangeClient interface {
 	SubmitOrder(ctx context.Context, in *SubmitOrderRequest, opts ...grpc.CallOption) (*SubmitOrderResponse, error)
+	BatchSubmitOrders(ctx context.Context, in *BatchSubmitOrdersRequest, opts ...grpc.CallOption) (*BatchSubmitOrdersResponse, error)
+	SubmitOrderGroup(ctx context.Context, in *SubmitOrderGroupRequest, opts ...grpc.CallOption) (*SubmitOrderGroupResponse, error)
+	CancelOrderGroup(ctx context.Context, in *CancelOrderGroupRequest, opts ...grpc.CallOption) (*CancelOrderGroupResponse, error)
+	PreviewOrder(ctx context.Context, in *SubmitOrderRequest, opts ...grpc.CallOption) (*PreviewOrderResponse, error)
 	ModifyOrder(ctx context.Context, in *ModifyOrderRequest, opts ...grpc.CallOption) (*ModifyOrderResponse, error)
 	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+	BatchCancelOrders(ctx context.Context, in *BatchCancelOrdersRequest, opts ...grpc.CallOption) (*BatchCancelOrdersResponse, error)
+	BatchModifyOrders(ctx context.Context, in *BatchModifyOrdersRequest, opts ...grpc.CallOption) (*BatchModifyOrdersResponse, error)
+	CancelBySide(ctx context.Context, in *CancelBySideRequest, opts ...grpc.CallOption) (*CancelBySideResponse, error)
+	CancelAllOrders(ctx context.Context, in *CancelAllOrdersRequest, opts ...grpc.CallOption) (*CancelAllOrdersResponse, error)
+	ForceCancelOrder(ctx context.Context, in *ForceCancelRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+	// session calls other than Login and orders placed through a session send its token as x-session-token metadata
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*Session, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Session, error)
+	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*SessionCancelResponse, error)
+	CancelSessionOrders(ctx context.Context, in *CancelSessionOrdersRequest, opts ...grpc.CallOption) (*SessionCancelResponse, error)
 	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*GetOrderResponse, error)
+	GetOrderByClientOrderID(ctx context.Context, in *GetOrderByClientOrderIDRequest, opts ...grpc.CallOption) (*GetOrderResponse, error)
+	ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
 	GetTradesForOrder(ctx context.Context, in *GetTradesRequest, opts ...grpc.CallOption) (*GetTradesResponse, error)
+	// GetTrades pages through the stored trades of a symbol, oldest first
+	GetTrades(ctx context.Context, in *GetTradesRequest, opts ...grpc.CallOption) (*GetTradesResponse, error)
+	GetTrade(ctx context.Context, in *GetTradeRequest, opts ...grpc.CallOption) (*GetTradeResponse, error)
+	GetQueuePosition(ctx context.Context, in *GetQueuePositionRequest, opts ...grpc.CallOption) (*GetQueuePositionResponse, error)
 	GetOrderbook(ctx context.Context, in *GetOrderbookRequest, opts ...grpc.CallOption) (*GetOrderbookResponse, error)
+	GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*GetQuoteResponse, error)
+	GetDepth(ctx context.Context, in *GetDepthRequest, opts ...grpc.CallOption) (*GetDepthResponse, error)
+	GetCandles(ctx context.Context, in *GetCandlesRequest, opts ...grpc.CallOption) (*GetCandlesResponse, error)
+	GetRecentTrades(ctx context.Context, in *GetRecentTradesRequest, opts ...grpc.CallOption) (*GetRecentTradesResponse, error)
 	SnapshotOrderbook(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error)
 	RestoreOrderbook(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error)
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
+	GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (*GetSnapshotResponse, error)
+	DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*DeleteSnapshotResponse, error)
+	ExportSnapshot(ctx context.Context, in *ExportSnapshotRequest, opts ...grpc.CallOption) (*ExportSnapshotResponse, error)
+	ImportSnapshot(ctx context.Context, in *ImportSnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error)
+	StreamImbalance(ctx context.Context, in *StreamImbalanceRequest, opts ...grpc.CallOption) (Exchange_StreamImbalanceClient, error)
+	StreamOrderbook(ctx context.Context, in *StreamOrderbookRequest, opts ...grpc.CallOption) (Exchange_StreamOrderbookClient, error)
+	StreamTrades(ctx context.Context, in *StreamTradesRequest, opts ...grpc.CallOption) (Exchange_StreamTradesClient, error)
+	StreamQuotes(ctx context.Context, in *StreamQuotesRequest, opts ...grpc.CallOption) (Exchange_StreamQuotesClient, error)
+	StreamOrderEvents(ctx context.Context, in *StreamOrderEventsRequest, opts ...grpc.CallOption) (Exchange_StreamOrderEventsClient, error)
+	StreamSurveillanceAlerts(ctx context.Context, in *StreamSurveillanceAlertsRequest, opts ...grpc.CallOption) (Exchange_StreamSurveillanceAlertsClient, error)
+	UpdateSubscription(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*UpdateSubscriptionResponse, error)
 }
 
 type exchangeClient struct {
@@ -60,6 +130,42 @@ func (c *exchangeClient) SubmitOrder(ctx context.Context, in *SubmitOrderRequest
 	return out, nil
 }
 
+func (c *exchangeClient) BatchSubmitOrders(ctx context.Context, in *BatchSubmitOrdersRequest, opts ...grpc.CallOption) (*BatchSubmitOrdersResponse, error) {
+	out := new(BatchSubmitOrdersResponse)
+	err := c.cc.Invoke(ctx, Exchange_BatchSubmitOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) SubmitOrderGroup(ctx context.Context, in *SubmitOrderGroupRequest, opts ...grpc.CallOption) (*SubmitOrderGroupResponse, error) {
+	out := new(SubmitOrderGroupResponse)
+	err := c.cc.Invoke(ctx, Exchange_SubmitOrderGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) CancelOrderGroup(ctx context.Context, in *CancelOrderGroupRequest, opts ...grpc.CallOption) (*CancelOrderGroupResponse, error) {
+	out := new(CancelOrderGroupResponse)
+	err := c.cc.Invoke(ctx, Exchange_CancelOrderGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) PreviewOrder(ctx context.Context, in *SubmitOrderRequest, opts ...grpc.CallOption) (*PreviewOrderResponse, error) {
+	out := new(PreviewOrderResponse)
+	err := c.cc.Invoke(ctx, Exchange_PreviewOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *exchangeClient) ModifyOrder(ctx context.Context, in *ModifyOrderRequest, opts ...grpc.CallOption) (*ModifyOrderResponse, error) {
 	out := new(ModifyOrderResponse)
 	err := c.cc.Invoke(ctx, Exchange_ModifyOrder_FullMethodName, in, out, opts...)
@@ -78,6 +184,87 @@ func (c *exchangeClient) CancelOrder(ctx context.Context, in *CancelOrderRequest
 	return out, nil
 }
 
+func (c *exchangeClient) BatchCancelOrders(ctx context.Context, in *BatchCancelOrdersRequest, opts ...grpc.CallOption) (*BatchCancelOrdersResponse, error) {
+	out := new(BatchCancelOrdersResponse)
+	err := c.cc.Invoke(ctx, Exchange_BatchCancelOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) BatchModifyOrders(ctx context.Context, in *BatchModifyOrdersRequest, opts ...grpc.CallOption) (*BatchModifyOrdersResponse, error) {
+	out := new(BatchModifyOrdersResponse)
+	err := c.cc.Invoke(ctx, Exchange_BatchModifyOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) CancelBySide(ctx context.Context, in *CancelBySideRequest, opts ...grpc.CallOption) (*CancelBySideResponse, error) {
+	out := new(CancelBySideResponse)
+	err := c.cc.Invoke(ctx, Exchange_CancelBySide_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) CancelAllOrders(ctx context.Context, in *CancelAllOrdersRequest, opts ...grpc.CallOption) (*CancelAllOrdersResponse, error) {
+	out := new(CancelAllOrdersResponse)
+	err := c.cc.Invoke(ctx, Exchange_CancelAllOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) ForceCancelOrder(ctx context.Context, in *ForceCancelRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error) {
+	out := new(CancelOrderResponse)
+	err := c.cc.Invoke(ctx, Exchange_ForceCancelOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*Session, error) {
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Exchange_Login_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Session, error) {
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Exchange_Heartbeat_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*SessionCancelResponse, error) {
+	out := new(SessionCancelResponse)
+	err := c.cc.Invoke(ctx, Exchange_Logout_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) CancelSessionOrders(ctx context.Context, in *CancelSessionOrdersRequest, opts ...grpc.CallOption) (*SessionCancelResponse, error) {
+	out := new(SessionCancelResponse)
+	err := c.cc.Invoke(ctx, Exchange_CancelSessionOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *exchangeClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*GetOrderResponse, error) {
 	out := new(GetOrderResponse)
 	err := c.cc.Invoke(ctx, Exchange_GetOrder_FullMethodName, in, out, opts...)
@@ -87,6 +274,24 @@ func (c *exchangeClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts
 	return out, nil
 }
 
+func (c *exchangeClient) GetOrderByClientOrderID(ctx context.Context, in *GetOrderByClientOrderIDRequest, opts ...grpc.CallOption) (*GetOrderResponse, error) {
+	out := new(GetOrderResponse)
+	err := c.cc.Invoke(ctx, Exchange_GetOrderByClientOrderID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error) {
+	out := new(ListOrdersResponse)
+	err := c.cc.Invoke(ctx, Exchange_ListOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *exchangeClient) GetTradesForOrder(ctx context.Context, in *GetTradesRequest, opts ...grpc.CallOption) (*GetTradesResponse, error) {
 	out := new(GetTradesResponse)
 	err := c.cc.Invoke(ctx, Exchange_GetTradesForOrder_FullMethodName, in, out, opts...)
@@ -96,6 +301,33 @@ func (c *exchangeClient) GetTradesForOrder(ctx context.Context, in *GetTradesReq
 	return out, nil
 }
 
+func (c *exchangeClient) GetTrades(ctx context.Context, in *GetTradesRequest, opts ...grpc.CallOption) (*GetTradesResponse, error) {
+	out := new(GetTradesResponse)
+	err := c.cc.Invoke(ctx, Exchange_GetTrades_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) GetTrade(ctx context.Context, in *GetTradeRequest, opts ...grpc.CallOption) (*GetTradeResponse, error) {
+	out := new(GetTradeResponse)
+	err := c.cc.Invoke(ctx, Exchange_GetTrade_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) GetQueuePosition(ctx context.Context, in *GetQueuePositionRequest, opts ...grpc.CallOption) (*GetQueuePositionResponse, error) {
+	out := new(GetQueuePositionResponse)
+	err := c.cc.Invoke(ctx, Exchange_GetQueuePosition_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *exchangeClient) GetOrderbook(ctx context.Context, in *GetOrderbookRequest, opts ...grpc.CallOption) (*GetOrderbookResponse, error) {
 	out := new(GetOrderbookResponse)
 	err := c.cc.Invoke(ctx, Exchange_GetOrderbook_FullMethodName, in, out, opts...)
@@ -105,6 +337,42 @@ func (c *exchangeClient) GetOrderbook(ctx context.Context, in *GetOrderbookReque
 	return out, nil
 }
 
+func (c *exchangeClient) GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*GetQuoteResponse, error) {
+	out := new(GetQuoteResponse)
+	err := c.cc.Invoke(ctx, Exchange_GetQuote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) GetDepth(ctx context.Context, in *GetDepthRequest, opts ...grpc.CallOption) (*GetDepthResponse, error) {
+	out := new(GetDepthResponse)
+	err := c.cc.Invoke(ctx, Exchange_GetDepth_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) GetCandles(ctx context.Context, in *GetCandlesRequest, opts ...grpc.CallOption) (*GetCandlesResponse, error) {
+	out := new(GetCandlesResponse)
+	err := c.cc.Invoke(ctx, Exchange_GetCandles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeClient) GetRecentTrades(ctx context.Context, in *GetRecentTradesRequest, opts ...grpc.CallOption) (*GetRecentTradesResponse, error) {
+	out := new(GetRecentTradesResponse)
+	err := c.cc.Invoke(ctx, Exchange_GetRecentTrades_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *exchangeClient) SnapshotOrderbook(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error) {
 	out := new(SnapshotResponse)
 	err := c.cc.Invoke(ctx, Exchange_SnapshotOrderbook_FullMethodName, in, out, opts...)
@@ -123,166 +391,946 @@ func (c *exchangeClient) RestoreOrderbook(ctx context.Context, in *RestoreReques
 	return out, nil
 }
 
-// ExchangeServer is the server API for Exchange service.
-// All implementations must embed UnimplementedExchangeServer
-// for forward compatibility
-type ExchangeServer interface {
-	SubmitOrder(context.Context, *SubmitOrderRequest) (*SubmitOrderResponse, error)
-	ModifyOrder(context.Context, *ModifyOrderRequest) (*ModifyOrderResponse, error)
-	CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error)
-	GetOrder(context.Context, *GetOrderRequest) (*GetOrderResponse, error)
-	GetTradesForOrder(context.Context, *GetTradesRequest) (*GetTradesResponse, error)
-	GetOrderbook(context.Context, *GetOrderbookRequest) (*GetOrderbookResponse, error)
-	SnapshotOrderbook(context.Context, *SnapshotRequest) (*SnapshotResponse, error)
-	RestoreOrderbook(context.Context, *RestoreRequest) (*RestoreResponse, error)
-	mustEmbedUnimplementedExchangeServer()
+func (c *exchangeClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, Exchange_ListSnapshots_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-// UnimplementedExchangeServer must be embedded to have forward compatible implementations.
-type UnimplementedExchangeServer struct {
+func (c *exchangeClient) GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (*GetSnapshotResponse, error) {
+	out := new(GetSnapshotResponse)
+	err := c.cc.Invoke(ctx, Exchange_GetSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (UnimplementedExchangeServer) SubmitOrder(context.Context, *SubmitOrderRequest) (*SubmitOrderResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SubmitOrder not implemented")
+func (c *exchangeClient) DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*DeleteSnapshotResponse, error) {
+	out := new(DeleteSnapshotResponse)
+	err := c.cc.Invoke(ctx, Exchange_DeleteSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedExchangeServer) ModifyOrder(context.Context, *ModifyOrderRequest) (*ModifyOrderResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ModifyOrder not implemented")
+
+func (c *exchangeClient) ExportSnapshot(ctx context.Context, in *ExportSnapshotRequest, opts ...grpc.CallOption) (*ExportSnapshotResponse, error) {
+	out := new(ExportSnapshotResponse)
+	err := c.cc.Invoke(ctx, Exchange_ExportSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedExchangeServer) CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+
+func (c *exchangeClient) ImportSnapshot(ctx context.Context, in *ImportSnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error) {
+	out := new(SnapshotResponse)
+	err := c.cc.Invoke(ctx, Exchange_ImportSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedExchangeServer) GetOrder(context.Context, *GetOrderRequest) (*GetOrderResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+
+func (c *exchangeClient) StreamImbalance(ctx context.Context, in *StreamImbalanceRequest, opts ...grpc.CallOption) (Exchange_StreamImbalanceClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Exchange_ServiceDesc.Streams[0], Exchange_StreamImbalance_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &exchangeStreamImbalanceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
-func (UnimplementedExchangeServer) GetTradesForOrder(context.Context, *GetTradesRequest) (*GetTradesResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetTradesForOrder not implemented")
+
+type Exchange_StreamImbalanceClient interface {
+	Recv() (*ImbalanceUpdate, error)
+	grpc.ClientStream
 }
-func (UnimplementedExchangeServer) GetOrderbook(context.Context, *GetOrderbookRequest) (*GetOrderbookResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetOrderbook not implemented")
+
+type exchangeStreamImbalanceClient struct {
+	grpc.ClientStream
 }
-func (UnimplementedExchangeServer) SnapshotOrderbook(context.Context, *SnapshotRequest) (*SnapshotResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SnapshotOrderbook not implemented")
+
+func (x *exchangeStreamImbalanceClient) Recv() (*ImbalanceUpdate, error) {
+	m := new(ImbalanceUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
-func (UnimplementedExchangeServer) RestoreOrderbook(context.Context, *RestoreRequest) (*RestoreResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RestoreOrderbook not implemented")
+
+func (c *exchangeClient) StreamOrderbook(ctx context.Context, in *StreamOrderbookRequest, opts ...grpc.CallOption) (Exchange_StreamOrderbookClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Exchange_ServiceDesc.Streams[1], Exchange_StreamOrderbook_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &exchangeStreamOrderbookClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
-func (UnimplementedExchangeServer) mustEmbedUnimplementedExchangeServer() {}
 
-// UnsafeExchangeServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to ExchangeServer will
-// result in compilation errors.
-type UnsafeExchangeServer interface {
-	mustEmbedUnimplementedExchangeServer()
+type Exchange_StreamOrderbookClient interface {
+	Recv() (*OrderbookUpdate, error)
+	grpc.ClientStream
+}
+
+type exchangeStreamOrderbookClient struct {
+	grpc.ClientStream
+}
+
+func (x *exchangeStreamOrderbookClient) Recv() (*OrderbookUpdate, error) {
+	m := new(OrderbookUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *exchangeClient) StreamTrades(ctx context.Context, in *StreamTradesRequest, opts ...grpc.CallOption) (Exchange_StreamTradesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Exchange_ServiceDesc.Streams[2], Exchange_StreamTrades_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &exchangeStreamTradesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Exchange_StreamTradesClient interface {
+	Recv() (*TradeUpdate, error)
+	grpc.ClientStream
+}
+
+type exchangeStreamTradesClient struct {
+	grpc.ClientStream
+}
+
+func (x *exchangeStreamTradesClient) Recv() (*TradeUpdate, error) {
+	m := new(TradeUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *exchangeClient) StreamQuotes(ctx context.Context, in *StreamQuotesRequest, opts ...grpc.CallOption) (Exchange_StreamQuotesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Exchange_ServiceDesc.Streams[3], Exchange_StreamQuotes_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &exchangeStreamQuotesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Exchange_StreamQuotesClient interface {
+	Recv() (*QuoteUpdate, error)
+	grpc.ClientStream
+}
+
+type exchangeStreamQuotesClient struct {
+	grpc.ClientStream
+}
+
+func (x *exchangeStreamQuotesClient) Recv() (*QuoteUpdate, error) {
+	m := new(QuoteUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *exchangeClient) StreamOrderEvents(ctx context.Context, in *StreamOrderEventsRequest, opts ...grpc.CallOption) (Exchange_StreamOrderEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Exchange_ServiceDesc.Streams[4], Exchange_StreamOrderEvents_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &exchangeStreamOrderEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Exchange_StreamOrderEventsClient interface {
+	Recv() (*OrderEvent, error)
+	grpc.ClientStream
+}
+
+type exchangeStreamOrderEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *exchangeStreamOrderEventsClient) Recv() (*OrderEvent, error) {
+	m := new(OrderEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *exchangeClient) StreamSurveillanceAlerts(ctx context.Context, in *StreamSurveillanceAlertsRequest, opts ...grpc.CallOption) (Exchange_StreamSurveillanceAlertsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Exchange_ServiceDesc.Streams[5], Exchange_StreamSurveillanceAlerts_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &exchangeStreamSurveillanceAlertsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Exchange_StreamSurveillanceAlertsClient interface {
+	Recv() (*SurveillanceAlert, error)
+	grpc.ClientStream
+}
+
+type exchangeStreamSurveillanceAlertsClient struct {
+	grpc.ClientStream
+}
+
+func (x *exchangeStreamSurveillanceAlertsClient) Recv() (*SurveillanceAlert, error) {
+	m := new(SurveillanceAlert)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *exchangeClient) UpdateSubscription(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*UpdateSubscriptionResponse, error) {
+	out := new(UpdateSubscriptionResponse)
+	err := c.cc.Invoke(ctx, Exchange_UpdateSubscription_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExchangeServer is the server API for Exchange service.
+// All implementations must embed UnimplementedExchangeServer
+// for forward compatibility
+type ExchangeServer interface {
+	SubmitOrder(context.Context, *SubmitOrderRequest) (*SubmitOrderResponse, error)
+	BatchSubmitOrders(context.Context, *BatchSubmitOrdersRequest) (*BatchSubmitOrdersResponse, error)
+	SubmitOrderGroup(context.Context, *SubmitOrderGroupRequest) (*SubmitOrderGroupResponse, error)
+	CancelOrderGroup(context.Context, *CancelOrderGroupRequest) (*CancelOrderGroupResponse, error)
+	PreviewOrder(context.Context, *SubmitOrderRequest) (*PreviewOrderResponse, error)
+	ModifyOrder(context.Context, *ModifyOrderRequest) (*ModifyOrderResponse, error)
+	CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error)
+	BatchCancelOrders(context.Context, *BatchCancelOrdersRequest) (*BatchCancelOrdersResponse, error)
+	BatchModifyOrders(context.Context, *BatchModifyOrdersRequest) (*BatchModifyOrdersResponse, error)
+	CancelBySide(context.Context, *CancelBySideRequest) (*CancelBySideResponse, error)
+	CancelAllOrders(context.Context, *CancelAllOrdersRequest) (*CancelAllOrdersResponse, error)
+	ForceCancelOrder(context.Context, *ForceCancelRequest) (*CancelOrderResponse, error)
+	// session calls other than Login and orders placed through a session send its token as x-session-token metadata
+	Login(context.Context, *LoginRequest) (*Session, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*Session, error)
+	Logout(context.Context, *LogoutRequest) (*SessionCancelResponse, error)
+	CancelSessionOrders(context.Context, *CancelSessionOrdersRequest) (*SessionCancelResponse, error)
+	GetOrder(context.Context, *GetOrderRequest) (*GetOrderResponse, error)
+	GetOrderByClientOrderID(context.Context, *GetOrderByClientOrderIDRequest) (*GetOrderResponse, error)
+	ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
+	GetTradesForOrder(context.Context, *GetTradesRequest) (*GetTradesResponse, error)
+	// GetTrades pages through the stored trades of a symbol, oldest first
+	GetTrades(context.Context, *GetTradesRequest) (*GetTradesResponse, error)
+	GetTrade(context.Context, *GetTradeRequest) (*GetTradeResponse, error)
+	GetQueuePosition(context.Context, *GetQueuePositionRequest) (*GetQueuePositionResponse, error)
+	GetOrderbook(context.Context, *GetOrderbookRequest) (*GetOrderbookResponse, error)
+	GetQuote(context.Context, *GetQuoteRequest) (*GetQuoteResponse, error)
+	GetDepth(context.Context, *GetDepthRequest) (*GetDepthResponse, error)
+	GetCandles(context.Context, *GetCandlesRequest) (*GetCandlesResponse, error)
+	GetRecentTrades(context.Context, *GetRecentTradesRequest) (*GetRecentTradesResponse, error)
+	SnapshotOrderbook(context.Context, *SnapshotRequest) (*SnapshotResponse, error)
+	RestoreOrderbook(context.Context, *RestoreRequest) (*RestoreResponse, error)
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
+	GetSnapshot(context.Context, *GetSnapshotRequest) (*GetSnapshotResponse, error)
+	DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*DeleteSnapshotResponse, error)
+	ExportSnapshot(context.Context, *ExportSnapshotRequest) (*ExportSnapshotResponse, error)
+	ImportSnapshot(context.Context, *ImportSnapshotRequest) (*SnapshotResponse, error)
+	StreamImbalance(*StreamImbalanceRequest, Exchange_StreamImbalanceServer) error
+	StreamOrderbook(*StreamOrderbookRequest, Exchange_StreamOrderbookServer) error
+	StreamTrades(*StreamTradesRequest, Exchange_StreamTradesServer) error
+	StreamQuotes(*StreamQuotesRequest, Exchange_StreamQuotesServer) error
+	StreamOrderEvents(*StreamOrderEventsRequest, Exchange_StreamOrderEventsServer) error
+	StreamSurveillanceAlerts(*StreamSurveillanceAlertsRequest, Exchange_StreamSurveillanceAlertsServer) error
+	UpdateSubscription(context.Context, *UpdateSubscriptionRequest) (*UpdateSubscriptionResponse, error)
+	mustEmbedUnimplementedExchangeServer()
+}
+
+// UnimplementedExchangeServer must be embedded to have forward compatible implementations.
+type UnimplementedExchangeServer struct {
+}
+
+func (UnimplementedExchangeServer) SubmitOrder(context.Context, *SubmitOrderRequest) (*SubmitOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitOrder not implemented")
+}
+func (UnimplementedExchangeServer) BatchSubmitOrders(context.Context, *BatchSubmitOrdersRequest) (*BatchSubmitOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchSubmitOrders not implemented")
+}
+func (UnimplementedExchangeServer) SubmitOrderGroup(context.Context, *SubmitOrderGroupRequest) (*SubmitOrderGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitOrderGroup not implemented")
+}
+func (UnimplementedExchangeServer) CancelOrderGroup(context.Context, *CancelOrderGroupRequest) (*CancelOrderGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrderGroup not implemented")
+}
+func (UnimplementedExchangeServer) PreviewOrder(context.Context, *SubmitOrderRequest) (*PreviewOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewOrder not implemented")
+}
+func (UnimplementedExchangeServer) ModifyOrder(context.Context, *ModifyOrderRequest) (*ModifyOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModifyOrder not implemented")
+}
+func (UnimplementedExchangeServer) CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedExchangeServer) BatchCancelOrders(context.Context, *BatchCancelOrdersRequest) (*BatchCancelOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCancelOrders not implemented")
+}
+func (UnimplementedExchangeServer) BatchModifyOrders(context.Context, *BatchModifyOrdersRequest) (*BatchModifyOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchModifyOrders not implemented")
+}
+func (UnimplementedExchangeServer) CancelBySide(context.Context, *CancelBySideRequest) (*CancelBySideResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelBySide not implemented")
+}
+func (UnimplementedExchangeServer) CancelAllOrders(context.Context, *CancelAllOrdersRequest) (*CancelAllOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelAllOrders not implemented")
+}
+func (UnimplementedExchangeServer) ForceCancelOrder(context.Context, *ForceCancelRequest) (*CancelOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForceCancelOrder not implemented")
+}
+func (UnimplementedExchangeServer) Login(context.Context, *LoginRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedExchangeServer) Heartbeat(context.Context, *HeartbeatRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedExchangeServer) Logout(context.Context, *LogoutRequest) (*SessionCancelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
+}
+func (UnimplementedExchangeServer) CancelSessionOrders(context.Context, *CancelSessionOrdersRequest) (*SessionCancelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelSessionOrders not implemented")
+}
+func (UnimplementedExchangeServer) GetOrder(context.Context, *GetOrderRequest) (*GetOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedExchangeServer) GetOrderByClientOrderID(context.Context, *GetOrderByClientOrderIDRequest) (*GetOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderByClientOrderID not implemented")
+}
+func (UnimplementedExchangeServer) ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrders not implemented")
+}
+func (UnimplementedExchangeServer) GetTradesForOrder(context.Context, *GetTradesRequest) (*GetTradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTradesForOrder not implemented")
+}
+func (UnimplementedExchangeServer) GetTrades(context.Context, *GetTradesRequest) (*GetTradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrades not implemented")
+}
+func (UnimplementedExchangeServer) GetTrade(context.Context, *GetTradeRequest) (*GetTradeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrade not implemented")
+}
+func (UnimplementedExchangeServer) GetQueuePosition(context.Context, *GetQueuePositionRequest) (*GetQueuePositionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQueuePosition not implemented")
+}
+func (UnimplementedExchangeServer) GetOrderbook(context.Context, *GetOrderbookRequest) (*GetOrderbookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderbook not implemented")
+}
+func (UnimplementedExchangeServer) GetQuote(context.Context, *GetQuoteRequest) (*GetQuoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuote not implemented")
+}
+func (UnimplementedExchangeServer) GetDepth(context.Context, *GetDepthRequest) (*GetDepthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDepth not implemented")
+}
+func (UnimplementedExchangeServer) GetCandles(context.Context, *GetCandlesRequest) (*GetCandlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCandles not implemented")
+}
+func (UnimplementedExchangeServer) GetRecentTrades(context.Context, *GetRecentTradesRequest) (*GetRecentTradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecentTrades not implemented")
+}
+func (UnimplementedExchangeServer) SnapshotOrderbook(context.Context, *SnapshotRequest) (*SnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SnapshotOrderbook not implemented")
+}
+func (UnimplementedExchangeServer) RestoreOrderbook(context.Context, *RestoreRequest) (*RestoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreOrderbook not implemented")
+}
+func (UnimplementedExchangeServer) ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
+func (UnimplementedExchangeServer) GetSnapshot(context.Context, *GetSnapshotRequest) (*GetSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
+}
+func (UnimplementedExchangeServer) DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*DeleteSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSnapshot not implemented")
+}
+func (UnimplementedExchangeServer) ExportSnapshot(context.Context, *ExportSnapshotRequest) (*ExportSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportSnapshot not implemented")
+}
+func (UnimplementedExchangeServer) ImportSnapshot(context.Context, *ImportSnapshotRequest) (*SnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportSnapshot not implemented")
+}
+func (UnimplementedExchangeServer) StreamImbalance(*StreamImbalanceRequest, Exchange_StreamImbalanceServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamImbalance not implemented")
+}
+func (UnimplementedExchangeServer) StreamOrderbook(*StreamOrderbookRequest, Exchange_StreamOrderbookServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOrderbook not implemented")
+}
+func (UnimplementedExchangeServer) StreamTrades(*StreamTradesRequest, Exchange_StreamTradesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTrades not implemented")
+}
+func (UnimplementedExchangeServer) StreamQuotes(*StreamQuotesRequest, Exchange_StreamQuotesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamQuotes not implemented")
+}
+func (UnimplementedExchangeServer) StreamOrderEvents(*StreamOrderEventsRequest, Exchange_StreamOrderEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOrderEvents not implemented")
+}
+func (UnimplementedExchangeServer) StreamSurveillanceAlerts(*StreamSurveillanceAlertsRequest, Exchange_StreamSurveillanceAlertsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSurveillanceAlerts not implemented")
+}
+func (UnimplementedExchangeServer) UpdateSubscription(context.Context, *UpdateSubscriptionRequest) (*UpdateSubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSubscription not implemented")
+}
+func (UnimplementedExchangeServer) mustEmbedUnimplementedExchangeServer() {}
+
+// UnsafeExchangeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExchangeServer will
+// result in compilation errors.
+type UnsafeExchangeServer interface {
+	mustEmbedUnimplementedExchangeServer()
+}
+
+func RegisterExchangeServer(s grpc.ServiceRegistrar, srv ExchangeServer) {
+	s.RegisterService(&Exchange_ServiceDesc, srv)
+}
+
+func _Exchange_SubmitOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).SubmitOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_SubmitOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).SubmitOrder(ctx, req.(*SubmitOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_BatchSubmitOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSubmitOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).BatchSubmitOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_BatchSubmitOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).BatchSubmitOrders(ctx, req.(*BatchSubmitOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_SubmitOrderGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitOrderGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).SubmitOrderGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_SubmitOrderGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).SubmitOrderGroup(ctx, req.(*SubmitOrderGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_CancelOrderGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).CancelOrderGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_CancelOrderGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).CancelOrderGroup(ctx, req.(*CancelOrderGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_PreviewOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).PreviewOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_PreviewOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).PreviewOrder(ctx, req.(*SubmitOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_ModifyOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModifyOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).ModifyOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_ModifyOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).ModifyOrder(ctx, req.(*ModifyOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_CancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_BatchCancelOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCancelOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).BatchCancelOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_BatchCancelOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).BatchCancelOrders(ctx, req.(*BatchCancelOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_BatchModifyOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchModifyOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).BatchModifyOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_BatchModifyOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).BatchModifyOrders(ctx, req.(*BatchModifyOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_CancelBySide_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelBySideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).CancelBySide(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_CancelBySide_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).CancelBySide(ctx, req.(*CancelBySideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_CancelAllOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelAllOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).CancelAllOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_CancelAllOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).CancelAllOrders(ctx, req.(*CancelAllOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_ForceCancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceCancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).ForceCancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_ForceCancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).ForceCancelOrder(ctx, req.(*ForceCancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_Logout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).Logout(ctx, req.(*LogoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_CancelSessionOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelSessionOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).CancelSessionOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_CancelSessionOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).CancelSessionOrders(ctx, req.(*CancelSessionOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_GetOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_GetOrderByClientOrderID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderByClientOrderIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).GetOrderByClientOrderID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_GetOrderByClientOrderID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).GetOrderByClientOrderID(ctx, req.(*GetOrderByClientOrderIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_ListOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).ListOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_ListOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).ListOrders(ctx, req.(*ListOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_GetTradesForOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).GetTradesForOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_GetTradesForOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).GetTradesForOrder(ctx, req.(*GetTradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_GetTrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).GetTrades(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_GetTrades_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).GetTrades(ctx, req.(*GetTradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterExchangeServer(s grpc.ServiceRegistrar, srv ExchangeServer) {
-	s.RegisterService(&Exchange_ServiceDesc, srv)
+func _Exchange_GetTrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).GetTrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_GetTrade_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).GetTrade(ctx, req.(*GetTradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Exchange_SubmitOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SubmitOrderRequest)
+func _Exchange_GetQueuePosition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQueuePositionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ExchangeServer).SubmitOrder(ctx, in)
+		return srv.(ExchangeServer).GetQueuePosition(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Exchange_SubmitOrder_FullMethodName,
+		FullMethod: Exchange_GetQueuePosition_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ExchangeServer).SubmitOrder(ctx, req.(*SubmitOrderRequest))
+		return srv.(ExchangeServer).GetQueuePosition(ctx, req.(*GetQueuePositionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Exchange_ModifyOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ModifyOrderRequest)
+func _Exchange_GetOrderbook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderbookRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ExchangeServer).ModifyOrder(ctx, in)
+		return srv.(ExchangeServer).GetOrderbook(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Exchange_ModifyOrder_FullMethodName,
+		FullMethod: Exchange_GetOrderbook_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ExchangeServer).ModifyOrder(ctx, req.(*ModifyOrderRequest))
+		return srv.(ExchangeServer).GetOrderbook(ctx, req.(*GetOrderbookRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Exchange_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CancelOrderRequest)
+func _Exchange_GetQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuoteRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ExchangeServer).CancelOrder(ctx, in)
+		return srv.(ExchangeServer).GetQuote(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Exchange_CancelOrder_FullMethodName,
+		FullMethod: Exchange_GetQuote_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ExchangeServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+		return srv.(ExchangeServer).GetQuote(ctx, req.(*GetQuoteRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Exchange_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetOrderRequest)
+func _Exchange_GetDepth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDepthRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ExchangeServer).GetOrder(ctx, in)
+		return srv.(ExchangeServer).GetDepth(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Exchange_GetOrder_FullMethodName,
+		FullMethod: Exchange_GetDepth_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ExchangeServer).GetOrder(ctx, req.(*GetOrderRequest))
+		return srv.(ExchangeServer).GetDepth(ctx, req.(*GetDepthRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Exchange_GetTradesForOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetTradesRequest)
+func _Exchange_GetCandles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCandlesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ExchangeServer).GetTradesForOrder(ctx, in)
+		return srv.(ExchangeServer).GetCandles(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Exchange_GetTradesForOrder_FullMethodName,
+		FullMethod: Exchange_GetCandles_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ExchangeServer).GetTradesForOrder(ctx, req.(*GetTradesRequest))
+		return srv.(ExchangeServer).GetCandles(ctx, req.(*GetCandlesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Exchange_GetOrderbook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetOrderbookRequest)
+func _Exchange_GetRecentTrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecentTradesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ExchangeServer).GetOrderbook(ctx, in)
+		return srv.(ExchangeServer).GetRecentTrades(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Exchange_GetOrderbook_FullMethodName,
+		FullMethod: Exchange_GetRecentTrades_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ExchangeServer).GetOrderbook(ctx, req.(*GetOrderbookRequest))
+		return srv.(ExchangeServer).GetRecentTrades(ctx, req.(*GetRecentTradesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -323,6 +1371,240 @@ func _Exchange_RestoreOrderbook_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Exchange_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_ListSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).GetSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_GetSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).GetSnapshot(ctx, req.(*GetSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_DeleteSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).DeleteSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_DeleteSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).DeleteSnapshot(ctx, req.(*DeleteSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_ExportSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).ExportSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_ExportSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).ExportSnapshot(ctx, req.(*ExportSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_ImportSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).ImportSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_ImportSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).ImportSnapshot(ctx, req.(*ImportSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exchange_StreamImbalance_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamImbalanceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExchangeServer).StreamImbalance(m, &exchangeStreamImbalanceServer{stream})
+}
+
+type Exchange_StreamImbalanceServer interface {
+	Send(*ImbalanceUpdate) error
+	grpc.ServerStream
+}
+
+type exchangeStreamImbalanceServer struct {
+	grpc.ServerStream
+}
+
+func (x *exchangeStreamImbalanceServer) Send(m *ImbalanceUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Exchange_StreamOrderbook_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamOrderbookRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExchangeServer).StreamOrderbook(m, &exchangeStreamOrderbookServer{stream})
+}
+
+type Exchange_StreamOrderbookServer interface {
+	Send(*OrderbookUpdate) error
+	grpc.ServerStream
+}
+
+type exchangeStreamOrderbookServer struct {
+	grpc.ServerStream
+}
+
+func (x *exchangeStreamOrderbookServer) Send(m *OrderbookUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Exchange_StreamTrades_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTradesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExchangeServer).StreamTrades(m, &exchangeStreamTradesServer{stream})
+}
+
+type Exchange_StreamTradesServer interface {
+	Send(*TradeUpdate) error
+	grpc.ServerStream
+}
+
+type exchangeStreamTradesServer struct {
+	grpc.ServerStream
+}
+
+func (x *exchangeStreamTradesServer) Send(m *TradeUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Exchange_StreamQuotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamQuotesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExchangeServer).StreamQuotes(m, &exchangeStreamQuotesServer{stream})
+}
+
+type Exchange_StreamQuotesServer interface {
+	Send(*QuoteUpdate) error
+	grpc.ServerStream
+}
+
+type exchangeStreamQuotesServer struct {
+	grpc.ServerStream
+}
+
+func (x *exchangeStreamQuotesServer) Send(m *QuoteUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Exchange_StreamOrderEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamOrderEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExchangeServer).StreamOrderEvents(m, &exchangeStreamOrderEventsServer{stream})
+}
+
+type Exchange_StreamOrderEventsServer interface {
+	Send(*OrderEvent) error
+	grpc.ServerStream
+}
+
+type exchangeStreamOrderEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *exchangeStreamOrderEventsServer) Send(m *OrderEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Exchange_StreamSurveillanceAlerts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamSurveillanceAlertsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExchangeServer).StreamSurveillanceAlerts(m, &exchangeStreamSurveillanceAlertsServer{stream})
+}
+
+type Exchange_StreamSurveillanceAlertsServer interface {
+	Send(*SurveillanceAlert) error
+	grpc.ServerStream
+}
+
+type exchangeStreamSurveillanceAlertsServer struct {
+	grpc.ServerStream
+}
+
+func (x *exchangeStreamSurveillanceAlertsServer) Send(m *SurveillanceAlert) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Exchange_UpdateSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServer).UpdateSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exchange_UpdateSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServer).UpdateSubscription(ctx, req.(*UpdateSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Exchange_ServiceDesc is the grpc.ServiceDesc for Exchange service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -334,6 +1616,22 @@ var Exchange_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SubmitOrder",
 			Handler:    _Exchange_SubmitOrder_Handler,
 		},
+		{
+			MethodName: "BatchSubmitOrders",
+			Handler:    _Exchange_BatchSubmitOrders_Handler,
+		},
+		{
+			MethodName: "SubmitOrderGroup",
+			Handler:    _Exchange_SubmitOrderGroup_Handler,
+		},
+		{
+			MethodName: "CancelOrderGroup",
+			Handler:    _Exchange_CancelOrderGroup_Handler,
+		},
+		{
+			MethodName: "PreviewOrder",
+			Handler:    _Exchange_PreviewOrder_Handler,
+		},
 		{
 			MethodName: "ModifyOrder",
 			Handler:    _Exchange_ModifyOrder_Handler,
@@ -342,18 +1640,90 @@ var Exchange_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelOrder",
 			Handler:    _Exchange_CancelOrder_Handler,
 		},
+		{
+			MethodName: "BatchCancelOrders",
+			Handler:    _Exchange_BatchCancelOrders_Handler,
+		},
+		{
+			MethodName: "BatchModifyOrders",
+			Handler:    _Exchange_BatchModifyOrders_Handler,
+		},
+		{
+			MethodName: "CancelBySide",
+			Handler:    _Exchange_CancelBySide_Handler,
+		},
+		{
+			MethodName: "CancelAllOrders",
+			Handler:    _Exchange_CancelAllOrders_Handler,
+		},
+		{
+			MethodName: "ForceCancelOrder",
+			Handler:    _Exchange_ForceCancelOrder_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _Exchange_Login_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Exchange_Heartbeat_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _Exchange_Logout_Handler,
+		},
+		{
+			MethodName: "CancelSessionOrders",
+			Handler:    _Exchange_CancelSessionOrders_Handler,
+		},
 		{
 			MethodName: "GetOrder",
 			Handler:    _Exchange_GetOrder_Handler,
 		},
+		{
+			MethodName: "GetOrderByClientOrderID",
+			Handler:    _Exchange_GetOrderByClientOrderID_Handler,
+		},
+		{
+			MethodName: "ListOrders",
+			Handler:    _Exchange_ListOrders_Handler,
+		},
 		{
 			MethodName: "GetTradesForOrder",
 			Handler:    _Exchange_GetTradesForOrder_Handler,
 		},
+		{
+			MethodName: "GetTrades",
+			Handler:    _Exchange_GetTrades_Handler,
+		},
+		{
+			MethodName: "GetTrade",
+			Handler:    _Exchange_GetTrade_Handler,
+		},
+		{
+			MethodName: "GetQueuePosition",
+			Handler:    _Exchange_GetQueuePosition_Handler,
+		},
 		{
 			MethodName: "GetOrderbook",
 			Handler:    _Exchange_GetOrderbook_Handler,
 		},
+		{
+			MethodName: "GetQuote",
+			Handler:    _Exchange_GetQuote_Handler,
+		},
+		{
+			MethodName: "GetDepth",
+			Handler:    _Exchange_GetDepth_Handler,
+		},
+		{
+			MethodName: "GetCandles",
+			Handler:    _Exchange_GetCandles_Handler,
+		},
+		{
+			MethodName: "GetRecentTrades",
+			Handler:    _Exchange_GetRecentTrades_Handler,
+		},
 		{
 			MethodName: "SnapshotOrderbook",
 			Handler:    _Exchange_SnapshotOrderbook_Handler,
@@ -362,7 +1732,62 @@ var Exchange_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RestoreOrderbook",
 			Handler:    _Exchange_RestoreOrderbook_Handler,
 		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _Exchange_ListSnapshots_Handler,
+		},
+		{
+			MethodName: "GetSnapshot",
+			Handler:    _Exchange_GetSnapshot_Handler,
+		},
+		{
+			MethodName: "DeleteSnapshot",
+			Handler:    _Exchange_DeleteSnapshot_Handler,
+		},
+		{
+			MethodName: "ExportSnapshot",
+			Handler:    _Exchange_ExportSnapshot_Handler,
+		},
+		{
+			MethodName: "ImportSnapshot",
+			Handler:    _Exchange_ImportSnapshot_Handler,
+		},
+		{
+			MethodName: "UpdateSubscription",
+			Handler:    _Exchange_UpdateSubscription_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamImbalance",
+			Handler:       _Exchange_StreamImbalance_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamOrderbook",
+			Handler:       _Exchange_StreamOrderbook_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamTrades",
+			Handler:       _Exchange_StreamTrades_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamQuotes",
+			Handler:       _Exchange_StreamQuotes_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamOrderEvents",
+			Handler:       _Exchange_StreamOrderEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamSurveillanceAlerts",
+			Handler:       _Exchange_StreamSurveillanceAlerts_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/exchange.proto",
 }
@@ -0,0 +1,7 @@
+create table notification_preferences (
+                        client_id   text not null,
+                        channel     text not null check (channel in ('webhook','websocket','log')),
+                        target      text not null default '',   -- URL для webhook
+                        exec_types  text[] not null default '{}', -- пусто = все события
+                        primary key (client_id, channel, target)
+);
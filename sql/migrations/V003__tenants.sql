@@ -0,0 +1,10 @@
+alter table orders add column tenant text not null default 'default';
+alter table trades add column tenant text not null default 'default';
+alter table notification_preferences add column tenant text not null default 'default';
+
+alter table notification_preferences drop constraint notification_preferences_pkey;
+alter table notification_preferences add primary key (tenant, client_id, channel, target);
+
+create index on orders (tenant, symbol, side, status, price, created_at);
+create index on orders (tenant, client_id, id);
+create index on trades (tenant, symbol, executed_at);
@@ -0,0 +1,8 @@
+-- partial indexes over resting orders only: filled and cancelled rows, the bulk of the table,
+-- never enter them, and each side is stored in the order matching walks it.
+-- bids need their own index because a backward scan would yield created_at desc within a price.
+create index orders_resting_asks on orders (tenant, symbol, price, created_at, id)
+    where side = 'SELL' and status in ('OPEN', 'PARTIALLY_FILLED');
+
+create index orders_resting_bids on orders (tenant, symbol, price desc, created_at, id)
+    where side = 'BUY' and status in ('OPEN', 'PARTIALLY_FILLED');
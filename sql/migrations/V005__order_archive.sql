@@ -0,0 +1,15 @@
+-- terminal orders move here once they age past the retention policy, keeping the live table to the
+-- book and recent history; trades stay put, so their order references can no longer be foreign keys
+create table orders_archive (like orders including defaults including constraints);
+alter table orders_archive add column archived_at timestamptz not null default now();
+alter table orders_archive add primary key (id);
+
+create index on orders_archive (tenant, client_id, id);
+create index on orders_archive (tenant, symbol, created_at);
+create index on orders_archive (tenant, updated_at);
+create index on orders (tenant, updated_at) where status in ('FILLED', 'CANCELLED');
+
+alter table trades drop constraint trades_buy_order_fkey;
+alter table trades drop constraint trades_sell_order_fkey;
+create index on trades (tenant, buy_order);
+create index on trades (tenant, sell_order);
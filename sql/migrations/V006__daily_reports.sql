@@ -0,0 +1,27 @@
+-- end-of-day reports, rebuilt as a whole per tenant and day
+create table daily_symbol_reports (
+    tenant       text not null,
+    day          date not null,
+    symbol       text not null,
+    trades       integer not null,
+    volume       numeric(38, 8) not null,
+    notional     numeric(38, 8) not null,
+    vwap         numeric(38, 8) not null,
+    high         numeric(38, 8) not null,
+    low          numeric(38, 8) not null,
+    generated_at timestamptz not null default now(),
+    primary key (tenant, day, symbol)
+);
+
+create table daily_client_reports (
+    tenant       text not null,
+    day          date not null,
+    client_id    text not null,
+    orders       integer not null,
+    cancelled    integer not null,
+    fills        integer not null,
+    volume       numeric(38, 8) not null,
+    notional     numeric(38, 8) not null,
+    generated_at timestamptz not null default now(),
+    primary key (tenant, day, client_id)
+);
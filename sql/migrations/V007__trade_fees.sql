@@ -0,0 +1,5 @@
+-- fees charged on the execution and the side of the incoming (taker) order; trades executed before
+-- this migration carry no fees and no taker side
+alter table trades add column maker_fee numeric(38, 8) not null default 0;
+alter table trades add column taker_fee numeric(38, 8) not null default 0;
+alter table trades add column taker_side text check (taker_side in ('BUY', 'SELL'));
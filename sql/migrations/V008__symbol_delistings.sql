@@ -0,0 +1,12 @@
+-- scheduled and completed delistings; a symbol without a row is listed
+create table symbol_delistings (
+    tenant       text not null,
+    symbol       text not null,
+    reason       text not null default '',
+    announced_at timestamptz not null default now(),
+    halt_at      timestamptz not null,
+    delist_at    timestamptz not null,
+    delisted_at  timestamptz,
+    primary key (tenant, symbol),
+    check (halt_at <= delist_at)
+);
@@ -0,0 +1,32 @@
+-- hot/cold split: resting orders live in the compact open_orders table that matching and book
+-- reads touch, and move to order_history in the statement that makes them FILLED or CANCELLED
+alter table orders rename to order_history;
+
+create table open_orders (like order_history including defaults including constraints);
+alter table open_orders add primary key (id);
+alter table open_orders add constraint open_orders_resting check (status in ('OPEN', 'PARTIALLY_FILLED'));
+
+insert into open_orders select * from order_history where status in ('OPEN', 'PARTIALLY_FILLED');
+delete from order_history where status in ('OPEN', 'PARTIALLY_FILLED');
+alter table order_history add constraint order_history_terminal check (status in ('FILLED', 'CANCELLED'));
+
+drop index orders_resting_asks;
+drop index orders_resting_bids;
+create index open_orders_asks on open_orders (tenant, symbol, price, created_at, id) where side = 'SELL';
+create index open_orders_bids on open_orders (tenant, symbol, price desc, created_at, id) where side = 'BUY';
+create index on open_orders (tenant, client_id, id);
+
+create trigger open_orders_set_updated_at
+    before update on open_orders
+    for each row execute function set_updated_at();
+
+-- every order wherever it lives, for lookups, history and reporting
+create view orders as
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant
+    from open_orders
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant
+    from order_history
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant
+    from orders_archive;
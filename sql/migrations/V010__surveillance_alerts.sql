@@ -0,0 +1,21 @@
+-- findings of the trade surveillance scans awaiting or past compliance review; the id is derived from
+-- the finding, so a rescan of the same trades inserts nothing
+create table surveillance_alerts (
+    tenant      text not null,
+    id          uuid not null,
+    kind        text not null check (kind in ('SELF_MATCH','GROUP_MATCH','ROUND_TRIP','VOLUME_ANOMALY')),
+    symbol      text not null,
+    client_ids  text[] not null default '{}',
+    trade_ids   text[] not null default '{}',
+    detail      text not null default '',
+    at          timestamptz not null,
+    detected_at timestamptz not null default now(),
+    status      text not null default 'OPEN' check (status in ('OPEN','ESCALATED','DISMISSED')),
+    reviewed_by text not null default '',
+    review_note text not null default '',
+    reviewed_at timestamptz,
+    primary key (tenant, id)
+);
+
+create index on surveillance_alerts (tenant, status, at, id);
+create index on surveillance_alerts using gin (client_ids);
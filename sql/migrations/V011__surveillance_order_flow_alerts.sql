@@ -0,0 +1,5 @@
+-- alert kinds raised from the live order flow rather than from trade scans
+alter table surveillance_alerts drop constraint surveillance_alerts_kind_check;
+alter table surveillance_alerts add constraint surveillance_alerts_kind_check check (kind in (
+    'SELF_MATCH','GROUP_MATCH','ROUND_TRIP','VOLUME_ANOMALY',
+    'SPOOFING','LAYERING','EXCESSIVE_CANCELS','PRICE_IMPACT'));
@@ -0,0 +1,20 @@
+-- trades carry what settlement and reporting need without joining back through orders: the clients
+-- of both orders, who made and who took liquidity, the fee assets and the taker's order type
+alter table trades add column buy_client text not null default '';
+alter table trades add column sell_client text not null default '';
+alter table trades add column maker_fee_asset text not null default '';
+alter table trades add column taker_fee_asset text not null default '';
+alter table trades add column taker_type text check (taker_type in ('LIMIT', 'MARKET'));
+alter table trades add column buy_liquidity text generated always as (
+    case taker_side when 'BUY' then 'TAKER' when 'SELL' then 'MAKER' end) stored;
+alter table trades add column sell_liquidity text generated always as (
+    case taker_side when 'SELL' then 'TAKER' when 'BUY' then 'MAKER' end) stored;
+
+-- earlier trades: clients from their orders where these are still kept, fees in the quote asset;
+-- the taker's order type was never recorded and stays null
+update trades tr set buy_client = o.client_id from orders o where o.tenant = tr.tenant and o.id = tr.buy_order;
+update trades tr set sell_client = o.client_id from orders o where o.tenant = tr.tenant and o.id = tr.sell_order;
+update trades set maker_fee_asset = split_part(symbol, '/', 2), taker_fee_asset = split_part(symbol, '/', 2);
+
+create index on trades (tenant, buy_client, executed_at);
+create index on trades (tenant, sell_client, executed_at);
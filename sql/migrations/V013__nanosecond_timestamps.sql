@@ -0,0 +1,41 @@
+-- timestamptz keeps microseconds; the engine's times are nanosecond-precise, so orders and trades also
+-- keep them as nanoseconds since the epoch. The timestamptz columns stay for range queries and indexes.
+create function epoch_ns(t timestamptz) returns bigint as $$
+  select (extract(epoch from t) * 1000000000)::bigint
+$$ language sql immutable;
+
+alter table open_orders add column created_ns bigint, add column updated_ns bigint;
+alter table order_history add column created_ns bigint, add column updated_ns bigint;
+alter table orders_archive add column created_ns bigint, add column updated_ns bigint;
+update open_orders set created_ns = epoch_ns(created_at), updated_ns = epoch_ns(updated_at);
+update order_history set created_ns = epoch_ns(created_at), updated_ns = epoch_ns(updated_at);
+update orders_archive set created_ns = epoch_ns(created_at), updated_ns = epoch_ns(updated_at);
+alter table open_orders alter column created_ns set not null, alter column updated_ns set not null;
+alter table order_history alter column created_ns set not null, alter column updated_ns set not null;
+alter table orders_archive alter column created_ns set not null, alter column updated_ns set not null;
+
+-- trades also carry the engine-assigned sequence of their execution; earlier trades have none
+alter table trades add column executed_ns bigint, add column seq bigint;
+update trades set executed_ns = epoch_ns(executed_at);
+alter table trades alter column executed_ns set not null;
+create index on trades (tenant, seq);
+
+-- the engine stamps every transition it saves; the trigger only stamps updates that do not
+create or replace function set_updated_at() returns trigger as $$
+begin
+  if new.updated_ns is not distinct from old.updated_ns then
+    new.updated_at = now();
+    new.updated_ns = epoch_ns(new.updated_at);
+  end if;
+  return new;
+end $$ language plpgsql;
+
+create or replace view orders as
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns
+    from open_orders
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns
+    from order_history
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns
+    from orders_archive;
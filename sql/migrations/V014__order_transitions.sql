@@ -0,0 +1,21 @@
+-- every state transition of an order with its cause, written by the engine after each commit; the
+-- history of a rejected order is kept although the order itself never reached the orders tables
+create table order_transitions (
+    tenant    text not null,
+    order_id  text not null,
+    seq       bigint not null,
+    client_id text not null,
+    symbol    text not null,
+    exec_type text not null,
+    status    text not null,
+    cause     text not null check (cause in ('PLACED','REJECTED','MATCHED','MODIFIED','CANCELLED_BY_USER','ADMIN',
+                                             'DELISTED','EXPIRED','TRIGGERED')),
+    reason    text not null default '',
+    remaining numeric(38, 8) not null,
+    trade_id  text not null default '',
+    at        timestamptz not null,
+    at_ns     bigint not null,
+    primary key (tenant, order_id, seq)
+);
+
+create index on order_transitions (tenant, at);
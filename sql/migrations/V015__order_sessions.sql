@@ -0,0 +1,22 @@
+-- orders remember the trading session that placed them, so a session's orders can be cancelled
+-- together; orders placed without a session have none
+alter table open_orders add column session_id text not null default '';
+alter table order_history add column session_id text not null default '';
+alter table orders_archive add column session_id text not null default '';
+create index on open_orders (tenant, session_id) where session_id <> '';
+
+create or replace view orders as
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id
+    from open_orders
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id
+    from order_history
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id
+    from orders_archive;
+
+-- orders cancelled because their session ended
+alter table order_transitions drop constraint order_transitions_cause_check;
+alter table order_transitions add constraint order_transitions_cause_check
+    check (cause in ('PLACED','REJECTED','MATCHED','MODIFIED','CANCELLED_BY_USER','ADMIN','DELISTED','EXPIRED','TRIGGERED',
+                     'DISCONNECTED'));
@@ -0,0 +1,15 @@
+-- short client labels on orders, e.g. the strategy that placed them; exports and statements filter on them
+alter table open_orders add column tags text[] not null default '{}';
+alter table order_history add column tags text[] not null default '{}';
+alter table orders_archive add column tags text[] not null default '{}';
+create index on order_history using gin (tags);
+
+create or replace view orders as
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags
+    from open_orders
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags
+    from order_history
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags
+    from orders_archive;
@@ -0,0 +1,14 @@
+-- opaque client metadata, stored and returned verbatim but never interpreted by the engine
+alter table open_orders add column user_data text not null default '';
+alter table order_history add column user_data text not null default '';
+alter table orders_archive add column user_data text not null default '';
+
+create or replace view orders as
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data
+    from open_orders
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data
+    from order_history
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data
+    from orders_archive;
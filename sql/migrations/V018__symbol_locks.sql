@@ -0,0 +1,8 @@
+-- one row per symbol that has been matched; on CockroachDB, which has no advisory locks, a matcher
+-- holds its symbol by writing the row until its transaction ends
+create table if not exists symbol_locks (
+    tenant    text not null default 'default',
+    symbol    text not null,
+    locked_at timestamptz not null default now(),
+    primary key (tenant, symbol)
+);
@@ -0,0 +1,3 @@
+-- trades are looked up by ID within their tenant, e.g. by settlement resolving an execution; the
+-- tenant leads the index like every other one on the table
+create unique index on trades (tenant, id);
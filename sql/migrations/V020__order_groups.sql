@@ -0,0 +1,25 @@
+-- orders submitted together as a basket share a group ID, so the group can be cancelled as a unit;
+-- with cancel_group_on_fill a leg that fills completely cancels the rest of the group
+alter table open_orders add column group_id text not null default '', add column cancel_group_on_fill boolean not null default false;
+alter table order_history add column group_id text not null default '', add column cancel_group_on_fill boolean not null default false;
+alter table orders_archive add column group_id text not null default '', add column cancel_group_on_fill boolean not null default false;
+create index on open_orders (tenant, group_id) where group_id <> '';
+
+create or replace view orders as
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data,
+           group_id, cancel_group_on_fill
+    from open_orders
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data,
+           group_id, cancel_group_on_fill
+    from order_history
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data,
+           group_id, cancel_group_on_fill
+    from orders_archive;
+
+-- legs cancelled because another leg of their group filled
+alter table order_transitions drop constraint order_transitions_cause_check;
+alter table order_transitions add constraint order_transitions_cause_check
+    check (cause in ('PLACED','REJECTED','MATCHED','MODIFIED','CANCELLED_BY_USER','ADMIN','DELISTED','EXPIRED','TRIGGERED',
+                     'DISCONNECTED','GROUP_FILLED'));
@@ -0,0 +1,33 @@
+-- stop and stop-limit orders wait in open_orders as PENDING, off the book, until a trade at or
+-- through their trigger price activates them; trigger_price is 0 for every other order
+alter table open_orders add column trigger_price numeric(38, 8) not null default 0;
+alter table order_history add column trigger_price numeric(38, 8) not null default 0;
+alter table orders_archive add column trigger_price numeric(38, 8) not null default 0;
+
+alter table open_orders drop constraint orders_type_check;
+alter table open_orders add constraint orders_type_check check (type in ('MARKET','LIMIT','STOP','STOP_LIMIT'));
+alter table order_history drop constraint orders_type_check;
+alter table order_history add constraint orders_type_check check (type in ('MARKET','LIMIT','STOP','STOP_LIMIT'));
+alter table orders_archive drop constraint orders_type_check;
+alter table orders_archive add constraint orders_type_check check (type in ('MARKET','LIMIT','STOP','STOP_LIMIT'));
+
+alter table open_orders drop constraint orders_status_check;
+alter table open_orders drop constraint open_orders_resting;
+alter table open_orders add constraint open_orders_resting check (status in ('OPEN', 'PARTIALLY_FILLED', 'PENDING'));
+
+-- the trigger book: buy stops fire at or below the last price, sell stops at or above it
+create index open_orders_buy_triggers on open_orders (tenant, symbol, trigger_price, created_at, id) where status = 'PENDING' and side = 'BUY';
+create index open_orders_sell_triggers on open_orders (tenant, symbol, trigger_price desc, created_at, id) where status = 'PENDING' and side = 'SELL';
+
+create or replace view orders as
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data,
+           group_id, cancel_group_on_fill, trigger_price
+    from open_orders
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data,
+           group_id, cancel_group_on_fill, trigger_price
+    from order_history
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data,
+           group_id, cancel_group_on_fill, trigger_price
+    from orders_archive;
@@ -0,0 +1,16 @@
+-- append-only ledger of the engine's write path: every order accepted, matched, replaced, triggered
+-- or cancelled and every trade, written in the transaction of the change it records and ahead of it.
+-- Replaying a symbol's rows in seq order rebuilds its book; rows are never updated or deleted.
+create table order_ledger (
+    tenant   text not null,
+    seq      bigserial not null,
+    kind     text not null check (kind in ('ORDER_ACCEPTED','ORDER_MATCHED','ORDER_REPLACED','ORDER_TRIGGERED',
+                                           'ORDER_CANCELLED','TRADE_EXECUTED')),
+    symbol   text not null,
+    order_id text not null default '',
+    body     jsonb,
+    at       timestamptz not null default now(),
+    primary key (tenant, seq)
+);
+
+create index on order_ledger (tenant, symbol, seq);
@@ -0,0 +1,22 @@
+-- client balances by asset: available funds new orders, locked is held by open orders. The checks
+-- are the funds check: a change that would take either below zero fails its transaction.
+create table accounts (
+    tenant     text not null,
+    client_id  text not null,
+    asset      text not null,
+    available  numeric(38, 8) not null default 0 check (available >= 0),
+    locked     numeric(38, 8) not null default 0 check (locked >= 0),
+    updated_at timestamptz not null default now(),
+    primary key (tenant, client_id, asset)
+);
+
+-- what each pending or resting order holds of its client's locked balance; fills draw on it and
+-- the cancel statements return the rest to the available balance
+create table account_holds (
+    tenant    text not null,
+    order_id  text not null,
+    client_id text not null,
+    asset     text not null,
+    amount    numeric(38, 8) not null check (amount > 0),
+    primary key (tenant, order_id)
+);
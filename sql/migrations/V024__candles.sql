@@ -0,0 +1,15 @@
+-- OHLCV bars of executed trades per symbol and period, merged as trades commit
+create table candles (
+    tenant    text not null,
+    symbol    text not null,
+    period    text not null, -- 1m, 5m, 1h or 1d
+    start_at  timestamptz not null,
+    open      numeric(38, 8) not null,
+    high      numeric(38, 8) not null,
+    low       numeric(38, 8) not null,
+    close     numeric(38, 8) not null,
+    volume    numeric(38, 8) not null,
+    notional  numeric(38, 8) not null,
+    trades    integer not null,
+    primary key (tenant, symbol, period, start_at)
+);
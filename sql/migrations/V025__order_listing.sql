@@ -0,0 +1,3 @@
+-- a client pages through its orders by creation: open orders are few, history and archive are not
+create index on order_history (tenant, client_id, created_ns, id);
+create index on orders_archive (tenant, client_id, created_ns, id);
@@ -0,0 +1,13 @@
+-- symbols registered for trading; once a tenant has any, orders for other symbols are rejected
+create table instruments (
+    tenant       text not null,
+    symbol       text not null,
+    base_asset   text not null,
+    quote_asset  text not null,
+    tick_size    numeric not null default 0 check (tick_size >= 0),
+    lot_size     numeric not null default 0 check (lot_size >= 0),
+    min_notional numeric not null default 0 check (min_notional >= 0),
+    status       text not null default 'TRADING' check (status in ('TRADING', 'HALTED')),
+    updated_at   timestamptz not null default now(),
+    primary key (tenant, symbol)
+);
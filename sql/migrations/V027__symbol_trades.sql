@@ -0,0 +1,2 @@
+-- the trade history of a symbol pages by execution in nanoseconds
+create index on trades (tenant, symbol, executed_ns, id);
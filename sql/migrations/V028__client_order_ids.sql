@@ -0,0 +1,21 @@
+-- the ID a client gives its order; unique among the client's open orders, so it names one of them
+-- for a cancel or amend, and reusable once that order is done
+alter table open_orders add column client_order_id text not null default '';
+alter table order_history add column client_order_id text not null default '';
+alter table orders_archive add column client_order_id text not null default '';
+
+create unique index open_orders_client_order_id on open_orders (tenant, client_id, client_order_id) where client_order_id <> '';
+create index on order_history (tenant, client_id, client_order_id, created_ns) where client_order_id <> '';
+
+create or replace view orders as
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data,
+           group_id, cancel_group_on_fill, trigger_price, client_order_id
+    from open_orders
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data,
+           group_id, cancel_group_on_fill, trigger_price, client_order_id
+    from order_history
+    union all
+    select id, client_id, symbol, side, type, price, quantity, remaining, status, created_at, updated_at, tenant, created_ns, updated_ns, session_id, tags, user_data,
+           group_id, cancel_group_on_fill, trigger_price, client_order_id
+    from orders_archive;